@@ -0,0 +1,239 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#include <limits.h>
+#include <string.h>
+#include <memory>
+#include <openssl/evp.h>
+#include <openssl/rand.h>
+#include "crypto.h"
+
+namespace {
+
+// CipherCtx frees an EVP_CIPHER_CTX when it goes out of scope.
+struct CipherCtx {
+  CipherCtx() : ctx(EVP_CIPHER_CTX_new()) {}
+  ~CipherCtx() { EVP_CIPHER_CTX_free(ctx); }
+
+  EVP_CIPHER_CTX* const ctx;
+};
+
+const EVP_CIPHER* CTRMode(size_t key_size) {
+  switch (key_size) {
+    case 16:
+      return EVP_aes_128_ctr();
+    case 24:
+      return EVP_aes_192_ctr();
+    case 32:
+      return EVP_aes_256_ctr();
+  }
+  return NULL;
+}
+
+const EVP_CIPHER* ECBMode(size_t key_size) {
+  switch (key_size) {
+    case 16:
+      return EVP_aes_128_ecb();
+    case 24:
+      return EVP_aes_192_ecb();
+    case 32:
+      return EVP_aes_256_ecb();
+  }
+  return NULL;
+}
+
+const EVP_CIPHER* GCMMode(size_t key_size) {
+  switch (key_size) {
+    case 16:
+      return EVP_aes_128_gcm();
+    case 24:
+      return EVP_aes_192_gcm();
+    case 32:
+      return EVP_aes_256_gcm();
+  }
+  return NULL;
+}
+
+const uint8_t* Bytes(const std::string& s) { return reinterpret_cast<const uint8_t*>(s.data()); }
+
+uint8_t* Bytes(char* s) { return reinterpret_cast<uint8_t*>(s); }
+
+// Update runs EVP_CipherUpdate on the n bytes of in, which may alias out, in
+// chunks that fit in the int lengths of OpenSSL.
+bool Update(EVP_CIPHER_CTX* ctx, const uint8_t* in, uint8_t* out, size_t n) {
+  while (n > 0) {
+    const int chunk = n > INT_MAX ? INT_MAX : int(n);
+    int len;
+    if (EVP_CipherUpdate(ctx, out, &len, in, chunk) != 1 || len != chunk) {
+      return false;
+    }
+    in += chunk;
+    out += chunk;
+    n -= chunk;
+  }
+  return true;
+}
+
+const rocksdb::Status kCipherError = rocksdb::Status::IOError("OpenSSL cipher operation failed");
+
+}  // namespace
+
+bool ValidAESKeySize(size_t n) { return CTRMode(n) != NULL; }
+
+CTRCipher* CTRCipher::Create(const std::string& key, const std::string& iv) {
+  if (iv.size() != kAESBlockSize || !ValidAESKeySize(key.size())) {
+    return NULL;
+  }
+  return new CTRCipher(key, iv);
+}
+
+rocksdb::Status CTRCipher::Transform(uint64_t offset, char* data, size_t n) const {
+  // Compute the counter of the block containing offset by adding the block
+  // index to the initialization vector.
+  uint8_t counter[kAESBlockSize];
+  memcpy(counter, iv_.data(), kAESBlockSize);
+  uint64_t carry = offset / kAESBlockSize;
+  for (int i = kAESBlockSize - 1; i >= 0 && carry != 0; i--) {
+    carry += counter[i];
+    counter[i] = carry & 0xff;
+    carry >>= 8;
+  }
+
+  // OpenSSL increments the whole counter block as a 128-bit big-endian
+  // integer, as we do above.
+  CipherCtx c;
+  if (c.ctx == NULL ||
+      EVP_CipherInit_ex(c.ctx, CTRMode(key_.size()), NULL, Bytes(key_), counter, 1) != 1) {
+    return kCipherError;
+  }
+  // Discard the keystream preceding offset in its block.
+  uint8_t skip[kAESBlockSize] = {};
+  if (!Update(c.ctx, skip, skip, offset % kAESBlockSize) ||
+      !Update(c.ctx, Bytes(data), Bytes(data), n)) {
+    return kCipherError;
+  }
+  return rocksdb::Status::OK();
+}
+
+std::string KeyID(const std::string& key) {
+  CipherCtx c;
+  uint8_t block[kAESBlockSize] = {};
+  if (c.ctx == NULL || EVP_CipherInit_ex(c.ctx, ECBMode(key.size()), NULL, Bytes(key), NULL, 1) != 1 ||
+      EVP_CIPHER_CTX_set_padding(c.ctx, 0) != 1 || !Update(c.ctx, block, block, kAESBlockSize)) {
+    return "";
+  }
+  return HexEncode(std::string(reinterpret_cast<const char*>(block), 8));
+}
+
+rocksdb::Status AEADSeal(const std::string& key, const std::string& aad,
+                         const std::string& plaintext, std::string* sealed) {
+  std::string nonce;
+  rocksdb::Status status = RandomBytes(kGCMNonceSize, &nonce);
+  if (!status.ok()) {
+    return status;
+  }
+  sealed->assign(nonce);
+  sealed->append(plaintext);
+  sealed->resize(kGCMNonceSize + plaintext.size() + kGCMTagSize);
+  char* ciphertext = &(*sealed)[kGCMNonceSize];
+  char* tag = ciphertext + plaintext.size();
+
+  CipherCtx c;
+  int len;
+  if (c.ctx == NULL || EVP_CipherInit_ex(c.ctx, GCMMode(key.size()), NULL, NULL, NULL, 1) != 1 ||
+      EVP_CIPHER_CTX_ctrl(c.ctx, EVP_CTRL_GCM_SET_IVLEN, kGCMNonceSize, NULL) != 1 ||
+      EVP_CipherInit_ex(c.ctx, NULL, NULL, Bytes(key), Bytes(nonce), 1) != 1 ||
+      (!aad.empty() && EVP_CipherUpdate(c.ctx, NULL, &len, Bytes(aad), aad.size()) != 1) ||
+      !Update(c.ctx, Bytes(ciphertext), Bytes(ciphertext), plaintext.size()) ||
+      EVP_CipherFinal_ex(c.ctx, Bytes(tag), &len) != 1 ||
+      EVP_CIPHER_CTX_ctrl(c.ctx, EVP_CTRL_GCM_GET_TAG, kGCMTagSize, tag) != 1) {
+    return kCipherError;
+  }
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status AEADOpen(const std::string& key, const std::string& aad,
+                         const std::string& sealed, std::string* plaintext) {
+  if (sealed.size() < kGCMNonceSize + kGCMTagSize) {
+    return rocksdb::Status::Corruption("sealed data is too short");
+  }
+  const std::string nonce = sealed.substr(0, kGCMNonceSize);
+  std::string tag = sealed.substr(sealed.size() - kGCMTagSize);
+  plaintext->assign(sealed, kGCMNonceSize, sealed.size() - kGCMNonceSize - kGCMTagSize);
+
+  CipherCtx c;
+  int len;
+  if (c.ctx == NULL || EVP_CipherInit_ex(c.ctx, GCMMode(key.size()), NULL, NULL, NULL, 0) != 1 ||
+      EVP_CIPHER_CTX_ctrl(c.ctx, EVP_CTRL_GCM_SET_IVLEN, kGCMNonceSize, NULL) != 1 ||
+      EVP_CipherInit_ex(c.ctx, NULL, NULL, Bytes(key), Bytes(nonce), 0) != 1 ||
+      (!aad.empty() && EVP_CipherUpdate(c.ctx, NULL, &len, Bytes(aad), aad.size()) != 1) ||
+      (!plaintext->empty() &&
+       !Update(c.ctx, Bytes(&(*plaintext)[0]), Bytes(&(*plaintext)[0]), plaintext->size())) ||
+      EVP_CIPHER_CTX_ctrl(c.ctx, EVP_CTRL_GCM_SET_TAG, kGCMTagSize, &tag[0]) != 1) {
+    return kCipherError;
+  }
+  uint8_t unused[kAESBlockSize];
+  if (EVP_CipherFinal_ex(c.ctx, unused, &len) != 1) {
+    plaintext->clear();
+    return rocksdb::Status::Corruption("authentication of sealed data failed");
+  }
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status RandomBytes(size_t n, std::string* out) {
+  out->resize(n);
+  if (n > 0 && RAND_bytes(Bytes(&(*out)[0]), n) != 1) {
+    return rocksdb::Status::IOError("could not generate random bytes");
+  }
+  return rocksdb::Status::OK();
+}
+
+std::string HexEncode(const std::string& s) {
+  static const char kDigits[] = "0123456789abcdef";
+  std::string out;
+  out.reserve(2 * s.size());
+  for (unsigned char c : s) {
+    out.push_back(kDigits[c >> 4]);
+    out.push_back(kDigits[c & 0xf]);
+  }
+  return out;
+}
+
+namespace {
+
+int HexDigit(char c) {
+  if (c >= '0' && c <= '9') {
+    return c - '0';
+  }
+  if (c >= 'a' && c <= 'f') {
+    return c - 'a' + 10;
+  }
+  if (c >= 'A' && c <= 'F') {
+    return c - 'A' + 10;
+  }
+  return -1;
+}
+
+}  // namespace
+
+bool HexDecode(const std::string& s, std::string* out) {
+  if (s.size() % 2 != 0) {
+    return false;
+  }
+  out->clear();
+  out->reserve(s.size() / 2);
+  for (size_t i = 0; i < s.size(); i += 2) {
+    const int hi = HexDigit(s[i]);
+    const int lo = HexDigit(s[i + 1]);
+    if (hi < 0 || lo < 0) {
+      return false;
+    }
+    out->push_back(char((hi << 4) | lo));
+  }
+  return true;
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#ifndef ROACHLIB_CCL_CRYPTO_H
+#define ROACHLIB_CCL_CRYPTO_H
+
+#include <stdint.h>
+#include <string>
+#include <rocksdb/status.h>
+
+// The primitives below are implemented with OpenSSL's libcrypto, which uses
+// AES-NI or constant-time implementations of AES where available.
+
+// kAESBlockSize is the size in bytes of an AES block, and of the
+// initialization vectors used in CTR mode.
+const size_t kAESBlockSize = 16;
+
+// kGCMNonceSize and kGCMTagSize are the sizes in bytes of the nonces and
+// authentication tags of AEADSeal.
+const size_t kGCMNonceSize = 12;
+const size_t kGCMTagSize = 16;
+
+// ValidAESKeySize returns whether n is the size of an AES-128, AES-192 or
+// AES-256 key.
+bool ValidAESKeySize(size_t n);
+
+// CTRCipher encrypts and decrypts data with AES in counter mode. The
+// counter of the block at offset o of a file is the initialization vector
+// plus o/kAESBlockSize, as a 128-bit big-endian integer, so that any range
+// of a file can be transformed independently.
+class CTRCipher {
+ public:
+  // Create returns a CTRCipher for key and iv, which must be kAESBlockSize
+  // bytes long. It returns NULL if either argument has an invalid length.
+  static CTRCipher* Create(const std::string& key, const std::string& iv);
+
+  // Transform encrypts or decrypts, in place, the n bytes of data found at
+  // offset in the file.
+  rocksdb::Status Transform(uint64_t offset, char* data, size_t n) const;
+
+  const std::string& key() const { return key_; }
+  const std::string& iv() const { return iv_; }
+
+ private:
+  CTRCipher(const std::string& key, const std::string& iv) : key_(key), iv_(iv) {}
+
+  const std::string key_;
+  const std::string iv_;
+};
+
+// KeyID returns an identifier for key, which is not secret: the hex-encoded
+// first 8 bytes of the encryption of a block of zeros with key. key must be
+// a valid AES key. It returns an empty string if the encryption fails.
+std::string KeyID(const std::string& key);
+
+// AEADSeal encrypts and authenticates plaintext, and authenticates aad,
+// with AES in GCM mode and a random nonce. It sets sealed to the nonce,
+// followed by the ciphertext and the authentication tag.
+rocksdb::Status AEADSeal(const std::string& key, const std::string& aad,
+                         const std::string& plaintext, std::string* sealed);
+
+// AEADOpen decrypts sealed, as returned by AEADSeal with the same key and
+// aad, into plaintext. It returns a Corruption status if sealed or aad were
+// altered.
+rocksdb::Status AEADOpen(const std::string& key, const std::string& aad,
+                         const std::string& sealed, std::string* plaintext);
+
+// RandomBytes sets out to n bytes from OpenSSL's cryptographically secure
+// random number generator.
+rocksdb::Status RandomBytes(size_t n, std::string* out);
+
+// HexEncode returns the lowercase hexadecimal encoding of s.
+std::string HexEncode(const std::string& s);
+
+// HexDecode decodes the hexadecimal encoding s into out, returning false if
+// s is not a valid encoding.
+bool HexDecode(const std::string& s, std::string* out);
+
+#endif // ROACHLIB_CCL_CRYPTO_H
@@ -13,6 +13,7 @@
 #include <rocksdb/utilities/write_batch_with_index.h>
 #include <libroachccl.h>
 #include "../db.h"
+#include "encrypted_env.h"
 
 const DBStatus kSuccess = { NULL, 0 };
 
@@ -49,3 +50,43 @@ DBStatus DBBatchReprVerify(
 
   return kSuccess;
 }
+
+namespace {
+
+rocksdb::Status OpenEncryptedEnv(
+    const std::string& dir, const DBOptions& opts, rocksdb::Env* base_env, rocksdb::Env** env) {
+  std::unique_ptr<EncryptionRegistry> registry;
+  rocksdb::Status status = EncryptionRegistry::Load(
+      base_env, dir, ToString(opts.encryption_key), ToString(opts.encryption_old_key),
+      true /* rotate */, &registry);
+  if (!status.ok()) {
+    return status;
+  }
+  *env = new EncryptedEnv(base_env, std::move(registry));
+  return rocksdb::Status::OK();
+}
+
+}  // namespace
+
+void DBRegisterEncryptedEnv() {
+  SetDBOpenHook(OpenEncryptedEnv);
+}
+
+DBStatus DBGetEncryptionStatus(
+  DBSlice dir, DBSlice key, DBSlice old_key, DBString* store_key_id, DBString* files
+) {
+  std::unique_ptr<EncryptionRegistry> registry;
+  rocksdb::Status status = EncryptionRegistry::Load(
+      rocksdb::Env::Default(), ToString(dir), ToString(key), ToString(old_key),
+      false /* rotate */, &registry);
+  if (!status.ok()) {
+    return ToDBStatus(status);
+  }
+  std::string out;
+  for (const auto& f : registry->Files()) {
+    out += f.first + "\t" + f.second + "\n";
+  }
+  *store_key_id = ToDBString(registry->StoreKeyID());
+  *files = ToDBString(out);
+  return kSuccess;
+}
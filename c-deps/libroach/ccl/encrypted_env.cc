@@ -0,0 +1,730 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#include <string.h>
+#include <vector>
+#include "../db.h"
+#include "encrypted_env.h"
+
+const char kPlainStoreKey[] = "plain";
+
+namespace {
+
+// The registry file starts with a plaintext header line:
+//
+//   cockroach-encryption-registry 1 <store key ID>
+//
+// followed by one line per change to the registry, which are appended as
+// files are created, renamed and deleted. A change is one of:
+//
+//   + <hex data key> <hex IV> <file name>
+//   - <file name>
+//
+// which respectively set and remove the data key of a file. Unless the
+// store key ID is kPlainStoreKey, each line holds the hex encoding of a
+// change sealed with AES-GCM under the store key, with the preceding line
+// as additional authenticated data. A registry whose changes were altered,
+// reordered, or moved to another registry or store key therefore fails to
+// load. Changes dropped from the end of the registry cannot be detected.
+const char kRegistryMagic[] = "cockroach-encryption-registry";
+const char kRegistryVersion[] = "1";
+
+// kRegistryCompactionSlack is the number of changes, beyond twice the
+// number of files, past which the registry is rewritten with one change
+// per file instead of being appended to.
+const size_t kRegistryCompactionSlack = 1000;
+
+// ReadStoreKey reads the store key in the file at path into key, which is
+// left empty if path is kPlainStoreKey.
+rocksdb::Status ReadStoreKey(rocksdb::Env* env, const std::string& path, std::string* key) {
+  key->clear();
+  if (path == kPlainStoreKey) {
+    return rocksdb::Status::OK();
+  }
+  rocksdb::Status status = rocksdb::ReadFileToString(env, path, key);
+  if (!status.ok()) {
+    return status;
+  }
+  if (!ValidAESKeySize(key->size())) {
+    return rocksdb::Status::InvalidArgument(
+        path, "store key must be 16, 24 or 32 bytes long, found " + std::to_string(key->size()));
+  }
+  return rocksdb::Status::OK();
+}
+
+std::string IDOfStoreKey(const std::string& key) {
+  return key.empty() ? kPlainStoreKey : KeyID(key);
+}
+
+std::vector<std::string> SplitFields(const std::string& line, size_t n) {
+  std::vector<std::string> fields;
+  size_t start = 0;
+  while (fields.size() + 1 < n) {
+    const size_t end = line.find(' ', start);
+    if (end == std::string::npos) {
+      break;
+    }
+    fields.push_back(line.substr(start, end - start));
+    start = end + 1;
+  }
+  fields.push_back(line.substr(start));
+  return fields;
+}
+
+// ShouldEncrypt returns whether the file fname holds keys or values; see
+// the comment on EncryptedEnv.
+bool ShouldEncrypt(const std::string& fname) {
+  const size_t slash = fname.rfind('/');
+  const std::string base = slash == std::string::npos ? fname : fname.substr(slash + 1);
+  auto hasSuffix = [&base](const std::string& suffix) {
+    return base.size() >= suffix.size() &&
+        base.compare(base.size() - suffix.size(), suffix.size(), suffix) == 0;
+  };
+  return hasSuffix(".sst") || hasSuffix(".log") || hasSuffix(".ingested") ||
+      base.compare(0, 9, "MANIFEST-") == 0;
+}
+
+// Transform decrypts the data read at offset into scratch, copying it there
+// first if the read returned data stored elsewhere.
+rocksdb::Status Transform(const CTRCipher& cipher, uint64_t offset, rocksdb::Slice* result,
+                          char* scratch) {
+  if (result->data() != scratch) {
+    memmove(scratch, result->data(), result->size());
+    *result = rocksdb::Slice(scratch, result->size());
+  }
+  return cipher.Transform(offset, scratch, result->size());
+}
+
+std::string HeaderLine(const std::string& store_key_id) {
+  return std::string(kRegistryMagic) + " " + kRegistryVersion + " " + store_key_id;
+}
+
+std::string SetEntry(const std::string& fname, const CTRCipher& cipher) {
+  return "+ " + HexEncode(cipher.key()) + " " + HexEncode(cipher.iv()) + " " + fname;
+}
+
+std::string RemoveEntry(const std::string& fname) { return "- " + fname; }
+
+class EncryptedSequentialFile : public rocksdb::SequentialFile {
+ public:
+  EncryptedSequentialFile(std::unique_ptr<rocksdb::SequentialFile> file,
+                          std::shared_ptr<const CTRCipher> cipher)
+      : file_(std::move(file)),
+        cipher_(cipher),
+        offset_(0) {
+  }
+
+  rocksdb::Status Read(size_t n, rocksdb::Slice* result, char* scratch) override {
+    rocksdb::Status status = file_->Read(n, result, scratch);
+    if (!status.ok()) {
+      return status;
+    }
+    status = Transform(*cipher_, offset_, result, scratch);
+    if (status.ok()) {
+      offset_ += result->size();
+    }
+    return status;
+  }
+
+  rocksdb::Status Skip(uint64_t n) override {
+    rocksdb::Status status = file_->Skip(n);
+    if (status.ok()) {
+      offset_ += n;
+    }
+    return status;
+  }
+
+  rocksdb::Status PositionedRead(uint64_t offset, size_t n, rocksdb::Slice* result,
+                                 char* scratch) override {
+    rocksdb::Status status = file_->PositionedRead(offset, n, result, scratch);
+    if (!status.ok()) {
+      return status;
+    }
+    return Transform(*cipher_, offset, result, scratch);
+  }
+
+  bool use_direct_io() const override { return file_->use_direct_io(); }
+
+  size_t GetRequiredBufferAlignment() const override {
+    return file_->GetRequiredBufferAlignment();
+  }
+
+  rocksdb::Status InvalidateCache(size_t offset, size_t length) override {
+    return file_->InvalidateCache(offset, length);
+  }
+
+ private:
+  std::unique_ptr<rocksdb::SequentialFile> file_;
+  std::shared_ptr<const CTRCipher> cipher_;
+  uint64_t offset_;
+};
+
+class EncryptedRandomAccessFile : public rocksdb::RandomAccessFile {
+ public:
+  EncryptedRandomAccessFile(std::unique_ptr<rocksdb::RandomAccessFile> file,
+                            std::shared_ptr<const CTRCipher> cipher)
+      : file_(std::move(file)),
+        cipher_(cipher) {
+  }
+
+  rocksdb::Status Read(uint64_t offset, size_t n, rocksdb::Slice* result,
+                       char* scratch) const override {
+    rocksdb::Status status = file_->Read(offset, n, result, scratch);
+    if (!status.ok()) {
+      return status;
+    }
+    return Transform(*cipher_, offset, result, scratch);
+  }
+
+  rocksdb::Status Prefetch(uint64_t offset, size_t n) override {
+    return file_->Prefetch(offset, n);
+  }
+
+  size_t GetUniqueId(char* id, size_t max_size) const override {
+    return file_->GetUniqueId(id, max_size);
+  }
+
+  void Hint(AccessPattern pattern) override { file_->Hint(pattern); }
+
+  bool use_direct_io() const override { return file_->use_direct_io(); }
+
+  size_t GetRequiredBufferAlignment() const override {
+    return file_->GetRequiredBufferAlignment();
+  }
+
+  rocksdb::Status InvalidateCache(size_t offset, size_t length) override {
+    return file_->InvalidateCache(offset, length);
+  }
+
+ private:
+  std::unique_ptr<rocksdb::RandomAccessFile> file_;
+  std::shared_ptr<const CTRCipher> cipher_;
+};
+
+class EncryptedWritableFile : public rocksdb::WritableFile {
+ public:
+  EncryptedWritableFile(std::unique_ptr<rocksdb::WritableFile> file,
+                        std::shared_ptr<const CTRCipher> cipher, uint64_t offset)
+      : file_(std::move(file)),
+        cipher_(cipher),
+        offset_(offset) {
+  }
+
+  rocksdb::Status Append(const rocksdb::Slice& data) override {
+    buf_.assign(data.data(), data.size());
+    rocksdb::Status status = cipher_->Transform(offset_, &buf_[0], buf_.size());
+    if (!status.ok()) {
+      return status;
+    }
+    status = file_->Append(buf_);
+    if (status.ok()) {
+      offset_ += data.size();
+    }
+    return status;
+  }
+
+  rocksdb::Status PositionedAppend(const rocksdb::Slice& data, uint64_t offset) override {
+    buf_.assign(data.data(), data.size());
+    rocksdb::Status status = cipher_->Transform(offset, &buf_[0], buf_.size());
+    if (!status.ok()) {
+      return status;
+    }
+    status = file_->PositionedAppend(buf_, offset);
+    if (status.ok()) {
+      offset_ = offset + data.size();
+    }
+    return status;
+  }
+
+  rocksdb::Status Truncate(uint64_t size) override {
+    rocksdb::Status status = file_->Truncate(size);
+    if (status.ok()) {
+      offset_ = size;
+    }
+    return status;
+  }
+
+  rocksdb::Status Close() override { return file_->Close(); }
+  rocksdb::Status Flush() override { return file_->Flush(); }
+  rocksdb::Status Sync() override { return file_->Sync(); }
+  rocksdb::Status Fsync() override { return file_->Fsync(); }
+  bool IsSyncThreadSafe() const override { return file_->IsSyncThreadSafe(); }
+  bool use_direct_io() const override { return file_->use_direct_io(); }
+
+  size_t GetRequiredBufferAlignment() const override {
+    return file_->GetRequiredBufferAlignment();
+  }
+
+  void SetIOPriority(rocksdb::Env::IOPriority pri) override { file_->SetIOPriority(pri); }
+  rocksdb::Env::IOPriority GetIOPriority() override { return file_->GetIOPriority(); }
+  uint64_t GetFileSize() override { return file_->GetFileSize(); }
+
+  rocksdb::Status InvalidateCache(size_t offset, size_t length) override {
+    return file_->InvalidateCache(offset, length);
+  }
+
+  rocksdb::Status RangeSync(uint64_t offset, uint64_t nbytes) override {
+    return file_->RangeSync(offset, nbytes);
+  }
+
+  void PrepareWrite(size_t offset, size_t len) override { file_->PrepareWrite(offset, len); }
+
+  rocksdb::Status Allocate(uint64_t offset, uint64_t len) override {
+    return file_->Allocate(offset, len);
+  }
+
+ private:
+  std::unique_ptr<rocksdb::WritableFile> file_;
+  std::shared_ptr<const CTRCipher> cipher_;
+  uint64_t offset_;
+  std::string buf_;
+};
+
+}  // namespace
+
+rocksdb::Status EncryptionRegistry::Load(rocksdb::Env* env, const std::string& dir,
+                                         const std::string& key_path,
+                                         const std::string& old_key_path, bool rotate,
+                                         std::unique_ptr<EncryptionRegistry>* result) {
+  std::unique_ptr<EncryptionRegistry> registry(new EncryptionRegistry(env, dir));
+  rocksdb::Status status = ReadStoreKey(env, key_path, &registry->store_key_);
+  if (!status.ok()) {
+    return status;
+  }
+  std::string old_key;
+  if (!old_key_path.empty()) {
+    status = ReadStoreKey(env, old_key_path, &old_key);
+    if (!status.ok()) {
+      return status;
+    }
+  }
+
+  const std::string path = dir + "/" + kEncryptionRegistryFilename;
+  status = env->FileExists(path);
+  if (status.IsNotFound()) {
+    // No file was ever encrypted in this store.
+    registry->loaded_key_id_ = kPlainStoreKey;
+    *result = std::move(registry);
+    return rocksdb::Status::OK();
+  } else if (!status.ok()) {
+    return status;
+  }
+  std::string contents;
+  status = rocksdb::ReadFileToString(env, path, &contents);
+  if (!status.ok()) {
+    return status;
+  }
+  bool rotated = false;
+  status = registry->Parse(contents, registry->store_key_,
+                           old_key_path.empty() ? NULL : &old_key, &rotated);
+  if (!status.ok()) {
+    return status;
+  }
+  if (rotated && rotate) {
+    std::lock_guard<std::mutex> guard(registry->mu_);
+    status = registry->Rewrite();
+    if (!status.ok()) {
+      return status;
+    }
+  }
+  *result = std::move(registry);
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status EncryptionRegistry::Parse(const std::string& contents, const std::string& key,
+                                          const std::string* old_key, bool* rotated) {
+  const std::string path = dir_ + "/" + kEncryptionRegistryFilename;
+  const rocksdb::Status corrupt = rocksdb::Status::Corruption(path, "malformed encryption registry");
+
+  const size_t nl = contents.find('\n');
+  if (nl == std::string::npos) {
+    return corrupt;
+  }
+  const std::string header_line = contents.substr(0, nl);
+  const std::vector<std::string> header = SplitFields(header_line, 3);
+  if (header.size() != 3 || header[0] != kRegistryMagic || header[1] != kRegistryVersion) {
+    return corrupt;
+  }
+
+  loaded_key_id_ = header[2];
+  std::string registry_key;
+  if (loaded_key_id_ == IDOfStoreKey(key)) {
+    registry_key = key;
+  } else if (old_key != NULL && loaded_key_id_ == IDOfStoreKey(*old_key)) {
+    registry_key = *old_key;
+    *rotated = true;
+  } else if (loaded_key_id_ == kPlainStoreKey) {
+    // The registry was never encrypted.
+    *rotated = true;
+  } else {
+    return rocksdb::Status::InvalidArgument(
+        "encryption registry is encrypted with store key " + loaded_key_id_ +
+        ", which was not provided");
+  }
+
+  std::string prev_line = header_line;
+  bool torn = false;
+  for (size_t start = nl + 1; start < contents.size();) {
+    const size_t end = contents.find('\n', start);
+    if (end == std::string::npos) {
+      // We crashed while appending the last change, which was therefore
+      // never acknowledged.
+      torn = true;
+      break;
+    }
+    const std::string line = contents.substr(start, end - start);
+    start = end + 1;
+
+    std::string entry = line;
+    if (!registry_key.empty()) {
+      std::string sealed;
+      if (!HexDecode(line, &sealed)) {
+        return corrupt;
+      }
+      if (!AEADOpen(registry_key, prev_line, sealed, &entry).ok()) {
+        return rocksdb::Status::Corruption(path, "encryption registry failed authentication");
+      }
+    }
+    prev_line = line;
+    records_++;
+
+    if (entry.compare(0, 2, "- ") == 0) {
+      files_.erase(entry.substr(2));
+      continue;
+    }
+    const std::vector<std::string> fields = SplitFields(entry, 4);
+    std::string data_key, iv;
+    if (fields.size() != 4 || fields[0] != "+" || !HexDecode(fields[1], &data_key) ||
+        !HexDecode(fields[2], &iv)) {
+      return corrupt;
+    }
+    std::shared_ptr<const CTRCipher> cipher(CTRCipher::Create(data_key, iv));
+    if (cipher == NULL) {
+      return corrupt;
+    }
+    files_[fields[3]] = cipher;
+  }
+
+  // Changes are only appended to a registry sealed with the current store
+  // key, and which does not end with a partial change.
+  last_line_ = prev_line;
+  can_append_ = !*rotated && !torn;
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status EncryptionRegistry::SealEntry(const std::string& entry, std::string* line) {
+  if (store_key_.empty()) {
+    *line = entry;
+    return rocksdb::Status::OK();
+  }
+  std::string sealed;
+  rocksdb::Status status = AEADSeal(store_key_, last_line_, entry, &sealed);
+  if (!status.ok()) {
+    return status;
+  }
+  *line = HexEncode(sealed);
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status EncryptionRegistry::Record(const std::string& entry) {
+  if (!can_append_ || records_ >= 2 * files_.size() + kRegistryCompactionSlack) {
+    return Rewrite();
+  }
+  const std::string path = dir_ + "/" + kEncryptionRegistryFilename;
+  rocksdb::Status status;
+  if (file_ == NULL) {
+    status = env_->ReopenWritableFile(path, &file_, rocksdb::EnvOptions());
+    if (!status.ok()) {
+      return status;
+    }
+  }
+  std::string line;
+  status = SealEntry(entry, &line);
+  if (!status.ok()) {
+    return status;
+  }
+  // A failed append may leave a partial change at the end of the registry,
+  // so it is rewritten before anything else is appended to it.
+  can_append_ = false;
+  status = file_->Append(line + "\n");
+  if (!status.ok()) {
+    return status;
+  }
+  status = file_->Sync();
+  if (!status.ok()) {
+    return status;
+  }
+  can_append_ = true;
+  last_line_ = line;
+  records_++;
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status EncryptionRegistry::Rewrite() {
+  file_.reset();
+  can_append_ = false;
+  last_line_ = HeaderLine(IDOfStoreKey(store_key_));
+  std::string contents = last_line_ + "\n";
+  for (const auto& f : files_) {
+    std::string line;
+    rocksdb::Status status = SealEntry(SetEntry(f.first, *f.second), &line);
+    if (!status.ok()) {
+      return status;
+    }
+    contents += line + "\n";
+    last_line_ = line;
+  }
+
+  // Write the registry to a temporary file and rename it so that the
+  // registry is replaced atomically.
+  const std::string path = dir_ + "/" + kEncryptionRegistryFilename;
+  const std::string tmp_path = path + ".tmp";
+  rocksdb::Status status =
+      rocksdb::WriteStringToFile(env_, contents, tmp_path, true /* should_sync */);
+  if (!status.ok()) {
+    return status;
+  }
+  status = env_->RenameFile(tmp_path, path);
+  if (!status.ok()) {
+    return status;
+  }
+  std::unique_ptr<rocksdb::Directory> d;
+  status = env_->NewDirectory(dir_, &d);
+  if (!status.ok()) {
+    return status;
+  }
+  status = d->Fsync();
+  if (!status.ok()) {
+    return status;
+  }
+  records_ = files_.size();
+  can_append_ = true;
+  return rocksdb::Status::OK();
+}
+
+std::string EncryptionRegistry::RelativeName(const std::string& fname) const {
+  if (fname.size() > dir_.size() && fname.compare(0, dir_.size(), dir_) == 0 &&
+      fname[dir_.size()] == '/') {
+    return fname.substr(dir_.size() + 1);
+  }
+  return fname;
+}
+
+std::shared_ptr<const CTRCipher> EncryptionRegistry::GetCipher(const std::string& fname) {
+  std::lock_guard<std::mutex> guard(mu_);
+  auto it = files_.find(RelativeName(fname));
+  if (it == files_.end()) {
+    return NULL;
+  }
+  return it->second;
+}
+
+rocksdb::Status EncryptionRegistry::CreateCipher(const std::string& fname,
+                                                 std::shared_ptr<const CTRCipher>* cipher) {
+  std::string data_key, iv;
+  rocksdb::Status status = RandomBytes(store_key_.size(), &data_key);
+  if (!status.ok()) {
+    return status;
+  }
+  status = RandomBytes(kAESBlockSize, &iv);
+  if (!status.ok()) {
+    return status;
+  }
+  cipher->reset(CTRCipher::Create(data_key, iv));
+
+  std::lock_guard<std::mutex> guard(mu_);
+  const std::string name = RelativeName(fname);
+  files_[name] = *cipher;
+  return Record(SetEntry(name, **cipher));
+}
+
+rocksdb::Status EncryptionRegistry::Remove(const std::string& fname) {
+  std::lock_guard<std::mutex> guard(mu_);
+  const std::string name = RelativeName(fname);
+  if (files_.erase(name) == 0) {
+    return rocksdb::Status::OK();
+  }
+  return Record(RemoveEntry(name));
+}
+
+rocksdb::Status EncryptionRegistry::Link(const std::string& src, const std::string& dst) {
+  std::lock_guard<std::mutex> guard(mu_);
+  const std::string dst_name = RelativeName(dst);
+  auto it = files_.find(RelativeName(src));
+  if (it != files_.end()) {
+    files_[dst_name] = it->second;
+    return Record(SetEntry(dst_name, *it->second));
+  }
+  if (files_.erase(dst_name) == 0) {
+    return rocksdb::Status::OK();
+  }
+  return Record(RemoveEntry(dst_name));
+}
+
+std::map<std::string, std::string> EncryptionRegistry::Files() {
+  std::map<std::string, std::string> files;
+  std::lock_guard<std::mutex> guard(mu_);
+  for (const auto& f : files_) {
+    files[f.first] = KeyID(f.second->key());
+  }
+  return files;
+}
+
+rocksdb::Status EncryptedEnv::NewSequentialFile(const std::string& fname,
+                                                std::unique_ptr<rocksdb::SequentialFile>* result,
+                                                const rocksdb::EnvOptions& options) {
+  std::unique_ptr<rocksdb::SequentialFile> file;
+  rocksdb::Status status = target()->NewSequentialFile(fname, &file, options);
+  if (!status.ok()) {
+    return status;
+  }
+  std::shared_ptr<const CTRCipher> cipher = registry_->GetCipher(fname);
+  if (cipher == NULL) {
+    *result = std::move(file);
+  } else {
+    result->reset(new EncryptedSequentialFile(std::move(file), cipher));
+  }
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status EncryptedEnv::NewRandomAccessFile(
+    const std::string& fname, std::unique_ptr<rocksdb::RandomAccessFile>* result,
+    const rocksdb::EnvOptions& options) {
+  std::shared_ptr<const CTRCipher> cipher = registry_->GetCipher(fname);
+  if (cipher != NULL && options.use_mmap_reads) {
+    return rocksdb::Status::NotSupported("memory mapped reads of encrypted file", fname);
+  }
+  std::unique_ptr<rocksdb::RandomAccessFile> file;
+  rocksdb::Status status = target()->NewRandomAccessFile(fname, &file, options);
+  if (!status.ok()) {
+    return status;
+  }
+  if (cipher == NULL) {
+    *result = std::move(file);
+  } else {
+    result->reset(new EncryptedRandomAccessFile(std::move(file), cipher));
+  }
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status EncryptedEnv::NewWritableFile(const std::string& fname,
+                                              std::unique_ptr<rocksdb::WritableFile>* result,
+                                              const rocksdb::EnvOptions& options) {
+  std::shared_ptr<const CTRCipher> cipher;
+  rocksdb::Status status;
+  if (registry_->Encrypting() && ShouldEncrypt(fname)) {
+    status = registry_->CreateCipher(fname, &cipher);
+  } else {
+    // The file is truncated if it exists, and rewritten in plaintext.
+    status = registry_->Remove(fname);
+  }
+  if (!status.ok()) {
+    return status;
+  }
+  std::unique_ptr<rocksdb::WritableFile> file;
+  status = target()->NewWritableFile(fname, &file, options);
+  if (!status.ok()) {
+    return status;
+  }
+  if (cipher == NULL) {
+    *result = std::move(file);
+  } else {
+    result->reset(new EncryptedWritableFile(std::move(file), cipher, 0));
+  }
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status EncryptedEnv::ReopenWritableFile(const std::string& fname,
+                                                 std::unique_ptr<rocksdb::WritableFile>* result,
+                                                 const rocksdb::EnvOptions& options) {
+  std::unique_ptr<rocksdb::WritableFile> file;
+  rocksdb::Status status = target()->ReopenWritableFile(fname, &file, options);
+  if (!status.ok()) {
+    return status;
+  }
+  std::shared_ptr<const CTRCipher> cipher = registry_->GetCipher(fname);
+  if (cipher == NULL) {
+    *result = std::move(file);
+  } else {
+    const uint64_t size = file->GetFileSize();
+    result->reset(new EncryptedWritableFile(std::move(file), cipher, size));
+  }
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status EncryptedEnv::ReuseWritableFile(const std::string& fname,
+                                                const std::string& old_fname,
+                                                std::unique_ptr<rocksdb::WritableFile>* result,
+                                                const rocksdb::EnvOptions& options) {
+  // The old file is renamed and overwritten from the start, so it gets a new
+  // data key like any new file.
+  std::shared_ptr<const CTRCipher> cipher;
+  rocksdb::Status status;
+  if (registry_->Encrypting() && ShouldEncrypt(fname)) {
+    status = registry_->CreateCipher(fname, &cipher);
+  } else {
+    status = registry_->Remove(fname);
+  }
+  if (!status.ok()) {
+    return status;
+  }
+  std::unique_ptr<rocksdb::WritableFile> file;
+  status = target()->ReuseWritableFile(fname, old_fname, &file, options);
+  if (!status.ok()) {
+    return status;
+  }
+  status = registry_->Remove(old_fname);
+  if (!status.ok()) {
+    return status;
+  }
+  if (cipher == NULL) {
+    *result = std::move(file);
+  } else {
+    result->reset(new EncryptedWritableFile(std::move(file), cipher, 0));
+  }
+  return rocksdb::Status::OK();
+}
+
+rocksdb::Status EncryptedEnv::NewRandomRWFile(const std::string& fname,
+                                              std::unique_ptr<rocksdb::RandomRWFile>* result,
+                                              const rocksdb::EnvOptions& options) {
+  if (registry_->GetCipher(fname) != NULL) {
+    return rocksdb::Status::NotSupported("random read-write access to encrypted file", fname);
+  }
+  return target()->NewRandomRWFile(fname, result, options);
+}
+
+rocksdb::Status EncryptedEnv::DeleteFile(const std::string& fname) {
+  rocksdb::Status status = target()->DeleteFile(fname);
+  if (!status.ok()) {
+    return status;
+  }
+  return registry_->Remove(fname);
+}
+
+rocksdb::Status EncryptedEnv::RenameFile(const std::string& src, const std::string& dst) {
+  // The data key of src is registered for dst before the rename so that dst
+  // is always readable, even if we crash before src is forgotten.
+  rocksdb::Status status = registry_->Link(src, dst);
+  if (!status.ok()) {
+    return status;
+  }
+  status = target()->RenameFile(src, dst);
+  if (!status.ok()) {
+    return status;
+  }
+  return registry_->Remove(src);
+}
+
+rocksdb::Status EncryptedEnv::LinkFile(const std::string& src, const std::string& dst) {
+  rocksdb::Status status = registry_->Link(src, dst);
+  if (!status.ok()) {
+    return status;
+  }
+  return target()->LinkFile(src, dst);
+}
@@ -0,0 +1,164 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+#ifndef ROACHLIB_CCL_ENCRYPTED_ENV_H
+#define ROACHLIB_CCL_ENCRYPTED_ENV_H
+
+#include <map>
+#include <memory>
+#include <mutex>
+#include <string>
+#include <rocksdb/env.h>
+#include "crypto.h"
+
+// kPlainStoreKey is the store key path denoting that files are not
+// encrypted.
+extern const char kPlainStoreKey[];
+
+// EncryptionRegistry holds the data keys of the encrypted files of a
+// store. Each encrypted file has its own randomly generated data key and
+// initialization vector, and the registry holding them is encrypted with
+// the store key, read from a file provided by the operator. Rotating the
+// store key only requires re-encrypting the registry: the data files are
+// left untouched.
+//
+// The registry is authenticated as well as encrypted, so that it cannot be
+// altered without detection. Every time an encrypted file is created,
+// renamed or deleted, the change is appended to the registry and synced;
+// the registry is only rewritten once enough changes have accumulated, or
+// when the store key changes. It is safe for concurrent use.
+class EncryptionRegistry {
+ public:
+  // Load reads the registry of the store in dir with the store key in the
+  // file at key_path, or the file at old_key_path if the registry is still
+  // encrypted with the previous store key. In the latter case, and if
+  // rotate is set, the registry is re-encrypted with the new store key.
+  // Either path can be kPlainStoreKey, and old_key_path can be empty if
+  // the store key is not being rotated.
+  static rocksdb::Status Load(rocksdb::Env* env, const std::string& dir,
+                              const std::string& key_path, const std::string& old_key_path,
+                              bool rotate, std::unique_ptr<EncryptionRegistry>* result);
+
+  // Encrypting returns whether new files are encrypted, which is the case
+  // unless the store key is kPlainStoreKey.
+  bool Encrypting() const { return !store_key_.empty(); }
+
+  // StoreKeyID returns the ID of the store key the registry was encrypted
+  // with when it was loaded, or kPlainStoreKey.
+  const std::string& StoreKeyID() const { return loaded_key_id_; }
+
+  // GetCipher returns the cipher of the file fname, or NULL if the file is
+  // not encrypted.
+  std::shared_ptr<const CTRCipher> GetCipher(const std::string& fname);
+
+  // CreateCipher generates a data key and initialization vector for the
+  // new file fname, and persists them before returning the cipher.
+  rocksdb::Status CreateCipher(const std::string& fname, std::shared_ptr<const CTRCipher>* cipher);
+
+  // Remove forgets the data key of the file fname, if any.
+  rocksdb::Status Remove(const std::string& fname);
+
+  // Link makes the file dst use the data key of the file src. It is called
+  // before src is renamed or hard linked to dst.
+  rocksdb::Status Link(const std::string& src, const std::string& dst);
+
+  // Files returns the IDs of the data keys of the encrypted files, keyed by
+  // file name relative to the store directory.
+  std::map<std::string, std::string> Files();
+
+ private:
+  EncryptionRegistry(rocksdb::Env* env, const std::string& dir)
+      : env_(env),
+        dir_(dir),
+        records_(0),
+        can_append_(false) {
+  }
+
+  std::string RelativeName(const std::string& fname) const;
+  rocksdb::Status Parse(const std::string& contents, const std::string& key,
+                        const std::string* old_key, bool* rotated);
+  // The methods below must be called with mu_ held.
+  //
+  // SealEntry encodes a change to the registry as the line following
+  // last_line_.
+  rocksdb::Status SealEntry(const std::string& entry, std::string* line);
+  // Record persists a change, already applied to files_, by appending it to
+  // the registry or by rewriting the registry.
+  rocksdb::Status Record(const std::string& entry);
+  // Rewrite replaces the registry with one holding the current data keys,
+  // sealed with the current store key.
+  rocksdb::Status Rewrite();
+
+  rocksdb::Env* const env_;
+  const std::string dir_;
+  std::string store_key_;
+  std::string loaded_key_id_;
+
+  std::mutex mu_;
+  std::map<std::string, std::shared_ptr<const CTRCipher>> files_;
+  // file_ is the registry file, opened for appending changes once one is
+  // recorded.
+  std::unique_ptr<rocksdb::WritableFile> file_;
+  // last_line_ is the last line of the registry, which authenticates the
+  // next change.
+  std::string last_line_;
+  // records_ is the number of changes in the registry.
+  size_t records_;
+  // can_append_ is false when the registry must be rewritten before changes
+  // are appended to it.
+  bool can_append_;
+};
+
+// EncryptedEnv is an Env encrypting the files of a store with AES in CTR
+// mode, using the data keys of the store's EncryptionRegistry. File sizes
+// are unchanged by the encryption.
+//
+// Only the files holding keys and values are encrypted: SSTables,
+// write-ahead logs, MANIFEST files and the SSTables written by CockroachDB
+// for ingestion (with an ".ingested" suffix), which keep their data key once
+// RocksDB links them into the store. The small metadata files RocksDB
+// atomically replaces by renaming temporary files (CURRENT, IDENTITY and
+// OPTIONS) and its info logs are left in plaintext, so that a crash can
+// never leave a file with the data key of another.
+//
+// Memory mapped reads, and random read-write access to encrypted files,
+// are not supported.
+class EncryptedEnv : public rocksdb::EnvWrapper {
+ public:
+  EncryptedEnv(rocksdb::Env* base_env, std::unique_ptr<EncryptionRegistry> registry)
+      : rocksdb::EnvWrapper(base_env),
+        registry_(std::move(registry)) {
+  }
+
+  rocksdb::Status NewSequentialFile(const std::string& fname,
+                                    std::unique_ptr<rocksdb::SequentialFile>* result,
+                                    const rocksdb::EnvOptions& options) override;
+  rocksdb::Status NewRandomAccessFile(const std::string& fname,
+                                      std::unique_ptr<rocksdb::RandomAccessFile>* result,
+                                      const rocksdb::EnvOptions& options) override;
+  rocksdb::Status NewWritableFile(const std::string& fname,
+                                  std::unique_ptr<rocksdb::WritableFile>* result,
+                                  const rocksdb::EnvOptions& options) override;
+  rocksdb::Status ReopenWritableFile(const std::string& fname,
+                                     std::unique_ptr<rocksdb::WritableFile>* result,
+                                     const rocksdb::EnvOptions& options) override;
+  rocksdb::Status ReuseWritableFile(const std::string& fname, const std::string& old_fname,
+                                    std::unique_ptr<rocksdb::WritableFile>* result,
+                                    const rocksdb::EnvOptions& options) override;
+  rocksdb::Status NewRandomRWFile(const std::string& fname,
+                                  std::unique_ptr<rocksdb::RandomRWFile>* result,
+                                  const rocksdb::EnvOptions& options) override;
+  rocksdb::Status DeleteFile(const std::string& fname) override;
+  rocksdb::Status RenameFile(const std::string& src, const std::string& dst) override;
+  rocksdb::Status LinkFile(const std::string& src, const std::string& dst) override;
+
+ private:
+  std::unique_ptr<EncryptionRegistry> registry_;
+};
+
+#endif // ROACHLIB_CCL_ENCRYPTED_ENV_H
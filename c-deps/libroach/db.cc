@@ -89,7 +89,7 @@ struct DBEngine {
 };
 
 struct DBImpl : public DBEngine {
-  std::unique_ptr<rocksdb::Env> memenv;
+  std::unique_ptr<rocksdb::Env> env;
   std::unique_ptr<rocksdb::DB> rep_deleter;
   std::shared_ptr<rocksdb::Cache> block_cache;
   std::shared_ptr<DBEventListener> event_listener;
@@ -97,10 +97,10 @@ struct DBImpl : public DBEngine {
   // Construct a new DBImpl from the specified DB and Env. Both the DB
   // and Env will be deleted when the DBImpl is deleted. It is ok to
   // pass NULL for the Env.
-  DBImpl(rocksdb::DB* r, rocksdb::Env* m, std::shared_ptr<rocksdb::Cache> bc,
+  DBImpl(rocksdb::DB* r, rocksdb::Env* e, std::shared_ptr<rocksdb::Cache> bc,
     std::shared_ptr<DBEventListener> event_listener)
       : DBEngine(r),
-        memenv(m),
+        env(e),
         rep_deleter(r),
         block_cache(bc),
         event_listener(event_listener) {
@@ -1684,6 +1684,18 @@ rocksdb::Options DBMakeOptions(DBOptions db_opts) {
   return options;
 }
 
+const char kEncryptionRegistryFilename[] = "COCKROACHDB_ENCRYPTION_REGISTRY";
+
+namespace {
+
+DBOpenHook db_open_hook = NULL;
+
+}  // namespace
+
+void SetDBOpenHook(DBOpenHook hook) {
+  db_open_hook = hook;
+}
+
 DBStatus DBOpen(DBEngine **db, DBSlice dir, DBOptions db_opts) {
   rocksdb::Options options = DBMakeOptions(db_opts);
 
@@ -1691,10 +1703,28 @@ DBStatus DBOpen(DBEngine **db, DBSlice dir, DBOptions db_opts) {
   std::shared_ptr<DBEventListener> event_listener(new DBEventListener);
   options.listeners.emplace_back(event_listener);
 
-  std::unique_ptr<rocksdb::Env> memenv;
+  std::unique_ptr<rocksdb::Env> env;
   if (dir.len == 0) {
-    memenv.reset(rocksdb::NewMemEnv(rocksdb::Env::Default()));
-    options.env = memenv.get();
+    env.reset(rocksdb::NewMemEnv(rocksdb::Env::Default()));
+    options.env = env.get();
+  } else if (db_opts.encryption_key.len != 0) {
+    if (db_open_hook == NULL) {
+      return FmtStatus("encryption at rest requires a CCL binary");
+    }
+    rocksdb::Env* encrypted_env;
+    rocksdb::Status status = db_open_hook(
+        ToString(dir), db_opts, rocksdb::Env::Default(), &encrypted_env);
+    if (!status.ok()) {
+      return ToDBStatus(status);
+    }
+    env.reset(encrypted_env);
+    options.env = env.get();
+  } else if (options.env->FileExists(ToString(dir) + "/" + kEncryptionRegistryFilename).ok()) {
+    // Reading an encrypted store as plaintext would fail in obscure ways
+    // at best.
+    return FmtStatus(
+        "store %s is encrypted: its store key must be given with --enterprise-encryption",
+        ToString(dir).c_str());
   }
 
   rocksdb::DB *db_ptr;
@@ -1702,7 +1732,7 @@ DBStatus DBOpen(DBEngine **db, DBSlice dir, DBOptions db_opts) {
   if (!status.ok()) {
     return ToDBStatus(status);
   }
-  *db = new DBImpl(db_ptr, memenv.release(),
+  *db = new DBImpl(db_ptr, env.release(),
       db_opts.cache != nullptr ? db_opts.cache->rep : nullptr,
       event_listener);
   return kSuccess;
@@ -12,8 +12,10 @@
 // implied.  See the License for the specific language governing
 // permissions and limitations under the License.
 
+#include <string>
 #include <rocksdb/iterator.h>
 #include <rocksdb/comparator.h>
+#include <rocksdb/env.h>
 #include <rocksdb/write_batch.h>
 #include <rocksdb/write_batch_base.h>
 #include <libroach.h>
@@ -21,6 +23,9 @@
 // ToString returns a c++ string with the contents of a DBSlice.
 std::string ToString(DBSlice s);
 
+// ToDBString returns a DBString with a copy of the contents of a Slice.
+DBString ToDBString(const rocksdb::Slice& s);
+
 // MVCC keys are encoded as <key>[<wall_time>[<logical>]]<#timestamp-bytes>. A
 // custom RocksDB comparator (DBComparator) is used to maintain the desired
 // ordering as these keys do not sort lexicographically correctly.
@@ -44,3 +49,18 @@ const ::rocksdb::Comparator* CockroachComparator();
 // Stats are only computed for keys between the given range.
 MVCCStatsResult MVCCComputeStatsInternal(
     ::rocksdb::Iterator* const iter_rep, DBKey start, DBKey end, int64_t now_nanos);
+
+// kEncryptionRegistryFilename is the name of the file, in the store
+// directory, holding the data keys of the store's encrypted files. Its
+// presence marks the store as encrypted.
+extern const char kEncryptionRegistryFilename[];
+
+// A DBOpenHook creates the Env encrypting the files of the store in dir with
+// the store keys in opts, on top of base_env. The caller assumes ownership
+// of the returned Env.
+typedef rocksdb::Status (*DBOpenHook)(
+    const std::string& dir, const DBOptions& opts, rocksdb::Env* base_env, rocksdb::Env** env);
+
+// SetDBOpenHook sets the hook DBOpen uses to open stores whose files are
+// encrypted. It is called by CCL code, which implements encryption at rest.
+void SetDBOpenHook(DBOpenHook hook);
@@ -71,6 +71,12 @@ typedef struct {
   bool logging_enabled;
   int num_cpu;
   int max_open_files;
+  // Paths to the files holding the store key used to encrypt the store's
+  // files at rest and, if it is being rotated, the previous store key.
+  // Either can be "plain" for no encryption. Encryption is disabled if
+  // encryption_key is empty, and requires a CCL binary otherwise.
+  DBSlice encryption_key;
+  DBSlice encryption_old_key;
 } DBOptions;
 
 // Create a new cache with the specified size.
@@ -20,6 +20,20 @@ extern "C" {
 DBStatus DBBatchReprVerify(
   DBSlice repr, DBKey start, DBKey end, int64_t now_nanos, MVCCStatsResult* stats);
 
+// DBRegisterEncryptedEnv makes DBOpen encrypt the files of the stores
+// opened with an encryption key. It must be called before any such store is
+// opened.
+void DBRegisterEncryptedEnv();
+
+// DBGetEncryptionStatus reads the encryption registry of the store in dir
+// with the store key in the file at key, or the file at old_key if it is
+// not empty and the registry was not yet re-encrypted with key. It sets
+// store_key_id to the ID of the store key encrypting the registry, or
+// "plain", and files to a line per encrypted file, holding the file name
+// and the ID of its data key separated by a tab.
+DBStatus DBGetEncryptionStatus(
+  DBSlice dir, DBSlice key, DBSlice old_key, DBString* store_key_id, DBString* files);
+
 #ifdef __cplusplus
 }  // extern "C"
 #endif
@@ -272,6 +272,117 @@ func (ssl *StoreSpecList) Set(value string) error {
 	return nil
 }
 
+// StoreEncryptionSpec contains the details that can be specified in the cli
+// pertaining to the --enterprise-encryption flag.
+type StoreEncryptionSpec struct {
+	Path       string
+	KeyPath    string
+	OldKeyPath string
+}
+
+// String returns a fully parsable version of the store encryption spec.
+func (es StoreEncryptionSpec) String() string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "path=%s,key=%s", es.Path, es.KeyPath)
+	if len(es.OldKeyPath) != 0 {
+		fmt.Fprintf(&buffer, ",old-key=%s", es.OldKeyPath)
+	}
+	return buffer.String()
+}
+
+// NewStoreEncryptionSpec parses the string passed into a
+// --enterprise-encryption flag and returns a StoreEncryptionSpec if it is
+// correctly parsed. The fields are, comma separated:
+// - path=xxx The path of the store to encrypt, as given to --store.
+// - key=xxx The path of the file holding the store key, which must be 16, 24
+//   or 32 bytes long for AES-128, AES-192 or AES-256 respectively, or
+//   "plain" to write new files unencrypted.
+// - old-key=xxx The optional path of the file holding the previous store
+//   key, or "plain", when the store key is being rotated.
+func NewStoreEncryptionSpec(value string) (StoreEncryptionSpec, error) {
+	var es StoreEncryptionSpec
+	used := make(map[string]struct{})
+	for _, split := range strings.Split(value, ",") {
+		if len(split) == 0 {
+			continue
+		}
+		subSplits := strings.SplitN(split, "=", 2)
+		if len(subSplits) != 2 {
+			return StoreEncryptionSpec{}, fmt.Errorf("%s is not of the form field=value", split)
+		}
+		field := strings.ToLower(subSplits[0])
+		value := subSplits[1]
+		if _, ok := used[field]; ok {
+			return StoreEncryptionSpec{}, fmt.Errorf("%s field was used twice in encryption definition", field)
+		}
+		used[field] = struct{}{}
+		if len(value) == 0 {
+			return StoreEncryptionSpec{}, fmt.Errorf("no value specified for %s", field)
+		}
+
+		switch field {
+		case "path":
+			var err error
+			es.Path, err = filepath.Abs(value)
+			if err != nil {
+				return StoreEncryptionSpec{}, errors.Wrapf(err, "could not find absolute path for %s", value)
+			}
+		case "key":
+			es.KeyPath = value
+		case "old-key":
+			es.OldKeyPath = value
+		default:
+			return StoreEncryptionSpec{}, fmt.Errorf("%s is not a valid enterprise-encryption field", field)
+		}
+	}
+	if es.Path == "" {
+		return StoreEncryptionSpec{}, fmt.Errorf("no path specified")
+	}
+	if es.KeyPath == "" {
+		return StoreEncryptionSpec{}, fmt.Errorf("no key specified")
+	}
+	return es, nil
+}
+
+// StoreEncryptionSpecList contains a slice of StoreEncryptionSpecs that
+// implements pflag's value interface.
+type StoreEncryptionSpecList struct {
+	Specs []StoreEncryptionSpec
+}
+
+var _ pflag.Value = &StoreEncryptionSpecList{}
+
+// String returns a string representation of all the StoreEncryptionSpecs.
+// This is part of pflag's value interface.
+func (esl StoreEncryptionSpecList) String() string {
+	var buffer bytes.Buffer
+	for _, es := range esl.Specs {
+		fmt.Fprintf(&buffer, "--enterprise-encryption=%s ", es)
+	}
+	// Trim the extra space from the end if it exists.
+	if l := buffer.Len(); l > 0 {
+		buffer.Truncate(l - 1)
+	}
+	return buffer.String()
+}
+
+// Type returns the underlying type in string form. This is part of pflag's
+// value interface.
+func (esl *StoreEncryptionSpecList) Type() string {
+	return "StoreEncryptionSpec"
+}
+
+// Set adds a new value to the StoreEncryptionSpecList. It is the important
+// part of pflag's value interface.
+func (esl *StoreEncryptionSpecList) Set(value string) error {
+	spec, err := NewStoreEncryptionSpec(value)
+	if err != nil {
+		return err
+	}
+	esl.Specs = append(esl.Specs, spec)
+	return nil
+}
+
 // JoinListType is a slice of strings that implements pflag's value
 // interface.
 type JoinListType []string
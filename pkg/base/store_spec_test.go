@@ -145,3 +145,58 @@ func TestNewStoreSpec(t *testing.T) {
 		}
 	}
 }
+
+// TestNewStoreEncryptionSpec verifies that the --enterprise-encryption
+// arguments are correctly parsed into StoreEncryptionSpecs.
+func TestNewStoreEncryptionSpec(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		value       string
+		expectedErr string
+		expected    StoreEncryptionSpec
+	}{
+		{"path=/mnt/hda1,key=/keys/a.key", "", StoreEncryptionSpec{"/mnt/hda1", "/keys/a.key", ""}},
+		{"key=plain,path=/mnt/hda1,", "", StoreEncryptionSpec{"/mnt/hda1", "plain", ""}},
+		{"path=/mnt/hda1,key=/keys/b.key,old-key=/keys/a.key", "", StoreEncryptionSpec{"/mnt/hda1", "/keys/b.key", "/keys/a.key"}},
+		{"path=/mnt/hda1,key=/keys/a.key,old-key=plain", "", StoreEncryptionSpec{"/mnt/hda1", "/keys/a.key", "plain"}},
+
+		{"", "no path specified", StoreEncryptionSpec{}},
+		{"key=/keys/a.key", "no path specified", StoreEncryptionSpec{}},
+		{"path=/mnt/hda1", "no key specified", StoreEncryptionSpec{}},
+		{"/mnt/hda1,key=/keys/a.key", "/mnt/hda1 is not of the form field=value", StoreEncryptionSpec{}},
+		{"path=/mnt/hda1,key=", "no value specified for key", StoreEncryptionSpec{}},
+		{"path=/mnt/hda1,key=a,key=b", "key field was used twice in encryption definition", StoreEncryptionSpec{}},
+		{"path=/mnt/hda1,key=a,size=1", "size is not a valid enterprise-encryption field", StoreEncryptionSpec{}},
+	}
+
+	for i, testCase := range testCases {
+		spec, err := NewStoreEncryptionSpec(testCase.value)
+		if err != nil {
+			if testCase.expectedErr != fmt.Sprint(err) {
+				t.Errorf("%d(%s): expected error \"%s\" does not match actual \"%s\"", i, testCase.value,
+					testCase.expectedErr, err)
+			}
+			continue
+		}
+		if len(testCase.expectedErr) > 0 {
+			t.Errorf("%d(%s): expected error %s but there was none", i, testCase.value, testCase.expectedErr)
+			continue
+		}
+		if !reflect.DeepEqual(testCase.expected, spec) {
+			t.Errorf("%d(%s): actual doesn't match expected\nactual:   %+v\nexpected: %+v", i,
+				testCase.value, spec, testCase.expected)
+		}
+
+		// Now test String() to make sure the result can be parsed.
+		spec2, err := NewStoreEncryptionSpec(spec.String())
+		if err != nil {
+			t.Errorf("%d(%s): error parsing String() result: %s", i, testCase.value, err)
+			continue
+		}
+		if spec != spec2 {
+			t.Errorf("%d(%s): actual doesn't match expected\nactual:   %+v\nexpected: %+v", i,
+				testCase.value, spec2, spec)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package cliccl
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl"
+	"github.com/cockroachdb/cockroach/pkg/cli"
+)
+
+func init() {
+	encryptionStatusCmd := &cobra.Command{
+		Use:   "encryption-status <directory> --key=<file> [--old-key=<file>]",
+		Short: "show the encryption status of a store",
+		Long: `
+Shows the ID of the store key protecting the data keys of an encrypted store,
+followed by each encrypted file of the store and the ID of its data key. The
+store key and previous store key are given as to --enterprise-encryption, with
+the previous key only needed if the node was not restarted since the store key
+was rotated.
+
+The store must not be in use.
+`,
+		RunE: runEncryptionStatus,
+	}
+	flags := encryptionStatusCmd.Flags()
+	flags.StringVar(&encryptionKey, "key", "", `path of the file holding the store key, or "plain"`)
+	flags.StringVar(&encryptionOldKey, "old-key", "", `path of the file holding the previous store key, or "plain"`)
+	cli.AddDebugCmd(encryptionStatusCmd)
+}
+
+var (
+	encryptionKey    string
+	encryptionOldKey string
+)
+
+func runEncryptionStatus(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("one argument required: dir")
+	}
+	if encryptionKey == "" {
+		return errors.New("--key is required")
+	}
+
+	status, err := engineccl.GetEncryptionStatus(args[0], encryptionKey, encryptionOldKey)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("store key: %s\n", status.StoreKeyID)
+
+	names := make([]string, 0, len(status.Files))
+	for name := range status.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tw := tabwriter.NewWriter(os.Stdout, 2, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "file\tdata key")
+	for _, name := range names {
+		fmt.Fprintf(tw, "%s\t%s\n", name, status.Files[name])
+	}
+	return tw.Flush()
+}
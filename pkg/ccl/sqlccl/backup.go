@@ -57,6 +57,11 @@ const (
 // to durable storage.
 var BackupCheckpointInterval = time.Minute
 
+// BackupProtectedTimestampExpiration is how long a backup prevents the GC
+// queue from collecting the data it exports past its last checkpoint. It
+// bounds how long the record of a backup whose node died keeps blocking GC.
+var BackupProtectedTimestampExpiration = 24 * time.Hour
+
 // BackupImplicitSQLDescriptors are descriptors for tables that are implicitly
 // included in every backup, plus their parent database descriptors.
 var BackupImplicitSQLDescriptors = []sqlbase.Descriptor{
@@ -387,7 +392,7 @@ func makeBackupDescriptor(
 
 	for _, desc := range sqlDescs {
 		if dbDesc := desc.GetDatabase(); dbDesc != nil {
-			if err := p.CheckPrivilege(dbDesc, privilege.SELECT); err != nil {
+			if err := p.CheckPrivilege(ctx, dbDesc, privilege.SELECT); err != nil {
 				return BackupDescriptor{}, err
 			}
 		}
@@ -401,7 +406,7 @@ func makeBackupDescriptor(
 	}
 
 	for _, desc := range tables {
-		if err := p.CheckPrivilege(desc, privilege.SELECT); err != nil {
+		if err := p.CheckPrivilege(ctx, desc, privilege.SELECT); err != nil {
 			return BackupDescriptor{}, err
 		}
 	}
@@ -491,6 +496,25 @@ func backup(
 		return err
 	}
 
+	// Prevent the GC queue from collecting data before it has been exported.
+	// Incremental backups also need the deletions since their start time, so
+	// protect that instead of the end time when there is one. The protection
+	// is extended whenever the backup checkpoints its progress.
+	protectedTS := backupDesc.EndTime
+	if (backupDesc.StartTime != hlc.Timestamp{}) {
+		protectedTS = backupDesc.StartTime
+	}
+	expiration := hlc.Timestamp{WallTime: timeutil.Now().Add(BackupProtectedTimestampExpiration).UnixNano()}
+	recordID, err := job.ProtectTimestamp(ctx, protectedTS, backupDesc.Spans, expiration)
+	if err != nil {
+		return errors.Wrap(err, "protecting backup timestamp")
+	}
+	defer func() {
+		if err := job.ReleaseProtectedTimestamp(ctx, recordID); err != nil {
+			log.Warningf(ctx, "unable to release protected timestamp record %d: %+v", recordID, err)
+		}
+	}()
+
 	// We're already limiting these on the server-side, but sending all the
 	// Export requests at once would fill up distsender/grpc/something and cause
 	// all sorts of badness (node liveness timeouts leading to mass leaseholder
@@ -573,6 +597,10 @@ func backup(
 					mu.checkpointed = true
 					mu.Unlock()
 				}
+				expiration := hlc.Timestamp{WallTime: timeutil.Now().Add(BackupProtectedTimestampExpiration).UnixNano()}
+				if err := job.ExtendProtectedTimestamp(ctx, recordID, expiration); err != nil {
+					return errors.Wrap(err, "extending backup protected timestamp")
+				}
 			}
 			return nil
 		})
@@ -230,7 +230,7 @@ func allocateTableRewrites(
 						return errors.Wrapf(err, "failed to lookup parent DB %d", parentID)
 					}
 
-					if err := p.CheckPrivilege(parentDB, privilege.CREATE); err != nil {
+					if err := p.CheckPrivilege(ctx, parentDB, privilege.CREATE); err != nil {
 						return err
 					}
 				}
@@ -12,13 +12,16 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/pkg/errors"
 )
@@ -30,6 +33,51 @@ func init() {
 	})
 }
 
+// AddSSTables writes kvs, which must be sorted by key, at timestamp ts using
+// AddSSTable requests of at most kv.import.batch_size bytes each. The keys
+// are expected to be in a span that nothing else is reading or writing, such
+// as an index that is not yet public.
+func AddSSTables(
+	ctx context.Context,
+	db *client.DB,
+	st *cluster.Settings,
+	ts hlc.Timestamp,
+	kvs []roachpb.KeyValue,
+) error {
+	maxSize := maxImportBatchSize(st)
+	for len(kvs) > 0 {
+		sstWriter, err := engine.MakeRocksDBSstFileWriter()
+		if err != nil {
+			return errors.Wrapf(err, "making sstBatcher")
+		}
+		batcher := &sstBatcher{sstWriter: sstWriter}
+		n := 0
+		for ; n < len(kvs) && batcher.Size() < maxSize; n++ {
+			key := engine.MVCCKey{Key: kvs[n].Key, Timestamp: ts}
+			if err := batcher.Add(key, kvs[n].Value.RawBytes); err != nil {
+				batcher.Close()
+				return err
+			}
+		}
+		err = batcher.Finish(ctx, db)
+		batcher.Close()
+		if err != nil {
+			// The span may have been split since the previous request, which
+			// AddSSTable cannot handle. Fall back to regular puts for this chunk.
+			log.Warningf(ctx, "falling back to puts: %v", err)
+			b := &client.Batch{}
+			for i := range kvs[:n] {
+				b.Put(kvs[i].Key, &kvs[i].Value)
+			}
+			if err := db.Run(ctx, b); err != nil {
+				return err
+			}
+		}
+		kvs = kvs[n:]
+	}
+	return nil
+}
+
 func evalAddSSTable(
 	ctx context.Context, batch engine.ReadWriter, cArgs storage.CommandArgs, _ roachpb.Response,
 ) (storage.EvalResult, error) {
@@ -0,0 +1,65 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package engineccl
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// #include <stdlib.h>
+// #include <libroachccl.h>
+import "C"
+
+func init() {
+	// Stores opened with engine.RocksDBConfig.EncryptionKeyPath set are
+	// encrypted at rest by libroachccl.
+	C.DBRegisterEncryptedEnv()
+}
+
+// EncryptionStatus describes the encryption at rest of a store.
+type EncryptionStatus struct {
+	// StoreKeyID identifies the store key the store's encryption registry is
+	// encrypted with. It is "plain" if the registry is not encrypted.
+	StoreKeyID string
+	// Files maps each encrypted file of the store, relative to the store
+	// directory, to the ID of its data key.
+	Files map[string]string
+}
+
+// GetEncryptionStatus returns the encryption status of the store in dir,
+// whose encryption registry is read with the store key in the file at
+// keyPath, or the file at oldKeyPath if the store key is being rotated and
+// the store was not opened since. Either path can be "plain". The store
+// must not be open.
+func GetEncryptionStatus(dir, keyPath, oldKeyPath string) (EncryptionStatus, error) {
+	var storeKeyID, files C.DBString
+	if err := statusToError(C.DBGetEncryptionStatus(
+		goToCSlice([]byte(dir)), goToCSlice([]byte(keyPath)), goToCSlice([]byte(oldKeyPath)),
+		&storeKeyID, &files,
+	)); err != nil {
+		return EncryptionStatus{}, err
+	}
+	status := EncryptionStatus{
+		StoreKeyID: cStringToGoString(storeKeyID),
+		Files:      make(map[string]string),
+	}
+	for _, line := range strings.Split(cStringToGoString(files), "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			return EncryptionStatus{}, errors.Errorf("malformed encryption status line: %q", line)
+		}
+		status.Files[fields[0]] = fields[1]
+	}
+	return status, nil
+}
@@ -0,0 +1,255 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package engineccl
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestEncryptionAtRest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanupFn := testutils.TempDir(t)
+	defer cleanupFn()
+	storeDir := filepath.Join(dir, "store")
+
+	writeKey := func(name string, size int) string {
+		key := make([]byte, size)
+		if _, err := rand.Read(key); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, key, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	key1 := writeKey("key1", 16)
+	key2 := writeKey("key2", 32)
+
+	open := func(keyPath, oldKeyPath string) (*engine.RocksDB, error) {
+		return engine.NewRocksDB(
+			engine.RocksDBConfig{
+				Settings:             cluster.MakeTestingClusterSettings(),
+				Dir:                  storeDir,
+				EncryptionKeyPath:    keyPath,
+				EncryptionOldKeyPath: oldKeyPath,
+			},
+			engine.RocksDBCache{},
+		)
+	}
+
+	key := engine.MakeMVCCMetadataKey([]byte("key"))
+	value := []byte("a distinctive value that must not be found on disk")
+	e, err := open(key1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	e.Close()
+
+	// Neither the SSTable nor the write-ahead log hold the value in
+	// plaintext.
+	files, err := ioutil.ReadDir(storeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		contents, err := ioutil.ReadFile(filepath.Join(storeDir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(contents, value) {
+			t.Errorf("%s contains the value in plaintext", f.Name())
+		}
+	}
+
+	status, err := GetEncryptionStatus(storeDir, key1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.StoreKeyID == "plain" {
+		t.Errorf("expected the encryption registry to be encrypted")
+	}
+	var ssts int
+	for name, dataKeyID := range status.Files {
+		if strings.HasSuffix(name, ".sst") {
+			ssts++
+		}
+		if dataKeyID == status.StoreKeyID {
+			t.Errorf("%s is encrypted with the store key", name)
+		}
+	}
+	if ssts == 0 {
+		t.Errorf("expected an encrypted SSTable, found %v", status.Files)
+	}
+
+	// The store cannot be opened without its store key.
+	if _, err := open(key2, ""); !testutils.IsError(err, "which was not provided") {
+		t.Fatalf("expected a missing store key error, got %v", err)
+	}
+
+	// Nor can it be opened as a plaintext store.
+	if _, err := open("", ""); !testutils.IsError(err, "is encrypted") {
+		t.Fatalf("expected an encrypted store error, got %v", err)
+	}
+
+	// Rotate the store key, and read the value back.
+	e, err = open(key2, key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := e.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("expected %q, got %q", value, v)
+	}
+	e.Close()
+
+	rotated, err := GetEncryptionStatus(storeDir, key2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.StoreKeyID == status.StoreKeyID {
+		t.Errorf("expected the store key to be rotated")
+	}
+	// The data keys of the existing files are unchanged.
+	for name, dataKeyID := range status.Files {
+		if id, ok := rotated.Files[name]; ok && id != dataKeyID {
+			t.Errorf("data key of %s changed from %s to %s", name, dataKeyID, id)
+		}
+	}
+	if _, err := GetEncryptionStatus(storeDir, key1, ""); !testutils.IsError(err, "which was not provided") {
+		t.Fatalf("expected a missing store key error, got %v", err)
+	}
+
+	// The registry cannot be altered without detection.
+	registryPath := filepath.Join(storeDir, "COCKROACHDB_ENCRYPTION_REGISTRY")
+	registry, err := ioutil.ReadFile(registryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := len(registry) - 2
+	if registry[last] == '0' {
+		registry[last] = '1'
+	} else {
+		registry[last] = '0'
+	}
+	if err := ioutil.WriteFile(registryPath, registry, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := open(key2, ""); !testutils.IsError(err, "failed authentication") {
+		t.Fatalf("expected an authentication error, got %v", err)
+	}
+}
+
+// TestEncryptionAtRestIngestedFiles verifies that SSTables written for
+// ingestion, as AddSSTable does, are encrypted at rest.
+func TestEncryptionAtRestIngestedFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanupFn := testutils.TempDir(t)
+	defer cleanupFn()
+	storeDir := filepath.Join(dir, "store")
+
+	storeKey := make([]byte, 16)
+	if _, err := rand.Read(storeKey); err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "key")
+	if err := ioutil.WriteFile(keyPath, storeKey, 0600); err != nil {
+		t.Fatal(err)
+	}
+	e, err := engine.NewRocksDB(
+		engine.RocksDBConfig{
+			Settings:          cluster.MakeTestingClusterSettings(),
+			Dir:               storeDir,
+			EncryptionKeyPath: keyPath,
+		},
+		engine.RocksDBCache{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	if !e.IsEncrypted() {
+		t.Fatal("expected the engine to be encrypted")
+	}
+
+	key := engine.MakeMVCCMetadataKey([]byte("key"))
+	value := []byte("a distinctive value that must not be found on disk")
+	sst, err := engine.MakeRocksDBSstFileWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sst.Close()
+	if err := sst.Add(engine.MVCCKeyValue{Key: key, Value: value}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := sst.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ingestDir := filepath.Join(e.GetAuxiliaryDir(), "sideloading")
+	if err := os.MkdirAll(ingestDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(ingestDir, "i1.t1.ingested")
+	if err := e.WriteFile(path, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.IngestExternalFile(context.Background(), path, true /* move */); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := e.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("expected %q, got %q", value, v)
+	}
+
+	// Neither the file written for ingestion nor the SSTable RocksDB linked
+	// it to hold the value in plaintext.
+	if err := filepath.Walk(storeDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(contents, value) {
+			t.Errorf("%s contains the value in plaintext", path)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
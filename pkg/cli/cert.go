@@ -16,6 +16,8 @@ package cli
 
 import (
 	"fmt"
+	"io/ioutil"
+	"math/big"
 	"os"
 	"strings"
 	"time"
@@ -175,11 +177,21 @@ func runListCerts(cmd *cobra.Command, args []string) error {
 	certTableHeaders := []string{"Usage", "Certificate File", "Key File", "Expires", "Notes", "Error"}
 	var rows [][]string
 
+	revoked := cm.RevocationList()
 	addRow := func(ci *security.CertInfo, notes string) {
 		var errString string
 		if ci.Error != nil {
 			errString = ci.Error.Error()
 		}
+		for _, cert := range ci.ParsedCertificates {
+			if revoked.Contains(cert) {
+				if notes != "" {
+					notes += ", "
+				}
+				notes += "REVOKED"
+				break
+			}
+		}
 		rows = append(rows, []string{
 			ci.FileUsage.String(),
 			ci.Filename,
@@ -226,11 +238,66 @@ func runListCerts(cmd *cobra.Command, args []string) error {
 	return printQueryOutput(os.Stdout, certTableHeaders, newRowSliceIter(rows))
 }
 
+// A revokeCert command adds a certificate to the revocation list of the
+// certs directory.
+var revokeCertCmd = &cobra.Command{
+	Use:   "revoke --certs-dir=<path to cockroach certs dir> <certificate file | serial number>",
+	Short: "revoke a certificate",
+	Long: `
+Add a certificate to the revocation list "<certs-dir>/revoked.txt". The
+certificate is identified either by the path to its file, or by its
+hexadecimal serial number, as printed by "openssl x509 -serial".
+
+Nodes reject TLS connections, both from SQL clients and from other nodes,
+presenting a revoked certificate. The revocation list must be copied to the
+certs directory of every node, which then must be sent SIGHUP to reload it.
+`,
+	RunE: MaybeDecorateGRPCError(runRevokeCert),
+}
+
+// runRevokeCert adds the serial number of a certificate to the revocation
+// list.
+func runRevokeCert(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageAndError(cmd)
+	}
+
+	var serial *big.Int
+	var note string
+	if contents, err := ioutil.ReadFile(args[0]); err == nil {
+		certs, err := security.PEMContentsToX509(contents)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse certificate file %s", args[0])
+		}
+		if len(certs) == 0 {
+			return errors.Errorf("no certificates found in %s", args[0])
+		}
+		serial = certs[0].SerialNumber
+		note = certs[0].Subject.CommonName
+	} else if !os.IsNotExist(err) {
+		return err
+	} else if serial, err = security.ParseSerialNumber(args[0]); err != nil {
+		return errors.Wrapf(err, "%s is neither a certificate file nor a serial number", args[0])
+	}
+
+	added, err := security.RevokeCertificate(baseCfg.SSLCertsDir, serial, note)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke certificate")
+	}
+	if !added {
+		fmt.Fprintf(os.Stdout, "certificate %s was already revoked\n", serial.Text(16))
+	} else {
+		fmt.Fprintf(os.Stdout, "revoked certificate %s\n", serial.Text(16))
+	}
+	return nil
+}
+
 var certCmds = []*cobra.Command{
 	createCACertCmd,
 	createNodeCertCmd,
 	createClientCertCmd,
 	listCertsCmd,
+	revokeCertCmd,
 }
 
 var certCmd = &cobra.Command{
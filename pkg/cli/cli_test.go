@@ -1818,13 +1818,19 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/14
   debug/nodes/1/ranges/15
   debug/nodes/1/ranges/16
+  debug/nodes/1/ranges/17
+  debug/nodes/1/ranges/18
+  debug/nodes/1/ranges/19
   debug/schema/system@details
   debug/schema/system/descriptor
   debug/schema/system/eventlog
   debug/schema/system/jobs
   debug/schema/system/lease
+  debug/schema/system/login_attempts
   debug/schema/system/namespace
+  debug/schema/system/protected_ts_records
   debug/schema/system/rangelog
+  debug/schema/system/role_members
   debug/schema/system/settings
   debug/schema/system/ui
   debug/schema/system/users
@@ -459,6 +459,32 @@ Also, if you use equal signs in the file path to a store, you must use the
 "path" field label.`,
 	}
 
+	EnterpriseEncryption = FlagInfo{
+		Name: "enterprise-encryption",
+		Description: `
+Encrypts the files of a store at rest (enterprise feature). This flag must be
+specified separately for each encrypted store, with the "path" of the store, as
+given to --store, and the "key" field holding the path of the file containing
+the store key, for example:
+<PRE>
+
+  --enterprise-encryption=path=/mnt/ssd01,key=/keys/store.key
+
+</PRE>
+The store key must be 16, 24 or 32 bytes long, for AES-128, AES-192 or AES-256
+respectively. It encrypts the data keys of the store's files, which are
+generated for each file. To rotate the store key, give the path of the new key
+in the "key" field and the path of the previous key in the "old-key" field:
+<PRE>
+
+  --enterprise-encryption=path=/mnt/ssd01,key=/keys/new.key,old-key=/keys/store.key
+
+</PRE>
+The data files are not rewritten, and the previous key is no longer needed once
+the node has started. Either key can be "plain" for no encryption, which makes
+the node write new files in plaintext.`,
+	}
+
 	TempDir = FlagInfo{
 		Name: "temp-dir",
 		Description: `
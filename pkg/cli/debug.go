@@ -569,7 +569,7 @@ func runDebugGCCmd(cmd *cobra.Command, args []string) error {
 		snap := db.NewSnapshot()
 		defer snap.Close()
 		_, info, err := storage.RunGC(context.Background(), &desc, snap, hlc.Timestamp{WallTime: timeutil.Now().UnixNano()},
-			config.GCPolicy{TTLSeconds: 24 * 60 * 60 /* 1 day */}, func(_ hlc.Timestamp, _ *roachpb.Transaction, _ roachpb.PushTxnType) {
+			config.GCPolicy{TTLSeconds: 24 * 60 * 60 /* 1 day */}, hlc.Timestamp{}, func(_ hlc.Timestamp, _ *roachpb.Transaction, _ roachpb.PushTxnType) {
 			}, func(_ []roachpb.Intent, _ storage.ResolveOptions) error { return nil })
 		if err != nil {
 			return err
@@ -893,6 +893,11 @@ func init() {
 	debugCmd.AddCommand(debugCmds...)
 }
 
+// AddDebugCmd adds a command to the debug commands.
+func AddDebugCmd(c *cobra.Command) {
+	debugCmd.AddCommand(c)
+}
+
 var debugCmds = []*cobra.Command{
 	debugKeysCmd,
 	debugRangeDataCmd,
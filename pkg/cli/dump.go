@@ -28,6 +28,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -449,6 +450,10 @@ func dumpTableData(w io.Writer, conn *sqlConn, clusterTS string, md tableMetadat
 					switch ct {
 					case "DATE":
 						d = parser.NewDDateFromTime(t, time.UTC)
+					case "TIME":
+						d = parser.MakeDTime(timeofday.FromTime(t))
+					case "TIME WITH TIME ZONE":
+						d = parser.MakeDTimeTZFromTime(t)
 					case "TIMESTAMP":
 						d = parser.MakeDTimestamp(t, time.Nanosecond)
 					case "TIMESTAMP WITH TIME ZONE":
@@ -222,6 +222,7 @@ func init() {
 		varFlag(f, &serverCfg.Locality, cliflags.Locality)
 
 		varFlag(f, &serverCfg.Stores, cliflags.Store)
+		varFlag(f, &serverCfg.StoreEncryption, cliflags.EnterpriseEncryption)
 		varFlag(f, &serverCfg.MaxOffset, cliflags.MaxOffset)
 
 		// Usage for the unix socket is odd as we use a real file, whereas
@@ -400,6 +400,29 @@ var cacheSizeValue = newBytesOrPercentageValue(&serverCfg.CacheSize, memoryPerce
 var sqlSizeValue = newBytesOrPercentageValue(&serverCfg.SQLMemoryPoolSize, memoryPercentResolver)
 var diskTempStorageSizeValue = newBytesOrPercentageValue(nil /* v */, nil /* percentResolver */)
 
+// checkStoreEncryptionSpecs verifies that each --enterprise-encryption flag
+// refers to a different on-disk store given with --store.
+func checkStoreEncryptionSpecs() error {
+	seen := make(map[string]struct{})
+	for _, es := range serverCfg.StoreEncryption.Specs {
+		if _, ok := seen[es.Path]; ok {
+			return errors.Errorf("--enterprise-encryption was specified twice for store %s", es.Path)
+		}
+		seen[es.Path] = struct{}{}
+		found := false
+		for _, spec := range serverCfg.Stores.Specs {
+			if !spec.InMemory && spec.Path == es.Path {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("--enterprise-encryption refers to unknown store %s", es.Path)
+		}
+	}
+	return nil
+}
+
 // runStart starts the cockroach node using --store as the list of
 // storage devices ("stores") on this machine and --join as the list
 // of other active nodes used to join this node to the cockroach
@@ -416,6 +439,10 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	// Deal with flags that may depend on other flags.
 
+	if err := checkStoreEncryptionSpecs(); err != nil {
+		return err
+	}
+
 	firstStore := serverCfg.Stores.Specs[0]
 	// The temp store size can depend on the location of the first regular store
 	// (if it's expressed as a percentage), so we resolve that flag here.
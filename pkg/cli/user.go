@@ -45,7 +45,8 @@ func runGetUser(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 	return runQueryAndFormatResults(conn, os.Stdout,
-		makeQuery(`SELECT * FROM system.users WHERE username=$1`, args[0]))
+		makeQuery(`SELECT username, "hashedPassword" FROM system.users `+
+			`WHERE username=$1 AND "isRole" IS NOT TRUE`, args[0]))
 }
 
 // A lsUsersCmd command displays a list of users.
@@ -68,7 +69,7 @@ func runLsUsers(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 	return runQueryAndFormatResults(conn, os.Stdout,
-		makeQuery(`SELECT username FROM system.users`))
+		makeQuery(`SELECT username FROM system.users WHERE "isRole" IS NOT TRUE`))
 }
 
 // A rmUserCmd command removes the user for the specified username.
@@ -91,7 +92,7 @@ func runRmUser(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 	return runQueryAndFormatResults(conn, os.Stdout,
-		makeQuery(`DELETE FROM system.users WHERE username=$1`, args[0]))
+		makeQuery(`DELETE FROM system.users WHERE username=$1 AND "isRole" IS NOT TRUE`, args[0]))
 }
 
 // A setUserCmd command creates a new or updates an existing user.
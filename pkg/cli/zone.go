@@ -196,39 +196,117 @@ func queryDescriptorIDPath(conn *sqlConn, names []string) ([]sqlbase.ID, error)
 	return path, nil
 }
 
-func parseZoneName(s string) ([]string, error) {
+func queryTableDescriptor(conn *sqlConn, id sqlbase.ID) (*sqlbase.TableDescriptor, error) {
+	rows, err := makeQuery(`SELECT descriptor FROM system.descriptor WHERE id = $1`, id)(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	vals := make([]driver.Value, 1)
+	if err := rows.Next(vals); err != nil {
+		return nil, err
+	}
+	desc := &sqlbase.Descriptor{}
+	if err := unmarshalProto(vals[0], desc); err != nil {
+		return nil, err
+	}
+	tableDesc := desc.GetTable()
+	if tableDesc == nil {
+		return nil, fmt.Errorf("%q is not a table", desc.GetName())
+	}
+	return tableDesc, nil
+}
+
+// A zoneSpecifier identifies the object a zone config applies to: a database,
+// a table, or an index ("db.table@index") or partition ("db.table.partition")
+// of a table. The zone configs of indexes and partitions are stored as
+// subzones of the zone config of their table.
+type zoneSpecifier struct {
+	names     []string
+	index     string
+	partition string
+}
+
+func (zs zoneSpecifier) isSubzone() bool {
+	return zs.index != "" || zs.partition != ""
+}
+
+// resolveSubzone returns the descriptor of the table with the given ID and
+// the index ID of the subzone designated by the zone specifier.
+func (zs zoneSpecifier) resolveSubzone(
+	conn *sqlConn, tableID sqlbase.ID,
+) (*sqlbase.TableDescriptor, uint32, error) {
+	desc, err := queryTableDescriptor(conn, tableID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if zs.partition != "" {
+		idx, err := desc.FindIndexByPartitionName(zs.partition)
+		if err != nil {
+			return nil, 0, err
+		}
+		return desc, uint32(idx.ID), nil
+	}
+	if desc.PrimaryIndex.Name == zs.index {
+		return desc, uint32(desc.PrimaryIndex.ID), nil
+	}
+	idx, dropped, err := desc.FindIndexByName(zs.index)
+	if err != nil {
+		return nil, 0, err
+	}
+	if dropped {
+		return nil, 0, fmt.Errorf("index %q is being dropped", zs.index)
+	}
+	return desc, uint32(idx.ID), nil
+}
+
+func parseZoneName(s string) (zoneSpecifier, error) {
 	switch t := strings.ToLower(s); s {
 	case defaultZoneName, metaZoneName, timeseriesZoneName, systemZoneName:
-		return []string{t}, nil
+		return zoneSpecifier{names: []string{t}}, nil
+	}
+
+	var zs zoneSpecifier
+	name := s
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		name, zs.index = name[:i], name[i+1:]
+		if zs.index == "" {
+			return zoneSpecifier{}, fmt.Errorf("malformed name: %s", s)
+		}
+	} else if parts := strings.Split(name, "."); len(parts) == 3 {
+		name, zs.partition = strings.Join(parts[:2], "."), parts[2]
 	}
 
 	// TODO(knz): we are passing a name that might not be escaped correctly.
 	// See #8389.
-	tn, err := parser.ParseTableName(s)
+	tn, err := parser.ParseTableName(name)
 	if err != nil {
-		return nil, fmt.Errorf("malformed name: %s", s)
+		return zoneSpecifier{}, fmt.Errorf("malformed name: %s", s)
 	}
 	// This is a bit of a hack: "." is not a valid database name.
 	// We use this to detect when a database name was not specified, in
 	// which case we interpret the table name as a database name below.
 	if err := tn.QualifyWithDatabase("."); err != nil {
-		return nil, err
+		return zoneSpecifier{}, err
 	}
-	var names []string
 	if n := tn.Database(); n != "." {
-		names = append(names, n)
+		zs.names = append(zs.names, n)
+	} else if zs.isSubzone() {
+		return zoneSpecifier{}, fmt.Errorf(
+			"%s: the table of an index or partition must be qualified with its database", s)
 	}
-	names = append(names, tn.Table())
-	return names, nil
+	zs.names = append(zs.names, tn.Table())
+	return zs, nil
 }
 
 // A getZoneCmd command displays a zone config.
 var getZoneCmd = &cobra.Command{
-	Use:   "get [options] <database[.table]>",
+	Use:   "get [options] <database[.table[@index|.partition]]>",
 	Short: "fetches and displays the zone config",
 	Long: `
-Fetches and displays the zone configuration for the specified database or
-table.
+Fetches and displays the zone configuration for the specified database, table,
+index or partition.
 `,
 	RunE: MaybeDecorateGRPCError(runGetZone),
 }
@@ -240,10 +318,11 @@ func runGetZone(cmd *cobra.Command, args []string) error {
 		return usageAndError(cmd)
 	}
 
-	names, err := parseZoneName(args[0])
+	zs, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
+	names := zs.names
 
 	conn, err := getPasswordAndMakeSQLClient()
 	if err != nil {
@@ -265,6 +344,37 @@ func runGetZone(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if zs.isSubzone() {
+		desc, indexID, err := zs.resolveSubzone(conn, path[len(path)-1])
+		if err != nil {
+			return err
+		}
+		// Fall back to the zone config of the index when the partition has no
+		// zone config of its own, and to the zone config of the table when the
+		// index has none either.
+		subzone := zone.GetSubzone(indexID, zs.partition)
+		if subzone == nil {
+			subzone = zone.GetSubzone(indexID, "")
+		}
+		if subzone != nil {
+			if subzone.PartitionName != "" {
+				fmt.Printf("%s.%s\n", strings.Join(names, "."), subzone.PartitionName)
+			} else {
+				idx, err := desc.FindIndexByID(sqlbase.IndexID(indexID))
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s@%s\n", strings.Join(names, "."), idx.Name)
+			}
+			res, err := yaml.Marshal(zone.SubzoneConfig(subzone))
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(res))
+			return nil
+		}
+	}
+
 	if zoneName, ok := specialZonesByID[id]; ok {
 		fmt.Println(zoneName)
 	} else {
@@ -362,10 +472,11 @@ func runLsZones(cmd *cobra.Command, args []string) error {
 
 // A rmZoneCmd command removes a zone config.
 var rmZoneCmd = &cobra.Command{
-	Use:   "rm [options] <database[.table]>",
+	Use:   "rm [options] <database[.table[@index|.partition]]>",
 	Short: "remove a zone config",
 	Long: `
-Remove an existing zone config for the specified database or table.
+Remove an existing zone config for the specified database, table, index or
+partition.
 `,
 	RunE: MaybeDecorateGRPCError(runRmZone),
 }
@@ -375,7 +486,7 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 		return usageAndError(cmd)
 	}
 
-	names, err := parseZoneName(args[0])
+	zs, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -387,7 +498,7 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 	defer conn.Close()
 
 	return conn.ExecTxn(func(conn *sqlConn) error {
-		path, err := queryDescriptorIDPath(conn, names)
+		path, err := queryDescriptorIDPath(conn, zs.names)
 		if err != nil {
 			if err == io.EOF {
 				fmt.Printf("%s not found\n", args[0])
@@ -400,6 +511,22 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("unable to remove special zone %s", args[0])
 		}
 
+		if zs.isSubzone() {
+			desc, indexID, err := zs.resolveSubzone(conn, id)
+			if err != nil {
+				return err
+			}
+			zone, found, err := queryZone(conn, id)
+			if err != nil {
+				return err
+			}
+			if !found || !zone.DeleteSubzone(indexID, zs.partition) {
+				fmt.Printf("%s has no zone config\n", args[0])
+				return nil
+			}
+			return writeTableZone(conn, id, desc, &zone)
+		}
+
 		if err := runQueryAndFormatResults(conn, os.Stdout,
 			makeQuery(`DELETE FROM system.zones WHERE id=$1`, id)); err != nil {
 			return err
@@ -411,16 +538,17 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 
 // A setZoneCmd command creates a new or updates an existing zone config.
 var setZoneCmd = &cobra.Command{
-	Use:   "set [options] <database[.table]> -f file.yaml",
+	Use:   "set [options] <database[.table[@index|.partition]]> -f file.yaml",
 	Short: "create or update zone config for object ID",
 	Long: `
-Create or update the zone config for the specified database or table to the
-specified zone-config from the given file ("-" for stdin).
+Create or update the zone config for the specified database, table, index or
+partition to the specified zone-config from the given file ("-" for stdin).
 
 The zone config format has the following YAML schema:
 
   num_replicas: <num>
   constraints: [comma-separated attribute list]
+  lease_preferences: [[comma-separated attribute list], ...]
   range_min_bytes: <size-in-bytes>
   range_max_bytes: <size-in-bytes>
   gc:
@@ -432,8 +560,25 @@ num_replicas: 3
 constraints: [ssd, -mem]
 EOF
 
+The lease preferences are tried in order: the range lease is placed on a live
+store satisfying all the constraints of the first preference possible. For
+example:
+$ cockroach zone set db.t -f - << EOF
+lease_preferences: [[+region=us-east1], [+region=us-west1]]
+EOF
+
+To keep the rows of a partition of a table on particular nodes, set the zone
+config of the partition, which is named after its table:
+$ cockroach zone set db.t.europe -f - << EOF
+constraints: [+region=eu-west1]
+EOF
+
 Note that the specified zone config is merged with the existing zone config for
-the database or table.
+the database, table, index or partition. Zone configs of indexes and partitions
+are stored in the zone config of their table, which is created from the zone
+config the table inherits if it doesn't exist yet. They only hold the fields
+which were specified for them; the other fields are inherited from the zone
+config of the index of a partition, and then from the zone config of the table.
 `,
 	RunE: MaybeDecorateGRPCError(runSetZone),
 }
@@ -470,13 +615,13 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 
-	names, err := parseZoneName(args[0])
+	zs, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
 
 	return conn.ExecTxn(func(conn *sqlConn) error {
-		path, err := queryDescriptorIDPath(conn, names)
+		path, err := queryDescriptorIDPath(conn, zs.names)
 		if err != nil {
 			if err == io.EOF {
 				fmt.Printf("%s not found\n", args[0])
@@ -503,6 +648,40 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("error reading zone config: %s", err)
 		}
+
+		id := path[len(path)-1]
+		if zs.isSubzone() {
+			desc, indexID, err := zs.resolveSubzone(conn, id)
+			if err != nil {
+				return err
+			}
+			// Merge the specified zone config into the fields the index or
+			// partition already overrides. The other fields keep being
+			// inherited.
+			var subzoneConfig config.ZoneConfig
+			if subzone := zone.GetSubzone(indexID, zs.partition); subzone != nil {
+				subzoneConfig = subzone.Config
+			}
+			if err := yaml.Unmarshal(conf, &subzoneConfig); err != nil {
+				return fmt.Errorf("unable to parse zoneConfig file: %s", err)
+			}
+			zone.SetSubzone(config.Subzone{
+				IndexID:       indexID,
+				PartitionName: zs.partition,
+				Config:        subzoneConfig,
+			})
+			if err := writeTableZone(conn, id, desc, &zone); err != nil {
+				return err
+			}
+
+			res, err := yaml.Marshal(zone.SubzoneConfig(zone.GetSubzone(indexID, zs.partition)))
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(res))
+			return nil
+		}
+
 		if err := yaml.Unmarshal(conf, &zone); err != nil {
 			return fmt.Errorf("unable to parse zoneConfig file: %s", err)
 		}
@@ -516,7 +695,6 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("unable to parse zone config file %q: %s", args[1], err)
 		}
 
-		id := path[len(path)-1]
 		_, _, err = runQuery(conn, makeQuery(
 			`UPSERT INTO system.zones (id, config) VALUES ($1, $2)`,
 			id, buf), false)
@@ -533,6 +711,28 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 	})
 }
 
+// writeTableZone regenerates the subzone spans of the zone config of a table,
+// which must be kept in sync with its subzones, and writes it to system.zones.
+func writeTableZone(
+	conn *sqlConn, id sqlbase.ID, desc *sqlbase.TableDescriptor, zone *config.ZoneConfig,
+) error {
+	var err error
+	if zone.SubzoneSpans, err = sqlbase.GenerateSubzoneSpans(desc, zone.Subzones); err != nil {
+		return err
+	}
+	if err := zone.Validate(); err != nil {
+		return err
+	}
+	buf, err := protoutil.Marshal(zone)
+	if err != nil {
+		return err
+	}
+	_, _, err = runQuery(conn, makeQuery(
+		`UPSERT INTO system.zones (id, config) VALUES ($1, $2)`,
+		id, buf), false)
+	return err
+}
+
 var zoneCmds = []*cobra.Command{
 	getZoneCmd,
 	lsZonesCmd,
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
@@ -168,6 +169,9 @@ func (z ZoneConfig) Validate() error {
 	case 2:
 		return fmt.Errorf("at least 3 replicas are required for multi-replica configurations")
 	}
+	if z.NumNonVoters < 0 {
+		return fmt.Errorf("NumNonVoters %d must not be negative", z.NumNonVoters)
+	}
 	if z.RangeMaxBytes < minRangeMaxBytes {
 		return fmt.Errorf("RangeMaxBytes %d less than minimum allowed %d",
 			z.RangeMaxBytes, minRangeMaxBytes)
@@ -176,6 +180,134 @@ func (z ZoneConfig) Validate() error {
 		return fmt.Errorf("RangeMinBytes %d is greater than or equal to RangeMaxBytes %d",
 			z.RangeMinBytes, z.RangeMaxBytes)
 	}
+	for _, preference := range z.LeasePreferences {
+		if len(preference.Constraints) == 0 {
+			return fmt.Errorf("every lease preference must include at least one constraint")
+		}
+		for _, c := range preference.Constraints {
+			if c.Type == Constraint_POSITIVE {
+				return fmt.Errorf("lease preference constraint %q must be required or prohibited", c.String())
+			}
+		}
+	}
+	for i := range z.Subzones {
+		if len(z.Subzones[i].Config.Subzones) > 0 {
+			return fmt.Errorf("subzones cannot have subzones")
+		}
+		if err := z.SubzoneConfig(&z.Subzones[i]).Validate(); err != nil {
+			return err
+		}
+	}
+	for _, span := range z.SubzoneSpans {
+		if span.SubzoneIndex < 0 || int(span.SubzoneIndex) >= len(z.Subzones) {
+			return fmt.Errorf("subzone span refers to unknown subzone %d", span.SubzoneIndex)
+		}
+	}
+	return nil
+}
+
+// InheritFromParent sets the fields of the zone config which are unset, that
+// is which have their zero value, to those of the parent zone config. The
+// zone configs of indexes and partitions only set the fields they override,
+// so that later changes to the zone config of their table still apply to
+// them. A subzone cannot override a field with its zero value.
+func (z *ZoneConfig) InheritFromParent(parent ZoneConfig) {
+	if z.RangeMinBytes == 0 {
+		z.RangeMinBytes = parent.RangeMinBytes
+	}
+	if z.RangeMaxBytes == 0 {
+		z.RangeMaxBytes = parent.RangeMaxBytes
+	}
+	if z.GC.TTLSeconds == 0 {
+		z.GC = parent.GC
+	}
+	if z.NumReplicas == 0 {
+		z.NumReplicas = parent.NumReplicas
+	}
+	if z.NumNonVoters == 0 {
+		z.NumNonVoters = parent.NumNonVoters
+	}
+	if len(z.Constraints.Constraints) == 0 {
+		z.Constraints = parent.Constraints
+	}
+	if len(z.LeasePreferences) == 0 {
+		z.LeasePreferences = parent.LeasePreferences
+	}
+}
+
+// SubzoneConfig returns the zone config applying to the index or partition of
+// the subzone, which must be one of the subzones of z. The fields which the
+// subzone of a partition doesn't set are inherited from the subzone of its
+// index, if any, and the remaining ones from z.
+func (z *ZoneConfig) SubzoneConfig(subzone *Subzone) ZoneConfig {
+	zone := subzone.Config
+	if subzone.PartitionName != "" {
+		if index := z.GetSubzone(subzone.IndexID, ""); index != nil {
+			zone.InheritFromParent(index.Config)
+		}
+	}
+	zone.InheritFromParent(*z)
+	return zone
+}
+
+// GetSubzone returns the subzone of the given index and partition, or nil if
+// there is none. An empty partition name refers to the whole index.
+func (z *ZoneConfig) GetSubzone(indexID uint32, partition string) *Subzone {
+	for i := range z.Subzones {
+		if z.Subzones[i].IndexID == indexID && z.Subzones[i].PartitionName == partition {
+			return &z.Subzones[i]
+		}
+	}
+	return nil
+}
+
+// SetSubzone adds the subzone, replacing any existing subzone of the same
+// index and partition. The caller is responsible for updating SubzoneSpans.
+func (z *ZoneConfig) SetSubzone(subzone Subzone) {
+	if existing := z.GetSubzone(subzone.IndexID, subzone.PartitionName); existing != nil {
+		existing.Config = subzone.Config
+		return
+	}
+	z.Subzones = append(z.Subzones, subzone)
+}
+
+// DeleteSubzone removes the subzone of the given index and partition and
+// returns whether it existed. The caller is responsible for updating
+// SubzoneSpans, since the indexes of the remaining subzones may change.
+func (z *ZoneConfig) DeleteSubzone(indexID uint32, partition string) bool {
+	for i := range z.Subzones {
+		if z.Subzones[i].IndexID == indexID && z.Subzones[i].PartitionName == partition {
+			z.Subzones = append(z.Subzones[:i], z.Subzones[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteIndexSubzones removes the subzones of the given index and of its
+// partitions and returns whether there were any. The caller is responsible
+// for updating SubzoneSpans.
+func (z *ZoneConfig) DeleteIndexSubzones(indexID uint32) bool {
+	subzones := z.Subzones[:0]
+	for _, subzone := range z.Subzones {
+		if subzone.IndexID != indexID {
+			subzones = append(subzones, subzone)
+		}
+	}
+	deleted := len(subzones) < len(z.Subzones)
+	z.Subzones = subzones
+	return deleted
+}
+
+// GetSubzoneForKeySuffix returns the subzone applying to the key, which must
+// not include the table prefix, or nil if no subzone applies to it.
+func (z *ZoneConfig) GetSubzoneForKeySuffix(keySuffix []byte) *Subzone {
+	for _, span := range z.SubzoneSpans {
+		if bytes.Compare(keySuffix, span.Key) >= 0 && bytes.Compare(keySuffix, span.EndKey) < 0 &&
+			int(span.SubzoneIndex) < len(z.Subzones) {
+			return &z.Subzones[span.SubzoneIndex]
+		}
+	}
 	return nil
 }
 
@@ -350,9 +482,22 @@ func (s SystemConfig) GetLargestObjectID(maxID uint32) (uint32, error) {
 }
 
 // GetZoneConfigForKey looks up the zone config for the range containing 'key'.
+// If the key belongs to an index or partition of a table which has a zone
+// config of its own, that subzone's config is returned.
 // It is the caller's responsibility to ensure that the range does not need to be split.
 func (s SystemConfig) GetZoneConfigForKey(key roachpb.RKey) (ZoneConfig, error) {
 	objectID, ok := ObjectIDForKey(key)
+	if ok && objectID > keys.MaxReservedDescID {
+		zone, err := s.getZoneConfigForID(objectID)
+		if err != nil {
+			return ZoneConfig{}, err
+		}
+		keySuffix := key[len(keys.MakeTablePrefix(objectID)):]
+		if subzone := zone.GetSubzoneForKeySuffix(keySuffix); subzone != nil {
+			return zone.SubzoneConfig(subzone), nil
+		}
+		return zone, nil
+	}
 	if !ok {
 		// Not in the structured data namespace.
 		objectID = keys.RootNamespaceID
@@ -382,12 +527,52 @@ func (s SystemConfig) getZoneConfigForID(id uint32) (ZoneConfig, error) {
 	testingLock.Lock()
 	hook := ZoneConfigHook
 	testingLock.Unlock()
+	if hook == nil {
+		// The hook is only unset in tests which don't depend on zone configs.
+		return DefaultZoneConfig(), nil
+	}
 	if cfg, found, err := hook(s, id); err != nil || found {
 		return cfg, err
 	}
 	return DefaultZoneConfig(), nil
 }
 
+// GetProtectedTimestamp returns the earliest timestamp protected by an
+// unexpired record among the supplied rows of system.protected_ts_records
+// with a span overlapping the supplied span. Returns false if no such record
+// exists.
+func GetProtectedTimestamp(
+	records []roachpb.KeyValue, span roachpb.RSpan, now hlc.Timestamp,
+) (hlc.Timestamp, bool, error) {
+	rawSpan := span.AsRawSpanWithNoLocals()
+	var protected hlc.Timestamp
+	var found bool
+	for _, kv := range records {
+		// Each column of the table is stored in its own column family, and
+		// the record column is the only one holding BYTES.
+		if kv.Value.GetTag() != roachpb.ValueType_BYTES {
+			continue
+		}
+		var record ProtectedTimestampRecord
+		if err := kv.Value.GetProto(&record); err != nil {
+			return hlc.Timestamp{}, false, err
+		}
+		if !now.Less(record.Expiration) {
+			continue
+		}
+		if found && !record.Timestamp.Less(protected) {
+			continue
+		}
+		for _, recordSpan := range record.Spans {
+			if recordSpan.Overlaps(rawSpan) {
+				protected, found = record.Timestamp, true
+				break
+			}
+		}
+	}
+	return protected, found, nil
+}
+
 // StaticSplits is the list of pre-defined split points in the beginning of
 // the keyspace that are there to support separate zone configs for different
 // parts of the system / system config ranges.
@@ -435,7 +620,11 @@ var StaticSplits = []struct {
 // of the system-config tables (i.e. /table/0), and at certain points within the
 // system ranges that come before the system tables. The system-config range is
 // somewhat special in that it can contain multiple SQL tables
-// (/table/0-/table/<max-system-config-desc>) within a single range.
+// (/table/0-/table/<max-system-config-desc>) within a single range. No split
+// is required at the start of a user ID which no longer has a descriptor
+// (e.g. a dropped table), so that the ranges left behind can be merged.
+// Splits are also required at the boundaries of the indexes and partitions
+// of a user table which have zone configs of their own.
 func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKey {
 	// Before dealing with splits necessitated by SQL tables, handle all of the
 	// static splits earlier in the keyspace. Note that this list must be kept in
@@ -466,7 +655,13 @@ func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKe
 		startID = keys.MaxSystemConfigDescID + 1
 	} else {
 		// The start key is either already a split key, or after the split
-		// key for its ID. We can skip straight to the next one.
+		// key for its ID. Unless a subzone of the table requires a split, we
+		// can skip straight to the next one.
+		if startID > keys.MaxReservedDescID {
+			if splitKey := s.subzoneSplitKey(startID, startKey, endKey); splitKey != nil {
+				return splitKey
+			}
+		}
 		startID++
 	}
 
@@ -476,10 +671,13 @@ func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKe
 	// gap.
 
 	// findSplitKey returns the first possible split key between the given range
-	// of IDs.
-	findSplitKey := func(startID, endID uint32) roachpb.RKey {
+	// of IDs. If userSpace is set, IDs without a descriptor are skipped.
+	findSplitKey := func(startID, endID uint32, userSpace bool) roachpb.RKey {
 		// endID could be smaller than startID if we don't have user tables.
 		for id := startID; id <= endID; id++ {
+			if userSpace && !s.hasDescriptor(id) {
+				continue
+			}
 			key := roachpb.RKey(keys.MakeTablePrefix(id))
 			// Skip if this ID matches the provided startKey.
 			if !startKey.Less(key) {
@@ -502,7 +700,7 @@ func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKe
 			log.Errorf(context.TODO(), "unable to determine largest reserved object ID from system config: %s", err)
 			return nil
 		}
-		if splitKey := findSplitKey(startID, endID); splitKey != nil {
+		if splitKey := findSplitKey(startID, endID, false /* userSpace */); splitKey != nil {
 			return splitKey
 		}
 		startID = keys.MaxReservedDescID + 1
@@ -514,7 +712,54 @@ func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKe
 		log.Errorf(context.TODO(), "unable to determine largest object ID from system config: %s", err)
 		return nil
 	}
-	return findSplitKey(startID, endID)
+	return findSplitKey(startID, endID, true /* userSpace */)
+}
+
+// subzoneSplitKey returns the first boundary of a subzone span of the table
+// with the given ID which lies within (startKey, endKey), or nil if there is
+// none.
+func (s SystemConfig) subzoneSplitKey(id uint32, startKey, endKey roachpb.RKey) roachpb.RKey {
+	zone, err := s.getZoneConfigForID(id)
+	if err != nil {
+		log.Errorf(context.TODO(), "unable to determine zone config of table %d: %s", id, err)
+		return nil
+	}
+	tablePrefix := keys.MakeTablePrefix(id)
+	var splitKey roachpb.RKey
+	for _, span := range zone.SubzoneSpans {
+		for _, suffix := range []roachpb.Key{span.Key, span.EndKey} {
+			key := make(roachpb.RKey, 0, len(tablePrefix)+len(suffix))
+			key = append(append(key, tablePrefix...), suffix...)
+			if startKey.Less(key) && key.Less(endKey) && (splitKey == nil || key.Less(splitKey)) {
+				splitKey = key
+			}
+		}
+	}
+	return splitKey
+}
+
+// hasDescriptor returns whether the config contains a descriptor for the
+// given ID.
+func (s SystemConfig) hasDescriptor(id uint32) bool {
+	testingLock.Lock()
+	hook := testingLargestIDHook
+	testingLock.Unlock()
+	if hook != nil {
+		// Tests which bypass GetLargestObjectID do not write descriptors.
+		return true
+	}
+
+	// DescriptorTable.PrimaryIndex.ID
+	key := encoding.EncodeUvarintAscending(keys.MakeTablePrefix(keys.DescriptorTableID), 1)
+	key = encoding.EncodeUvarintAscending(key, uint64(id))
+	index := sort.Search(len(s.Values), func(i int) bool {
+		return bytes.Compare(s.Values[i].Key, key) >= 0
+	})
+	if index == len(s.Values) {
+		return false
+	}
+	descID, err := decodeDescMetadataID(s.Values[index].Key)
+	return err == nil && uint32(descID) == id
 }
 
 // NeedsSplit returns whether the range [startKey, endKey) needs a split due
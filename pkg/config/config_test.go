@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
 
@@ -333,6 +334,9 @@ func TestComputeSplitKeyTableIDs(t *testing.T) {
 		{allSql, keys.MakeTablePrefix(start), keys.MakeTablePrefix(start + 10), start + 1},
 		{allSql, keys.MakeTablePrefix(start - 1), keys.MakeTablePrefix(start + 10), start},
 		{allSql, keys.MakeTablePrefix(start + 4), keys.MakeTablePrefix(start + 10), start + 5},
+		// No splits are needed at IDs without descriptors.
+		{allSql, keys.MakeTablePrefix(start + 1), keys.MakeTablePrefix(start + 5), -1},
+		{allSql, keys.MakeTablePrefix(start + 1), keys.MakeTablePrefix(start + 10), start + 5},
 		{allSql, keys.MakeTablePrefix(start + 5), keys.MakeTablePrefix(start + 10), -1},
 		{allSql, keys.MakeTablePrefix(start + 6), keys.MakeTablePrefix(start + 10), -1},
 		{allSql, testutils.MakeKey(keys.MakeTablePrefix(start), roachpb.RKey("foo")),
@@ -427,6 +431,131 @@ func TestGetZoneConfigForKey(t *testing.T) {
 	}
 }
 
+// TestGetZoneConfigForKeySubzones verifies that the zone config of the index
+// or partition containing a key is returned, and that ranges are split at
+// the boundaries of the subzones.
+func TestGetZoneConfigForKeySubzones(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const id = keys.MaxReservedDescID + 1
+	tablePrefix := keys.MakeTablePrefix(id)
+	index1 := encoding.EncodeUvarintAscending(nil, 1)
+	index2 := encoding.EncodeUvarintAscending(nil, 2)
+	suffix := func(index []byte, s string) roachpb.Key {
+		return roachpb.Key(testutils.MakeKey(index, []byte(s)))
+	}
+	zone := config.ZoneConfig{
+		NumReplicas: 1,
+		Subzones: []config.Subzone{
+			{IndexID: 1, PartitionName: "p", Config: config.ZoneConfig{NumReplicas: 3}},
+			{IndexID: 2, Config: config.ZoneConfig{NumReplicas: 5}},
+		},
+		SubzoneSpans: []config.SubzoneSpan{
+			{Key: suffix(index1, "b"), EndKey: suffix(index1, "c"), SubzoneIndex: 0},
+			{Key: index2, EndKey: roachpb.Key(index2).PrefixEnd(), SubzoneIndex: 1},
+		},
+	}
+
+	originalZoneConfigHook := config.ZoneConfigHook
+	defer func() {
+		config.ZoneConfigHook = originalZoneConfigHook
+	}()
+	config.ZoneConfigHook = func(_ config.SystemConfig, objectID uint32) (config.ZoneConfig, bool, error) {
+		if objectID == id {
+			return zone, true, nil
+		}
+		return config.ZoneConfig{}, false, nil
+	}
+	key := func(keySuffix roachpb.Key) roachpb.RKey {
+		return testutils.MakeKey(tablePrefix, keySuffix)
+	}
+
+	testCases := []struct {
+		key         roachpb.RKey
+		numReplicas int32
+	}{
+		{tablePrefix, 1},
+		{key(suffix(index1, "a")), 1},
+		{key(suffix(index1, "b")), 3},
+		{key(suffix(index1, "bz")), 3},
+		{key(suffix(index1, "c")), 1},
+		{key(index2), 5},
+		{key(suffix(index2, "z")), 5},
+		{key(encoding.EncodeUvarintAscending(nil, 3)), 1},
+	}
+	cfg := config.SystemConfig{}
+	for tcNum, tc := range testCases {
+		zone, err := cfg.GetZoneConfigForKey(tc.key)
+		if err != nil {
+			t.Fatalf("#%d: GetZoneConfigForKey(%v) got error: %v", tcNum, tc.key, err)
+		}
+		if zone.NumReplicas != tc.numReplicas {
+			t.Errorf("#%d: GetZoneConfigForKey(%v) got %d replicas; want %d",
+				tcNum, tc.key, zone.NumReplicas, tc.numReplicas)
+		}
+	}
+
+	splitCases := []struct {
+		start, end roachpb.RKey
+		split      roachpb.RKey
+	}{
+		{tablePrefix, roachpb.RKey(tablePrefix).PrefixEnd(), key(suffix(index1, "b"))},
+		{key(suffix(index1, "b")), roachpb.RKey(tablePrefix).PrefixEnd(), key(suffix(index1, "c"))},
+		{key(suffix(index1, "c")), roachpb.RKey(tablePrefix).PrefixEnd(), key(index2)},
+		{key(index2), roachpb.RKey(tablePrefix).PrefixEnd(), key(roachpb.Key(index2).PrefixEnd())},
+		{key(suffix(index1, "bb")), key(suffix(index1, "bc")), nil},
+		{key(roachpb.Key(index2).PrefixEnd()), roachpb.RKey(tablePrefix).PrefixEnd(), nil},
+	}
+	for tcNum, tc := range splitCases {
+		if split := cfg.ComputeSplitKey(tc.start, tc.end); !split.Equal(tc.split) {
+			t.Errorf("#%d: ComputeSplitKey(%v, %v) got %v; want %v", tcNum, tc.start, tc.end, split, tc.split)
+		}
+	}
+}
+
+// TestSubzoneConfigInheritance verifies that subzones inherit the fields they
+// don't set from the subzone of their index and from the zone config of their
+// table.
+func TestSubzoneConfigInheritance(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ssd := config.Constraints{Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Value: "ssd"}}}
+	zone := config.DefaultZoneConfig()
+	zone.SetSubzone(config.Subzone{IndexID: 1, Config: config.ZoneConfig{Constraints: ssd}})
+	zone.SetSubzone(config.Subzone{IndexID: 1, PartitionName: "p", Config: config.ZoneConfig{NumReplicas: 5}})
+	zone.SetSubzone(config.Subzone{IndexID: 2, PartitionName: "q", Config: config.ZoneConfig{NumReplicas: 5}})
+	if err := zone.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(indexID uint32, partition string, numReplicas int32, constraints config.Constraints) {
+		cfg := zone.SubzoneConfig(zone.GetSubzone(indexID, partition))
+		if cfg.NumReplicas != numReplicas || !reflect.DeepEqual(cfg.Constraints, constraints) {
+			t.Errorf("subzone %d/%q: expected %d replicas and constraints %v, got %d replicas and constraints %v",
+				indexID, partition, numReplicas, constraints, cfg.NumReplicas, cfg.Constraints)
+		}
+		if cfg.RangeMaxBytes != zone.RangeMaxBytes || cfg.GC != zone.GC {
+			t.Errorf("subzone %d/%q: expected the range size and GC policy of the table, got %+v",
+				indexID, partition, cfg)
+		}
+	}
+	check(1, "", 3, ssd)
+	check(1, "p", 5, ssd)
+	check(2, "q", 5, config.Constraints{})
+
+	// Changes to the zone config of the table reach the subzones.
+	zone.NumReplicas = 7
+	zone.RangeMaxBytes *= 2
+	check(1, "", 7, ssd)
+	check(1, "p", 5, ssd)
+
+	// The subzones are validated once complete.
+	zone.SetSubzone(config.Subzone{IndexID: 2, Config: config.ZoneConfig{NumReplicas: 2}})
+	if err := zone.Validate(); !testutils.IsError(err, "at least 3 replicas are required") {
+		t.Fatalf("expected an invalid subzone, got %v", err)
+	}
+}
+
 func TestZoneConfigValidate(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -465,6 +594,51 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"is greater than or equal to RangeMaxBytes",
 		},
+		{
+			config.ZoneConfig{
+				NumReplicas:      1,
+				RangeMaxBytes:    config.DefaultZoneConfig().RangeMaxBytes,
+				LeasePreferences: []config.Constraints{{}},
+			},
+			"every lease preference must include at least one constraint",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   1,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				LeasePreferences: []config.Constraints{
+					{Constraints: []config.Constraint{{Type: config.Constraint_POSITIVE, Value: "ssd"}}},
+				},
+			},
+			"lease preference constraint \"ssd\" must be required or prohibited",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   1,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				LeasePreferences: []config.Constraints{
+					{Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Value: "ssd"}}},
+					{Constraints: []config.Constraint{{Type: config.Constraint_PROHIBITED, Value: "hdd"}}},
+				},
+			},
+			"",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   1,
+				NumNonVoters:  -1,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+			},
+			"NumNonVoters -1 must not be negative",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   3,
+				NumNonVoters:  2,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+			},
+			"",
+		},
 	}
 	for i, c := range testCases {
 		err := c.cfg.Validate()
@@ -504,6 +678,30 @@ func TestZoneConfigMarshalYAML(t *testing.T) {
 				},
 			},
 		},
+		LeasePreferences: []config.Constraints{
+			{
+				Constraints: []config.Constraint{
+					{
+						Type:  config.Constraint_REQUIRED,
+						Key:   "region",
+						Value: "us-east1",
+					},
+				},
+			},
+			{
+				Constraints: []config.Constraint{
+					{
+						Type:  config.Constraint_REQUIRED,
+						Key:   "region",
+						Value: "us-west1",
+					},
+					{
+						Type:  config.Constraint_PROHIBITED,
+						Value: "hdd",
+					},
+				},
+			},
+		},
 	}
 
 	expected := `range_min_bytes: 1
@@ -512,6 +710,7 @@ gc:
   ttlseconds: 1
 num_replicas: 1
 constraints: [foo, +duck=foo, -duck=foo]
+lease_preferences: [[+region=us-east1], [+region=us-west1, -hdd]]
 `
 
 	body, err := yaml.Marshal(original)
@@ -530,3 +729,66 @@ constraints: [foo, +duck=foo, -duck=foo]
 		t.Errorf("yaml.Unmarshal(%q) = %+v; not %+v", body, unmarshaled, original)
 	}
 }
+
+func TestGetProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	table := sqlbase.ProtectedTimestampRecordsTable
+	recordKV := func(id int64, record config.ProtectedTimestampRecord) []roachpb.KeyValue {
+		k := keys.MakeTablePrefix(uint32(table.ID))
+		k = encoding.EncodeUvarintAscending(k, uint64(table.PrimaryIndex.ID))
+		k = encoding.EncodeVarintAscending(k, id)
+		jobIDKV := roachpb.KeyValue{Key: keys.MakeFamilyKey(append([]byte(nil), k...), 2)}
+		jobIDKV.Value.SetInt(id)
+		recKV := roachpb.KeyValue{Key: keys.MakeFamilyKey(append([]byte(nil), k...), 3)}
+		if err := recKV.Value.SetProto(&record); err != nil {
+			t.Fatal(err)
+		}
+		return []roachpb.KeyValue{
+			{Key: keys.MakeFamilyKey(k, 0)},
+			jobIDKV,
+			recKV,
+		}
+	}
+	ts := func(wallTime int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: wallTime}
+	}
+	span := func(start, end string) roachpb.Span {
+		return roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+	}
+
+	var records []roachpb.KeyValue
+	for i, record := range []config.ProtectedTimestampRecord{
+		{Timestamp: ts(5), Spans: []roachpb.Span{span("a", "c")}, Expiration: ts(100)},
+		{Timestamp: ts(3), Spans: []roachpb.Span{span("b", "d"), span("x", "z")}, Expiration: ts(100)},
+		// Expired records protect nothing.
+		{Timestamp: ts(1), Spans: []roachpb.Span{span("a", "z")}, Expiration: ts(50)},
+	} {
+		records = append(records, recordKV(int64(i+1), record)...)
+	}
+
+	testCases := []struct {
+		start, end string
+		now        hlc.Timestamp
+		expTS      hlc.Timestamp
+		expFound   bool
+	}{
+		{"a", "b", ts(10), ts(1), true},
+		{"a", "b", ts(60), ts(5), true},
+		{"b", "c", ts(60), ts(3), true},
+		{"c", "d", ts(60), ts(3), true},
+		{"d", "e", ts(60), hlc.Timestamp{}, false},
+		{"y", "z", ts(60), ts(3), true},
+		{"a", "z", ts(100), hlc.Timestamp{}, false},
+	}
+	for i, tc := range testCases {
+		rspan := roachpb.RSpan{Key: roachpb.RKey(tc.start), EndKey: roachpb.RKey(tc.end)}
+		protected, found, err := config.GetProtectedTimestamp(records, rspan, tc.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if protected != tc.expTS || found != tc.expFound {
+			t.Errorf("%d: expected (%s, %t); got (%s, %t)", i, tc.expTS, tc.expFound, protected, found)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -228,6 +229,11 @@ func (r *RSG) GenerateRandomArg(typ parser.Type) string {
 	case parser.TypeTimestamp, parser.TypeTimestampTZ:
 		t := timeutil.Unix(0, r.Int63())
 		v = fmt.Sprintf(`'%s'`, t.Format(time.RFC3339Nano))
+	case parser.TypeTime:
+		v = fmt.Sprintf(`'%s'`, timeofday.FromInt(r.Int63()))
+	case parser.TypeTimeTZ:
+		d := parser.MakeDTimeTZ(timeofday.FromInt(r.Int63()), int32(r.Intn(24)-12)*60*60)
+		v = fmt.Sprintf(`'%s'`, parser.AsStringWithFlags(d, parser.FmtBareStrings))
 	case parser.TypeBool:
 		v = boolArgs[r.Intn(2)]
 	case parser.TypeDate:
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -439,9 +440,14 @@ func (ds *DistSender) sendSingleRange(
 	// no-op.
 	replicas.OptimizeReplicaOrder(ds.getNodeDescriptor())
 
-	// If this request needs to go to a lease holder and we know who that is, move
-	// it to the front.
-	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) {
+	// If this request is a read old enough to be served by any replica, send
+	// it to the nearest one. Otherwise, if it needs to go to a lease holder
+	// and we know who that is, move it to the front.
+	if ds.canSendToFollower(ba) {
+		if ds.rpcContext != nil {
+			replicas.SortByLatency(ds.getNodeDescriptor(), ds.rpcContext.RemoteClocks.Latency)
+		}
+	} else if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) {
 		if storeID, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(storeID); i >= 0 {
 				replicas.MoveToFront(i)
@@ -468,6 +474,20 @@ func (ds *DistSender) sendSingleRange(
 	return br, pErr
 }
 
+// canSendToFollower returns whether the batch is a read at a timestamp
+// old enough for it to have been closed on all replicas, so that it can
+// be served by a replica other than the lease holder. Should a follower be
+// unable to serve the read after all, it redirects the request to the lease
+// holder.
+func (ds *DistSender) canSendToFollower(ba roachpb.BatchRequest) bool {
+	if !storagebase.BatchCanBeEvaluatedOnFollower(ba) {
+		return false
+	}
+	threshold := storagebase.FollowerReadThreshold(&ds.st.SV, ds.clock.Now())
+	ts := storagebase.FollowerReadTimestamp(ba)
+	return threshold != (hlc.Timestamp{}) && ts != (hlc.Timestamp{}) && !threshold.Less(ts)
+}
+
 // initAndVerifyBatch initializes timestamp-related information and
 // verifies batch constraints before splitting.
 func (ds *DistSender) initAndVerifyBatch(
@@ -15,6 +15,9 @@
 package kv
 
 import (
+	"sort"
+	"time"
+
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/gossip"
@@ -166,3 +169,28 @@ func (rs ReplicaSlice) OptimizeReplicaOrder(nodeDesc *roachpb.NodeDescriptor) {
 		rs.MoveToFront(i)
 	}
 }
+
+// LatencyFunc returns the latency from this node to a node given by its
+// address, and whether a measurement is available.
+type LatencyFunc func(addr string) (time.Duration, bool)
+
+// SortByLatency rearranges the ReplicaSlice so that replicas on nodes with
+// lower measured latency come first. The replica on the local node, if any,
+// is treated as having no latency, and replicas on nodes with unknown latency
+// sort last. The relative order of replicas with equal latency is preserved.
+func (rs ReplicaSlice) SortByLatency(nodeDesc *roachpb.NodeDescriptor, latencyFn LatencyFunc) {
+	latency := func(i int) (time.Duration, bool) {
+		if nodeDesc != nil && rs[i].NodeID == nodeDesc.NodeID {
+			return 0, true
+		}
+		return latencyFn(rs[i].NodeDesc.Address.String())
+	}
+	sort.SliceStable(rs, func(i, j int) bool {
+		li, iOK := latency(i)
+		lj, jOK := latency(j)
+		if iOK != jOK {
+			return iOK
+		}
+		return li < lj
+	})
+}
@@ -16,9 +16,12 @@ package kv
 
 import (
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
 
@@ -166,3 +169,34 @@ func TestMoveLocalReplicaToFront(t *testing.T) {
 	}
 
 }
+
+// TestReplicaSetSortByLatency verifies that SortByLatency orders replicas by
+// the latency to their nodes, with the local node first and nodes of unknown
+// latency last.
+func TestReplicaSetSortByLatency(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	rs := ReplicaSlice(nil)
+	for i := 1; i <= 5; i++ {
+		rs = append(rs, ReplicaInfo{
+			ReplicaDescriptor: roachpb.ReplicaDescriptor{NodeID: roachpb.NodeID(i), StoreID: roachpb.StoreID(i)},
+			NodeDesc: &roachpb.NodeDescriptor{
+				NodeID:  roachpb.NodeID(i),
+				Address: util.MakeUnresolvedAddr("tcp", strconv.Itoa(i)),
+			},
+		})
+	}
+	latencies := map[string]time.Duration{
+		"1": 50 * time.Millisecond,
+		"2": 10 * time.Millisecond,
+		"4": 20 * time.Millisecond,
+	}
+	latencyFn := func(addr string) (time.Duration, bool) {
+		l, ok := latencies[addr]
+		return l, ok
+	}
+	rs.SortByLatency(&roachpb.NodeDescriptor{NodeID: 5}, latencyFn)
+	exp := []roachpb.StoreID{5, 2, 4, 1, 3}
+	if stores := getStores(rs); !reflect.DeepEqual(stores, exp) {
+		t.Errorf("expected order %s, got %s", exp, stores)
+	}
+}
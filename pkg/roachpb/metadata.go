@@ -143,6 +143,33 @@ func (r RangeDescriptor) GetReplicaDescriptorByID(replicaID ReplicaID) (ReplicaD
 	return ReplicaDescriptor{}, false
 }
 
+// Voters returns the replicas which participate in the Raft quorum.
+func (r RangeDescriptor) Voters() []ReplicaDescriptor {
+	return r.replicasOfType(VOTER)
+}
+
+// NonVoters returns the replicas which receive the Raft log without
+// participating in the Raft quorum.
+func (r RangeDescriptor) NonVoters() []ReplicaDescriptor {
+	return r.replicasOfType(NON_VOTER)
+}
+
+// Learners returns the replicas which are in the process of being added to
+// the range as voters.
+func (r RangeDescriptor) Learners() []ReplicaDescriptor {
+	return r.replicasOfType(LEARNER)
+}
+
+func (r RangeDescriptor) replicasOfType(typ ReplicaType) []ReplicaDescriptor {
+	var replicas []ReplicaDescriptor
+	for _, rep := range r.Replicas {
+		if rep.GetType() == typ {
+			replicas = append(replicas, rep)
+		}
+	}
+	return replicas
+}
+
 // IsInitialized returns false if this descriptor represents an
 // uninitialized range.
 // TODO(bdarnell): unify this with Validate().
@@ -210,9 +237,26 @@ func (r ReplicaDescriptor) String() string {
 	} else {
 		fmt.Fprintf(&buf, "%d", r.ReplicaID)
 	}
+	if typ := r.GetType(); typ != VOTER {
+		buf.WriteString(typ.String())
+	}
 	return buf.String()
 }
 
+// GetType returns the type of the replica. Replicas without a type are
+// voters.
+func (r ReplicaDescriptor) GetType() ReplicaType {
+	if r.Type == nil {
+		return VOTER
+	}
+	return *r.Type
+}
+
+// IsVoter returns whether the replica participates in the Raft quorum.
+func (r ReplicaDescriptor) IsVoter() bool {
+	return r.GetType() == VOTER
+}
+
 // Validate performs some basic validation of the contents of a replica descriptor.
 func (r ReplicaDescriptor) Validate() error {
 	if r.NodeID == 0 {
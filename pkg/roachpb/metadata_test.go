@@ -132,6 +132,36 @@ func TestRangeDescriptorMissingReplica(t *testing.T) {
 	}
 }
 
+func TestRangeDescriptorReplicaTypes(t *testing.T) {
+	desc := RangeDescriptor{
+		Replicas: []ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2, Type: NON_VOTER.Enum()},
+			{NodeID: 3, StoreID: 3, ReplicaID: 3, Type: VOTER.Enum()},
+			{NodeID: 4, StoreID: 4, ReplicaID: 4, Type: LEARNER.Enum()},
+		},
+	}
+	if voters := desc.Voters(); len(voters) != 2 ||
+		voters[0].StoreID != 1 || voters[1].StoreID != 3 {
+		t.Errorf("unexpected voters %v", voters)
+	}
+	if nonVoters := desc.NonVoters(); len(nonVoters) != 1 || nonVoters[0].StoreID != 2 {
+		t.Errorf("unexpected non-voters %v", nonVoters)
+	}
+	if learners := desc.Learners(); len(learners) != 1 || learners[0].StoreID != 4 {
+		t.Errorf("unexpected learners %v", learners)
+	}
+	if desc.Replicas[3].IsVoter() {
+		t.Errorf("expected learner %v not to be a voter", desc.Replicas[3])
+	}
+	if s, e := desc.Replicas[1].String(), "(n2,s2):2NON_VOTER"; s != e {
+		t.Errorf("expected %q, got %q", e, s)
+	}
+	if s, e := desc.Replicas[2].String(), "(n3,s3):3"; s != e {
+		t.Errorf("expected %q, got %q", e, s)
+	}
+}
+
 // TestLocalityConversions verifies that setting the value from the CLI short
 // hand format works correctly.
 func TestLocalityConversions(t *testing.T) {
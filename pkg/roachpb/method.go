@@ -133,4 +133,6 @@ const (
 	AdminScatter
 	// AddSSTable links a file into the RocksDB log-structured merge-tree.
 	AddSSTable
+	// RangeStats returns the MVCC statistics for a range.
+	RangeStats
 )
@@ -16,10 +16,12 @@ package security
 
 import (
 	"crypto/tls"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 const (
@@ -33,6 +35,43 @@ const (
 // connection originates from a client or another node in the cluster.
 type UserAuthHook func(string, bool) error
 
+// ErrInvalidPassword is returned by password authentication hooks when the
+// password does not match. Only these failures count towards the lockout of
+// an account.
+var ErrInvalidPassword = errors.New("invalid password")
+
+// PasswordPolicy holds the restrictions on the password authentication of a
+// user.
+type PasswordPolicy struct {
+	// ValidUntil is the time at which the password expires. The password
+	// never expires if it is zero.
+	ValidUntil time.Time
+	// LockedUntil is the time until which password authentication is refused
+	// after too many failed attempts. The account is not locked if it is
+	// zero or in the past.
+	LockedUntil time.Time
+}
+
+// checkLocked returns an error if the account is locked at the given time.
+// It is checked before the password, so that a locked account cannot be
+// used to keep guessing it.
+func (p PasswordPolicy) checkLocked(requestedUser string, now time.Time) error {
+	if now.Before(p.LockedUntil) {
+		return errors.Errorf("account of user %s is locked after too many failed login attempts", requestedUser)
+	}
+	return nil
+}
+
+// checkExpired returns an error if the password has expired at the given
+// time. It is checked after the password, so that the expiration of a
+// password is only revealed to someone who knows it.
+func (p PasswordPolicy) checkExpired(requestedUser string, now time.Time) error {
+	if !p.ValidUntil.IsZero() && !now.Before(p.ValidUntil) {
+		return errors.Errorf("password of user %s has expired", requestedUser)
+	}
+	return nil
+}
+
 // GetCertificateUser extract the username from a client certificate.
 func GetCertificateUser(tlsState *tls.ConnectionState) (string, error) {
 	if tlsState == nil {
@@ -118,8 +157,36 @@ func UserAuthCertHook(insecureMode bool, tlsState *tls.ConnectionState) (UserAut
 }
 
 // UserAuthPasswordHook builds an authentication hook based on the security
-// mode, password, and its potentially matching hash.
-func UserAuthPasswordHook(insecureMode bool, password string, hashedPassword []byte) UserAuthHook {
+// mode, password, its potentially matching hash, and the password policy of
+// the user.
+func UserAuthPasswordHook(
+	insecureMode bool, password string, hashedPassword []byte, policy PasswordPolicy,
+) UserAuthHook {
+	return userAuthPasswordHook(insecureMode, policy, func(requestedUser string) error {
+		// If the requested user has an empty password, disallow authentication.
+		if len(password) == 0 || CompareHashAndPassword(hashedPassword, requestedUser, password) != nil {
+			return ErrInvalidPassword
+		}
+		return nil
+	})
+}
+
+// UserAuthChallengeHook builds an authentication hook based on the security
+// mode, the outcome of a challenge-response password exchange, such as
+// SCRAM-SHA-256, in which the password is never sent to the server, and the
+// password policy of the user. The exchange must fail with
+// ErrInvalidPassword if the client's proof is wrong; other errors, such as
+// malformed messages, are returned unchanged and do not count as failed
+// logins.
+func UserAuthChallengeHook(insecureMode bool, challengeErr error, policy PasswordPolicy) UserAuthHook {
+	return userAuthPasswordHook(insecureMode, policy, func(string) error {
+		return challengeErr
+	})
+}
+
+func userAuthPasswordHook(
+	insecureMode bool, policy PasswordPolicy, checkPassword func(string) error,
+) UserAuthHook {
 	return func(requestedUser string, clientConnection bool) error {
 		if len(requestedUser) == 0 {
 			return errors.New("user is missing")
@@ -137,11 +204,13 @@ func UserAuthPasswordHook(insecureMode bool, password string, hashedPassword []b
 			return errors.Errorf("user %s must use certificate authentication instead of password authentication", RootUser)
 		}
 
-		// If the requested user has an empty password, disallow authentication.
-		if len(password) == 0 || CompareHashAndPassword(hashedPassword, password) != nil {
-			return errors.New("invalid password")
+		now := timeutil.Now()
+		if err := policy.checkLocked(requestedUser, now); err != nil {
+			return err
 		}
-
-		return nil
+		if err := checkPassword(requestedUser); err != nil {
+			return err
+		}
+		return policy.checkExpired(requestedUser, now)
 	}
 }
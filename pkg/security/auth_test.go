@@ -19,12 +19,18 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"fmt"
+	"io"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 // Construct a fake tls.ConnectionState object with one peer certificate
@@ -130,3 +136,59 @@ func TestAuthenticationHook(t *testing.T) {
 		}
 	}
 }
+
+func TestPasswordAuthHookPolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	hashedPassword, err := security.HashPassword("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := timeutil.Now().Add(-time.Hour)
+	future := timeutil.Now().Add(time.Hour)
+
+	testCases := []struct {
+		password string
+		policy   security.PasswordPolicy
+		expected string
+	}{
+		{"abc", security.PasswordPolicy{}, ""},
+		{"abd", security.PasswordPolicy{}, "invalid password"},
+		{"abc", security.PasswordPolicy{ValidUntil: future}, ""},
+		{"abc", security.PasswordPolicy{ValidUntil: past}, "password of user foo has expired"},
+		// Expiration is only reported for the right password.
+		{"abd", security.PasswordPolicy{ValidUntil: past}, "invalid password"},
+		{"abc", security.PasswordPolicy{LockedUntil: past}, ""},
+		{"abc", security.PasswordPolicy{LockedUntil: future}, "account of user foo is locked"},
+		{"abd", security.PasswordPolicy{LockedUntil: future}, "account of user foo is locked"},
+	}
+	for i, tc := range testCases {
+		hook := security.UserAuthPasswordHook(false /* insecure */, tc.password, hashedPassword, tc.policy)
+		if err := hook("foo", true /* clientConnection */); !testutils.IsError(err, tc.expected) {
+			t.Errorf("%d: expected error %q, got %v", i, tc.expected, err)
+		}
+	}
+}
+
+// TestChallengeAuthHookErrors verifies that only wrong proofs are reported
+// as invalid passwords, so that other failures do not count towards the
+// lockout of an account.
+func TestChallengeAuthHookErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	malformed := errors.New("malformed SCRAM client proof")
+
+	testCases := []struct {
+		challengeErr error
+		expected     error
+	}{
+		{nil, nil},
+		{security.ErrInvalidPassword, security.ErrInvalidPassword},
+		{malformed, malformed},
+		{io.EOF, io.EOF},
+	}
+	for i, tc := range testCases {
+		hook := security.UserAuthChallengeHook(false /* insecure */, tc.challengeErr, security.PasswordPolicy{})
+		if err := hook("foo", true /* clientConnection */); err != tc.expected {
+			t.Errorf("%d: expected error %v, got %v", i, tc.expected, err)
+		}
+	}
+}
@@ -16,6 +16,7 @@ package security
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -37,6 +38,12 @@ var (
 	metaNodeExpiration = metric.Metadata{
 		Name: "security.certificate.expiration.node",
 		Help: "Expiration timestamp for the node certificate. 0 means no certificate or error."}
+	metaRevokedSerials = metric.Metadata{
+		Name: "security.certificate.revoked.serials",
+		Help: "Number of certificate serial numbers in the revocation list"}
+	metaRevokedRejections = metric.Metadata{
+		Name: "security.certificate.revoked.rejections",
+		Help: "Number of TLS connections rejected because the peer presented a revoked certificate"}
 )
 
 // CertificateManager lives for the duration of the process and manages certificates and keys.
@@ -64,6 +71,9 @@ type CertificateManager struct {
 	nodeCert    *CertInfo
 	clientCerts map[string]*CertInfo
 
+	// Revoked certificate serial numbers, swapped in during Load().
+	revoked RevocationList
+
 	// TLS configs. Initialized lazily. Wiped on every successful Load().
 	// Server-side config.
 	serverConfig *tls.Config
@@ -76,16 +86,20 @@ type CertificateManager struct {
 // These are initialized when the certificate manager is created and updated
 // on reload.
 type CertificateMetrics struct {
-	CAExpiration   *metric.Gauge
-	NodeExpiration *metric.Gauge
+	CAExpiration      *metric.Gauge
+	NodeExpiration    *metric.Gauge
+	RevokedSerials    *metric.Gauge
+	RevokedRejections *metric.Counter
 }
 
 func makeCertificateManager(certsDir string) *CertificateManager {
 	cm := &CertificateManager{certsDir: os.ExpandEnv(certsDir)}
 	// Initialize metrics:
 	cm.certMetrics = CertificateMetrics{
-		CAExpiration:   metric.NewGauge(metaCAExpiration),
-		NodeExpiration: metric.NewGauge(metaNodeExpiration),
+		CAExpiration:      metric.NewGauge(metaCAExpiration),
+		NodeExpiration:    metric.NewGauge(metaNodeExpiration),
+		RevokedSerials:    metric.NewGauge(metaRevokedSerials),
+		RevokedRejections: metric.NewCounter(metaRevokedRejections),
 	}
 	return cm
 }
@@ -195,13 +209,25 @@ func (cm *CertificateManager) ClientCerts() map[string]*CertInfo {
 	return cm.clientCerts
 }
 
-// LoadCertificates creates a CertificateLoader to load all certs and keys.
+// RevocationList returns the revoked certificate serial numbers.
+func (cm *CertificateManager) RevocationList() RevocationList {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.revoked
+}
+
+// LoadCertificates creates a CertificateLoader to load all certs and keys,
+// and loads the revocation list.
 // Upon success, it swaps the existing certificates for the new ones.
 func (cm *CertificateManager) LoadCertificates() error {
 	cl := NewCertificateLoader(cm.certsDir)
 	if err := cl.Load(); err != nil {
 		return errors.Wrapf(err, "problem loading certs directory %s", cm.certsDir)
 	}
+	revoked, err := LoadRevocationList(cm.certsDir)
+	if err != nil {
+		return err
+	}
 
 	var caCert, nodeCert *CertInfo
 	clientCerts := make(map[string]*CertInfo)
@@ -232,6 +258,7 @@ func (cm *CertificateManager) LoadCertificates() error {
 	cm.caCert = caCert
 	cm.nodeCert = nodeCert
 	cm.clientCerts = clientCerts
+	cm.revoked = revoked
 	cm.initialized = true
 
 	cm.serverConfig = nil
@@ -266,6 +293,36 @@ func (cm *CertificateManager) updateMetricsLocked() {
 			cm.certMetrics.NodeExpiration.Update(0)
 		}
 	}
+
+	if cm.certMetrics.RevokedSerials != nil {
+		cm.certMetrics.RevokedSerials.Update(int64(len(cm.revoked)))
+	}
+}
+
+// verifyPeerCertificate is the tls.Config.VerifyPeerCertificate callback of
+// the configs built by the certificate manager. It rejects peers presenting
+// a certificate chain containing a revoked certificate. It is only called
+// with chains already verified against the CA, and always consults the
+// latest revocation list, so cached configs need not be rebuilt on reload.
+func (cm *CertificateManager) verifyPeerCertificate(
+	_ [][]byte, verifiedChains [][]*x509.Certificate,
+) error {
+	revoked := cm.RevocationList()
+	if len(revoked) == 0 {
+		return nil
+	}
+	for _, chain := range verifiedChains {
+		for _, cert := range chain {
+			if revoked.Contains(cert) {
+				if cm.certMetrics.RevokedRejections != nil {
+					cm.certMetrics.RevokedRejections.Inc(1)
+				}
+				return errors.Errorf("certificate %q with serial number %s has been revoked",
+					cert.Subject.CommonName, canonicalSerial(cert.SerialNumber))
+			}
+		}
+	}
+	return nil
 }
 
 // GetServerTLSConfig returns a server TLS config with a callback to fetch the
@@ -307,6 +364,7 @@ func (cm *CertificateManager) GetEmbeddedServerTLSConfig(
 	if err != nil {
 		return nil, err
 	}
+	cfg.VerifyPeerCertificate = cm.verifyPeerCertificate
 
 	cm.serverConfig = cfg
 	return cfg, nil
@@ -380,6 +438,8 @@ func (cm *CertificateManager) GetClientTLSConfig(user string) (*tls.Config, erro
 	if err != nil {
 		return nil, err
 	}
+	// Nodes also reject other nodes presenting revoked certificates.
+	cfg.VerifyPeerCertificate = cm.verifyPeerCertificate
 
 	// Cache the config.
 	cm.clientConfig = cfg
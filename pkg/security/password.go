@@ -16,7 +16,10 @@ package security
 
 import (
 	"bytes"
+	"crypto/md5"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 	"os"
 
@@ -34,18 +37,60 @@ const bcryptCost = bcrypt.DefaultCost
 // ErrEmptyPassword indicates that an empty password was attempted to be set.
 var ErrEmptyPassword = errors.New("empty passwords are not permitted")
 
+// md5Prefix prefixes MD5 password hashes, as in Postgres.
+const md5Prefix = "md5"
+
 // CompareHashAndPassword tests that the provided bytes are equivalent to the
 // hash of the supplied password. If they are not equivalent, returns an
-// error.
-func CompareHashAndPassword(hashedPassword []byte, password string) error {
-	h := sha256.New()
-	return bcrypt.CompareHashAndPassword(hashedPassword, h.Sum([]byte(password)))
+// error. The hash may be a SCRAM-SHA-256 secret, an MD5 hash (which is salted
+// with the username) or a legacy bcrypt hash.
+func CompareHashAndPassword(hashedPassword []byte, username, password string) error {
+	switch {
+	case IsScramHash(hashedPassword):
+		return compareScramSecretAndPassword(hashedPassword, password)
+	case IsMD5Hash(hashedPassword):
+		if subtle.ConstantTimeCompare(HashPasswordMD5(username, password), hashedPassword) != 1 {
+			return errors.New("password does not match")
+		}
+		return nil
+	default:
+		h := sha256.New()
+		return bcrypt.CompareHashAndPassword(hashedPassword, h.Sum([]byte(password)))
+	}
 }
 
-// HashPassword takes a raw password and returns a bcrypt hashed password.
+// HashPassword takes a raw password and returns a SCRAM-SHA-256 secret.
 func HashPassword(password string) ([]byte, error) {
-	h := sha256.New()
-	return bcrypt.GenerateFromPassword(h.Sum([]byte(password)), bcryptCost)
+	return HashPasswordSCRAM(password)
+}
+
+// HashPasswordMD5 returns the Postgres MD5 hash of a password, which is
+// "md5" followed by the hex-encoded MD5 of the password and username. It is
+// only meant for legacy clients that do not support SCRAM-SHA-256.
+func HashPasswordMD5(username, password string) []byte {
+	sum := md5.Sum([]byte(password + username))
+	return []byte(md5Prefix + hex.EncodeToString(sum[:]))
+}
+
+// IsMD5Hash returns true if the stored hash was produced by HashPasswordMD5.
+func IsMD5Hash(hashedPassword []byte) bool {
+	return len(hashedPassword) == len(md5Prefix)+2*md5.Size &&
+		bytes.HasPrefix(hashedPassword, []byte(md5Prefix))
+}
+
+// IsBCryptHash returns true if the stored hash is a legacy bcrypt hash. Such
+// hashes can only be checked against a cleartext password.
+func IsBCryptHash(hashedPassword []byte) bool {
+	_, err := bcrypt.Cost(hashedPassword)
+	return err == nil
+}
+
+// MD5Challenge computes the response a client sends to an MD5 password
+// challenge, given the stored MD5 hash and the 4-byte salt sent by the
+// server: "md5" followed by the hex-encoded MD5 of the hash and the salt.
+func MD5Challenge(hashedPassword []byte, salt []byte) string {
+	sum := md5.Sum(append(append([]byte(nil), hashedPassword[len(md5Prefix):]...), salt...))
+	return md5Prefix + hex.EncodeToString(sum[:])
 }
 
 // PromptForPassword prompts for a password.
@@ -88,8 +133,8 @@ func PromptForPasswordTwice() (string, error) {
 	return string(one), nil
 }
 
-// PromptForPasswordAndHash prompts for a password twice and returns the bcrypt
-// hash.
+// PromptForPasswordAndHash prompts for a password twice and returns its
+// SCRAM-SHA-256 secret.
 func PromptForPasswordAndHash() ([]byte, error) {
 	password, err := PromptForPasswordTwice()
 	if err != nil {
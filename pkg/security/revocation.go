@@ -0,0 +1,151 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RevocationListFilename is the name of the file, in the certs directory,
+// listing the serial numbers of revoked certificates. It holds one
+// hexadecimal serial number per line; anything following a '#' is a
+// comment.
+//
+// The list is a plain file rather than a system table because it must be
+// consulted during TLS handshakes, including those of the RPC connections
+// the system tables are read over. Like certificates, it is reloaded on
+// SIGHUP, and must be distributed to the certs directory of every node.
+const RevocationListFilename = "revoked.txt"
+
+const revocationListFileMode = 0644
+
+// RevocationList is a set of revoked certificate serial numbers, keyed by
+// their canonical hexadecimal representation.
+type RevocationList map[string]struct{}
+
+// canonicalSerial returns the canonical representation of a serial number.
+func canonicalSerial(serial *big.Int) string {
+	return serial.Text(16)
+}
+
+// ParseSerialNumber parses a hexadecimal certificate serial number. Bytes
+// may be separated by colons, as printed by openssl, and the number may be
+// prefixed with 0x.
+func ParseSerialNumber(s string) (*big.Int, error) {
+	hex := strings.ToLower(strings.Replace(strings.TrimSpace(s), ":", "", -1))
+	hex = strings.TrimPrefix(hex, "0x")
+	serial, ok := new(big.Int).SetString(hex, 16)
+	if !ok || len(hex) == 0 || serial.Sign() < 0 {
+		return nil, errors.Errorf("invalid certificate serial number %q", s)
+	}
+	return serial, nil
+}
+
+// Contains returns whether the certificate is revoked.
+func (rl RevocationList) Contains(cert *x509.Certificate) bool {
+	if len(rl) == 0 || cert.SerialNumber == nil {
+		return false
+	}
+	_, ok := rl[canonicalSerial(cert.SerialNumber)]
+	return ok
+}
+
+// LoadRevocationList reads the revocation list from the certs directory.
+// A missing file denotes an empty list.
+func LoadRevocationList(certsDir string) (RevocationList, error) {
+	path := filepath.Join(certsDir, RevocationListFilename)
+	if _, err := assetLoaderImpl.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return RevocationList{}, nil
+		}
+		return nil, errors.Wrapf(err, "could not stat revocation list %s", path)
+	}
+	contents, err := assetLoaderImpl.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read revocation list %s", path)
+	}
+
+	rl := RevocationList{}
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		serial, err := ParseSerialNumber(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s:%d", path, lineNum)
+		}
+		rl[canonicalSerial(serial)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "could not read revocation list %s", path)
+	}
+	return rl, nil
+}
+
+// RevokeCertificate adds the serial number to the revocation list in the
+// certs directory, creating it if needed. The note, if not empty, is
+// recorded as a comment. It returns false if the serial number was already
+// revoked.
+func RevokeCertificate(certsDir string, serial *big.Int, note string) (bool, error) {
+	rl, err := LoadRevocationList(certsDir)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := rl[canonicalSerial(serial)]; ok {
+		return false, nil
+	}
+
+	path := filepath.Join(certsDir, RevocationListFilename)
+	contents, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, errors.Wrapf(err, "could not read revocation list %s", path)
+	}
+	var buf bytes.Buffer
+	buf.Write(contents)
+	if len(contents) > 0 && contents[len(contents)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(canonicalSerial(serial))
+	if note = strings.TrimSpace(note); note != "" {
+		fmt.Fprintf(&buf, " # %s", strings.Replace(note, "\n", " ", -1))
+	}
+	buf.WriteByte('\n')
+
+	// Write to a temporary file first, so that a node reloading its
+	// certificates never sees a partially written list.
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, buf.Bytes(), revocationListFileMode); err != nil {
+		return false, errors.Wrapf(err, "could not write revocation list %s", tmpPath)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return false, errors.Wrapf(err, "could not write revocation list %s", path)
+	}
+	return true, nil
+}
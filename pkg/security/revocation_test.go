@@ -0,0 +1,181 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security_test
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseSerialNumber(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		input    string
+		expected int64
+		err      string
+	}{
+		{"1f", 0x1f, ""},
+		{"0x1F", 0x1f, ""},
+		{"01:00", 0x100, ""},
+		{" abc ", 0xabc, ""},
+		{"", 0, "invalid certificate serial number"},
+		{"0x", 0, "invalid certificate serial number"},
+		{"xyz", 0, "invalid certificate serial number"},
+		{"-1", 0, "invalid certificate serial number"},
+	}
+	for _, tc := range testCases {
+		serial, err := security.ParseSerialNumber(tc.input)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%q: expected error %q, got %v", tc.input, tc.err, err)
+			continue
+		}
+		if err == nil && serial.Cmp(big.NewInt(tc.expected)) != 0 {
+			t.Errorf("%q: expected %x, got %x", tc.input, tc.expected, serial)
+		}
+	}
+}
+
+func TestRevokeCertificate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Do not mock cert access for this test.
+	security.ResetAssetLoader()
+	defer ResetTest()
+	certsDir, err := ioutil.TempDir("", "revocation_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(certsDir); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if err := generateAllCerts(certsDir); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := security.NewCertificateManager(certsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// handshake connects a client using the certificate of user to the
+	// server.
+	handshake := func(user string) error {
+		serverCfg, err := cm.GetServerTLSConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCfg, err := cm.GetClientTLSConfig(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCfg = clientCfg.Clone()
+		clientCfg.ServerName = "127.0.0.1"
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		errCh := make(chan error, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			defer conn.Close()
+			errCh <- tls.Server(conn, serverCfg).Handshake()
+		}()
+		conn, err := tls.Dial("tcp", ln.Addr().String(), clientCfg)
+		if err == nil {
+			defer conn.Close()
+		}
+		return <-errCh
+	}
+
+	if err := handshake(security.RootUser); err != nil {
+		t.Fatalf("expected successful handshake, got %v", err)
+	}
+	if a := cm.Metrics().RevokedSerials.Value(); a != 0 {
+		t.Errorf("expected no revoked serials, got %d", a)
+	}
+
+	rootCert := cm.ClientCerts()[security.RootUser].ParsedCertificates[0]
+	for i, expected := range []bool{true, false} {
+		added, err := security.RevokeCertificate(certsDir, rootCert.SerialNumber, "root")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if added != expected {
+			t.Errorf("%d: expected added=%t, got %t", i, expected, added)
+		}
+	}
+	contents, err := ioutil.ReadFile(filepath.Join(certsDir, security.RevocationListFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := rootCert.SerialNumber.Text(16)+" # root\n", string(contents); e != a {
+		t.Errorf("expected revocation list %q, got %q", e, a)
+	}
+
+	// The revocation list is only consulted once reloaded.
+	if err := handshake(security.RootUser); err != nil {
+		t.Fatalf("expected successful handshake, got %v", err)
+	}
+	if err := cm.LoadCertificates(); err != nil {
+		t.Fatal(err)
+	}
+	if !cm.RevocationList().Contains(rootCert) {
+		t.Errorf("expected the root certificate to be revoked")
+	}
+	if a := cm.Metrics().RevokedSerials.Value(); a != 1 {
+		t.Errorf("expected 1 revoked serial, got %d", a)
+	}
+
+	if err := handshake(security.RootUser); !testutils.IsError(err, "has been revoked") {
+		t.Fatalf("expected revoked certificate error, got %v", err)
+	}
+	if a := cm.Metrics().RevokedRejections.Count(); a != 1 {
+		t.Errorf("expected 1 rejection, got %d", a)
+	}
+	// Other certificates are unaffected.
+	if err := handshake(security.NodeUser); err != nil {
+		t.Fatalf("expected successful handshake, got %v", err)
+	}
+
+	// A malformed revocation list fails the reload.
+	if err := ioutil.WriteFile(
+		filepath.Join(certsDir, security.RevocationListFilename), []byte("not-hex\n"), 0644,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.LoadCertificates(); !testutils.IsError(err, "invalid certificate serial number") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+	if !cm.RevocationList().Contains(rootCert) {
+		t.Errorf("expected the previous revocation list to be kept")
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ScramSHA256 is the name of the SASL mechanism implemented by ScramServer.
+const ScramSHA256 = "SCRAM-SHA-256"
+
+const (
+	// scramIterations is the iteration count used for new SCRAM secrets. It is
+	// the minimum recommended by RFC 7677 and the default used by Postgres.
+	scramIterations = 4096
+	scramSaltLen    = 16
+	scramNonceLen   = 18
+)
+
+// ScramSecret holds the salted keys that are stored in place of a password
+// for SCRAM-SHA-256 authentication (RFC 5802, RFC 7677). The password
+// itself cannot be recovered from it.
+type ScramSecret struct {
+	Iterations int
+	Salt       []byte
+	StoredKey  []byte
+	ServerKey  []byte
+}
+
+// MakeScramSecret derives a ScramSecret from a password and salt.
+//
+// Passwords are used as raw bytes: SASLprep normalization is not applied, so
+// clients must send non-ASCII passwords in the same form they were set in.
+func MakeScramSecret(password string, salt []byte, iterations int) ScramSecret {
+	saltedPassword := scramHi([]byte(password), salt, iterations)
+	clientKey := scramHMAC(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	return ScramSecret{
+		Iterations: iterations,
+		Salt:       salt,
+		StoredKey:  storedKey[:],
+		ServerKey:  scramHMAC(saltedPassword, []byte("Server Key")),
+	}
+}
+
+// Encode returns the representation of the secret stored in system.users,
+// which is the same as the one Postgres uses:
+//   SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+func (s ScramSecret) Encode() []byte {
+	enc := base64.StdEncoding
+	return []byte(fmt.Sprintf("%s$%d:%s$%s:%s", ScramSHA256, s.Iterations,
+		enc.EncodeToString(s.Salt), enc.EncodeToString(s.StoredKey), enc.EncodeToString(s.ServerKey)))
+}
+
+// IsScramHash returns true if the stored hash is a SCRAM-SHA-256 secret.
+func IsScramHash(hashedPassword []byte) bool {
+	return bytes.HasPrefix(hashedPassword, []byte(ScramSHA256+"$"))
+}
+
+// ParseScramSecret decodes a secret produced by ScramSecret.Encode.
+func ParseScramSecret(hashedPassword []byte) (ScramSecret, error) {
+	var s ScramSecret
+	if !IsScramHash(hashedPassword) {
+		return s, errors.New("not a SCRAM-SHA-256 secret")
+	}
+	parts := strings.Split(string(hashedPassword[len(ScramSHA256)+1:]), "$")
+	if len(parts) != 2 {
+		return s, errors.New("malformed SCRAM-SHA-256 secret")
+	}
+	iterSalt := strings.SplitN(parts[0], ":", 2)
+	keys := strings.SplitN(parts[1], ":", 2)
+	if len(iterSalt) != 2 || len(keys) != 2 {
+		return s, errors.New("malformed SCRAM-SHA-256 secret")
+	}
+	var err error
+	if s.Iterations, err = strconv.Atoi(iterSalt[0]); err != nil || s.Iterations <= 0 {
+		return s, errors.New("malformed SCRAM-SHA-256 iteration count")
+	}
+	enc := base64.StdEncoding
+	if s.Salt, err = enc.DecodeString(iterSalt[1]); err != nil {
+		return s, errors.Wrap(err, "malformed SCRAM-SHA-256 salt")
+	}
+	if s.StoredKey, err = enc.DecodeString(keys[0]); err != nil {
+		return s, errors.Wrap(err, "malformed SCRAM-SHA-256 stored key")
+	}
+	if s.ServerKey, err = enc.DecodeString(keys[1]); err != nil {
+		return s, errors.Wrap(err, "malformed SCRAM-SHA-256 server key")
+	}
+	return s, nil
+}
+
+// HashPasswordSCRAM returns the encoded SCRAM-SHA-256 secret for a password,
+// using a random salt.
+func HashPasswordSCRAM(password string) ([]byte, error) {
+	salt := make([]byte, scramSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return MakeScramSecret(password, salt, scramIterations).Encode(), nil
+}
+
+// compareScramSecretAndPassword checks a cleartext password against a stored
+// SCRAM secret.
+func compareScramSecretAndPassword(hashedPassword []byte, password string) error {
+	s, err := ParseScramSecret(hashedPassword)
+	if err != nil {
+		return err
+	}
+	computed := MakeScramSecret(password, s.Salt, s.Iterations)
+	if subtle.ConstantTimeCompare(computed.StoredKey, s.StoredKey) != 1 {
+		return errors.New("password does not match")
+	}
+	return nil
+}
+
+// ScramServer implements the server side of a SCRAM-SHA-256 exchange. Channel
+// binding is not supported. The username sent by the client is ignored, as
+// the user was already named in the startup message.
+type ScramServer struct {
+	secret ScramSecret
+	// nonce is the server's part of the combined nonce. It is generated
+	// randomly if left empty.
+	nonce string
+
+	gs2Header       string
+	clientFirstBare string
+	serverFirst     string
+	combinedNonce   string
+}
+
+// NewScramServer creates a ScramServer which authenticates against secret.
+func NewScramServer(secret ScramSecret) *ScramServer {
+	return &ScramServer{secret: secret}
+}
+
+// ServerFirst processes the client-first-message and returns the
+// server-first-message.
+func (s *ScramServer) ServerFirst(clientFirst []byte) ([]byte, error) {
+	// client-first-message = gs2-header client-first-message-bare, where
+	// gs2-header = gs2-cbind-flag "," [ authzid ] ",".
+	msg := string(clientFirst)
+	switch {
+	case strings.HasPrefix(msg, "n,"), strings.HasPrefix(msg, "y,"):
+	case strings.HasPrefix(msg, "p="):
+		return nil, errors.New("SCRAM channel binding is not supported")
+	default:
+		return nil, errors.New("malformed SCRAM client-first-message")
+	}
+	i := strings.IndexByte(msg[2:], ',')
+	if i < 0 {
+		return nil, errors.New("malformed SCRAM client-first-message")
+	}
+	if i > 0 {
+		return nil, errors.New("SCRAM authorization identity is not supported")
+	}
+	s.gs2Header = msg[:3]
+	s.clientFirstBare = msg[3:]
+
+	var clientNonce string
+	for _, attr := range strings.Split(s.clientFirstBare, ",") {
+		if strings.HasPrefix(attr, "m=") {
+			return nil, errors.New("unsupported SCRAM extension")
+		}
+		if strings.HasPrefix(attr, "r=") {
+			clientNonce = attr[2:]
+		}
+	}
+	if clientNonce == "" {
+		return nil, errors.New("SCRAM client-first-message is missing the nonce")
+	}
+
+	if s.nonce == "" {
+		b := make([]byte, scramNonceLen)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		s.nonce = base64.StdEncoding.EncodeToString(b)
+	}
+	s.combinedNonce = clientNonce + s.nonce
+	s.serverFirst = fmt.Sprintf("r=%s,s=%s,i=%d", s.combinedNonce,
+		base64.StdEncoding.EncodeToString(s.secret.Salt), s.secret.Iterations)
+	return []byte(s.serverFirst), nil
+}
+
+// ServerFinal processes the client-final-message, verifies the client proof
+// and returns the server-final-message. An error is returned if the proof
+// does not match the stored secret.
+func (s *ScramServer) ServerFinal(clientFinal []byte) ([]byte, error) {
+	msg := string(clientFinal)
+	i := strings.LastIndex(msg, ",p=")
+	if i < 0 {
+		return nil, errors.New("SCRAM client-final-message is missing the proof")
+	}
+	withoutProof, proofB64 := msg[:i], msg[i+len(",p="):]
+
+	var channelBinding, nonce string
+	for _, attr := range strings.Split(withoutProof, ",") {
+		switch {
+		case strings.HasPrefix(attr, "c="):
+			channelBinding = attr[2:]
+		case strings.HasPrefix(attr, "r="):
+			nonce = attr[2:]
+		}
+	}
+	if channelBinding != base64.StdEncoding.EncodeToString([]byte(s.gs2Header)) {
+		return nil, errors.New("SCRAM channel binding does not match")
+	}
+	if nonce != s.combinedNonce {
+		return nil, errors.New("SCRAM nonce does not match")
+	}
+	proof, err := base64.StdEncoding.DecodeString(proofB64)
+	if err != nil || len(proof) != sha256.Size {
+		return nil, errors.New("malformed SCRAM client proof")
+	}
+
+	authMessage := []byte(s.clientFirstBare + "," + s.serverFirst + "," + withoutProof)
+	clientSignature := scramHMAC(s.secret.StoredKey, authMessage)
+	clientKey := make([]byte, len(proof))
+	for i := range proof {
+		clientKey[i] = proof[i] ^ clientSignature[i]
+	}
+	storedKey := sha256.Sum256(clientKey)
+	if subtle.ConstantTimeCompare(storedKey[:], s.secret.StoredKey) != 1 {
+		return nil, ErrInvalidPassword
+	}
+
+	serverSignature := scramHMAC(s.secret.ServerKey, authMessage)
+	return []byte("v=" + base64.StdEncoding.EncodeToString(serverSignature)), nil
+}
+
+func scramHMAC(key, msg []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// scramHi is the Hi() function of RFC 5802, which is PBKDF2 with
+// HMAC-SHA-256 and a single output block.
+func scramHi(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	_, _ = mac.Write(salt)
+	_, _ = mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+	result := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		_, _ = mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
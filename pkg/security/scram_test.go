@@ -0,0 +1,141 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestScramExchange checks the server side of the SCRAM-SHA-256 exchange
+// against the test vector of RFC 7677, section 3.
+func TestScramExchange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := security.MakeScramSecret("pencil", salt, 4096)
+
+	const (
+		clientFirst = "n,,n=user,r=rOprNGfwEbeRWgbNEkqO"
+		serverFirst = "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+		clientFinal = "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="
+		serverFinal = "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="
+	)
+
+	newServer := func() *security.ScramServer {
+		s := security.NewScramServer(secret)
+		s.SetNonce("%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0")
+		if msg, err := s.ServerFirst([]byte(clientFirst)); err != nil {
+			t.Fatal(err)
+		} else if string(msg) != serverFirst {
+			t.Fatalf("expected %q, got %q", serverFirst, msg)
+		}
+		return s
+	}
+
+	if msg, err := newServer().ServerFinal([]byte(clientFinal)); err != nil {
+		t.Fatal(err)
+	} else if string(msg) != serverFinal {
+		t.Fatalf("expected %q, got %q", serverFinal, msg)
+	}
+
+	// A proof computed from another password is rejected.
+	badProof := "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+		"p=AAAAZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="
+	if _, err := newServer().ServerFinal([]byte(badProof)); !testutils.IsError(err, "invalid password") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The nonce must be the one sent by the server.
+	badNonce := "c=biws,r=rOprNGfwEbeRWgbNEkqO,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="
+	if _, err := newServer().ServerFinal([]byte(badNonce)); !testutils.IsError(err, "nonce does not match") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Channel binding is not supported.
+	s := security.NewScramServer(secret)
+	if _, err := s.ServerFirst([]byte("p=tls-unique,,n=user,r=abc")); !testutils.IsError(err, "channel binding is not supported") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestScramSecretEncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	hashed, err := security.HashPasswordSCRAM("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !security.IsScramHash(hashed) {
+		t.Fatalf("expected a SCRAM secret, got %q", hashed)
+	}
+	secret, err := security.ParseScramSecret(hashed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, e := string(secret.Encode()), string(hashed); a != e {
+		t.Fatalf("expected %q, got %q", e, a)
+	}
+
+	for _, malformed := range []string{
+		"SCRAM-SHA-256$",
+		"SCRAM-SHA-256$4096:c2FsdA==",
+		"SCRAM-SHA-256$x:c2FsdA==$a2V5:a2V5",
+		"SCRAM-SHA-256$4096:!!!$a2V5:a2V5",
+	} {
+		if _, err := security.ParseScramSecret([]byte(malformed)); err == nil {
+			t.Errorf("%q: expected error", malformed)
+		}
+	}
+}
+
+func TestCompareHashAndPassword(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	scram, err := security.HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	md5 := security.HashPasswordMD5("foo", "hunter2")
+	if !security.IsMD5Hash(md5) || security.IsScramHash(md5) || security.IsBCryptHash(md5) {
+		t.Fatalf("%q: wrong hash kind", md5)
+	}
+	// The hash of user "foo" with password "hunter2" as computed by Postgres.
+	if e := "md5100abcfc8e1f5534791924e6d8ae283b"; string(md5) != e {
+		t.Fatalf("expected %q, got %q", e, md5)
+	}
+
+	for _, hashed := range [][]byte{scram, md5} {
+		if err := security.CompareHashAndPassword(hashed, "foo", "hunter2"); err != nil {
+			t.Errorf("%q: %v", hashed, err)
+		}
+		if err := security.CompareHashAndPassword(hashed, "foo", "hunter3"); err == nil {
+			t.Errorf("%q: expected wrong password to be rejected", hashed)
+		}
+	}
+	// MD5 hashes are salted with the username.
+	if err := security.CompareHashAndPassword(md5, "bar", "hunter2"); err == nil {
+		t.Error("expected wrong username to be rejected")
+	}
+}
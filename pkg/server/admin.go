@@ -640,7 +640,7 @@ func (s *adminServer) Users(
 	args := sql.SessionArgs{User: s.getUser(req)}
 	ctx, session := s.NewContextAndSessionForRPC(ctx, args)
 	defer session.Finish(s.server.sqlExecutor)
-	query := "SELECT username FROM system.users"
+	query := `SELECT username FROM system.users WHERE "isRole" IS NOT TRUE`
 	r, err := s.server.sqlExecutor.ExecuteStatementsBuffered(session, query, nil, 1)
 	if err != nil {
 		return nil, s.serverError(err)
@@ -831,6 +831,9 @@ func (s *adminServer) RangeLog(
 			if event.Info.RemovedReplica != nil {
 				prettyInfo.RemovedReplica = event.Info.RemovedReplica.String()
 			}
+			if event.Info.LeaseTarget != nil {
+				prettyInfo.LeaseTarget = event.Info.LeaseTarget.String()
+			}
 			prettyInfo.Reason = string(event.Info.Reason)
 			prettyInfo.Details = event.Info.Details
 		}
@@ -57,17 +57,23 @@ type authenticationServer struct {
 	server     *Server
 	executor   sql.InternalExecutor
 	memMetrics *sql.MemoryMetrics
+	tokenAuth  TokenAuthenticator
 }
 
 // newAuthenticationServer allocates and returns a new REST server for
 // authentication APIs.
 func newAuthenticationServer(s *Server) *authenticationServer {
+	tokenAuth := s.cfg.TokenAuthenticator
+	if tokenAuth == nil {
+		tokenAuth = newJWTAuthenticator(s.st, s.clock.PhysicalTime)
+	}
 	return &authenticationServer{
 		server: s,
 		executor: sql.InternalExecutor{
 			LeaseManager: s.leaseMgr,
 		},
 		memMetrics: &s.adminMemMetrics,
+		tokenAuth:  tokenAuth,
 	}
 }
 
@@ -230,13 +236,14 @@ WHERE id = $1`
 }
 
 // verifyPassword verifies the passed username/password pair against the
-// system.users table. The returned boolean indicates whether or not the
-// verification succeeded; an error is returned if the validation process could
-// not be completed.
+// system.users table, enforcing the password expiration and lockout of the
+// user. The returned boolean indicates whether or not the verification
+// succeeded; an error is returned if the validation process could not be
+// completed.
 func (s *authenticationServer) verifyPassword(
 	ctx context.Context, username string, password string,
 ) (bool, error) {
-	exists, hashedPassword, err := sql.GetUserHashedPassword(
+	exists, hashedPassword, policy, err := sql.GetUserHashedPassword(
 		ctx, s.server.sqlExecutor, s.memMetrics, username,
 	)
 	if err != nil {
@@ -245,7 +252,18 @@ func (s *authenticationServer) verifyPassword(
 	if !exists {
 		return false, nil
 	}
-	return (security.CompareHashAndPassword(hashedPassword, password) == nil), nil
+	// MD5 hashes are salted with the normalized username.
+	normalizedUsername := parser.Name(username).Normalize()
+	hook := security.UserAuthPasswordHook(false /* insecureMode */, password, hashedPassword, policy)
+	err = hook(normalizedUsername, true /* clientConnection */)
+	if err == nil || err == security.ErrInvalidPassword {
+		if recordErr := sql.RecordUserLoginAttempt(
+			ctx, s.server.sqlExecutor, s.memMetrics, normalizedUsername, err == nil,
+		); recordErr != nil {
+			return false, recordErr
+		}
+	}
+	return err == nil, nil
 }
 
 // newAuthSession attempts to create a new authentication session for the given
@@ -314,6 +332,22 @@ func newAuthenticationMux(s *authenticationServer, inner http.Handler) *authenti
 }
 
 func (am *authenticationMux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// API clients which log in through an external identity provider may
+	// present a bearer token instead of a session cookie.
+	if token, ok := bearerToken(req); ok {
+		_, valid, err := am.server.verifyToken(req.Context(), token)
+		if err != nil {
+			http.Error(w, apiInternalError(req.Context(), err).Error(), http.StatusInternalServerError)
+			return
+		}
+		if !valid {
+			http.Error(w, "The provided token could not be validated.", http.StatusUnauthorized)
+			return
+		}
+		am.inner.ServeHTTP(w, req)
+		return
+	}
+
 	// Validate the returned cookie.
 	rawCookie, err := req.Cookie(sessionCookieName)
 	if err != nil {
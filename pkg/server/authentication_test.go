@@ -227,6 +227,79 @@ func TestVerifyPassword(t *testing.T) {
 	}
 }
 
+func TestVerifyPasswordPolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ts := s.(*TestServer)
+
+	for _, stmt := range []string{
+		`SET CLUSTER SETTING server.user_login.max_failed_attempts = 2`,
+		`CREATE USER expired WITH PASSWORD 'hunter2' VALID UNTIL '2000-01-01'`,
+		`CREATE USER valid WITH PASSWORD 'hunter2' VALID UNTIL '2100-01-01'`,
+		`CREATE USER locked WITH PASSWORD 'hunter2'`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	verify := func(username, password string, expected bool) {
+		t.Helper()
+		valid, err := ts.authentication.verifyPassword(context.TODO(), username, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if valid != expected {
+			t.Fatalf("credentials %s/%s valid = %t, wanted %t", username, password, valid, expected)
+		}
+	}
+
+	verify("expired", "hunter2", false)
+	verify("valid", "hunter2", true)
+
+	// Failed attempts are counted outside of system.users, which is part of
+	// the gossiped system config. A success resets the count.
+	verify("locked", "hunter3", false)
+	var failedLogins int
+	if err := db.QueryRow(
+		`SELECT "failedLogins" FROM system.login_attempts WHERE username = 'locked'`,
+	).Scan(&failedLogins); err != nil {
+		t.Fatal(err)
+	}
+	if failedLogins != 1 {
+		t.Fatalf("expected 1 failed login, got %d", failedLogins)
+	}
+	verify("locked", "hunter2", true)
+	verify("locked", "hunter3", false)
+	verify("locked", "hunter2", true)
+
+	// After two consecutive failures, the right password is refused too.
+	verify("locked", "hunter3", false)
+	verify("locked", "hunter3", false)
+	verify("locked", "hunter2", false)
+
+	var locked bool
+	if err := db.QueryRow(
+		`SELECT locked FROM [SHOW USERS] WHERE username = 'locked'`,
+	).Scan(&locked); err != nil {
+		t.Fatal(err)
+	}
+	if !locked {
+		t.Fatal("expected user to be locked")
+	}
+
+	if _, err := db.Exec(`ALTER USER locked ACCOUNT UNLOCK`); err != nil {
+		t.Fatal(err)
+	}
+	verify("locked", "hunter2", true)
+
+	if _, err := db.Exec(`ALTER USER expired VALID UNTIL 'infinity'`); err != nil {
+		t.Fatal(err)
+	}
+	verify("expired", "hunter2", true)
+}
+
 func TestCreateSession(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
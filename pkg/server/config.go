@@ -128,6 +128,10 @@ type Config struct {
 	// Stores is specified to enable durable key-value storage.
 	Stores base.StoreSpecList
 
+	// StoreEncryption specifies the stores encrypted at rest, along with
+	// their store keys. Encryption at rest requires a CCL binary.
+	StoreEncryption base.StoreEncryptionSpecList
+
 	// TempStorageConfig is used to store ephemeral data when processing large
 	// queries.
 	TempStorageConfig base.TempStorageConfig
@@ -157,6 +161,11 @@ type Config struct {
 	// used by SQL clients to store row data in server RAM.
 	SQLMemoryPoolSize int64
 
+	// TokenAuthenticator verifies the bearer tokens presented to the HTTP
+	// endpoints. If nil, tokens are verified as JWTs signed by one of the keys
+	// configured in the server.web_login.jwt.* cluster settings.
+	TokenAuthenticator TokenAuthenticator
+
 	// Parsed values.
 
 	// NodeAttributes is the parsed representation of Attrs.
@@ -495,6 +504,12 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 				WarnLargeBatchThreshold: 500 * time.Millisecond,
 				Settings:                cfg.Settings,
 			}
+			for _, es := range cfg.StoreEncryption.Specs {
+				if es.Path == spec.Path {
+					rocksDBConfig.EncryptionKeyPath = es.KeyPath
+					rocksDBConfig.EncryptionOldKeyPath = es.OldKeyPath
+				}
+			}
 
 			eng, err := engine.NewRocksDB(rocksDBConfig, cache)
 			if err != nil {
@@ -1070,6 +1070,15 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 		return err
 	}
 
+	sql.NewTempDatabaseSweeper(
+		s.st,
+		s.db,
+		s.clock,
+		s.leaseMgr,
+		s.sessionRegistry,
+		&s.nodeIDContainer,
+	).Start(s.stopper, s.nodeLiveness, sql.DefaultTempDatabaseSweepInterval)
+
 	// Initialize grpc-gateway mux and context.
 	jsonpb := &protoutil.JSONPb{
 		EnumsAsInts:  true,
@@ -1115,6 +1124,7 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 	s.mux.Handle(ts.URLPrefix, authHandler)
 	s.mux.Handle(statusPrefix, authHandler)
 	s.mux.Handle(authPrefix, gwMux)
+	s.mux.Handle(tokenLoginPath, http.HandlerFunc(s.authentication.handleTokenLogin))
 	s.mux.Handle("/health", gwMux)
 	s.mux.Handle(statusVars, http.HandlerFunc(s.status.handleVars))
 	log.Event(ctx, "added http endpoints")
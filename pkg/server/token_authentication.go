@@ -0,0 +1,525 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+const (
+	// tokenLoginPath is the HTTP endpoint which exchanges a bearer token for
+	// a web session cookie.
+	tokenLoginPath = authPrefix + "token_login"
+	// bearerPrefix prefixes the token in an Authorization header.
+	bearerPrefix = "Bearer "
+	// jwtClockSkew is the leeway allowed when checking the time-based claims
+	// of a JWT.
+	jwtClockSkew = time.Minute
+)
+
+var jwtKeySet = settings.RegisterValidatedStringSetting(
+	"server.web_login.jwt.jwks",
+	"a JSON Web Key Set holding the public keys used to verify JWTs presented to the HTTP endpoints; "+
+		"if empty, the keys are read from server.web_login.jwt.jwks_file",
+	"",
+	func(s string) error {
+		if s == "" {
+			return nil
+		}
+		_, err := parseJWKS([]byte(s))
+		return err
+	},
+)
+
+var jwtKeySetFile = settings.RegisterStringSetting(
+	"server.web_login.jwt.jwks_file",
+	"the path to a local file holding a JSON Web Key Set used to verify JWTs presented to the HTTP endpoints",
+	"",
+)
+
+var jwtIssuer = settings.RegisterStringSetting(
+	"server.web_login.jwt.issuer",
+	"if set, the value the iss claim of a JWT must match",
+	"",
+)
+
+var jwtAudience = settings.RegisterStringSetting(
+	"server.web_login.jwt.audience",
+	"if set, a value the aud claim of a JWT must contain",
+	"",
+)
+
+var jwtUsernameClaim = settings.RegisterValidatedStringSetting(
+	"server.web_login.jwt.username_claim",
+	"the JWT claim holding the name of the SQL user to log in as",
+	"sub",
+	func(s string) error {
+		if s == "" {
+			return errors.New("the username claim cannot be empty")
+		}
+		return nil
+	},
+)
+
+// TokenAuthenticator verifies the bearer tokens presented to the HTTP
+// endpoints.
+type TokenAuthenticator interface {
+	// Authenticate verifies the token and returns the name of the SQL user it
+	// was issued for. Authenticate must not make network calls, as it is
+	// invoked on every request which carries a token.
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+// jwtAuthenticator is the default TokenAuthenticator. It verifies signed JWTs
+// against the JSON Web Key Set configured through the server.web_login.jwt.*
+// cluster settings.
+type jwtAuthenticator struct {
+	st  *cluster.Settings
+	now func() time.Time
+
+	mu struct {
+		sync.Mutex
+		// raw and keys cache the most recently parsed key set, so that it is
+		// only parsed again when the setting or file changes.
+		raw  []byte
+		keys map[string]crypto.PublicKey
+	}
+}
+
+var _ TokenAuthenticator = &jwtAuthenticator{}
+
+func newJWTAuthenticator(st *cluster.Settings, now func() time.Time) *jwtAuthenticator {
+	return &jwtAuthenticator{st: st, now: now}
+}
+
+// Authenticate implements the TokenAuthenticator interface.
+func (a *jwtAuthenticator) Authenticate(ctx context.Context, token string) (string, error) {
+	keys, err := a.keySet()
+	if err != nil {
+		return "", err
+	}
+	claims, err := verifyJWT(token, keys)
+	if err != nil {
+		return "", err
+	}
+	if err := checkJWTClaims(
+		claims, a.now(), jwtIssuer.Get(&a.st.SV), jwtAudience.Get(&a.st.SV),
+	); err != nil {
+		return "", err
+	}
+	claim := jwtUsernameClaim.Get(&a.st.SV)
+	username, ok := claims[claim].(string)
+	if !ok || username == "" {
+		return "", errors.Errorf("token does not contain a %q claim", claim)
+	}
+	return username, nil
+}
+
+// keySet returns the configured public keys, indexed by key ID.
+func (a *jwtAuthenticator) keySet() (map[string]crypto.PublicKey, error) {
+	raw := []byte(jwtKeySet.Get(&a.st.SV))
+	if len(raw) == 0 {
+		path := jwtKeySetFile.Get(&a.st.SV)
+		if path == "" {
+			return nil, errors.New("token authentication is not configured")
+		}
+		var err error
+		if raw, err = ioutil.ReadFile(path); err != nil {
+			return nil, errors.Wrap(err, "could not read JSON Web Key Set")
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.mu.keys != nil && bytes.Equal(a.mu.raw, raw) {
+		return a.mu.keys, nil
+	}
+	keys, err := parseJWKS(raw)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.raw, a.mu.keys = raw, keys
+	return keys, nil
+}
+
+// jsonWebKey is the subset of RFC 7517 needed to reconstruct RSA and EC
+// public keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA parameters.
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC parameters.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses a JSON Web Key Set, returning its signing keys indexed by
+// key ID.
+func parseJWKS(raw []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON Web Key Set")
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if _, ok := keys[k.Kid]; ok {
+			return nil, errors.Errorf("duplicate key ID %q in JSON Web Key Set", k.Kid)
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %q in JSON Web Key Set", k.Kid)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JSON Web Key Set does not contain any signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if e.BitLen() > 31 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("missing key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// verifyJWT checks the signature of a compact-serialized JWT against the
+// given keys and returns its claims. Only asymmetric algorithms are
+// accepted, so that a public key can never be used as an HMAC secret.
+func verifyJWT(token string, keys map[string]crypto.PublicKey) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token header")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, errors.Wrap(err, "malformed token header")
+	}
+	key, ok := keys[header.Kid]
+	if !ok {
+		return nil, errors.Errorf("token signed with unknown key %q", header.Kid)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token signature")
+	}
+	if err := verifyJWTSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token claims")
+	}
+	var claims map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(claimsBytes))
+	decoder.UseNumber()
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, errors.Wrap(err, "malformed token claims")
+	}
+	return claims, nil
+}
+
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return errors.Errorf("unsupported token signing algorithm %q", alg)
+	}
+	var digest []byte
+	switch hash {
+	case crypto.SHA256:
+		sum := sha256.Sum256(signed)
+		digest = sum[:]
+	case crypto.SHA384:
+		sum := sha512.Sum384(signed)
+		digest = sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(signed)
+		digest = sum[:]
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if alg[0] != 'R' {
+			break
+		}
+		if err := rsa.VerifyPKCS1v15(key, hash, digest, sig); err != nil {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if alg[0] != 'E' {
+			break
+		}
+		// ECDSA signatures are the fixed-size concatenation of r and s.
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	}
+	return errors.Errorf("token signing algorithm %q does not match the key type", alg)
+}
+
+// checkJWTClaims validates the registered claims of a JWT: the token must be
+// within its validity window and, if configured, come from the expected
+// issuer and be intended for the expected audience.
+func checkJWTClaims(claims map[string]interface{}, now time.Time, issuer, audience string) error {
+	exp, ok, err := jwtTimeClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("token does not have an expiration time")
+	}
+	if now.After(exp.Add(jwtClockSkew)) {
+		return errors.New("token has expired")
+	}
+	nbf, ok, err := jwtTimeClaim(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(jwtClockSkew).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+
+	if issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != issuer {
+			return errors.Errorf("token issuer %q does not match %q", iss, issuer)
+		}
+	}
+	if audience != "" {
+		var found bool
+		switch aud := claims["aud"].(type) {
+		case string:
+			found = aud == audience
+		case []interface{}:
+			for _, a := range aud {
+				if a == audience {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return errors.Errorf("token audience does not include %q", audience)
+		}
+	}
+	return nil
+}
+
+// jwtTimeClaim returns the time held by a NumericDate claim, and whether the
+// claim was present.
+func jwtTimeClaim(claims map[string]interface{}, name string) (time.Time, bool, error) {
+	v, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false, errors.Errorf("token %q claim is not a number", name)
+	}
+	secs, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false, errors.Wrapf(err, "token %q claim is not a number", name)
+	}
+	return time.Unix(int64(secs), 0), true, nil
+}
+
+// bearerToken returns the token carried in the Authorization header of the
+// request, if any.
+func bearerToken(req *http.Request) (string, bool) {
+	auth := req.Header.Get("Authorization")
+	if len(auth) <= len(bearerPrefix) || !strings.EqualFold(auth[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(auth[len(bearerPrefix):]), true
+}
+
+// verifyToken authenticates a bearer token and maps it to an existing SQL
+// user. The returned boolean indicates whether the token was accepted; an
+// error is returned if the verification could not be completed.
+func (s *authenticationServer) verifyToken(ctx context.Context, token string) (string, bool, error) {
+	username, err := s.tokenAuth.Authenticate(ctx, token)
+	if err != nil {
+		log.Infof(ctx, "token authentication failed: %v", err)
+		return "", false, nil
+	}
+	username = parser.Name(username).Normalize()
+	// Root must use certificate authentication, as for password logins.
+	if username == security.RootUser {
+		return "", false, nil
+	}
+	exists, _, policy, err := sql.GetUserHashedPassword(
+		ctx, s.server.sqlExecutor, s.memMetrics, username,
+	)
+	if err != nil {
+		return "", false, err
+	}
+	if !exists {
+		return "", false, nil
+	}
+	// A locked account cannot log in, whichever credential is presented. The
+	// password expiration does not apply, as no password is used.
+	if s.server.clock.PhysicalTime().Before(policy.LockedUntil) {
+		return "", false, nil
+	}
+	return username, true, nil
+}
+
+// handleTokenLogin exchanges the bearer token of the request for a new web
+// session, which is returned as a session cookie like the ones issued by
+// UserLogin. This lets clients which log in through an external identity
+// provider use the admin UI without a SQL password.
+func (s *authenticationServer) handleTokenLogin(w http.ResponseWriter, req *http.Request) {
+	ctx := s.server.AnnotateCtx(req.Context())
+	if req.Method != http.MethodPost {
+		http.Error(w, "token login requires a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	token, ok := bearerToken(req)
+	if !ok {
+		http.Error(w, "a bearer token is required", http.StatusUnauthorized)
+		return
+	}
+	username, valid, err := s.verifyToken(ctx, token)
+	if err != nil {
+		http.Error(w, apiInternalError(ctx, err).Error(), http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		http.Error(w, "the provided token could not be validated", http.StatusUnauthorized)
+		return
+	}
+
+	id, secret, err := s.newAuthSession(ctx, username)
+	if err != nil {
+		http.Error(w, apiInternalError(ctx, err).Error(), http.StatusInternalServerError)
+		return
+	}
+	cookie, err := encodeSessionCookie(&serverpb.SessionCookie{
+		ID:     id,
+		Secret: secret,
+	})
+	if err != nil {
+		http.Error(w, apiInternalError(ctx, err).Error(), http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, cookie)
+	w.Header().Set("Content-Type", httputil.JSONContentType)
+	_, _ = w.Write([]byte("{}"))
+}
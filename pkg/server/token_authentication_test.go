@@ -0,0 +1,313 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"golang.org/x/net/context"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// signTestJWT returns a compact-serialized JWT holding the given claims,
+// signed with RS256 or ES256 depending on the type of key.
+func signTestJWT(
+	t *testing.T, key crypto.Signer, alg, kid string, claims map[string]interface{},
+) string {
+	header := map[string]string{"alg": alg, "typ": "JWT", "kid": kid}
+	signed := b64(mustJSON(t, header)) + "." + b64(mustJSON(t, claims))
+	digest := sha256.Sum256([]byte(signed))
+	var sig []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		// ES256 signatures are the 32-byte big-endian r and s, concatenated.
+		rb, sb := r.Bytes(), s.Bytes()
+		sig = make([]byte, 64)
+		copy(sig[32-len(rb):32], rb)
+		copy(sig[64-len(sb):], sb)
+	}
+	return signed + "." + b64(sig)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := string(mustJSON(t, map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa", "use": "sig",
+				"n": b64(rsaKey.N.Bytes()),
+				"e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "kid": "ec", "crv": "P-256",
+				"x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes()),
+			},
+		},
+	}))
+
+	now := time.Unix(1500000000, 0)
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":   "https://sso.example.com",
+			"aud":   []string{"cockroach", "other"},
+			"sub":   "carl",
+			"email": "carl@example.com",
+			"exp":   now.Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	testCases := []struct {
+		name     string
+		token    string
+		settings map[string]string
+		username string
+		err      string
+	}{
+		{"rsa", signTestJWT(t, rsaKey, "RS256", "rsa", claims(nil)), nil, "carl", ""},
+		{"ecdsa", signTestJWT(t, ecKey, "ES256", "ec", claims(nil)), nil, "carl", ""},
+		{
+			"username claim",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(nil)),
+			map[string]string{"server.web_login.jwt.username_claim": "email"},
+			"carl@example.com", "",
+		},
+		{
+			"missing username claim",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"sub": nil})),
+			nil, "", `does not contain a "sub" claim`,
+		},
+		{
+			"unknown key",
+			signTestJWT(t, otherKey, "RS256", "other", claims(nil)),
+			nil, "", `unknown key "other"`,
+		},
+		{
+			"wrong key",
+			signTestJWT(t, otherKey, "RS256", "rsa", claims(nil)),
+			nil, "", "invalid token signature",
+		},
+		{
+			"algorithm mismatch",
+			signTestJWT(t, rsaKey, "ES256", "rsa", claims(nil)),
+			nil, "", "does not match the key type",
+		},
+		{
+			"hmac",
+			b64([]byte(`{"alg":"HS256","kid":"rsa"}`)) + "." + b64(mustJSON(t, claims(nil))) + ".c2ln",
+			nil, "", `unsupported token signing algorithm "HS256"`,
+		},
+		{
+			"unsigned",
+			b64([]byte(`{"alg":"none","kid":"rsa"}`)) + "." + b64(mustJSON(t, claims(nil))) + ".",
+			nil, "", `unsupported token signing algorithm "none"`,
+		},
+		{"malformed", "not-a-token", nil, "", "malformed token"},
+		{
+			"expired",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{
+				"exp": now.Add(-time.Hour).Unix(),
+			})),
+			nil, "", "token has expired",
+		},
+		{
+			"no expiration",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"exp": nil})),
+			nil, "", "does not have an expiration time",
+		},
+		{
+			"not yet valid",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{
+				"nbf": now.Add(time.Hour).Unix(),
+			})),
+			nil, "", "token is not valid yet",
+		},
+		{
+			"issuer",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(nil)),
+			map[string]string{"server.web_login.jwt.issuer": "https://sso.example.com"},
+			"carl", "",
+		},
+		{
+			"wrong issuer",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(nil)),
+			map[string]string{"server.web_login.jwt.issuer": "https://other.example.com"},
+			"", "does not match",
+		},
+		{
+			"audience",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"aud": "cockroach"})),
+			map[string]string{"server.web_login.jwt.audience": "cockroach"},
+			"carl", "",
+		},
+		{
+			"audience list",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(nil)),
+			map[string]string{"server.web_login.jwt.audience": "cockroach"},
+			"carl", "",
+		},
+		{
+			"wrong audience",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(nil)),
+			map[string]string{"server.web_login.jwt.audience": "grafana"},
+			"", `audience does not include "grafana"`,
+		},
+		{
+			"not configured",
+			signTestJWT(t, rsaKey, "RS256", "rsa", claims(nil)),
+			map[string]string{"server.web_login.jwt.jwks": ""},
+			"", "token authentication is not configured",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := cluster.MakeTestingClusterSettings()
+			u := st.MakeUpdater()
+			if err := u.Set("server.web_login.jwt.jwks", jwks, "s"); err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tc.settings {
+				if err := u.Set(k, v, "s"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			a := newJWTAuthenticator(st, func() time.Time { return now })
+			username, err := a.Authenticate(context.TODO(), tc.token)
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+			if username != tc.username {
+				t.Fatalf("expected username %q, got %q", tc.username, username)
+			}
+		})
+	}
+
+	t.Run("file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "jwks")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := os.RemoveAll(dir); err != nil {
+				t.Fatal(err)
+			}
+		}()
+		path := filepath.Join(dir, "jwks.json")
+		if err := ioutil.WriteFile(path, []byte(jwks), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		st := cluster.MakeTestingClusterSettings()
+		if err := st.MakeUpdater().Set("server.web_login.jwt.jwks_file", path, "s"); err != nil {
+			t.Fatal(err)
+		}
+		a := newJWTAuthenticator(st, func() time.Time { return now })
+		username, err := a.Authenticate(context.TODO(), signTestJWT(t, ecKey, "ES256", "ec", claims(nil)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if username != "carl" {
+			t.Fatalf("expected username %q, got %q", "carl", username)
+		}
+	})
+}
+
+func TestParseJWKS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		jwks string
+		err  string
+	}{
+		{`not json`, "invalid JSON Web Key Set"},
+		{`{"keys": []}`, "does not contain any signing keys"},
+		{`{"keys": [{"kty": "oct", "kid": "a", "k": "c2VjcmV0"}]}`, `unsupported key type "oct"`},
+		{`{"keys": [{"kty": "RSA", "kid": "a", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`,
+			"does not contain any signing keys"},
+		{`{"keys": [{"kty": "RSA", "kid": "a", "n": "AQAB"}]}`, "missing key parameter"},
+		{`{"keys": [{"kty": "EC", "kid": "a", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`,
+			"not on the curve"},
+		{`{"keys": [{"kty": "EC", "kid": "a", "crv": "secp256k1", "x": "AQ", "y": "AQ"}]}`,
+			`unsupported curve "secp256k1"`},
+		{`{"keys": [{"kty": "RSA", "kid": "a", "n": "AQAB", "e": "AQAB"},` +
+			`{"kty": "RSA", "kid": "a", "n": "AQAB", "e": "AQAB"}]}`, `duplicate key ID "a"`},
+		{`{"keys": [{"kty": "RSA", "kid": "a", "n": "AQAB", "e": "AQAB"}]}`, ""},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if _, err := parseJWKS([]byte(tc.jwks)); !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
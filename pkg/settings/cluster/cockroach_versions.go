@@ -35,6 +35,8 @@ const (
 	VersionRaftLastIndex
 	VersionMVCCNetworkStats
 	VersionMeta2Splits
+	VersionNonVoterReplicas
+	VersionLearnerReplicas
 
 	// Add new versions here (step one of two)
 
@@ -91,6 +93,19 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionMeta2Splits,
 		Version: roachpb.Version{Major: 1, Minor: 1, Unstable: 3},
 	},
+	{
+		// VersionNonVoterReplicas adds non-voting replicas, which nodes running
+		// older binaries do not know about.
+		Key:     VersionNonVoterReplicas,
+		Version: roachpb.Version{Major: 1, Minor: 1, Unstable: 4},
+	},
+	{
+		// VersionLearnerReplicas adds replicas to ranges as Raft learners, which
+		// nodes running older binaries do not know about, instead of sending
+		// them preemptive snapshots.
+		Key:     VersionLearnerReplicas,
+		Version: roachpb.Version{Major: 1, Minor: 1, Unstable: 5},
+	},
 
 	// Add new versions here (step two of two).
 
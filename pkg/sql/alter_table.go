@@ -37,7 +37,7 @@ type alterTableNode struct {
 //   notes: postgres requires CREATE on the table.
 //          mysql requires ALTER, CREATE, INSERT on the table.
 func (p *planner) AlterTable(ctx context.Context, n *parser.AlterTable) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +53,7 @@ func (p *planner) AlterTable(ctx context.Context, n *parser.AlterTable) (planNod
 		return nil, sqlbase.NewUndefinedRelationError(tn)
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 	return &alterTableNode{n: n, tableDesc: tableDesc}, nil
@@ -79,6 +79,9 @@ func (n *alterTableNode) Start(params runParams) error {
 				return pgerror.Unimplemented(
 					"alter add fk", "adding a REFERENCES constraint via ALTER not supported")
 			}
+			if err := params.p.resolveColumnType(params.ctx, d, n.tableDesc.ParentID); err != nil {
+				return err
+			}
 			col, idx, err := sqlbase.MakeColumnDefDescs(d, params.p.session.SearchPath, &params.p.evalCtx)
 			if err != nil {
 				return err
@@ -105,6 +108,9 @@ func (n *alterTableNode) Start(params runParams) error {
 			}
 
 			n.tableDesc.AddColumnMutation(*col, sqlbase.DescriptorMutation_ADD)
+			if err := params.p.addTypeBackReference(params.ctx, *col, n.tableDesc.ID); err != nil {
+				return err
+			}
 			if idx != nil {
 				if err := n.tableDesc.AddIndexMutation(*idx, sqlbase.DescriptorMutation_ADD); err != nil {
 					return err
@@ -161,7 +167,7 @@ func (n *alterTableNode) Start(params runParams) error {
 				descriptorChanged = true
 
 			case *parser.ForeignKeyConstraintTableDef:
-				if _, err := d.Table.NormalizeWithDatabaseName(params.p.session.Database); err != nil {
+				if _, err := params.p.normalizeTableName(params.ctx, &d.Table); err != nil {
 					return err
 				}
 				affected := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
@@ -170,7 +176,14 @@ func (n *alterTableNode) Start(params runParams) error {
 					return err
 				}
 				descriptorChanged = true
+				temporary, err := isTempTable(params.ctx, params.p.txn, n.tableDesc)
+				if err != nil {
+					return err
+				}
 				for _, updated := range affected {
+					if err := checkTempTableReference(params.ctx, params.p.txn, temporary, updated); err != nil {
+						return err
+					}
 					if err := params.p.saveNonmutationAndNotify(params.ctx, updated); err != nil {
 						return err
 					}
@@ -411,6 +424,27 @@ func (n *alterTableNode) Start(params runParams) error {
 				return errors.Errorf("validating %s constraint %q unsupported", constraint.Kind, t.Constraint)
 			}
 
+		case *parser.AlterTableSetAudit:
+			// Users with CREATE on the table must not be able to turn off
+			// auditing of their own accesses.
+			if err := params.p.RequireSuperUser("change auditing settings on a table"); err != nil {
+				return err
+			}
+			mode := sqlbase.TableDescriptor_DISABLED
+			if t.Mode == parser.AuditModeReadWrite {
+				mode = sqlbase.TableDescriptor_READWRITE
+			}
+			if n.tableDesc.AuditMode != mode {
+				n.tableDesc.AuditMode = mode
+				descriptorChanged = true
+			}
+
+		case *parser.AlterTableRowLevelSecurity:
+			if n.tableDesc.RowLevelSecurity != t.Enable {
+				n.tableDesc.RowLevelSecurity = t.Enable
+				descriptorChanged = true
+			}
+
 		case parser.ColumnMutationCmd:
 			// Column mutations
 			col, dropped, err := n.tableDesc.FindColumnByName(t.GetColumn())
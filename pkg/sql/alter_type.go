@@ -0,0 +1,138 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/enum"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type alterTypeNode struct {
+	n        *parser.AlterTypeAddValue
+	typeDesc *sqlbase.TypeDescriptor
+}
+
+// AlterTypeAddValue adds a value to an ENUM type.
+// Privileges: CREATE on type.
+//   notes: postgres requires ownership of the type.
+func (p *planner) AlterTypeAddValue(
+	ctx context.Context, n *parser.AlterTypeAddValue,
+) (planNode, error) {
+	if p.session.Database == "" {
+		return nil, errNoDatabase
+	}
+
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	typeDesc, err := mustGetTypeDesc(ctx, p.txn, dbDesc.ID, string(n.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, typeDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	return &alterTypeNode{n: n, typeDesc: typeDesc}, nil
+}
+
+func (n *alterTypeNode) Start(params runParams) error {
+	members := n.typeDesc.EnumMembers
+	for _, m := range members {
+		if m.LogicalRepresentation == n.n.NewVal {
+			if n.n.IfNotExists {
+				return nil
+			}
+			return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+				"enum label %q already exists", n.n.NewVal)
+		}
+	}
+
+	// Find the position of the new member. It is added at the end of the type
+	// unless BEFORE or AFTER is specified.
+	pos := len(members)
+	if placement := n.n.Placement; placement != nil {
+		pos = -1
+		for i, m := range members {
+			if m.LogicalRepresentation == placement.ExistingVal {
+				pos = i
+				break
+			}
+		}
+		if pos == -1 {
+			return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"%q is not an existing enum label", placement.ExistingVal)
+		}
+		if !placement.Before {
+			pos++
+		}
+	}
+
+	// The physical representation of the new member sorts between the ones of
+	// its neighbors, so that the existing rows need not be rewritten.
+	var prev, next []byte
+	if pos > 0 {
+		prev = members[pos-1].PhysicalRepresentation
+	}
+	if pos < len(members) {
+		next = members[pos].PhysicalRepresentation
+	}
+	newMember := sqlbase.EnumMember{
+		LogicalRepresentation:  n.n.NewVal,
+		PhysicalRepresentation: enum.GenByteStringBetween(prev, next),
+	}
+
+	newMembers := make([]sqlbase.EnumMember, 0, len(members)+1)
+	newMembers = append(newMembers, members[:pos]...)
+	newMembers = append(newMembers, newMember)
+	newMembers = append(newMembers, members[pos:]...)
+	n.typeDesc.EnumMembers = newMembers
+	n.typeDesc.Version++
+	n.typeDesc.ModificationTime = params.p.txn.OrigTimestamp()
+
+	if err := params.p.writeTypeDesc(params.ctx, n.typeDesc); err != nil {
+		return err
+	}
+	if err := params.p.updateReferencingTables(params.ctx, n.typeDesc); err != nil {
+		return err
+	}
+	params.p.session.TxnState.schemaChangers.queueTypeVersionWait(n.typeDesc.ID)
+
+	// Log Alter Type event. This is an auditable log event and is recorded
+	// in the same transaction as the type descriptor update.
+	return MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogAlterType,
+		int32(n.typeDesc.ID),
+		int32(params.p.evalCtx.NodeID),
+		struct {
+			TypeName  string
+			Statement string
+			User      string
+		}{n.typeDesc.Name, n.n.String(), params.p.session.User},
+	)
+}
+
+func (*alterTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterTypeNode) Close(context.Context)        {}
+func (*alterTypeNode) Values() parser.Datums        { return parser.Datums{} }
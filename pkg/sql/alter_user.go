@@ -0,0 +1,133 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+)
+
+type alterUserNode struct {
+	n          *parser.AlterUser
+	validUntil parser.Datum
+}
+
+// AlterUser changes the password or password expiration of a user, or
+// unlocks a user locked out after too many failed login attempts.
+// Privileges: UPDATE on system.users.
+//   notes: postgres allows users to change their own password.
+func (p *planner) AlterUser(ctx context.Context, n *parser.AlterUser) (planNode, error) {
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &parser.TableName{DatabaseName: "system", TableName: "users"})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, tDesc, privilege.UPDATE); err != nil {
+		return nil, err
+	}
+
+	if n.HasPassword() && *n.Password == "" {
+		return nil, security.ErrEmptyPassword
+	}
+
+	var validUntil parser.Datum
+	if n.ValidUntil != nil {
+		if validUntil, err = parseValidUntil(*n.ValidUntil); err != nil {
+			return nil, err
+		}
+	}
+
+	return &alterUserNode{n: n, validUntil: validUntil}, nil
+}
+
+func (n *alterUserNode) Start(params runParams) error {
+	normalizedUsername, err := NormalizeAndValidateUsername(string(n.n.Name))
+	if err != nil {
+		return err
+	}
+
+	var sets []string
+	args := []interface{}{normalizedUsername}
+	addSet := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf(`%s = $%d`, column, len(args)))
+	}
+	if n.n.HasPassword() {
+		hashedPassword, err := hashPassword(params.p.ExecCfg().Settings, normalizedUsername, *n.n.Password)
+		if err != nil {
+			return err
+		}
+		addSet(`"hashedPassword"`, hashedPassword)
+	}
+	if n.validUntil != nil {
+		addSet(`"validUntil"`, n.validUntil)
+	}
+
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	var exists bool
+	if len(sets) > 0 {
+		rowsAffected, err := internalExecutor.ExecuteStatementInTransaction(
+			params.ctx,
+			"alter-user",
+			params.p.txn,
+			`UPDATE system.users SET `+strings.Join(sets, ", ")+
+				` WHERE username = $1 AND "isRole" IS NOT TRUE`,
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+		exists = rowsAffected > 0
+	} else {
+		row, err := internalExecutor.QueryRowInTransaction(
+			params.ctx,
+			"alter-user",
+			params.p.txn,
+			`SELECT 1 FROM system.users WHERE username = $1 AND "isRole" IS NOT TRUE`,
+			normalizedUsername,
+		)
+		if err != nil {
+			return err
+		}
+		exists = row != nil
+	}
+	if exists && n.n.Unlock {
+		// The failed login attempts are recorded outside of system.users.
+		if _, err := internalExecutor.ExecuteStatementInTransaction(
+			params.ctx,
+			"alter-user",
+			params.p.txn,
+			`DELETE FROM system.login_attempts WHERE username = $1`,
+			normalizedUsername,
+		); err != nil {
+			return err
+		}
+	}
+	if !exists && !n.n.IfExists {
+		return errors.Errorf("user %s does not exist", normalizedUsername)
+	}
+	return nil
+}
+
+func (*alterUserNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterUserNode) Close(context.Context)        {}
+func (*alterUserNode) Values() parser.Datums        { return parser.Datums{} }
@@ -0,0 +1,78 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// auditLog records the statements accessing tables whose audit mode is set
+// with ALTER TABLE ... EXPERIMENTAL_AUDIT SET. Its entries are synced to
+// disk before the statement's result is returned to the client.
+var auditLog = log.NewSecondaryLogger("sql-audit", true /* forceSyncWrites */)
+
+// auditEvent is an access to an audited table by the current statement.
+type auditEvent struct {
+	desc    *sqlbase.TableDescriptor
+	writing bool
+}
+
+// maybeAudit records the access of the current statement to a table, if the
+// table is audited. It must be called before the privilege check for the
+// access, so that denied accesses are audited as well.
+func (p *planner) maybeAudit(desc *sqlbase.TableDescriptor, priv privilege.Kind) {
+	if desc.AuditMode != sqlbase.TableDescriptor_READWRITE {
+		return
+	}
+	writing := priv != privilege.SELECT
+	for i := range p.auditEvents {
+		if p.auditEvents[i].desc.ID == desc.ID {
+			p.auditEvents[i].writing = p.auditEvents[i].writing || writing
+			return
+		}
+	}
+	p.auditEvents = append(p.auditEvents, auditEvent{desc: desc, writing: writing})
+}
+
+// logAuditEvents writes an entry to the audit log for every audited table
+// accessed by stmt. rows is the number of rows affected or returned, and
+// err the error the statement failed with, if any.
+func (p *planner) logAuditEvents(ctx context.Context, stmt Statement, rows int, err error) {
+	if len(p.auditEvents) == 0 {
+		return
+	}
+	p.session.mu.RLock()
+	appName := p.session.mu.ApplicationName
+	p.session.mu.RUnlock()
+
+	outcome := "OK"
+	if err != nil {
+		outcome = "ERROR: " + err.Error()
+	}
+	for _, ev := range p.auditEvents {
+		access := "read"
+		if ev.writing {
+			access = "write"
+		}
+		auditLog.Logf(ctx, "user=%s client=%s app=%q table=%q(%d) access=%s stmt=%q rows=%d outcome=%q",
+			p.session.User, p.session.ClientAddr, appName, ev.desc.Name, ev.desc.ID, access,
+			stmt.String(), rows, outcome)
+	}
+	p.auditEvents = nil
+}
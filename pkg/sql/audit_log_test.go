@@ -0,0 +1,96 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestAuditLog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := log.ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(t, db)
+
+	sqlDB.Exec(`CREATE DATABASE d`)
+	sqlDB.Exec(`CREATE TABLE d.audited (x INT)`)
+	sqlDB.Exec(`CREATE TABLE d.plain (x INT)`)
+	sqlDB.Exec(`ALTER TABLE d.audited EXPERIMENTAL_AUDIT SET READ WRITE`)
+
+	sqlDB.Exec(`INSERT INTO d.audited VALUES (1), (2)`)
+	sqlDB.Exec(`INSERT INTO d.plain VALUES (1)`)
+	sqlDB.Exec(`UPDATE d.audited SET x = 3 WHERE x = 2`)
+	sqlDB.QueryStr(`SELECT * FROM d.audited JOIN d.plain USING (x)`)
+	if _, err := db.Exec(`INSERT INTO d.audited VALUES ('a')`); err == nil {
+		t.Fatal("expected an error")
+	}
+	sqlDB.Exec(`TRUNCATE d.audited`)
+	sqlDB.Exec(`ALTER TABLE d.audited EXPERIMENTAL_AUDIT SET OFF`)
+	sqlDB.QueryStr(`SELECT x FROM d.audited`)
+
+	files, err := log.ListLogFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents string
+	for _, f := range files {
+		if !strings.Contains(f.Details.Program, "sql-audit") {
+			continue
+		}
+		r, err := log.GetLogReader(f.Name, true /* restricted */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents += string(b)
+	}
+
+	expected := []string{
+		`user=root .* table="audited"\(\d+\) access=write ` +
+			`stmt="INSERT INTO d.audited VALUES \(1\), \(2\)" rows=2 outcome="OK"`,
+		`table="audited"\(\d+\) access=write stmt="UPDATE d.audited SET x = 3 WHERE x = 2" rows=1 outcome="OK"`,
+		`table="audited"\(\d+\) access=read stmt="SELECT \* FROM d.audited JOIN d.plain USING \(x\)" rows=1 outcome="OK"`,
+		`table="audited"\(\d+\) access=write stmt="INSERT INTO d.audited VALUES \('a'\)" rows=0 outcome="ERROR: .*"`,
+		`table="audited"\(\d+\) access=write stmt="TRUNCATE TABLE d.audited" rows=\d+ outcome="OK"`,
+	}
+	for _, e := range expected {
+		if !regexp.MustCompile(e).MatchString(contents) {
+			t.Errorf("audit log does not match %q:\n%s", e, contents)
+		}
+	}
+	for _, unexpected := range []string{`table="plain"`, `SELECT x FROM d.audited`} {
+		if strings.Contains(contents, unexpected) {
+			t.Errorf("audit log unexpectedly contains %q:\n%s", unexpected, contents)
+		}
+	}
+}
@@ -17,29 +17,40 @@ package sql
 import (
 	"fmt"
 
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 )
 
 // AuthorizationAccessor for checking authorization (e.g. desc privileges).
 type AuthorizationAccessor interface {
-	// CheckPrivilege verifies that the user has `privilege` on `descriptor`.
+	// CheckPrivilege verifies that the user has `privilege` on `descriptor`,
+	// either directly or through one of the roles it is a member of.
 	CheckPrivilege(
-		descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
+		ctx context.Context, descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
 	) error
 
 	// anyPrivilege verifies that the user has any privilege on `descriptor`.
-	anyPrivilege(descriptor sqlbase.DescriptorProto) error
+	anyPrivilege(ctx context.Context, descriptor sqlbase.DescriptorProto) error
 
 	// RequiresSuperUser errors if the session user isn't a super-user (i.e. root
 	// or node). Includes the named action in the error message.
 	RequireSuperUser(action string) error
+
+	// MemberOfWithAdminOption looks up all the roles `member` belongs to,
+	// directly or indirectly, and returns a map of role -> isAdmin.
+	MemberOfWithAdminOption(ctx context.Context, member string) (map[string]bool, error)
 }
 
 var _ AuthorizationAccessor = &planner{}
 
 // CheckPrivilege verifies that `user`` has `privilege` on `descriptor`.
+// Role memberships are not considered; use (*planner).CheckPrivilege for that.
 func CheckPrivilege(
 	user string, descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
 ) error {
@@ -52,14 +63,57 @@ func CheckPrivilege(
 
 // CheckPrivilege implements the AuthorizationAccessor interface.
 func (p *planner) CheckPrivilege(
-	descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
+	ctx context.Context, descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
 ) error {
-	return CheckPrivilege(p.session.User, descriptor, privilege)
+	ok, err := p.hasPrivilege(ctx, descriptor, privilege)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return newPrivilegeError(p.session.User, privilege, descriptor)
+	}
+	return nil
+}
+
+// hasPrivilege returns whether the session user has `privilege` on the
+// descriptor, either directly or through one of the roles it is a member of.
+// Unlike CheckPrivilege, it distinguishes a missing privilege from a failure
+// to look up the roles of the user.
+func (p *planner) hasPrivilege(
+	ctx context.Context, descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
+) (bool, error) {
+	user := p.session.User
+	privs := descriptor.GetPrivileges()
+	if privs.CheckPrivilege(user, privilege) {
+		return true, nil
+	}
+
+	memberOf, err := p.MemberOfWithAdminOption(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	for role := range memberOf {
+		if privs.CheckPrivilege(role, privilege) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newPrivilegeError(
+	user string, privilege privilege.Kind, descriptor sqlbase.DescriptorProto,
+) error {
+	return fmt.Errorf("user %s does not have %s privilege on %s %s",
+		user, privilege, descriptor.TypeName(), descriptor.GetName())
 }
 
 // anyPrivilege implements the AuthorizationAccessor interface.
-func (p *planner) anyPrivilege(descriptor sqlbase.DescriptorProto) error {
-	if userCanSeeDescriptor(descriptor, p.session.User) {
+func (p *planner) anyPrivilege(ctx context.Context, descriptor sqlbase.DescriptorProto) error {
+	canSee, err := p.userCanSeeDescriptor(ctx, descriptor)
+	if err != nil {
+		return err
+	}
+	if canSee {
 		return nil
 	}
 	return fmt.Errorf("user %s has no privileges on %s %s",
@@ -74,6 +128,292 @@ func (p *planner) RequireSuperUser(action string) error {
 	return nil
 }
 
+// MemberOfWithAdminOption implements the AuthorizationAccessor interface.
+// Membership is transitive: if A is a member of B and B is a member of C, A
+// is a member of C. As in Postgres, A holds the admin option on C if A or any
+// role A is a member of was granted C WITH ADMIN OPTION.
+//
+// The lookup reads system.role_members as root in the planner's transaction,
+// so that the session user does not need privileges on it and so that
+// memberships granted earlier in the transaction are visible. Results are
+// cached on the planner for the duration of the statement, and in the
+// executor's roleMembershipCache across statements.
+func (p *planner) MemberOfWithAdminOption(
+	ctx context.Context, member string,
+) (map[string]bool, error) {
+	if ret, ok := p.roleMemberships[member]; ok {
+		return ret, nil
+	}
+	// The node user is never a member of any role.
+	if member == security.NodeUser {
+		return nil, nil
+	}
+
+	cache, tableVersion, err := p.getRoleMembershipCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret, ok := cache.get(tableVersion, member)
+	if !ok {
+		if ret, err = p.resolveMemberOfWithAdminOption(ctx, member); err != nil {
+			return nil, err
+		}
+		cache.add(tableVersion, member, ret)
+	}
+
+	if p.roleMemberships == nil {
+		p.roleMemberships = make(map[string]map[string]bool)
+	}
+	p.roleMemberships[member] = ret
+	return ret, nil
+}
+
+// resolveMemberOfWithAdminOption reads the roles member belongs to from
+// system.role_members, one level of the role graph at a time.
+func (p *planner) resolveMemberOfWithAdminOption(
+	ctx context.Context, member string,
+) (map[string]bool, error) {
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	ret := make(map[string]bool)
+	visited := map[string]struct{}{member: {}}
+	for queue := []string{member}; len(queue) > 0; queue = queue[1:] {
+		rows, err := ie.QueryRowsInTransaction(ctx, "member-of", p.txn,
+			`SELECT "role", "isAdmin" FROM system.role_members WHERE member = $1`, queue[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			role := string(parser.MustBeDString(row[0]))
+			ret[role] = ret[role] || bool(*row[1].(*parser.DBool))
+			if _, ok := visited[role]; !ok {
+				visited[role] = struct{}{}
+				queue = append(queue, role)
+			}
+		}
+	}
+	return ret, nil
+}
+
+// getRoleMembershipCache returns the cache of role memberships which the
+// planner's transaction may use, along with the version of the
+// system.role_members descriptor it holds a lease on. The cache is nil, which
+// disables it, for internal planners and for transactions which changed
+// system.role_members themselves, as their view of it is not committed.
+func (p *planner) getRoleMembershipCache(
+	ctx context.Context,
+) (*roleMembershipCache, sqlbase.DescriptorVersion, error) {
+	if p.session.execCfg == nil || p.session.execCfg.roleMembershipCache == nil {
+		return nil, 0, nil
+	}
+	for _, table := range p.session.tables.uncommittedTables {
+		if table.ID == keys.RoleMembersTableID {
+			return nil, 0, nil
+		}
+	}
+	desc, err := p.session.tables.getTableVersionByID(ctx, p.txn, keys.RoleMembersTableID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p.session.execCfg.roleMembershipCache, desc.Version, nil
+}
+
+// bumpRoleMembersTableVersion marks the system.role_members descriptor for a
+// version increment in the planner's transaction. Every statement which
+// changes system.role_members must call it; startEditNode does so for
+// statements which write to it directly. Once the transaction commits, the
+// session's schema changer increments the version and waits for the leases
+// on the previous version to drain, so that no node uses the memberships
+// cached at that version after the statement returns.
+func (p *planner) bumpRoleMembersTableVersion(ctx context.Context) error {
+	desc, err := sqlbase.GetTableDescFromID(ctx, p.txn, keys.RoleMembersTableID)
+	if err != nil {
+		return err
+	}
+	if err := desc.SetUpVersion(); err != nil {
+		return err
+	}
+	if err := p.txn.SetSystemConfigTrigger(); err != nil {
+		return err
+	}
+	if err := p.writeTableDesc(ctx, desc); err != nil {
+		return err
+	}
+	// Internal planners run within the transaction of a statement which
+	// schedules the version increment itself.
+	if p.session.execCfg != nil {
+		p.notifySchemaChange(desc, sqlbase.InvalidMutationID)
+	}
+	// The memberships cached on the planner are now stale.
+	p.roleMemberships = nil
+	return nil
+}
+
+// roleMembershipCache caches the results of MemberOfWithAdminOption across
+// statements and sessions. Since every change to system.role_members also
+// bumps the version of its descriptor, the memberships read by transactions
+// which lease the same version of the descriptor are the same. The cache only
+// holds memberships read at the latest version it has seen; a transaction
+// which sees an older version does not use it.
+type roleMembershipCache struct {
+	syncutil.Mutex
+	tableVersion sqlbase.DescriptorVersion
+	// memberships maps a member to the roles it belongs to, and whether it
+	// holds the admin option on them. The maps are never modified once added.
+	memberships map[string]map[string]bool
+}
+
+// get returns the cached memberships of member at the given version of the
+// system.role_members descriptor.
+func (c *roleMembershipCache) get(
+	tableVersion sqlbase.DescriptorVersion, member string,
+) (map[string]bool, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.tableVersion != tableVersion {
+		return nil, false
+	}
+	ret, ok := c.memberships[member]
+	return ret, ok
+}
+
+// add caches the memberships of member read at the given version of the
+// system.role_members descriptor, dropping those cached at older versions.
+func (c *roleMembershipCache) add(
+	tableVersion sqlbase.DescriptorVersion, member string, memberships map[string]bool,
+) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if tableVersion < c.tableVersion {
+		return
+	}
+	if tableVersion > c.tableVersion || c.memberships == nil {
+		c.tableVersion = tableVersion
+		c.memberships = make(map[string]map[string]bool)
+	}
+	c.memberships[member] = memberships
+}
+
+// userCanSeeDescriptor returns true if the session user has any privilege on
+// the descriptor, directly or through a role, or if it is virtual.
+func (p *planner) userCanSeeDescriptor(
+	ctx context.Context, descriptor sqlbase.DescriptorProto,
+) (bool, error) {
+	user := p.session.User
+	if userCanSeeDescriptor(descriptor, user) {
+		return true, nil
+	}
+	memberOf, err := p.MemberOfWithAdminOption(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	for role := range memberOf {
+		if descriptor.GetPrivileges().AnyPrivilege(role) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func userCanSeeDescriptor(descriptor sqlbase.DescriptorProto, user string) bool {
 	return descriptor.GetPrivileges().AnyPrivilege(user) || isVirtualDescriptor(descriptor)
 }
+
+// hasColumnPrivilege returns whether the session user has `privilege` on the
+// column itself, either directly or through one of the roles it is a member
+// of. Privileges granted on the table are not considered.
+func (p *planner) hasColumnPrivilege(
+	ctx context.Context, col *sqlbase.ColumnDescriptor, privilege privilege.Kind,
+) (bool, error) {
+	if col.Privileges == nil {
+		return false, nil
+	}
+	user := p.session.User
+	if col.Privileges.CheckPrivilege(user, privilege) {
+		return true, nil
+	}
+	memberOf, err := p.MemberOfWithAdminOption(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	for role := range memberOf {
+		if col.Privileges.CheckPrivilege(role, privilege) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// anyColumnPrivilege returns whether the session user has `privilege` on any
+// column of the table.
+func (p *planner) anyColumnPrivilege(
+	ctx context.Context, desc *sqlbase.TableDescriptor, privilege privilege.Kind,
+) (bool, error) {
+	for i := range desc.Columns {
+		if ok, err := p.hasColumnPrivilege(ctx, &desc.Columns[i], privilege); ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func newColumnPrivilegeError(
+	user string, privilege privilege.Kind, desc *sqlbase.TableDescriptor, col *sqlbase.ColumnDescriptor,
+) error {
+	return fmt.Errorf("user %s does not have %s privilege on column %s of %s %s",
+		user, privilege, col.Name, desc.TypeName(), desc.GetName())
+}
+
+// checkColumnPrivileges verifies that the session user has `privilege` on
+// each of the given columns of the table, either on the table itself or on
+// the column.
+func (p *planner) checkColumnPrivileges(
+	ctx context.Context,
+	desc *sqlbase.TableDescriptor,
+	cols []sqlbase.ColumnDescriptor,
+	privilege privilege.Kind,
+) error {
+	if ok, err := p.hasPrivilege(ctx, desc, privilege); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+	for i := range cols {
+		ok, err := p.hasColumnPrivilege(ctx, &cols[i], privilege)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return newColumnPrivilegeError(p.session.User, privilege, desc, &cols[i])
+		}
+	}
+	return nil
+}
+
+// restrictColumns is used when the session user does not have the SELECT
+// privilege on the table, but may have it on some of its columns. It marks
+// the result columns, produced from the given columns of the table, that
+// the user may not read: name resolution rejects references to them.
+func (p *planner) restrictColumns(
+	ctx context.Context,
+	desc *sqlbase.TableDescriptor,
+	cols []sqlbase.ColumnDescriptor,
+	resultCols sqlbase.ResultColumns,
+) error {
+	for i := range cols {
+		ok, err := p.hasColumnPrivilege(ctx, &cols[i], privilege.SELECT)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			resultCols[i].PrivilegeError = newColumnPrivilegeError(
+				p.session.User, privilege.SELECT, desc, &cols[i])
+		}
+	}
+	return nil
+}
@@ -29,18 +29,24 @@ import (
 )
 
 // checkHelper validates check constraints on rows, on INSERT and UPDATE.
+// It also validates the rows against the row-level security policies of
+// the table, if they apply to the session user.
 type checkHelper struct {
 	exprs        []parser.TypedExpr
 	cols         []sqlbase.ColumnDescriptor
 	sourceInfo   *dataSourceInfo
 	ivars        []parser.IndexedVar
 	curSourceRow parser.Datums
+
+	tableDesc  *sqlbase.TableDescriptor
+	policyExpr parser.TypedExpr
 }
 
 func (c *checkHelper) init(
 	ctx context.Context, p *planner, tn *parser.TableName, tableDesc *sqlbase.TableDescriptor,
 ) error {
-	if len(tableDesc.Checks) == 0 {
+	rls := p.rowLevelSecurityApplies(tableDesc)
+	if len(tableDesc.Checks) == 0 && !rls {
 		return nil
 	}
 
@@ -68,6 +74,14 @@ func (c *checkHelper) init(
 		}
 		c.exprs[i] = typedExpr
 	}
+	if rls {
+		c.tableDesc = tableDesc
+		policyExpr, err := p.analyzePolicyExpr(ctx, tableDesc, c.cols, ivarHelper, true /* forCheck */)
+		if err != nil {
+			return err
+		}
+		c.policyExpr = policyExpr
+	}
 	c.ivars = ivarHelper.GetIndexedVars()
 	c.curSourceRow = make(parser.Datums, len(c.cols))
 	return nil
@@ -79,7 +93,7 @@ func (c *checkHelper) init(
 func (c *checkHelper) loadRow(
 	colIdx map[sqlbase.ColumnID]int, row parser.Datums, merge bool,
 ) error {
-	if len(c.exprs) == 0 {
+	if len(c.exprs) == 0 && c.policyExpr == nil {
 		return nil
 	}
 	// Populate IndexedVars.
@@ -130,6 +144,16 @@ func (c *checkHelper) check(ctx *parser.EvalContext) error {
 				"failed to satisfy CHECK constraint (%s)", expr)
 		}
 	}
+	if c.policyExpr != nil {
+		// Unlike CHECK constraints, policies are not satisfied by NULL.
+		if d, err := c.policyExpr.Eval(ctx); err != nil {
+			return err
+		} else if res, err := parser.GetBool(d); err != nil {
+			return err
+		} else if !res {
+			return newPolicyViolationError(c.tableDesc)
+		}
+	}
 	return nil
 }
 
@@ -144,7 +168,7 @@ func (p *planner) validateCheckExpr(
 		return err
 	}
 	sel := &parser.SelectClause{
-		Exprs: sqlbase.ColumnsSelectors(tableDesc.Columns),
+		Exprs: sqlbase.ColumnsSelectors(tableDesc.Columns, false /* forUpdateOrDelete */),
 		From:  &parser.From{Tables: parser.TableExprs{tableName}},
 		Where: &parser.Where{Expr: &parser.NotExpr{Expr: expr}},
 	}
@@ -65,7 +65,7 @@ func (p *planner) CopyFrom(ctx context.Context, n *parser.CopyFrom) (planNode, e
 		columns: n.Columns,
 	}
 
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -187,6 +187,8 @@ func (n *copyNode) addRow(ctx context.Context, line []byte) error {
 			parser.TypeInterval,
 			parser.TypeINet,
 			parser.TypeString,
+			parser.TypeTime,
+			parser.TypeTimeTZ,
 			parser.TypeTimestamp,
 			parser.TypeTimestampTZ,
 			parser.TypeUUID:
@@ -194,7 +194,14 @@ CREATE TABLE crdb_internal.tables (
 		// include added and dropped descriptors.
 		for _, desc := range descs {
 			table, ok := desc.(*sqlbase.TableDescriptor)
-			if !ok || !userCanSeeDescriptor(table, p.session.User) {
+			if !ok {
+				continue
+			}
+			canSee, err := p.userCanSeeDescriptor(ctx, table)
+			if err != nil {
+				return err
+			}
+			if !canSee {
 				continue
 			}
 			dbName := dbNames[table.GetParentID()]
@@ -255,7 +262,14 @@ CREATE TABLE crdb_internal.schema_changes (
 		// include added and dropped descriptors.
 		for _, desc := range descs {
 			table, ok := desc.(*sqlbase.TableDescriptor)
-			if !ok || !userCanSeeDescriptor(table, p.session.User) {
+			if !ok {
+				continue
+			}
+			canSee, err := p.userCanSeeDescriptor(ctx, table)
+			if err != nil {
+				return err
+			}
+			if !canSee {
 				continue
 			}
 			tableID := parser.NewDInt(parser.DInt(int64(table.ID)))
@@ -304,10 +318,17 @@ CREATE TABLE crdb_internal.leases (
   deleted     BOOL NOT NULL
 );
 `,
-	populate: func(_ context.Context, p *planner, _ string, addRow func(...parser.Datum) error) error {
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...parser.Datum) error) error {
 		leaseMgr := p.LeaseMgr()
 		nodeID := parser.NewDInt(parser.DInt(int64(leaseMgr.nodeID.Get())))
 
+		// Resolve the roles of the session user before locking the lease
+		// manager, as the lookup may itself need to acquire leases. The
+		// visibility checks below are then served from the planner's cache.
+		if _, err := p.MemberOfWithAdminOption(ctx, p.session.User); err != nil {
+			return err
+		}
+
 		leaseMgr.mu.Lock()
 		defer leaseMgr.mu.Unlock()
 
@@ -321,7 +342,11 @@ CREATE TABLE crdb_internal.leases (
 				dropped := parser.MakeDBool(parser.DBool(ts.mu.dropped))
 
 				for _, state := range ts.mu.active.data {
-					if !userCanSeeDescriptor(&state.TableDescriptor, p.session.User) {
+					canSee, err := p.userCanSeeDescriptor(ctx, &state.TableDescriptor)
+					if err != nil {
+						return err
+					}
+					if !canSee {
 						continue
 					}
 
@@ -627,6 +652,19 @@ CREATE TABLE crdb_internal.session_variables (
 				return err
 			}
 		}
+		customNames := make([]string, 0, len(p.session.CustomVars))
+		for vName := range p.session.CustomVars {
+			customNames = append(customNames, vName)
+		}
+		sort.Strings(customNames)
+		for _, vName := range customNames {
+			if err := addRow(
+				parser.NewDString(vName),
+				parser.NewDString(p.session.CustomVars[vName]),
+			); err != nil {
+				return err
+			}
+		}
 		return nil
 	},
 }
@@ -19,12 +19,14 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/enum"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -48,6 +50,11 @@ func (p *planner) CreateDatabase(n *parser.CreateDatabase) (planNode, error) {
 		return nil, errEmptyDatabaseName
 	}
 
+	if name := string(n.Name); name == tempDatabaseAlias || isTempDatabaseName(name) {
+		return nil, pgerror.NewErrorf(pgerror.CodeReservedNameError,
+			"unacceptable database name %q", name)
+	}
+
 	if tmpl := n.Template; tmpl != "" {
 		// See https://www.postgresql.org/docs/current/static/manage-ag-templatedbs.html
 		if !strings.EqualFold(tmpl, "template0") {
@@ -129,7 +136,7 @@ type createIndexNode struct {
 //   notes: postgres requires CREATE on the table.
 //          mysql requires INDEX on the table.
 func (p *planner) CreateIndex(ctx context.Context, n *parser.CreateIndex) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +146,7 @@ func (p *planner) CreateIndex(ctx context.Context, n *parser.CreateIndex) (planN
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -221,9 +228,13 @@ func (*createIndexNode) Next(runParams) (bool, error) { return false, nil }
 func (*createIndexNode) Close(context.Context)        {}
 func (*createIndexNode) Values() parser.Datums        { return parser.Datums{} }
 
+// createUserNode creates a user or, if isRole is set, a role. Roles are
+// stored alongside users in system.users.
 type createUserNode struct {
-	n        *parser.CreateUser
-	password string
+	name       parser.Name
+	password   string
+	validUntil parser.Datum
+	isRole     bool
 }
 
 // CreateUser creates a user.
@@ -240,7 +251,7 @@ func (p *planner) CreateUser(ctx context.Context, n *parser.CreateUser) (planNod
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tDesc, privilege.INSERT); err != nil {
+	if err := p.CheckPrivilege(ctx, tDesc, privilege.INSERT); err != nil {
 		return nil, err
 	}
 
@@ -252,7 +263,33 @@ func (p *planner) CreateUser(ctx context.Context, n *parser.CreateUser) (planNod
 		}
 	}
 
-	return &createUserNode{n: n, password: resolvedPassword}, nil
+	validUntil := parser.Datum(parser.DNull)
+	if n.ValidUntil != nil {
+		if validUntil, err = parseValidUntil(*n.ValidUntil); err != nil {
+			return nil, err
+		}
+	}
+
+	return &createUserNode{name: n.Name, password: resolvedPassword, validUntil: validUntil}, nil
+}
+
+// CreateRole creates a role.
+// Privileges: INSERT on system.users.
+func (p *planner) CreateRole(ctx context.Context, n *parser.CreateRole) (planNode, error) {
+	if n.Name == "" {
+		return nil, errors.New("no role name specified")
+	}
+
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &parser.TableName{DatabaseName: "system", TableName: "users"})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, tDesc, privilege.INSERT); err != nil {
+		return nil, err
+	}
+
+	return &createUserNode{name: n.Name, validUntil: parser.DNull, isRole: true}, nil
 }
 
 const usernameHelp = "usernames are case insensitive, must start with a letter " +
@@ -277,33 +314,52 @@ func NormalizeAndValidateUsername(username string) (string, error) {
 	return username, nil
 }
 
+// parseValidUntil parses the timestamp of a VALID UNTIL clause. As in
+// postgres, 'infinity' means that the password never expires, which is
+// stored as NULL.
+func parseValidUntil(s string) (parser.Datum, error) {
+	if strings.EqualFold(s, "infinity") {
+		return parser.DNull, nil
+	}
+	d, err := parser.ParseDTimestamp(s, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
 func (n *createUserNode) Start(params runParams) error {
+	normalizedUsername, err := NormalizeAndValidateUsername(string(n.name))
+	if err != nil {
+		return err
+	}
+
 	var hashedPassword []byte
 	if n.password != "" {
-		var err error
-		hashedPassword, err = security.HashPassword(n.password)
+		hashedPassword, err = hashPassword(params.p.ExecCfg().Settings, normalizedUsername, n.password)
 		if err != nil {
 			return err
 		}
 	}
 
-	normalizedUsername, err := NormalizeAndValidateUsername(string(n.n.Name))
-	if err != nil {
-		return err
-	}
-
 	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
 	rowsAffected, err := internalExecutor.ExecuteStatementInTransaction(
 		params.ctx,
 		"create-user",
 		params.p.txn,
-		"INSERT INTO system.users VALUES ($1, $2);",
+		`INSERT INTO system.users (username, "hashedPassword", "isRole", "validUntil") VALUES ($1, $2, $3, $4);`,
 		normalizedUsername,
 		hashedPassword,
+		n.isRole,
+		n.validUntil,
 	)
 	if err != nil {
 		if sqlbase.IsUniquenessConstraintViolationError(err) {
-			err = errors.Errorf("user %s already exists", normalizedUsername)
+			if n.isRole {
+				err = errors.Errorf("a user or role named %s already exists", normalizedUsername)
+			} else {
+				err = errors.Errorf("user %s already exists", normalizedUsername)
+			}
 		}
 		return err
 	} else if rowsAffected != 1 {
@@ -347,7 +403,7 @@ func (p *planner) CreateView(ctx context.Context, n *parser.CreateView) (planNod
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -384,6 +440,16 @@ func (p *planner) CreateView(ctx context.Context, n *parser.CreateView) (planNod
 	if err != nil {
 		return nil, err
 	}
+	for _, dep := range planDeps {
+		temporary, err := isTempTable(ctx, p.txn, dep.desc)
+		if err != nil {
+			return nil, err
+		}
+		if temporary {
+			return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"views cannot depend on temporary table %q", parser.ErrString(parser.Name(dep.desc.Name)))
+		}
+	}
 
 	numColNames := len(n.ColumnNames)
 	numColumns := len(sourceColumns)
@@ -450,6 +516,9 @@ func (n *createViewNode) Start(params runParams) error {
 	if err = n.p.createDescriptorWithID(params.ctx, key, id, &desc); err != nil {
 		return err
 	}
+	if err := n.p.addTypeBackReferences(params.ctx, &desc); err != nil {
+		return err
+	}
 
 	// Persist the back-references in all referenced table descriptors.
 	for _, updated := range n.planDeps {
@@ -475,6 +544,12 @@ func (n *createViewNode) Start(params runParams) error {
 		return err
 	}
 
+	if desc.IsMaterializedView() {
+		if err := n.p.populateMaterializedView(params.ctx, &desc); err != nil {
+			return err
+		}
+	}
+
 	// Log Create View event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
 	if err := MakeEventLogger(n.p.LeaseMgr()).InsertEventRecord(
@@ -500,6 +575,94 @@ func (n *createViewNode) Close(ctx context.Context) {}
 func (*createViewNode) Next(runParams) (bool, error) { return false, nil }
 func (*createViewNode) Values() parser.Datums        { return parser.Datums{} }
 
+type createTypeNode struct {
+	n      *parser.CreateType
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// CreateType creates a user-defined type.
+// Privileges: CREATE on database.
+//   Notes: postgres requires CREATE on the schema.
+func (p *planner) CreateType(ctx context.Context, n *parser.CreateType) (planNode, error) {
+	if p.session.Database == "" {
+		return nil, errNoDatabase
+	}
+
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(n.EnumLabels))
+	for _, label := range n.EnumLabels {
+		if _, ok := seen[label]; ok {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"enum label %q used more than once", label)
+		}
+		seen[label] = struct{}{}
+	}
+
+	return &createTypeNode{n: n, dbDesc: dbDesc}, nil
+}
+
+func (n *createTypeNode) Start(params runParams) error {
+	physicalReps := enum.GenerateEvenlySpacedBytes(len(n.n.EnumLabels))
+	members := make([]sqlbase.EnumMember, len(n.n.EnumLabels))
+	for i, label := range n.n.EnumLabels {
+		members[i] = sqlbase.EnumMember{
+			LogicalRepresentation:  label,
+			PhysicalRepresentation: physicalReps[i],
+		}
+	}
+
+	desc := sqlbase.TypeDescriptor{
+		Name:             string(n.n.Name),
+		ParentID:         n.dbDesc.ID,
+		Version:          1,
+		ModificationTime: params.p.txn.OrigTimestamp(),
+		EnumMembers:      members,
+		// Inherit permissions from the database descriptor.
+		Privileges: n.dbDesc.GetPrivileges(),
+	}
+
+	tKey := tableKey{parentID: n.dbDesc.ID, name: desc.Name}
+	if _, err := params.p.createDescriptor(params.ctx, tKey, &desc, false /* ifNotExists */); err != nil {
+		return err
+	}
+	if err := desc.Validate(); err != nil {
+		return err
+	}
+	params.p.session.tables.addUncommittedType(desc, false /* dropped */)
+	if err := params.p.writeDatabaseTypeIDs(
+		params.ctx, n.dbDesc, []sqlbase.ID{desc.ID}, nil, /* removed */
+	); err != nil {
+		return err
+	}
+
+	// Log Create Type event. This is an auditable log event and is
+	// recorded in the same transaction as the type descriptor update.
+	return MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogCreateType,
+		int32(desc.ID),
+		int32(params.p.evalCtx.NodeID),
+		struct {
+			TypeName  string
+			Statement string
+			User      string
+		}{n.n.Name.String(), n.n.String(), params.p.session.User},
+	)
+}
+
+func (*createTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*createTypeNode) Close(context.Context)        {}
+func (*createTypeNode) Values() parser.Datums        { return parser.Datums{} }
+
 type createTableNode struct {
 	n          *parser.CreateTable
 	dbDesc     *sqlbase.DatabaseDescriptor
@@ -508,30 +671,76 @@ type createTableNode struct {
 }
 
 // CreateTable creates a table.
-// Privileges: CREATE on database.
+// Privileges: CREATE on database. None for temporary tables.
 //   Notes: postgres/mysql require CREATE on database.
+//          postgres requires TEMPORARY on database for temporary tables.
 func (p *planner) CreateTable(ctx context.Context, n *parser.CreateTable) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := n.Table.Normalize()
 	if err != nil {
 		return nil, err
 	}
-
-	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), tn.Database())
-	if err != nil {
+	if err := p.resolveTempDatabaseName(tn); err != nil {
 		return nil, err
 	}
+	if !tn.DBNameOriginallyOmitted && p.session.tempDatabaseName != "" &&
+		tn.Database() == p.session.tempDatabaseName {
+		// Like in postgres, tables created in pg_temp are temporary.
+		n.Temporary = true
+	}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
-		return nil, err
+	// The database of a temporary table is only created when the
+	// statement runs. User-defined types are looked up in the session
+	// database.
+	var dbDesc *sqlbase.DatabaseDescriptor
+	typeDBID := sqlbase.ID(keys.RootNamespaceID)
+	if n.Temporary {
+		if !tn.DBNameOriginallyOmitted && tn.Database() != p.session.tempDatabaseName {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+				"cannot create temporary relation in non-temporary schema")
+		}
+		if err := checkTempTableDefs(n); err != nil {
+			return nil, err
+		}
+		tn.DatabaseName = parser.Name(p.session.tempDatabaseName)
+		if p.session.Database != "" {
+			sessionDBDesc, err := getDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), p.session.Database)
+			if err != nil {
+				return nil, err
+			}
+			if sessionDBDesc != nil {
+				typeDBID = sessionDBDesc.ID
+			}
+		}
+	} else {
+		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+			return nil, err
+		}
+		dbDesc, err = MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), tn.Database())
+		if err != nil {
+			return nil, err
+		}
+		if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+			return nil, err
+		}
+		typeDBID = dbDesc.ID
 	}
 
 	HoistConstraints(n)
 	for _, def := range n.Defs {
 		switch t := def.(type) {
+		case *parser.ColumnTableDef:
+			if err := p.resolveColumnType(ctx, t, typeDBID); err != nil {
+				return nil, err
+			}
 		case *parser.ForeignKeyConstraintTableDef:
-			if _, err := t.Table.NormalizeWithDatabaseName(p.session.Database); err != nil {
+			refTn, err := p.normalizeTableName(ctx, &t.Table)
+			if err != nil {
 				return nil, err
 			}
+			if n.Temporary && refTn.DBNameOriginallyOmitted && refTn.Table() == tn.Table() {
+				// Self-reference to the temporary table being created.
+				refTn.DatabaseName = tn.DatabaseName
+			}
 		}
 	}
 
@@ -594,6 +803,13 @@ func HoistConstraints(n *parser.CreateTable) {
 }
 
 func (n *createTableNode) Start(params runParams) error {
+	if n.n.Temporary {
+		var err error
+		if n.dbDesc, err = params.p.getOrCreateTempDatabase(params.ctx); err != nil {
+			return err
+		}
+	}
+
 	tKey := tableKey{parentID: n.dbDesc.ID, name: n.n.Table.TableName().Table()}
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
@@ -641,8 +857,14 @@ func (n *createTableNode) Start(params runParams) error {
 	if err := params.p.createDescriptorWithID(params.ctx, key, id, &desc); err != nil {
 		return err
 	}
+	if err := params.p.addTypeBackReferences(params.ctx, &desc); err != nil {
+		return err
+	}
 
 	for _, updated := range affected {
+		if err := checkTempTableReference(params.ctx, params.p.txn, n.n.Temporary, updated); err != nil {
+			return err
+		}
 		if err := params.p.saveNonmutationAndNotify(params.ctx, updated); err != nil {
 			return err
 		}
@@ -1151,6 +1373,7 @@ func (n *createViewNode) makeViewTableDesc(
 ) (sqlbase.TableDescriptor, error) {
 	desc := initTableDescriptor(id, parentID, viewName, n.p.txn.OrigTimestamp(), privileges)
 	desc.ViewQuery = parser.AsStringWithFlags(n.n.AsSource, parser.FmtParsable)
+	desc.Materialized = n.n.Materialized
 	for i, colRes := range resultColumns {
 		colType, err := parser.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
@@ -1342,6 +1565,14 @@ func MakeTableDesc(
 		}
 	}
 
+	if n.PartitionBy != nil {
+		partitioning, err := createPartitioning(evalCtx, searchPath, &desc, &desc.PrimaryIndex, n.PartitionBy)
+		if err != nil {
+			return desc, err
+		}
+		desc.PrimaryIndex.Partitioning = partitioning
+	}
+
 	// With all structural elements in place and IDs allocated, we can resolve the
 	// constraints and qualifications.
 	// FKs are resolved after the descriptor is otherwise complete and IDs have
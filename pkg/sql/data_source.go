@@ -464,6 +464,8 @@ func (p *planner) QualifyWithDatabase(
 		if err := p.searchAndQualifyDatabase(ctx, tn); err != nil {
 			return nil, err
 		}
+	} else if err := p.resolveTempDatabaseName(tn); err != nil {
+		return nil, err
 	}
 	return tn, nil
 }
@@ -613,20 +615,20 @@ func (p *planner) getPlanForDesc(
 	scanVisibility scanVisibility,
 	wantedColumns []parser.ColumnID,
 ) (planDataSource, error) {
-	if desc.IsView() {
+	if desc.IsView() && !desc.IsMaterializedView() {
 		if wantedColumns != nil {
 			return planDataSource{},
 				errors.Errorf("cannot specify an explicit column list when accessing a view by reference")
 		}
 		return p.getViewPlan(ctx, tn, desc)
-	} else if !desc.IsTable() {
+	} else if !desc.IsTable() && !desc.IsMaterializedView() {
 		return planDataSource{}, errors.Errorf(
 			"unexpected table descriptor of type %s for %q", desc.TypeName(), parser.ErrString(tn))
 	}
 
-	// This name designates a real table.
+	// This name designates a real table or a materialized view.
 	scan := p.Scan()
-	if err := scan.initTable(p, desc, hints, scanVisibility, wantedColumns); err != nil {
+	if err := scan.initTable(ctx, p, desc, hints, scanVisibility, wantedColumns); err != nil {
 		return planDataSource{}, err
 	}
 
@@ -657,7 +659,7 @@ func (p *planner) getViewPlan(
 	// SELECT privileges on the view, which is intended to allow for exposing
 	// some subset of a restricted table's data to less privileged users.
 	if !p.skipSelectPrivilegeChecks {
-		if err := p.CheckPrivilege(desc, privilege.SELECT); err != nil {
+		if err := p.CheckPrivilege(ctx, desc, privilege.SELECT); err != nil {
 			return planDataSource{}, err
 		}
 		p.skipSelectPrivilegeChecks = true
@@ -731,6 +733,9 @@ func (src *dataSourceInfo) expandStar(
 	colSel := func(idx int) {
 		col := src.sourceColumns[idx]
 		if !col.Hidden {
+			if col.PrivilegeError != nil && err == nil {
+				err = col.PrivilegeError
+			}
 			ivar := ivarHelper.IndexedVar(idx)
 			columns = append(columns, sqlbase.ResultColumn{Name: col.Name, Typ: ivar.ResolvedType()})
 			exprs = append(exprs, ivar)
@@ -759,6 +764,10 @@ func (src *dataSourceInfo) expandStar(
 			colSel(i)
 		}
 	}
+	if err != nil {
+		// The star expands to a column the session user may not read.
+		return nil, nil, err
+	}
 
 	return columns, exprs, nil
 }
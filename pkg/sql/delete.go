@@ -58,7 +58,7 @@ func (p *planner) Delete(
 		return nil, pgerror.NewDangerousStatementErrorf("DELETE without WHERE clause")
 	}
 
-	tn, err := p.getAliasedTableName(n.Table)
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +92,7 @@ func (p *planner) Delete(
 	// performs index selection. We cannot perform index selection
 	// properly until the placeholder values are known.
 	rows, err := p.SelectClause(ctx, &parser.SelectClause{
-		Exprs: sqlbase.ColumnsSelectors(rd.FetchCols),
+		Exprs: sqlbase.ColumnsSelectors(rd.FetchCols, true /* forUpdateOrDelete */),
 		From:  &parser.From{Tables: []parser.TableExpr{n.Table}},
 		Where: n.Where,
 	}, nil, n.Limit, nil, publicAndNonPublicColumns)
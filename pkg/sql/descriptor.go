@@ -38,7 +38,7 @@ var (
 // DescriptorAccessor provides helper methods for using descriptors
 // to SQL objects.
 type DescriptorAccessor interface {
-	// createDescriptor takes a Table or Database descriptor and creates it if
+	// createDescriptor takes a Table, Database or Type descriptor and creates it if
 	// needed, incrementing the descriptor counter. Returns true if the descriptor
 	// is actually created, false if it already existed, or an error if one was encountered.
 	// The ifNotExists flag is used to declare if the "already existed" state should be an
@@ -94,6 +94,8 @@ func (p *planner) createDescriptor(
 			return false, sqlbase.NewDatabaseAlreadyExistsError(plainKey.Name())
 		case "table", "view":
 			return false, sqlbase.NewRelationAlreadyExistsError(plainKey.Name())
+		case "type":
+			return false, sqlbase.NewTypeAlreadyExistsError(plainKey.Name())
 		default:
 			return false, descriptorAlreadyExistsErr{descriptor, plainKey.Name()}
 		}
@@ -198,6 +200,11 @@ func getDescriptorByID(
 	case *sqlbase.TableDescriptor:
 		table := desc.GetTable()
 		if table == nil {
+			if desc.GetType() != nil {
+				// Types share the namespace of tables, so a table name can resolve
+				// to a type. Treat it as if the table did not exist.
+				return false, nil
+			}
 			return false, errors.Errorf("%q is not a table", desc.String())
 		}
 		table.MaybeUpgradeFormatVersion()
@@ -219,6 +226,19 @@ func getDescriptorByID(
 			return false, err
 		}
 		*t = *database
+	case *sqlbase.TypeDescriptor:
+		typ := desc.GetType()
+		if typ == nil {
+			if desc.GetTable() != nil {
+				// Likewise, a type name can resolve to a table.
+				return false, nil
+			}
+			return false, errors.Errorf("%q is not a type", desc.String())
+		}
+		if err := typ.Validate(); err != nil {
+			return false, err
+		}
+		*t = *typ
 	}
 	return true, nil
 }
@@ -242,6 +262,8 @@ func getAllDescriptors(ctx context.Context, txn *client.Txn) ([]sqlbase.Descript
 			descs[i] = desc.GetTable()
 		case *sqlbase.Descriptor_Database:
 			descs[i] = desc.GetDatabase()
+		case *sqlbase.Descriptor_Type:
+			descs[i] = desc.GetType()
 		default:
 			return nil, errors.Errorf("Descriptor.Union has unexpected type %T", t)
 		}
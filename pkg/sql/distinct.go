@@ -20,13 +20,24 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/pkg/errors"
 )
 
 // distinctNode de-duplicates rows returned by a wrapped planNode.
 type distinctNode struct {
 	plan planNode
 	p    *planner
+	// The columns of the DISTINCT ON expressions, if any. When set, rows are
+	// de-duplicated on these columns only, and the first row of each group in
+	// the source ordering is returned.
+	distinctOnColIdxs util.FastIntSet
+	// The number of columns returned by the node, if the DISTINCT ON expressions
+	// required renders which are not part of the requested column set. Zero if
+	// all the source columns are returned.
+	numOutputCols int
 	// All the columns that are part of the Sort. Set to nil if no-sort, or
 	// sort used an expression that was not part of the requested column set.
 	columnsInOrder []bool
@@ -40,15 +51,112 @@ type distinctNode struct {
 	suffixMemAcc WrappableMemoryAccount
 }
 
-// distinct constructs a distinctNode.
-func (p *planner) Distinct(n *parser.SelectClause) *distinctNode {
+// Distinct constructs a distinctNode. The DISTINCT ON expressions which are
+// not already rendered are added to the renders of r; this must happen before
+// the ORDER BY clause is processed so that the sortNode preserves them.
+func (p *planner) Distinct(
+	ctx context.Context, n *parser.SelectClause, r *renderNode,
+) (*distinctNode, error) {
 	if !n.Distinct {
-		return nil
+		return nil, nil
 	}
 	d := &distinctNode{p: p}
+	for _, expr := range n.DistinctOn {
+		colIdxs, err := p.distinctOnColumns(ctx, expr, r)
+		if err != nil {
+			return nil, err
+		}
+		for _, colIdx := range colIdxs {
+			d.distinctOnColIdxs.Add(colIdx)
+		}
+	}
+	if len(r.columns) > r.numOriginalCols {
+		d.numOutputCols = r.numOriginalCols
+	}
 	d.prefixMemAcc = p.session.TxnState.OpenAccount()
 	d.suffixMemAcc = p.session.TxnState.OpenAccount()
-	return d
+	return d, nil
+}
+
+// distinctOnColumns returns the indices of the render columns for a DISTINCT
+// ON expression. As for ORDER BY, the expression can be the name or the
+// ordinal of a column in the select list, or an arbitrary expression which is
+// rendered if needed.
+func (p *planner) distinctOnColumns(
+	ctx context.Context, expr parser.Expr, r *renderNode,
+) ([]int, error) {
+	expr = parser.StripParens(expr)
+
+	// First, deal with render aliases.
+	if vBase, ok := expr.(parser.VarName); ok {
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return nil, err
+		}
+
+		if c, ok := v.(*parser.ColumnItem); ok && c.TableName.Table() == "" {
+			target := string(c.ColumnName)
+			index := -1
+			for j, col := range r.columns[:r.numOriginalCols] {
+				if col.Name != target {
+					continue
+				}
+				if index != -1 {
+					if !r.equivalentRenders(j, index) {
+						return nil, errors.Errorf("DISTINCT ON \"%s\" is ambiguous", target)
+					}
+					continue
+				}
+				index = j
+			}
+			if index != -1 {
+				return []int{index}, nil
+			}
+		}
+	}
+
+	// Then, deal with column ordinals.
+	col, err := p.colIndex(r.numOriginalCols, expr, "DISTINCT ON")
+	if err != nil {
+		return nil, err
+	}
+	if col != -1 {
+		return []int{col}, nil
+	}
+
+	// Finally, render the expression.
+	cols, exprs, hasStar, err := p.computeRenderAllowingStars(
+		ctx, parser.SelectExpr{Expr: expr}, parser.TypeAny,
+		r.sourceInfo, r.ivarHelper, autoGenerateRenderOutputName)
+	if err != nil {
+		return nil, err
+	}
+	p.hasStar = p.hasStar || hasStar
+
+	// DISTINCT ON (a, b) -> DISTINCT ON (a), (b)
+	cols, exprs = flattenTuples(cols, exprs)
+	return r.addOrReuseRenders(cols, exprs, true), nil
+}
+
+// checkOrdering verifies that the DISTINCT ON expressions match the leftmost
+// expressions of the given ordering, as postgres does. Otherwise, the row
+// returned for each group would not be the first one in the requested order.
+func (n *distinctNode) checkOrdering(ordering sqlbase.ColumnOrdering) error {
+	if n.distinctOnColIdxs.Empty() {
+		return nil
+	}
+	var seen util.FastIntSet
+	for _, o := range ordering {
+		if seen.Equals(n.distinctOnColIdxs) {
+			break
+		}
+		if !n.distinctOnColIdxs.Contains(o.ColIdx) {
+			return pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+				"SELECT DISTINCT ON expressions must match initial ORDER BY expressions")
+		}
+		seen.Add(o.ColIdx)
+	}
+	return nil
 }
 
 func (n *distinctNode) Start(params runParams) error {
@@ -56,7 +164,14 @@ func (n *distinctNode) Start(params runParams) error {
 	return n.plan.Start(params)
 }
 
-func (n *distinctNode) Values() parser.Datums { return n.plan.Values() }
+func (n *distinctNode) Values() parser.Datums {
+	values := n.plan.Values()
+	if n.numOutputCols > 0 {
+		// Strip the columns only rendered for DISTINCT ON.
+		return values[:n.numOutputCols]
+	}
+	return values
+}
 
 func (n *distinctNode) addSuffixSeen(
 	ctx context.Context, acc WrappedMemoryAccount, sKey string,
@@ -86,7 +201,7 @@ func (n *distinctNode) Next(params runParams) (bool, error) {
 		}
 
 		// Detect duplicates
-		prefix, suffix, err := n.encodeValues(n.Values())
+		prefix, suffix, err := n.encodeValues(n.plan.Values())
 		if err != nil {
 			return false, err
 		}
@@ -130,6 +245,10 @@ func (n *distinctNode) encodeValues(values parser.Datums) ([]byte, []byte, error
 	var prefix, suffix []byte
 	var err error
 	for i, val := range values {
+		if !n.distinctOnColIdxs.Empty() && !n.distinctOnColIdxs.Contains(i) {
+			// Only the DISTINCT ON columns are compared.
+			continue
+		}
 		if n.columnsInOrder != nil && n.columnsInOrder[i] {
 			if prefix == nil {
 				prefix = make([]byte, 0, 100)
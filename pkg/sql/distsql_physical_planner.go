@@ -162,6 +162,22 @@ func (v *distSQLExprCheckVisitor) VisitPre(expr parser.Expr) (recurse bool, newE
 			v.err = newQueryNotSupportedErrorf("function %s cannot be executed with distsql", t)
 			return false, expr
 		}
+
+	// User-defined types are resolved by name, which the remote nodes cannot
+	// do when they parse the expressions again.
+	case *parser.DEnum:
+		v.err = newQueryNotSupportedError("user-defined types not supported yet")
+		return false, expr
+	case *parser.CastExpr:
+		if _, ok := t.Type.(*parser.EnumColType); ok {
+			v.err = newQueryNotSupportedError("user-defined types not supported yet")
+			return false, expr
+		}
+	case *parser.AnnotateTypeExpr:
+		if _, ok := t.Type.(*parser.EnumColType); ok {
+			v.err = newQueryNotSupportedError("user-defined types not supported yet")
+			return false, expr
+		}
 	}
 	return true, expr
 }
@@ -2348,9 +2364,15 @@ func (dsp *distSQLPlanner) createPlanForDistinct(
 	}
 
 	var distinctColumns []uint32
-	for i := range planColumns(n) {
-		if plan.planToStreamColMap[i] != -1 {
+	if !n.distinctOnColIdxs.Empty() {
+		n.distinctOnColIdxs.ForEach(func(i int) {
 			distinctColumns = append(distinctColumns, uint32(plan.planToStreamColMap[i]))
+		})
+	} else {
+		for i := range planColumns(n) {
+			if plan.planToStreamColMap[i] != -1 {
+				distinctColumns = append(distinctColumns, uint32(plan.planToStreamColMap[i]))
+			}
 		}
 	}
 
@@ -2363,16 +2385,27 @@ func (dsp *distSQLPlanner) createPlanForDistinct(
 
 	if len(currentResultRouters) == 1 {
 		plan.AddNoGroupingStage(distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes, plan.MergeOrdering)
-		return plan, nil
-	}
+	} else {
+		// TODO(arjun): This is potentially memory inefficient if we don't have any sorted columns.
 
-	// TODO(arjun): This is potentially memory inefficient if we don't have any sorted columns.
+		// Add distinct processors local to each existing current result processor.
+		plan.AddNoGroupingStage(distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes, plan.MergeOrdering)
 
-	// Add distinct processors local to each existing current result processor.
-	plan.AddNoGroupingStage(distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes, plan.MergeOrdering)
+		// TODO(arjun): We could distribute this final stage by hash.
+		plan.AddSingleGroupStage(dsp.nodeDesc.NodeID, distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes)
+	}
 
-	// TODO(arjun): We could distribute this final stage by hash.
-	plan.AddSingleGroupStage(dsp.nodeDesc.NodeID, distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes)
+	if n.numOutputCols > 0 {
+		// We have columns that are only used for DISTINCT ON; we set a projection
+		// such that the plan results map 1-to-1 to distinctNode columns.
+		plan.planToStreamColMap = plan.planToStreamColMap[:n.numOutputCols]
+		columns := make([]uint32, n.numOutputCols)
+		for i, col := range plan.planToStreamColMap {
+			columns[i] = uint32(col)
+			plan.planToStreamColMap[i] = i
+		}
+		plan.AddProjection(columns)
+	}
 	return plan, nil
 }
 
@@ -131,7 +131,10 @@ func (d *distinct) mainLoop(ctx context.Context) (earlyExit bool, _ error) {
 		}
 
 		if _, ok := d.seen[string(encoding)]; !ok {
-			if len(encoding) > 0 {
+			// The encoding is empty if all the distinct columns are ordered; the
+			// group then has a single distinct row, so the empty encoding is
+			// recorded as well.
+			if len(d.distinctCols) > 0 {
 				if err := d.memAcc.Grow(ctx, int64(len(encoding))); err != nil {
 					return false, err
 				}
@@ -171,6 +174,11 @@ func (d *distinct) encode(appendTo []byte, row sqlbase.EncDatumRow) ([]byte, err
 		if _, distinct := d.distinctCols[uint32(i)]; !distinct {
 			continue
 		}
+		// Ordered columns have the same value for all the rows of the group,
+		// so they need not be part of the key.
+		if _, ordered := d.orderedCols[uint32(i)]; ordered {
+			continue
+		}
 
 		// TODO(irfansharif): Different rows may come with different encodings,
 		// e.g. if they come from different streams that were merged, in which
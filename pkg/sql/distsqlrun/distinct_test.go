@@ -105,6 +105,25 @@ func TestDistinct(t *testing.T) {
 				{v[5], v[6]},
 			},
 		},
+		{
+			spec: DistinctSpec{
+				OrderedColumns:  []uint32{0},
+				DistinctColumns: []uint32{0},
+			},
+			input: sqlbase.EncDatumRows{
+				{v[2], v[9]},
+				{v[2], v[3]},
+				{v[3], v[5]},
+				{v[5], v[6]},
+				{v[5], v[1]},
+				{v[5], v[8]},
+			},
+			expected: sqlbase.EncDatumRows{
+				{v[2], v[9]},
+				{v[3], v[5]},
+				{v[5], v[6]},
+			},
+		},
 		{
 			spec: DistinctSpec{
 				OrderedColumns:  []uint32{1},
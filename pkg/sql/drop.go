@@ -28,12 +28,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 )
 
 type dropDatabaseNode struct {
 	n      *parser.DropDatabase
 	dbDesc *sqlbase.DatabaseDescriptor
 	td     []*sqlbase.TableDescriptor
+	types  []*sqlbase.TypeDescriptor
 }
 
 // DropDatabase drops a database.
@@ -62,7 +64,7 @@ func (p *planner) DropDatabase(ctx context.Context, n *parser.DropDatabase) (pla
 		return nil, sqlbase.NewUndefinedDatabaseError(string(n.Name))
 	}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.DROP); err != nil {
 		return nil, err
 	}
 
@@ -71,7 +73,12 @@ func (p *planner) DropDatabase(ctx context.Context, n *parser.DropDatabase) (pla
 		return nil, err
 	}
 
-	if len(tbNames) > 0 {
+	types, err := getTypeDescs(ctx, p.txn, dbDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tbNames) > 0 || len(types) > 0 {
 		switch n.DropBehavior {
 		case parser.DropRestrict:
 			return nil, pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
@@ -113,7 +120,21 @@ func (p *planner) DropDatabase(ctx context.Context, n *parser.DropDatabase) (pla
 		return nil, err
 	}
 
-	return &dropDatabaseNode{n: n, dbDesc: dbDesc, td: td}, nil
+	// The tables of other databases can use the types of this database, for
+	// example through CREATE TABLE ... AS.
+	for _, typeDesc := range types {
+		tables, err := getReferencingTables(ctx, p.txn, typeDesc)
+		if err != nil {
+			return nil, err
+		}
+		for _, tableDesc := range tables {
+			if tableDesc.ParentID != dbDesc.ID {
+				return nil, typeInUseError(typeDesc, tableDesc)
+			}
+		}
+	}
+
+	return &dropDatabaseNode{n: n, dbDesc: dbDesc, td: td, types: types}, nil
 }
 
 // filterCascadedTables takes a list of table descriptors and removes any
@@ -188,6 +209,15 @@ func (n *dropDatabaseNode) Start(params runParams) error {
 	}
 	b.Del(descKey)
 	b.Del(nameKey)
+	for _, typeDesc := range n.types {
+		typeNameKey, typeDescKey := getKeysForTypeDescriptor(typeDesc)
+		if p.session.Tracing.KVTracingEnabled() {
+			log.VEventf(ctx, 2, "Del %s", typeDescKey)
+			log.VEventf(ctx, 2, "Del %s", typeNameKey)
+		}
+		b.Del(typeDescKey)
+		b.Del(typeNameKey)
+	}
 	// Delete the zone config entry for this database.
 	b.DelRange(zoneKeyPrefix, zoneKeyPrefix.PrefixEnd(), false /* returnKeys */)
 
@@ -230,6 +260,123 @@ func (*dropDatabaseNode) Next(runParams) (bool, error) { return false, nil }
 func (*dropDatabaseNode) Close(context.Context)        {}
 func (*dropDatabaseNode) Values() parser.Datums        { return parser.Datums{} }
 
+type dropTypeNode struct {
+	n      *parser.DropType
+	dbDesc *sqlbase.DatabaseDescriptor
+	td     []*sqlbase.TypeDescriptor
+}
+
+// DropType drops user-defined types.
+// Privileges: DROP on type.
+//   Notes: postgres allows only the type owner to DROP a type.
+func (p *planner) DropType(ctx context.Context, n *parser.DropType) (planNode, error) {
+	if p.session.Database == "" {
+		return nil, errNoDatabase
+	}
+
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	td := make([]*sqlbase.TypeDescriptor, 0, len(n.Names))
+	for _, name := range n.Names {
+		typeDesc, err := getTypeDesc(ctx, p.txn, dbDesc.ID, string(name))
+		if err != nil {
+			return nil, err
+		}
+		if typeDesc == nil {
+			if n.IfExists {
+				continue
+			}
+			return nil, sqlbase.NewUndefinedTypeError(string(name))
+		}
+
+		if err := p.CheckPrivilege(ctx, typeDesc, privilege.DROP); err != nil {
+			return nil, err
+		}
+
+		tables, err := getReferencingTables(ctx, p.txn, typeDesc)
+		if err != nil {
+			return nil, err
+		}
+		if len(tables) > 0 {
+			return nil, typeInUseError(typeDesc, tables[0])
+		}
+		td = append(td, typeDesc)
+	}
+	if len(td) == 0 {
+		return &zeroNode{}, nil
+	}
+
+	return &dropTypeNode{n: n, dbDesc: dbDesc, td: td}, nil
+}
+
+// typeInUseError is returned when a type cannot be dropped because one of
+// the columns of the given table uses it.
+func typeInUseError(typeDesc *sqlbase.TypeDescriptor, tableDesc *sqlbase.TableDescriptor) error {
+	msg := fmt.Sprintf("cannot drop type %q because table %q depends on it",
+		typeDesc.Name, tableDesc.Name)
+	return sqlbase.NewDependentObjectError(msg)
+}
+
+func getKeysForTypeDescriptor(
+	typeDesc *sqlbase.TypeDescriptor,
+) (nameKey roachpb.Key, descKey roachpb.Key) {
+	nameKey = tableKey{parentID: typeDesc.ParentID, name: typeDesc.Name}.Key()
+	descKey = sqlbase.MakeDescMetadataKey(typeDesc.ID)
+	return nameKey, descKey
+}
+
+func (n *dropTypeNode) Start(params runParams) error {
+	ctx := params.ctx
+	p := params.p
+
+	b := &client.Batch{}
+	typeIDs := make([]sqlbase.ID, len(n.td))
+	for i, typeDesc := range n.td {
+		nameKey, descKey := getKeysForTypeDescriptor(typeDesc)
+		if p.session.Tracing.KVTracingEnabled() {
+			log.VEventf(ctx, 2, "Del %s", descKey)
+			log.VEventf(ctx, 2, "Del %s", nameKey)
+		}
+		b.Del(descKey)
+		b.Del(nameKey)
+		typeIDs[i] = typeDesc.ID
+		p.session.tables.addUncommittedType(*typeDesc, true /* dropped */)
+	}
+	if err := p.txn.Run(ctx, b); err != nil {
+		return err
+	}
+	if err := p.writeDatabaseTypeIDs(ctx, n.dbDesc, nil /* added */, typeIDs); err != nil {
+		return err
+	}
+
+	for _, typeDesc := range n.td {
+		// Log a Drop Type event for this type. This is an auditable log event
+		// and is recorded in the same transaction as the descriptor deletion.
+		if err := MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+			ctx,
+			p.txn,
+			EventLogDropType,
+			int32(typeDesc.ID),
+			int32(p.evalCtx.NodeID),
+			struct {
+				TypeName  string
+				Statement string
+				User      string
+			}{typeDesc.Name, n.n.String(), p.session.User},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropTypeNode) Close(context.Context)        {}
+func (*dropTypeNode) Values() parser.Datums        { return parser.Datums{} }
+
 type dropIndexNode struct {
 	n        *parser.DropIndex
 	idxNames []fullIndexName
@@ -257,7 +404,7 @@ func (p *planner) DropIndex(ctx context.Context, n *parser.DropIndex) (planNode,
 			return nil, err
 		}
 
-		if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+		if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 			return nil, err
 		}
 
@@ -380,6 +527,9 @@ func (p *planner) dropIndexByName(
 	if !found {
 		return fmt.Errorf("index %q in the middle of being added, try again later", idxName)
 	}
+	if err := p.removeIndexZoneConfigs(ctx, tableDesc, idx.ID); err != nil {
+		return err
+	}
 
 	if err := tableDesc.Validate(ctx, p.txn); err != nil {
 		return err
@@ -417,6 +567,37 @@ func (p *planner) dropIndexByName(
 	return nil
 }
 
+// removeIndexZoneConfigs removes the zone configs of the index and of its
+// partitions, which are stored as subzones of the zone config of the table.
+func (p *planner) removeIndexZoneConfigs(
+	ctx context.Context, tableDesc *sqlbase.TableDescriptor, indexID sqlbase.IndexID,
+) error {
+	kv, err := p.txn.Get(ctx, sqlbase.MakeZoneKey(tableDesc.ID))
+	if err != nil {
+		return err
+	}
+	if kv.Value == nil {
+		return nil
+	}
+	zone, err := config.MigrateZoneConfig(kv.Value)
+	if err != nil {
+		return err
+	}
+	if !zone.DeleteIndexSubzones(uint32(indexID)) {
+		return nil
+	}
+	if zone.SubzoneSpans, err = sqlbase.GenerateSubzoneSpans(tableDesc, zone.Subzones); err != nil {
+		return err
+	}
+	buf, err := protoutil.Marshal(&zone)
+	if err != nil {
+		return err
+	}
+	const upsertZoneCfg = `UPSERT INTO system.zones (id, config) VALUES ($1, $2)`
+	_, err = p.exec(ctx, upsertZoneCfg, tableDesc.ID, buf)
+	return err
+}
+
 func (*dropIndexNode) Next(runParams) (bool, error) { return false, nil }
 func (*dropIndexNode) Close(context.Context)        {}
 func (*dropIndexNode) Values() parser.Datums        { return parser.Datums{} }
@@ -437,7 +618,7 @@ func (p *planner) DropView(ctx context.Context, n *parser.DropView) (planNode, e
 		if err != nil {
 			return nil, err
 		}
-		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		if err := p.qualifyTableName(ctx, tn); err != nil {
 			return nil, err
 		}
 
@@ -542,7 +723,7 @@ func (p *planner) DropTable(ctx context.Context, n *parser.DropTable) (planNode,
 		if err != nil {
 			return nil, err
 		}
-		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		if err := p.qualifyTableName(ctx, tn); err != nil {
 			return nil, err
 		}
 
@@ -610,7 +791,7 @@ func (p *planner) canRemoveFK(
 	if behavior != parser.DropCascade {
 		return nil, fmt.Errorf("%q is referenced by foreign key from table %q", from, table.Name)
 	}
-	if err := p.CheckPrivilege(table, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, table, privilege.CREATE); err != nil {
 		return nil, err
 	}
 	return table, nil
@@ -633,7 +814,7 @@ func (p *planner) canRemoveInterleave(
 		return pgerror.UnimplementedWithIssueErrorf(
 			8036, "%q is interleaved by table %q", from, table.Name)
 	}
-	if err := p.CheckPrivilege(table, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, table, privilege.CREATE); err != nil {
 		return err
 	}
 	return nil
@@ -660,7 +841,7 @@ func (p *planner) canRemoveDependentViewGeneric(
 	if err != nil {
 		return err
 	}
-	if err := p.CheckPrivilege(viewDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, viewDesc, privilege.DROP); err != nil {
 		return err
 	}
 	// If this view is depended on by other views, we have to check them as well.
@@ -783,7 +964,7 @@ func (p *planner) dropTableOrViewPrepare(
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.DROP); err != nil {
 		return nil, err
 	}
 	return tableDesc, nil
@@ -1046,15 +1227,31 @@ func (p *planner) getViewDescForCascade(
 	return viewDesc, nil
 }
 
+// dropUserNode drops users or, if isRole is set, roles.
 type dropUserNode struct {
-	n *parser.DropUser
+	names    parser.NameList
+	ifExists bool
+	isRole   bool
 	// The number of users deleted.
 	numDeleted int
 }
 
 func (n *dropUserNode) Start(params runParams) error {
+	userOrRole := "user"
+	// Users created before roles existed have a NULL isRole.
+	deleteStmt := `DELETE FROM system.users WHERE username=$1 AND "isRole" IS NOT TRUE`
+	if n.isRole {
+		userOrRole = "role"
+		deleteStmt = `DELETE FROM system.users WHERE username=$1 AND "isRole" = true`
+	}
+
+	// The memberships of the dropped users and roles are deleted below.
+	if err := params.p.bumpRoleMembersTableVersion(params.ctx); err != nil {
+		return err
+	}
+
 	numDeleted := 0
-	for _, name := range n.n.Names {
+	for _, name := range n.names {
 		normalizedUsername, err := NormalizeAndValidateUsername(string(name))
 		if err != nil {
 			return err
@@ -1071,15 +1268,36 @@ func (n *dropUserNode) Start(params runParams) error {
 			params.ctx,
 			"drop-user",
 			params.p.txn,
-			"DELETE FROM system.users WHERE username=$1",
+			deleteStmt,
 			normalizedUsername,
 		)
 		if err != nil {
 			return err
 		}
 
-		if rowsAffected == 0 && !n.n.IfExists {
-			return errors.Errorf("user %s does not exist", normalizedUsername)
+		if rowsAffected == 0 && !n.ifExists {
+			return errors.Errorf("%s %s does not exist", userOrRole, normalizedUsername)
+		}
+
+		// Drop the memberships of the user or role, and for a role, the
+		// memberships in it.
+		if _, err := internalExecutor.ExecuteStatementInTransaction(
+			params.ctx,
+			"drop-user",
+			params.p.txn,
+			`DELETE FROM system.role_members WHERE member = $1 OR "role" = $1`,
+			normalizedUsername,
+		); err != nil {
+			return err
+		}
+		if _, err := internalExecutor.ExecuteStatementInTransaction(
+			params.ctx,
+			"drop-user",
+			params.p.txn,
+			`DELETE FROM system.login_attempts WHERE username = $1`,
+			normalizedUsername,
+		); err != nil {
+			return err
 		}
 
 		numDeleted += rowsAffected
@@ -1103,9 +1321,24 @@ func (p *planner) DropUser(ctx context.Context, n *parser.DropUser) (planNode, e
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tDesc, privilege.DELETE); err != nil {
+	if err := p.CheckPrivilege(ctx, tDesc, privilege.DELETE); err != nil {
+		return nil, err
+	}
+
+	return &dropUserNode{names: n.Names, ifExists: n.IfExists}, nil
+}
+
+// DropRole drops a list of roles, along with their memberships.
+// Privileges: DELETE on system.users.
+func (p *planner) DropRole(ctx context.Context, n *parser.DropRole) (planNode, error) {
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &parser.TableName{DatabaseName: "system", TableName: "users"})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, tDesc, privilege.DELETE); err != nil {
 		return nil, err
 	}
 
-	return &dropUserNode{n: n}, nil
+	return &dropUserNode{names: n.Names, ifExists: n.IfExists, isRole: true}, nil
 }
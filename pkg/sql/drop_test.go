@@ -425,6 +425,70 @@ func TestDropIndex(t *testing.T) {
 	}
 }
 
+// TestDropIndexWithZoneConfig verifies that dropping an index removes its
+// zone config and those of its partitions from the zone config of its table.
+func TestDropIndexWithZoneConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	s, sqlDB, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if err := createKVTable(sqlDB, 10); err != nil {
+		t.Fatal(err)
+	}
+	tableDesc := sqlbase.GetTableDescriptor(kvDB, "t", "kv")
+	idx, _, err := tableDesc.FindIndexByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone := config.DefaultZoneConfig()
+	zone.SetSubzone(config.Subzone{
+		IndexID: uint32(tableDesc.PrimaryIndex.ID), Config: config.ZoneConfig{NumReplicas: 5},
+	})
+	zone.SetSubzone(config.Subzone{
+		IndexID: uint32(idx.ID), Config: config.ZoneConfig{NumReplicas: 1},
+	})
+	if zone.SubzoneSpans, err = sqlbase.GenerateSubzoneSpans(tableDesc, zone.Subzones); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := protoutil.Marshal(&zone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO system.zones VALUES ($1, $2)`, tableDesc.ID, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sqlDB.Exec(`DROP INDEX t.kv@foo`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sqlDB.QueryRow(
+		`SELECT config FROM system.zones WHERE id = $1`, tableDesc.ID,
+	).Scan(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var newZone config.ZoneConfig
+	if err := protoutil.Unmarshal(buf, &newZone); err != nil {
+		t.Fatal(err)
+	}
+	if len(newZone.Subzones) != 1 || newZone.Subzones[0].IndexID != uint32(tableDesc.PrimaryIndex.ID) {
+		t.Fatalf("expected only the subzone of the primary index, got %+v", newZone.Subzones)
+	}
+
+	// The subzone spans can still be generated from the new descriptor, as
+	// they are when the zone configs of the table are changed.
+	tableDesc = sqlbase.GetTableDescriptor(kvDB, "t", "kv")
+	spans, err := sqlbase.GenerateSubzoneSpans(tableDesc, newZone.Subzones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(spans, newZone.SubzoneSpans) {
+		t.Fatalf("expected subzone spans %+v, got %+v", spans, newZone.SubzoneSpans)
+	}
+}
+
 func createKVInterleavedTable(t *testing.T, sqlDB *gosql.DB, numRows int) {
 	// Fix the column families so the key counts don't change if the family
 	// heuristics are updated.
@@ -0,0 +1,95 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package enum generates the physical representations of the members of
+// user-defined ENUM types.
+//
+// A physical representation is a non-empty byte string without trailing
+// zero bytes. Members are ordered by the bytewise order of their physical
+// representations, which is what lets the key encoding of an ENUM value be
+// the plain bytes encoding of its physical representation. Because there is
+// always room between two such byte strings, a new member can be placed
+// anywhere in the type without rewriting the representations of the existing
+// members (and therefore without rewriting any stored data).
+package enum
+
+// GenerateEvenlySpacedBytes returns n byte strings that are spread out evenly
+// over the space of physical representations. It is used to assign the
+// representations of the members of a newly created type, so that later
+// additions between any two of them stay short.
+func GenerateEvenlySpacedBytes(n int) [][]byte {
+	if n == 0 {
+		return nil
+	}
+	// Use the smallest width that fits n distinct non-zero values.
+	width := 1
+	for space := uint64(256); space <= uint64(n); space *= 256 {
+		width++
+	}
+	space := uint64(1) << (8 * uint(width))
+	step := float64(space) / float64(n+1)
+	result := make([][]byte, n)
+	for i := range result {
+		v := uint64(float64(i+1) * step)
+		b := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			b[j] = byte(v)
+			v >>= 8
+		}
+		result[i] = trimTrailingZeros(b)
+	}
+	return result
+}
+
+// GenByteStringBetween returns a physical representation that sorts strictly
+// after prev and strictly before next. A nil prev stands for the start of the
+// space and an empty next for its end, so passing nil for both returns the
+// representation of the only member of a type. The caller must ensure that
+// prev sorts before next.
+func GenByteStringBetween(prev []byte, next []byte) []byte {
+	var result []byte
+	for i := 0; ; i++ {
+		p := 0
+		if i < len(prev) {
+			p = int(prev[i])
+		}
+		n := 256
+		if i < len(next) {
+			n = int(next[i])
+		}
+		if p == n {
+			// Still within the common prefix of prev and next.
+			result = append(result, byte(p))
+			continue
+		}
+		if n-p > 1 {
+			return append(result, byte((p+n)/2))
+		}
+		// There is no byte strictly between p and n. Keep p and find a suffix
+		// that sorts after the rest of prev; next no longer constrains it.
+		result = append(result, byte(p))
+		var rest []byte
+		if i+1 < len(prev) {
+			rest = prev[i+1:]
+		}
+		return append(result, GenByteStringBetween(rest, nil)...)
+	}
+}
+
+func trimTrailingZeros(b []byte) []byte {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return b
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package enum
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func checkValid(t *testing.T, b []byte) {
+	if len(b) == 0 {
+		t.Fatalf("generated an empty byte string")
+	}
+	if b[len(b)-1] == 0 {
+		t.Fatalf("generated %x, which has a trailing zero", b)
+	}
+}
+
+func TestGenerateEvenlySpacedBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	for _, n := range []int{0, 1, 2, 10, 254, 255, 256, 1000, 70000} {
+		reps := GenerateEvenlySpacedBytes(n)
+		if len(reps) != n {
+			t.Fatalf("%d: expected %d representations, got %d", n, n, len(reps))
+		}
+		for i := range reps {
+			checkValid(t, reps[i])
+			if i > 0 && bytes.Compare(reps[i-1], reps[i]) >= 0 {
+				t.Fatalf("%d: %x does not sort before %x", n, reps[i-1], reps[i])
+			}
+		}
+	}
+	if reps := GenerateEvenlySpacedBytes(3); len(reps[0]) != 1 {
+		t.Fatalf("expected single byte representations, got %x", reps)
+	}
+}
+
+func TestGenByteStringBetween(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	testCases := []struct {
+		prev, next, expected []byte
+	}{
+		{nil, nil, []byte{128}},
+		{[]byte{128}, nil, []byte{192}},
+		{nil, []byte{128}, []byte{64}},
+		{[]byte{1}, []byte{3}, []byte{2}},
+		{[]byte{1}, []byte{2}, []byte{1, 128}},
+		{[]byte{255}, nil, []byte{255, 128}},
+		{nil, []byte{1}, []byte{0, 128}},
+		{[]byte{1, 255}, []byte{2}, []byte{1, 255, 128}},
+		{[]byte{1, 2}, []byte{1, 2, 3}, []byte{1, 2, 1}},
+	}
+	for _, tc := range testCases {
+		if res := GenByteStringBetween(tc.prev, tc.next); !bytes.Equal(res, tc.expected) {
+			t.Errorf("between %x and %x: expected %x, got %x", tc.prev, tc.next, tc.expected, res)
+		}
+	}
+}
+
+// TestGenByteStringBetweenRandom inserts values at random positions and
+// checks that the resulting list stays sorted.
+func TestGenByteStringBetweenRandom(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	rng := rand.New(rand.NewSource(1))
+	reps := GenerateEvenlySpacedBytes(3)
+	for i := 0; i < 2000; i++ {
+		pos := rng.Intn(len(reps) + 1)
+		var prev, next []byte
+		if pos > 0 {
+			prev = reps[pos-1]
+		}
+		if pos < len(reps) {
+			next = reps[pos]
+		}
+		rep := GenByteStringBetween(prev, next)
+		checkValid(t, rep)
+		if (prev != nil && bytes.Compare(prev, rep) >= 0) ||
+			(next != nil && bytes.Compare(rep, next) >= 0) {
+			t.Fatalf("%x is not between %x and %x", rep, prev, next)
+		}
+		reps = append(reps, nil)
+		copy(reps[pos+1:], reps[pos:])
+		reps[pos] = rep
+	}
+}
@@ -42,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
@@ -248,6 +249,16 @@ type Executor struct {
 		syncutil.Mutex
 		counts map[string]int64
 	}
+
+	// hbaConf caches the parsed host-based authentication configuration.
+	// See HBAConfiguration.
+	hbaConf struct {
+		syncutil.Mutex
+		// source is the value of the setting last parsed.
+		source string
+		// conf is the last valid configuration.
+		conf *hba.Conf
+	}
 }
 
 // NodeInfo contains metadata about the executing node and cluster.
@@ -285,6 +296,10 @@ type ExecutorConfig struct {
 	// Caches updated by DistSQL.
 	RangeDescriptorCache *kv.RangeDescriptorCache
 	LeaseHolderCache     *kv.LeaseHolderCache
+
+	// roleMembershipCache is shared by the sessions of the executor. It is
+	// set by NewExecutor.
+	roleMembershipCache *roleMembershipCache
 }
 
 // Organization returns the value of cluster.organization.
@@ -370,6 +385,7 @@ type DistSQLPlannerTestingKnobs struct {
 // NewExecutor creates an Executor and registers a callback on the
 // system config.
 func NewExecutor(cfg ExecutorConfig, stopper *stop.Stopper) *Executor {
+	cfg.roleMembershipCache = &roleMembershipCache{}
 	return &Executor{
 		cfg:     cfg,
 		stopper: stopper,
@@ -2135,6 +2151,7 @@ func (e *Executor) execStmt(
 	plan, err := planner.makePlan(ctx, stmt)
 	planner.phaseTimes[plannerEndLogicalPlan] = timeutil.Now()
 	if err != nil {
+		planner.logAuditEvents(ctx, stmt, 0, err)
 		return err
 	}
 
@@ -2165,6 +2182,7 @@ func (e *Executor) execStmt(
 	e.recordStatementSummary(
 		planner, stmt, useDistSQL, automaticRetryCount, res, err,
 	)
+	planner.logAuditEvents(ctx, stmt, res.RowsAffected(), err)
 	if e.cfg.TestingKnobs.AfterExecute != nil {
 		e.cfg.TestingKnobs.AfterExecute(ctx, stmt.String(), res, err)
 	}
@@ -2195,6 +2213,7 @@ func (e *Executor) execStmtInParallel(
 
 	plan, err := planner.makePlan(ctx, stmt)
 	if err != nil {
+		planner.logAuditEvents(ctx, stmt, 0, err)
 		return err
 	}
 
@@ -2224,6 +2243,7 @@ func (e *Executor) execStmtInParallel(
 		err = e.execClassic(planner, plan, bufferedWriter)
 		planner.phaseTimes[plannerEndExecStmt] = timeutil.Now()
 		e.recordStatementSummary(planner, stmt, false, 0, bufferedWriter, err)
+		planner.logAuditEvents(ctx, stmt, bufferedWriter.RowsAffected(), err)
 		if e.cfg.TestingKnobs.AfterExecute != nil {
 			e.cfg.TestingKnobs.AfterExecute(ctx, stmt.String(), bufferedWriter, err)
 		}
@@ -2352,6 +2372,8 @@ func checkResultType(typ parser.Type) error {
 	case parser.TypeBytes:
 	case parser.TypeString:
 	case parser.TypeDate:
+	case parser.TypeTime:
+	case parser.TypeTimeTZ:
 	case parser.TypeTimestamp:
 	case parser.TypeTimestampTZ:
 	case parser.TypeInterval:
@@ -22,6 +22,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
 )
 
 // expandPlan finalizes type checking of placeholders and expands
@@ -199,28 +200,52 @@ func doExpandPlan(
 
 		ordering := planPhysicalProps(n.plan)
 		if !ordering.isEmpty() {
-			// If any of the columns form a key, we already know that all rows are
-			// unique. Elide the distinctNode.
-			if len(ordering.keySets) > 0 {
-				return n.plan, nil
+			if n.distinctOnColIdxs.Empty() {
+				// If any of the columns form a key, we already know that all rows are
+				// unique. Elide the distinctNode.
+				if len(ordering.keySets) > 0 {
+					return n.plan, nil
+				}
+			} else if n.numOutputCols == 0 {
+				// Likewise with DISTINCT ON if the DISTINCT ON columns form a key.
+				var groups util.FastIntSet
+				n.distinctOnColIdxs.ForEach(func(i int) {
+					groups.Add(ordering.eqGroups.Find(i))
+				})
+				if ordering.groupContainsKey(groups) {
+					return n.plan, nil
+				}
 			}
 
 			// The distinctNode can take advantage of any ordering. It only needs to
 			// know the set of columns S that contribute to the ordering (it keeps
 			// track of distinct elements within each group of rows with equal values
-			// on S).
-			n.columnsInOrder = make([]bool, len(planColumns(n.plan)))
+			// on S). With DISTINCT ON, S is restricted to the leftmost columns of the
+			// ordering that are DISTINCT ON columns.
+			numCols := len(planColumns(n.plan))
+			isDistinctCol := func(i int) bool {
+				return n.distinctOnColIdxs.Empty() || n.distinctOnColIdxs.Contains(i)
+			}
+			var distinctGroups, orderedGroups util.FastIntSet
+			for i := 0; i < numCols; i++ {
+				if isDistinctCol(i) {
+					distinctGroups.Add(ordering.eqGroups.Find(i))
+				}
+			}
+			for _, g := range ordering.ordering {
+				if !distinctGroups.Contains(g.ColIdx) {
+					break
+				}
+				orderedGroups.Add(g.ColIdx)
+			}
+			n.columnsInOrder = make([]bool, numCols)
 			for i := range n.columnsInOrder {
-				group := ordering.eqGroups.Find(i)
-				if ordering.constantCols.Contains(group) {
-					n.columnsInOrder[i] = true
+				if !isDistinctCol(i) {
 					continue
 				}
-				for _, g := range ordering.ordering {
-					if g.ColIdx == group {
-						n.columnsInOrder[i] = true
-						break
-					}
+				group := ordering.eqGroups.Find(i)
+				if ordering.constantCols.Contains(group) || orderedGroups.Contains(group) {
+					n.columnsInOrder[i] = true
 				}
 			}
 		}
@@ -251,18 +276,25 @@ func doExpandPlan(
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterTypeNode:
+	case *alterUserNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createPolicyNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropPolicyNode:
 	case *dropTableNode:
+	case *dropTypeNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshMaterializedViewNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
@@ -572,18 +604,25 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterTypeNode:
+	case *alterUserNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createPolicyNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropPolicyNode:
 	case *dropTableNode:
+	case *dropTypeNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshMaterializedViewNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
@@ -78,6 +78,9 @@ import (
 //
 //     f( [distinct FROM A], P )  = T, [distinct FROM w(B, P) ]
 //
+// (With DISTINCT ON, only the part of P that uses the DISTINCT ON
+// columns alone is propagated; the rest remains above the distinct.)
+//
 //     f( [sort FROM A], P )      = T, [sort FROM w(B, P) ]
 //
 // Some nodes "block" filter propagation entirely:
@@ -189,6 +192,21 @@ func (p *planner) propagateFilters(
 		return newPlan, parser.DBoolTrue, nil
 
 	case *scanNode:
+		if n.policyBarrier {
+			// The filter is applied above the scan, once the policies of the
+			// table have filtered the rows. Its conjuncts which can neither
+			// fail nor reveal the values they are evaluated on are also
+			// merged into the scan's filter, so that they can constrain the
+			// index spans.
+			var safe parser.TypedExpr
+			for _, e := range splitAndExpr(&p.evalCtx, extraFilter, nil) {
+				if isPolicySafeFilter(e) {
+					safe = mergeConj(safe, e)
+				}
+			}
+			n.filter = mergeConj(n.filter, n.filterVars.Rebind(safe, false, false))
+			return plan, extraFilter, nil
+		}
 		n.filter = mergeConj(n.filter, n.filterVars.Rebind(extraFilter, true, false))
 		return plan, parser.DBoolTrue, nil
 
@@ -213,6 +231,9 @@ func (p *planner) propagateFilters(
 		panic("filter optimization must occur before index selection")
 
 	case *distinctNode:
+		if !n.distinctOnColIdxs.Empty() {
+			return p.addDistinctOnFilter(ctx, n, extraFilter)
+		}
 		// A distinct node can propagate a filter. Source filtering
 		// reduces the amount of work.
 		n.plan, err = p.propagateOrWrapFilters(ctx, n.plan, nil, extraFilter)
@@ -322,18 +343,25 @@ func (p *planner) propagateFilters(
 		}
 
 	case *alterTableNode:
+	case *alterTypeNode:
+	case *alterUserNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createPolicyNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropPolicyNode:
 	case *dropTableNode:
+	case *dropTypeNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshMaterializedViewNode:
 	case *hookFnNode:
 	case *valueGenerator:
 	case *valuesNode:
@@ -435,6 +463,38 @@ func (p *planner) addGroupFilter(
 	return g, extraFilter, nil
 }
 
+// addDistinctOnFilter attempts to add the extraFilter to a distinctNode with
+// DISTINCT ON columns. Only the part of the filter that depends on the
+// DISTINCT ON columns alone is propagated to the source: filtering on the
+// other columns before de-duplication would change the rows returned.
+func (p *planner) addDistinctOnFilter(
+	ctx context.Context, n *distinctNode, extraFilter parser.TypedExpr,
+) (planNode, parser.TypedExpr, error) {
+	// innerFilter is the passed-through filter on the source planNode.
+	var innerFilter parser.TypedExpr = parser.DBoolTrue
+
+	if !isFilterTrue(extraFilter) {
+		convFunc := func(v parser.VariableExpr) (bool, parser.Expr) {
+			if iv, ok := v.(*parser.IndexedVar); ok && n.distinctOnColIdxs.Contains(iv.Idx) {
+				return true, v
+			}
+			return false, v
+		}
+		innerFilter, extraFilter = splitFilter(extraFilter, convFunc)
+	}
+
+	// Propagate the inner filter.
+	newPlan, err := p.propagateOrWrapFilters(ctx, n.plan, nil, innerFilter)
+	if err != nil {
+		return n, extraFilter, err
+	}
+
+	// Attach what remains as the new source.
+	n.plan = newPlan
+
+	return n, extraFilter, nil
+}
+
 // addRenderFilter attempts to add the extraFilter to the renderNode.
 // The filter is only propagated to the sub-plan if it is expressed
 // using renders that are either simple datums or simple column
@@ -804,6 +864,31 @@ func mergeConj(left, right parser.TypedExpr) parser.TypedExpr {
 	return parser.NewTypedAndExpr(left, right)
 }
 
+// isPolicySafeFilter returns whether expr compares a column to a constant.
+// Such a comparison cannot fail nor reveal the value it is evaluated on, so
+// it can be evaluated on rows which the row-level security policies of a
+// table do not allow.
+func isPolicySafeFilter(expr parser.TypedExpr) bool {
+	c, ok := expr.(*parser.ComparisonExpr)
+	if !ok {
+		return false
+	}
+	switch c.Operator {
+	case parser.EQ, parser.LT, parser.GT, parser.LE, parser.GE, parser.NE, parser.In, parser.NotIn:
+	default:
+		return false
+	}
+	left, right := c.TypedLeft(), c.TypedRight()
+	if _, ok := left.(*parser.IndexedVar); !ok {
+		left, right = right, left
+	}
+	if _, ok := left.(*parser.IndexedVar); !ok {
+		return false
+	}
+	_, ok = right.(parser.Datum)
+	return ok
+}
+
 func isFilterTrue(expr parser.TypedExpr) bool {
 	return expr == nil || expr == parser.DBoolTrue
 }
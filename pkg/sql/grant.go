@@ -15,8 +15,10 @@
 package sql
 
 import (
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -25,21 +27,31 @@ import (
 func (p *planner) changePrivileges(
 	ctx context.Context,
 	targets parser.TargetList,
+	columns parser.NameList,
 	grantees parser.NameList,
 	changePrivilege func(*sqlbase.PrivilegeDescriptor, string),
 ) (planNode, error) {
+	if columns != nil && targets.Databases != nil {
+		return nil, errors.New("column privileges can only be specified on tables")
+	}
 	descriptors, err := getDescriptorsFromTargetList(ctx, p.txn, p.getVirtualTabler(), p.session.Database, targets)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, descriptor := range descriptors {
-		if err := p.CheckPrivilege(descriptor, privilege.GRANT); err != nil {
+		if err := p.CheckPrivilege(ctx, descriptor, privilege.GRANT); err != nil {
 			return nil, err
 		}
-		privileges := descriptor.GetPrivileges()
-		for _, grantee := range grantees {
-			changePrivilege(privileges, string(grantee))
+		if columns != nil {
+			if err := changeColumnPrivileges(descriptor, columns, grantees, changePrivilege); err != nil {
+				return nil, err
+			}
+		} else {
+			privileges := descriptor.GetPrivileges()
+			for _, grantee := range grantees {
+				changePrivilege(privileges, string(grantee))
+			}
 		}
 
 		switch d := descriptor.(type) {
@@ -70,9 +82,61 @@ func (p *planner) changePrivileges(
 	return &zeroNode{}, nil
 }
 
+// changeColumnPrivileges applies changePrivilege to the privileges of the
+// given columns of the table descriptor, for each grantee.
+func changeColumnPrivileges(
+	descriptor sqlbase.DescriptorProto,
+	columns parser.NameList,
+	grantees parser.NameList,
+	changePrivilege func(*sqlbase.PrivilegeDescriptor, string),
+) error {
+	desc, ok := descriptor.(*sqlbase.TableDescriptor)
+	if !ok || !desc.IsTable() {
+		return errors.Errorf("column privileges can only be specified on tables, not on %s %s",
+			descriptor.TypeName(), descriptor.GetName())
+	}
+	for _, name := range columns {
+		c, err := desc.FindActiveColumnByName(string(name))
+		if err != nil {
+			return err
+		}
+		col, err := desc.FindActiveColumnByID(c.ID)
+		if err != nil {
+			return err
+		}
+		if col.Privileges == nil {
+			col.Privileges = &sqlbase.PrivilegeDescriptor{}
+		}
+		for _, grantee := range grantees {
+			changePrivilege(col.Privileges, string(grantee))
+		}
+		if len(col.Privileges.Users) == 0 {
+			col.Privileges = nil
+		}
+	}
+	return nil
+}
+
+// columnPrivilegeList checks that the privileges can be granted on columns,
+// expanding ALL into the column privileges.
+func columnPrivilegeList(privs privilege.List) (privilege.List, error) {
+	for _, priv := range privs {
+		switch priv {
+		case privilege.ALL:
+			return privilege.ColumnPrivileges, nil
+		case privilege.SELECT, privilege.UPDATE:
+		default:
+			return nil, errors.Errorf("invalid privilege type %s for column", priv)
+		}
+	}
+	return privs, nil
+}
+
 // Grant adds privileges to users.
 // Current status:
 // - Target: single database, table, or view.
+// - Target: columns of tables, if the column list is specified. Only
+//   SELECT and UPDATE can be granted on columns.
 // TODO(marc): open questions:
 // - should we have root always allowed and not present in the permissions list?
 // - should we make users case-insensitive?
@@ -80,14 +144,23 @@ func (p *planner) changePrivileges(
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
 func (p *planner) Grant(ctx context.Context, n *parser.Grant) (planNode, error) {
-	return p.changePrivileges(ctx, n.Targets, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
-		privDesc.Grant(grantee, n.Privileges)
+	privs := n.Privileges
+	if n.Columns != nil {
+		var err error
+		if privs, err = columnPrivilegeList(privs); err != nil {
+			return nil, err
+		}
+	}
+	return p.changePrivileges(ctx, n.Targets, n.Columns, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
+		privDesc.Grant(grantee, privs)
 	})
 }
 
 // Revoke removes privileges from users.
 // Current status:
 // - Target: single database, table, or view.
+// - Target: columns of tables, if the column list is specified. Revoking
+//   privileges on the table does not revoke those granted on its columns.
 // TODO(marc): open questions:
 // - should we have root always allowed and not present in the permissions list?
 // - should we make users case-insensitive?
@@ -95,7 +168,154 @@ func (p *planner) Grant(ctx context.Context, n *parser.Grant) (planNode, error)
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
 func (p *planner) Revoke(ctx context.Context, n *parser.Revoke) (planNode, error) {
-	return p.changePrivileges(ctx, n.Targets, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
-		privDesc.Revoke(grantee, n.Privileges)
+	privs := n.Privileges
+	if n.Columns != nil {
+		var err error
+		if privs, err = columnPrivilegeList(privs); err != nil {
+			return nil, err
+		}
+	}
+	return p.changePrivileges(ctx, n.Targets, n.Columns, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
+		privDesc.Revoke(grantee, privs)
 	})
 }
+
+// checkRoleAdmin verifies that the session user may change the memberships
+// of the given roles: either it has the privilege on system.role_members,
+// or it holds the admin option on every role.
+func (p *planner) checkRoleAdmin(
+	ctx context.Context, priv privilege.Kind, roles parser.NameList,
+) error {
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(),
+		&parser.TableName{DatabaseName: "system", TableName: "role_members"})
+	if err != nil {
+		return err
+	}
+	if tDesc.Privileges.CheckPrivilege(p.session.User, priv) {
+		return nil
+	}
+	memberships, err := p.MemberOfWithAdminOption(ctx, p.session.User)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if !memberships[string(role)] {
+			return errors.Errorf("%s is not a superuser or role admin for role %s",
+				p.session.User, role)
+		}
+	}
+	return nil
+}
+
+// lookupRole returns whether name exists in system.users and, if so, whether
+// it is a role. The root user is not stored in system.users, but exists.
+func (p *planner) lookupRole(ctx context.Context, name string) (exists, isRole bool, _ error) {
+	if name == security.RootUser {
+		return true, false, nil
+	}
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	row, err := ie.QueryRowInTransaction(ctx, "lookup-role", p.txn,
+		`SELECT "isRole" FROM system.users WHERE username = $1`, name)
+	if err != nil || row == nil {
+		return false, false, err
+	}
+	return true, row[0] == parser.DBoolTrue, nil
+}
+
+// GrantRole adds members to roles.
+// Privileges: INSERT on system.role_members, or the admin option on each
+// role.
+//   Notes: postgres requires CREATEROLE or the admin option.
+func (p *planner) GrantRole(ctx context.Context, n *parser.GrantRole) (planNode, error) {
+	if err := p.checkRoleAdmin(ctx, privilege.INSERT, n.Roles); err != nil {
+		return nil, err
+	}
+
+	for _, role := range n.Roles {
+		if _, isRole, err := p.lookupRole(ctx, string(role)); err != nil {
+			return nil, err
+		} else if !isRole {
+			return nil, errors.Errorf("role %s does not exist", role)
+		}
+	}
+	for _, member := range n.Members {
+		if exists, _, err := p.lookupRole(ctx, string(member)); err != nil {
+			return nil, err
+		} else if !exists {
+			return nil, errors.Errorf("user or role %s does not exist", member)
+		}
+	}
+
+	// Reject memberships that would make a role a member of itself. This
+	// is the case if the role is already a (transitive) member of the new
+	// member.
+	for _, role := range n.Roles {
+		memberships, err := p.MemberOfWithAdminOption(ctx, string(role))
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range n.Members {
+			if _, ok := memberships[string(member)]; ok || role == member {
+				return nil, errors.Errorf("making %s a member of %s would create a cycle",
+					member, role)
+			}
+		}
+	}
+
+	// Without the admin option, an existing membership is left as is so
+	// that a previously granted admin option is not lost.
+	stmt := `INSERT INTO system.role_members ("role", member, "isAdmin") VALUES ($1, $2, $3) ` +
+		`ON CONFLICT ("role", member) DO NOTHING`
+	if n.AdminOption {
+		stmt = `UPSERT INTO system.role_members ("role", member, "isAdmin") VALUES ($1, $2, $3)`
+	}
+	if err := p.bumpRoleMembersTableVersion(ctx); err != nil {
+		return nil, err
+	}
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	for _, role := range n.Roles {
+		for _, member := range n.Members {
+			if _, err := ie.ExecuteStatementInTransaction(ctx, "grant-role", p.txn,
+				stmt, string(role), string(member), n.AdminOption); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &zeroNode{}, nil
+}
+
+// RevokeRole removes members from roles, or with ADMIN OPTION FOR, only
+// removes their admin option.
+// Privileges: DELETE on system.role_members, or the admin option on each
+// role.
+func (p *planner) RevokeRole(ctx context.Context, n *parser.RevokeRole) (planNode, error) {
+	if err := p.checkRoleAdmin(ctx, privilege.DELETE, n.Roles); err != nil {
+		return nil, err
+	}
+
+	for _, role := range n.Roles {
+		if _, isRole, err := p.lookupRole(ctx, string(role)); err != nil {
+			return nil, err
+		} else if !isRole {
+			return nil, errors.Errorf("role %s does not exist", role)
+		}
+	}
+
+	stmt := `DELETE FROM system.role_members WHERE "role" = $1 AND member = $2`
+	if n.AdminOption {
+		stmt = `UPDATE system.role_members SET "isAdmin" = false WHERE "role" = $1 AND member = $2`
+	}
+	if err := p.bumpRoleMembersTableVersion(ctx); err != nil {
+		return nil, err
+	}
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	for _, role := range n.Roles {
+		for _, member := range n.Members {
+			if _, err := ie.ExecuteStatementInTransaction(ctx, "revoke-role", p.txn,
+				stmt, string(role), string(member)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &zeroNode{}, nil
+}
@@ -34,6 +34,7 @@ const (
 var informationSchema = virtualSchema{
 	name: informationSchemaName,
 	tables: []virtualSchemaTable{
+		informationSchemaColumnPrivileges,
 		informationSchemaColumnsTable,
 		informationSchemaKeyColumnUsageTable,
 		informationSchemaSchemataTable,
@@ -96,6 +97,47 @@ func dIntFnOrNull(fn func() (int32, bool)) parser.Datum {
 	return parser.DNull
 }
 
+var informationSchemaColumnPrivileges = virtualSchemaTable{
+	schema: `
+CREATE TABLE information_schema.column_privileges (
+	GRANTOR STRING NOT NULL DEFAULT '',
+	GRANTEE STRING NOT NULL DEFAULT '',
+	TABLE_CATALOG STRING NOT NULL DEFAULT '',
+	TABLE_SCHEMA STRING NOT NULL DEFAULT '',
+	TABLE_NAME STRING NOT NULL DEFAULT '',
+	COLUMN_NAME STRING NOT NULL DEFAULT '',
+	PRIVILEGE_TYPE STRING NOT NULL DEFAULT '',
+	IS_GRANTABLE BOOL NOT NULL DEFAULT FALSE
+);
+`,
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...parser.Datum) error) error {
+		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+			return forEachColumnInTable(table, func(column *sqlbase.ColumnDescriptor) error {
+				if column.Privileges == nil {
+					return nil
+				}
+				for _, u := range column.Privileges.Show() {
+					for _, privilege := range u.Privileges {
+						if err := addRow(
+							parser.DNull,                   // grantor
+							parser.NewDString(u.User),      // grantee
+							defString,                      // table_catalog
+							parser.NewDString(db.Name),     // table_schema
+							parser.NewDString(table.Name),  // table_name
+							parser.NewDString(column.Name), // column_name
+							parser.NewDString(privilege),   // privilege_type
+							parser.DNull,                   // is_grantable
+						); err != nil {
+							return err
+						}
+					}
+				}
+				return nil
+			})
+		})
+	},
+}
+
 var informationSchemaColumnsTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE information_schema.columns (
@@ -606,7 +648,11 @@ func forEachDatabaseDesc(
 
 	sort.Sort(sortedDBDescs(dbDescs))
 	for _, db := range dbDescs {
-		if userCanSeeDatabase(db, p.session.User) {
+		canSee, err := p.userCanSeeDescriptor(ctx, db)
+		if err != nil {
+			return err
+		}
+		if canSee && isTempDatabaseVisible(db.Name, p.session) {
 			if err := fn(db); err != nil {
 				return err
 			}
@@ -640,6 +686,57 @@ func forEachTableDesc(
 	})
 }
 
+// forEachTypeDesc retrieves all user-defined type descriptors and iterates
+// through them in lexicographical order with respect primarily to database
+// name and secondarily to type name. For each type, the function will call fn
+// with its respective database and type descriptor.
+//
+// The prefix argument has the same meaning as for forEachTableDesc.
+func forEachTypeDesc(
+	ctx context.Context,
+	p *planner,
+	prefix string,
+	fn func(*sqlbase.DatabaseDescriptor, *sqlbase.TypeDescriptor) error,
+) error {
+	descs, err := getAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return err
+	}
+	dbs := make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
+	var types []*sqlbase.TypeDescriptor
+	for _, desc := range descs {
+		switch t := desc.(type) {
+		case *sqlbase.DatabaseDescriptor:
+			dbs[t.ID] = t
+		case *sqlbase.TypeDescriptor:
+			types = append(types, t)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if a, b := dbs[types[i].ParentID], dbs[types[j].ParentID]; a != nil && b != nil && a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return types[i].Name < types[j].Name
+	})
+	for _, typ := range types {
+		db, ok := dbs[typ.ParentID]
+		if !ok || !isDatabaseVisible(db.Name, prefix, p.session.User) ||
+			!isTempDatabaseVisible(db.Name, p.session) {
+			continue
+		}
+		canSee, err := p.userCanSeeDescriptor(ctx, typ)
+		if err != nil {
+			return err
+		}
+		if canSee {
+			if err := fn(db, typ); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // forEachTableDescAll does the same as forEachTableDesc but also
 // includes newly added non-public descriptors.
 func forEachTableDescAll(
@@ -790,7 +887,7 @@ func forEachTableDescWithTableLookupInternal(
 	}
 	sort.Strings(dbNames)
 	for _, dbName := range dbNames {
-		if !isDatabaseVisible(dbName, prefix, p.session.User) {
+		if !isDatabaseVisible(dbName, prefix, p.session.User) || !isTempDatabaseVisible(dbName, p.session) {
 			continue
 		}
 		db := databases[dbName]
@@ -801,7 +898,11 @@ func forEachTableDescWithTableLookupInternal(
 		sort.Strings(dbTableNames)
 		for _, tableName := range dbTableNames {
 			tableDesc := db.tables[tableName]
-			if userCanSeeTable(tableDesc, p.session.User, allowAdding) {
+			canSee, err := p.userCanSeeTable(ctx, tableDesc, allowAdding)
+			if err != nil {
+				return err
+			}
+			if canSee {
 				if err := fn(db.desc, tableDesc, tableLookup); err != nil {
 					return err
 				}
@@ -860,8 +961,13 @@ func forEachColumnInIndex(
 	return nil
 }
 
-func forEachUser(ctx context.Context, p *planner, fn func(username string) error) error {
-	query := `SELECT username FROM system.users`
+// forEachRole calls fn for every user and role, including the root user.
+func forEachRole(
+	ctx context.Context,
+	p *planner,
+	fn func(username string, isRole bool, validUntil parser.Datum) error,
+) error {
+	query := `SELECT username, "isRole", "validUntil" FROM system.users`
 	plan, err := p.query(ctx, query)
 	if err != nil {
 		return nil
@@ -873,7 +979,7 @@ func forEachUser(ctx context.Context, p *planner, fn func(username string) error
 
 	// TODO(cuongdo/asubiotto): Get rid of root user special-casing if/when a row
 	// for "root" exists in system.user.
-	if err := fn(security.RootUser); err != nil {
+	if err := fn(security.RootUser, false, parser.DNull); err != nil {
 		return err
 	}
 	params := runParams{
@@ -891,21 +997,20 @@ func forEachUser(ctx context.Context, p *planner, fn func(username string) error
 		}
 		row := plan.Values()
 		username := parser.MustBeDString(row[0])
-		if err := fn(string(username)); err != nil {
+		isRole := row[1] == parser.DBoolTrue
+		if err := fn(string(username), isRole, row[2]); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func userCanSeeDatabase(db *sqlbase.DatabaseDescriptor, user string) bool {
-	return userCanSeeDescriptor(db, user)
-}
-
-func userCanSeeTable(table *sqlbase.TableDescriptor, user string, allowAdding bool) bool {
+func (p *planner) userCanSeeTable(
+	ctx context.Context, table *sqlbase.TableDescriptor, allowAdding bool,
+) (bool, error) {
 	if !(table.State == sqlbase.TableDescriptor_PUBLIC ||
 		(allowAdding && table.State == sqlbase.TableDescriptor_ADD)) {
-		return false
+		return false, nil
 	}
-	return userCanSeeDescriptor(table, user)
+	return p.userCanSeeDescriptor(ctx, table)
 }
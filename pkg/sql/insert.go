@@ -77,7 +77,7 @@ type insertNode struct {
 func (p *planner) Insert(
 	ctx context.Context, n *parser.Insert, desiredTypes []parser.Type,
 ) (planNode, error) {
-	tn, err := p.getAliasedTableName(n.Table)
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +89,7 @@ func (p *planner) Insert(
 	isUpsertReturning := false
 	if n.OnConflict != nil {
 		if !n.OnConflict.DoNothing {
-			if err := p.CheckPrivilege(en.tableDesc, privilege.UPDATE); err != nil {
+			if err := p.CheckPrivilege(ctx, en.tableDesc, privilege.UPDATE); err != nil {
 				return nil, err
 			}
 		}
@@ -264,6 +264,11 @@ func (p *planner) Insert(
 	if err := in.checkHelper.init(ctx, p, tn, en.tableDesc); err != nil {
 		return nil, err
 	}
+	if tu, ok := tw.(*tableUpserter); ok && tu.evaler != nil && p.rowLevelSecurityApplies(en.tableDesc) {
+		// The rows updated on conflict are checked like the inserted ones.
+		tu.checkHelper = &in.checkHelper
+		tu.evalCtx = &p.evalCtx
+	}
 
 	if err := in.run.initEditNode(
 		ctx, &in.editNodeBase, rows, in.tw, tn, n.Returning, desiredTypes); err != nil {
@@ -15,6 +15,8 @@
 package jobs
 
 import (
+	"golang.org/x/net/context"
+
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
@@ -119,3 +121,9 @@ func (nl *FakeNodeLiveness) FakeSetExpiration(id roachpb.NodeID, ts hlc.Timestam
 	defer nl.mu.Unlock()
 	nl.mu.livenessMap[id].Expiration = hlc.LegacyTimestamp(ts)
 }
+
+// DeleteExpiredProtectedTimestamps exposes deleteExpiredProtectedTimestamps
+// for tests.
+func (r *Registry) DeleteExpiredProtectedTimestamps(ctx context.Context) error {
+	return r.deleteExpiredProtectedTimestamps(ctx)
+}
@@ -22,12 +22,14 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
@@ -66,6 +68,7 @@ type Details interface{}
 var _ Details = BackupDetails{}
 var _ Details = RestoreDetails{}
 var _ Details = SchemaChangeDetails{}
+var _ Details = MaterializedViewRefreshDetails{}
 
 // Record stores the job fields that are not automatically managed by Job.
 type Record struct {
@@ -386,6 +389,88 @@ func (j *Job) SetDetails(ctx context.Context, details interface{}) error {
 	})
 }
 
+// ProtectTimestamp prevents the GC queue from collecting any values in spans
+// which are visible at ts, returning the ID of the record it writes to
+// system.protected_ts_records. The protection lasts until the record is
+// released with ReleaseProtectedTimestamp or until expiration, whichever comes
+// first; the expiration ensures that jobs which die without releasing their
+// records do not block GC forever. The GC queue reads the records from the
+// table rather than waiting for them to be gossiped, so the protection is in
+// effect for any GC run starting after ProtectTimestamp returns. Jobs which
+// run for longer than the expiration must extend it with
+// ExtendProtectedTimestamp as they progress.
+func (j *Job) ProtectTimestamp(
+	ctx context.Context, ts hlc.Timestamp, spans []roachpb.Span, expiration hlc.Timestamp,
+) (int64, error) {
+	if j.id == nil {
+		return 0, errors.New("job has not been created")
+	}
+	record := config.ProtectedTimestampRecord{
+		Timestamp:  ts,
+		Spans:      spans,
+		Expiration: expiration,
+	}
+	recordBytes, err := protoutil.Marshal(&record)
+	if err != nil {
+		return 0, err
+	}
+
+	var row parser.Datums
+	if err := j.runInTxn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		const stmt = `INSERT INTO system.protected_ts_records ("jobID", record) VALUES ($1, $2) RETURNING id`
+		row, err = j.registry.ex.QueryRowInTransaction(ctx, "protect-timestamp", txn, stmt, *j.id, recordBytes)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return int64(*row[0].(*parser.DInt)), nil
+}
+
+// ExtendProtectedTimestamp moves the expiration of the protected timestamp
+// record with the given ID, as returned by ProtectTimestamp, to expiration.
+// It returns an error if the record no longer exists, e.g. because it expired
+// and was deleted, in which case the data it protected may have been
+// collected.
+func (j *Job) ExtendProtectedTimestamp(
+	ctx context.Context, recordID int64, expiration hlc.Timestamp,
+) error {
+	return j.runInTxn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		const stmt = "SELECT record FROM system.protected_ts_records WHERE id = $1"
+		row, err := j.registry.ex.QueryRowInTransaction(ctx, "get-protected-timestamp", txn, stmt, recordID)
+		if err != nil {
+			return err
+		}
+		if row == nil {
+			return errors.Errorf("protected timestamp record %d not found", recordID)
+		}
+		var record config.ProtectedTimestampRecord
+		if err := protoutil.Unmarshal([]byte(*row[0].(*parser.DBytes)), &record); err != nil {
+			return err
+		}
+		record.Expiration = expiration
+		recordBytes, err := protoutil.Marshal(&record)
+		if err != nil {
+			return err
+		}
+		const updateStmt = "UPDATE system.protected_ts_records SET record = $2 WHERE id = $1"
+		_, err = j.registry.ex.ExecuteStatementInTransaction(
+			ctx, "extend-protected-timestamp", txn, updateStmt, recordID, recordBytes,
+		)
+		return err
+	})
+}
+
+// ReleaseProtectedTimestamp deletes the protected timestamp record with the
+// given ID, as returned by ProtectTimestamp. It is not an error to release a
+// record which has already been deleted, e.g. because it expired.
+func (j *Job) ReleaseProtectedTimestamp(ctx context.Context, recordID int64) error {
+	return j.runInTxn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		const stmt = "DELETE FROM system.protected_ts_records WHERE id = $1"
+		_, err := j.registry.ex.ExecuteStatementInTransaction(ctx, "release-protected-timestamp", txn, stmt, recordID)
+		return err
+	})
+}
+
 // Payload returns the most recently sent Payload for this Job. Will return an
 // empty Payload until Created() is called on a new Job.
 func (j *Job) Payload() Payload {
@@ -588,6 +673,8 @@ func (p *Payload) Type() Type {
 		return TypeSchemaChange
 	case *Payload_Import:
 		return TypeImport
+	case *Payload_MaterializedViewRefresh:
+		return TypeMaterializedViewRefresh
 	default:
 		panic("Payload.Type called on a payload with an unknown details type")
 	}
@@ -610,6 +697,8 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_SchemaChange{SchemaChange: &d}
 	case ImportDetails:
 		return &Payload_Import{Import: &d}
+	case MaterializedViewRefreshDetails:
+		return &Payload_MaterializedViewRefresh{MaterializedViewRefresh: &d}
 	default:
 		panic(fmt.Sprintf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
@@ -631,6 +720,8 @@ func (p *Payload) UnwrapDetails() (Details, error) {
 		return *d.SchemaChange, nil
 	case *Payload_Import:
 		return *d.Import, nil
+	case *Payload_MaterializedViewRefresh:
+		return *d.MaterializedViewRefresh, nil
 	default:
 		return nil, errors.Errorf("jobs.Payload: unsupported details type %T", d)
 	}
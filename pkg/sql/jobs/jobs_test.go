@@ -403,6 +403,7 @@ func TestJobLifecycle(t *testing.T) {
 		}{
 			{jobs.TypeSchemaChange, jobs.SchemaChangeDetails{}, "schema change"},
 			{jobs.TypeImport, jobs.ImportDetails{}, "import"},
+			{jobs.TypeMaterializedViewRefresh, jobs.MaterializedViewRefreshDetails{}, "materialized view refresh"},
 		}
 		for _, tc := range testCases {
 			job, _ := createJob(tc.typ, jobs.WithoutCancel, jobs.Record{
@@ -22,6 +22,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -31,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
@@ -124,7 +126,8 @@ const DefaultCancelInterval = base.DefaultHeartbeatInterval
 var DefaultAdoptInterval = 30 * time.Second
 
 // Start polls the current node for liveness failures and cancels all registered
-// jobs if it observes a failure.
+// jobs if it observes a failure. It also periodically adopts jobs with expired
+// leases and deletes expired protected timestamp records.
 func (r *Registry) Start(
 	ctx context.Context,
 	stopper *stop.Stopper,
@@ -153,6 +156,9 @@ func (r *Registry) Start(
 				if err := r.maybeAdoptJob(ctx, nl); err != nil {
 					log.Errorf(ctx, "error while adopting jobs: %+v", err)
 				}
+				if err := r.deleteExpiredProtectedTimestamps(ctx); err != nil {
+					log.Errorf(ctx, "error while deleting expired protected timestamps: %+v", err)
+				}
 			case <-stopper.ShouldStop():
 				return
 			}
@@ -334,6 +340,39 @@ func (r *Registry) maybeAdoptJob(ctx context.Context, nl nodeLiveness) error {
 	return nil
 }
 
+// deleteExpiredProtectedTimestamps deletes the records in
+// system.protected_ts_records whose expiration has passed. The GC queue already
+// ignores such records; deleting them keeps records orphaned by jobs which
+// never released them from accumulating.
+func (r *Registry) deleteExpiredProtectedTimestamps(ctx context.Context) error {
+	return r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		const stmt = `SELECT id, record FROM system.protected_ts_records`
+		rows, err := r.ex.QueryRowsInTransaction(ctx, "protected-ts-expired", txn, stmt)
+		if err != nil {
+			return err
+		}
+		now := r.clock.Now()
+		for _, row := range rows {
+			id := (*int64)(row[0].(*parser.DInt))
+			var record config.ProtectedTimestampRecord
+			if err := protoutil.Unmarshal([]byte(*row[1].(*parser.DBytes)), &record); err != nil {
+				return err
+			}
+			if now.Less(record.Expiration) {
+				continue
+			}
+			log.Infof(ctx, "deleting protected timestamp record %d which expired at %s", *id, record.Expiration)
+			const deleteStmt = "DELETE FROM system.protected_ts_records WHERE id = $1"
+			if _, err := r.ex.ExecuteStatementInTransaction(
+				ctx, "protected-ts-delete", txn, deleteStmt, *id,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *Registry) cancelAll(ctx context.Context) {
 	r.mu.AssertHeld()
 	for jobID, job := range r.mu.jobs {
@@ -17,6 +17,7 @@ package jobs_test
 import (
 	"math"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -24,7 +25,9 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -212,3 +215,74 @@ func TestRegistryResumeActiveLease(t *testing.T) {
 		t.Fatalf("expected job %d to be resumed, but got %d", e, a)
 	}
 }
+
+func TestJobProtectTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	registry := s.JobRegistry().(*jobs.Registry)
+	defer s.Stopper().Stop(ctx)
+	sqlRunner := sqlutils.MakeSQLRunner(t, sqlDB)
+
+	job := registry.NewJob(jobs.Record{Details: jobs.BackupDetails{}})
+	if err := job.Created(ctx, jobs.WithoutCancel); err != nil {
+		t.Fatal(err)
+	}
+
+	span := roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("b")}
+	now := s.Clock().Now()
+	protectedTS := now.Add(-time.Hour.Nanoseconds(), 0)
+	recordID, err := job.ProtectTimestamp(
+		ctx, protectedTS, []roachpb.Span{span.AsRawSpanWithNoLocals()}, now.Add(time.Hour.Nanoseconds(), 0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Extending the record keeps protecting the timestamp past its original
+	// expiration.
+	if err := job.ExtendProtectedTimestamp(ctx, recordID, now.Add(2*time.Hour.Nanoseconds(), 0)); err != nil {
+		t.Fatal(err)
+	}
+	start := roachpb.Key(keys.MakeTablePrefix(keys.ProtectedTimestampRecordsTableID))
+	kvs, err := s.KVClient().(*client.DB).Scan(ctx, start, start.PrefixEnd(), 0 /* maxRows */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := make([]roachpb.KeyValue, len(kvs))
+	for i, kv := range kvs {
+		records[i] = roachpb.KeyValue{Key: kv.Key, Value: *kv.Value}
+	}
+	ts, found, err := config.GetProtectedTimestamp(records, span, now.Add(90*time.Minute.Nanoseconds(), 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || ts != protectedTS {
+		t.Fatalf("expected protected timestamp %s; got %s (found=%t)", protectedTS, ts, found)
+	}
+
+	if err := job.ReleaseProtectedTimestamp(ctx, recordID); err != nil {
+		t.Fatal(err)
+	}
+	sqlRunner.CheckQueryResults(`SELECT count(*) FROM system.protected_ts_records`, [][]string{{"0"}})
+
+	// Records which expired are deleted by the registry, while others are left
+	// alone.
+	if _, err := job.ProtectTimestamp(
+		ctx, protectedTS, []roachpb.Span{span.AsRawSpanWithNoLocals()}, now,
+	); err != nil {
+		t.Fatal(err)
+	}
+	liveID, err := job.ProtectTimestamp(
+		ctx, protectedTS, []roachpb.Span{span.AsRawSpanWithNoLocals()}, now.Add(time.Hour.Nanoseconds(), 0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.DeleteExpiredProtectedTimestamps(ctx); err != nil {
+		t.Fatal(err)
+	}
+	sqlRunner.CheckQueryResults(`SELECT id FROM system.protected_ts_records`,
+		[][]string{{strconv.FormatInt(liveID, 10)}})
+}
@@ -478,5 +478,8 @@ func pickUsingColumn(
 	if idx == invalidColIdx {
 		return idx, nil, fmt.Errorf("column \"%s\" specified in USING clause does not exist in %s table", colName, context)
 	}
+	if err := cols[idx].PrivilegeError; err != nil {
+		return idx, nil, err
+	}
 	return idx, cols[idx].Typ, nil
 }
@@ -108,7 +108,13 @@ func (s *tableVersionState) incRefcountLocked() {
 // hlc.Timestamp, so we're using this method to give us the stored
 // type: parser.DTimestamp.
 func (s *tableVersionState) leaseExpiration() parser.DTimestamp {
-	return parser.DTimestamp{Time: timeutil.Unix(0, s.expiration.WallTime).Round(time.Microsecond)}
+	return makeLeaseExpiration(s.expiration)
+}
+
+// makeLeaseExpiration converts the expiration of a lease to the type stored
+// in system.lease.
+func makeLeaseExpiration(expiration hlc.Timestamp) parser.DTimestamp {
+	return parser.DTimestamp{Time: timeutil.Unix(0, expiration.WallTime).Round(time.Microsecond)}
 }
 
 // LeaseStore implements the operations for acquiring and releasing leases and
@@ -164,25 +170,7 @@ func (s LeaseStore) acquire(
 			return err
 		}
 
-		nodeID := s.nodeID.Get()
-		if nodeID == 0 {
-			panic("zero nodeID")
-		}
-		p := makeInternalPlanner("lease-insert", txn, security.RootUser, s.memMetrics)
-		defer finishInternalPlanner(p)
-		const insertLease = `INSERT INTO system.lease ("descID", version, "nodeID", expiration) ` +
-			`VALUES ($1, $2, $3, $4)`
-		leaseExpiration := table.leaseExpiration()
-		count, err := p.exec(
-			ctx, insertLease, table.ID, int(table.Version), nodeID, &leaseExpiration,
-		)
-		if err != nil {
-			return err
-		}
-		if count != 1 {
-			return errors.Errorf("%s: expected 1 result, found %d", insertLease, count)
-		}
-		return nil
+		return s.insertLease(ctx, txn, table.ID, table.Version, table.leaseExpiration())
 	})
 	if err == nil && s.testingKnobs.LeaseAcquiredEvent != nil {
 		s.testingKnobs.LeaseAcquiredEvent(table.TableDescriptor, nil)
@@ -190,6 +178,53 @@ func (s LeaseStore) acquire(
 	return table, err
 }
 
+// insertLease records in system.lease that this node holds a lease on the
+// given version of a descriptor.
+func (s LeaseStore) insertLease(
+	ctx context.Context,
+	txn *client.Txn,
+	descID sqlbase.ID,
+	version sqlbase.DescriptorVersion,
+	expiration parser.DTimestamp,
+) error {
+	nodeID := s.nodeID.Get()
+	if nodeID == 0 {
+		panic("zero nodeID")
+	}
+	p := makeInternalPlanner("lease-insert", txn, security.RootUser, s.memMetrics)
+	defer finishInternalPlanner(p)
+	const insertLease = `INSERT INTO system.lease ("descID", version, "nodeID", expiration) ` +
+		`VALUES ($1, $2, $3, $4)`
+	count, err := p.exec(ctx, insertLease, descID, int(version), nodeID, &expiration)
+	if err != nil {
+		return err
+	}
+	if count != 1 {
+		return errors.Errorf("%s: expected 1 result, found %d", insertLease, count)
+	}
+	return nil
+}
+
+// deleteLease removes the record of a lease held by this node from
+// system.lease. It returns the number of records removed.
+func (s LeaseStore) deleteLease(
+	ctx context.Context,
+	txn *client.Txn,
+	descID sqlbase.ID,
+	version sqlbase.DescriptorVersion,
+	expiration parser.DTimestamp,
+) (int, error) {
+	nodeID := s.nodeID.Get()
+	if nodeID == 0 {
+		panic("zero nodeID")
+	}
+	p := makeInternalPlanner("lease-release", txn, security.RootUser, s.memMetrics)
+	defer finishInternalPlanner(p)
+	const deleteLease = `DELETE FROM system.lease ` +
+		`WHERE ("descID", version, "nodeID", expiration) = ($1, $2, $3, $4)`
+	return p.exec(ctx, deleteLease, descID, int(version), nodeID, &expiration)
+}
+
 // Release a previously acquired table descriptor.
 func (s LeaseStore) release(ctx context.Context, stopper *stop.Stopper, table *tableVersionState) {
 	retryOptions := base.DefaultRetryOptions()
@@ -199,17 +234,7 @@ func (s LeaseStore) release(ctx context.Context, stopper *stop.Stopper, table *t
 		// This transaction is idempotent.
 		err := s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 			log.VEventf(ctx, 2, "LeaseStore releasing lease %s", table)
-			nodeID := s.nodeID.Get()
-			if nodeID == 0 {
-				panic("zero nodeID")
-			}
-			p := makeInternalPlanner("lease-release", txn, security.RootUser, s.memMetrics)
-			defer finishInternalPlanner(p)
-			const deleteLease = `DELETE FROM system.lease ` +
-				`WHERE ("descID", version, "nodeID", expiration) = ($1, $2, $3, $4)`
-			leaseExpiration := table.leaseExpiration()
-			count, err := p.exec(
-				ctx, deleteLease, table.ID, int(table.Version), nodeID, &leaseExpiration)
+			count, err := s.deleteLease(ctx, txn, table.ID, table.Version, table.leaseExpiration())
 			if err != nil {
 				return err
 			}
@@ -232,32 +257,37 @@ func (s LeaseStore) release(ctx context.Context, stopper *stop.Stopper, table *t
 }
 
 // WaitForOneVersion returns once there are no unexpired leases on the
-// previous version of the table descriptor. It returns the current version.
-// After returning there can only be versions of the descriptor >= to the
-// returned version. Lease acquisition (see acquire()) maintains the
+// previous version of the table or type descriptor. It returns the current
+// version. After returning there can only be versions of the descriptor >= to
+// the returned version. Lease acquisition (see acquire()) maintains the
 // invariant that no new leases for desc.Version-1 will be granted once
 // desc.Version exists.
 func (s LeaseStore) WaitForOneVersion(
-	ctx context.Context, tableID sqlbase.ID, retryOpts retry.Options,
+	ctx context.Context, descID sqlbase.ID, retryOpts retry.Options,
 ) (sqlbase.DescriptorVersion, error) {
 	desc := &sqlbase.Descriptor{}
-	descKey := sqlbase.MakeDescMetadataKey(tableID)
-	var tableDesc *sqlbase.TableDescriptor
+	descKey := sqlbase.MakeDescMetadataKey(descID)
+	var name string
+	var version sqlbase.DescriptorVersion
 	for r := retry.Start(retryOpts); r.Next(); {
-		// Get the current version of the table descriptor non-transactionally.
+		// Get the current version of the descriptor non-transactionally.
 		//
 		// TODO(pmattis): Do an inconsistent read here?
 		if err := s.db.GetProto(context.TODO(), descKey, desc); err != nil {
 			return 0, err
 		}
-		tableDesc = desc.GetTable()
-		if tableDesc == nil {
-			return 0, errors.Errorf("ID %d is not a table", tableID)
+		switch union := desc.Union.(type) {
+		case *sqlbase.Descriptor_Table:
+			name, version = union.Table.Name, union.Table.Version
+		case *sqlbase.Descriptor_Type:
+			name, version = union.Type.Name, union.Type.Version
+		default:
+			return 0, errors.Errorf("ID %d is not a table or type", descID)
 		}
 		// Check to see if there are any leases that still exist on the previous
 		// version of the descriptor.
 		now := s.clock.Now()
-		count, err := s.countLeases(ctx, tableDesc.ID, tableDesc.Version-1, now.GoTime())
+		count, err := s.countLeases(ctx, descID, version-1, now.GoTime())
 		if err != nil {
 			return 0, err
 		}
@@ -265,9 +295,9 @@ func (s LeaseStore) WaitForOneVersion(
 			break
 		}
 		log.Infof(context.TODO(), "publish (count leases): descID=%d name=%s version=%d count=%d",
-			tableDesc.ID, tableDesc.Name, tableDesc.Version-1, count)
+			descID, name, version-1, count)
 	}
-	return tableDesc.Version, nil
+	return version, nil
 }
 
 var errDidntUpdateDescriptor = errors.New("didn't update the table descriptor")
@@ -1056,10 +1086,10 @@ func makeTableNameCacheKey(dbID sqlbase.ID, tableName string) tableNameCacheKey
 	return tableNameCacheKey{dbID, tableName}
 }
 
-// LeaseManager manages acquiring and releasing per-table leases. It also
-// handles resolving table names to descriptor IDs. The leases are managed
-// internally with a table descriptor and expiration time exported by the
-// API. The table descriptor acquired needs to be released. A transaction
+// LeaseManager manages acquiring and releasing per-table leases, and the
+// per-type leases of lease_type.go. It also handles resolving table names to
+// descriptor IDs. The leases are managed internally with a table descriptor
+// and expiration time exported by the API. The table descriptor acquired needs to be released. A transaction
 // can use a table descriptor as long as its timestamp is within the
 // validity window for the descriptor:
 // descriptor.ModificationTime <= txn.Timestamp < expirationTime
@@ -1068,11 +1098,13 @@ func makeTableNameCacheKey(dbID sqlbase.ID, tableName string) tableNameCacheKey
 //
 // The locking order is:
 // LeaseManager.mu > tableState.mu > tableNameCache.mu > tableVersionState.mu
+// LeaseManager.mu > typeState.mu > typeVersionState.mu
 type LeaseManager struct {
 	LeaseStore
 	mu struct {
 		syncutil.Mutex
 		tables map[sqlbase.ID]*tableState
+		types  map[sqlbase.ID]*typeState
 	}
 
 	draining atomic.Value
@@ -1114,6 +1146,7 @@ func NewLeaseManager(
 
 	lm.mu.Lock()
 	lm.mu.tables = make(map[sqlbase.ID]*tableState)
+	lm.mu.types = make(map[sqlbase.ID]*typeState)
 	lm.mu.Unlock()
 
 	lm.draining.Store(false)
@@ -1318,6 +1351,11 @@ func (m *LeaseManager) SetDraining(drain bool) {
 		t.removeInactiveVersions(m)
 		t.mu.Unlock()
 	}
+	for _, t := range m.mu.types {
+		t.mu.Lock()
+		t.removeInactiveVersions(m, false /* keepNewest */)
+		t.mu.Unlock()
+	}
 }
 
 // If create is set, cache and stopper need to be set as well.
@@ -1333,7 +1371,7 @@ func (m *LeaseManager) findTableState(tableID sqlbase.ID, create bool) *tableSta
 }
 
 // RefreshLeases starts a goroutine that refreshes the lease manager
-// leases for tables received in the latest system configuration via gossip.
+// leases for tables and types received in the latest system configuration via gossip.
 func (m *LeaseManager) RefreshLeases(s *stop.Stopper, db *client.DB, gossip *gossip.Gossip) {
 	ctx := context.TODO()
 	s.RunWorker(ctx, func(ctx context.Context) {
@@ -1382,6 +1420,19 @@ func (m *LeaseManager) RefreshLeases(s *stop.Stopper, db *client.DB, gossip *gos
 									table.ID, table.Name, err)
 							}
 						}
+					case *sqlbase.Descriptor_Type:
+						typ := union.Type
+						if log.V(2) {
+							log.Infof(ctx, "%s: refreshing lease type: %d (%s), version: %d",
+								kv.Key, typ.ID, typ.Name, typ.Version)
+						}
+						// Try to refresh the type lease to one >= this version.
+						if t := m.findTypeState(typ.ID, false /* create */); t != nil {
+							if err := t.purgeOldVersions(ctx, typ.Version, m); err != nil {
+								log.Warningf(ctx, "error purging leases for type %d(%s): %s",
+									typ.ID, typ.Name, err)
+							}
+						}
 					case *sqlbase.Descriptor_Database:
 						// Ignore.
 					}
@@ -891,3 +891,43 @@ INSERT INTO t.timestamp VALUES ('a', 'b');
 		t.Fatal(err)
 	}
 }
+
+// TestTypeLeases checks that the types resolved by queries are leased, and
+// that ALTER TYPE returns once the lease on the previous version of the type
+// is released.
+func TestTypeLeases(testingT *testing.T) {
+	defer leaktest.AfterTest(testingT)()
+	params, _ := createTestServerParams()
+	params.UseDatabase = "t"
+	t := newLeaseTest(testingT, params)
+	defer t.cleanup()
+
+	if _, err := t.db.Exec(`
+CREATE DATABASE t;
+CREATE TYPE color AS ENUM ('red', 'green');
+`); err != nil {
+		t.Fatal(err)
+	}
+	var id int64
+	if err := t.db.QueryRow(
+		`SELECT id FROM system.namespace WHERE name = 'color'`,
+	).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	typeID := sqlbase.ID(id)
+
+	if _, err := t.db.Exec(`SELECT 'red'::color`); err != nil {
+		t.Fatal(err)
+	}
+	t.expectLeases(typeID, "/1/1")
+
+	if _, err := t.db.Exec(`ALTER TYPE color ADD VALUE 'blue'`); err != nil {
+		t.Fatal(err)
+	}
+	if leases := t.getLeases(typeID); leases != "/2/1" {
+		t.Fatalf("expected a lease on version 2 only, but found %s", leases)
+	}
+	if _, err := t.db.Exec(`SELECT 'blue'::color`); err != nil {
+		t.Fatal(err)
+	}
+}
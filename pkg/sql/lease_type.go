@@ -0,0 +1,415 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Type descriptors are leased like table descriptors: a node records its
+// leases in system.lease, and ALTER TYPE waits until no lease remains on the
+// previous version of the type before returning (see
+// schemaChangerCollection.execSchemaChanges). Unlike for tables, only the
+// versions of a type that are leased by the node are kept; a transaction
+// whose timestamp precedes all of them reads the type descriptor
+// transactionally instead.
+
+// errTypeVersionNotLeased is returned when a node holds no lease on a
+// version of a type valid for the requested timestamp.
+var errTypeVersionNotLeased = errors.New("no leased type version for timestamp")
+
+// typeVersionState holds the state for a leased type version.
+type typeVersionState struct {
+	// This descriptor is immutable and can be shared by many goroutines.
+	// Care must be taken to not modify it.
+	sqlbase.TypeDescriptor
+
+	// The expiration time of the lease. A transaction with timestamp T can
+	// use this type descriptor version iff
+	// TypeDescriptor.ModificationTime <= T < expiration
+	expiration hlc.Timestamp
+
+	// mu protects refcount and leased.
+	mu       syncutil.Mutex
+	refcount int
+	// Set until the lease has been released from the store.
+	leased bool
+}
+
+func (s *typeVersionState) String() string {
+	return fmt.Sprintf("%d(%q) ver=%d:%s, refcount=%d", s.ID, s.Name, s.Version, s.expiration, s.refcount)
+}
+
+// hasExpired checks if the type is too old to be used (by a txn operating)
+// at the given timestamp.
+func (s *typeVersionState) hasExpired(timestamp hlc.Timestamp) bool {
+	return !timestamp.Less(s.expiration)
+}
+
+func (s *typeVersionState) leaseExpiration() parser.DTimestamp {
+	return makeLeaseExpiration(s.expiration)
+}
+
+// acquireType acquires a lease on the most recent version of a type
+// descriptor. sqlbase.ErrDescriptorNotFound is returned if the ID does not
+// refer to a type.
+func (s LeaseStore) acquireType(
+	ctx context.Context, typeID sqlbase.ID, minExpirationTime hlc.Timestamp,
+) (*typeVersionState, error) {
+	var typ *typeVersionState
+	err := s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		expiration := txn.OrigTimestamp()
+		expiration.WallTime += int64(jitteredLeaseDuration())
+		if expiration.Less(minExpirationTime) {
+			expiration = minExpirationTime
+		}
+
+		typeDesc, err := getTypeDescByID(ctx, txn, typeID)
+		if err != nil {
+			return err
+		}
+		if typeDesc == nil {
+			return sqlbase.ErrDescriptorNotFound
+		}
+		// Once the descriptor is set it is immutable and care must be taken
+		// to not modify it.
+		typ = &typeVersionState{
+			TypeDescriptor: *typeDesc,
+			expiration:     expiration,
+			leased:         true,
+		}
+		return s.insertLease(ctx, txn, typ.ID, typ.Version, typ.leaseExpiration())
+	})
+	return typ, err
+}
+
+// releaseType releases a previously acquired type descriptor.
+func (s LeaseStore) releaseType(ctx context.Context, stopper *stop.Stopper, typ *typeVersionState) {
+	retryOptions := base.DefaultRetryOptions()
+	retryOptions.Closer = stopper.ShouldQuiesce()
+	for r := retry.Start(retryOptions); r.Next(); {
+		// This transaction is idempotent.
+		err := s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			log.VEventf(ctx, 2, "LeaseStore releasing type lease %s", typ)
+			_, err := s.deleteLease(ctx, txn, typ.ID, typ.Version, typ.leaseExpiration())
+			return err
+		})
+		if err == nil {
+			break
+		}
+		log.Warningf(ctx, "error releasing lease %q: %s", typ, err)
+	}
+}
+
+// typeState holds the leased versions of a type descriptor.
+type typeState struct {
+	id      sqlbase.ID
+	stopper *stop.Stopper
+
+	mu struct {
+		syncutil.Mutex
+
+		// The leased versions of the type sorted by increasing version. The
+		// latest entry is the newest version known to the node.
+		active []*typeVersionState
+		// A channel used to indicate whether a lease is actively being
+		// acquired. nil if there is no lease acquisition in progress for
+		// the type. If non-nil, the channel will be closed when lease
+		// acquisition completes.
+		acquiring chan struct{}
+	}
+}
+
+// acquire returns a version of the type valid for the timestamp, or
+// errTypeVersionNotLeased. The type will have its refcount incremented, so
+// the caller is responsible for calling release() on it.
+func (t *typeState) acquire(
+	ctx context.Context, timestamp hlc.Timestamp, m *LeaseManager,
+) (*typeVersionState, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Wait for any existing lease acquisition.
+	t.acquireWait()
+
+	// Acquire a lease if no lease exists or if the latest lease is
+	// about to expire.
+	if s := t.findNewestLocked(); s == nil || s.hasExpired(timestamp) {
+		if err := t.acquireNodeLease(ctx, m, hlc.Timestamp{}); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := len(t.mu.active) - 1; i >= 0; i-- {
+		if s := t.mu.active[i]; !timestamp.Less(s.ModificationTime) && !s.hasExpired(timestamp) {
+			s.mu.Lock()
+			s.refcount++
+			s.mu.Unlock()
+			return s, nil
+		}
+	}
+	return nil, errTypeVersionNotLeased
+}
+
+func (t *typeState) findNewestLocked() *typeVersionState {
+	if len(t.mu.active) == 0 {
+		return nil
+	}
+	return t.mu.active[len(t.mu.active)-1]
+}
+
+// acquireWait waits until no lease acquisition is in progress.
+func (t *typeState) acquireWait() {
+	for acquiring := t.mu.acquiring; acquiring != nil; acquiring = t.mu.acquiring {
+		// We're called with mu locked, but need to unlock it while we wait
+		// for the in-progress lease acquisition to finish.
+		t.mu.Unlock()
+		<-acquiring
+		t.mu.Lock()
+	}
+}
+
+// acquireNodeLease acquires a lease on the latest version of the type and
+// makes it the newest entry of the active set. minExpirationTime, if not set
+// to the zero value, is used as a lower bound on the expiration of the lease.
+//
+// t.mu needs to be locked.
+func (t *typeState) acquireNodeLease(
+	ctx context.Context, m *LeaseManager, minExpirationTime hlc.Timestamp,
+) error {
+	if m.isDraining() {
+		return errors.New("cannot acquire lease when draining")
+	}
+
+	// Notify when lease has been acquired.
+	t.mu.acquiring = make(chan struct{})
+	defer func() {
+		close(t.mu.acquiring)
+		t.mu.acquiring = nil
+	}()
+	// We're called with mu locked, but need to unlock it during lease
+	// acquisition.
+	t.mu.Unlock()
+	typ, err := m.LeaseStore.acquireType(ctx, t.id, minExpirationTime)
+	t.mu.Lock()
+	if err != nil {
+		return err
+	}
+
+	// Leases are always acquired on the latest version, so the new lease
+	// either replaces the one on the same version or is appended.
+	for i, s := range t.mu.active {
+		if s.Version == typ.Version {
+			s.mu.Lock()
+			typ.mu.Lock()
+			// Subsume the refcount of the older lease.
+			typ.refcount += s.refcount
+			s.refcount = 0
+			s.leased = false
+			typ.mu.Unlock()
+			s.mu.Unlock()
+			log.VEventf(ctx, 2, "replaced lease: %s with %s", s, typ)
+			t.mu.active = append(t.mu.active[:i], t.mu.active[i+1:]...)
+			t.releaseLease(s, m)
+			break
+		}
+	}
+	t.mu.active = append(t.mu.active, typ)
+	return nil
+}
+
+func (t *typeState) release(typeDesc *sqlbase.TypeDescriptor, m *LeaseManager) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	idx := -1
+	for i, s := range t.mu.active {
+		if s.Version == typeDesc.Version {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return errors.Errorf("type %d version %d not found", typeDesc.ID, typeDesc.Version)
+	}
+	s := t.mu.active[idx]
+	// Release from the store once the version is not referenced any more if
+	// it is not the latest one, since only the latest version can be leased.
+	removeOnceDereferenced := m.LeaseStore.testingKnobs.RemoveOnceDereferenced ||
+		m.isDraining() || idx != len(t.mu.active)-1
+
+	s.mu.Lock()
+	s.refcount--
+	log.VEventf(context.TODO(), 2, "release: %s", s)
+	if s.refcount < 0 {
+		panic(fmt.Sprintf("negative ref count: %s", s))
+	}
+	remove := s.refcount == 0 && s.leased && removeOnceDereferenced
+	if remove {
+		s.leased = false
+	}
+	s.mu.Unlock()
+
+	if remove {
+		t.mu.active = append(t.mu.active[:idx], t.mu.active[idx+1:]...)
+		t.releaseLease(s, m)
+	}
+	return nil
+}
+
+// removeInactiveVersions removes the versions in t.mu.active with refcount
+// 0, except for the newest one if keepNewest is set.
+//
+// t.mu must be locked.
+func (t *typeState) removeInactiveVersions(m *LeaseManager, keepNewest bool) {
+	newest := t.findNewestLocked()
+	active := t.mu.active[:0]
+	for _, s := range t.mu.active {
+		s.mu.Lock()
+		remove := s.refcount == 0 && !(keepNewest && s == newest)
+		if remove {
+			s.leased = false
+		}
+		s.mu.Unlock()
+		if remove {
+			t.releaseLease(s, m)
+			continue
+		}
+		active = append(active, s)
+	}
+	t.mu.active = active
+}
+
+// releaseLease releases the lease associated with the type version.
+//
+// t.mu needs to be locked.
+func (t *typeState) releaseLease(typ *typeVersionState, m *LeaseManager) {
+	ctx := context.TODO()
+	if m.isDraining() {
+		// Release synchronously to guarantee release before exiting.
+		m.LeaseStore.releaseType(ctx, t.stopper, typ)
+		return
+	}
+
+	// Release to the store asynchronously, without the typeState lock.
+	if err := t.stopper.RunAsyncTask(
+		ctx, "sql.typeState: releasing descriptor lease",
+		func(ctx context.Context) {
+			m.LeaseStore.releaseType(ctx, t.stopper, typ)
+		}); err != nil {
+		log.Warningf(ctx, "error: %s, not releasing lease: %q", err, typ)
+	}
+}
+
+// purgeOldVersions ensures that the node holds a lease on a version of the
+// type >= minVersion, and releases the unused leases on older versions. If
+// the node holds no lease on the type, nothing is done.
+func (t *typeState) purgeOldVersions(
+	ctx context.Context, minVersion sqlbase.DescriptorVersion, m *LeaseManager,
+) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.acquireWait()
+
+	newest := t.findNewestLocked()
+	if newest == nil {
+		// We don't currently have a version of this type, so no need to
+		// refresh anything.
+		return nil
+	}
+	if newest.Version < minVersion {
+		// Set the min expiration time to guarantee that the new lease is
+		// the newest one.
+		minExpirationTime := newest.expiration.Add(int64(time.Millisecond), 0)
+		if err := t.acquireNodeLease(ctx, m, minExpirationTime); err != nil {
+			return err
+		}
+	}
+	t.removeInactiveVersions(m, true /* keepNewest */)
+	return nil
+}
+
+// AcquireType acquires a read lease for the specified type ID valid for the
+// timestamp. It returns the type descriptor and an expiration time, with the
+// same contract as Acquire. errTypeVersionNotLeased is returned if no
+// version of the type valid for the timestamp can be leased.
+func (m *LeaseManager) AcquireType(
+	ctx context.Context, timestamp hlc.Timestamp, typeID sqlbase.ID,
+) (*sqlbase.TypeDescriptor, hlc.Timestamp, error) {
+	t := m.findTypeState(typeID, true)
+	typ, err := t.acquire(ctx, timestamp, m)
+	if err != nil {
+		return nil, hlc.Timestamp{}, err
+	}
+	return &typ.TypeDescriptor, typ.expiration, nil
+}
+
+// AcquireTypeByName is like AcquireType, but resolves the type by its name
+// and the ID of its database. If the name does not refer to a type,
+// sqlbase.ErrDescriptorNotFound is returned.
+func (m *LeaseManager) AcquireTypeByName(
+	ctx context.Context, timestamp hlc.Timestamp, dbID sqlbase.ID, typeName string,
+) (*sqlbase.TypeDescriptor, hlc.Timestamp, error) {
+	typeID, err := m.resolveName(ctx, timestamp, dbID, typeName)
+	if err != nil {
+		return nil, hlc.Timestamp{}, err
+	}
+	typ, expiration, err := m.AcquireType(ctx, timestamp, typeID)
+	if err != nil {
+		return nil, hlc.Timestamp{}, err
+	}
+	if typ.ParentID != dbID || typ.Name != typeName {
+		// Types cannot be renamed, but the name may have been reused after the
+		// type was dropped.
+		if err := m.ReleaseType(typ); err != nil {
+			log.Warningf(ctx, "error releasing lease: %s", err)
+		}
+		return nil, hlc.Timestamp{}, sqlbase.ErrDescriptorNotFound
+	}
+	return typ, expiration, nil
+}
+
+// ReleaseType releases a previously acquired type.
+func (m *LeaseManager) ReleaseType(desc *sqlbase.TypeDescriptor) error {
+	t := m.findTypeState(desc.ID, false /* create */)
+	if t == nil {
+		return errors.Errorf("type %d not found", desc.ID)
+	}
+	return t.release(desc, m)
+}
+
+func (m *LeaseManager) findTypeState(typeID sqlbase.ID, create bool) *typeState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.mu.types[typeID]
+	if t == nil && create {
+		t = &typeState{id: typeID, stopper: m.stopper}
+		m.mu.types[typeID] = t
+	}
+	return t
+}
@@ -164,18 +164,25 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterTypeNode:
+	case *alterUserNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createPolicyNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropPolicyNode:
 	case *dropTableNode:
+	case *dropTypeNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshMaterializedViewNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
@@ -0,0 +1,476 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// Materialized views
+//
+// A materialized view is a view whose descriptor also describes a physical
+// table: it keeps the view query and the dependencies of a logical view, but
+// it has a primary index (on a hidden rowid column) that stores the results
+// of the query. Reads scan that index instead of expanding the query, and
+// the contents only change when the view is refreshed.
+//
+// REFRESH MATERIALIZED VIEW runs as a job. It reserves a new index ID, runs
+// the view query, and writes the results under the new ID with the bulk
+// writer, which CCL builds replace with one that ingests sstables through
+// AddSSTable. No descriptor version refers to the new index yet, so nothing
+// reads it while it is being written. A single descriptor update then makes
+// it the primary index, so readers see either the old contents or the new
+// ones, never a mix. Once no leases remain on the version which referred to
+// the old index, its data is deleted.
+//
+// Readers are never blocked by a refresh, so CONCURRENTLY is accepted for
+// compatibility with PostgreSQL but does not change the behavior.
+
+// BulkKVWriter writes kvs, which are sorted by key, at timestamp ts. The kvs
+// belong to an index that is not read or written by anything else. It is
+// called once per batch of results, so the spans of successive calls for the
+// same index may overlap.
+type BulkKVWriter func(
+	ctx context.Context, db *client.DB, st *cluster.Settings, ts hlc.Timestamp, kvs []roachpb.KeyValue,
+) error
+
+// materializedViewWriteBatchSize is the maximum number of kvs held in memory
+// while the contents of a materialized view are written, and the number of
+// kvs written per batch by the default BulkKVWriter.
+const materializedViewWriteBatchSize = 10000
+
+var bulkKVWriter BulkKVWriter = writeKVsInBatches
+
+// SetBulkKVWriter sets the function used to write the contents of
+// materialized views when they are refreshed.
+func SetBulkKVWriter(fn BulkKVWriter) {
+	bulkKVWriter = fn
+}
+
+// writeKVsInBatches is the default BulkKVWriter. It writes the kvs with
+// regular, non-transactional batches of puts.
+func writeKVsInBatches(
+	ctx context.Context, db *client.DB, _ *cluster.Settings, _ hlc.Timestamp, kvs []roachpb.KeyValue,
+) error {
+	for len(kvs) > 0 {
+		n := len(kvs)
+		if n > materializedViewWriteBatchSize {
+			n = materializedViewWriteBatchSize
+		}
+		b := &client.Batch{}
+		for i := range kvs[:n] {
+			b.Put(kvs[i].Key, &kvs[i].Value)
+		}
+		if err := db.Run(ctx, b); err != nil {
+			return err
+		}
+		kvs = kvs[n:]
+	}
+	return nil
+}
+
+// materializedViewWriter encodes the results of the view query of a
+// materialized view as the entries of one of its primary indexes, and passes
+// them to a flush function in batches of at most
+// materializedViewWriteBatchSize kvs. Only one batch is held in memory at a
+// time, and it is accounted in acc.
+type materializedViewWriter struct {
+	desc         sqlbase.TableDescriptor
+	ri           sqlbase.RowInserter
+	cols         []sqlbase.ColumnDescriptor
+	defaultExprs []parser.TypedExpr
+	evalCtx      parser.EvalContext
+
+	acc   mon.BoundAccount
+	kvs   []roachpb.KeyValue
+	flush func(context.Context, []roachpb.KeyValue) error
+}
+
+func (w *materializedViewWriter) init(
+	desc *sqlbase.TableDescriptor,
+	indexID sqlbase.IndexID,
+	evalCtx parser.EvalContext,
+	acc mon.BoundAccount,
+	flush func(context.Context, []roachpb.KeyValue) error,
+) error {
+	w.desc = *desc
+	w.desc.PrimaryIndex.ID = indexID
+	w.evalCtx = evalCtx
+	w.acc = acc
+	w.flush = flush
+	var err error
+	w.ri, err = sqlbase.MakeRowInserter(nil /* txn */, &w.desc, nil, /* fkTables */
+		w.desc.Columns, false /* checkFKs */, &sqlbase.DatumAlloc{})
+	if err != nil {
+		return err
+	}
+	// The values of the hidden rowid column are generated by its default
+	// expression.
+	var parse parser.Parser
+	w.cols, w.defaultExprs, err = sqlbase.ProcessDefaultColumns(
+		w.desc.Columns, &w.desc, &parse, &w.evalCtx)
+	return err
+}
+
+// addRow encodes a row of results of the view query, flushing the current
+// batch first if it is full.
+func (w *materializedViewWriter) addRow(ctx context.Context, values parser.Datums) error {
+	if len(w.kvs) >= materializedViewWriteBatchSize {
+		if err := w.flushBatch(ctx); err != nil {
+			return err
+		}
+	}
+	row, err := GenerateInsertRow(
+		w.defaultExprs, w.ri.InsertColIDtoRowIndex, w.cols, w.evalCtx, &w.desc, values)
+	if err != nil {
+		return err
+	}
+	var accErr error
+	if err := w.ri.InsertRow(ctx, kvPutter(func(kv roachpb.KeyValue) {
+		if accErr == nil {
+			accErr = w.acc.Grow(ctx, int64(len(kv.Key)+len(kv.Value.RawBytes)))
+		}
+		w.kvs = append(w.kvs, kv)
+	}), row, true /* ignoreConflicts */, false /* traceKV */); err != nil {
+		return err
+	}
+	return accErr
+}
+
+// flushBatch passes the current batch, sorted by key, to the flush function
+// and releases it.
+func (w *materializedViewWriter) flushBatch(ctx context.Context) error {
+	if len(w.kvs) == 0 {
+		return nil
+	}
+	kvs := w.kvs
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key.Compare(kvs[j].Key) < 0 })
+	if err := w.flush(ctx, kvs); err != nil {
+		return err
+	}
+	for i := range kvs {
+		kvs[i] = roachpb.KeyValue{}
+	}
+	w.kvs = kvs[:0]
+	w.acc.Clear(ctx)
+	return nil
+}
+
+// writeViewQueryResults runs the view query of desc in txn and writes its
+// results as the entries of the primary index of desc with ID indexID,
+// passing them to flush in sorted batches as they are produced.
+func writeViewQueryResults(
+	ctx context.Context,
+	leaseMgr *LeaseManager,
+	opName string,
+	txn *client.Txn,
+	desc *sqlbase.TableDescriptor,
+	indexID sqlbase.IndexID,
+	flush func(context.Context, []roachpb.KeyValue) error,
+) error {
+	ie := InternalExecutor{LeaseManager: leaseMgr}
+	p := makeInternalPlanner(opName, txn, security.RootUser, leaseMgr.memMetrics)
+	defer finishInternalPlanner(p)
+	ie.initSession(p)
+
+	var w materializedViewWriter
+	if err := w.init(
+		desc, indexID, p.evalCtx, p.session.TxnState.makeBoundAccount(), flush,
+	); err != nil {
+		return err
+	}
+	defer w.acc.Close(ctx)
+
+	plan, err := p.query(ctx, desc.ViewQuery)
+	if err != nil {
+		return err
+	}
+	defer plan.Close(ctx)
+	if err := p.startPlan(ctx, plan); err != nil {
+		return err
+	}
+	if err := forEachRow(runParams{ctx: ctx, p: p}, plan, func(values parser.Datums) error {
+		if values == nil {
+			return nil
+		}
+		return w.addRow(ctx, values)
+	}); err != nil {
+		return err
+	}
+	return w.flushBatch(ctx)
+}
+
+// kvPutter implements the putter interface of RowInserter.InsertRow by
+// passing the kvs to a function.
+type kvPutter func(roachpb.KeyValue)
+
+func (k kvPutter) CPut(key, value, expValue interface{}) {
+	panic("unimplemented")
+}
+
+func (k kvPutter) Put(key, value interface{}) {
+	k(roachpb.KeyValue{
+		Key:   *key.(*roachpb.Key),
+		Value: *value.(*roachpb.Value),
+	})
+}
+
+// populateMaterializedView writes the results of the view query of desc, a
+// materialized view created in the current transaction, to its primary
+// index.
+func (p *planner) populateMaterializedView(ctx context.Context, desc *sqlbase.TableDescriptor) error {
+	return writeViewQueryResults(ctx, p.LeaseMgr(), "create-materialized-view", p.txn,
+		desc, desc.PrimaryIndex.ID, func(ctx context.Context, kvs []roachpb.KeyValue) error {
+			b := p.txn.NewBatch()
+			for i := range kvs {
+				b.Put(kvs[i].Key, &kvs[i].Value)
+			}
+			return p.txn.Run(ctx, b)
+		})
+}
+
+type refreshMaterializedViewNode struct {
+	n    *parser.RefreshMaterializedView
+	desc *sqlbase.TableDescriptor
+}
+
+// RefreshMaterializedView recomputes the contents of a materialized view.
+// Privileges: DROP on view.
+//   Notes: postgres requires ownership of the view. Like TRUNCATE, a refresh
+//          replaces all the contents, so we require DROP.
+func (p *planner) RefreshMaterializedView(
+	ctx context.Context, n *parser.RefreshMaterializedView,
+) (planNode, error) {
+	tn, err := n.Name.NormalizeTableName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.qualifyTableName(ctx, tn); err != nil {
+		return nil, err
+	}
+	desc, err := MustGetTableOrViewDesc(ctx, p.txn, p.getVirtualTabler(), tn, false /* allowAdding */)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.IsMaterializedView() {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "materialized view")
+	}
+	if err := p.CheckPrivilege(ctx, desc, privilege.DROP); err != nil {
+		return nil, err
+	}
+	return &refreshMaterializedViewNode{n: n, desc: desc}, nil
+}
+
+func (n *refreshMaterializedViewNode) Start(params runParams) error {
+	execCfg := params.p.ExecCfg()
+	return refreshMaterializedView(params.ctx, execCfg, params.p.User(), n.desc.ID, n.n.String())
+}
+
+func (*refreshMaterializedViewNode) Next(runParams) (bool, error) { return false, nil }
+func (*refreshMaterializedViewNode) Close(context.Context)        {}
+func (*refreshMaterializedViewNode) Values() parser.Datums        { return parser.Datums{} }
+
+// refreshMaterializedView recomputes the contents of the materialized view
+// with the given ID in a new primary index, and swaps it in.
+func refreshMaterializedView(
+	ctx context.Context, execCfg *ExecutorConfig, user string, id sqlbase.ID, stmt string,
+) error {
+	db := execCfg.DB
+
+	// Reserve the new index ID and create the job in the same transaction so
+	// that an interrupted refresh can always be cleaned up.
+	var job *jobs.Job
+	var desc *sqlbase.TableDescriptor
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		if err := txn.SetSystemConfigTrigger(); err != nil {
+			return err
+		}
+		var err error
+		desc, err = sqlbase.GetTableDescFromID(ctx, txn, id)
+		if err != nil {
+			return err
+		}
+		if desc.Dropped() {
+			return errTableDropped
+		}
+		details := jobs.MaterializedViewRefreshDetails{
+			TableID:    id,
+			NewIndexID: desc.NextIndexID,
+			OldIndexID: desc.PrimaryIndex.ID,
+		}
+		desc.NextIndexID++
+		if err := txn.Put(ctx, sqlbase.MakeDescMetadataKey(id), sqlbase.WrapDescriptor(desc)); err != nil {
+			return err
+		}
+		job = execCfg.JobRegistry.NewJob(jobs.Record{
+			Description:   stmt,
+			Username:      user,
+			DescriptorIDs: sqlbase.IDs{id},
+			Details:       details,
+		})
+		return job.WithTxn(txn).Created(ctx, cancel)
+	}); err != nil {
+		return err
+	}
+	if err := job.Started(ctx); err != nil {
+		return err
+	}
+	refreshErr := runMaterializedViewRefresh(ctx, execCfg, desc, job)
+	if err := job.FinishedWith(ctx, refreshErr); err != nil {
+		return err
+	}
+	return refreshErr
+}
+
+func runMaterializedViewRefresh(
+	ctx context.Context, execCfg *ExecutorConfig, desc *sqlbase.TableDescriptor, job *jobs.Job,
+) error {
+	db := execCfg.DB
+	details := job.Record.Details.(jobs.MaterializedViewRefreshDetails)
+	newIndexSpan := desc.IndexSpan(details.NewIndexID)
+
+	// The results of the view query are written as they are produced, so the
+	// writes are not part of the transaction which runs it. If that
+	// transaction is retried, the rows it wrote get new rowids, so the index
+	// is cleared before trying again.
+	var wrote bool
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		if wrote {
+			if err := db.DelRange(ctx, newIndexSpan.Key, newIndexSpan.EndKey); err != nil {
+				return err
+			}
+			wrote = false
+		}
+		return writeViewQueryResults(ctx, execCfg.LeaseManager, "refresh-materialized-view", txn,
+			desc, details.NewIndexID, func(ctx context.Context, kvs []roachpb.KeyValue) error {
+				wrote = true
+				return bulkKVWriter(ctx, db, execCfg.Settings, execCfg.Clock.Now(), kvs)
+			})
+	}); err != nil {
+		return errors.Wrapf(err, "writing contents of materialized view %q", desc.Name)
+	}
+	if err := job.Progressed(ctx, .6, jobs.Noop); err != nil {
+		log.Warningf(ctx, "failed to log progress on job %d: %v", *job.ID(), err)
+	}
+
+	if _, err := execCfg.LeaseManager.Publish(ctx, desc.ID, func(desc *sqlbase.TableDescriptor) error {
+		if desc.Dropped() {
+			return errTableDropped
+		}
+		if desc.PrimaryIndex.ID != details.OldIndexID {
+			return fmt.Errorf("materialized view %q was refreshed concurrently", desc.Name)
+		}
+		desc.PrimaryIndex.ID = details.NewIndexID
+		return nil
+	}, nil /* logEvent */); err != nil {
+		if delErr := db.DelRange(ctx, newIndexSpan.Key, newIndexSpan.EndKey); delErr != nil {
+			log.Warningf(ctx, "failed to clean up index %d of %q: %v", details.NewIndexID, desc.Name, delErr)
+		}
+		return err
+	}
+
+	// Wait until nothing can read the old index anymore before deleting it.
+	if _, err := execCfg.LeaseManager.WaitForOneVersion(ctx, desc.ID, base.DefaultRetryOptions()); err != nil {
+		return err
+	}
+	oldIndexSpan := desc.IndexSpan(details.OldIndexID)
+	return db.DelRange(ctx, oldIndexSpan.Key, oldIndexSpan.EndKey)
+}
+
+// cleanupMaterializedViewRefresh deletes the index left over by a refresh
+// which was interrupted, returning an error if the refresh did not take
+// effect. It cannot use the lease manager, so it waits out the longest
+// possible lease on the versions preceding the last descriptor update
+// instead of waiting for them to be released.
+func cleanupMaterializedViewRefresh(
+	ctx context.Context, db *client.DB, details jobs.MaterializedViewRefreshDetails,
+) error {
+	var desc *sqlbase.TableDescriptor
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		desc, err = sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		return err
+	}); err != nil {
+		return err
+	}
+	if desc.Dropped() {
+		// The data is deleted along with the view.
+		return nil
+	}
+
+	maxLeaseDuration := time.Duration(float64(LeaseDuration) * 1.25)
+	expiration := desc.ModificationTime.GoTime().Add(maxLeaseDuration)
+	select {
+	case <-time.After(expiration.Sub(timeutil.Now())):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	garbage := details.NewIndexID
+	if desc.PrimaryIndex.ID != details.OldIndexID {
+		garbage = details.OldIndexID
+	}
+	span := desc.IndexSpan(garbage)
+	if err := db.DelRange(ctx, span.Key, span.EndKey); err != nil {
+		return err
+	}
+	if garbage == details.NewIndexID {
+		return errors.Errorf("refresh of materialized view %q was interrupted", desc.Name)
+	}
+	return nil
+}
+
+func materializedViewRefreshResumeHook(
+	typ jobs.Type, _ *cluster.Settings,
+) func(ctx context.Context, job *jobs.Job) error {
+	if typ != jobs.TypeMaterializedViewRefresh {
+		return nil
+	}
+
+	return func(ctx context.Context, job *jobs.Job) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		if err := job.Created(ctx, cancel); err != nil {
+			return err
+		}
+		details := job.Record.Details.(jobs.MaterializedViewRefreshDetails)
+		return cleanupMaterializedViewRefresh(ctx, job.DB(), details)
+	}
+}
+
+func init() {
+	jobs.AddResumeHook(materializedViewRefreshResumeHook)
+}
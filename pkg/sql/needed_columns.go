@@ -96,8 +96,19 @@ func setNeededColumns(plan planNode, needed []bool) {
 		markOmitted(n.resultColumns, n.valNeededForCol)
 
 	case *distinctNode:
-		// Distinct needs values for every input column.
-		setNeededColumns(n.plan, allColumns(n.plan))
+		if n.distinctOnColIdxs.Empty() {
+			// Distinct needs values for every input column.
+			setNeededColumns(n.plan, allColumns(n.plan))
+		} else {
+			// DISTINCT ON only needs values for the DISTINCT ON columns in
+			// addition to those needed by the context.
+			sourceNeeded := make([]bool, len(planColumns(n.plan)))
+			copy(sourceNeeded, needed)
+			n.distinctOnColIdxs.ForEach(func(i int) {
+				sourceNeeded[i] = true
+			})
+			setNeededColumns(n.plan, sourceNeeded)
+		}
 
 	case *filterNode:
 		// Detect which columns from the source are needed in addition to
@@ -184,18 +195,25 @@ func setNeededColumns(plan planNode, needed []bool) {
 		setNeededColumns(n.rows, allColumns(n.rows))
 
 	case *alterTableNode:
+	case *alterTypeNode:
+	case *alterUserNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createPolicyNode:
+	case *createTypeNode:
 	case *createUserNode:
 	case *createViewNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropPolicyNode:
 	case *dropTableNode:
+	case *dropTypeNode:
 	case *dropViewNode:
 	case *dropUserNode:
+	case *refreshMaterializedViewNode:
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
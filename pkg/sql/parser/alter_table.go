@@ -63,6 +63,8 @@ func (*AlterTableAddConstraint) alterTableCmd()      {}
 func (*AlterTableDropColumn) alterTableCmd()         {}
 func (*AlterTableDropConstraint) alterTableCmd()     {}
 func (*AlterTableDropNotNull) alterTableCmd()        {}
+func (*AlterTableRowLevelSecurity) alterTableCmd()   {}
+func (*AlterTableSetAudit) alterTableCmd()           {}
 func (*AlterTableSetDefault) alterTableCmd()         {}
 func (*AlterTableValidateConstraint) alterTableCmd() {}
 
@@ -71,6 +73,8 @@ var _ AlterTableCmd = &AlterTableAddConstraint{}
 var _ AlterTableCmd = &AlterTableDropColumn{}
 var _ AlterTableCmd = &AlterTableDropConstraint{}
 var _ AlterTableCmd = &AlterTableDropNotNull{}
+var _ AlterTableCmd = &AlterTableRowLevelSecurity{}
+var _ AlterTableCmd = &AlterTableSetAudit{}
 var _ AlterTableCmd = &AlterTableSetDefault{}
 var _ AlterTableCmd = &AlterTableValidateConstraint{}
 
@@ -227,3 +231,49 @@ func (node *AlterTableDropNotNull) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.Column)
 	buf.WriteString(" DROP NOT NULL")
 }
+
+// AuditMode represents a table audit mode.
+type AuditMode int
+
+const (
+	// AuditModeDisable is the default mode - no audit.
+	AuditModeDisable AuditMode = iota
+	// AuditModeReadWrite enables audit on read or write statements.
+	AuditModeReadWrite
+)
+
+var auditModeName = [...]string{
+	AuditModeDisable:   "OFF",
+	AuditModeReadWrite: "READ WRITE",
+}
+
+func (m AuditMode) String() string {
+	return auditModeName[m]
+}
+
+// AlterTableSetAudit represents an EXPERIMENTAL_AUDIT SET command.
+type AlterTableSetAudit struct {
+	Mode AuditMode
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetAudit) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("EXPERIMENTAL_AUDIT SET ")
+	buf.WriteString(node.Mode.String())
+}
+
+// AlterTableRowLevelSecurity represents an ALTER TABLE {ENABLE | DISABLE}
+// ROW LEVEL SECURITY command.
+type AlterTableRowLevelSecurity struct {
+	Enable bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableRowLevelSecurity) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Enable {
+		buf.WriteString("ENABLE")
+	} else {
+		buf.WriteString("DISABLE")
+	}
+	buf.WriteString(" ROW LEVEL SECURITY")
+}
@@ -0,0 +1,41 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import "bytes"
+
+// AlterUser represents an ALTER USER statement. It either changes the
+// options of the user, or if Unlock is set, unlocks an account locked
+// after too many failed login attempts.
+type AlterUser struct {
+	Name     Name
+	IfExists bool
+	Unlock   bool
+	UserOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterUser) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER USER ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	if node.Unlock {
+		buf.WriteString(" ACCOUNT UNLOCK")
+		return
+	}
+	FormatNode(buf, f, &node.UserOptions)
+}
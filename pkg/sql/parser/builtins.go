@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
@@ -1254,6 +1255,32 @@ CockroachDB supports the following flags:
 		},
 	},
 
+	"current_time": {
+		Builtin{
+			Types:      ArgTypes{},
+			ReturnType: fixedReturnType(TypeTimeTZ),
+			impure:     true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				t := ctx.GetTxnTimestamp(time.Microsecond).Time
+				return MakeDTimeTZFromTime(t.In(ctx.GetLocation())), nil
+			},
+			Info: "Returns the current transaction's time of day, with time zone.",
+		},
+	},
+
+	"localtime": {
+		Builtin{
+			Types:      ArgTypes{},
+			ReturnType: fixedReturnType(TypeTime),
+			impure:     true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				t := ctx.GetTxnTimestamp(time.Microsecond).Time
+				return MakeDTime(timeofday.FromTime(t.In(ctx.GetLocation()))), nil
+			},
+			Info: "Returns the current transaction's time of day.",
+		},
+	},
+
 	"now":                   txnTSImpl,
 	"current_timestamp":     txnTSImpl,
 	"transaction_timestamp": txnTSImpl,
@@ -1315,6 +1342,9 @@ CockroachDB supports the following flags:
 		},
 	},
 
+	// timezone is used to implement the AT TIME ZONE operator.
+	"timezone": append(timezoneImpls(TypeString), timezoneImpls(TypeInterval)...),
+
 	"extract": {
 		Builtin{
 			Types:      ArgTypes{{"element", TypeString}, {"input", TypeTimestamp}},
@@ -1357,6 +1387,25 @@ CockroachDB supports the following flags:
 				"Compatible elements: year, quarter, month, week, dayofweek, dayofyear,\n" +
 				"hour, minute, second, millisecond, microsecond, epoch",
 		},
+		Builtin{
+			Types:      ArgTypes{{"element", TypeString}, {"input", TypeTime}},
+			ReturnType: fixedReturnType(TypeInt),
+			category:   categoryDateAndTime,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				fromTime := timeofday.TimeOfDay(*args[1].(*DTime))
+				timeSpan := strings.ToLower(string(MustBeDString(args[0])))
+				switch timeSpan {
+				case "hour", "hours", "minute", "minutes", "second", "seconds",
+					"millisecond", "milliseconds", "microsecond", "microseconds", "epoch":
+					return extractStringFromTimestamp(ctx, fromTime.ToTime(), timeSpan)
+				default:
+					return nil, pgerror.NewErrorf(
+						pgerror.CodeInvalidParameterValueError, "unsupported timespan: %s", timeSpan)
+				}
+			},
+			Info: "Extracts `element` from `input`.\n\n" +
+				"Compatible elements: hour, minute, second, millisecond, microsecond, epoch",
+		},
 	},
 
 	"extract_duration": {
@@ -2041,6 +2090,30 @@ CockroachDB supports the following flags:
 		},
 	},
 
+	"current_setting": {
+		Builtin{
+			Types:      ArgTypes{{"setting_name", TypeString}},
+			ReturnType: fixedReturnType(TypeString),
+			category:   categorySystemInfo,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				return currentSetting(ctx, string(MustBeDString(args[0])), false /* missingOK */)
+			},
+			Info: "Returns the current value of the session variable `setting_name`. " +
+				"This function is provided for compatibility with PostgreSQL.",
+		},
+		Builtin{
+			Types:      ArgTypes{{"setting_name", TypeString}, {"missing_ok", TypeBool}},
+			ReturnType: fixedReturnType(TypeString),
+			category:   categorySystemInfo,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				return currentSetting(ctx, string(MustBeDString(args[0])), bool(*args[1].(*DBool)))
+			},
+			Info: "Returns the current value of the session variable `setting_name`, " +
+				"or NULL if it is not defined and `missing_ok` is true. This function is " +
+				"provided for compatibility with PostgreSQL.",
+		},
+	},
+
 	"current_user": {
 		Builtin{
 			Types:      ArgTypes{},
@@ -2332,6 +2405,106 @@ var txnTSImpl = []Builtin{
 	},
 }
 
+// timezoneImpls returns the overloads of the timezone builtin whose zone
+// argument has type zoneType. A string zone names a location; an interval
+// zone is a fixed offset east of UTC.
+func timezoneImpls(zoneType Type) []Builtin {
+	return []Builtin{
+		{
+			Types:      ArgTypes{{"zone", zoneType}, {"ts", TypeTimestamp}},
+			ReturnType: fixedReturnType(TypeTimestampTZ),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := timeZoneArgToLocation(args[0])
+				if err != nil {
+					return nil, err
+				}
+				t := args[1].(*DTimestamp).Time
+				return MakeDTimestampTZ(time.Date(t.Year(), t.Month(), t.Day(),
+					t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc), time.Microsecond), nil
+			},
+			Info: "Treats `ts` as a wall clock reading in time zone `zone` and returns " +
+				"the corresponding timestamp with time zone.",
+		},
+		{
+			Types:      ArgTypes{{"zone", zoneType}, {"ts", TypeTimestampTZ}},
+			ReturnType: fixedReturnType(TypeTimestamp),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := timeZoneArgToLocation(args[0])
+				if err != nil {
+					return nil, err
+				}
+				t := args[1].(*DTimestampTZ).Time.In(loc)
+				return MakeDTimestamp(time.Date(t.Year(), t.Month(), t.Day(),
+					t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC), time.Microsecond), nil
+			},
+			Info: "Returns the wall clock reading of `ts` in time zone `zone`.",
+		},
+		{
+			Types:      ArgTypes{{"zone", zoneType}, {"t", TypeTimeTZ}},
+			ReturnType: fixedReturnType(TypeTimeTZ),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := timeZoneArgToLocation(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return convertTimeTZToLocation(args[1].(*DTimeTZ), loc), nil
+			},
+			Info: "Converts `t` to time zone `zone`.",
+		},
+		{
+			Types:      ArgTypes{{"zone", zoneType}, {"t", TypeTime}},
+			ReturnType: fixedReturnType(TypeTimeTZ),
+			category:   categoryDateAndTime,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				loc, err := timeZoneArgToLocation(args[0])
+				if err != nil {
+					return nil, err
+				}
+				// A time without time zone is taken to be in the session time zone.
+				_, offsetSecs := timeutil.Now().In(ctx.GetLocation()).Zone()
+				t := MakeDTimeTZ(timeofday.TimeOfDay(*args[1].(*DTime)), int32(offsetSecs))
+				return convertTimeTZToLocation(t, loc), nil
+			},
+			Info: "Treats `t` as a time in the session time zone and converts it to " +
+				"time zone `zone`.",
+		},
+	}
+}
+
+// timeZoneArgToLocation converts the zone argument of the timezone builtin to
+// a time.Location. Location names are looked up the same way as for SET TIME
+// ZONE.
+func timeZoneArgToLocation(d Datum) (*time.Location, error) {
+	switch v := d.(type) {
+	case *DString:
+		loc, err := timeutil.TimeZoneNameToLocation(string(*v))
+		if err != nil {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"cannot find time zone %q: %v", string(*v), err)
+		}
+		return loc, nil
+	case *DInterval:
+		if v.Months != 0 || v.Days != 0 {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"interval time zone %s must not include months or days", v)
+		}
+		return timeutil.FixedOffsetTimeZoneToLocation(int(v.Nanos/int64(time.Second)), v.String()), nil
+	default:
+		return nil, pgerror.NewErrorf(pgerror.CodeInternalError, "unexpected zone argument %s", d)
+	}
+}
+
+// convertTimeTZToLocation returns the time of day in loc at the same instant
+// as t, using the UTC offset currently in effect in loc.
+func convertTimeTZToLocation(t *DTimeTZ, loc *time.Location) *DTimeTZ {
+	_, offsetSecs := timeutil.Now().In(loc).Zone()
+	utc := timeofday.ToUTC(t.TimeOfDay, t.OffsetSecs)
+	return MakeDTimeTZ(timeofday.FromUTC(utc, int32(offsetSecs)), int32(offsetSecs))
+}
+
 var powImpls = []Builtin{
 	floatBuiltin2("x", "y", func(x, y float64) (Datum, error) {
 		return NewDFloat(DFloat(math.Pow(x, y))), nil
@@ -2485,6 +2658,25 @@ func bytesBuiltin1(
 	}
 }
 
+// currentSetting implements current_setting(). The session variables are
+// only available where the statement is planned; elsewhere, the function
+// must have been folded into a constant during normalization.
+func currentSetting(ctx *EvalContext, name string, missingOK bool) (Datum, error) {
+	if ctx.Planner == nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"current_setting() cannot be evaluated here")
+	}
+	value, ok := ctx.Planner.SessionVar(strings.ToLower(name))
+	if !ok {
+		if missingOK {
+			return DNull, nil
+		}
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"unrecognized configuration parameter %q", name)
+	}
+	return NewDString(value), nil
+}
+
 func feedHash(h hash.Hash, args Datums) {
 	for _, datum := range args {
 		if datum == DNull {
@@ -43,6 +43,8 @@ func (*IntColType) columnType()            {}
 func (*FloatColType) columnType()          {}
 func (*DecimalColType) columnType()        {}
 func (*DateColType) columnType()           {}
+func (*TimeColType) columnType()           {}
+func (*TimeTZColType) columnType()         {}
 func (*TimestampColType) columnType()      {}
 func (*TimestampTZColType) columnType()    {}
 func (*IntervalColType) columnType()       {}
@@ -55,6 +57,7 @@ func (*CollatedStringColType) columnType() {}
 func (*ArrayColType) columnType()          {}
 func (*VectorColType) columnType()         {}
 func (*OidColType) columnType()            {}
+func (*EnumColType) columnType()           {}
 
 // All ColumnTypes also implement CastTargetType.
 func (*BoolColType) castTargetType()           {}
@@ -62,6 +65,8 @@ func (*IntColType) castTargetType()            {}
 func (*FloatColType) castTargetType()          {}
 func (*DecimalColType) castTargetType()        {}
 func (*DateColType) castTargetType()           {}
+func (*TimeColType) castTargetType()           {}
+func (*TimeTZColType) castTargetType()         {}
 func (*TimestampColType) castTargetType()      {}
 func (*TimestampTZColType) castTargetType()    {}
 func (*IntervalColType) castTargetType()       {}
@@ -74,6 +79,7 @@ func (*CollatedStringColType) castTargetType() {}
 func (*ArrayColType) castTargetType()          {}
 func (*VectorColType) castTargetType()         {}
 func (*OidColType) castTargetType()            {}
+func (*EnumColType) castTargetType()           {}
 
 // Pre-allocated immutable boolean column types.
 var (
@@ -251,6 +257,30 @@ func (node *DateColType) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("DATE")
 }
 
+// Pre-allocated immutable time column type.
+var timeColTypeTime = &TimeColType{}
+
+// TimeColType represents a TIME type.
+type TimeColType struct {
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TimeColType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("TIME")
+}
+
+// Pre-allocated immutable time with time zone column type.
+var timeTZColTypeTimeWithTZ = &TimeTZColType{}
+
+// TimeTZColType represents a TIME WITH TIME ZONE type.
+type TimeTZColType struct {
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TimeTZColType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("TIME WITH TIME ZONE")
+}
+
 // Pre-allocated immutable timestamp column type.
 var timestampColTypeTimestamp = &TimestampColType{}
 
@@ -486,11 +516,52 @@ func oidTypeToColType(t Type) *OidColType {
 	}
 }
 
+// EnumColType represents a user-defined ENUM type, referenced by name. The
+// type itself is looked up during semantic analysis, which fills in Typ.
+type EnumColType struct {
+	Name Name
+	Typ  TEnum
+}
+
+// Format implements the NodeFormatter interface.
+func (node *EnumColType) Format(buf *bytes.Buffer, f FmtFlags) {
+	FormatNode(buf, f, node.Name)
+}
+
+// resolve looks up the type referenced by the receiver. It is done again on
+// every type check so that prepared statements observe changes to the type.
+func (node *EnumColType) resolve(ctx *SemaContext) error {
+	if ctx == nil || ctx.TypeResolver == nil {
+		if node.Typ.TypeID == 0 {
+			return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+				"type %q does not exist", node.Name)
+		}
+		return nil
+	}
+	typ, err := ctx.TypeResolver.ResolveEnumType(node.Name)
+	if err != nil {
+		return err
+	}
+	node.Typ = typ
+	return nil
+}
+
+// resolveCastTarget resolves the user-defined type referenced by a cast
+// target, if any.
+func resolveCastTarget(ctx *SemaContext, t CastTargetType) error {
+	if ct, ok := t.(*EnumColType); ok {
+		return ct.resolve(ctx)
+	}
+	return nil
+}
+
 func (node *BoolColType) String() string           { return AsString(node) }
 func (node *IntColType) String() string            { return AsString(node) }
 func (node *FloatColType) String() string          { return AsString(node) }
 func (node *DecimalColType) String() string        { return AsString(node) }
 func (node *DateColType) String() string           { return AsString(node) }
+func (node *TimeColType) String() string           { return AsString(node) }
+func (node *TimeTZColType) String() string         { return AsString(node) }
 func (node *TimestampColType) String() string      { return AsString(node) }
 func (node *TimestampTZColType) String() string    { return AsString(node) }
 func (node *IntervalColType) String() string       { return AsString(node) }
@@ -503,6 +574,7 @@ func (node *CollatedStringColType) String() string { return AsString(node) }
 func (node *ArrayColType) String() string          { return AsString(node) }
 func (node *VectorColType) String() string         { return AsString(node) }
 func (node *OidColType) String() string            { return AsString(node) }
+func (node *EnumColType) String() string           { return AsString(node) }
 
 // DatumTypeToColumnType produces a SQL column type equivalent to the
 // given Datum type. Used to generate CastExpr nodes during
@@ -517,6 +589,10 @@ func DatumTypeToColumnType(t Type) (ColumnType, error) {
 		return floatColTypeFloat, nil
 	case TypeDecimal:
 		return decimalColTypeDecimal, nil
+	case TypeTime:
+		return timeColTypeTime, nil
+	case TypeTimeTZ:
+		return timeTZColTypeTimeWithTZ, nil
 	case TypeTimestamp:
 		return timestampColTypeTimestamp, nil
 	case TypeTimestampTZ:
@@ -547,6 +623,10 @@ func DatumTypeToColumnType(t Type) (ColumnType, error) {
 	switch typ := t.(type) {
 	case TCollatedString:
 		return &CollatedStringColType{Name: "STRING", Locale: typ.Locale}, nil
+	case TEnum:
+		if typ.TypeID != 0 {
+			return &EnumColType{Name: Name(typ.TypeName), Typ: typ}, nil
+		}
 	case TArray:
 		elemTyp, err := DatumTypeToColumnType(typ.Typ)
 		if err != nil {
@@ -581,6 +661,10 @@ func CastTargetToDatumType(t CastTargetType) Type {
 		return TypeBytes
 	case *DateColType:
 		return TypeDate
+	case *TimeColType:
+		return TypeTime
+	case *TimeTZColType:
+		return TypeTimeTZ
 	case *TimestampColType:
 		return TypeTimestamp
 	case *TimestampTZColType:
@@ -599,6 +683,8 @@ func CastTargetToDatumType(t CastTargetType) Type {
 		return TypeIntVector
 	case *OidColType:
 		return oidColTypeToType(ct)
+	case *EnumColType:
+		return ct.Typ
 	default:
 		panic(fmt.Sprintf("unexpected CastTarget %T", t))
 	}
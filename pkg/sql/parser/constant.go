@@ -346,11 +346,14 @@ var (
 		TypeFloat,
 		TypeDecimal,
 		TypeDate,
+		TypeTime,
+		TypeTimeTZ,
 		TypeTimestamp,
 		TypeTimestampTZ,
 		TypeInterval,
 		TypeUUID,
 		TypeINet,
+		TypeAnyEnum,
 	}
 	strValAvailBytesString = []Type{TypeBytes, TypeString, TypeUUID, TypeINet}
 	strValAvailBytes       = []Type{TypeBytes, TypeUUID}
@@ -427,6 +430,10 @@ func (expr *StrVal) ResolveAsType(ctx *SemaContext, typ Type) (Datum, error) {
 		return ParseDDate(expr.s, ctx.getLocation())
 	case TypeINet:
 		return ParseDIPAddrFromINetString(expr.s)
+	case TypeTime:
+		return ParseDTime(expr.s)
+	case TypeTimeTZ:
+		return ParseDTimeTZ(expr.s, ctx.getLocation())
 	case TypeTimestamp:
 		return ParseDTimestamp(expr.s, time.Microsecond)
 	case TypeTimestampTZ:
@@ -439,6 +446,13 @@ func (expr *StrVal) ResolveAsType(ctx *SemaContext, typ Type) (Datum, error) {
 		}
 		return ParseDUuidFromString(expr.s)
 	default:
+		// A string can become a member of any particular ENUM type.
+		if t, ok := typ.(TEnum); ok {
+			if t.TypeID == 0 {
+				return nil, makeParseError(expr.s, typ, errors.New("ENUM type is not known"))
+			}
+			return MakeDEnumFromLogicalRep(t, expr.s)
+		}
 		return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
 			"could not resolve %T %v into a %T", expr, expr, typ)
 	}
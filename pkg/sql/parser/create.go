@@ -636,11 +636,79 @@ func (node *InterleaveDef) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// PartitionBy represents a PARTITION BY definition within a CREATE TABLE
+// statement. Exactly one of List and Range is set.
+type PartitionBy struct {
+	Fields NameList
+	List   []ListPartition
+	Range  []RangePartition
+}
+
+// Format implements the NodeFormatter interface.
+func (node *PartitionBy) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.List != nil {
+		buf.WriteString(" PARTITION BY LIST (")
+	} else {
+		buf.WriteString(" PARTITION BY RANGE (")
+	}
+	FormatNode(buf, f, node.Fields)
+	buf.WriteString(") (")
+	for i := range node.List {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, &node.List[i])
+	}
+	for i := range node.Range {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, &node.Range[i])
+	}
+	buf.WriteByte(')')
+}
+
+// ListPartition represents a PARTITION definition within a PARTITION BY LIST.
+type ListPartition struct {
+	Name  Name
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ListPartition) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("PARTITION ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" VALUES IN (")
+	FormatNode(buf, f, node.Exprs)
+	buf.WriteByte(')')
+}
+
+// RangePartition represents a PARTITION definition within a PARTITION BY
+// RANGE.
+type RangePartition struct {
+	Name Name
+	From Exprs
+	To   Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RangePartition) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("PARTITION ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" VALUES FROM (")
+	FormatNode(buf, f, node.From)
+	buf.WriteString(") TO (")
+	FormatNode(buf, f, node.To)
+	buf.WriteByte(')')
+}
+
 // CreateTable represents a CREATE TABLE statement.
 type CreateTable struct {
 	IfNotExists   bool
+	Temporary     bool
 	Table         NormalizableTableName
 	Interleave    *InterleaveDef
+	PartitionBy   *PartitionBy
 	Defs          TableDefs
 	AsSource      *Select
 	AsColumnNames NameList // Only to be used in conjunction with AsSource
@@ -654,7 +722,11 @@ func (node *CreateTable) As() bool {
 
 // Format implements the NodeFormatter interface.
 func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE TABLE ")
+	buf.WriteString("CREATE ")
+	if node.Temporary {
+		buf.WriteString("TEMPORARY ")
+	}
+	buf.WriteString("TABLE ")
 	if node.IfNotExists {
 		buf.WriteString("IF NOT EXISTS ")
 	}
@@ -674,24 +746,26 @@ func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
 		if node.Interleave != nil {
 			FormatNode(buf, f, node.Interleave)
 		}
+		if node.PartitionBy != nil {
+			FormatNode(buf, f, node.PartitionBy)
+		}
 	}
 }
 
-// CreateUser represents a CREATE USER statement.
-type CreateUser struct {
-	Name     Name
-	Password *string // pointer so that empty and nil can be differentiated
+// UserOptions represents the options of a CREATE USER or ALTER USER
+// statement.
+type UserOptions struct {
+	Password   *string // pointer so that empty and nil can be differentiated
+	ValidUntil *string
 }
 
-// HasPassword returns if the CreateUser has a password.
-func (node *CreateUser) HasPassword() bool {
+// HasPassword returns if the options set a password.
+func (node *UserOptions) HasPassword() bool {
 	return node.Password != nil
 }
 
 // Format implements the NodeFormatter interface.
-func (node *CreateUser) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE USER ")
-	FormatNode(buf, f, node.Name)
+func (node *UserOptions) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.HasPassword() {
 		buf.WriteString(" WITH PASSWORD ")
 		if f.showPasswords {
@@ -700,18 +774,72 @@ func (node *CreateUser) Format(buf *bytes.Buffer, f FmtFlags) {
 			buf.WriteString("*****")
 		}
 	}
+	if node.ValidUntil != nil {
+		buf.WriteString(" VALID UNTIL ")
+		encodeSQLStringWithFlags(buf, *node.ValidUntil, f)
+	}
+}
+
+var (
+	errPasswordSpecifiedMultipleTimes   = pgerror.NewError(pgerror.CodeSyntaxError, "password specified multiple times")
+	errValidUntilSpecifiedMultipleTimes = pgerror.NewError(pgerror.CodeSyntaxError, "VALID UNTIL specified multiple times")
+)
+
+func (node *UserOptions) merge(other UserOptions) error {
+	if other.Password != nil {
+		if node.Password != nil {
+			return errPasswordSpecifiedMultipleTimes
+		}
+		node.Password = other.Password
+	}
+	if other.ValidUntil != nil {
+		if node.ValidUntil != nil {
+			return errValidUntilSpecifiedMultipleTimes
+		}
+		node.ValidUntil = other.ValidUntil
+	}
+	return nil
+}
+
+// CreateUser represents a CREATE USER statement.
+type CreateUser struct {
+	Name Name
+	UserOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateUser) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE USER ")
+	FormatNode(buf, f, node.Name)
+	FormatNode(buf, f, &node.UserOptions)
+}
+
+// CreateRole represents a CREATE ROLE statement.
+type CreateRole struct {
+	Name Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE ROLE ")
+	FormatNode(buf, f, node.Name)
 }
 
 // CreateView represents a CREATE VIEW statement.
 type CreateView struct {
-	Name        NormalizableTableName
-	ColumnNames NameList
-	AsSource    *Select
+	Name         NormalizableTableName
+	ColumnNames  NameList
+	AsSource     *Select
+	Materialized bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *CreateView) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE VIEW ")
+	buf.WriteString("CREATE ")
+	if node.Materialized {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
 	FormatNode(buf, f, &node.Name)
 
 	if len(node.ColumnNames) > 0 {
@@ -724,3 +852,71 @@ func (node *CreateView) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString(" AS ")
 	FormatNode(buf, f, node.AsSource)
 }
+
+// RefreshMaterializedView represents a REFRESH MATERIALIZED VIEW statement.
+type RefreshMaterializedView struct {
+	Name         NormalizableTableName
+	Concurrently bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RefreshMaterializedView) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("REFRESH MATERIALIZED VIEW ")
+	if node.Concurrently {
+		buf.WriteString("CONCURRENTLY ")
+	}
+	FormatNode(buf, f, &node.Name)
+}
+
+// CreateType represents a CREATE TYPE statement. Only ENUM types are
+// currently supported.
+type CreateType struct {
+	Name       Name
+	EnumLabels []string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE TYPE ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" AS ENUM (")
+	for i, label := range node.EnumLabels {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		encodeSQLStringWithFlags(buf, label, f)
+	}
+	buf.WriteByte(')')
+}
+
+// CreatePolicy represents a CREATE POLICY statement.
+type CreatePolicy struct {
+	Name  Name
+	Table NormalizableTableName
+	// Roles is empty if the policy applies to all users.
+	Roles     NameList
+	Using     Expr
+	WithCheck Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreatePolicy) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE POLICY ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" ON ")
+	FormatNode(buf, f, &node.Table)
+	if len(node.Roles) > 0 {
+		buf.WriteString(" TO ")
+		FormatNode(buf, f, node.Roles)
+	}
+	if node.Using != nil {
+		buf.WriteString(" USING (")
+		FormatNode(buf, f, node.Using)
+		buf.WriteByte(')')
+	}
+	if node.WithCheck != nil {
+		buf.WriteString(" WITH CHECK (")
+		FormatNode(buf, f, node.WithCheck)
+		buf.WriteByte(')')
+	}
+}
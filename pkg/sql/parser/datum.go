@@ -38,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
@@ -1715,6 +1716,445 @@ func (d *DTimestampTZ) Size() uintptr {
 	return unsafe.Sizeof(*d)
 }
 
+// DTime is the time Datum.
+type DTime timeofday.TimeOfDay
+
+// MakeDTime creates a DTime from a TimeOfDay.
+func MakeDTime(t timeofday.TimeOfDay) *DTime {
+	d := DTime(t)
+	return &d
+}
+
+// time of day formats.
+const (
+	timeOfDayFormat        = "15:04:05"
+	timeOfDayNoSecsFormat  = "15:04"
+	timeOfDayMeridiem      = "3:04:05 PM"
+	timeOfDayNoSecsMerid   = "3:04 PM"
+	timeOfDayZoneSuffix    = "-070000"
+	timeOfDayUTCZoneSuffix = "Z"
+)
+
+var timeOfDayFormats = []string{
+	timeOfDayFormat,
+	timeOfDayNoSecsFormat,
+	timeOfDayMeridiem,
+	timeOfDayNoSecsMerid,
+}
+
+// parseTimeOfDay parses a string representing a time of day, optionally
+// followed by a UTC offset. Strings which contain a date as well are accepted
+// too, in which case the date is ignored and the zone is resolved in the
+// provided location. The returned bool indicates whether the string specified
+// a zone (or a date from which a zone could be determined).
+func parseTimeOfDay(s string, loc *time.Location, typ Type) (time.Time, bool, error) {
+	origS := s
+	s = strings.TrimSpace(s)
+	zoneSuffix := ""
+	if strings.HasSuffix(s, timeOfDayUTCZoneSuffix) {
+		s = strings.TrimSpace(strings.TrimSuffix(s, timeOfDayUTCZoneSuffix))
+		zoneSuffix = timeOfDayUTCZoneSuffix
+	} else if i := strings.LastIndexAny(s, "+-"); i > 0 {
+		// Remove `:` characters from the offset and pad it to 6 digits, as in
+		// parseTimestampInLocation, to convert the offset to the `-070000`
+		// format.
+		tzSpec := strings.Replace(strings.TrimSpace(s[i+1:]), ":", "", -1)
+		if len(tzSpec)%2 == 1 {
+			tzSpec = "0" + tzSpec
+		}
+		if len(tzSpec) < 6 {
+			tzSpec += strings.Repeat("0", 6-len(tzSpec))
+		}
+		s = strings.TrimSpace(s[:i]) + s[i:i+1] + tzSpec
+		zoneSuffix = timeOfDayZoneSuffix
+	}
+	for _, format := range timeOfDayFormats {
+		if t, err := time.ParseInLocation(format+zoneSuffix, s, time.UTC); err == nil {
+			return t, zoneSuffix != "", nil
+		}
+	}
+	// Fall back to parsing a full timestamp and discarding the date.
+	t, err := parseTimestampInLocation(origS, loc, typ)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
+
+// ParseDTime parses and returns the *DTime Datum value represented by the
+// provided string, or an error if parsing is unsuccessful. Any time zone in
+// the string is ignored.
+func ParseDTime(s string) (*DTime, error) {
+	t, _, err := parseTimeOfDay(s, time.UTC, TypeTime)
+	if err != nil {
+		return nil, err
+	}
+	return MakeDTime(timeofday.FromTime(t)), nil
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DTime) ResolvedType() Type {
+	return TypeTime
+}
+
+// Compare implements the Datum interface.
+func (d *DTime) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := other.(*DTime)
+	if !ok {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	if *d < *v {
+		return -1
+	}
+	if *v < *d {
+		return 1
+	}
+	return 0
+}
+
+// Prev implements the Datum interface.
+func (d *DTime) Prev() (Datum, bool) {
+	if d.IsMin() {
+		return nil, false
+	}
+	prev := *d - 1
+	return &prev, true
+}
+
+// Next implements the Datum interface.
+func (d *DTime) Next() (Datum, bool) {
+	if d.IsMax() {
+		return nil, false
+	}
+	next := *d + 1
+	return &next, true
+}
+
+var dTimeMin = MakeDTime(timeofday.Min)
+var dTimeMax = MakeDTime(timeofday.Max)
+
+// IsMax implements the Datum interface.
+func (d *DTime) IsMax() bool {
+	return *d == *dTimeMax
+}
+
+// IsMin implements the Datum interface.
+func (d *DTime) IsMin() bool {
+	return *d == *dTimeMin
+}
+
+// max implements the Datum interface.
+func (d *DTime) max() (Datum, bool) {
+	return dTimeMax, true
+}
+
+// min implements the Datum interface.
+func (d *DTime) min() (Datum, bool) {
+	return dTimeMin, true
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DTime) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DTime) Format(buf *bytes.Buffer, f FmtFlags) {
+	if !f.bareStrings {
+		buf.WriteByte('\'')
+	}
+	timeofday.TimeOfDay(*d).Format(buf)
+	if !f.bareStrings {
+		buf.WriteByte('\'')
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DTime) Size() uintptr {
+	return unsafe.Sizeof(*d)
+}
+
+// The range of UTC offsets accepted for a DTimeTZ, matching Postgres.
+const (
+	maxTimeTZOffsetSecs = 15*60*60 + 59*60 + 59
+	minTimeTZOffsetSecs = -maxTimeTZOffsetSecs
+)
+
+// DTimeTZ is the time with time zone Datum. It represents a time of day
+// observed at a fixed offset from UTC.
+type DTimeTZ struct {
+	TimeOfDay timeofday.TimeOfDay
+	// OffsetSecs is the UTC offset of TimeOfDay, in seconds east of UTC.
+	OffsetSecs int32
+}
+
+// MakeDTimeTZ creates a DTimeTZ from a TimeOfDay and an offset in seconds east
+// of UTC.
+func MakeDTimeTZ(t timeofday.TimeOfDay, offsetSecs int32) *DTimeTZ {
+	return &DTimeTZ{TimeOfDay: t, OffsetSecs: offsetSecs}
+}
+
+// MakeDTimeTZFromTime creates a DTimeTZ from the wall-clock reading and UTC
+// offset of a time.Time.
+func MakeDTimeTZFromTime(t time.Time) *DTimeTZ {
+	_, offsetSecs := t.Zone()
+	return MakeDTimeTZ(timeofday.FromTime(t), int32(offsetSecs))
+}
+
+// ParseDTimeTZ parses and returns the *DTimeTZ Datum value represented by the
+// provided string, or an error if parsing is unsuccessful. If the string does
+// not specify a UTC offset, the current offset of the provided location is
+// used.
+func ParseDTimeTZ(s string, loc *time.Location) (*DTimeTZ, error) {
+	t, hasZone, err := parseTimeOfDay(s, loc, TypeTimeTZ)
+	if err != nil {
+		return nil, err
+	}
+	if !hasZone {
+		_, offsetSecs := timeutil.Now().In(loc).Zone()
+		return MakeDTimeTZ(timeofday.FromTime(t), int32(offsetSecs)), nil
+	}
+	if _, offsetSecs := t.Zone(); offsetSecs < minTimeTZOffsetSecs || offsetSecs > maxTimeTZOffsetSecs {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidTimeZoneDisplacementValueError,
+			"could not parse %q as type %s: UTC offset out of range", s, TypeTimeTZ)
+	}
+	return MakeDTimeTZFromTime(t), nil
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DTimeTZ) ResolvedType() Type {
+	return TypeTimeTZ
+}
+
+// utc returns the time of day in UTC corresponding to the receiver.
+func (d *DTimeTZ) utc() timeofday.TimeOfDay {
+	return timeofday.ToUTC(d.TimeOfDay, d.OffsetSecs)
+}
+
+// Compare implements the Datum interface. Like Postgres, values are ordered
+// first by the time of day in UTC, and then by UTC offset.
+func (d *DTimeTZ) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := other.(*DTimeTZ)
+	if !ok {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	if l, r := d.utc(), v.utc(); l < r {
+		return -1
+	} else if r < l {
+		return 1
+	}
+	if d.OffsetSecs < v.OffsetSecs {
+		return -1
+	}
+	if v.OffsetSecs < d.OffsetSecs {
+		return 1
+	}
+	return 0
+}
+
+// Prev implements the Datum interface.
+func (d *DTimeTZ) Prev() (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DTimeTZ) Next() (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DTimeTZ) IsMax() bool {
+	return d.utc() == timeofday.Max && d.OffsetSecs == maxTimeTZOffsetSecs
+}
+
+// IsMin implements the Datum interface.
+func (d *DTimeTZ) IsMin() bool {
+	return d.utc() == timeofday.Min && d.OffsetSecs == minTimeTZOffsetSecs
+}
+
+// max implements the Datum interface.
+func (d *DTimeTZ) max() (Datum, bool) {
+	return nil, false
+}
+
+// min implements the Datum interface.
+func (d *DTimeTZ) min() (Datum, bool) {
+	return nil, false
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DTimeTZ) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DTimeTZ) Format(buf *bytes.Buffer, f FmtFlags) {
+	if !f.bareStrings {
+		buf.WriteByte('\'')
+	}
+	d.TimeOfDay.Format(buf)
+	buf.WriteString(timeutil.Unix(0, 0).In(timeofday.OffsetSecondsToZone(d.OffsetSecs)).Format(timeTZOffsetFormat))
+	if !f.bareStrings {
+		buf.WriteByte('\'')
+	}
+}
+
+// timeTZOffsetFormat is used to output the UTC offset of a DTimeTZ.
+const timeTZOffsetFormat = "-07:00"
+
+// Size implements the Datum interface.
+func (d *DTimeTZ) Size() uintptr {
+	return unsafe.Sizeof(*d)
+}
+
+// DEnum is the Datum for a member of a user-defined ENUM type.
+type DEnum struct {
+	EnumTyp TEnum
+	// PhysicalRep is the representation of the member that is stored on disk.
+	PhysicalRep []byte
+	// LogicalRep is the name of the member.
+	LogicalRep string
+}
+
+// MakeDEnumFromPhysicalRep creates a DEnum of the given type from the physical
+// representation of one of its members.
+func MakeDEnumFromPhysicalRep(typ TEnum, rep []byte) (*DEnum, error) {
+	if typ.Members != nil {
+		for i, r := range typ.Members.PhysicalReps {
+			if bytes.Equal(r, rep) {
+				return &DEnum{EnumTyp: typ, PhysicalRep: r, LogicalRep: typ.Members.LogicalReps[i]}, nil
+			}
+		}
+	}
+	return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
+		"could not find %x in enum %s", rep, typ)
+}
+
+// MakeDEnumFromLogicalRep creates a DEnum of the given type from the name of
+// one of its members.
+func MakeDEnumFromLogicalRep(typ TEnum, rep string) (*DEnum, error) {
+	if typ.Members != nil {
+		for i, r := range typ.Members.LogicalReps {
+			if r == rep {
+				return &DEnum{EnumTyp: typ, PhysicalRep: typ.Members.PhysicalReps[i], LogicalRep: r}, nil
+			}
+		}
+	}
+	return nil, pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError,
+		"invalid input value for enum %s: %q", typ, rep)
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (d *DEnum) ResolvedType() Type {
+	return d.EnumTyp
+}
+
+// Compare implements the Datum interface. Members are ordered by their
+// physical representation, which matches their declared order.
+func (d *DEnum) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := other.(*DEnum)
+	if !ok || !d.EnumTyp.Equivalent(v.EnumTyp) {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return bytes.Compare(d.PhysicalRep, v.PhysicalRep)
+}
+
+// memberIdx returns the position of the receiver within the members of its
+// type, or -1 if it is not found.
+func (d *DEnum) memberIdx() int {
+	if d.EnumTyp.Members == nil {
+		return -1
+	}
+	for i, r := range d.EnumTyp.Members.PhysicalReps {
+		if bytes.Equal(r, d.PhysicalRep) {
+			return i
+		}
+	}
+	return -1
+}
+
+// member returns the member of the receiver's type at position idx.
+func (d *DEnum) member(idx int) *DEnum {
+	return &DEnum{
+		EnumTyp:     d.EnumTyp,
+		PhysicalRep: d.EnumTyp.Members.PhysicalReps[idx],
+		LogicalRep:  d.EnumTyp.Members.LogicalReps[idx],
+	}
+}
+
+// Prev implements the Datum interface.
+func (d *DEnum) Prev() (Datum, bool) {
+	idx := d.memberIdx()
+	if idx <= 0 {
+		return nil, false
+	}
+	return d.member(idx - 1), true
+}
+
+// Next implements the Datum interface.
+func (d *DEnum) Next() (Datum, bool) {
+	idx := d.memberIdx()
+	if idx < 0 || idx == len(d.EnumTyp.Members.PhysicalReps)-1 {
+		return nil, false
+	}
+	return d.member(idx + 1), true
+}
+
+// IsMax implements the Datum interface.
+func (d *DEnum) IsMax() bool {
+	idx := d.memberIdx()
+	return idx >= 0 && idx == len(d.EnumTyp.Members.PhysicalReps)-1
+}
+
+// IsMin implements the Datum interface.
+func (d *DEnum) IsMin() bool {
+	return d.memberIdx() == 0
+}
+
+// max implements the Datum interface.
+func (d *DEnum) max() (Datum, bool) {
+	if d.EnumTyp.Members == nil || len(d.EnumTyp.Members.PhysicalReps) == 0 {
+		return nil, false
+	}
+	return d.member(len(d.EnumTyp.Members.PhysicalReps) - 1), true
+}
+
+// min implements the Datum interface.
+func (d *DEnum) min() (Datum, bool) {
+	if d.EnumTyp.Members == nil || len(d.EnumTyp.Members.PhysicalReps) == 0 {
+		return nil, false
+	}
+	return d.member(0), true
+}
+
+// AmbiguousFormat implements the Datum interface. The type of an ENUM value is
+// not annotated when it is serialized, because the type name cannot be resolved
+// everywhere the expression is parsed again, e.g. in DEFAULT expressions. The
+// label is resolved against the type expected in that context instead.
+func (*DEnum) AmbiguousFormat() bool { return false }
+
+// Format implements the NodeFormatter interface.
+func (d *DEnum) Format(buf *bytes.Buffer, f FmtFlags) {
+	if f.withinArray {
+		encodeSQLStringInsideArray(buf, d.LogicalRep)
+	} else {
+		encodeSQLStringWithFlags(buf, d.LogicalRep, f)
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DEnum) Size() uintptr {
+	return unsafe.Sizeof(*d) + uintptr(len(d.PhysicalRep)) + uintptr(len(d.LogicalRep))
+}
+
 // DInterval is the interval Datum.
 type DInterval struct {
 	duration.Duration
@@ -20,6 +20,9 @@ import (
 	"time"
 
 	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 )
 
 func prepareExpr(t *testing.T, datumExpr string) TypedExpr {
@@ -92,6 +95,12 @@ func TestDatumOrdering(t *testing.T) {
 		{`'2006-01-02 03:04:05.123123':::timestamp`,
 			`'2006-01-02 03:04:05.123122+00:00'`, `'2006-01-02 03:04:05.123124+00:00'`, noMin, noMax},
 
+		// Times
+		{`'12:00:00':::time`, `'11:59:59.999999'`, `'12:00:00.000001'`, `'00:00:00'`, `'23:59:59.999999'`},
+		{`'00:00:00':::time`, valIsMin, `'00:00:00.000001'`, `'00:00:00'`, `'23:59:59.999999'`},
+		{`'23:59:59.999999':::time`, `'23:59:59.999998'`, valIsMax, `'00:00:00'`, `'23:59:59.999999'`},
+		{`'12:00:00-05:00':::timetz`, noPrev, noNext, noMin, noMax},
+
 		// Intervals
 		{`'1 day':::interval`, noPrev, noNext,
 			`'-768614336404564650y-8mon-9223372036854775808d-2562047h-47m-16s-854ms-775µs-808ns'`,
@@ -445,3 +454,63 @@ func TestParseDTimestamp(t *testing.T) {
 		}
 	}
 }
+
+func TestParseDTime(t *testing.T) {
+	testData := []struct {
+		str      string
+		expected timeofday.TimeOfDay
+	}{
+		{"04:05:06", timeofday.New(4, 5, 6, 0)},
+		{"04:05:06.000001", timeofday.New(4, 5, 6, 1)},
+		{"04:05:06.123456", timeofday.New(4, 5, 6, 123456)},
+		{"04:05", timeofday.New(4, 5, 0, 0)},
+		{"4:05:06 PM", timeofday.New(16, 5, 6, 0)},
+		{"4:05 AM", timeofday.New(4, 5, 0, 0)},
+		{"04:05:06-07", timeofday.New(4, 5, 6, 0)},
+		{"04:05:06+07:30", timeofday.New(4, 5, 6, 0)},
+		{"04:05:06Z", timeofday.New(4, 5, 6, 0)},
+		{"2001-02-03 04:05:06", timeofday.New(4, 5, 6, 0)},
+		{"2001-02-03 04:05:06-07", timeofday.New(4, 5, 6, 0)},
+	}
+	for _, td := range testData {
+		actual, err := ParseDTime(td.str)
+		if err != nil {
+			t.Errorf("unexpected error while parsing TIME %s: %s", td.str, err)
+			continue
+		}
+		if timeofday.TimeOfDay(*actual) != td.expected {
+			t.Errorf("TIME %s: got %s, expected %s", td.str, actual, td.expected)
+		}
+	}
+}
+
+func TestParseDTimeTZ(t *testing.T) {
+	testData := []struct {
+		str        string
+		expected   timeofday.TimeOfDay
+		offsetSecs int32
+	}{
+		{"04:05:06", timeofday.New(4, 5, 6, 0), 0},
+		{"04:05:06Z", timeofday.New(4, 5, 6, 0), 0},
+		{"04:05:06.123-07", timeofday.New(4, 5, 6, 123000), -7 * 60 * 60},
+		{"04:05:06+07:30", timeofday.New(4, 5, 6, 0), 7*60*60 + 30*60},
+		{"04:05-07:30:09", timeofday.New(4, 5, 0, 0), -(7*60*60 + 30*60 + 9)},
+		{"4:05:06 PM +3", timeofday.New(16, 5, 6, 0), 3 * 60 * 60},
+		{"2001-02-03 04:05:06-07", timeofday.New(4, 5, 6, 0), -7 * 60 * 60},
+	}
+	for _, td := range testData {
+		actual, err := ParseDTimeTZ(td.str, time.UTC)
+		if err != nil {
+			t.Errorf("unexpected error while parsing TIMETZ %s: %s", td.str, err)
+			continue
+		}
+		if actual.TimeOfDay != td.expected || actual.OffsetSecs != td.offsetSecs {
+			t.Errorf("TIMETZ %s: got %s, expected %s with offset %d",
+				td.str, actual, td.expected, td.offsetSecs)
+		}
+	}
+
+	if _, err := ParseDTimeTZ("04:05:06+16", time.UTC); !testutils.IsError(err, "UTC offset out of range") {
+		t.Errorf("expected out of range error, got %v", err)
+	}
+}
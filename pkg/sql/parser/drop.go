@@ -62,6 +62,39 @@ func (node *DropDatabase) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// DropType represents a DROP TYPE statement.
+type DropType struct {
+	Names    NameList
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP TYPE ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Names)
+}
+
+// DropPolicy represents a DROP POLICY statement.
+type DropPolicy struct {
+	Name     Name
+	Table    NormalizableTableName
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropPolicy) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP POLICY ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" ON ")
+	FormatNode(buf, f, &node.Table)
+}
+
 // DropIndex represents a DROP INDEX statement.
 type DropIndex struct {
 	IndexList    TableNameWithIndexList
@@ -122,6 +155,21 @@ func (node *DropView) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// DropRole represents a DROP ROLE statement
+type DropRole struct {
+	Names    NameList
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP ROLE ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Names)
+}
+
 // DropUser represents a DROP USER statement
 type DropUser struct {
 	Names    NameList
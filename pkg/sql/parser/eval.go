@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -331,6 +332,24 @@ func addWithOverflow(a, b int64) (r int64, ok bool) {
 	return a + b, true
 }
 
+// makeDTimestampFromDateAndTime returns the timestamp at time of day t on
+// date d.
+func makeDTimestampFromDateAndTime(d *DDate, t *DTime) *DTimestamp {
+	micros := time.Duration(*t) * time.Microsecond
+	return MakeDTimestamp(timeutil.Unix(int64(*d)*secondsInDay, 0).Add(micros), time.Microsecond)
+}
+
+// makeDTimestampTZFromDateAndTimeTZ returns the instant at which the wall
+// clock at t's UTC offset reads t on date d.
+func makeDTimestampTZFromDateAndTimeTZ(d *DDate, t *DTimeTZ) *DTimestampTZ {
+	year, month, day := timeutil.Unix(int64(*d)*secondsInDay, 0).Date()
+	tod := t.TimeOfDay
+	loc := timeofday.OffsetSecondsToZone(t.OffsetSecs)
+	return MakeDTimestampTZ(
+		time.Date(year, month, day, tod.Hour(), tod.Minute(), tod.Second(), tod.Microsecond()*1000, loc),
+		time.Microsecond)
+}
+
 // BinOps contains the binary operations indexed by operation type.
 var BinOps = map[BinaryOperator]binOpOverload{
 	Bitand: {
@@ -504,6 +523,74 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				return MakeDTimestampTZ(t, time.Microsecond), nil
 			},
 		},
+		BinOp{
+			LeftType:   TypeTime,
+			RightType:  TypeInterval,
+			ReturnType: TypeTime,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := timeofday.TimeOfDay(*left.(*DTime))
+				return MakeDTime(t.Add(right.(*DInterval).Duration)), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeInterval,
+			RightType:  TypeTime,
+			ReturnType: TypeTime,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := timeofday.TimeOfDay(*right.(*DTime))
+				return MakeDTime(t.Add(left.(*DInterval).Duration)), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeDate,
+			RightType:  TypeTime,
+			ReturnType: TypeTimestamp,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return makeDTimestampFromDateAndTime(left.(*DDate), right.(*DTime)), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeTime,
+			RightType:  TypeDate,
+			ReturnType: TypeTimestamp,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return makeDTimestampFromDateAndTime(right.(*DDate), left.(*DTime)), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeTimeTZ,
+			RightType:  TypeInterval,
+			ReturnType: TypeTimeTZ,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := left.(*DTimeTZ)
+				return MakeDTimeTZ(t.TimeOfDay.Add(right.(*DInterval).Duration), t.OffsetSecs), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeInterval,
+			RightType:  TypeTimeTZ,
+			ReturnType: TypeTimeTZ,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := right.(*DTimeTZ)
+				return MakeDTimeTZ(t.TimeOfDay.Add(left.(*DInterval).Duration), t.OffsetSecs), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeDate,
+			RightType:  TypeTimeTZ,
+			ReturnType: TypeTimestampTZ,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return makeDTimestampTZFromDateAndTimeTZ(left.(*DDate), right.(*DTimeTZ)), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeTimeTZ,
+			RightType:  TypeDate,
+			ReturnType: TypeTimestampTZ,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return makeDTimestampTZFromDateAndTimeTZ(right.(*DDate), left.(*DTimeTZ)), nil
+			},
+		},
 	},
 
 	Minus: {
@@ -655,6 +742,34 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				return &DInterval{Duration: left.(*DInterval).Duration.Sub(right.(*DInterval).Duration)}, nil
 			},
 		},
+		BinOp{
+			LeftType:   TypeTime,
+			RightType:  TypeTime,
+			ReturnType: TypeInterval,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t1 := timeofday.TimeOfDay(*left.(*DTime))
+				t2 := timeofday.TimeOfDay(*right.(*DTime))
+				return &DInterval{Duration: timeofday.Difference(t1, t2)}, nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeTime,
+			RightType:  TypeInterval,
+			ReturnType: TypeTime,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := timeofday.TimeOfDay(*left.(*DTime))
+				return MakeDTime(t.Add(right.(*DInterval).Duration.Mul(-1))), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeTimeTZ,
+			RightType:  TypeInterval,
+			ReturnType: TypeTimeTZ,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				t := left.(*DTimeTZ)
+				return MakeDTimeTZ(t.TimeOfDay.Add(right.(*DInterval).Duration.Mul(-1)), t.OffsetSecs), nil
+			},
+		},
 	},
 
 	Mult: {
@@ -1152,6 +1267,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeCollatedString,
 			fn:        cmpOpScalarEQFn,
 		},
+		CmpOp{
+			LeftType:  TypeAnyEnum,
+			RightType: TypeAnyEnum,
+			fn:        cmpOpScalarEQFn,
+		},
 		CmpOp{
 			LeftType:  TypeBytes,
 			RightType: TypeBytes,
@@ -1252,6 +1372,16 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeDate,
 			fn:        cmpOpScalarEQFn,
 		},
+		CmpOp{
+			LeftType:  TypeTime,
+			RightType: TypeTime,
+			fn:        cmpOpScalarEQFn,
+		},
+		CmpOp{
+			LeftType:  TypeTimeTZ,
+			RightType: TypeTimeTZ,
+			fn:        cmpOpScalarEQFn,
+		},
 		CmpOp{
 			LeftType:  TypeInterval,
 			RightType: TypeInterval,
@@ -1292,6 +1422,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeCollatedString,
 			fn:        cmpOpScalarLTFn,
 		},
+		CmpOp{
+			LeftType:  TypeAnyEnum,
+			RightType: TypeAnyEnum,
+			fn:        cmpOpScalarLTFn,
+		},
 		CmpOp{
 			LeftType:  TypeBytes,
 			RightType: TypeBytes,
@@ -1392,6 +1527,16 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeDate,
 			fn:        cmpOpScalarLTFn,
 		},
+		CmpOp{
+			LeftType:  TypeTime,
+			RightType: TypeTime,
+			fn:        cmpOpScalarLTFn,
+		},
+		CmpOp{
+			LeftType:  TypeTimeTZ,
+			RightType: TypeTimeTZ,
+			fn:        cmpOpScalarLTFn,
+		},
 		CmpOp{
 			LeftType:  TypeInterval,
 			RightType: TypeInterval,
@@ -1427,6 +1572,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeCollatedString,
 			fn:        cmpOpScalarLEFn,
 		},
+		CmpOp{
+			LeftType:  TypeAnyEnum,
+			RightType: TypeAnyEnum,
+			fn:        cmpOpScalarLEFn,
+		},
 		CmpOp{
 			LeftType:  TypeBytes,
 			RightType: TypeBytes,
@@ -1527,6 +1677,16 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeDate,
 			fn:        cmpOpScalarLEFn,
 		},
+		CmpOp{
+			LeftType:  TypeTime,
+			RightType: TypeTime,
+			fn:        cmpOpScalarLEFn,
+		},
+		CmpOp{
+			LeftType:  TypeTimeTZ,
+			RightType: TypeTimeTZ,
+			fn:        cmpOpScalarLEFn,
+		},
 		CmpOp{
 			LeftType:  TypeInterval,
 			RightType: TypeInterval,
@@ -1558,8 +1718,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeEvalTupleIn(TypeDecimal),
 		makeEvalTupleIn(TypeString),
 		makeEvalTupleIn(TypeCollatedString),
+		makeEvalTupleIn(TypeAnyEnum),
 		makeEvalTupleIn(TypeBytes),
 		makeEvalTupleIn(TypeDate),
+		makeEvalTupleIn(TypeTime),
+		makeEvalTupleIn(TypeTimeTZ),
 		makeEvalTupleIn(TypeTimestamp),
 		makeEvalTupleIn(TypeTimestampTZ),
 		makeEvalTupleIn(TypeInterval),
@@ -1840,6 +2003,10 @@ type EvalPlanner interface {
 	// QualifyWithDatabase resolves a possibly unqualified table name into a
 	// normalized table name that is qualified by database.
 	QualifyWithDatabase(ctx context.Context, t *NormalizableTableName) (*TableName, error)
+
+	// SessionVar returns the value of the named session variable, and
+	// whether it is defined.
+	SessionVar(name string) (string, bool)
 }
 
 // CtxProvider is anything that can return a Context.
@@ -2425,7 +2592,7 @@ func performCast(ctx *EvalContext, d Datum, t CastTargetType) (Datum, error) {
 		switch t := d.(type) {
 		case *DBool, *DInt, *DFloat, *DDecimal, dNull:
 			s = d.String()
-		case *DTimestamp, *DTimestampTZ, *DDate:
+		case *DTimestamp, *DTimestampTZ, *DDate, *DTime, *DTimeTZ:
 			s = AsStringWithFlags(d, FmtBareStrings)
 		case *DInterval:
 			// When converting an interval to string, we need a string representation
@@ -2447,6 +2614,8 @@ func performCast(ctx *EvalContext, d Datum, t CastTargetType) (Datum, error) {
 			s = buf.String()
 		case *DOid:
 			s = t.name
+		case *DEnum:
+			s = t.LogicalRep
 		}
 		switch c := t.(type) {
 		case *StringColType:
@@ -2517,6 +2686,41 @@ func performCast(ctx *EvalContext, d Datum, t CastTargetType) (Datum, error) {
 			return NewDDateFromTime(d.Time, time.UTC), nil
 		}
 
+	case *TimeColType:
+		switch d := d.(type) {
+		case *DString:
+			return ParseDTime(string(*d))
+		case *DCollatedString:
+			return ParseDTime(d.Contents)
+		case *DTime:
+			return d, nil
+		case *DTimeTZ:
+			return MakeDTime(d.TimeOfDay), nil
+		case *DTimestamp:
+			return MakeDTime(timeofday.FromTime(d.Time)), nil
+		case *DTimestampTZ:
+			return MakeDTime(timeofday.FromTime(d.Time.In(ctx.GetLocation()))), nil
+		case *DInterval:
+			return MakeDTime(timeofday.Midnight.Add(d.Duration)), nil
+		}
+
+	case *TimeTZColType:
+		switch d := d.(type) {
+		case *DString:
+			return ParseDTimeTZ(string(*d), ctx.GetLocation())
+		case *DCollatedString:
+			return ParseDTimeTZ(d.Contents, ctx.GetLocation())
+		case *DTime:
+			// A time without time zone is interpreted in the session time zone,
+			// using the UTC offset currently in effect there.
+			_, offsetSecs := timeutil.Now().In(ctx.GetLocation()).Zone()
+			return MakeDTimeTZ(timeofday.TimeOfDay(*d), int32(offsetSecs)), nil
+		case *DTimeTZ:
+			return d, nil
+		case *DTimestampTZ:
+			return MakeDTimeTZFromTime(d.Time.In(ctx.GetLocation())), nil
+		}
+
 	case *TimestampColType:
 		// TODO(knz): Timestamp from float, decimal.
 		switch d := d.(type) {
@@ -2564,6 +2768,8 @@ func performCast(ctx *EvalContext, d Datum, t CastTargetType) (Datum, error) {
 		case *DInt:
 			// An integer duration represents a duration in microseconds.
 			return &DInterval{Duration: duration.Duration{Nanos: int64(*v) * 1000}}, nil
+		case *DTime:
+			return &DInterval{Duration: duration.Duration{Nanos: int64(*v) * 1000}}, nil
 		case *DInterval:
 			return d, nil
 		}
@@ -2635,8 +2841,9 @@ func performCast(ctx *EvalContext, d Datum, t CastTargetType) (Datum, error) {
 				}
 				return queryOid(ctx, typ, NewDString(funcDef.Name))
 			case oidColTypeRegType:
+				// User-defined types are only known to pg_type.
 				colType, err := ParseType(s)
-				if err == nil {
+				if _, isEnum := colType.(*EnumColType); err == nil && !isEnum {
 					datumType := CastTargetToDatumType(colType)
 					return &DOid{semanticType: typ, DInt: DInt(datumType.Oid()), name: datumType.SQLName()}, nil
 				}
@@ -2674,6 +2881,18 @@ func performCast(ctx *EvalContext, d Datum, t CastTargetType) (Datum, error) {
 				return queryOid(ctx, typ, NewDString(s))
 			}
 		}
+
+	case *EnumColType:
+		switch v := d.(type) {
+		case *DString:
+			return MakeDEnumFromLogicalRep(typ.Typ, string(*v))
+		case *DCollatedString:
+			return MakeDEnumFromLogicalRep(typ.Typ, v.Contents)
+		case *DEnum:
+			if v.EnumTyp.Equivalent(typ.Typ) {
+				return v, nil
+			}
+		}
 	}
 
 	return nil, pgerror.NewErrorf(
@@ -3108,6 +3327,21 @@ func (t *DCollatedString) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DTime) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (t *DTimeTZ) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (t *DEnum) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t *DTimestamp) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
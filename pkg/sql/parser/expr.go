@@ -1110,15 +1110,19 @@ var (
 	decimalCastTypes = []Type{TypeNull, TypeBool, TypeInt, TypeFloat, TypeDecimal, TypeString, TypeCollatedString,
 		TypeTimestamp, TypeTimestampTZ, TypeDate, TypeInterval}
 	stringCastTypes = []Type{TypeNull, TypeBool, TypeInt, TypeFloat, TypeDecimal, TypeString, TypeCollatedString,
-		TypeBytes, TypeTimestamp, TypeTimestampTZ, TypeInterval, TypeUUID, TypeDate, TypeOid, TypeINet}
+		TypeBytes, TypeTimestamp, TypeTimestampTZ, TypeInterval, TypeUUID, TypeDate, TypeTime, TypeTimeTZ, TypeOid, TypeINet,
+		TypeAnyEnum}
 	bytesCastTypes     = []Type{TypeNull, TypeString, TypeCollatedString, TypeBytes, TypeUUID}
 	dateCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeDate, TypeTimestamp, TypeTimestampTZ, TypeInt}
 	timestampCastTypes = []Type{TypeNull, TypeString, TypeCollatedString, TypeDate, TypeTimestamp, TypeTimestampTZ, TypeInt}
-	intervalCastTypes  = []Type{TypeNull, TypeString, TypeCollatedString, TypeInt, TypeInterval}
+	timeCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeTime, TypeTimeTZ, TypeTimestamp, TypeTimestampTZ, TypeInterval}
+	timeTZCastTypes    = []Type{TypeNull, TypeString, TypeCollatedString, TypeTime, TypeTimeTZ, TypeTimestampTZ}
+	intervalCastTypes  = []Type{TypeNull, TypeString, TypeCollatedString, TypeInt, TypeTime, TypeInterval}
 	oidCastTypes       = []Type{TypeNull, TypeString, TypeCollatedString, TypeInt, TypeOid}
 	uuidCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeBytes, TypeUUID}
 	inetCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeINet}
 	arrayCastTypes     = []Type{TypeNull, TypeString}
+	enumCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeAnyEnum}
 )
 
 // validCastTypes returns a set of types that can be cast into the provided type.
@@ -1138,6 +1142,10 @@ func validCastTypes(t Type) []Type {
 		return bytesCastTypes
 	case TypeDate:
 		return dateCastTypes
+	case TypeTime:
+		return timeCastTypes
+	case TypeTimeTZ:
+		return timeTZCastTypes
 	case TypeTimestamp, TypeTimestampTZ:
 		return timestampCastTypes
 	case TypeInterval:
@@ -1155,6 +1163,8 @@ func validCastTypes(t Type) []Type {
 			return stringCastTypes
 		} else if t.FamilyEqual(TypeArray) {
 			return arrayCastTypes
+		} else if t.FamilyEqual(TypeAnyEnum) {
+			return enumCastTypes
 		}
 		return nil
 	}
@@ -1263,6 +1273,9 @@ func (node *DUuid) String() string            { return AsString(node) }
 func (node *DIPAddr) String() string          { return AsString(node) }
 func (node *DString) String() string          { return AsString(node) }
 func (node *DCollatedString) String() string  { return AsString(node) }
+func (node *DTime) String() string            { return AsString(node) }
+func (node *DTimeTZ) String() string          { return AsString(node) }
+func (node *DEnum) String() string            { return AsString(node) }
 func (node *DTimestamp) String() string       { return AsString(node) }
 func (node *DTimestampTZ) String() string     { return AsString(node) }
 func (node *DTuple) String() string           { return AsString(node) }
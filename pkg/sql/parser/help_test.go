@@ -77,6 +77,13 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER VIEW blah RENAME ??`, `ALTER VIEW`},
 		{`ALTER VIEW blah RENAME TO blih ??`, `ALTER VIEW`},
 
+		{`ALTER TYPE ??`, `ALTER TYPE`},
+		{`ALTER TYPE blah ADD VALUE 'x' ??`, `ALTER TYPE`},
+
+		{`ALTER USER ??`, `ALTER USER`},
+		{`ALTER USER blah ??`, `ALTER USER`},
+		{`ALTER USER blah ACCOUNT ??`, `ALTER USER`},
+
 		{`CANCEL ??`, `CANCEL`},
 		{`CANCEL JOB ??`, `CANCEL JOB`},
 		{`CANCEL QUERY ??`, `CANCEL QUERY`},
@@ -93,13 +100,20 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE DATABASE IF NOT ??`, `CREATE DATABASE`},
 		{`CREATE DATABASE blih ??`, `CREATE DATABASE`},
 
+		{`CREATE ROLE blih ??`, `CREATE ROLE`},
+
 		{`CREATE USER blih ??`, `CREATE USER`},
 		{`CREATE USER blih WITH ??`, `CREATE USER`},
+		{`CREATE USER blih VALID UNTIL ??`, `CREATE USER`},
+
+		{`CREATE TYPE ??`, `CREATE TYPE`},
+		{`CREATE TYPE blah AS ENUM (??`, `CREATE TYPE`},
 
 		{`CREATE VIEW blah (??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS (SELECT c FROM x) ??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS SELECT c FROM x ??`, `SELECT`},
 		{`CREATE VIEW blah AS (??`, `<SELECTCLAUSE>`},
+		{`CREATE MATERIALIZED VIEW blah (??`, `CREATE VIEW`},
 
 		{`CREATE TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ??`, `CREATE TABLE`},
@@ -133,9 +147,15 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP VIEW IF ??`, `DROP VIEW`},
 		{`DROP VIEW IF EXISTS blih, bloh ??`, `DROP VIEW`},
 
+		{`DROP ROLE IF ??`, `DROP ROLE`},
+		{`DROP ROLE IF EXISTS bloh ??`, `DROP ROLE`},
+
 		{`DROP USER IF ??`, `DROP USER`},
 		{`DROP USER IF EXISTS bloh ??`, `DROP USER`},
 
+		{`DROP TYPE ??`, `DROP TYPE`},
+		{`DROP TYPE IF EXISTS blah ??`, `DROP TYPE`},
+
 		{`EXPLAIN (??`, `EXPLAIN`},
 		{`EXPLAIN SELECT 1 ??`, `SELECT`},
 		{`EXPLAIN INSERT INTO xx (SELECT 1) ??`, `INSERT`},
@@ -183,14 +203,21 @@ func TestContextualHelp(t *testing.T) {
 		{`GRANT ALL ??`, `GRANT`},
 		{`GRANT ALL ON foo TO ??`, `GRANT`},
 		{`GRANT ALL ON foo TO bar ??`, `GRANT`},
+		{`GRANT foo TO ??`, `GRANT`},
+		{`GRANT foo TO bar WITH ??`, `GRANT`},
 
 		{`PAUSE ??`, `PAUSE JOB`},
 
+		{`REFRESH ??`, `REFRESH MATERIALIZED VIEW`},
+		{`REFRESH MATERIALIZED VIEW ??`, `REFRESH MATERIALIZED VIEW`},
+
 		{`RESUME ??`, `RESUME JOB`},
 
 		{`REVOKE ALL ??`, `REVOKE`},
 		{`REVOKE ALL ON foo FROM ??`, `REVOKE`},
 		{`REVOKE ALL ON foo FROM bar ??`, `REVOKE`},
+		{`REVOKE foo FROM ??`, `REVOKE`},
+		{`REVOKE ADMIN OPTION FOR foo FROM ??`, `REVOKE`},
 
 		{`SELECT * FROM ??`, `<SOURCE>`},
 		{`SELECT * FROM (??`, `<SOURCE>`}, // not <selectclause>! joins are allowed.
@@ -239,6 +266,7 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW GRANTS ON ??`, `SHOW GRANTS`},
 		{`SHOW GRANTS ON foo FOR ??`, `SHOW GRANTS`},
 		{`SHOW GRANTS ON foo FOR bar ??`, `SHOW GRANTS`},
+		{`SHOW GRANTS ON ROLE foo FOR ??`, `SHOW GRANTS`},
 
 		{`SHOW KEYS ??`, `SHOW INDEXES`},
 		{`SHOW INDEX ??`, `SHOW INDEXES`},
@@ -253,6 +281,8 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW TRANSACTION ISOLATION ??`, `SHOW TRANSACTION`},
 		{`SHOW TRANSACTION ISOLATION LEVEL ??`, `SHOW TRANSACTION`},
 
+		{`SHOW ROLES ??`, `SHOW ROLES`},
+
 		{`SHOW USERS ??`, `SHOW USERS`},
 
 		{`TRUNCATE foo ??`, `TRUNCATE`},
@@ -9,6 +9,8 @@ var expectedHelpStrings = []string{
 	"ALTER DATABASE",
 	"ALTER INDEX",
 	"ALTER TABLE",
+	"ALTER TYPE",
+	"ALTER USER",
 	"ALTER VIEW",
 	"ALTER",
 	"BACKUP",
@@ -19,7 +21,10 @@ var expectedHelpStrings = []string{
 	"COMMIT",
 	"CREATE DATABASE",
 	"CREATE INDEX",
+	"CREATE POLICY",
+	"CREATE ROLE",
 	"CREATE TABLE",
+	"CREATE TYPE",
 	"CREATE USER",
 	"CREATE VIEW",
 	"CREATE",
@@ -28,7 +33,10 @@ var expectedHelpStrings = []string{
 	"DISCARD",
 	"DROP DATABASE",
 	"DROP INDEX",
+	"DROP POLICY",
+	"DROP ROLE",
 	"DROP TABLE",
+	"DROP TYPE",
 	"DROP USER",
 	"DROP VIEW",
 	"DROP",
@@ -39,6 +47,7 @@ var expectedHelpStrings = []string{
 	"INSERT",
 	"PAUSE JOB",
 	"PREPARE",
+	"REFRESH MATERIALIZED VIEW",
 	"RELEASE",
 	"RESET CLUSTER SETTING",
 	"RESET",
@@ -62,6 +71,7 @@ var expectedHelpStrings = []string{
 	"SHOW INDEXES",
 	"SHOW JOBS",
 	"SHOW QUERIES",
+	"SHOW ROLES",
 	"SHOW SESSION",
 	"SHOW SESSIONS",
 	"SHOW TABLES",
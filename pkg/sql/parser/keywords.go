@@ -7,8 +7,11 @@ var keywords = map[string]struct {
 	tok int
 	cat string
 }{
+	"account":                   {ACCOUNT, "U"},
 	"action":                    {ACTION, "U"},
 	"add":                       {ADD, "U"},
+	"admin":                     {ADMIN, "U"},
+	"after":                     {AFTER, "U"},
 	"all":                       {ALL, "R"},
 	"alter":                     {ALTER, "U"},
 	"analyse":                   {ANALYSE, "R"},
@@ -22,6 +25,7 @@ var keywords = map[string]struct {
 	"asymmetric":                {ASYMMETRIC, "R"},
 	"at":                        {AT, "U"},
 	"backup":                    {BACKUP, "U"},
+	"before":                    {BEFORE, "U"},
 	"begin":                     {BEGIN, "U"},
 	"between":                   {BETWEEN, "C"},
 	"bigint":                    {BIGINT, "C"},
@@ -50,6 +54,7 @@ var keywords = map[string]struct {
 	"columns":                   {COLUMNS, "U"},
 	"commit":                    {COMMIT, "U"},
 	"committed":                 {COMMITTED, "U"},
+	"concurrently":              {CONCURRENTLY, "T"},
 	"conflict":                  {CONFLICT, "U"},
 	"constraint":                {CONSTRAINT, "R"},
 	"constraints":               {CONSTRAINTS, "U"},
@@ -80,17 +85,21 @@ var keywords = map[string]struct {
 	"deferrable":                {DEFERRABLE, "R"},
 	"delete":                    {DELETE, "U"},
 	"desc":                      {DESC, "R"},
+	"disable":                   {DISABLE, "U"},
 	"discard":                   {DISCARD, "U"},
 	"distinct":                  {DISTINCT, "R"},
 	"do":                        {DO, "R"},
 	"double":                    {DOUBLE, "U"},
 	"drop":                      {DROP, "U"},
 	"else":                      {ELSE, "R"},
+	"enable":                    {ENABLE, "U"},
 	"encoding":                  {ENCODING, "U"},
 	"end":                       {END, "R"},
+	"enum":                      {ENUM, "U"},
 	"except":                    {EXCEPT, "R"},
 	"execute":                   {EXECUTE, "U"},
 	"exists":                    {EXISTS, "C"},
+	"experimental_audit":        {EXPERIMENTAL_AUDIT, "U"},
 	"experimental_fingerprints": {EXPERIMENTAL_FINGERPRINTS, "U"},
 	"explain":                   {EXPLAIN, "U"},
 	"extract":                   {EXTRACT, "C"},
@@ -157,11 +166,13 @@ var keywords = map[string]struct {
 	"level":                     {LEVEL, "U"},
 	"like":                      {LIKE, "T"},
 	"limit":                     {LIMIT, "R"},
+	"list":                      {LIST, "U"},
 	"local":                     {LOCAL, "U"},
 	"localtime":                 {LOCALTIME, "R"},
 	"localtimestamp":            {LOCALTIMESTAMP, "R"},
 	"low":                       {LOW, "U"},
 	"match":                     {MATCH, "U"},
+	"materialized":              {MATERIALIZED, "U"},
 	"minute":                    {MINUTE, "U"},
 	"month":                     {MONTH, "U"},
 	"name":                      {NAME, "C"},
@@ -184,6 +195,7 @@ var keywords = map[string]struct {
 	"oid":                       {OID, "U"},
 	"on":                        {ON, "R"},
 	"only":                      {ONLY, "R"},
+	"option":                    {OPTION, "U"},
 	"options":                   {OPTIONS, "U"},
 	"or":                        {OR, "R"},
 	"order":                     {ORDER, "R"},
@@ -200,6 +212,7 @@ var keywords = map[string]struct {
 	"pause":                     {PAUSE, "U"},
 	"placing":                   {PLACING, "R"},
 	"plans":                     {PLANS, "U"},
+	"policy":                    {POLICY, "U"},
 	"position":                  {POSITION, "C"},
 	"preceding":                 {PRECEDING, "U"},
 	"precision":                 {PRECISION, "C"},
@@ -214,6 +227,7 @@ var keywords = map[string]struct {
 	"recursive":                 {RECURSIVE, "U"},
 	"ref":                       {REF, "U"},
 	"references":                {REFERENCES, "R"},
+	"refresh":                   {REFRESH, "U"},
 	"regclass":                  {REGCLASS, "U"},
 	"regnamespace":              {REGNAMESPACE, "U"},
 	"regproc":                   {REGPROC, "U"},
@@ -229,6 +243,8 @@ var keywords = map[string]struct {
 	"returning":                 {RETURNING, "R"},
 	"revoke":                    {REVOKE, "U"},
 	"right":                     {RIGHT, "T"},
+	"role":                      {ROLE, "U"},
+	"roles":                     {ROLES, "U"},
 	"rollback":                  {ROLLBACK, "U"},
 	"rollup":                    {ROLLUP, "U"},
 	"row":                       {ROW, "C"},
@@ -237,6 +253,7 @@ var keywords = map[string]struct {
 	"scatter":                   {SCATTER, "U"},
 	"search":                    {SEARCH, "U"},
 	"second":                    {SECOND, "U"},
+	"security":                  {SECURITY, "U"},
 	"select":                    {SELECT, "R"},
 	"sequences":                 {SEQUENCES, "U"},
 	"serial":                    {SERIAL, "C"},
@@ -278,6 +295,7 @@ var keywords = map[string]struct {
 	"time":                      {TIME, "C"},
 	"timestamp":                 {TIMESTAMP, "C"},
 	"timestamptz":               {TIMESTAMPTZ, "C"},
+	"timetz":                    {TIMETZ, "C"},
 	"to":                        {TO, "R"},
 	"trace":                     {TRACE, "U"},
 	"trailing":                  {TRAILING, "R"},
@@ -292,6 +310,8 @@ var keywords = map[string]struct {
 	"union":                     {UNION, "R"},
 	"unique":                    {UNIQUE, "R"},
 	"unknown":                   {UNKNOWN, "U"},
+	"unlock":                    {UNLOCK, "U"},
+	"until":                     {UNTIL, "U"},
 	"update":                    {UPDATE, "U"},
 	"upsert":                    {UPSERT, "U"},
 	"use":                       {USE, "U"},
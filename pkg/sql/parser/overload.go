@@ -618,6 +618,22 @@ func defaultTypeCheck(
 // This modifies values within s as scratch slices, but only in the case where
 // it returns true, which signals to the calling function that it should
 // immediately return, so any mutations to s are irrelevant.
+// concreteType returns the type that a constant or placeholder argument
+// should take on when its parameter has type typ. Neither can become a member
+// of an unknown ENUM type, so the wildcard ENUM type is replaced by the type
+// of a resolved ENUM argument, if there is one.
+func (s *typeCheckOverloadState) concreteType(typ Type) Type {
+	if typ != TypeAnyEnum {
+		return typ
+	}
+	for _, i := range s.resolvableIdxs {
+		if t := s.typedExprs[i].ResolvedType(); t.FamilyEqual(TypeAnyEnum) {
+			return t
+		}
+	}
+	return typ
+}
+
 func checkReturn(
 	ctx *SemaContext, s typeCheckOverloadState,
 ) ([]TypedExpr, []overloadImpl, bool, error) {
@@ -633,7 +649,7 @@ func checkReturn(
 		o := s.overloads[idx]
 		p := o.params()
 		for _, i := range s.constIdxs {
-			des := p.getAt(i)
+			des := s.concreteType(p.getAt(i))
 			typ, err := s.exprs[i].TypeCheck(ctx, des)
 			if err != nil {
 				return s.typedExprs, nil, true, errors.Wrap(err, "error type checking constant value")
@@ -645,7 +661,7 @@ func checkReturn(
 		}
 
 		for _, i := range s.placeholderIdxs {
-			des := p.getAt(i)
+			des := s.concreteType(p.getAt(i))
 			typ, err := s.exprs[i].TypeCheck(ctx, des)
 			if err != nil {
 				return s.typedExprs, nil, true, err
@@ -230,6 +230,10 @@ func ParseStringAs(t Type, s string, evalCtx *EvalContext) (Datum, error) {
 		d, err = ParseDInterval(s)
 	case TypeString:
 		d = NewDString(s)
+	case TypeTime:
+		d, err = ParseDTime(s)
+	case TypeTimeTZ:
+		d, err = ParseDTimeTZ(s, evalCtx.GetLocation())
 	case TypeTimestamp:
 		d, err = ParseDTimestamp(s, time.Microsecond)
 	case TypeTimestampTZ:
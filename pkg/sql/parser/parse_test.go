@@ -86,6 +86,8 @@ func TestParse(t *testing.T) {
 		{`CREATE UNIQUE INDEX a ON b.c (d)`},
 
 		{`CREATE TABLE a ()`},
+		{`CREATE TEMPORARY TABLE a (b INT)`},
+		{`CREATE TEMPORARY TABLE IF NOT EXISTS a AS SELECT * FROM b`},
 		{`CREATE TABLE a (b INT)`},
 		{`CREATE TABLE a (b INT, c INT)`},
 		{`CREATE TABLE a (b CHAR)`},
@@ -136,6 +138,10 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT, INDEX (b) STORING (c))`},
 		{`CREATE TABLE a (b INT, c TEXT, INDEX (b ASC, c DESC) STORING (c))`},
 		{`CREATE TABLE a (b INT, INDEX (b) INTERLEAVE IN PARENT c (d, e))`},
+		{`CREATE TABLE a (b INT PRIMARY KEY) PARTITION BY LIST (b) (PARTITION p1 VALUES IN (1, 2), PARTITION p2 VALUES IN (3))`},
+		{`CREATE TABLE a (b INT, c STRING, PRIMARY KEY (b, c)) PARTITION BY LIST (b, c) (PARTITION p1 VALUES IN ((1, 'a'), (2, 'b')))`},
+		{`CREATE TABLE a (b INT PRIMARY KEY) PARTITION BY RANGE (b) (PARTITION p1 VALUES FROM (1) TO (2), PARTITION p2 VALUES FROM (2) TO (10))`},
+		{`CREATE TABLE a (b INT PRIMARY KEY) INTERLEAVE IN PARENT c (b) PARTITION BY LIST (b) (PARTITION p1 VALUES IN (1))`},
 		{`CREATE TABLE a (b INT, FAMILY (b))`},
 		{`CREATE TABLE a (b INT, c STRING, FAMILY foo (b), FAMILY (c))`},
 		{`CREATE TABLE a (b INT) INTERLEAVE IN PARENT foo (c, d)`},
@@ -167,6 +173,21 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
+		{`CREATE MATERIALIZED VIEW a AS SELECT * FROM b`},
+		{`CREATE MATERIALIZED VIEW a (x, y) AS SELECT c, d FROM b`},
+		{`REFRESH MATERIALIZED VIEW a`},
+		{`REFRESH MATERIALIZED VIEW CONCURRENTLY db.a`},
+
+		{`CREATE TYPE a AS ENUM ()`},
+		{`CREATE TYPE a AS ENUM ('b')`},
+		{`CREATE TYPE a AS ENUM ('b', 'c d')`},
+
+		{`CREATE POLICY p ON a USING (b = 1)`},
+		{`CREATE POLICY p ON db.a TO u, v USING (b = current_setting('app.b')::INT)`},
+		{`CREATE POLICY p ON a WITH CHECK (b > 0)`},
+		{`CREATE POLICY p ON a USING (b = 1) WITH CHECK (b = 1 AND c IS NOT NULL)`},
+		{`CREATE TABLE a (b mood)`},
+		{`CREATE TABLE a (b "My Type" DEFAULT 'x')`},
 
 		{`DELETE FROM a`},
 		{`DELETE FROM a.b`},
@@ -178,6 +199,7 @@ func TestParse(t *testing.T) {
 		{`DELETE FROM a WHERE a = b RETURNING NOTHING`},
 
 		{`DISCARD ALL`},
+		{`DISCARD TEMP`},
 
 		{`DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
@@ -215,6 +237,22 @@ func TestParse(t *testing.T) {
 		{`DROP USER a`},
 		{`DROP USER a, b`},
 
+		{`CREATE USER a VALID UNTIL '2030-01-01'`},
+		{`ALTER USER a VALID UNTIL 'infinity'`},
+		{`ALTER USER IF EXISTS a VALID UNTIL '2030-01-01 12:00:00'`},
+		{`ALTER USER a ACCOUNT UNLOCK`},
+		{`ALTER USER IF EXISTS a ACCOUNT UNLOCK`},
+
+		{`CREATE ROLE a`},
+		{`DROP ROLE a`},
+		{`DROP ROLE IF EXISTS a, b`},
+
+		{`DROP TYPE a`},
+		{`DROP TYPE a, b`},
+		{`DROP TYPE IF EXISTS a, b`},
+		{`DROP POLICY p ON a`},
+		{`DROP POLICY IF EXISTS p ON db.a`},
+
 		{`CANCEL JOB a`},
 		{`CANCEL QUERY a`},
 		{`RESUME JOB a`},
@@ -244,6 +282,7 @@ func TestParse(t *testing.T) {
 		{`SHOW CONSTRAINTS FROM a.b.c`},
 		{`SHOW TABLES FROM a; SHOW COLUMNS FROM b`},
 		{`SHOW USERS`},
+		{`SHOW ROLES`},
 		{`SHOW JOBS`},
 		{`SHOW CLUSTER QUERIES`},
 		{`SHOW LOCAL QUERIES`},
@@ -270,6 +309,8 @@ func TestParse(t *testing.T) {
 		{`SHOW GRANTS ON DATABASE foo, bar`},
 		{`SHOW GRANTS ON DATABASE foo FOR bar`},
 		{`SHOW GRANTS FOR bar, baz`},
+		{`SHOW GRANTS ON ROLE foo`},
+		{`SHOW GRANTS ON ROLE foo, bar FOR baz`},
 
 		{`SHOW TRANSACTION ISOLATION LEVEL`},
 		{`SHOW TRANSACTION PRIORITY`},
@@ -317,6 +358,12 @@ func TestParse(t *testing.T) {
 		{`GRANT SELECT, INSERT ON DATABASE bar TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO "test-user"`},
+		{`GRANT SELECT (a, b) ON foo TO bar`},
+		{`GRANT SELECT, UPDATE (a) ON foo, db.foo TO bar, baz`},
+		{`GRANT ALL (a) ON foo TO bar`},
+
+		{`GRANT foo TO bar`},
+		{`GRANT foo, bar TO baz, qux WITH ADMIN OPTION`},
 
 		// Tables are the default, but can also be specified with
 		// REVOKE x ON TABLE y. However, the stringer does not output TABLE.
@@ -326,6 +373,11 @@ func TestParse(t *testing.T) {
 		{`REVOKE ALL ON DATABASE foo FROM root, test`},
 		{`REVOKE SELECT, INSERT ON DATABASE bar FROM foo, bar, baz`},
 		{`REVOKE SELECT, INSERT ON DATABASE db1, db2 FROM foo, bar, baz`},
+		{`REVOKE SELECT (a, b) ON foo FROM bar`},
+		{`REVOKE ALL (a) ON foo FROM bar`},
+
+		{`REVOKE foo FROM bar`},
+		{`REVOKE ADMIN OPTION FOR foo, bar FROM baz`},
 
 		{`INSERT INTO a VALUES (1)`},
 		{`INSERT INTO a.b VALUES (1)`},
@@ -442,12 +494,16 @@ func TestParse(t *testing.T) {
 		{`SELECT REAL 'foo'`},
 		{`SELECT DECIMAL 'foo'`},
 		{`SELECT DATE 'foo'`},
+		{`SELECT TIME 'foo'`},
+		{`SELECT TIME WITH TIME ZONE 'foo'`},
 		{`SELECT TIMESTAMP 'foo'`},
 		{`SELECT TIMESTAMP WITH TIME ZONE 'foo'`},
 		{`SELECT CHAR 'foo'`},
 
 		{`SELECT '192.168.0.1':::INET`},
 		{`SELECT '192.168.0.1'::INET`},
+		{`SELECT 'happy'::mood`},
+		{`SELECT 'happy':::mood`},
 
 		{`SELECT 'a' AS "12345"`},
 		{`SELECT 'a' AS clnm`},
@@ -605,6 +661,8 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t LIMIT a OFFSET b`},
 		{`SELECT DISTINCT * FROM t`},
 		{`SELECT DISTINCT a, b FROM t`},
+		{`SELECT DISTINCT ON (a, b) c FROM t`},
+		{`SELECT DISTINCT ON (a) a, b FROM t ORDER BY a, b DESC`},
 		{`SET a = 3`},
 		{`SET a = 3, 4`},
 		{`SET a = '3'`},
@@ -663,6 +721,10 @@ func TestParse(t *testing.T) {
 		{`SELECT * FROM "0" JOIN "0" USING (id, "0")`}, // last "0" lost its quotes.
 
 		{`ALTER DATABASE a RENAME TO b`},
+		{`ALTER TYPE a ADD VALUE 'b'`},
+		{`ALTER TYPE a ADD VALUE IF NOT EXISTS 'b'`},
+		{`ALTER TYPE a ADD VALUE 'b' BEFORE 'c'`},
+		{`ALTER TYPE a ADD VALUE IF NOT EXISTS 'b' AFTER 'c'`},
 		{`ALTER TABLE a RENAME TO b`},
 		{`ALTER TABLE IF EXISTS a RENAME TO b`},
 		{`ALTER INDEX a@b RENAME TO b`},
@@ -697,6 +759,10 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a DROP CONSTRAINT b CASCADE`},
 		{`ALTER TABLE a DROP CONSTRAINT IF EXISTS b RESTRICT`},
 		{`ALTER TABLE a VALIDATE CONSTRAINT a`},
+		{`ALTER TABLE a EXPERIMENTAL_AUDIT SET READ WRITE`},
+		{`ALTER TABLE a EXPERIMENTAL_AUDIT SET OFF`},
+		{`ALTER TABLE a ENABLE ROW LEVEL SECURITY`},
+		{`ALTER TABLE a DISABLE ROW LEVEL SECURITY`},
 
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT 42`},
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT NULL`},
@@ -782,9 +848,16 @@ func TestParse2(t *testing.T) {
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b) INTERLEAVE IN PARENT c (d))`,
 			`CREATE TABLE a (b INT, CONSTRAINT foo UNIQUE (b) INTERLEAVE IN PARENT c (d))`},
 		{`CREATE INDEX ON a (b) COVERING (c)`, `CREATE INDEX ON a (b) STORING (c)`},
+		{`CREATE TEMP TABLE a (b INT)`, `CREATE TEMPORARY TABLE a (b INT)`},
+		{`CREATE LOCAL TEMPORARY TABLE a (b INT)`, `CREATE TEMPORARY TABLE a (b INT)`},
+		{`DISCARD TEMPORARY`, `DISCARD TEMP`},
 
 		{`SELECT TIMESTAMP WITHOUT TIME ZONE 'foo'`, `SELECT TIMESTAMP 'foo'`},
 		{`SELECT CAST('foo' AS TIMESTAMP WITHOUT TIME ZONE)`, `SELECT CAST('foo' AS TIMESTAMP)`},
+		{`SELECT TIME WITHOUT TIME ZONE 'foo'`, `SELECT TIME 'foo'`},
+		{`SELECT TIMETZ 'foo'`, `SELECT TIME WITH TIME ZONE 'foo'`},
+		{`SELECT CAST('foo' AS TIMETZ)`, `SELECT CAST('foo' AS TIME WITH TIME ZONE)`},
+		{`SELECT a AT TIME ZONE 'UTC'`, `SELECT timezone('UTC', a)`},
 		{`SELECT CAST(1 AS "char")`, `SELECT CAST(1 AS CHAR)`},
 
 		{`SELECT 'a' FROM t@{FORCE_INDEX=bar}`, `SELECT 'a' FROM t@bar`},
@@ -868,6 +941,10 @@ func TestParse2(t *testing.T) {
 			`SELECT current_timestamp()`},
 		{`SELECT CURRENT_DATE`,
 			`SELECT current_date()`},
+		{`SELECT CURRENT_TIME`,
+			`SELECT current_time()`},
+		{`SELECT LOCALTIME`,
+			`SELECT localtime()`},
 		{`SELECT POSITION(a IN b)`,
 			`SELECT strpos(b, a)`},
 		{`SELECT TRIM(BOTH a FROM b)`,
@@ -882,6 +959,8 @@ func TestParse2(t *testing.T) {
 			`SELECT current_user()`},
 		{`SELECT SESSION_USER`,
 			`SELECT current_user()`},
+		{`SELECT CURRENT_ROLE`,
+			`SELECT current_user()`},
 		{`SELECT USER`,
 			`SELECT current_user()`},
 		// Offset has an optional ROW/ROWS keyword.
@@ -38,6 +38,8 @@ var typeBuiltinsHaveUnderscore = map[oid.Oid]struct{}{
 	TypeDecimal.Oid():     {},
 	TypeInterval.Oid():    {},
 	TypeUUID.Oid():        {},
+	TypeTime.Oid():        {},
+	TypeTimeTZ.Oid():      {},
 	TypeTimestamp.Oid():   {},
 	TypeTimestampTZ.Oid(): {},
 	TypeTuple.Oid():       {},
@@ -33,6 +33,7 @@ var reservedKeywords = map[string]struct{}{
 	"collate":           {},
 	"collation":         {},
 	"column":            {},
+	"concurrently":      {},
 	"constraint":        {},
 	"create":            {},
 	"cross":             {},
@@ -139,6 +140,7 @@ var reservedKeywords = map[string]struct{}{
 	"time":              {},
 	"timestamp":         {},
 	"timestamptz":       {},
+	"timetz":            {},
 	"to":                {},
 	"trailing":          {},
 	"treat":             {},
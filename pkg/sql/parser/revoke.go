@@ -30,16 +30,42 @@ import (
 // PrivilegeList and TargetList are defined in grant.go
 type Revoke struct {
 	Privileges privilege.List
-	Targets    TargetList
-	Grantees   NameList
+	// Columns, if set, restricts the privileges to these columns of the
+	// target tables.
+	Columns  NameList
+	Targets  TargetList
+	Grantees NameList
 }
 
 // Format implements the NodeFormatter interface.
 func (node *Revoke) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("REVOKE ")
 	node.Privileges.Format(buf)
+	if node.Columns != nil {
+		buf.WriteString(" (")
+		FormatNode(buf, f, node.Columns)
+		buf.WriteByte(')')
+	}
 	buf.WriteString(" ON ")
 	FormatNode(buf, f, node.Targets)
 	buf.WriteString(" FROM ")
 	FormatNode(buf, f, node.Grantees)
 }
+
+// RevokeRole represents a REVOKE <role> statement.
+type RevokeRole struct {
+	Roles       NameList
+	Members     NameList
+	AdminOption bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RevokeRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("REVOKE ")
+	if node.AdminOption {
+		buf.WriteString("ADMIN OPTION FOR ")
+	}
+	FormatNode(buf, f, node.Roles)
+	buf.WriteString(" FROM ")
+	FormatNode(buf, f, node.Members)
+}
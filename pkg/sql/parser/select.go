@@ -65,6 +65,7 @@ func (node *ParenSelect) Format(buf *bytes.Buffer, f FmtFlags) {
 // SelectClause represents a SELECT statement.
 type SelectClause struct {
 	Distinct    bool
+	DistinctOn  DistinctOn
 	Exprs       SelectExprs
 	From        *From
 	Where       *Where
@@ -81,7 +82,10 @@ func (node *SelectClause) Format(buf *bytes.Buffer, f FmtFlags) {
 		FormatNode(buf, f, node.From.Tables[0])
 	} else {
 		buf.WriteString("SELECT ")
-		if node.Distinct {
+		if len(node.DistinctOn) > 0 {
+			FormatNode(buf, f, node.DistinctOn)
+			buf.WriteByte(' ')
+		} else if node.Distinct {
 			buf.WriteString("DISTINCT ")
 		}
 		FormatNode(buf, f, node.Exprs)
@@ -93,6 +97,16 @@ func (node *SelectClause) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// DistinctOn represents a DISTINCT ON clause.
+type DistinctOn []Expr
+
+// Format implements the NodeFormatter interface.
+func (node DistinctOn) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DISTINCT ON (")
+	FormatNode(buf, f, Exprs(node))
+	buf.WriteByte(')')
+}
+
 // SelectExprs represents SELECT expressions.
 type SelectExprs []SelectExpr
 
@@ -196,6 +196,22 @@ func (node *ShowGrants) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// ShowRoleGrants represents a SHOW GRANTS ON ROLE statement.
+type ShowRoleGrants struct {
+	Roles    NameList
+	Grantees NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowRoleGrants) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW GRANTS ON ROLE ")
+	FormatNode(buf, f, node.Roles)
+	if node.Grantees != nil {
+		buf.WriteString(" FOR ")
+		FormatNode(buf, f, node.Grantees)
+	}
+}
+
 // ShowCreateTable represents a SHOW CREATE TABLE statement.
 type ShowCreateTable struct {
 	Table NormalizableTableName
@@ -236,6 +252,15 @@ func (node *ShowUsers) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("SHOW USERS")
 }
 
+// ShowRoles represents a SHOW ROLES statement.
+type ShowRoles struct {
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowRoles) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW ROLES")
+}
+
 // ShowRanges represents a SHOW TESTING_RANGES statement.
 // Only one of Table and Index can be set.
 type ShowRanges struct {
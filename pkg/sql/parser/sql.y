@@ -265,9 +265,6 @@ func (u *sqlSymUnion) targetList() TargetList {
 func (u *sqlSymUnion) targetListPtr() *TargetList {
     return u.val.(*TargetList)
 }
-func (u *sqlSymUnion) privilegeType() privilege.Kind {
-    return u.val.(privilege.Kind)
-}
 func (u *sqlSymUnion) privilegeList() privilege.List {
     return u.val.(privilege.List)
 }
@@ -313,18 +310,39 @@ func (u *sqlSymUnion) idxElems() IndexElemList {
 func (u *sqlSymUnion) dropBehavior() DropBehavior {
     return u.val.(DropBehavior)
 }
+func (u *sqlSymUnion) auditMode() AuditMode {
+    return u.val.(AuditMode)
+}
 func (u *sqlSymUnion) validationBehavior() ValidationBehavior {
     return u.val.(ValidationBehavior)
 }
 func (u *sqlSymUnion) interleave() *InterleaveDef {
     return u.val.(*InterleaveDef)
 }
+func (u *sqlSymUnion) partitionBy() *PartitionBy {
+    return u.val.(*PartitionBy)
+}
+func (u *sqlSymUnion) listPartition() ListPartition {
+    return u.val.(ListPartition)
+}
+func (u *sqlSymUnion) listPartitions() []ListPartition {
+    return u.val.([]ListPartition)
+}
+func (u *sqlSymUnion) rangePartition() RangePartition {
+    return u.val.(RangePartition)
+}
+func (u *sqlSymUnion) rangePartitions() []RangePartition {
+    return u.val.([]RangePartition)
+}
 func (u *sqlSymUnion) windowDef() *WindowDef {
     return u.val.(*WindowDef)
 }
 func (u *sqlSymUnion) window() Window {
     return u.val.(Window)
 }
+func (u *sqlSymUnion) distinctOn() DistinctOn {
+    return u.val.(DistinctOn)
+}
 func (u *sqlSymUnion) op() operator {
     return u.val.(operator)
 }
@@ -346,6 +364,12 @@ func (u *sqlSymUnion) kvOptions() []KVOption {
 func (u *sqlSymUnion) transactionModes() TransactionModes {
     return u.val.(TransactionModes)
 }
+func (u *sqlSymUnion) alterTypeAddValuePlacement() *AlterTypeAddValuePlacement {
+    return u.val.(*AlterTypeAddValuePlacement)
+}
+func (u *sqlSymUnion) userOptions() UserOptions {
+    return u.val.(UserOptions)
+}
 
 %}
 
@@ -366,17 +390,17 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str>   ACTION ADD
+%token <str>   ACCOUNT ACTION ADD ADMIN AFTER
 %token <str>   ALL ALTER ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str>   ASYMMETRIC AT
 
-%token <str>   BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
+%token <str>   BACKUP BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str>   BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
 %token <str>   CANCEL CASCADE CASE CAST CHAR
 %token <str>   CHARACTER CHARACTERISTICS CHECK
 %token <str>   CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
-%token <str>   COMMITTED CONCAT CONFLICT CONSTRAINT CONSTRAINTS
+%token <str>   COMMITTED CONCAT CONCURRENTLY CONFLICT CONSTRAINT CONSTRAINTS
 %token <str>   CONTAINS COPY COVERING CREATE
 %token <str>   CROSS CSV CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
 %token <str>   CURRENT_ROLE CURRENT_TIME CURRENT_TIMESTAMP
@@ -384,10 +408,10 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 
 %token <str>   DATA DATABASE DATABASES DATE DAY DEC DECIMAL DEFAULT
 %token <str>   DEALLOCATE DEFERRABLE DELETE DESC
-%token <str>   DISCARD DISTINCT DO DOUBLE DROP
+%token <str>   DISABLE DISCARD DISTINCT DO DOUBLE DROP
 
-%token <str>   ELSE ENCODING END ESCAPE EXCEPT
-%token <str>   EXISTS EXECUTE EXPERIMENTAL_FINGERPRINTS EXPLAIN EXTRACT EXTRACT_DURATION
+%token <str>   ELSE ENABLE ENCODING END ENUM ESCAPE EXCEPT
+%token <str>   EXISTS EXECUTE EXPERIMENTAL_AUDIT EXPERIMENTAL_FINGERPRINTS EXPLAIN EXTRACT EXTRACT_DURATION
 
 %token <str>   FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH FILTER
 %token <str>   FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FROM FULL
@@ -406,40 +430,40 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %token <str>   KEY KEYS KV
 
 %token <str>   LATERAL LC_CTYPE LC_COLLATE
-%token <str>   LEADING LEAST LEFT LEVEL LIKE LIMIT LOCAL
+%token <str>   LEADING LEAST LEFT LEVEL LIKE LIMIT LIST LOCAL
 %token <str>   LOCALTIME LOCALTIMESTAMP LOW LSHIFT
 
-%token <str>   MATCH MINUTE MONTH
+%token <str>   MATCH MATERIALIZED MINUTE MONTH
 
 %token <str>   NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
 %token <str>   NOT NOTHING NULL NULLIF
 %token <str>   NULLS NUMERIC
 
-%token <str>   OF OFF OFFSET OID ON ONLY OPTIONS OR
+%token <str>   OF OFF OFFSET OID ON ONLY OPTION OPTIONS OR
 %token <str>   ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY
 
-%token <str>   PARENT PARTIAL PARTITION PASSWORD PAUSE PLACING PLANS POSITION
+%token <str>   PARENT PARTIAL PARTITION PASSWORD PAUSE PLACING PLANS POLICY POSITION
 %token <str>   PRECEDING PRECISION PREPARE PRIMARY PRIORITY
 
 %token <str>   QUERIES QUERY
 
-%token <str>   RANGE READ REAL RECURSIVE REF REFERENCES
+%token <str>   RANGE READ REAL RECURSIVE REF REFERENCES REFRESH
 %token <str>   REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str>   REMOVE_PATH RENAME REPEATABLE
 %token <str>   RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
-%token <str>   ROLLBACK ROLLUP ROW ROWS RSHIFT
+%token <str>   ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT
 
-%token <str>   SAVEPOINT SCATTER SEARCH SECOND SELECT SEQUENCES
+%token <str>   SAVEPOINT SCATTER SEARCH SECOND SECURITY SELECT SEQUENCES
 %token <str>   SERIAL SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
 %token <str>   SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 %token <str>   START STATUS STDIN STRICT STRING STORE STORING SUBSTRING
 %token <str>   SYMMETRIC SYSTEM
 
 %token <str>   TABLE TABLES TEMP TEMPLATE TEMPORARY TESTING_RANGES TESTING_RELOCATE TEXT THEN
-%token <str>   TIME TIMESTAMP TIMESTAMPTZ TO TRAILING TRACE TRANSACTION TREAT TRIM TRUE
+%token <str>   TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO TRAILING TRACE TRANSACTION TREAT TRIM TRUE
 %token <str>   TRUNCATE TYPE
 
-%token <str>   UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN
+%token <str>   UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOCK UNTIL
 %token <str>   UPDATE UPSERT USE USER USERS USING UUID
 
 %token <str>   VALID VALIDATE VALUE VALUES VARCHAR VARIADIC VIEW VARYING
@@ -477,6 +501,8 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <Statement> alter_index_stmt
 %type <Statement> alter_view_stmt
 %type <Statement> alter_database_stmt
+%type <Statement> alter_type_stmt
+%type <Statement> alter_user_stmt
 
 // ALTER TABLE
 %type <Statement> alter_onetable_stmt
@@ -512,17 +538,23 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <Statement> create_index_stmt
 %type <Statement> create_table_stmt
 %type <Statement> create_table_as_stmt
+%type <Statement> create_role_stmt
 %type <Statement> create_user_stmt
 %type <Statement> create_view_stmt
+%type <Statement> create_type_stmt
+%type <Statement> create_policy_stmt
 %type <Statement> delete_stmt
 %type <Statement> discard_stmt
 
 %type <Statement> drop_stmt
 %type <Statement> drop_database_stmt
 %type <Statement> drop_index_stmt
+%type <Statement> drop_role_stmt
 %type <Statement> drop_table_stmt
 %type <Statement> drop_user_stmt
 %type <Statement> drop_view_stmt
+%type <Statement> drop_type_stmt
+%type <Statement> drop_policy_stmt
 
 %type <Statement> explain_stmt
 %type <Statement> prepare_stmt
@@ -570,12 +602,14 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <Statement> show_testing_stmt
 %type <Statement> show_trace_stmt
 %type <Statement> show_transaction_stmt
+%type <Statement> show_roles_stmt
 %type <Statement> show_users_stmt
 
 %type <str> session_var
 
 %type <Statement> transaction_stmt
 %type <Statement> truncate_stmt
+%type <Statement> refresh_stmt
 %type <Statement> update_stmt
 %type <Statement> upsert_stmt
 %type <Statement> use_stmt
@@ -602,9 +636,10 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <DropBehavior> opt_interleave_drop_behavior
 
 %type <ValidationBehavior> opt_validate_behavior
+%type <AuditMode> audit_mode
 
 %type <str> opt_template_clause opt_encoding_clause opt_lc_collate_clause opt_lc_ctype_clause
-%type <*string> opt_password
+%type <UserOptions> opt_user_options user_option_list user_option
 
 %type <IsolationLevel> transaction_iso_level
 %type <UserPriority>  transaction_user_priority
@@ -631,8 +666,14 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 
 %type <TableDefs> opt_table_elem_list table_elem_list
 %type <*InterleaveDef> opt_interleave
+%type <*PartitionBy> opt_partition_by
+%type <ListPartition> list_partition
+%type <[]ListPartition> list_partitions
+%type <RangePartition> range_partition
+%type <[]RangePartition> range_partitions
 %type <empty> opt_all_clause
 %type <bool> distinct_clause
+%type <DistinctOn> distinct_on_clause
 %type <NameList> opt_column_list
 %type <OrderBy> sort_clause opt_sort_clause
 %type <[]*Order> sortby_list
@@ -673,7 +714,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <durationField> opt_interval interval_second
 %type <Expr> overlay_placing
 
-%type <bool> opt_unique opt_column
+%type <bool> opt_unique opt_column opt_temp opt_concurrently
 
 %type <empty> opt_set_data
 
@@ -691,6 +732,8 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <TransactionModes> transaction_mode_list transaction_mode
 
 %type <NameList> opt_storing
+%type <NameList> opt_policy_roles
+%type <Expr> opt_policy_using opt_policy_with_check
 %type <*ColumnTableDef> column_def
 %type <TableDef> table_elem
 %type <Expr>  where_clause
@@ -730,6 +773,8 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 
 %type <str> explain_option_name
 %type <[]string> explain_option_list
+%type <[]string> opt_enum_val_list enum_val_list
+%type <*AlterTypeAddValuePlacement> opt_add_val_placement
 
 %type <ColumnType> typename simple_typename const_typename
 %type <ColumnType> numeric opt_numeric_modifiers
@@ -785,8 +830,9 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <TargetList>    targets
 %type <*TargetList> on_privilege_target_clause
 %type <NameList>       grantee_list for_grantee_clause
-%type <privilege.List> privileges privilege_list
-%type <privilege.Kind> privilege
+%type <privilege.List> privileges
+%type <NameList> privilege_list
+%type <str> privilege
 
 // Precedence: lowest to highest
 %nonassoc  VALUES              // see value_clause
@@ -894,6 +940,7 @@ stmt:
 | import_stmt     // EXTEND WITH HELP: IMPORT
 | pause_stmt      // EXTEND WITH HELP: PAUSE JOB
 | prepare_stmt    // EXTEND WITH HELP: PREPARE
+| refresh_stmt    // EXTEND WITH HELP: REFRESH MATERIALIZED VIEW
 | restore_stmt    // EXTEND WITH HELP: RESTORE
 | resume_stmt     // EXTEND WITH HELP: RESUME JOB
 | revoke_stmt     // EXTEND WITH HELP: REVOKE
@@ -923,6 +970,8 @@ alter_stmt:
 | alter_index_stmt    // EXTEND WITH HELP: ALTER INDEX
 | alter_view_stmt     // EXTEND WITH HELP: ALTER VIEW
 | alter_database_stmt // EXTEND WITH HELP: ALTER DATABASE
+| alter_type_stmt     // EXTEND WITH HELP: ALTER TYPE
+| alter_user_stmt     // EXTEND WITH HELP: ALTER USER
 | ALTER error         // SHOW HELP: ALTER
 
 // %Help: ALTER TABLE - change the definition of a table
@@ -942,6 +991,8 @@ alter_stmt:
 //   ALTER TABLE ... VALIDATE CONSTRAINT <constraintname>
 //   ALTER TABLE ... SPLIT AT <selectclause>
 //   ALTER TABLE ... SCATTER [ FROM ( <exprs...> ) TO ( <exprs...> ) ]
+//   ALTER TABLE ... EXPERIMENTAL_AUDIT SET {READ WRITE | OFF}
+//   ALTER TABLE ... {ENABLE | DISABLE} ROW LEVEL SECURITY
 //
 // Column qualifiers:
 //   [CONSTRAINT <constraintname>] {NULL | NOT NULL | UNIQUE | PRIMARY KEY | CHECK (<expr>) | DEFAULT <expr>}
@@ -982,6 +1033,77 @@ alter_database_stmt:
 // prefix is spread over multiple non-terminals.
 | ALTER DATABASE error // SHOW HELP: ALTER DATABASE
 
+// %Help: ALTER TYPE - change the definition of a type
+// %Category: DDL
+// %Text:
+// ALTER TYPE <name> ADD VALUE [IF NOT EXISTS] <value> [{BEFORE | AFTER} <existing_value>]
+// %SeeAlso: CREATE TYPE
+alter_type_stmt:
+  ALTER TYPE name ADD VALUE SCONST opt_add_val_placement
+  {
+    $$.val = &AlterTypeAddValue{
+      Name: Name($3),
+      NewVal: $6,
+      Placement: $7.alterTypeAddValuePlacement(),
+    }
+  }
+| ALTER TYPE name ADD VALUE IF NOT EXISTS SCONST opt_add_val_placement
+  {
+    $$.val = &AlterTypeAddValue{
+      Name: Name($3),
+      NewVal: $9,
+      IfNotExists: true,
+      Placement: $10.alterTypeAddValuePlacement(),
+    }
+  }
+| ALTER TYPE error // SHOW HELP: ALTER TYPE
+
+// %Help: ALTER USER - change the options of a user
+// %Category: Priv
+// %Text:
+// ALTER USER [IF EXISTS] <name> [WITH] <option> [...]
+// ALTER USER [IF EXISTS] <name> ACCOUNT UNLOCK
+//
+// Options:
+//   PASSWORD <passwd>
+//   VALID UNTIL <timestamp>
+//
+// A password never expires if its timestamp is 'infinity'. ACCOUNT UNLOCK
+// unlocks a user locked out after too many failed login attempts.
+// %SeeAlso: CREATE USER, SHOW USERS
+alter_user_stmt:
+  ALTER USER name opt_with user_option_list
+  {
+    $$.val = &AlterUser{Name: Name($3), UserOptions: $5.userOptions()}
+  }
+| ALTER USER IF EXISTS name opt_with user_option_list
+  {
+    $$.val = &AlterUser{Name: Name($5), IfExists: true, UserOptions: $7.userOptions()}
+  }
+| ALTER USER name ACCOUNT UNLOCK
+  {
+    $$.val = &AlterUser{Name: Name($3), Unlock: true}
+  }
+| ALTER USER IF EXISTS name ACCOUNT UNLOCK
+  {
+    $$.val = &AlterUser{Name: Name($5), IfExists: true, Unlock: true}
+  }
+| ALTER USER error // SHOW HELP: ALTER USER
+
+opt_add_val_placement:
+  BEFORE SCONST
+  {
+    $$.val = &AlterTypeAddValuePlacement{Before: true, ExistingVal: $2}
+  }
+| AFTER SCONST
+  {
+    $$.val = &AlterTypeAddValuePlacement{Before: false, ExistingVal: $2}
+  }
+| /* EMPTY */
+  {
+    $$.val = (*AlterTypeAddValuePlacement)(nil)
+  }
+
 // %Help: ALTER INDEX - change the definition of an index
 // %Category: DDL
 // %Text:
@@ -1159,6 +1281,31 @@ alter_table_cmd:
       DropBehavior: $4.dropBehavior(),
     }
   }
+  // ALTER TABLE <name> EXPERIMENTAL_AUDIT SET <mode>
+| EXPERIMENTAL_AUDIT SET audit_mode
+  {
+    $$.val = &AlterTableSetAudit{Mode: $3.auditMode()}
+  }
+  // ALTER TABLE <name> ENABLE ROW LEVEL SECURITY
+| ENABLE ROW LEVEL SECURITY
+  {
+    $$.val = &AlterTableRowLevelSecurity{Enable: true}
+  }
+  // ALTER TABLE <name> DISABLE ROW LEVEL SECURITY
+| DISABLE ROW LEVEL SECURITY
+  {
+    $$.val = &AlterTableRowLevelSecurity{Enable: false}
+  }
+
+audit_mode:
+  READ WRITE
+  {
+    $$.val = AuditModeReadWrite
+  }
+| OFF
+  {
+    $$.val = AuditModeDisable
+  }
 
 alter_column_default:
   SET DEFAULT a_expr
@@ -1417,9 +1564,12 @@ create_stmt:
 | create_table_stmt    // EXTEND WITH HELP: CREATE TABLE
 | create_table_as_stmt // EXTEND WITH HELP: CREATE TABLE
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
-| CREATE TABLE error   // SHOW HELP: CREATE TABLE
+| CREATE opt_temp TABLE error   // SHOW HELP: CREATE TABLE
+| create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_user_stmt     // EXTEND WITH HELP: CREATE USER
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
+| create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
+| create_policy_stmt   // EXTEND WITH HELP: CREATE POLICY
 | CREATE error         // SHOW HELP: CREATE
 
 // %Help: DELETE - delete rows from a table
@@ -1442,7 +1592,7 @@ delete_stmt:
 
 // %Help: DISCARD - reset the session to its initial state
 // %Category: Cfg
-// %Text: DISCARD ALL
+// %Text: DISCARD { ALL | TEMP }
 discard_stmt:
   DISCARD ALL
   {
@@ -1450,19 +1600,28 @@ discard_stmt:
   }
 | DISCARD PLANS { return unimplemented(sqllex, "discard plans") }
 | DISCARD SEQUENCES { return unimplemented(sqllex, "discard sequences") }
-| DISCARD TEMP { return unimplemented(sqllex, "discard temp") }
-| DISCARD TEMPORARY { return unimplemented(sqllex, "discard temporary") }
+| DISCARD TEMP
+  {
+    $$.val = &Discard{Mode: DiscardModeTemp}
+  }
+| DISCARD TEMPORARY
+  {
+    $$.val = &Discard{Mode: DiscardModeTemp}
+  }
 | DISCARD error // SHOW HELP: DISCARD
 
 // %Help: DROP
 // %Category: Group
-// %Text: DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP USER
+// %Text: DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP USER, DROP ROLE
 drop_stmt:
   drop_database_stmt // EXTEND WITH HELP: DROP DATABASE
 | drop_index_stmt    // EXTEND WITH HELP: DROP INDEX
 | drop_table_stmt    // EXTEND WITH HELP: DROP TABLE
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
+| drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
+| drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
+| drop_policy_stmt   // EXTEND WITH HELP: DROP POLICY
 | DROP error         // SHOW HELP: DROP
 
 // %Help: DROP VIEW - remove a view
@@ -1556,6 +1715,51 @@ drop_user_stmt:
   }
 | DROP USER error // SHOW HELP: DROP USER
 
+// %Help: DROP ROLE - remove a role
+// %Category: Priv
+// %Text: DROP ROLE [IF EXISTS] <role> [, ...]
+// %SeeAlso: CREATE ROLE, SHOW ROLES
+drop_role_stmt:
+  DROP ROLE name_list
+  {
+    $$.val = &DropRole{Names: $3.nameList(), IfExists: false}
+  }
+| DROP ROLE IF EXISTS name_list
+  {
+    $$.val = &DropRole{Names: $5.nameList(), IfExists: true}
+  }
+| DROP ROLE error // SHOW HELP: DROP ROLE
+
+// %Help: DROP TYPE - remove a type
+// %Category: DDL
+// %Text: DROP TYPE [IF EXISTS] <typename> [, ...]
+// %SeeAlso: CREATE TYPE
+drop_type_stmt:
+  DROP TYPE name_list
+  {
+    $$.val = &DropType{Names: $3.nameList(), IfExists: false}
+  }
+| DROP TYPE IF EXISTS name_list
+  {
+    $$.val = &DropType{Names: $5.nameList(), IfExists: true}
+  }
+| DROP TYPE error // SHOW HELP: DROP TYPE
+
+// %Help: DROP POLICY - remove a row-level security policy
+// %Category: Priv
+// %Text: DROP POLICY [IF EXISTS] <name> ON <tablename>
+// %SeeAlso: CREATE POLICY
+drop_policy_stmt:
+  DROP POLICY name ON qualified_name
+  {
+    $$.val = &DropPolicy{Name: Name($3), Table: $5.normalizableTableName(), IfExists: false}
+  }
+| DROP POLICY IF EXISTS name ON qualified_name
+  {
+    $$.val = &DropPolicy{Name: Name($5), Table: $7.normalizableTableName(), IfExists: true}
+  }
+| DROP POLICY error // SHOW HELP: DROP POLICY
+
 table_name_list:
   any_name
   {
@@ -1731,13 +1935,19 @@ deallocate_stmt:
   }
 | DEALLOCATE error // SHOW HELP: DEALLOCATE
 
-// %Help: GRANT - define access privileges
+// %Help: GRANT - define access privileges and role memberships
 // %Category: Priv
 // %Text:
-// GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+// Grant privileges:
+//   GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+// Grant column privileges:
+//   GRANT {ALL | <privileges...> } (<colnames...>) ON [TABLE] <tablenames...> TO <grantees...>
+// Grant role membership:
+//   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
 // Privileges:
 //   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE
+//   (only SELECT and UPDATE can be granted on columns)
 //
 // Targets:
 //   DATABASE <databasename> [, ...]
@@ -1749,15 +1959,33 @@ grant_stmt:
   {
     $$.val = &Grant{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| GRANT privileges '(' name_list ')' ON targets TO grantee_list
+  {
+    $$.val = &Grant{Privileges: $2.privilegeList(), Columns: $4.nameList(), Grantees: $9.nameList(), Targets: $7.targetList()}
+  }
+| GRANT privilege_list TO grantee_list
+  {
+    $$.val = &GrantRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false}
+  }
+| GRANT privilege_list TO grantee_list WITH ADMIN OPTION
+  {
+    $$.val = &GrantRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: true}
+  }
 | GRANT error // SHOW HELP: GRANT
 
 // %Help: REVOKE - remove access privileges
 // %Category: Priv
 // %Text:
-// REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+// Revoke privileges:
+//   REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+// Revoke column privileges:
+//   REVOKE {ALL | <privileges...> } (<colnames...>) ON [TABLE] <tablenames...> FROM <grantees...>
+// Revoke role membership:
+//   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
 // Privileges:
 //   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE
+//   (only SELECT and UPDATE can be revoked on columns)
 //
 // Targets:
 //   DATABASE <databasename> [, <databasename>]...
@@ -1769,6 +1997,18 @@ revoke_stmt:
   {
     $$.val = &Revoke{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| REVOKE privileges '(' name_list ')' ON targets FROM grantee_list
+  {
+    $$.val = &Revoke{Privileges: $2.privilegeList(), Columns: $4.nameList(), Grantees: $9.nameList(), Targets: $7.targetList()}
+  }
+| REVOKE privilege_list FROM grantee_list
+  {
+    $$.val = &RevokeRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false}
+  }
+| REVOKE ADMIN OPTION FOR privilege_list FROM grantee_list
+  {
+    $$.val = &RevokeRole{Roles: $5.nameList(), Members: $7.nameList(), AdminOption: true}
+  }
 | REVOKE error // SHOW HELP: REVOKE
 
 targets:
@@ -1791,48 +2031,35 @@ privileges:
   {
     $$.val = privilege.List{privilege.ALL}
   }
-  | privilege_list { }
+| privilege_list
+  {
+    privList, err := privilege.ListFromStrings($1.nameList().ToStrings())
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = privList
+  }
 
 privilege_list:
   privilege
   {
-    $$.val = privilege.List{$1.privilegeType()}
+    $$.val = NameList{Name($1)}
   }
-  | privilege_list ',' privilege
+| privilege_list ',' privilege
   {
-    $$.val = append($1.privilegeList(), $3.privilegeType())
+    $$.val = append($1.nameList(), Name($3))
   }
 
-// This list must match the list of privileges in sql/privilege/privilege.go.
+// Privileges are parsed as names so that the same list can also name the
+// roles granted by GRANT <roles> TO <grantees>. The keywords below are the
+// reserved ones; the others (DROP, INSERT, DELETE, UPDATE) are names. This
+// must match the list of privileges in sql/privilege/privilege.go.
 privilege:
-  CREATE
-  {
-    $$.val = privilege.CREATE
-  }
-| DROP
-  {
-    $$.val = privilege.DROP
-  }
+  name
+| CREATE
 | GRANT
-  {
-    $$.val = privilege.GRANT
-  }
 | SELECT
-  {
-    $$.val = privilege.SELECT
-  }
-| INSERT
-  {
-    $$.val = privilege.INSERT
-  }
-| DELETE
-  {
-    $$.val = privilege.DELETE
-  }
-| UPDATE
-  {
-    $$.val = privilege.UPDATE
-  }
 
 // TODO(marc): this should not be 'name', but should instead be a
 // type just for usernames.
@@ -2094,7 +2321,7 @@ non_reserved_word_or_sconst:
 // %Category: Group
 // %Text:
 // SHOW SESSION, SHOW CLUSTER SETTING, SHOW DATABASES, SHOW TABLES, SHOW COLUMNS, SHOW INDEXES,
-// SHOW CONSTRAINTS, SHOW CREATE TABLE, SHOW CREATE VIEW, SHOW USERS, SHOW TRANSACTION, SHOW BACKUP,
+// SHOW CONSTRAINTS, SHOW CREATE TABLE, SHOW CREATE VIEW, SHOW USERS, SHOW ROLES, SHOW TRANSACTION, SHOW BACKUP,
 // SHOW JOBS, SHOW QUERIES, SHOW SESSIONS, SHOW TRACE
 show_stmt:
   show_backup_stmt       // EXTEND WITH HELP: SHOW BACKUP
@@ -2114,6 +2341,7 @@ show_stmt:
 | show_testing_stmt
 | show_trace_stmt        // EXTEND WITH HELP: SHOW TRACE
 | show_transaction_stmt  // EXTEND WITH HELP: SHOW TRANSACTION
+| show_roles_stmt        // EXTEND WITH HELP: SHOW ROLES
 | show_users_stmt        // EXTEND WITH HELP: SHOW USERS
 | SHOW error             // SHOW HELP: SHOW
 
@@ -2198,13 +2426,22 @@ show_databases_stmt:
 
 // %Help: SHOW GRANTS - list grants
 // %Category: Priv
-// %Text: SHOW GRANTS [ON <targets...>] [FOR <users...>]
+// %Text:
+// Show privilege grants:
+//   SHOW GRANTS [ON <targets...>] [FOR <users...>]
+// Show role grants:
+//   SHOW GRANTS ON ROLE <roles...> [FOR <grantees...>]
+//
 // %SeeAlso: WEBDOCS/show-grants.html
 show_grants_stmt:
   SHOW GRANTS on_privilege_target_clause for_grantee_clause
   {
     $$.val = &ShowGrants{Targets: $3.targetListPtr(), Grantees: $4.nameList()}
   }
+| SHOW GRANTS ON ROLE name_list for_grantee_clause
+  {
+    $$.val = &ShowRoleGrants{Roles: $5.nameList(), Grantees: $6.nameList()}
+  }
 | SHOW GRANTS error // SHOW HELP: SHOW GRANTS
 
 // %Help: SHOW INDEXES - list indexes
@@ -2388,6 +2625,17 @@ show_users_stmt:
   }
 | SHOW USERS error // SHOW HELP: SHOW USERS
 
+// %Help: SHOW ROLES - list defined roles
+// %Category: Priv
+// %Text: SHOW ROLES
+// %SeeAlso: CREATE ROLE, DROP ROLE
+show_roles_stmt:
+  SHOW ROLES
+  {
+    $$.val = &ShowRoles{}
+  }
+| SHOW ROLES error // SHOW HELP: SHOW ROLES
+
 show_testing_stmt:
   SHOW TESTING_RANGES FROM TABLE qualified_name
   {
@@ -2440,8 +2688,8 @@ pause_stmt:
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
 // %Text:
-// CREATE TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
-// CREATE TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
+// CREATE [TEMP] TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>] [<partition>]
+// CREATE [TEMP] TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
 //
 // Table elements:
 //    <name> <type> [<qualifiers...>]
@@ -2465,27 +2713,55 @@ pause_stmt:
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
 //
+// Partition clause:
+//    PARTITION BY LIST ( <colnames...> ) ( PARTITION <name> VALUES IN ( <values...> ) [, ...] )
+//    PARTITION BY RANGE ( <colnames...> ) ( PARTITION <name> VALUES FROM ( <values...> ) TO ( <values...> ) [, ...] )
+//
 // %SeeAlso: SHOW TABLES, CREATE VIEW, SHOW CREATE TABLE,
 // WEBDOCS/create-table.html
 // WEBDOCS/create-table-as.html
 create_table_stmt:
-  CREATE TABLE any_name '(' opt_table_elem_list ')' opt_interleave
+  CREATE opt_temp TABLE any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
   {
-    $$.val = &CreateTable{Table: $3.normalizableTableName(), IfNotExists: false, Interleave: $7.interleave(), Defs: $5.tblDefs(), AsSource: nil, AsColumnNames: nil}
+    $$.val = &CreateTable{Table: $4.normalizableTableName(), Temporary: $2.bool(), IfNotExists: false, Interleave: $8.interleave(), PartitionBy: $9.partitionBy(), Defs: $6.tblDefs(), AsSource: nil, AsColumnNames: nil}
   }
-| CREATE TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave
+| CREATE opt_temp TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
   {
-    $$.val = &CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: $10.interleave(), Defs: $8.tblDefs(), AsSource: nil, AsColumnNames: nil}
+    $$.val = &CreateTable{Table: $7.normalizableTableName(), Temporary: $2.bool(), IfNotExists: true, Interleave: $11.interleave(), PartitionBy: $12.partitionBy(), Defs: $9.tblDefs(), AsSource: nil, AsColumnNames: nil}
   }
 
 create_table_as_stmt:
-  CREATE TABLE any_name opt_column_list AS select_stmt
+  CREATE opt_temp TABLE any_name opt_column_list AS select_stmt
   {
-    $$.val = &CreateTable{Table: $3.normalizableTableName(), IfNotExists: false, Interleave: nil, Defs: nil, AsSource: $6.slct(), AsColumnNames: $4.nameList()}
+    $$.val = &CreateTable{Table: $4.normalizableTableName(), Temporary: $2.bool(), IfNotExists: false, Interleave: nil, Defs: nil, AsSource: $7.slct(), AsColumnNames: $5.nameList()}
   }
-| CREATE TABLE IF NOT EXISTS any_name opt_column_list AS select_stmt
+| CREATE opt_temp TABLE IF NOT EXISTS any_name opt_column_list AS select_stmt
   {
-    $$.val = &CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: nil, Defs: nil, AsSource: $9.slct(), AsColumnNames: $7.nameList()}
+    $$.val = &CreateTable{Table: $7.normalizableTableName(), Temporary: $2.bool(), IfNotExists: true, Interleave: nil, Defs: nil, AsSource: $10.slct(), AsColumnNames: $8.nameList()}
+  }
+
+// Temporary tables are scoped to the session that created them; the
+// LOCAL keyword is accepted for compatibility and has no effect.
+opt_temp:
+  TEMPORARY
+  {
+    $$.val = true
+  }
+| TEMP
+  {
+    $$.val = true
+  }
+| LOCAL TEMPORARY
+  {
+    $$.val = true
+  }
+| LOCAL TEMP
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
   }
 
 opt_table_elem_list:
@@ -2531,6 +2807,58 @@ opt_interleave:
     $$.val = (*InterleaveDef)(nil)
   }
 
+opt_partition_by:
+  PARTITION BY LIST '(' name_list ')' '(' list_partitions ')'
+  {
+    $$.val = &PartitionBy{
+               Fields: $5.nameList(),
+               List: $8.listPartitions(),
+    }
+  }
+| PARTITION BY RANGE '(' name_list ')' '(' range_partitions ')'
+  {
+    $$.val = &PartitionBy{
+               Fields: $5.nameList(),
+               Range: $8.rangePartitions(),
+    }
+  }
+| /* EMPTY */
+  {
+    $$.val = (*PartitionBy)(nil)
+  }
+
+list_partitions:
+  list_partition
+  {
+    $$.val = []ListPartition{$1.listPartition()}
+  }
+| list_partitions ',' list_partition
+  {
+    $$.val = append($1.listPartitions(), $3.listPartition())
+  }
+
+list_partition:
+  PARTITION name VALUES IN '(' expr_list ')'
+  {
+    $$.val = ListPartition{Name: Name($2), Exprs: $6.exprs()}
+  }
+
+range_partitions:
+  range_partition
+  {
+    $$.val = []RangePartition{$1.rangePartition()}
+  }
+| range_partitions ',' range_partition
+  {
+    $$.val = append($1.rangePartitions(), $3.rangePartition())
+  }
+
+range_partition:
+  PARTITION name VALUES FROM '(' expr_list ')' TO '(' expr_list ')'
+  {
+    $$.val = RangePartition{Name: Name($2), From: $6.exprs(), To: $10.exprs()}
+  }
+
 // TODO(dan): This can be removed in favor of opt_drop_behavior when #7854 is fixed.
 opt_interleave_drop_behavior:
   CASCADE
@@ -2812,29 +3140,66 @@ truncate_stmt:
 
 // %Help: CREATE USER - define a new user
 // %Category: Priv
-// %Text: CREATE USER <name> [ [WITH] PASSWORD <passwd> ]
-// %SeeAlso: DROP USER, SHOW USERS, WEBDOCS/create-user.html
+// %Text: CREATE USER <name> [ [WITH] PASSWORD <passwd> ] [VALID UNTIL <timestamp>]
+// %SeeAlso: ALTER USER, DROP USER, SHOW USERS, WEBDOCS/create-user.html
 create_user_stmt:
-  CREATE USER name opt_password
+  CREATE USER name opt_user_options
   {
-    $$.val = &CreateUser{Name: Name($3), Password: $4.strPtr()}
+    $$.val = &CreateUser{Name: Name($3), UserOptions: $4.userOptions()}
   }
 | CREATE USER error // SHOW HELP: CREATE USER
 
-opt_password:
-  opt_with PASSWORD SCONST
+opt_user_options:
+  opt_with user_option_list
+  {
+    $$.val = $2.userOptions()
+  }
+| /* EMPTY */
+  {
+    $$.val = UserOptions{}
+  }
+
+user_option_list:
+  user_option
   {
-    pwd := $3
-    $$.val = &pwd
+    $$.val = $1.userOptions()
   }
-| /* EMPTY */ {
-    $$.val = (*string)(nil)
+| user_option_list user_option
+  {
+    a := $1.userOptions()
+    b := $2.userOptions()
+    err := a.merge(b)
+    if err != nil { sqllex.Error(err.Error()); return 1 }
+    $$.val = a
+  }
+
+user_option:
+  PASSWORD SCONST
+  {
+    pwd := $2
+    $$.val = UserOptions{Password: &pwd}
+  }
+| VALID UNTIL SCONST
+  {
+    validUntil := $3
+    $$.val = UserOptions{ValidUntil: &validUntil}
   }
 
+// %Help: CREATE ROLE - define a new role
+// %Category: Priv
+// %Text: CREATE ROLE <name>
+// %SeeAlso: DROP ROLE, SHOW ROLES, GRANT
+create_role_stmt:
+  CREATE ROLE name
+  {
+    $$.val = &CreateRole{Name: Name($3)}
+  }
+| CREATE ROLE error // SHOW HELP: CREATE ROLE
+
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
-// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
-// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, WEBDOCS/create-view.html
+// %Text: CREATE [MATERIALIZED] VIEW <viewname> [( <colnames...> )] AS <source>
+// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, REFRESH MATERIALIZED VIEW, WEBDOCS/create-view.html
 create_view_stmt:
   CREATE VIEW any_name opt_column_list AS select_stmt
   {
@@ -2844,7 +3209,129 @@ create_view_stmt:
       AsSource: $6.slct(),
     }
   }
+| CREATE MATERIALIZED VIEW any_name opt_column_list AS select_stmt
+  {
+    $$.val = &CreateView{
+      Name: $4.normalizableTableName(),
+      ColumnNames: $5.nameList(),
+      AsSource: $7.slct(),
+      Materialized: true,
+    }
+  }
 | CREATE VIEW error // SHOW HELP: CREATE VIEW
+| CREATE MATERIALIZED VIEW error // SHOW HELP: CREATE VIEW
+
+// %Help: REFRESH MATERIALIZED VIEW - recompute the contents of a materialized view
+// %Category: DDL
+// %Text: REFRESH MATERIALIZED VIEW [CONCURRENTLY] <viewname>
+// %SeeAlso: CREATE VIEW, SHOW JOBS
+refresh_stmt:
+  REFRESH MATERIALIZED VIEW opt_concurrently any_name
+  {
+    $$.val = &RefreshMaterializedView{
+      Name: $5.normalizableTableName(),
+      Concurrently: $4.bool(),
+    }
+  }
+| REFRESH error // SHOW HELP: REFRESH MATERIALIZED VIEW
+
+opt_concurrently:
+  CONCURRENTLY
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+// %Help: CREATE TYPE - create a new type
+// %Category: DDL
+// %Text: CREATE TYPE <typename> AS ENUM ( [<value> [, ...]] )
+// %SeeAlso: ALTER TYPE, DROP TYPE
+create_type_stmt:
+  CREATE TYPE name AS ENUM '(' opt_enum_val_list ')'
+  {
+    $$.val = &CreateType{
+      Name: Name($3),
+      EnumLabels: $7.strs(),
+    }
+  }
+| CREATE TYPE error // SHOW HELP: CREATE TYPE
+
+// %Help: CREATE POLICY - define a row-level security policy
+// %Category: Priv
+// %Text:
+// CREATE POLICY <name> ON <tablename>
+//        [TO <user_or_role> [, ...]]
+//        [USING ( <expr> )]
+//        [WITH CHECK ( <expr> )]
+//
+// The policies of a table only take effect once row-level security is
+// enabled with ALTER TABLE ... ENABLE ROW LEVEL SECURITY.
+// %SeeAlso: DROP POLICY, ALTER TABLE
+create_policy_stmt:
+  CREATE POLICY name ON qualified_name opt_policy_roles opt_policy_using opt_policy_with_check
+  {
+    $$.val = &CreatePolicy{
+      Name: Name($3),
+      Table: $5.normalizableTableName(),
+      Roles: $6.nameList(),
+      Using: $7.expr(),
+      WithCheck: $8.expr(),
+    }
+  }
+| CREATE POLICY error // SHOW HELP: CREATE POLICY
+
+opt_policy_roles:
+  TO name_list
+  {
+    $$.val = $2.nameList()
+  }
+| /* EMPTY */
+  {
+    $$.val = NameList(nil)
+  }
+
+opt_policy_using:
+  USING '(' a_expr ')'
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = Expr(nil)
+  }
+
+opt_policy_with_check:
+  WITH CHECK '(' a_expr ')'
+  {
+    $$.val = $4.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = Expr(nil)
+  }
+
+opt_enum_val_list:
+  enum_val_list
+  {
+    $$.val = $1.strs()
+  }
+| /* EMPTY */
+  {
+    $$.val = []string(nil)
+  }
+
+enum_val_list:
+  SCONST
+  {
+    $$.val = []string{$1}
+  }
+| enum_val_list ',' SCONST
+  {
+    $$.val = append($1.strs(), $3)
+  }
 
 // TODO(a-robinson): CREATE OR REPLACE VIEW support (#2971).
 
@@ -3558,7 +4045,7 @@ simple_select:
 // %Help: SELECT - retrieve rows from a data source and compute a result
 // %Category: DML
 // %Text:
-// SELECT [DISTINCT]
+// SELECT [DISTINCT [ ON ( <expr> [, ...] ) ] ]
 //        { <expr> [[AS] <name>] | [ [<dbname>.] <tablename>. ] * } [, ...]
 //        [ FROM <source> ]
 //        [ WHERE <expr> ]
@@ -3598,6 +4085,21 @@ simple_select_clause:
       Window:   $8.window(),
     }
   }
+| SELECT distinct_on_clause target_list
+    from_clause where_clause
+    group_clause having_clause window_clause
+  {
+    $$.val = &SelectClause{
+      Distinct:   true,
+      DistinctOn: $2.distinctOn(),
+      Exprs:      $3.selExprs(),
+      From:       $4.from(),
+      Where:      newWhere(astWhere, $5.expr()),
+      GroupBy:    $6.groupBy(),
+      Having:     newWhere(astHaving, $7.expr()),
+      Window:     $8.window(),
+    }
+  }
 | SELECT error // SHOW HELP: SELECT
 
 set_operation:
@@ -3696,6 +4198,12 @@ distinct_clause:
     $$.val = true
   }
 
+distinct_on_clause:
+  DISTINCT ON '(' expr_list ')'
+  {
+    $$.val = DistinctOn($4.exprs())
+  }
+
 opt_all_clause:
   ALL {}
 | /* EMPTY */ {}
@@ -4390,13 +4898,12 @@ simple_typename:
     // See https://www.postgresql.org/docs/9.1/static/datatype-character.html
     // Postgres supports a special character type named "char" (with the quotes)
     // that is a single-character column type. It's used by system tables.
-    // Eventually this clause will be used to parse user-defined types as well,
-    // since their names can be quoted.
+    // Any other identifier names a user-defined type (currently only ENUM
+    // types), which is resolved during type checking.
     if $1 == "char" {
       $$.val = stringColTypeChar
     } else {
-      sqllex.Error("syntax error")
-      return 1
+      $$.val = &EnumColType{Name: Name($1)}
     }
   }
 
@@ -4667,6 +5174,22 @@ const_datetime:
   {
     $$.val = dateColTypeDate
   }
+| TIME
+  {
+    $$.val = timeColTypeTime
+  }
+| TIME WITHOUT TIME ZONE
+  {
+    $$.val = timeColTypeTime
+  }
+| TIMETZ
+  {
+    $$.val = timeTZColTypeTimeWithTZ
+  }
+| TIME WITH_LA TIME ZONE
+  {
+    $$.val = timeTZColTypeTimeWithTZ
+  }
 | TIMESTAMP
   {
     $$.val = timestampColTypeTimestamp
@@ -4789,7 +5312,10 @@ a_expr:
   {
     $$.val = &CollateExpr{Expr: $1.expr(), Locale: $3}
   }
-| a_expr AT TIME ZONE a_expr %prec AT { return unimplemented(sqllex, "at tz") }
+| a_expr AT TIME ZONE a_expr %prec AT
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("timezone"), Exprs: Exprs{$5.expr(), $1.expr()}}
+  }
   // These operators must be called out explicitly in order to make use of
   // bison's automatic operator-precedence handling. All other operator names
   // are handled by the generic productions using "OP", below; and all those
@@ -5363,7 +5889,28 @@ func_expr_common_subexpr:
     $$.val = &FuncExpr{Func: wrapFunction($1)}
   }
 | CURRENT_TIMESTAMP '(' error { return helpWithFunction(sqllex, ResolvableFunctionReference{UnresolvedName{Name($1)}}) }
-| CURRENT_ROLE { return unimplemented(sqllex, "current role") }
+| CURRENT_TIME
+  {
+    $$.val = &FuncExpr{Func: wrapFunction($1)}
+  }
+| CURRENT_TIME '(' ')'
+  {
+    $$.val = &FuncExpr{Func: wrapFunction($1)}
+  }
+| CURRENT_TIME '(' error { return helpWithFunction(sqllex, ResolvableFunctionReference{UnresolvedName{Name($1)}}) }
+| LOCALTIME
+  {
+    $$.val = &FuncExpr{Func: wrapFunction($1)}
+  }
+| LOCALTIME '(' ')'
+  {
+    $$.val = &FuncExpr{Func: wrapFunction($1)}
+  }
+| LOCALTIME '(' error { return helpWithFunction(sqllex, ResolvableFunctionReference{UnresolvedName{Name($1)}}) }
+| CURRENT_ROLE
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("current_user")}
+  }
 | CURRENT_USER
   {
     $$.val = &FuncExpr{Func: wrapFunction($1)}
@@ -6243,11 +6790,15 @@ unrestricted_name:
 //
 // "Unreserved" keywords --- available for use as any kind of name.
 unreserved_keyword:
-  ACTION
+  ACCOUNT
+| ACTION
 | ADD
+| ADMIN
+| AFTER
 | ALTER
 | AT
 | BACKUP
+| BEFORE
 | BEGIN
 | BLOB
 | BY
@@ -6271,11 +6822,15 @@ unreserved_keyword:
 | DAY
 | DEALLOCATE
 | DELETE
+| DISABLE
 | DISCARD
 | DOUBLE
 | DROP
+| ENABLE
 | ENCODING
+| ENUM
 | EXECUTE
+| EXPERIMENTAL_AUDIT
 | EXPERIMENTAL_FINGERPRINTS
 | EXPLAIN
 | FILTER
@@ -6300,9 +6855,11 @@ unreserved_keyword:
 | LC_COLLATE
 | LC_CTYPE
 | LEVEL
+| LIST
 | LOCAL
 | LOW
 | MATCH
+| MATERIALIZED
 | MINUTE
 | MONTH
 | NAMES
@@ -6315,6 +6872,7 @@ unreserved_keyword:
 | OF
 | OFF
 | OID
+| OPTION
 | OPTIONS
 | ORDINALITY
 | OVER
@@ -6324,6 +6882,7 @@ unreserved_keyword:
 | PASSWORD
 | PAUSE
 | PLANS
+| POLICY
 | PRECEDING
 | PREPARE
 | PRIORITY
@@ -6333,6 +6892,7 @@ unreserved_keyword:
 | READ
 | RECURSIVE
 | REF
+| REFRESH
 | REGCLASS
 | REGPROC
 | REGPROCEDURE
@@ -6346,6 +6906,8 @@ unreserved_keyword:
 | RESTRICT
 | RESUME
 | REVOKE
+| ROLE
+| ROLES
 | ROLLBACK
 | ROLLUP
 | ROWS
@@ -6356,6 +6918,7 @@ unreserved_keyword:
 | SCATTER
 | SEARCH
 | SECOND
+| SECURITY
 | SERIALIZABLE
 | SEQUENCES
 | SESSION
@@ -6386,6 +6949,8 @@ unreserved_keyword:
 | UNBOUNDED
 | UNCOMMITTED
 | UNKNOWN
+| UNLOCK
+| UNTIL
 | UPDATE
 | UPSERT
 | USE
@@ -6460,6 +7025,7 @@ col_name_keyword:
 | STRING
 | SUBSTRING
 | TIME
+| TIMETZ
 | TIMESTAMP
 | TIMESTAMPTZ
 | TREAT
@@ -6479,6 +7045,7 @@ col_name_keyword:
 // - thomas 2000-11-28
 type_func_name_keyword:
   COLLATION
+| CONCURRENTLY
 | CROSS
 | FAMILY
 | FULL
@@ -101,6 +101,18 @@ func (*AlterTable) StatementTag() string { return "ALTER TABLE" }
 
 func (*AlterTable) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*AlterTypeAddValue) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterTypeAddValue) StatementTag() string { return "ALTER TYPE" }
+
+// StatementType implements the Statement interface.
+func (*AlterUser) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterUser) StatementTag() string { return "ALTER USER" }
+
 // StatementType implements the Statement interface.
 func (*Backup) StatementType() StatementType { return Rows }
 
@@ -166,6 +178,12 @@ func (n *CreateTable) StatementTag() string {
 	return "CREATE TABLE"
 }
 
+// StatementType implements the Statement interface.
+func (*CreateRole) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateRole) StatementTag() string { return "CREATE ROLE" }
+
 // StatementType implements the Statement interface.
 func (*CreateUser) StatementType() StatementType { return Ack }
 
@@ -178,6 +196,18 @@ func (*CreateView) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*CreateView) StatementTag() string { return "CREATE VIEW" }
 
+// StatementType implements the Statement interface.
+func (*CreateType) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateType) StatementTag() string { return "CREATE TYPE" }
+
+// StatementType implements the Statement interface.
+func (*CreatePolicy) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreatePolicy) StatementTag() string { return "CREATE POLICY" }
+
 // StatementType implements the Statement interface.
 func (*Deallocate) StatementType() StatementType { return Ack }
 
@@ -228,12 +258,30 @@ func (*DropView) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*DropView) StatementTag() string { return "DROP VIEW" }
 
+// StatementType implements the Statement interface.
+func (*DropRole) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropRole) StatementTag() string { return "DROP ROLE" }
+
 // StatementType implements the Statement interface.
 func (*DropUser) StatementType() StatementType { return RowsAffected }
 
 // StatementTag returns a short string identifying the type of statement.
 func (*DropUser) StatementTag() string { return "DROP USER" }
 
+// StatementType implements the Statement interface.
+func (*DropType) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropType) StatementTag() string { return "DROP TYPE" }
+
+// StatementType implements the Statement interface.
+func (*DropPolicy) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropPolicy) StatementTag() string { return "DROP POLICY" }
+
 // StatementType implements the Statement interface.
 func (*Execute) StatementType() StatementType { return Unknown }
 
@@ -256,6 +304,14 @@ func (*Grant) StatementTag() string { return "GRANT" }
 
 func (*Grant) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*GrantRole) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*GrantRole) StatementTag() string { return "GRANT" }
+
+func (*GrantRole) hiddenFromStats() {}
+
 // StatementType implements the Statement interface.
 func (n *Insert) StatementType() StatementType { return n.Returning.statementType() }
 
@@ -288,6 +344,12 @@ func (*Prepare) StatementTag() string { return "PREPARE" }
 
 func (*Prepare) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*RefreshMaterializedView) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*RefreshMaterializedView) StatementTag() string { return "REFRESH MATERIALIZED VIEW" }
+
 // StatementType implements the Statement interface.
 func (*ReleaseSavepoint) StatementType() StatementType { return Ack }
 
@@ -353,6 +415,14 @@ func (*Revoke) StatementTag() string { return "REVOKE" }
 
 func (*Revoke) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*RevokeRole) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*RevokeRole) StatementTag() string { return "REVOKE" }
+
+func (*RevokeRole) hiddenFromStats() {}
+
 // StatementType implements the Statement interface.
 func (*RollbackToSavepoint) StatementType() StatementType { return Ack }
 
@@ -503,6 +573,15 @@ func (*ShowGrants) StatementTag() string { return "SHOW GRANTS" }
 func (*ShowGrants) hiddenFromStats()                   {}
 func (*ShowGrants) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ShowRoleGrants) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowRoleGrants) StatementTag() string { return "SHOW GRANTS ON ROLE" }
+
+func (*ShowRoleGrants) hiddenFromStats()                   {}
+func (*ShowRoleGrants) independentFromParallelizedPriors() {}
+
 // StatementType implements the Statement interface.
 func (*ShowIndex) StatementType() StatementType { return Rows }
 
@@ -557,6 +636,15 @@ func (*ShowUsers) StatementTag() string { return "SHOW USERS" }
 func (*ShowUsers) hiddenFromStats()                   {}
 func (*ShowUsers) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ShowRoles) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowRoles) StatementTag() string { return "SHOW ROLES" }
+
+func (*ShowRoles) hiddenFromStats()                   {}
+func (*ShowRoles) independentFromParallelizedPriors() {}
+
 // StatementType implements the Statement interface.
 func (*ShowRanges) StatementType() StatementType { return Rows }
 
@@ -629,6 +717,8 @@ func (n *AlterTableDropColumn) String() string     { return AsString(n) }
 func (n *AlterTableDropConstraint) String() string { return AsString(n) }
 func (n *AlterTableDropNotNull) String() string    { return AsString(n) }
 func (n *AlterTableSetDefault) String() string     { return AsString(n) }
+func (n *AlterTypeAddValue) String() string        { return AsString(n) }
+func (n *AlterUser) String() string                { return AsString(n) }
 func (n *Backup) String() string                   { return AsString(n) }
 func (n *BeginTransaction) String() string         { return AsString(n) }
 func (n *CancelJob) String() string                { return AsString(n) }
@@ -638,23 +728,31 @@ func (n *CopyFrom) String() string                 { return AsString(n) }
 func (n *CreateDatabase) String() string           { return AsString(n) }
 func (n *CreateIndex) String() string              { return AsString(n) }
 func (n *CreateTable) String() string              { return AsString(n) }
+func (n *CreateRole) String() string               { return AsString(n) }
 func (n *CreateUser) String() string               { return AsString(n) }
 func (n *CreateView) String() string               { return AsString(n) }
+func (n *CreateType) String() string               { return AsString(n) }
+func (n *CreatePolicy) String() string             { return AsString(n) }
 func (n *Deallocate) String() string               { return AsString(n) }
 func (n *Delete) String() string                   { return AsString(n) }
 func (n *DropDatabase) String() string             { return AsString(n) }
 func (n *DropIndex) String() string                { return AsString(n) }
 func (n *DropTable) String() string                { return AsString(n) }
 func (n *DropView) String() string                 { return AsString(n) }
+func (n *DropRole) String() string                 { return AsString(n) }
 func (n *DropUser) String() string                 { return AsString(n) }
+func (n *DropType) String() string                 { return AsString(n) }
+func (n *DropPolicy) String() string               { return AsString(n) }
 func (n *Execute) String() string                  { return AsString(n) }
 func (n *Explain) String() string                  { return AsString(n) }
 func (n *Grant) String() string                    { return AsString(n) }
+func (n *GrantRole) String() string                { return AsString(n) }
 func (n *Insert) String() string                   { return AsString(n) }
 func (n *Import) String() string                   { return AsString(n) }
 func (n *ParenSelect) String() string              { return AsString(n) }
 func (n *PauseJob) String() string                 { return AsString(n) }
 func (n *Prepare) String() string                  { return AsString(n) }
+func (n *RefreshMaterializedView) String() string  { return AsString(n) }
 func (n *ReleaseSavepoint) String() string         { return AsString(n) }
 func (n *TestingRelocate) String() string          { return AsString(n) }
 func (n *RenameColumn) String() string             { return AsString(n) }
@@ -664,6 +762,7 @@ func (n *RenameTable) String() string              { return AsString(n) }
 func (n *Restore) String() string                  { return AsString(n) }
 func (n *ResumeJob) String() string                { return AsString(n) }
 func (n *Revoke) String() string                   { return AsString(n) }
+func (n *RevokeRole) String() string               { return AsString(n) }
 func (n *RollbackToSavepoint) String() string      { return AsString(n) }
 func (n *RollbackTransaction) String() string      { return AsString(n) }
 func (n *Savepoint) String() string                { return AsString(n) }
@@ -686,6 +785,8 @@ func (n *ShowIndex) String() string                { return AsString(n) }
 func (n *ShowJobs) String() string                 { return AsString(n) }
 func (n *ShowQueries) String() string              { return AsString(n) }
 func (n *ShowRanges) String() string               { return AsString(n) }
+func (n *ShowRoleGrants) String() string           { return AsString(n) }
+func (n *ShowRoles) String() string                { return AsString(n) }
 func (n *ShowSessions) String() string             { return AsString(n) }
 func (n *ShowTables) String() string               { return AsString(n) }
 func (n *ShowTrace) String() string                { return AsString(n) }
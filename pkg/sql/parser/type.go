@@ -83,6 +83,10 @@ var (
 	TypeBytes Type = tBytes{}
 	// TypeDate is the type of a DDate. Can be compared with ==.
 	TypeDate Type = tDate{}
+	// TypeTime is the type of a DTime. Can be compared with ==.
+	TypeTime Type = tTime{}
+	// TypeTimeTZ is the type of a DTimeTZ. Can be compared with ==.
+	TypeTimeTZ Type = tTimeTZ{}
 	// TypeTimestamp is the type of a DTimestamp. Can be compared with ==.
 	TypeTimestamp Type = tTimestamp{}
 	// TypeTimestampTZ is the type of a DTimestampTZ. Can be compared with ==.
@@ -107,6 +111,9 @@ var (
 	TypeAnyArray Type = TArray{TypeAny}
 	// TypeAny can be any type. Can be compared with ==.
 	TypeAny Type = tAny{}
+	// TypeAnyEnum is the type of a DEnum with a wildcard ENUM type. Can be
+	// compared with ==.
+	TypeAnyEnum Type = TEnum{}
 
 	// TypeOid is the type of an OID. Can be compared with ==.
 	TypeOid = tOid{oid.T_oid}
@@ -140,6 +147,8 @@ var (
 		TypeString,
 		TypeBytes,
 		TypeDate,
+		TypeTime,
+		TypeTimeTZ,
 		TypeTimestamp,
 		TypeTimestampTZ,
 		TypeInterval,
@@ -186,6 +195,8 @@ var OidToType = map[oid.Oid]Type{
 	oid.T__int8:        TArray{TypeInt},
 	oid.T_record:       TypeTuple,
 	oid.T_text:         TypeString,
+	oid.T_time:         TypeTime,
+	oid.T_timetz:       TypeTimeTZ,
 	oid.T_timestamp:    TypeTimestamp,
 	oid.T_timestamptz:  TypeTimestampTZ,
 	oid.T_uuid:         TypeUUID,
@@ -331,6 +342,82 @@ func (t TCollatedString) IsAmbiguous() bool {
 	return t.Locale == ""
 }
 
+// UserDefinedTypeOIDOffset is added to the descriptor ID of a user-defined
+// type to form its OID, keeping it clear of the OIDs of the builtin types.
+const UserDefinedTypeOIDOffset = 100000
+
+// TEnum is the type of a user-defined ENUM. The zero TypeID is a wildcard
+// that is equivalent to every ENUM type.
+type TEnum struct {
+	// TypeID is the ID of the type's descriptor.
+	TypeID uint32
+	// TypeName is the name of the type.
+	TypeName string
+	// Members holds the members of the ENUM. It is a pointer so that TEnum
+	// remains comparable with ==, although two TEnums for the same type
+	// need not be equal; use Equivalent instead.
+	Members *EnumMembers
+}
+
+// EnumMembers holds the members of an ENUM type, in order. The physical
+// representations sort in the same order as the members.
+type EnumMembers struct {
+	LogicalReps  []string
+	PhysicalReps [][]byte
+}
+
+// String implements the fmt.Stringer interface.
+func (t TEnum) String() string {
+	if t.TypeID == 0 {
+		return "anyenum"
+	}
+	return t.TypeName
+}
+
+// Equivalent implements the Type interface.
+func (t TEnum) Equivalent(other Type) bool {
+	if other == TypeAny {
+		return true
+	}
+	u, ok := UnwrapType(other).(TEnum)
+	if ok {
+		return t.TypeID == 0 || u.TypeID == 0 || t.TypeID == u.TypeID
+	}
+	return false
+}
+
+// FamilyEqual implements the Type interface.
+func (TEnum) FamilyEqual(other Type) bool {
+	_, ok := UnwrapType(other).(TEnum)
+	return ok
+}
+
+// Size implements the Type interface.
+func (TEnum) Size() (uintptr, bool) {
+	return unsafe.Sizeof(DEnum{}), variableSize
+}
+
+// Oid implements the Type interface.
+func (t TEnum) Oid() oid.Oid {
+	if t.TypeID == 0 {
+		return oid.T_anyenum
+	}
+	return oid.Oid(t.TypeID + UserDefinedTypeOIDOffset)
+}
+
+// SQLName implements the Type interface.
+func (t TEnum) SQLName() string {
+	if t.TypeID == 0 {
+		return "anyenum"
+	}
+	return t.TypeName
+}
+
+// IsAmbiguous implements the Type interface.
+func (t TEnum) IsAmbiguous() bool {
+	return t.TypeID == 0
+}
+
 type tBytes struct{}
 
 func (tBytes) String() string              { return "bytes" }
@@ -351,6 +438,28 @@ func (tDate) Oid() oid.Oid                { return oid.T_date }
 func (tDate) SQLName() string             { return "date" }
 func (tDate) IsAmbiguous() bool           { return false }
 
+type tTime struct{}
+
+func (tTime) String() string              { return "time" }
+func (tTime) Equivalent(other Type) bool  { return UnwrapType(other) == TypeTime || other == TypeAny }
+func (tTime) FamilyEqual(other Type) bool { return UnwrapType(other) == TypeTime }
+func (tTime) Size() (uintptr, bool)       { return unsafe.Sizeof(DTime(0)), fixedSize }
+func (tTime) Oid() oid.Oid                { return oid.T_time }
+func (tTime) SQLName() string             { return "time without time zone" }
+func (tTime) IsAmbiguous() bool           { return false }
+
+type tTimeTZ struct{}
+
+func (tTimeTZ) String() string { return "timetz" }
+func (tTimeTZ) Equivalent(other Type) bool {
+	return UnwrapType(other) == TypeTimeTZ || other == TypeAny
+}
+func (tTimeTZ) FamilyEqual(other Type) bool { return UnwrapType(other) == TypeTimeTZ }
+func (tTimeTZ) Size() (uintptr, bool)       { return unsafe.Sizeof(DTimeTZ{}), fixedSize }
+func (tTimeTZ) Oid() oid.Oid                { return oid.T_timetz }
+func (tTimeTZ) SQLName() string             { return "time with time zone" }
+func (tTimeTZ) IsAmbiguous() bool           { return false }
+
 type tTimestamp struct{}
 
 func (tTimestamp) String() string { return "timestamp" }
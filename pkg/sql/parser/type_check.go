@@ -39,6 +39,10 @@ type SemaContext struct {
 	// already.
 	SearchPath SearchPath
 
+	// TypeResolver, if set, is used to look up user-defined types referenced
+	// by name.
+	TypeResolver TypeResolver
+
 	// privileged, if true, enables "unsafe" builtins, e.g. those
 	// from the crdb_internal namespace. Must be set only for
 	// the root user.
@@ -46,6 +50,12 @@ type SemaContext struct {
 	privileged bool
 }
 
+// TypeResolver looks up user-defined types by name.
+type TypeResolver interface {
+	// ResolveEnumType returns the ENUM type with the given name.
+	ResolveEnumType(name Name) (TEnum, error)
+}
+
 // MakeSemaContext initializes a simple SemaContext suitable
 // for "lightweight" type checking such as the one performed for default
 // expressions.
@@ -221,6 +231,9 @@ func (expr *CaseExpr) TypeCheck(ctx *SemaContext, desired Type) (TypedExpr, erro
 
 // TypeCheck implements the Expr interface.
 func (expr *CastExpr) TypeCheck(ctx *SemaContext, _ Type) (TypedExpr, error) {
+	if err := resolveCastTarget(ctx, expr.Type); err != nil {
+		return nil, err
+	}
 	returnType := expr.castType()
 
 	// The desired type provided to a CastExpr is ignored. Instead,
@@ -301,6 +314,9 @@ func (expr *IndirectionExpr) TypeCheck(ctx *SemaContext, desired Type) (TypedExp
 
 // TypeCheck implements the Expr interface.
 func (expr *AnnotateTypeExpr) TypeCheck(ctx *SemaContext, desired Type) (TypedExpr, error) {
+	if err := resolveCastTarget(ctx, expr.Type); err != nil {
+		return nil, err
+	}
 	annotType := expr.annotationType()
 	subExpr, err := typeCheckAndRequire(ctx, expr.Expr, annotType,
 		fmt.Sprintf("type annotation for %v as %s, found", expr.Expr, annotType))
@@ -878,6 +894,18 @@ func (d *DIPAddr) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return
 // identity function for Datum.
 func (d *DDate) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d, nil }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTime) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d, nil }
+
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTimeTZ) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d, nil }
+
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DEnum) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d, nil }
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DTimestamp) TypeCheck(_ *SemaContext, _ Type) (TypedExpr, error) { return d, nil }
@@ -1160,9 +1188,10 @@ func typeCheckComparisonOp(
 	// Throw a typing error if overload resolution found either no compatible candidates
 	// or if it found an ambiguity.
 	collationMismatch := leftReturn.FamilyEqual(TypeCollatedString) && !leftReturn.Equivalent(rightReturn)
-	if len(fns) != 1 || collationMismatch {
+	enumMismatch := leftReturn.FamilyEqual(TypeAnyEnum) && !leftReturn.Equivalent(rightReturn)
+	if len(fns) != 1 || collationMismatch || enumMismatch {
 		sig := fmt.Sprintf(compSignatureFmt, leftReturn, op, rightReturn)
-		if len(fns) == 0 || collationMismatch {
+		if len(fns) == 0 || collationMismatch || enumMismatch {
 			return nil, nil, CmpOp{},
 				pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, unsupportedCompErrFmt, sig)
 		}
@@ -1546,6 +1575,11 @@ func (v *placeholderAnnotationVisitor) VisitPre(expr Expr) (recurse bool, newExp
 		}
 	case *CastExpr:
 		if arg, ok := t.Expr.(*Placeholder); ok {
+			if _, ok := t.Type.(*EnumColType); ok {
+				// User-defined types are not resolved yet. The placeholder is
+				// typed as a STRING instead, and cast during evaluation.
+				return false, expr
+			}
 			castType := t.castType()
 			if state, ok := v.placeholders[arg.Name]; ok {
 				// Ignore casts once an assertion has been seen.
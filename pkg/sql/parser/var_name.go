@@ -146,6 +146,12 @@ type ColumnItem struct {
 	// Selector defines which sub-part of the variable is being
 	// accessed.
 	Selector NameParts
+
+	// ForUpdateOrDelete indicates that the reference was generated by the
+	// planner to fetch the rows modified by an UPDATE or DELETE statement.
+	// Column privileges are not checked for such references: the
+	// statement checks the privileges it requires itself.
+	ForUpdateOrDelete bool
 }
 
 // Format implements the NodeFormatter interface.
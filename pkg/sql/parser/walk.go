@@ -546,6 +546,15 @@ func (expr *DString) Walk(_ Visitor) Expr { return expr }
 // Walk implements the Expr interface.
 func (expr *DCollatedString) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr *DTime) Walk(_ Visitor) Expr { return expr }
+
+// Walk implements the Expr interface.
+func (expr *DTimeTZ) Walk(_ Visitor) Expr { return expr }
+
+// Walk implements the Expr interface.
+func (expr *DEnum) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr *DTimestamp) Walk(_ Visitor) Expr { return expr }
 
@@ -913,6 +922,7 @@ func (stmt *Select) WalkStmt(v Visitor) Statement {
 // CopyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *SelectClause) CopyNode() *SelectClause {
 	stmtCopy := *stmt
+	stmtCopy.DistinctOn = append(DistinctOn(nil), stmt.DistinctOn...)
 	stmtCopy.Exprs = append(SelectExprs(nil), stmt.Exprs...)
 	stmtCopy.From = &From{
 		Tables: append(TableExprs(nil), stmt.From.Tables...),
@@ -934,6 +944,16 @@ func (stmt *SelectClause) CopyNode() *SelectClause {
 func (stmt *SelectClause) WalkStmt(v Visitor) Statement {
 	ret := stmt
 
+	for i, expr := range stmt.DistinctOn {
+		e, changed := WalkExpr(v, expr)
+		if changed {
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
+			ret.DistinctOn[i] = e
+		}
+	}
+
 	for i, expr := range stmt.Exprs {
 		e, changed := WalkExpr(v, expr.Expr)
 		if changed {
@@ -0,0 +1,208 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// createPartitioning constructs the partitioning descriptor of an index from
+// its PARTITION BY clause. The partitioning columns must be a prefix of the
+// index columns; the partition values are evaluated and stored key-encoded.
+func createPartitioning(
+	evalCtx *parser.EvalContext,
+	searchPath parser.SearchPath,
+	desc *sqlbase.TableDescriptor,
+	idx *sqlbase.IndexDescriptor,
+	partBy *parser.PartitionBy,
+) (sqlbase.PartitioningDescriptor, error) {
+	var part sqlbase.PartitioningDescriptor
+	if len(idx.Interleave.Ancestors) > 0 {
+		return part, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"interleaved indexes cannot be partitioned")
+	}
+	if len(partBy.Fields) > len(idx.ColumnNames) {
+		return part, pgerror.NewErrorf(pgerror.CodeInvalidObjectDefinitionError,
+			"declared partition columns (%s) exceed the number of columns in index %q (%s)",
+			quoteNames(partBy.Fields.ToStrings()...), idx.Name, quoteNames(idx.ColumnNames...))
+	}
+	for i, field := range partBy.Fields {
+		if string(field) != idx.ColumnNames[i] {
+			return part, pgerror.NewErrorf(pgerror.CodeInvalidObjectDefinitionError,
+				"declared partition columns (%s) do not match first %d columns in index %q (%s)",
+				quoteNames(partBy.Fields.ToStrings()...), len(partBy.Fields), idx.Name,
+				quoteNames(idx.ColumnNames[:len(partBy.Fields)]...))
+		}
+	}
+	numColumns := len(partBy.Fields)
+	part.NumColumns = uint32(numColumns)
+
+	for _, l := range partBy.List {
+		p := sqlbase.PartitioningDescriptor_List{Name: string(l.Name)}
+		for _, expr := range l.Exprs {
+			// A multi-column partition lists its values as tuples.
+			exprs := parser.Exprs{expr}
+			if numColumns > 1 {
+				tuple, ok := expr.(*parser.Tuple)
+				if !ok {
+					return part, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+						"partition %q: expected a tuple of %d values, got %s",
+						l.Name, numColumns, expr)
+				}
+				exprs = tuple.Exprs
+			}
+			encoded, err := encodePartitionValues(evalCtx, searchPath, desc, idx, numColumns, l.Name, exprs)
+			if err != nil {
+				return part, err
+			}
+			p.Values = append(p.Values, encoded)
+		}
+		part.List = append(part.List, p)
+	}
+
+	for _, r := range partBy.Range {
+		from, err := encodePartitionValues(evalCtx, searchPath, desc, idx, numColumns, r.Name, r.From)
+		if err != nil {
+			return part, err
+		}
+		to, err := encodePartitionValues(evalCtx, searchPath, desc, idx, numColumns, r.Name, r.To)
+		if err != nil {
+			return part, err
+		}
+		part.Range = append(part.Range, sqlbase.PartitioningDescriptor_Range{
+			Name:          string(r.Name),
+			FromInclusive: from,
+			ToExclusive:   to,
+		})
+	}
+
+	return part, nil
+}
+
+// encodePartitionValues evaluates the values of one partition tuple, which
+// must be constant expressions of the types of the partitioning columns, and
+// key-encodes them.
+func encodePartitionValues(
+	evalCtx *parser.EvalContext,
+	searchPath parser.SearchPath,
+	desc *sqlbase.TableDescriptor,
+	idx *sqlbase.IndexDescriptor,
+	numColumns int,
+	partName parser.Name,
+	exprs parser.Exprs,
+) ([]byte, error) {
+	if len(exprs) != numColumns {
+		return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"partition %q: expected %d values, got %d", partName, numColumns, len(exprs))
+	}
+	datums := make(parser.Datums, numColumns)
+	for i, expr := range exprs {
+		col, err := desc.FindActiveColumnByID(idx.ColumnIDs[i])
+		if err != nil {
+			return nil, err
+		}
+		typedExpr, err := sqlbase.SanitizeVarFreeExpr(
+			expr, col.Type.ToDatumType(), "partition values", searchPath)
+		if err != nil {
+			return nil, err
+		}
+		if datums[i], err = typedExpr.Eval(evalCtx); err != nil {
+			return nil, err
+		}
+		if datums[i] == parser.DNull {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidObjectDefinitionError,
+				"partition %q: NULL values are not allowed", partName)
+		}
+	}
+	return sqlbase.EncodePartitionTuple(idx, datums)
+}
+
+// showCreatePartitioning returns a PARTITION BY clause for the specified
+// index, if applicable.
+func showCreatePartitioning(
+	a *sqlbase.DatumAlloc,
+	desc *sqlbase.TableDescriptor,
+	idx *sqlbase.IndexDescriptor,
+	buf *bytes.Buffer,
+) error {
+	part := &idx.Partitioning
+	if part.NumColumns == 0 {
+		return nil
+	}
+	numColumns := int(part.NumColumns)
+	formatTuple := func(encoded []byte) (string, error) {
+		datums, err := sqlbase.DecodePartitionTuple(a, desc, idx, numColumns, encoded)
+		if err != nil {
+			return "", err
+		}
+		var tupleBuf bytes.Buffer
+		for i, d := range datums {
+			if i > 0 {
+				tupleBuf.WriteString(", ")
+			}
+			d.Format(&tupleBuf, parser.FmtSimple)
+		}
+		return tupleBuf.String(), nil
+	}
+
+	fields := quoteNames(idx.ColumnNames[:numColumns]...)
+	if len(part.List) > 0 {
+		fmt.Fprintf(buf, " PARTITION BY LIST (%s) (", fields)
+		for i, l := range part.List {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			fmt.Fprintf(buf, "\n\tPARTITION %s VALUES IN (", quoteNames(l.Name))
+			for j, v := range l.Values {
+				if j > 0 {
+					buf.WriteString(", ")
+				}
+				tuple, err := formatTuple(v)
+				if err != nil {
+					return err
+				}
+				if numColumns > 1 {
+					tuple = "(" + tuple + ")"
+				}
+				buf.WriteString(tuple)
+			}
+			buf.WriteString(")")
+		}
+	} else {
+		fmt.Fprintf(buf, " PARTITION BY RANGE (%s) (", fields)
+		for i, r := range part.Range {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			from, err := formatTuple(r.FromInclusive)
+			if err != nil {
+				return err
+			}
+			to, err := formatTuple(r.ToExclusive)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(buf, "\n\tPARTITION %s VALUES FROM (%s) TO (%s)",
+				quoteNames(r.Name), from, to)
+		}
+	}
+	buf.WriteString("\n)")
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/lib/pq/oid"
@@ -54,6 +55,7 @@ var pgCatalog = virtualSchema{
 		pgCatalogAmTable,
 		pgCatalogAttrDefTable,
 		pgCatalogAttributeTable,
+		pgCatalogAuthMembersTable,
 		pgCatalogClassTable,
 		pgCatalogCollationTable,
 		pgCatalogConstraintTable,
@@ -68,6 +70,7 @@ var pgCatalog = virtualSchema{
 		pgCatalogIndexesTable,
 		pgCatalogInheritsTable,
 		pgCatalogNamespaceTable,
+		pgCatalogPoliciesTable,
 		pgCatalogProcTable,
 		pgCatalogRangeTable,
 		pgCatalogRolesTable,
@@ -127,8 +130,8 @@ CREATE TABLE pg_catalog.pg_attrdef (
 					h.ColumnOid(db, table, column),      // oid
 					h.TableOid(db, table),               // adrelid
 					parser.NewDInt(parser.DInt(colNum)), // adnum
-					defSrc, // adbin
-					defSrc, // adsrc
+					defSrc,                              // adbin
+					defSrc,                              // adsrc
 				)
 			})
 		})
@@ -175,12 +178,12 @@ CREATE TABLE pg_catalog.pg_attribute (
 					zeroVal,                             // attstattarget
 					typLen(colTyp),                      // attlen
 					parser.NewDInt(parser.DInt(colNum)), // attnum
-					zeroVal,      // attndims
-					negOneVal,    // attcacheoff
-					negOneVal,    // atttypmod
-					parser.DNull, // attbyval (see pg_type.typbyval)
-					parser.DNull, // attstorage
-					parser.DNull, // attalign
+					zeroVal,                             // attndims
+					negOneVal,                           // attcacheoff
+					negOneVal,                           // atttypmod
+					parser.DNull,                        // attbyval (see pg_type.typbyval)
+					parser.DNull,                        // attstorage
+					parser.DNull,                        // attalign
 					parser.MakeDBool(parser.DBool(!column.Nullable)),          // attnotnull
 					parser.MakeDBool(parser.DBool(column.DefaultExpr != nil)), // atthasdef
 					parser.MakeDBool(false),                                   // attisdropped
@@ -219,13 +222,48 @@ CREATE TABLE pg_catalog.pg_attribute (
 }
 
 var (
-	relKindTable = parser.NewDString("r")
-	relKindIndex = parser.NewDString("i")
-	relKindView  = parser.NewDString("v")
+	relKindTable            = parser.NewDString("r")
+	relKindIndex            = parser.NewDString("i")
+	relKindView             = parser.NewDString("v")
+	relKindMaterializedView = parser.NewDString("m")
 
 	relPersistencePermanent = parser.NewDString("p")
 )
 
+// See: https://www.postgresql.org/docs/9.6/static/catalog-pg-auth-members.html.
+var pgCatalogAuthMembersTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE pg_catalog.pg_auth_members (
+	roleid OID,
+	member OID,
+	grantor OID,
+	admin_option BOOL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...parser.Datum) error) error {
+		// As with pg_roles, the memberships are visible to all users, so
+		// system.role_members is read as root.
+		h := makeOidHasher()
+		ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+		rows, err := ie.QueryRowsInTransaction(ctx, "pg-auth-members", p.txn,
+			`SELECT "role", member, "isAdmin" FROM system.role_members`)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := addRow(
+				h.UserOid(string(parser.MustBeDString(row[0]))), // roleid
+				h.UserOid(string(parser.MustBeDString(row[1]))), // member
+				parser.DNull, // grantor
+				row[2],       // admin_option
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // See: https://www.postgresql.org/docs/9.6/static/catalog-pg-class.html.
 var pgCatalogClassTable = virtualSchemaTable{
 	schema: `
@@ -264,7 +302,9 @@ CREATE TABLE pg_catalog.pg_class (
 		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
 			// Table.
 			relKind := relKindTable
-			if table.IsView() {
+			if table.IsMaterializedView() {
+				relKind = relKindMaterializedView
+			} else if table.IsView() {
 				// The only difference between tables and views is the relkind column.
 				relKind = relKindView
 			}
@@ -515,12 +555,12 @@ CREATE TABLE pg_catalog.pg_constraint (
 				}
 
 				if err := addRow(
-					oid,                                            // oid
-					dNameOrNull(name),                              // conname
-					pgNamespaceForDB(db, h).Oid,                    // connamespace
-					contype,                                        // contype
-					parser.MakeDBool(false),                        // condeferrable
-					parser.MakeDBool(false),                        // condeferred
+					oid,                         // oid
+					dNameOrNull(name),           // conname
+					pgNamespaceForDB(db, h).Oid, // connamespace
+					contype,                     // contype
+					parser.MakeDBool(false),     // condeferrable
+					parser.MakeDBool(false),     // condeferred
 					parser.MakeDBool(parser.DBool(!c.Unvalidated)), // convalidated
 					h.TableOid(db, table),                          // conrelid
 					oidZero,                                        // contypid
@@ -753,9 +793,25 @@ CREATE TABLE pg_catalog.pg_enum (
   enumlabel STRING
 );
 `,
-	populate: func(_ context.Context, p *planner, _ string, addRow func(...parser.Datum) error) error {
-		// Enum types are not currently supported.
-		return nil
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...parser.Datum) error) error {
+		h := makeOidHasher()
+		return forEachTypeDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, typ *sqlbase.TypeDescriptor) error {
+			enumTypID := typOid(typ.EnumType())
+			for i := range typ.EnumMembers {
+				member := &typ.EnumMembers[i]
+				sortOrder := parser.NewDFloat(parser.DFloat(i + 1))
+				label := parser.NewDString(member.LogicalRepresentation)
+				if err := addRow(
+					h.EnumMemberOid(typ, member), // oid
+					enumTypID,                    // enumtypid
+					sortOrder,                    // enumsortorder
+					label,                        // enumlabel
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	},
 }
 
@@ -1140,35 +1196,35 @@ CREATE TABLE pg_catalog.pg_proc (
 				}
 				err := addRow(
 					h.BuiltinOid(name, &builtin), // oid
-					dName,                                             // proname
-					nspOid,                                            // pronamespace
-					parser.DNull,                                      // proowner
-					oidZero,                                           // prolang
-					parser.DNull,                                      // procost
-					parser.DNull,                                      // prorows
-					variadicType,                                      // provariadic
-					parser.DNull,                                      // protransform
-					parser.MakeDBool(parser.DBool(isAggregate)),       // proisagg
-					parser.MakeDBool(parser.DBool(isWindow)),          // proiswindow
-					parser.MakeDBool(false),                           // prosecdef
-					parser.MakeDBool(parser.DBool(!builtin.Impure())), // proleakproof
-					parser.MakeDBool(false),                           // proisstrict
-					parser.MakeDBool(parser.DBool(isRetSet)),          // proretset
-					parser.DNull,                                      // provolatile
-					parser.DNull,                                      // proparallel
+					dName,                        // proname
+					nspOid,                       // pronamespace
+					parser.DNull,                 // proowner
+					oidZero,                      // prolang
+					parser.DNull,                 // procost
+					parser.DNull,                 // prorows
+					variadicType,                 // provariadic
+					parser.DNull,                 // protransform
+					parser.MakeDBool(parser.DBool(isAggregate)),         // proisagg
+					parser.MakeDBool(parser.DBool(isWindow)),            // proiswindow
+					parser.MakeDBool(false),                             // prosecdef
+					parser.MakeDBool(parser.DBool(!builtin.Impure())),   // proleakproof
+					parser.MakeDBool(false),                             // proisstrict
+					parser.MakeDBool(parser.DBool(isRetSet)),            // proretset
+					parser.DNull,                                        // provolatile
+					parser.DNull,                                        // proparallel
 					parser.NewDInt(parser.DInt(builtin.Types.Length())), // pronargs
 					parser.NewDInt(parser.DInt(0)),                      // pronargdefaults
-					retType, // prorettype
-					parser.NewDString(dArgTypeString), // proargtypes
-					parser.DNull,                      // proallargtypes
-					argmodes,                          // proargmodes
-					parser.DNull,                      // proargnames
-					parser.DNull,                      // proargdefaults
-					parser.DNull,                      // protrftypes
-					dSrc,                              // prosrc
-					parser.DNull,                      // probin
-					parser.DNull,                      // proconfig
-					parser.DNull,                      // proacl
+					retType,                                             // prorettype
+					parser.NewDString(dArgTypeString),                   // proargtypes
+					parser.DNull,                                        // proallargtypes
+					argmodes,                                            // proargmodes
+					parser.DNull,                                        // proargnames
+					parser.DNull,                                        // proargdefaults
+					parser.DNull,                                        // protrftypes
+					dSrc,                                                // prosrc
+					parser.DNull,                                        // probin
+					parser.DNull,                                        // proconfig
+					parser.DNull,                                        // proacl
 				)
 				if err != nil {
 					return err
@@ -1223,22 +1279,25 @@ CREATE TABLE pg_catalog.pg_roles (
 		// need to do the same. This shouldn't be an issue, because pg_roles doesn't
 		// include sensitive information such as password hashes.
 		h := makeOidHasher()
-		return forEachUser(ctx, p,
-			func(username string) error {
+		return forEachRole(ctx, p,
+			func(username string, isRole bool, validUntil parser.Datum) error {
 				isRoot := parser.DBool(username == security.RootUser)
+				if ts, ok := validUntil.(*parser.DTimestamp); ok {
+					validUntil = parser.MakeDTimestampTZ(ts.Time, time.Microsecond)
+				}
 				return addRow(
-					h.UserOid(username),           // oid
-					parser.NewDName(username),     // rolname
-					parser.MakeDBool(isRoot),      // rolsuper
-					parser.MakeDBool(false),       // rolinherit
-					parser.MakeDBool(isRoot),      // rolcreaterole
-					parser.MakeDBool(isRoot),      // rolcreatedb
-					parser.MakeDBool(false),       // rolcatupdate
-					parser.MakeDBool(true),        // rolcanlogin
-					negOneVal,                     // rolconnlimit
-					parser.NewDString("********"), // rolpassword
-					parser.DNull,                  // rolvaliduntil
-					parser.NewDString("{}"),       // rolconfig
+					h.UserOid(username),                     // oid
+					parser.NewDName(username),               // rolname
+					parser.MakeDBool(isRoot),                // rolsuper
+					parser.MakeDBool(true),                  // rolinherit
+					parser.MakeDBool(isRoot),                // rolcreaterole
+					parser.MakeDBool(isRoot),                // rolcreatedb
+					parser.MakeDBool(false),                 // rolcatupdate
+					parser.MakeDBool(parser.DBool(!isRole)), // rolcanlogin
+					negOneVal,                               // rolconnlimit
+					parser.NewDString("********"),           // rolpassword
+					validUntil,                              // rolvaliduntil
+					parser.NewDString("{}"),                 // rolconfig
 				)
 			})
 	},
@@ -1303,6 +1362,51 @@ CREATE TABLE pg_catalog.pg_settings (
 	},
 }
 
+// See: https://www.postgresql.org/docs/9.6/static/view-pg-policies.html.
+var pgCatalogPoliciesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE pg_catalog.pg_policies (
+	schemaname NAME,
+	tablename NAME,
+	policyname NAME,
+	roles STRING[],
+	cmd TEXT,
+	qual TEXT,
+	with_check TEXT
+);
+`,
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...parser.Datum) error) error {
+		all := parser.NewDString("ALL")
+		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+			for _, policy := range table.Policies {
+				roles := parser.NewDArray(parser.TypeString)
+				if len(policy.Roles) == 0 {
+					if err := roles.Append(parser.NewDString("public")); err != nil {
+						return err
+					}
+				}
+				for _, role := range policy.Roles {
+					if err := roles.Append(parser.NewDString(role)); err != nil {
+						return err
+					}
+				}
+				if err := addRow(
+					parser.NewDName(db.Name),           // schemaname
+					parser.NewDName(table.Name),        // tablename
+					parser.NewDName(policy.Name),       // policyname
+					roles,                              // roles
+					all,                                // cmd
+					dStringPtrOrNull(policy.UsingExpr), // qual
+					dStringPtrOrNull(policy.CheckExpr), // with_check
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	},
+}
+
 // See: https://www.postgresql.org/docs/9.6/static/view-pg-tables.html.
 var pgCatalogTablesTable = virtualSchemaTable{
 	schema: `
@@ -1330,7 +1434,7 @@ CREATE TABLE pg_catalog.pg_tables (
 				parser.MakeDBool(parser.DBool(table.IsPhysicalTable())), // hasindexes
 				parser.MakeDBool(false),                                 // hasrules
 				parser.MakeDBool(false),                                 // hastriggers
-				parser.MakeDBool(false),                                 // rowsecurity
+				parser.MakeDBool(parser.DBool(table.RowLevelSecurity)),  // rowsecurity
 			)
 		})
 	},
@@ -1350,7 +1454,7 @@ CREATE TABLE pg_catalog.pg_tablespace (
 `,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...parser.Datum) error) error {
 		return addRow(
-			oidZero, // oid
+			oidZero,                         // oid
 			parser.NewDString("pg_default"), // spcname
 			parser.DNull,                    // spcowner
 			parser.DNull,                    // spclocation
@@ -1371,7 +1475,6 @@ var (
 	// Avoid unused warning for constants.
 	_ = typTypeComposite
 	_ = typTypeDomain
-	_ = typTypeEnum
 	_ = typTypePseudo
 	_ = typTypeRange
 
@@ -1395,7 +1498,6 @@ var (
 	// Avoid unused warning for constants.
 	_ = typCategoryArray
 	_ = typCategoryComposite
-	_ = typCategoryEnum
 	_ = typCategoryGeometric
 	_ = typCategoryNetworkAddr
 	_ = typCategoryPseudo
@@ -1443,7 +1545,7 @@ CREATE TABLE pg_catalog.pg_type (
 	typacl STRING
 );
 `,
-	populate: func(_ context.Context, p *planner, _ string, addRow func(...parser.Datum) error) error {
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...parser.Datum) error) error {
 		h := makeOidHasher()
 		for o, typ := range parser.OidToType {
 			cat := typCategory(typ)
@@ -1474,21 +1576,21 @@ CREATE TABLE pg_catalog.pg_type (
 				typByVal(typ),                  // typbyval
 				typTypeBase,                    // typtype
 				cat,                            // typcategory
-				parser.MakeDBool(false), // typispreferred
-				parser.MakeDBool(true),  // typisdefined
-				typDelim,                // typdelim
-				oidZero,                 // typrelid
-				typElem,                 // typelem
-				oidZero,                 // typarray
+				parser.MakeDBool(false),        // typispreferred
+				parser.MakeDBool(true),         // typisdefined
+				typDelim,                       // typdelim
+				oidZero,                        // typrelid
+				typElem,                        // typelem
+				oidZero,                        // typarray
 
 				// regproc references
 				h.RegProc(builtinPrefix+"in"),   // typinput
 				h.RegProc(builtinPrefix+"out"),  // typoutput
 				h.RegProc(builtinPrefix+"recv"), // typreceive
 				h.RegProc(builtinPrefix+"send"), // typsend
-				oidZero, // typmodin
-				oidZero, // typmodout
-				oidZero, // typanalyze
+				oidZero,                         // typmodin
+				oidZero,                         // typmodout
+				oidZero,                         // typanalyze
 
 				parser.DNull,            // typalign
 				parser.DNull,            // typstorage
@@ -1504,7 +1606,44 @@ CREATE TABLE pg_catalog.pg_type (
 				return err
 			}
 		}
-		return nil
+
+		// User-defined types.
+		return forEachTypeDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, desc *sqlbase.TypeDescriptor) error {
+			typ := desc.EnumType()
+			return addRow(
+				typOid(typ),                 // oid
+				parser.NewDName(desc.Name),  // typname
+				pgNamespaceForDB(db, h).Oid, // typnamespace
+				parser.DNull,                // typowner
+				typLen(typ),                 // typlen
+				typByVal(typ),               // typbyval
+				typTypeEnum,                 // typtype
+				typCategoryEnum,             // typcategory
+				parser.MakeDBool(false),     // typispreferred
+				parser.MakeDBool(true),      // typisdefined
+				typDelim,                    // typdelim
+				oidZero,                     // typrelid
+				oidZero,                     // typelem
+				oidZero,                     // typarray
+				h.RegProc("enum_in"),        // typinput
+				h.RegProc("enum_out"),       // typoutput
+				h.RegProc("enum_recv"),      // typreceive
+				h.RegProc("enum_send"),      // typsend
+				oidZero,                     // typmodin
+				oidZero,                     // typmodout
+				oidZero,                     // typanalyze
+				parser.DNull,                // typalign
+				parser.DNull,                // typstorage
+				parser.MakeDBool(false),     // typnotnull
+				oidZero,                     // typbasetype
+				negOneVal,                   // typtypmod
+				zeroVal,                     // typndims
+				oidZero,                     // typcollation
+				parser.DNull,                // typdefaultbin
+				parser.DNull,                // typdefault
+				parser.DNull,                // typacl
+			)
+		})
 	},
 }
 
@@ -1552,6 +1691,8 @@ var datumToTypeCategory = map[reflect.Type]*parser.DString{
 	reflect.TypeOf(parser.TypeInterval):    typCategoryTimespan,
 	reflect.TypeOf(parser.TypeDecimal):     typCategoryNumeric,
 	reflect.TypeOf(parser.TypeString):      typCategoryString,
+	reflect.TypeOf(parser.TypeTime):        typCategoryDateTime,
+	reflect.TypeOf(parser.TypeTimeTZ):      typCategoryDateTime,
 	reflect.TypeOf(parser.TypeTimestamp):   typCategoryDateTime,
 	reflect.TypeOf(parser.TypeTimestampTZ): typCategoryDateTime,
 	reflect.TypeOf(parser.TypeTuple):       typCategoryPseudo,
@@ -1559,6 +1700,7 @@ var datumToTypeCategory = map[reflect.Type]*parser.DString{
 	reflect.TypeOf(parser.TypeOid):         typCategoryNumeric,
 	reflect.TypeOf(parser.TypeUUID):        typCategoryUserDefined,
 	reflect.TypeOf(parser.TypeINet):        typCategoryNetworkAddr,
+	reflect.TypeOf(parser.TypeAnyEnum):     typCategoryEnum,
 }
 
 func typCategory(typ parser.Type) parser.Datum {
@@ -1612,16 +1754,15 @@ CREATE TABLE pg_catalog.pg_views (
 // are unique across all objects and that they are stable across accesses.
 //
 // The type has a few layers of methods:
-// - write<go_type> methods write concrete types to the underlying running hash.
-// - write<db_object> methods account for single database objects like TableDescriptors
-//   or IndexDescriptors in the running hash. These methods aim to write information
-//   that would uniquely fingerprint the object to the hash using the first layer of
-//   methods.
-// - <DB_Object>Oid methods use the second layer of methods to construct a unique
-//   object identifier for the provided database object. This object identifier will
-//   be returned as a *parser.DInt, and the running hash will be reset. These are the
-//   only methods that are part of the oidHasher's external facing interface.
-//
+//   - write<go_type> methods write concrete types to the underlying running hash.
+//   - write<db_object> methods account for single database objects like TableDescriptors
+//     or IndexDescriptors in the running hash. These methods aim to write information
+//     that would uniquely fingerprint the object to the hash using the first layer of
+//     methods.
+//   - <DB_Object>Oid methods use the second layer of methods to construct a unique
+//     object identifier for the provided database object. This object identifier will
+//     be returned as a *parser.DInt, and the running hash will be reset. These are the
+//     only methods that are part of the oidHasher's external facing interface.
 type oidHasher struct {
 	h hash.Hash32
 }
@@ -1664,6 +1805,7 @@ const (
 	functionTypeTag
 	userTypeTag
 	collationTypeTag
+	enumMemberTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -1810,6 +1952,15 @@ func (h oidHasher) UserOid(username string) *parser.DOid {
 	return h.getOid()
 }
 
+func (h oidHasher) EnumMemberOid(
+	typ *sqlbase.TypeDescriptor, member *sqlbase.EnumMember,
+) *parser.DOid {
+	h.writeTypeTag(enumMemberTypeTag)
+	h.writeUInt32(uint32(typ.ID))
+	h.writeStr(member.LogicalRepresentation)
+	return h.getOid()
+}
+
 func (h oidHasher) CollationOid(collation string) *parser.DOid {
 	h.writeTypeTag(collationTypeTag)
 	h.writeStr(collation)
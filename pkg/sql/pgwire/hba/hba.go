@@ -0,0 +1,253 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package hba implements a host-based authentication configuration in the
+// style of Postgres' pg_hba.conf.
+//
+// A configuration is a list of rules, one per line:
+//
+//   TYPE  DATABASE  USER  [ADDRESS]  METHOD
+//
+// TYPE is one of local (unix socket connections), host (any TCP
+// connection), hostssl (TCP connections using TLS) or hostnossl (TCP
+// connections not using TLS). DATABASE and USER are comma-separated lists
+// of names, or "all". ADDRESS is only given for the host types and is
+// either a CIDR address, such as 10.0.0.0/8, or "all". METHOD is one of the
+// Method constants. Text following a "#" is a comment.
+//
+// Rules are evaluated in order and the first one matching a connection
+// decides how it authenticates.
+package hba
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
+)
+
+// ConnType is a set of connection types a rule applies to.
+type ConnType int
+
+const (
+	// ConnLocal is a connection over a unix socket.
+	ConnLocal ConnType = 1 << iota
+	// ConnHostNoSSL is a TCP connection not using TLS.
+	ConnHostNoSSL
+	// ConnHostSSL is a TCP connection using TLS.
+	ConnHostSSL
+	// ConnHost is any TCP connection.
+	ConnHost = ConnHostNoSSL | ConnHostSSL
+)
+
+var connTypes = map[string]ConnType{
+	"local":     ConnLocal,
+	"host":      ConnHost,
+	"hostssl":   ConnHostSSL,
+	"hostnossl": ConnHostNoSSL,
+}
+
+// Method is an authentication method.
+type Method string
+
+const (
+	// MethodCertPassword authenticates with a client certificate if one is
+	// presented, and with a password otherwise. It is the method used for
+	// connections when no configuration is set.
+	MethodCertPassword Method = "cert-password"
+	// MethodCert requires a client certificate.
+	MethodCert Method = "cert"
+	// MethodPassword requires a password, even if a client certificate is
+	// presented.
+	MethodPassword Method = "password"
+	// MethodTrust accepts the connection without authentication.
+	MethodTrust Method = "trust"
+	// MethodReject rejects the connection.
+	MethodReject Method = "reject"
+)
+
+var methods = map[Method]bool{
+	MethodCertPassword: true,
+	MethodCert:         true,
+	MethodPassword:     true,
+	MethodTrust:        true,
+	MethodReject:       true,
+}
+
+// Entry is a single rule of a configuration.
+type Entry struct {
+	ConnType ConnType
+	// Databases and Users hold normalized names. A nil list matches any
+	// name.
+	Databases []string
+	Users     []string
+	// Address is nil if the rule matches any address.
+	Address *ipaddr.IPAddr
+	Method  Method
+}
+
+// Conf is a parsed host-based authentication configuration.
+type Conf struct {
+	Entries []Entry
+}
+
+// Parse parses a configuration. An empty configuration has no entries.
+func Parse(s string) (*Conf, error) {
+	var conf Conf
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		entry, err := parseEntry(fields)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		conf.Entries = append(conf.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+func parseEntry(fields []string) (Entry, error) {
+	var entry Entry
+	var ok bool
+	if entry.ConnType, ok = connTypes[fields[0]]; !ok {
+		return entry, errors.Errorf("unknown connection type %q", fields[0])
+	}
+	numFields := 5
+	if entry.ConnType == ConnLocal {
+		numFields = 4
+	}
+	if len(fields) != numFields {
+		return entry, errors.Errorf("expected %d fields for connection type %s, found %d",
+			numFields, fields[0], len(fields))
+	}
+	entry.Databases = parseNameList(fields[1])
+	entry.Users = parseNameList(fields[2])
+	if entry.ConnType != ConnLocal && fields[3] != "all" {
+		var addr ipaddr.IPAddr
+		if err := ipaddr.ParseINet(fields[3], &addr); err != nil {
+			return entry, err
+		}
+		entry.Address = &addr
+	}
+	entry.Method = Method(fields[numFields-1])
+	if !methods[entry.Method] {
+		return entry, errors.Errorf("unknown authentication method %q", entry.Method)
+	}
+	return entry, nil
+}
+
+// parseNameList parses a comma-separated list of names, returning nil for
+// "all".
+func parseNameList(s string) []string {
+	if s == "all" {
+		return nil
+	}
+	names := strings.Split(s, ",")
+	for i := range names {
+		names[i] = parser.Name(names[i]).Normalize()
+	}
+	return names
+}
+
+// Find returns the first entry matching a connection. The user and database
+// names must be normalized. addr is ignored for local connections. If no
+// entry matches, an error naming the connection is returned.
+func (c *Conf) Find(connType ConnType, addr net.IP, database, user string) (Entry, error) {
+	var ip ipaddr.IPAddr
+	if connType != ConnLocal {
+		if err := ipaddr.ParseINet(addr.String(), &ip); err != nil {
+			return Entry{}, err
+		}
+	}
+	for _, entry := range c.Entries {
+		if entry.ConnType&connType == 0 ||
+			!matchName(entry.Databases, database) ||
+			!matchName(entry.Users, user) {
+			continue
+		}
+		if connType != ConnLocal && entry.Address != nil && !contains(entry.Address, ip) {
+			continue
+		}
+		return entry, nil
+	}
+	host := "local"
+	if connType != ConnLocal {
+		host = addr.String()
+	}
+	return Entry{}, errors.Errorf("no host-based authentication rule for host %q, user %q, database %q",
+		host, user, database)
+}
+
+func matchName(names []string, name string) bool {
+	if names == nil {
+		return true
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns whether ip is within the network of cidr.
+func contains(cidr *ipaddr.IPAddr, ip ipaddr.IPAddr) bool {
+	if cidr.Family != ip.Family {
+		return false
+	}
+	mask := cidr.Netmask().Addr
+	return cidr.Addr.Hi&mask.Hi == ip.Addr.Hi&mask.Hi &&
+		cidr.Addr.Lo&mask.Lo == ip.Addr.Lo&mask.Lo
+}
+
+// String implements fmt.Stringer.
+func (e Entry) String() string {
+	var connType string
+	for name, t := range connTypes {
+		if t == e.ConnType {
+			connType = name
+		}
+	}
+	s := fmt.Sprintf("%s %s %s", connType, formatNameList(e.Databases), formatNameList(e.Users))
+	if e.ConnType != ConnLocal {
+		if e.Address == nil {
+			s += " all"
+		} else {
+			s += " " + e.Address.String()
+		}
+	}
+	return s + " " + string(e.Method)
+}
+
+func formatNameList(names []string) string {
+	if names == nil {
+		return "all"
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,124 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package hba
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		conf string
+		exp  []string
+		err  string
+	}{
+		{"", nil, ""},
+		{"# comment only\n\n", nil, ""},
+		{
+			"local all all trust\nhost all Root 127.0.0.1/32 cert # root only from localhost",
+			[]string{"local all all trust", "host all root 127.0.0.1 cert"},
+			"",
+		},
+		{
+			"hostssl db1,db2 all 10.0.0.0/8 password\nhostnossl all all all reject",
+			[]string{"hostssl db1,db2 all 10.0.0.0/8 password", "hostnossl all all all reject"},
+			"",
+		},
+		{"host all all ::1/128 cert-password", []string{"host all all ::1 cert-password"}, ""},
+		{"remote all all all trust", nil, `line 1: unknown connection type "remote"`},
+		{"host all all trust", nil, "line 1: expected 5 fields for connection type host, found 4"},
+		{"local all all all trust", nil, "line 1: expected 4 fields for connection type local, found 5"},
+		{"\nhost all all 10.0.0.0/40 cert", nil, "line 2: could not parse"},
+		{"host all all all ident", nil, `line 1: unknown authentication method "ident"`},
+	}
+	for _, tc := range testCases {
+		conf, err := Parse(tc.conf)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%q: expected error %q, got %v", tc.conf, tc.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		var entries []string
+		for _, e := range conf.Entries {
+			entries = append(entries, e.String())
+		}
+		if len(entries) != len(tc.exp) {
+			t.Errorf("%q: expected %q, got %q", tc.conf, tc.exp, entries)
+			continue
+		}
+		for i := range entries {
+			if entries[i] != tc.exp[i] {
+				t.Errorf("%q: expected %q, got %q", tc.conf, tc.exp[i], entries[i])
+			}
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	conf, err := Parse(`
+local     all  all   trust
+host      all  root  127.0.0.1/32 cert
+host      all  root  ::1/128      cert
+host      all  root  all          reject
+hostnossl all  all   all          reject
+hostssl   db   all   10.1.0.0/16  cert
+hostssl   all  all   10.0.0.0/8   password
+hostssl   all  all   2001:db8::/32 cert-password
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		connType ConnType
+		addr     string
+		database string
+		user     string
+		exp      Method
+		err      string
+	}{
+		{ConnLocal, "", "", "root", MethodTrust, ""},
+		{ConnHostSSL, "127.0.0.1", "db", "root", MethodCert, ""},
+		{ConnHostNoSSL, "127.0.0.1", "db", "root", MethodCert, ""},
+		{ConnHostSSL, "::1", "", "root", MethodCert, ""},
+		{ConnHostSSL, "10.1.2.3", "db", "root", MethodReject, ""},
+		{ConnHostNoSSL, "10.1.2.3", "db", "foo", MethodReject, ""},
+		{ConnHostSSL, "10.1.2.3", "db", "foo", MethodCert, ""},
+		{ConnHostSSL, "10.1.2.3", "other", "foo", MethodPassword, ""},
+		{ConnHostSSL, "10.2.2.3", "db", "foo", MethodPassword, ""},
+		{ConnHostSSL, "2001:db8::1", "db", "foo", MethodCertPassword, ""},
+		// IPv4-mapped IPv6 addresses are matched as IPv4 addresses.
+		{ConnHostSSL, "::ffff:10.1.2.3", "db", "foo", MethodCert, ""},
+		{ConnHostSSL, "2001:db9::1", "db", "foo", "",
+			`no host-based authentication rule for host "2001:db9::1", user "foo", database "db"`},
+		{ConnHostSSL, "192.168.0.1", "db", "foo", "",
+			`no host-based authentication rule for host "192.168.0.1", user "foo", database "db"`},
+	}
+	for _, tc := range testCases {
+		entry, err := conf.Find(tc.connType, net.ParseIP(tc.addr), tc.database, tc.user)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%+v: expected error %q, got %v", tc, tc.err, err)
+			continue
+		}
+		if err == nil && entry.Method != tc.exp {
+			t.Errorf("%+v: expected %s, got %s", tc, tc.exp, entry.Method)
+		}
+	}
+}
@@ -1597,6 +1597,9 @@ func TestPGWireAuth(t *testing.T) {
 	defer s.Stopper().Stop(context.TODO())
 	{
 		unicodeUser := "Ὀδυσσεύς"
+		// MD5 password hashes are salted with the username as stored, so
+		// clients must log in with the normalized name.
+		normalizedUnicodeUser := "ὀδυσσεύς"
 
 		t.Run("RootUserAuth", func(t *testing.T) {
 			// Authenticate as root with certificate and expect success.
@@ -1620,6 +1623,14 @@ func TestPGWireAuth(t *testing.T) {
 				t.Fatal(err)
 			}
 
+			// The vendored lib/pq does not support SCRAM-SHA-256, so store the
+			// password as an MD5 hash.
+			if _, err := db.Exec(
+				"SET CLUSTER SETTING server.user_login.password_encryption = 'md5'",
+			); err != nil {
+				t.Fatal(err)
+			}
+
 			if _, err := db.Exec(fmt.Sprintf("CREATE USER %s WITH PASSWORD '蟑♫螂';", unicodeUser)); err != nil {
 				t.Fatal(err)
 			}
@@ -1633,7 +1644,7 @@ func TestPGWireAuth(t *testing.T) {
 			}
 			unicodeUserPgURL := url.URL{
 				Scheme:   "postgres",
-				User:     url.User(unicodeUser),
+				User:     url.User(normalizedUnicodeUser),
 				Host:     net.JoinHostPort(host, port),
 				RawQuery: "sslmode=require",
 			}
@@ -1642,7 +1653,7 @@ func TestPGWireAuth(t *testing.T) {
 			}
 
 			// Supply correct password.
-			unicodeUserPgURL.User = url.UserPassword(unicodeUser, "蟑♫螂")
+			unicodeUserPgURL.User = url.UserPassword(normalizedUnicodeUser, "蟑♫螂")
 			if err := trivialQuery(unicodeUserPgURL); err != nil {
 				t.Fatal(err)
 			}
@@ -1677,6 +1688,111 @@ func TestPGWireAuth(t *testing.T) {
 	})
 }
 
+func TestPGWireHBA(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	rootPgURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanupFn()
+	db, err := gosql.Open("postgres", rootPgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(fmt.Sprintf("CREATE USER %s", server.TestUser)); err != nil {
+		t.Fatal(err)
+	}
+
+	testUserPgURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), t.Name(), url.User(server.TestUser))
+	defer cleanupFn()
+
+	if _, err := db.Exec(
+		`SET CLUSTER SETTING server.host_based_authentication.configuration = 'host all all all ident'`,
+	); !testutils.IsError(err, `unknown authentication method "ident"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		conf string
+		err  string
+	}{
+		// The certificate is ignored when a password is required, and
+		// testuser has none.
+		{"host all root all cert\nhost all testuser all password", "pq: invalid password"},
+		{"host all root all cert\nhost all testuser all reject",
+			"connection rejected by host-based authentication rule"},
+		{"host all root all cert\nhost all testuser 10.0.0.0/8 cert",
+			"no host-based authentication rule for host"},
+		{"host all root all cert\nhostnossl all all all reject\nhost all all all cert", ""},
+		{"", ""},
+	}
+	for _, tc := range testCases {
+		if _, err := db.Exec(
+			`SET CLUSTER SETTING server.host_based_authentication.configuration = $1`, tc.conf,
+		); err != nil {
+			t.Fatal(err)
+		}
+		// Cluster settings are propagated asynchronously.
+		testutils.SucceedsSoon(t, func() error {
+			if err := trivialQuery(testUserPgURL); !testutils.IsError(err, tc.err) {
+				return errors.Errorf("%q: expected error %q, got %v", tc.conf, tc.err, err)
+			}
+			return nil
+		})
+	}
+}
+
+// TestPGWireHBAInsecure checks that the host-based authentication methods
+// are enforced on connections without TLS to an insecure server.
+func TestPGWireHBAInsecure(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(fmt.Sprintf("CREATE USER %s", server.TestUser)); err != nil {
+		t.Fatal(err)
+	}
+
+	testUserPgURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(server.TestUser),
+		Host:     s.ServingAddr(),
+		RawQuery: "sslmode=disable",
+	}
+
+	testCases := []struct {
+		conf string
+		err  string
+	}{
+		{"", ""},
+		{"host all root all trust\nhost all testuser all trust", ""},
+		{"host all root all trust\nhost all testuser all password", "pq: invalid password"},
+		{"host all root all trust\nhost all testuser all cert-password", "pq: invalid password"},
+		{"host all root all trust\nhost all testuser all cert", "client certificate required"},
+		{"host all root all trust\nhost all testuser all reject",
+			"connection rejected by host-based authentication rule"},
+	}
+	for _, tc := range testCases {
+		if _, err := db.Exec(
+			`SET CLUSTER SETTING server.host_based_authentication.configuration = $1`, tc.conf,
+		); err != nil {
+			t.Fatal(err)
+		}
+		// Cluster settings are propagated asynchronously.
+		testutils.SucceedsSoon(t, func() error {
+			if err := trivialQuery(testUserPgURL); !testutils.IsError(err, tc.err) {
+				return errors.Errorf("%q: expected error %q, got %v", tc.conf, tc.err, err)
+			}
+			return nil
+		})
+	}
+}
+
 func TestPGWireResultChange(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -307,6 +308,46 @@ func (s *Server) setDrainingImpl(
 	return nil
 }
 
+// authMethod evaluates the host-based authentication configuration for a
+// connection and returns the authentication method to use. An error is
+// returned if the connection must be rejected.
+func (s *Server) authMethod(
+	ctx context.Context, conn net.Conn, args sql.SessionArgs,
+) (hba.Method, error) {
+	conf := sql.HBAConfiguration(ctx, s.executor)
+	if conf == nil {
+		if _, ok := conn.(*tls.Conn); !ok {
+			// Connections without TLS are only accepted by insecure servers,
+			// which trust them unless configured otherwise.
+			return hba.MethodTrust, nil
+		}
+		return hba.MethodCertPassword, nil
+	}
+	var connType hba.ConnType
+	var ip net.IP
+	switch addr := conn.RemoteAddr().(type) {
+	case *net.UnixAddr:
+		connType = hba.ConnLocal
+	case *net.TCPAddr:
+		connType = hba.ConnHostNoSSL
+		if _, ok := conn.(*tls.Conn); ok {
+			connType = hba.ConnHostSSL
+		}
+		ip = addr.IP
+	default:
+		return "", errors.Errorf("unsupported client address %s", addr)
+	}
+	database := parser.Name(args.Database).Normalize()
+	entry, err := conf.Find(connType, ip, database, args.User)
+	if err != nil {
+		return "", err
+	}
+	if entry.Method == hba.MethodReject {
+		return "", errors.Errorf("connection rejected by host-based authentication rule %q", entry)
+	}
+	return entry.Method, nil
+}
+
 // ServeConn serves a single connection, driving the handshake process
 // and delegating to the appropriate connection type.
 func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
@@ -399,7 +440,11 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 		}
 
 		v3conn.sessionArgs.User = parser.Name(v3conn.sessionArgs.User).Normalize()
-		if err := v3conn.handleAuthentication(ctx, s.cfg.Insecure); err != nil {
+		var method hba.Method
+		if method, err = s.authMethod(ctx, conn, v3conn.sessionArgs); err != nil {
+			return v3conn.sendError(pgerror.NewError(pgerror.CodeInvalidAuthorizationSpecificationError, err.Error()))
+		}
+		if err := v3conn.handleAuthentication(ctx, s.cfg.Insecure, method); err != nil {
 			return v3conn.sendError(pgerror.NewError(pgerror.CodeInvalidPasswordError, err.Error()))
 		}
 
@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/lib/pq"
@@ -151,6 +152,9 @@ func (b *writeBuffer) writeTextDatum(
 	case *parser.DCollatedString:
 		b.writeLengthPrefixedString(v.Contents)
 
+	case *parser.DEnum:
+		b.writeLengthPrefixedString(v.LogicalRep)
+
 	case *parser.DDate:
 		t := timeutil.Unix(int64(*v)*secondsInDay, 0)
 		// Start at offset 4 because `putInt32` clobbers the first 4 bytes.
@@ -170,6 +174,12 @@ func (b *writeBuffer) writeTextDatum(
 		b.putInt32(int32(len(s)))
 		b.write(s)
 
+	case *parser.DTime:
+		b.writeLengthPrefixedString(timeofday.TimeOfDay(*v).String())
+
+	case *parser.DTimeTZ:
+		b.writeLengthPrefixedString(parser.AsStringWithFlags(v, parser.FmtBareStrings))
+
 	case *parser.DInterval:
 		b.writeLengthPrefixedString(v.ValueAsString())
 
@@ -359,6 +369,9 @@ func (b *writeBuffer) writeBinaryDatum(
 	case *parser.DCollatedString:
 		b.writeLengthPrefixedString(v.Contents)
 
+	case *parser.DEnum:
+		b.writeLengthPrefixedString(v.LogicalRep)
+
 	case *parser.DTimestamp:
 		b.putInt32(8)
 		b.putInt64(timeToPgBinary(v.Time, nil))
@@ -371,6 +384,16 @@ func (b *writeBuffer) writeBinaryDatum(
 		b.putInt32(4)
 		b.putInt32(dateToPgBinary(v))
 
+	case *parser.DTime:
+		b.putInt32(8)
+		b.putInt64(int64(*v))
+
+	case *parser.DTimeTZ:
+		b.putInt32(12)
+		b.putInt64(int64(v.TimeOfDay))
+		// Postgres stores the zone as seconds west of UTC.
+		b.putInt32(-v.OffsetSecs)
+
 	case *parser.DArray:
 		if v.ParamTyp.FamilyEqual(parser.TypeAnyArray) {
 			b.setError(errors.New("unsupported binary serialization of multidimensional arrays"))
@@ -580,6 +603,18 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (parser.Datum, error)
 				return nil, errors.Errorf("could not parse string %q as timestamptz", b)
 			}
 			return d, nil
+		case oid.T_time:
+			d, err := parser.ParseDTime(string(b))
+			if err != nil {
+				return nil, errors.Errorf("could not parse string %q as time", b)
+			}
+			return d, nil
+		case oid.T_timetz:
+			d, err := parser.ParseDTimeTZ(string(b), time.UTC)
+			if err != nil {
+				return nil, errors.Errorf("could not parse string %q as timetz", b)
+			}
+			return d, nil
 		case oid.T_date:
 			ts, err := parser.ParseDTimestamp(string(b), time.Microsecond)
 			if err != nil {
@@ -783,6 +818,20 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (parser.Datum, error)
 			}
 			i := int32(binary.BigEndian.Uint32(b))
 			return pgBinaryToDate(i), nil
+		case oid.T_time:
+			if len(b) < 8 {
+				return nil, errors.Errorf("time requires 8 bytes for binary format")
+			}
+			i := int64(binary.BigEndian.Uint64(b))
+			return parser.MakeDTime(timeofday.FromInt(i)), nil
+		case oid.T_timetz:
+			if len(b) < 12 {
+				return nil, errors.Errorf("timetz requires 12 bytes for binary format")
+			}
+			i := int64(binary.BigEndian.Uint64(b))
+			// Postgres sends the zone as seconds west of UTC.
+			zone := int32(binary.BigEndian.Uint32(b[8:]))
+			return parser.MakeDTimeTZ(timeofday.FromInt(i), -zone), nil
 		case oid.T_uuid:
 			u, err := parser.ParseDUuidFromBytes(b)
 			if err != nil {
@@ -815,6 +864,14 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (parser.Datum, error)
 		}
 		return parser.NewDName(string(b)), nil
 	default:
+		if id >= parser.UserDefinedTypeOIDOffset {
+			// The values of ENUM types are sent as their labels, in both
+			// formats. They are converted once the type is known.
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			return parser.NewDString(string(b)), nil
+		}
 		return nil, errors.Errorf("unsupported OID %v with format code %s", id, code)
 	}
 }
@@ -16,6 +16,7 @@ package pgwire
 
 import (
 	"bufio"
+	"crypto/rand"
 	"crypto/tls"
 	"fmt"
 	"math"
@@ -34,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -106,6 +108,10 @@ const (
 const (
 	authOK                int32 = 0
 	authCleartextPassword int32 = 3
+	authMD5Password       int32 = 5
+	authSASL              int32 = 10
+	authSASLContinue      int32 = 11
+	authSASLFinal         int32 = 12
 )
 
 // connResultsBufferSizeBytes refers to the size of the result set which we
@@ -333,13 +339,23 @@ var statusReportParams = map[string]string{
 // name, if different from the one given initially. Note: at this
 // point the sql.Session does not exist yet! If need exists to access the
 // database to look up authentication data, use the internal executor.
-func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error {
+// method is the authentication method chosen by the host-based
+// authentication configuration.
+func (c *v3Conn) handleAuthentication(
+	ctx context.Context, insecure bool, method hba.Method,
+) error {
+	if _, ok := c.conn.(*tls.Conn); !ok && method != hba.MethodTrust {
+		if err := c.authenticateWithoutTLS(ctx, method); err != nil {
+			return c.sendError(err)
+		}
+	}
 	if tlsConn, ok := c.conn.(*tls.Conn); ok {
 		var authenticationHook security.UserAuthHook
 
 		// Check that the requested user exists and retrieve the hashed
-		// password in case password authentication is needed.
-		exists, hashedPassword, err := sql.GetUserHashedPassword(
+		// password and password policy in case password authentication is
+		// needed.
+		exists, hashedPassword, policy, err := sql.GetUserHashedPassword(
 			ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
 		)
 		if err != nil {
@@ -350,22 +366,24 @@ func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error
 		}
 
 		tlsState := tlsConn.ConnectionState()
-		// If no certificates are provided, default to password
-		// authentication.
-		if len(tlsState.PeerCertificates) == 0 {
-			password, err := c.sendAuthPasswordRequest()
+		switch {
+		case method == hba.MethodTrust:
+			authenticationHook = func(string, bool) error { return nil }
+		case method == hba.MethodCert && len(tlsState.PeerCertificates) == 0:
+			return c.sendError(errors.New("client certificate required"))
+		case len(tlsState.PeerCertificates) == 0 || method == hba.MethodPassword:
+			// If no certificates are provided, default to password
+			// authentication.
+			authenticationHook, err = c.passwordAuthHook(ctx, insecure, hashedPassword, policy)
 			if err != nil {
 				return c.sendError(err)
 			}
-			authenticationHook = security.UserAuthPasswordHook(
-				insecure, password, hashedPassword,
-			)
-		} else {
+			authenticationHook = c.recordLoginAttempt(ctx, authenticationHook)
+		default:
 			// Normalize the username contained in the certificate.
 			tlsState.PeerCertificates[0].Subject.CommonName = parser.Name(
 				tlsState.PeerCertificates[0].Subject.CommonName,
 			).Normalize()
-			var err error
 			authenticationHook, err = security.UserAuthCertHook(insecure, &tlsState)
 			if err != nil {
 				return c.sendError(err)
@@ -382,6 +400,33 @@ func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error
 	return c.writeBuf.finishMsg(c.wr)
 }
 
+// authenticateWithoutTLS enforces the authentication method chosen by the
+// host-based authentication configuration on a connection without TLS,
+// which only insecure servers accept. As no client certificate can be
+// presented, the password of the user is checked, or the connection is
+// rejected if the method requires a certificate.
+func (c *v3Conn) authenticateWithoutTLS(ctx context.Context, method hba.Method) error {
+	if method == hba.MethodCert {
+		return errors.New("client certificate required")
+	}
+	exists, hashedPassword, policy, err := sql.GetUserHashedPassword(
+		ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
+	)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.Errorf("user %s does not exist", c.sessionArgs.User)
+	}
+	// The password is checked even though the server is insecure, since the
+	// configuration explicitly requires it.
+	authenticationHook, err := c.passwordAuthHook(ctx, false /* insecure */, hashedPassword, policy)
+	if err != nil {
+		return err
+	}
+	return c.recordLoginAttempt(ctx, authenticationHook)(c.sessionArgs.User, true /* public */)
+}
+
 func (c *v3Conn) setupSession(ctx context.Context, reserved mon.BoundAccount) error {
 	c.session = sql.NewSession(
 		ctx, c.sessionArgs, c.executor, c.conn.RemoteAddr(), &c.metrics.SQLMemMetrics,
@@ -572,6 +617,196 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 	}
 }
 
+// passwordAuthHook runs the password exchange matching the kind of hash
+// stored for the user and returns the resulting authentication hook:
+// - SCRAM-SHA-256 secrets use a SASL exchange.
+// - MD5 hashes use an MD5 challenge, if enabled.
+// - Legacy bcrypt hashes can only be checked against a cleartext password,
+//   if enabled. The hash is then upgraded so that the next login does not
+//   need the cleartext password.
+// Users without a password cannot use password authentication; they are not
+// asked for one.
+// The hooks also enforce the expiration and lockout of the password policy.
+func (c *v3Conn) passwordAuthHook(
+	ctx context.Context, insecure bool, hashedPassword []byte, policy security.PasswordPolicy,
+) (security.UserAuthHook, error) {
+	switch {
+	case len(hashedPassword) == 0:
+		// This is reported to the client like a wrong password, but is not
+		// counted as a failed login since the client was not asked for one.
+		return security.UserAuthChallengeHook(insecure, errors.New("invalid password"), policy), nil
+
+	case security.IsScramHash(hashedPassword):
+		secret, err := security.ParseScramSecret(hashedPassword)
+		if err != nil {
+			return nil, err
+		}
+		return security.UserAuthChallengeHook(insecure, c.handleAuthSCRAM(secret), policy), nil
+
+	case security.IsMD5Hash(hashedPassword):
+		if !sql.MD5PasswordAuthEnabled(c.executor) {
+			return nil, errors.New("MD5 password authentication is disabled")
+		}
+		return security.UserAuthChallengeHook(insecure, c.handleAuthMD5(hashedPassword), policy), nil
+
+	case security.IsBCryptHash(hashedPassword):
+		if !sql.BCryptCleartextFallbackEnabled(c.executor) {
+			return nil, errors.Errorf("the password of user %s must be reset", c.sessionArgs.User)
+		}
+		password, err := c.sendAuthPasswordRequest()
+		if err != nil {
+			return nil, err
+		}
+		hook := security.UserAuthPasswordHook(insecure, password, hashedPassword, policy)
+		return func(requestedUser string, clientConnection bool) error {
+			if err := hook(requestedUser, clientConnection); err != nil {
+				return err
+			}
+			if err := sql.UpgradeUserHashedPassword(
+				ctx, c.executor, c.metrics.internalMemMetrics, requestedUser, hashedPassword, password,
+			); err != nil {
+				// The login is valid; the upgrade will be retried next time.
+				log.Warningf(ctx, "unable to upgrade password hash of user %s: %v", requestedUser, err)
+			}
+			return nil
+		}, nil
+
+	default:
+		return nil, errors.Errorf("unknown password hash format for user %s", c.sessionArgs.User)
+	}
+}
+
+// recordLoginAttempt wraps a password authentication hook so that its
+// outcome is recorded, and accounts can be locked after too many failed
+// attempts. Only wrong passwords count as failures.
+func (c *v3Conn) recordLoginAttempt(
+	ctx context.Context, hook security.UserAuthHook,
+) security.UserAuthHook {
+	return func(requestedUser string, clientConnection bool) error {
+		err := hook(requestedUser, clientConnection)
+		if err == nil || err == security.ErrInvalidPassword {
+			if recordErr := sql.RecordUserLoginAttempt(
+				ctx, c.executor, c.metrics.internalMemMetrics, requestedUser, err == nil,
+			); recordErr != nil {
+				log.Warningf(ctx, "unable to record login attempt of user %s: %v", requestedUser, recordErr)
+			}
+		}
+		return err
+	}
+}
+
+// handleAuthSCRAM runs a SCRAM-SHA-256 exchange with the client. A nil
+// return value means the client proved knowledge of the password.
+func (c *v3Conn) handleAuthSCRAM(secret security.ScramSecret) error {
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authSASL)
+	c.writeBuf.writeTerminatedString(security.ScramSHA256)
+	c.writeBuf.nullTerminate()
+	if err := c.writeBuf.finishMsg(c.wr); err != nil {
+		return err
+	}
+	if err := c.wr.Flush(); err != nil {
+		return err
+	}
+
+	// SASLInitialResponse: mechanism name, then the length-prefixed
+	// client-first-message.
+	if err := c.readAuthResponse(); err != nil {
+		return err
+	}
+	mechanism, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	if mechanism != security.ScramSHA256 {
+		return errors.Errorf("unsupported SASL mechanism %q", mechanism)
+	}
+	n, err := c.readBuf.getUint32()
+	if err != nil {
+		return err
+	}
+	if int32(n) < 0 {
+		return errors.New("SASL initial response is missing the client-first-message")
+	}
+	clientFirst, err := c.readBuf.getBytes(int(n))
+	if err != nil {
+		return err
+	}
+
+	server := security.NewScramServer(secret)
+	serverFirst, err := server.ServerFirst(clientFirst)
+	if err != nil {
+		return err
+	}
+	if err := c.sendAuthSASLMessage(authSASLContinue, serverFirst); err != nil {
+		return err
+	}
+
+	// SASLResponse: the client-final-message is the rest of the message.
+	if err := c.readAuthResponse(); err != nil {
+		return err
+	}
+	serverFinal, err := server.ServerFinal(c.readBuf.msg)
+	if err != nil {
+		return err
+	}
+	return c.sendAuthSASLMessage(authSASLFinal, serverFinal)
+}
+
+// handleAuthMD5 sends an MD5 password challenge to the client and checks
+// its response against the stored MD5 hash.
+func (c *v3Conn) handleAuthMD5(hashedPassword []byte) error {
+	salt := make([]byte, 4)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authMD5Password)
+	c.writeBuf.write(salt)
+	if err := c.writeBuf.finishMsg(c.wr); err != nil {
+		return err
+	}
+	if err := c.wr.Flush(); err != nil {
+		return err
+	}
+
+	if err := c.readAuthResponse(); err != nil {
+		return err
+	}
+	response, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	if response != security.MD5Challenge(hashedPassword, salt) {
+		return security.ErrInvalidPassword
+	}
+	return nil
+}
+
+func (c *v3Conn) sendAuthSASLMessage(code int32, data []byte) error {
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(code)
+	c.writeBuf.write(data)
+	if err := c.writeBuf.finishMsg(c.wr); err != nil {
+		return err
+	}
+	return c.wr.Flush()
+}
+
+// readAuthResponse reads the client's response to an authentication
+// request into c.readBuf.
+func (c *v3Conn) readAuthResponse() error {
+	typ, n, err := c.readBuf.readTypedMsg(c.rd)
+	c.metrics.BytesInCount.Inc(int64(n))
+	if err != nil {
+		return err
+	}
+	if typ != clientMsgPassword {
+		return errors.Errorf("invalid response to authentication request: %s", typ)
+	}
+	return nil
+}
+
 // sendAuthPasswordRequest requests a cleartext password from the client and
 // returns it.
 func (c *v3Conn) sendAuthPasswordRequest() (string, error) {
@@ -584,16 +819,9 @@ func (c *v3Conn) sendAuthPasswordRequest() (string, error) {
 		return "", err
 	}
 
-	typ, n, err := c.readBuf.readTypedMsg(c.rd)
-	c.metrics.BytesInCount.Inc(int64(n))
-	if err != nil {
+	if err := c.readAuthResponse(); err != nil {
 		return "", err
 	}
-
-	if typ != clientMsgPassword {
-		return "", errors.Errorf("invalid response to authentication request: %s", typ)
-	}
-
 	return c.readBuf.getString()
 }
 
@@ -872,6 +1100,15 @@ func (c *v3Conn) handleBind(ctx context.Context, buf *readBuffer) error {
 		if err != nil {
 			return c.sendError(errors.Wrapf(err, "error in argument for $%d", i+1))
 		}
+		if typ, ok := stmt.Types[k].(parser.TEnum); ok {
+			// The members of user-defined types are not known from their OID
+			// alone, so their values are decoded as strings first.
+			if s, ok := d.(*parser.DString); ok {
+				if d, err = parser.MakeDEnumFromLogicalRep(typ, string(*s)); err != nil {
+					return c.sendError(errors.Wrapf(err, "error in argument for $%d", i+1))
+				}
+			}
+		}
 		qargs[k] = d
 	}
 
@@ -138,17 +138,23 @@ type planNodeFastPath interface {
 }
 
 var _ planNode = &alterTableNode{}
+var _ planNode = &alterTypeNode{}
+var _ planNode = &alterUserNode{}
 var _ planNode = &copyNode{}
 var _ planNode = &createDatabaseNode{}
 var _ planNode = &createIndexNode{}
+var _ planNode = &createPolicyNode{}
 var _ planNode = &createTableNode{}
+var _ planNode = &createTypeNode{}
 var _ planNode = &createViewNode{}
 var _ planNode = &delayedNode{}
 var _ planNode = &deleteNode{}
 var _ planNode = &distinctNode{}
 var _ planNode = &dropDatabaseNode{}
 var _ planNode = &dropIndexNode{}
+var _ planNode = &dropPolicyNode{}
 var _ planNode = &dropTableNode{}
+var _ planNode = &dropTypeNode{}
 var _ planNode = &dropViewNode{}
 var _ planNode = &zeroNode{}
 var _ planNode = &unaryNode{}
@@ -178,6 +184,7 @@ var _ planNode = &valuesNode{}
 var _ planNode = &windowNode{}
 var _ planNode = &createUserNode{}
 var _ planNode = &dropUserNode{}
+var _ planNode = &refreshMaterializedViewNode{}
 
 var _ planNodeFastPath = &deleteNode{}
 var _ planNodeFastPath = &dropUserNode{}
@@ -326,6 +333,10 @@ func (p *planner) newPlan(
 	switch n := stmt.(type) {
 	case *parser.AlterTable:
 		return p.AlterTable(ctx, n)
+	case *parser.AlterTypeAddValue:
+		return p.AlterTypeAddValue(ctx, n)
+	case *parser.AlterUser:
+		return p.AlterUser(ctx, n)
 	case *parser.BeginTransaction:
 		return p.BeginTransaction(n)
 	case *parser.CancelQuery:
@@ -340,8 +351,14 @@ func (p *planner) newPlan(
 		return p.CreateDatabase(n)
 	case *parser.CreateIndex:
 		return p.CreateIndex(ctx, n)
+	case *parser.CreatePolicy:
+		return p.CreatePolicy(ctx, n)
+	case *parser.CreateRole:
+		return p.CreateRole(ctx, n)
 	case *parser.CreateTable:
 		return p.CreateTable(ctx, n)
+	case *parser.CreateType:
+		return p.CreateType(ctx, n)
 	case *parser.CreateUser:
 		return p.CreateUser(ctx, n)
 	case *parser.CreateView:
@@ -356,8 +373,14 @@ func (p *planner) newPlan(
 		return p.DropDatabase(ctx, n)
 	case *parser.DropIndex:
 		return p.DropIndex(ctx, n)
+	case *parser.DropPolicy:
+		return p.DropPolicy(ctx, n)
+	case *parser.DropRole:
+		return p.DropRole(ctx, n)
 	case *parser.DropTable:
 		return p.DropTable(ctx, n)
+	case *parser.DropType:
+		return p.DropType(ctx, n)
 	case *parser.DropView:
 		return p.DropView(ctx, n)
 	case *parser.DropUser:
@@ -368,6 +391,8 @@ func (p *planner) newPlan(
 		return p.Explain(ctx, n)
 	case *parser.Grant:
 		return p.Grant(ctx, n)
+	case *parser.GrantRole:
+		return p.GrantRole(ctx, n)
 	case *parser.Insert:
 		return p.Insert(ctx, n, desiredTypes)
 	case *parser.ParenSelect:
@@ -376,6 +401,8 @@ func (p *planner) newPlan(
 		return p.PauseJob(ctx, n)
 	case *parser.TestingRelocate:
 		return p.TestingRelocate(ctx, n)
+	case *parser.RefreshMaterializedView:
+		return p.RefreshMaterializedView(ctx, n)
 	case *parser.RenameColumn:
 		return p.RenameColumn(ctx, n)
 	case *parser.RenameDatabase:
@@ -388,6 +415,8 @@ func (p *planner) newPlan(
 		return p.ResumeJob(ctx, n)
 	case *parser.Revoke:
 		return p.Revoke(ctx, n)
+	case *parser.RevokeRole:
+		return p.RevokeRole(ctx, n)
 	case *parser.Scatter:
 		return p.Scatter(ctx, n)
 	case *parser.Select:
@@ -418,6 +447,8 @@ func (p *planner) newPlan(
 		return p.ShowDatabases(ctx, n)
 	case *parser.ShowGrants:
 		return p.ShowGrants(ctx, n)
+	case *parser.ShowRoleGrants:
+		return p.ShowRoleGrants(ctx, n)
 	case *parser.ShowIndex:
 		return p.ShowIndex(ctx, n)
 	case *parser.ShowQueries:
@@ -434,6 +465,8 @@ func (p *planner) newPlan(
 		return p.ShowTransactionStatus(ctx)
 	case *parser.ShowUsers:
 		return p.ShowUsers(ctx, n)
+	case *parser.ShowRoles:
+		return p.ShowRoles(ctx, n)
 	case *parser.ShowRanges:
 		return p.ShowRanges(ctx, n)
 	case *parser.ShowFingerprints:
@@ -502,6 +535,8 @@ func (p *planner) prepare(ctx context.Context, stmt parser.Statement) (planNode,
 		return p.ShowDatabases(ctx, n)
 	case *parser.ShowGrants:
 		return p.ShowGrants(ctx, n)
+	case *parser.ShowRoleGrants:
+		return p.ShowRoleGrants(ctx, n)
 	case *parser.ShowIndex:
 		return p.ShowIndex(ctx, n)
 	case *parser.ShowConstraints:
@@ -518,6 +553,8 @@ func (p *planner) prepare(ctx context.Context, stmt parser.Statement) (planNode,
 		return p.ShowTrace(ctx, n)
 	case *parser.ShowUsers:
 		return p.ShowUsers(ctx, n)
+	case *parser.ShowRoles:
+		return p.ShowRoles(ctx, n)
 	case *parser.ShowTransactionStatus:
 		return p.ShowTransactionStatus(ctx)
 	case *parser.ShowRanges:
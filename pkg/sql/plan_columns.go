@@ -103,7 +103,11 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		// Nodes that have the same schema as their source or their
 		// valueNode helper.
 	case *distinctNode:
-		return getPlanColumns(n.plan, mut)
+		cols := getPlanColumns(n.plan, mut)
+		if n.numOutputCols > 0 {
+			cols = cols[:n.numOutputCols]
+		}
+		return cols
 	case *filterNode:
 		return getPlanColumns(n.source.plan, mut)
 	case *indexJoinNode:
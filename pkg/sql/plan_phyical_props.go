@@ -29,7 +29,7 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *explainPlanNode:
 		return planPhysicalProps(n.results)
 	case *distinctNode:
-		return planPhysicalProps(n.plan)
+		return distinctPhysicalProps(n)
 	case *limitNode:
 		return planPhysicalProps(n.plan)
 	case *indexJoinNode:
@@ -70,6 +70,21 @@ func planPhysicalProps(plan planNode) physicalProps {
 	return physicalProps{}
 }
 
+func distinctPhysicalProps(n *distinctNode) physicalProps {
+	props := planPhysicalProps(n.plan)
+	if n.numOutputCols > 0 {
+		// The distinctNode is projecting away the columns only rendered for
+		// DISTINCT ON, e.g:
+		//   SELECT DISTINCT ON (k) v FROM kv.
+		colMap := make([]int, n.numOutputCols)
+		for i := range colMap {
+			colMap[i] = i
+		}
+		return props.project(colMap)
+	}
+	return props
+}
+
 func sortPhysicalProps(n *sortNode) physicalProps {
 	underlying := planPhysicalProps(n.plan)
 
@@ -98,6 +98,14 @@ type planner struct {
 	// occurred during logical plan construction.
 	hasSubqueries bool
 
+	// roleMemberships caches the results of MemberOfWithAdminOption, keyed by
+	// member, for the duration of the statement.
+	roleMemberships map[string]map[string]bool
+
+	// auditEvents collects the accesses of the current statement to audited
+	// tables. See audit_log.go.
+	auditEvents []auditEvent
+
 	// Avoid allocations by embedding commonly used objects and visitors.
 	parser                parser.Parser
 	subqueryVisitor       subqueryVisitor
@@ -251,6 +259,15 @@ func (p *planner) QueryRow(
 	}
 }
 
+// SessionVar implements the parser.EvalPlanner interface.
+func (p *planner) SessionVar(name string) (string, bool) {
+	if v, ok := varGen[name]; ok {
+		return v.Get(p.session), true
+	}
+	value, ok := p.session.CustomVars[name]
+	return value, ok
+}
+
 // queryRows executes a SQL query string where multiple result rows are returned.
 func (p *planner) queryRows(
 	ctx context.Context, sql string, args ...interface{},
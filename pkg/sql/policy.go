@@ -0,0 +1,345 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// Row-level security
+//
+// When row-level security is enabled on a table, the rows users other than
+// root can access are restricted by the policies of the table:
+//
+// - rows are only read, updated or deleted if they satisfy the USING
+//   expression of one of the policies applying to the user. The expressions
+//   are the filter of the scans of the table, and the other filters of the
+//   query are only applied to the rows they allow.
+// - rows are only inserted or updated if the new row satisfies the WITH
+//   CHECK expression of one of the policies applying to the user, or its
+//   USING expression if it has none. The expressions are checked along with
+//   the CHECK constraints of the table, including on the rows updated by
+//   UPSERT and INSERT ... ON CONFLICT DO UPDATE. The existing rows these
+//   update must also satisfy the USING expressions.
+//
+// If no policy applies to the user, no rows can be accessed. Session
+// variables can be used in policy expressions through current_setting(),
+// which is evaluated when the statement is planned.
+
+// policyTypingContext is used in errors about policy expressions.
+const policyTypingContext = "POLICY"
+
+type createPolicyNode struct {
+	n         *parser.CreatePolicy
+	tableDesc *sqlbase.TableDescriptor
+	policy    sqlbase.TableDescriptor_PolicyDescriptor
+}
+
+// CreatePolicy adds a row-level security policy to a table.
+// Privileges: CREATE on table.
+//   Notes: postgres requires the table owner.
+func (p *planner) CreatePolicy(ctx context.Context, n *parser.CreatePolicy) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+	tableDesc, err := MustGetTableDesc(ctx, p.txn, p.getVirtualTabler(), tn, false /* allowAdding */)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	name := string(n.Name)
+	if tableDesc.FindPolicyByName(name) >= 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+			"policy %q for table %q already exists", name, tableDesc.Name)
+	}
+	if n.Using == nil && n.WithCheck == nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"policy %q must have a USING or a WITH CHECK expression", name)
+	}
+
+	policy := sqlbase.TableDescriptor_PolicyDescriptor{Name: name}
+	for _, role := range n.Roles {
+		policy.Roles = append(policy.Roles, string(role))
+	}
+	if n.Using != nil {
+		expr, err := validatePolicyExpr(tableDesc, n.Using, p.session.SearchPath)
+		if err != nil {
+			return nil, err
+		}
+		policy.UsingExpr = &expr
+	}
+	if n.WithCheck != nil {
+		expr, err := validatePolicyExpr(tableDesc, n.WithCheck, p.session.SearchPath)
+		if err != nil {
+			return nil, err
+		}
+		policy.CheckExpr = &expr
+	}
+
+	return &createPolicyNode{n: n, tableDesc: tableDesc, policy: policy}, nil
+}
+
+func (n *createPolicyNode) Start(params runParams) error {
+	n.tableDesc.Policies = append(n.tableDesc.Policies, n.policy)
+	return params.p.writePolicyChange(params.ctx, n.tableDesc, n.n)
+}
+
+func (*createPolicyNode) Next(runParams) (bool, error) { return false, nil }
+func (*createPolicyNode) Close(context.Context)        {}
+func (*createPolicyNode) Values() parser.Datums        { return parser.Datums{} }
+
+type dropPolicyNode struct {
+	n         *parser.DropPolicy
+	tableDesc *sqlbase.TableDescriptor
+	idx       int
+}
+
+// DropPolicy removes a row-level security policy from a table.
+// Privileges: CREATE on table.
+//   Notes: postgres requires the table owner.
+func (p *planner) DropPolicy(ctx context.Context, n *parser.DropPolicy) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+	tableDesc, err := MustGetTableDesc(ctx, p.txn, p.getVirtualTabler(), tn, false /* allowAdding */)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	idx := tableDesc.FindPolicyByName(string(n.Name))
+	if idx < 0 {
+		if n.IfExists {
+			return &zeroNode{}, nil
+		}
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"policy %q for table %q does not exist", string(n.Name), tableDesc.Name)
+	}
+	return &dropPolicyNode{n: n, tableDesc: tableDesc, idx: idx}, nil
+}
+
+func (n *dropPolicyNode) Start(params runParams) error {
+	policies := n.tableDesc.Policies
+	n.tableDesc.Policies = append(policies[:n.idx:n.idx], policies[n.idx+1:]...)
+	return params.p.writePolicyChange(params.ctx, n.tableDesc, n.n)
+}
+
+func (*dropPolicyNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropPolicyNode) Close(context.Context)        {}
+func (*dropPolicyNode) Values() parser.Datums        { return parser.Datums{} }
+
+// writePolicyChange writes the table descriptor after its policies were
+// changed by stmt, and records the change in the event log.
+func (p *planner) writePolicyChange(
+	ctx context.Context, tableDesc *sqlbase.TableDescriptor, stmt parser.Statement,
+) error {
+	if err := tableDesc.SetUpVersion(); err != nil {
+		return err
+	}
+	if err := tableDesc.ValidateTable(); err != nil {
+		return err
+	}
+	if err := p.writeTableDesc(ctx, tableDesc); err != nil {
+		return err
+	}
+
+	// Record this table alteration in the event log. This is an auditable log
+	// event and is recorded in the same transaction as the table descriptor
+	// update.
+	if err := MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogAlterTable,
+		int32(tableDesc.ID),
+		int32(p.evalCtx.NodeID),
+		struct {
+			TableName string
+			Statement string
+			User      string
+		}{tableDesc.Name, stmt.String(), p.session.User},
+	); err != nil {
+		return err
+	}
+
+	p.notifySchemaChange(tableDesc, sqlbase.InvalidMutationID)
+	return nil
+}
+
+// validatePolicyExpr checks that the expression can be used in a policy of
+// the table, and returns its serialized form.
+func validatePolicyExpr(
+	desc *sqlbase.TableDescriptor, expr parser.Expr, searchPath parser.SearchPath,
+) (string, error) {
+	preFn := func(expr parser.Expr) (err error, recurse bool, newExpr parser.Expr) {
+		if _, ok := expr.(*parser.Subquery); ok {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"subqueries are not allowed in %s expressions", policyTypingContext), false, nil
+		}
+		vBase, ok := expr.(parser.VarName)
+		if !ok {
+			return nil, true, expr
+		}
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return err, false, nil
+		}
+		c, ok := v.(*parser.ColumnItem)
+		if !ok {
+			return nil, true, expr
+		}
+		col, err := desc.FindActiveColumnByName(string(c.ColumnName))
+		if err != nil {
+			return fmt.Errorf("column %q not found for policy expression", c.ColumnName), false, nil
+		}
+		// Convert to a dummy node of the correct type.
+		return nil, false, dummyColumnItem{col.Type.ToDatumType()}
+	}
+
+	replaced, err := parser.SimpleVisit(expr, preFn)
+	if err != nil {
+		return "", err
+	}
+	var p parser.Parser
+	if err := p.AssertNoAggregationOrWindowing(
+		replaced, policyTypingContext+" expressions", searchPath,
+	); err != nil {
+		return "", err
+	}
+	if _, err := sqlbase.SanitizeVarFreeExpr(
+		replaced, parser.TypeBool, policyTypingContext, searchPath,
+	); err != nil {
+		return "", err
+	}
+	return parser.Serialize(expr), nil
+}
+
+// rowLevelSecurityApplies returns whether the policies of the table restrict
+// the rows the session user can access.
+func (p *planner) rowLevelSecurityApplies(desc *sqlbase.TableDescriptor) bool {
+	if !desc.RowLevelSecurity {
+		return false
+	}
+	user := p.session.User
+	return user != security.RootUser && user != security.NodeUser
+}
+
+// policyExpr returns the disjunction of the expressions of the policies of
+// the table that apply to the session user: their USING expressions, or if
+// forCheck is set, their WITH CHECK expressions. The expression is false if
+// no policy applies.
+func (p *planner) policyExpr(
+	ctx context.Context, desc *sqlbase.TableDescriptor, forCheck bool,
+) (parser.Expr, error) {
+	var memberOf map[string]bool
+	var exprs []string
+	for _, policy := range desc.Policies {
+		applies := len(policy.Roles) == 0
+		for _, role := range policy.Roles {
+			if role == p.session.User {
+				applies = true
+				break
+			}
+			if memberOf == nil {
+				var err error
+				if memberOf, err = p.MemberOfWithAdminOption(ctx, p.session.User); err != nil {
+					return nil, err
+				}
+			}
+			if _, ok := memberOf[role]; ok {
+				applies = true
+				break
+			}
+		}
+		if !applies {
+			continue
+		}
+
+		expr := policy.UsingExpr
+		if forCheck && policy.CheckExpr != nil {
+			expr = policy.CheckExpr
+		}
+		if expr != nil {
+			exprs = append(exprs, *expr)
+		}
+	}
+	if len(exprs) == 0 {
+		return parser.DBoolFalse, nil
+	}
+
+	parsed, err := parser.ParseExprs(exprs)
+	if err != nil {
+		return nil, err
+	}
+	var result parser.Expr
+	for _, expr := range parsed {
+		if result == nil {
+			result = expr
+		} else {
+			result = &parser.OrExpr{Left: result, Right: expr}
+		}
+	}
+	return result, nil
+}
+
+// analyzePolicyExpr analyzes the policy expression returned by policyExpr
+// against the given columns of the table. Policy expressions can refer to
+// all the columns, regardless of the column privileges of the user.
+func (p *planner) analyzePolicyExpr(
+	ctx context.Context,
+	desc *sqlbase.TableDescriptor,
+	cols []sqlbase.ColumnDescriptor,
+	iVarHelper parser.IndexedVarHelper,
+	forCheck bool,
+) (parser.TypedExpr, error) {
+	expr, err := p.policyExpr(ctx, desc, forCheck)
+	if err != nil {
+		return nil, err
+	}
+	sourceInfo := newSourceInfoForSingleTable(
+		parser.TableName{TableName: parser.Name(desc.Name)}, sqlbase.ResultColumnsFromColDescs(cols),
+	)
+	return p.analyzeExpr(ctx, expr, multiSourceInfo{sourceInfo}, iVarHelper,
+		parser.TypeBool, true, policyTypingContext)
+}
+
+// newPolicyViolationError is returned when a row that is inserted or updated
+// does not satisfy the policies of the table.
+func newPolicyViolationError(desc *sqlbase.TableDescriptor) error {
+	return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+		"new row violates row-level security policy for table %q", desc.Name)
+}
+
+// newPolicyUsingViolationError is returned when UPSERT or INSERT ... ON
+// CONFLICT DO UPDATE conflicts with an existing row that the policies of the
+// table don't allow to be updated.
+func newPolicyUsingViolationError(desc *sqlbase.TableDescriptor) error {
+	return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+		"new row violates row-level security policy (USING expression) for table %q", desc.Name)
+}
@@ -45,6 +45,8 @@ const (
 var (
 	ReadData      = List{GRANT, SELECT}
 	ReadWriteData = List{GRANT, SELECT, INSERT, DELETE, UPDATE}
+	// ColumnPrivileges are the privileges that can be granted on columns.
+	ColumnPrivileges = List{SELECT, UPDATE}
 )
 
 // Mask returns the bitmask for a given privilege.
@@ -57,6 +59,18 @@ var ByValue = [...]Kind{
 	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE,
 }
 
+// ByName is a map of string -> kind value.
+var ByName = map[string]Kind{
+	"ALL":    ALL,
+	"CREATE": CREATE,
+	"DROP":   DROP,
+	"GRANT":  GRANT,
+	"SELECT": SELECT,
+	"INSERT": INSERT,
+	"DELETE": DELETE,
+	"UPDATE": UPDATE,
+}
+
 // List is a list of privileges.
 type List []Kind
 
@@ -138,6 +152,20 @@ func ListFromBitField(m uint32) List {
 	return ret
 }
 
+// ListFromStrings takes a list of privilege names and returns the
+// corresponding list of Kinds. Names are matched case-insensitively.
+func ListFromStrings(strs []string) (List, error) {
+	ret := make(List, len(strs))
+	for i, s := range strs {
+		k, ok := ByName[strings.ToUpper(s)]
+		if !ok {
+			return nil, fmt.Errorf("not a valid privilege: %q", s)
+		}
+		ret[i] = k
+	}
+	return ret, nil
+}
+
 // Lists is a list of privilege lists
 type Lists []List
 
@@ -60,3 +60,32 @@ func TestPrivilegeDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestPrivilegeListFromStrings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	testCases := []struct {
+		strs     []string
+		expected privilege.List
+		err      string
+	}{
+		{[]string{"select"}, privilege.List{privilege.SELECT}, ""},
+		{[]string{"INSERT", "Delete"}, privilege.List{privilege.INSERT, privilege.DELETE}, ""},
+		{[]string{"update", "foo"}, nil, `not a valid privilege: "foo"`},
+	}
+
+	for _, tc := range testCases {
+		pl, err := privilege.ListFromStrings(tc.strs)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("%v: expected error %q, got %v", tc.strs, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tc.strs, err)
+		}
+		if pl.String() != tc.expected.String() {
+			t.Fatalf("%v: expected %s, got %s", tc.strs, tc.expected, pl)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -52,7 +53,7 @@ func (p *planner) RenameDatabase(ctx context.Context, n *parser.RenameDatabase)
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.DROP); err != nil {
 		return nil, err
 	}
 
@@ -111,14 +112,24 @@ func (p *planner) RenameDatabase(ctx context.Context, n *parser.RenameDatabase)
 //          mysql requires ALTER, DROP on the original table, and CREATE, INSERT
 //          on the new table (and does not copy privileges over).
 func (p *planner) RenameTable(ctx context.Context, n *parser.RenameTable) (planNode, error) {
-	oldTn, err := n.Name.NormalizeWithDatabaseName(p.session.Database)
+	oldTn, err := p.normalizeTableName(ctx, &n.Name)
 	if err != nil {
 		return nil, err
 	}
-	newTn, err := n.NewName.NormalizeWithDatabaseName(p.session.Database)
+	newTn, err := n.NewName.Normalize()
 	if err != nil {
 		return nil, err
 	}
+	if newTn.DBNameOriginallyOmitted && isTempDatabaseName(oldTn.Database()) {
+		// Renamed temporary tables stay temporary.
+		newTn.DatabaseName = oldTn.DatabaseName
+	} else if err := p.qualifyTableName(ctx, newTn); err != nil {
+		return nil, err
+	}
+	if isTempDatabaseName(oldTn.Database()) != isTempDatabaseName(newTn.Database()) {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot move objects into or out of temporary schemas")
+	}
 
 	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), oldTn.Database())
 	if err != nil {
@@ -165,7 +176,7 @@ func (p *planner) RenameTable(ctx context.Context, n *parser.RenameTable) (planN
 		}
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.DROP); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.DROP); err != nil {
 		return nil, err
 	}
 
@@ -184,7 +195,7 @@ func (p *planner) RenameTable(ctx context.Context, n *parser.RenameTable) (planN
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(targetDbDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, targetDbDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -275,7 +286,7 @@ func (p *planner) RenameIndex(ctx context.Context, n *parser.RenameIndex) (planN
 		return nil, err
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -322,7 +333,7 @@ func (p *planner) RenameIndex(ctx context.Context, n *parser.RenameIndex) (planN
 //          mysql requires ALTER, CREATE, INSERT on the table.
 func (p *planner) RenameColumn(ctx context.Context, n *parser.RenameColumn) (planNode, error) {
 	// Check if table exists.
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -339,7 +350,7 @@ func (p *planner) RenameColumn(ctx context.Context, n *parser.RenameColumn) (pla
 		return nil, fmt.Errorf("table %q does not exist", tn.Table())
 	}
 
-	if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
 
@@ -235,12 +235,21 @@ func (p *planner) SelectClause(
 		return nil, err
 	}
 
-	// NB: orderBy, window, and groupBy are passed and can modify the renderNode,
-	// but must do so in that order.
+	// NB: distinct, orderBy, window, and groupBy are passed and can modify the
+	// renderNode, but must do so in that order.
+	distinctPlan, err := p.Distinct(ctx, parsed, r)
+	if err != nil {
+		return nil, err
+	}
 	sort, err := p.orderBy(ctx, orderBy, r)
 	if err != nil {
 		return nil, err
 	}
+	if distinctPlan != nil && sort != nil {
+		if err := distinctPlan.checkOrdering(sort.ordering); err != nil {
+			return nil, err
+		}
+	}
 	window, err := p.window(ctx, parsed, r)
 	if err != nil {
 		return nil, err
@@ -265,7 +274,6 @@ func (p *planner) SelectClause(
 	if err != nil {
 		return nil, err
 	}
-	distinctPlan := p.Distinct(parsed)
 
 	result := planNode(r)
 	if groupComplex != nil {
@@ -20,6 +20,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/pkg/errors"
@@ -52,7 +53,7 @@ func (p *planner) newReturningHelper(
 	r parser.ReturningClause,
 	desiredTypes []parser.Type,
 	tn *parser.TableName,
-	tablecols []sqlbase.ColumnDescriptor,
+	desc *sqlbase.TableDescriptor,
 ) (*returningHelper, error) {
 	rh := &returningHelper{
 		p: p,
@@ -75,10 +76,11 @@ func (p *planner) newReturningHelper(
 		}
 	}
 
+	tablecols := desc.Columns
+	sourceCols := sqlbase.ResultColumnsFromColDescs(tablecols)
+
 	rh.columns = make(sqlbase.ResultColumns, 0, len(rExprs))
-	rh.source = newSourceInfoForSingleTable(
-		*tn, sqlbase.ResultColumnsFromColDescs(tablecols),
-	)
+	rh.source = newSourceInfoForSingleTable(*tn, sourceCols)
 	rh.exprs = make([]parser.TypedExpr, 0, len(rExprs))
 	ivarHelper := parser.MakeIndexedVarHelper(rh, len(tablecols))
 	for _, target := range rExprs {
@@ -91,6 +93,18 @@ func (p *planner) newReturningHelper(
 		rh.columns = append(rh.columns, cols...)
 		rh.exprs = append(rh.exprs, typedExprs...)
 	}
+
+	// The columns read by RETURNING require the SELECT privilege, on the
+	// table or on each of them, even though the statement itself does not.
+	var readCols []sqlbase.ColumnDescriptor
+	for i := range tablecols {
+		if ivarHelper.IndexedVarUsed(i) {
+			readCols = append(readCols, tablecols[i])
+		}
+	}
+	if err := p.checkColumnPrivileges(ctx, desc, readCols, privilege.SELECT); err != nil {
+		return nil, err
+	}
 	return rh, nil
 }
 
@@ -80,6 +80,11 @@ type scanNode struct {
 	// parser.IndexedVar leaves generated using filterVars.
 	filter     parser.TypedExpr
 	filterVars parser.IndexedVarHelper
+	// policyBarrier is set when filter holds the row-level security policies
+	// of the table. No other filter is then merged into it, so that user
+	// predicates, which could fail or otherwise reveal the values they are
+	// evaluated on, only see the rows allowed by the policies.
+	policyBarrier bool
 
 	scanInitialized bool
 	fetcher         sqlbase.RowFetcher
@@ -189,6 +194,7 @@ func (n *scanNode) Next(params runParams) (bool, error) {
 
 // Initializes a scanNode with a table descriptor.
 func (n *scanNode) initTable(
+	ctx context.Context,
 	p *planner,
 	desc *sqlbase.TableDescriptor,
 	indexHints *parser.IndexHints,
@@ -197,10 +203,26 @@ func (n *scanNode) initTable(
 ) error {
 	n.desc = desc
 
+	p.maybeAudit(n.desc, privilege.SELECT)
+	restricted := false
 	if !p.skipSelectPrivilegeChecks {
-		if err := p.CheckPrivilege(n.desc, privilege.SELECT); err != nil {
+		ok, err := p.hasPrivilege(ctx, n.desc, privilege.SELECT)
+		if err != nil {
 			return err
 		}
+		if !ok {
+			// SELECT may have been granted on some of the columns only, in
+			// which case the references to the other columns are rejected
+			// during name resolution.
+			ok, err = p.anyColumnPrivilege(ctx, n.desc, privilege.SELECT)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return newPrivilegeError(p.session.User, privilege.SELECT, n.desc)
+			}
+			restricted = true
+		}
 	}
 
 	if indexHints != nil {
@@ -210,7 +232,22 @@ func (n *scanNode) initTable(
 	}
 
 	n.noIndexJoin = (indexHints != nil && indexHints.NoIndexJoin)
-	return n.initDescDefaults(scanVisibility, wantedColumns)
+	if err := n.initDescDefaults(scanVisibility, wantedColumns); err != nil {
+		return err
+	}
+	if p.rowLevelSecurityApplies(n.desc) {
+		// Only the rows allowed by the policies of the table are scanned.
+		filter, err := p.analyzePolicyExpr(ctx, n.desc, n.cols, n.filterVars, false /* forCheck */)
+		if err != nil {
+			return err
+		}
+		n.filter = mergeConj(n.filter, filter)
+		n.policyBarrier = true
+	}
+	if restricted {
+		return p.restrictColumns(ctx, n.desc, n.cols, n.resultColumns)
+	}
+	return nil
 }
 
 func (n *scanNode) lookupSpecifiedIndex(indexHints *parser.IndexHints) error {
@@ -1049,6 +1049,10 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 							s.schemaChangers[table.ID] = schemaChanger
 						}
 
+					case *sqlbase.Descriptor_Type:
+						// Types have no mutations to process; the session altering
+						// a type waits for the leases on its previous version (see
+						// schemaChangerCollection.execSchemaChanges).
 					case *sqlbase.Descriptor_Database:
 						// Ignore.
 					}
@@ -120,6 +120,10 @@ func (v *nameResolutionVisitor) VisitPre(expr parser.Expr) (recurse bool, newNod
 			v.err = err
 			return false, expr
 		}
+		if err := v.sources[srcIdx].sourceColumns[colIdx].PrivilegeError; err != nil && !t.ForUpdateOrDelete {
+			v.err = err
+			return false, expr
+		}
 		ivar := v.iVarHelper.IndexedVar(v.sources[srcIdx].colOffset + colIdx)
 		v.foundDependentVars = true
 		return true, ivar
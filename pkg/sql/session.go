@@ -210,6 +210,9 @@ type Session struct {
 	// SafeUpdates causes errors when the client
 	// sends syntax that may have unwanted side effects.
 	SafeUpdates bool
+	// CustomVars holds the custom session variables, whose names contain a
+	// dot (e.g. app.tenant_id). See customVar().
+	CustomVars map[string]string
 
 	//
 	// Session parameters, non-user-configurable.
@@ -299,6 +302,14 @@ type Session struct {
 
 	tables TableCollection
 
+	// tempDatabaseName is the name of the database holding the temporary
+	// tables of the session. See temp_table.go.
+	tempDatabaseName string
+	// tempDatabaseCreated is set once the session has attempted to create
+	// its temporary database. Until then, name resolution does not need to
+	// look into it.
+	tempDatabaseCreated bool
+
 	// If set, contains the in progress COPY FROM columns.
 	copyFrom *copyNode
 
@@ -402,6 +413,19 @@ func (r *SessionRegistry) deregister(s *Session) {
 	r.Unlock()
 }
 
+// hasTempDatabase returns true if the given temporary database belongs
+// to a registered session.
+func (r *SessionRegistry) hasTempDatabase(name string) bool {
+	r.Lock()
+	defer r.Unlock()
+	for s := range r.store {
+		if s.tempDatabaseName == name {
+			return true
+		}
+	}
+	return false
+}
+
 // CancelQuery looks up the associated query in the session registry and cancels it.
 func (r *SessionRegistry) CancelQuery(queryIDStr string, username string) (bool, error) {
 	queryID, err := uint128.FromString(queryIDStr)
@@ -473,6 +497,7 @@ func NewSession(
 			leaseMgr:      e.cfg.LeaseManager,
 			databaseCache: e.getDatabaseCache(),
 		},
+		tempDatabaseName: makeTempDatabaseName(e.cfg.NodeID.Get()),
 	}
 	s.phaseTimes[sessionInit] = timeutil.Now()
 	s.resetApplicationName(args.ApplicationName)
@@ -547,6 +572,14 @@ func (s *Session) Finish(e *Executor) {
 	// addressed, there might be leases accumulated by preparing statements.
 	s.tables.releaseTables(s.context)
 
+	if s.tempDatabaseCreated {
+		if err := dropTempDatabase(
+			s.context, s.execCfg.DB, s.execCfg.LeaseManager, s.tempDatabaseName,
+		); err != nil {
+			log.Warningf(s.context, "error dropping temporary database %s: %s", s.tempDatabaseName, err)
+		}
+	}
+
 	s.ClearStatementsAndPortals(s.context)
 	s.sessionMon.Stop(s.context)
 	s.mon.Stop(s.context)
@@ -623,11 +656,14 @@ func (s *Session) resetPlanner(p *planner, e *Executor, txn *client.Txn) {
 	// phaseTimes is an array, not a slice, so this performs a copy-by-value.
 	p.phaseTimes = s.phaseTimes
 	p.stmt = nil
+	p.roleMemberships = nil
+	p.auditEvents = nil
 	p.cancelChecker = sqlbase.NewCancelChecker(s.Ctx())
 
 	p.semaCtx = parser.MakeSemaContext(s.User == security.RootUser)
 	p.semaCtx.Location = &s.Location
 	p.semaCtx.SearchPath = s.SearchPath
+	p.semaCtx.TypeResolver = p
 
 	p.evalCtx = s.evalCtx()
 	p.evalCtx.Planner = p
@@ -1296,6 +1332,11 @@ type schemaChangerCollection struct {
 		epoch int
 		sc    SchemaChanger
 	}
+
+	// IDs of the types whose version was incremented by the transaction.
+	// Once it has finished, we wait until no leases remain on the previous
+	// versions of the types.
+	typeIDs []sqlbase.ID
 }
 
 func (scc *schemaChangerCollection) queueSchemaChanger(schemaChanger SchemaChanger) {
@@ -1307,6 +1348,17 @@ func (scc *schemaChangerCollection) queueSchemaChanger(schemaChanger SchemaChang
 		}{scc.curGroupNum, schemaChanger})
 }
 
+// queueTypeVersionWait registers a type whose version was incremented by the
+// transaction.
+func (scc *schemaChangerCollection) queueTypeVersionWait(typeID sqlbase.ID) {
+	for _, id := range scc.typeIDs {
+		if id == typeID {
+			return
+		}
+	}
+	scc.typeIDs = append(scc.typeIDs, typeID)
+}
+
 // execSchemaChanges releases schema leases and runs the queued
 // schema changers. This needs to be run after the transaction
 // scheduling the schema change has finished.
@@ -1354,6 +1406,19 @@ func (scc *schemaChangerCollection) execSchemaChanges(
 		}
 	}
 	scc.schemaChangers = scc.schemaChangers[:0]
+
+	// Wait for the nodes to stop using the previous versions of the altered
+	// types, so that the new members can be used anywhere once the statement
+	// returns. If the wait fails, the client is told: the new members may not
+	// be usable everywhere yet.
+	for _, id := range scc.typeIDs {
+		if _, err := e.cfg.LeaseManager.WaitForOneVersion(
+			ctx, id, base.DefaultRetryOptions(),
+		); err != nil && firstError == nil {
+			firstError = errors.Wrapf(err, "error waiting for leases on type %d", id)
+		}
+	}
+	scc.typeIDs = scc.typeIDs[:0]
 	return firstError
 }
 
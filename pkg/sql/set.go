@@ -26,7 +26,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
-	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -83,7 +82,7 @@ func (p *planner) SetVar(ctx context.Context, n *parser.SetVar) (planNode, error
 		}
 	}
 
-	v, ok := varGen[name]
+	v, ok := lookupSessionVar(name)
 	if !ok {
 		return nil, fmt.Errorf("unknown variable: %q", name)
 	}
@@ -392,16 +391,9 @@ func setTimeZone(_ context.Context, session *Session, values []parser.TypedExpr)
 	switch v := parser.UnwrapDatum(d).(type) {
 	case *parser.DString:
 		location := string(*v)
-		loc, err = timeutil.LoadLocation(location)
+		loc, err = timeutil.TimeZoneNameToLocation(location)
 		if err != nil {
-			var err1 error
-			loc, err1 = timeutil.LoadLocation(strings.ToUpper(location))
-			if err1 != nil {
-				loc, err1 = timeutil.LoadLocation(strings.ToTitle(location))
-				if err1 != nil {
-					return fmt.Errorf("cannot find time zone %q: %v", location, err)
-				}
-			}
+			return fmt.Errorf("cannot find time zone %q: %v", location, err)
 		}
 
 	case *parser.DInterval:
@@ -430,7 +422,7 @@ func setTimeZone(_ context.Context, session *Session, values []parser.TypedExpr)
 		return fmt.Errorf("bad time zone value: %s", d.String())
 	}
 	if loc == nil {
-		loc = sqlbase.FixedOffsetTimeZoneToLocation(int(offset), d.String())
+		loc = timeutil.FixedOffsetTimeZoneToLocation(int(offset), d.String())
 	}
 	session.Location = loc
 	return nil
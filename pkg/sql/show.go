@@ -210,7 +210,7 @@ func (p *planner) ShowColumns(ctx context.Context, n *parser.ShowColumns) (planN
 func (p *planner) showTableDetails(
 	ctx context.Context, showType string, t parser.NormalizableTableName, query string,
 ) (planNode, error) {
-	tn, err := t.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &t)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +224,7 @@ func (p *planner) showTableDetails(
 		if err != nil {
 			return err
 		}
-		return p.anyPrivilege(desc)
+		return p.anyPrivilege(ctx, desc)
 	}
 
 	return p.delegateQuery(ctx, showType,
@@ -524,7 +524,7 @@ func (p *planner) ShowIndex(ctx context.Context, n *parser.ShowIndex) (planNode,
 func (p *planner) ShowConstraints(
 	ctx context.Context, n *parser.ShowConstraints,
 ) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -533,7 +533,7 @@ func (p *planner) ShowConstraints(
 	if err != nil {
 		return nil, sqlbase.NewUndefinedRelationError(tn)
 	}
-	if err := p.anyPrivilege(desc); err != nil {
+	if err := p.anyPrivilege(ctx, desc); err != nil {
 		return nil, err
 	}
 
@@ -655,9 +655,45 @@ func (p *planner) ShowTransactionStatus(ctx context.Context) (planNode, error) {
 	return p.ShowVar(ctx, &parser.ShowVar{Name: "transaction status"})
 }
 
-// ShowUsers returns all the users.
-// Privileges: SELECT on system.users.
+// ShowUsers returns all the users, along with when their password expires
+// and whether they are locked out after too many failed login attempts.
+// Privileges: SELECT on system.users and system.login_attempts.
 func (p *planner) ShowUsers(ctx context.Context, n *parser.ShowUsers) (planNode, error) {
 	return p.delegateQuery(ctx, "SHOW USERS",
-		`SELECT username FROM system.users ORDER BY 1`, nil, nil)
+		`SELECT u.username, u."validUntil" AS valid_until, COALESCE(a."lockedUntil" > now(), false) AS locked `+
+			`FROM system.users AS u LEFT JOIN system.login_attempts AS a ON a.username = u.username `+
+			`WHERE u."isRole" IS NOT TRUE ORDER BY 1`, nil, nil)
+}
+
+// ShowRoles returns all the roles.
+// Privileges: SELECT on system.users.
+func (p *planner) ShowRoles(ctx context.Context, n *parser.ShowRoles) (planNode, error) {
+	return p.delegateQuery(ctx, "SHOW ROLES",
+		`SELECT username AS role FROM system.users WHERE "isRole" = true ORDER BY 1`, nil, nil)
+}
+
+// ShowRoleGrants returns the memberships of the given roles, optionally
+// restricted to the given grantees.
+// Privileges: SELECT on system.role_members.
+func (p *planner) ShowRoleGrants(ctx context.Context, n *parser.ShowRoleGrants) (planNode, error) {
+	var query bytes.Buffer
+	query.WriteString(`SELECT "role" AS "Role", member AS "Member", "isAdmin" AS "Admin" ` +
+		`FROM system.role_members WHERE true`)
+
+	var params []string
+	for _, role := range n.Roles.ToStrings() {
+		params = append(params, parser.EscapeSQLString(role))
+	}
+	if len(params) > 0 {
+		fmt.Fprintf(&query, ` AND "role" IN (%s)`, strings.Join(params, ","))
+	}
+	if n.Grantees != nil {
+		params = params[:0]
+		for _, grantee := range n.Grantees.ToStrings() {
+			params = append(params, parser.EscapeSQLString(grantee))
+		}
+		fmt.Fprintf(&query, ` AND member IN (%s)`, strings.Join(params, ","))
+	}
+	query.WriteString(" ORDER BY 1,2")
+	return p.delegateQuery(ctx, "SHOW GRANTS ON ROLE", query.String(), nil, nil)
 }
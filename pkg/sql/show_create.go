@@ -30,13 +30,20 @@ func (p *planner) showCreateView(
 	ctx context.Context, tn parser.Name, desc *sqlbase.TableDescriptor,
 ) (string, error) {
 	var buf bytes.Buffer
-	buf.WriteString("CREATE VIEW ")
+	buf.WriteString("CREATE ")
+	if desc.IsMaterializedView() {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
 	tn.Format(&buf, parser.FmtSimple)
 	buf.WriteString(" (")
-	for i, col := range desc.Columns {
-		if i > 0 {
-			buf.WriteString(", ")
+	sep := ""
+	for _, col := range desc.Columns {
+		if col.Hidden {
+			continue
 		}
+		buf.WriteString(sep)
+		sep = ", "
 		parser.Name(col.Name).Format(&buf, parser.FmtSimple)
 	}
 	fmt.Fprintf(&buf, ") AS %s", desc.ViewQuery)
@@ -134,6 +141,10 @@ func (p *planner) showCreateTable(
 	if err := p.showCreateInterleave(ctx, &desc.PrimaryIndex, &buf, dbPrefix); err != nil {
 		return "", err
 	}
+	var alloc sqlbase.DatumAlloc
+	if err := showCreatePartitioning(&alloc, desc, &desc.PrimaryIndex, &buf); err != nil {
+		return "", err
+	}
 
 	return buf.String(), nil
 }
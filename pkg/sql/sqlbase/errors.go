@@ -134,6 +134,11 @@ func IsUndefinedRelationError(err error) bool {
 	return errHasCode(err, pgerror.CodeUndefinedTableError)
 }
 
+// NewUndefinedTypeError creates an error that represents a missing type.
+func NewUndefinedTypeError(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError, "type %q does not exist", name)
+}
+
 // NewDatabaseAlreadyExistsError creates an error for a preexisting database.
 func NewDatabaseAlreadyExistsError(name string) error {
 	return pgerror.NewErrorf(pgerror.CodeDuplicateDatabaseError, "database %q already exists", name)
@@ -144,6 +149,11 @@ func NewRelationAlreadyExistsError(name string) error {
 	return pgerror.NewErrorf(pgerror.CodeDuplicateRelationError, "relation %q already exists", name)
 }
 
+// NewTypeAlreadyExistsError creates an error for a preexisting type.
+func NewTypeAlreadyExistsError(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError, "type %q already exists", name)
+}
+
 // NewWrongObjectTypeError creates a wrong object type error.
 func NewWrongObjectTypeError(name *parser.TableName, desiredObjType string) error {
 	return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError, "%q is not a %s",
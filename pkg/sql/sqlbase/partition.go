@@ -0,0 +1,222 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// EncodePartitionTuple encodes the values of the first len(datums) columns of
+// the index with the key encoding, as stored in a PartitioningDescriptor.
+func EncodePartitionTuple(idx *IndexDescriptor, datums parser.Datums) ([]byte, error) {
+	if len(datums) > len(idx.ColumnIDs) {
+		return nil, errors.Errorf("partition has %d columns but index %q has only %d",
+			len(datums), idx.Name, len(idx.ColumnIDs))
+	}
+	var key []byte
+	for i, datum := range datums {
+		dir, err := idx.ColumnDirections[i].ToEncodingDirection()
+		if err != nil {
+			return nil, err
+		}
+		if key, err = EncodeTableKey(key, datum, dir); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// DecodePartitionTuple decodes the values of the first numColumns columns of
+// the index from a tuple encoded by EncodePartitionTuple.
+func DecodePartitionTuple(
+	a *DatumAlloc, desc *TableDescriptor, idx *IndexDescriptor, numColumns int, encoded []byte,
+) (parser.Datums, error) {
+	if numColumns > len(idx.ColumnIDs) {
+		return nil, errors.Errorf("partition has %d columns but index %q has only %d",
+			numColumns, idx.Name, len(idx.ColumnIDs))
+	}
+	datums := make(parser.Datums, numColumns)
+	for i := range datums {
+		col, err := desc.FindColumnByID(idx.ColumnIDs[i])
+		if err != nil {
+			return nil, err
+		}
+		dir, err := idx.ColumnDirections[i].ToEncodingDirection()
+		if err != nil {
+			return nil, err
+		}
+		if datums[i], encoded, err = DecodeTableKey(a, col.Type.ToDatumType(), encoded, dir); err != nil {
+			return nil, err
+		}
+	}
+	if len(encoded) > 0 {
+		return nil, errors.Errorf("superfluous data in encoded partition tuple")
+	}
+	return datums, nil
+}
+
+// FindIndexByPartitionName returns the index which has a partition with the
+// given name.
+func (desc *TableDescriptor) FindIndexByPartitionName(name string) (*IndexDescriptor, error) {
+	for _, idx := range desc.AllNonDropIndexes() {
+		if idx.Partitioning.hasPartition(name) {
+			return &idx, nil
+		}
+	}
+	return nil, fmt.Errorf("partition %q does not exist", name)
+}
+
+func (p *PartitioningDescriptor) hasPartition(name string) bool {
+	for _, l := range p.List {
+		if l.Name == name {
+			return true
+		}
+	}
+	for _, r := range p.Range {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePartitioning verifies the partitioning of all the indexes of the
+// table. Partition names must be unique within the table, since zone configs
+// refer to partitions by name.
+func (desc *TableDescriptor) validatePartitioning() error {
+	partitionNames := map[string]string{}
+	addName := func(idx *IndexDescriptor, name string) error {
+		if name == "" {
+			return fmt.Errorf("index %q has a partition with an empty name", idx.Name)
+		}
+		if other, ok := partitionNames[name]; ok {
+			if other == idx.Name {
+				return fmt.Errorf("partition %q is defined more than once", name)
+			}
+			return fmt.Errorf("partition %q is defined on both index %q and index %q",
+				name, other, idx.Name)
+		}
+		partitionNames[name] = idx.Name
+		return nil
+	}
+
+	return desc.ForeachNonDropIndex(func(idx *IndexDescriptor) error {
+		part := &idx.Partitioning
+		if part.NumColumns == 0 {
+			if len(part.List) > 0 || len(part.Range) > 0 {
+				return fmt.Errorf("index %q has partitions but no partitioning columns", idx.Name)
+			}
+			return nil
+		}
+		if int(part.NumColumns) > len(idx.ColumnIDs) {
+			return fmt.Errorf("index %q is partitioned on %d columns but has only %d",
+				idx.Name, part.NumColumns, len(idx.ColumnIDs))
+		}
+		if (len(part.List) == 0) == (len(part.Range) == 0) {
+			return fmt.Errorf("index %q must have either list or range partitions", idx.Name)
+		}
+
+		values := map[string]string{}
+		for _, l := range part.List {
+			if err := addName(idx, l.Name); err != nil {
+				return err
+			}
+			if len(l.Values) == 0 {
+				return fmt.Errorf("partition %q has no values", l.Name)
+			}
+			for _, v := range l.Values {
+				if other, ok := values[string(v)]; ok {
+					return fmt.Errorf("partitions %q and %q overlap", other, l.Name)
+				}
+				values[string(v)] = l.Name
+			}
+		}
+		for i, r := range part.Range {
+			if err := addName(idx, r.Name); err != nil {
+				return err
+			}
+			if bytes.Compare(r.FromInclusive, r.ToExclusive) >= 0 {
+				return fmt.Errorf("partition %q has an empty range", r.Name)
+			}
+			for _, other := range part.Range[:i] {
+				if bytes.Compare(r.FromInclusive, other.ToExclusive) < 0 &&
+					bytes.Compare(other.FromInclusive, r.ToExclusive) < 0 {
+					return fmt.Errorf("partitions %q and %q overlap", other.Name, r.Name)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GenerateSubzoneSpans returns the spans of the table's keys to which the
+// given subzones apply, as stored in the zone config of the table. The spans
+// of partitions precede the spans of whole indexes, so that the subzone of a
+// partition takes precedence over the subzone of its index.
+func GenerateSubzoneSpans(
+	desc *TableDescriptor, subzones []config.Subzone,
+) ([]config.SubzoneSpan, error) {
+	var partitionSpans, indexSpans []config.SubzoneSpan
+	for i, subzone := range subzones {
+		idx, err := desc.FindIndexByID(IndexID(subzone.IndexID))
+		if err != nil {
+			return nil, err
+		}
+		if len(idx.Interleave.Ancestors) > 0 {
+			return nil, fmt.Errorf("index %q is interleaved and cannot have its own zone config", idx.Name)
+		}
+		prefix := roachpb.Key(encoding.EncodeUvarintAscending(nil, uint64(idx.ID)))
+		if subzone.PartitionName == "" {
+			indexSpans = append(indexSpans, config.SubzoneSpan{
+				Key: prefix, EndKey: prefix.PrefixEnd(), SubzoneIndex: int32(i),
+			})
+			continue
+		}
+
+		if !idx.Partitioning.hasPartition(subzone.PartitionName) {
+			return nil, fmt.Errorf("partition %q does not exist on index %q", subzone.PartitionName, idx.Name)
+		}
+		for _, l := range idx.Partitioning.List {
+			if l.Name != subzone.PartitionName {
+				continue
+			}
+			for _, v := range l.Values {
+				key := append(prefix[:len(prefix):len(prefix)], v...)
+				partitionSpans = append(partitionSpans, config.SubzoneSpan{
+					Key: key, EndKey: key.PrefixEnd(), SubzoneIndex: int32(i),
+				})
+			}
+		}
+		for _, r := range idx.Partitioning.Range {
+			if r.Name != subzone.PartitionName {
+				continue
+			}
+			partitionSpans = append(partitionSpans, config.SubzoneSpan{
+				Key:          append(prefix[:len(prefix):len(prefix)], r.FromInclusive...),
+				EndKey:       append(prefix[:len(prefix):len(prefix)], r.ToExclusive...),
+				SubzoneIndex: int32(i),
+			})
+		}
+	}
+	return append(partitionSpans, indexSpans...), nil
+}
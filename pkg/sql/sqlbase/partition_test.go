@@ -0,0 +1,158 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestGenerateSubzoneSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	intType := ColumnType{SemanticType: ColumnType_INT}
+	desc := TableDescriptor{
+		ID:   100,
+		Name: "t",
+		Columns: []ColumnDescriptor{
+			{ID: 1, Name: "a", Type: intType},
+			{ID: 2, Name: "b", Type: intType},
+		},
+		PrimaryIndex: makeIndexDescriptor("primary", []string{"a"}),
+		Indexes:      []IndexDescriptor{makeIndexDescriptor("b_idx", []string{"b"})},
+	}
+	desc.PrimaryIndex.ID, desc.PrimaryIndex.ColumnIDs = 1, []ColumnID{1}
+	desc.Indexes[0].ID, desc.Indexes[0].ColumnIDs = 2, []ColumnID{2}
+
+	encode := func(i int) []byte {
+		encoded, err := EncodePartitionTuple(&desc.PrimaryIndex, parser.Datums{parser.NewDInt(parser.DInt(i))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	}
+	desc.PrimaryIndex.Partitioning = PartitioningDescriptor{
+		NumColumns: 1,
+		List: []PartitioningDescriptor_List{
+			{Name: "p1", Values: [][]byte{encode(1), encode(2)}},
+		},
+	}
+	if err := desc.validatePartitioning(); err != nil {
+		t.Fatal(err)
+	}
+
+	datums, err := DecodePartitionTuple(&DatumAlloc{}, &desc, &desc.PrimaryIndex, 1, encode(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datums) != 1 || *datums[0].(*parser.DInt) != 2 {
+		t.Fatalf("expected [2], got %v", datums)
+	}
+
+	indexPrefix := func(id IndexID) roachpb.Key {
+		return roachpb.Key(encoding.EncodeUvarintAscending(nil, uint64(id)))
+	}
+	partitionKey := func(i int) roachpb.Key {
+		return append(indexPrefix(1), encode(i)...)
+	}
+
+	subzones := []config.Subzone{{IndexID: 2}, {IndexID: 1, PartitionName: "p1"}}
+	spans, err := GenerateSubzoneSpans(&desc, subzones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The spans of partitions come before the spans of whole indexes.
+	expected := []config.SubzoneSpan{
+		{Key: partitionKey(1), EndKey: partitionKey(1).PrefixEnd(), SubzoneIndex: 1},
+		{Key: partitionKey(2), EndKey: partitionKey(2).PrefixEnd(), SubzoneIndex: 1},
+		{Key: indexPrefix(2), EndKey: indexPrefix(2).PrefixEnd(), SubzoneIndex: 0},
+	}
+	if !reflect.DeepEqual(expected, spans) {
+		t.Fatalf("expected %+v, got %+v", expected, spans)
+	}
+
+	for _, subzone := range []config.Subzone{
+		{IndexID: 3},
+		{IndexID: 1, PartitionName: "p2"},
+		{IndexID: 2, PartitionName: "p1"},
+	} {
+		_, err := GenerateSubzoneSpans(&desc, []config.Subzone{subzone})
+		if !testutils.IsError(err, "does not exist") {
+			t.Errorf("%+v: expected does not exist error, got %v", subzone, err)
+		}
+	}
+}
+
+func TestValidatePartitioning(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	intType := ColumnType{SemanticType: ColumnType_INT}
+	desc := TableDescriptor{
+		ID:      100,
+		Name:    "t",
+		Columns: []ColumnDescriptor{{ID: 1, Name: "a", Type: intType}},
+	}
+	desc.PrimaryIndex = makeIndexDescriptor("primary", []string{"a"})
+	desc.PrimaryIndex.ID, desc.PrimaryIndex.ColumnIDs = 1, []ColumnID{1}
+	encode := func(i int) []byte {
+		encoded, err := EncodePartitionTuple(&desc.PrimaryIndex, parser.Datums{parser.NewDInt(parser.DInt(i))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	}
+
+	testCases := []struct {
+		part PartitioningDescriptor
+		err  string
+	}{
+		{PartitioningDescriptor{
+			NumColumns: 2,
+			List:       []PartitioningDescriptor_List{{Name: "p", Values: [][]byte{encode(1)}}},
+		}, `index "primary" is partitioned on 2 columns but has only 1`},
+		{PartitioningDescriptor{
+			NumColumns: 1,
+		}, `index "primary" must have either list or range partitions`},
+		{PartitioningDescriptor{
+			NumColumns: 1,
+			List:       []PartitioningDescriptor_List{{Name: "", Values: [][]byte{encode(1)}}},
+		}, `index "primary" has a partition with an empty name`},
+		{PartitioningDescriptor{
+			NumColumns: 1,
+			List:       []PartitioningDescriptor_List{{Name: "p"}},
+		}, `partition "p" has no values`},
+		{PartitioningDescriptor{
+			NumColumns: 1,
+			Range: []PartitioningDescriptor_Range{
+				{Name: "p1", FromInclusive: encode(1), ToExclusive: encode(3)},
+				{Name: "p2", FromInclusive: encode(3), ToExclusive: encode(5)},
+				{Name: "p3", FromInclusive: encode(0), ToExclusive: encode(2)},
+			},
+		}, `partitions "p1" and "p3" overlap`},
+	}
+	for i, tc := range testCases {
+		desc.PrimaryIndex.Partitioning = tc.part
+		if err := desc.validatePartitioning(); !testutils.IsError(err, tc.err) {
+			t.Errorf("%d: expected %q, got %v", i, tc.err, err)
+		}
+	}
+}
@@ -30,6 +30,10 @@ type ResultColumn struct {
 
 	// If set, a value won't be produced for this column; used internally.
 	Omitted bool
+
+	// If set, the session user is not allowed to read this column, and
+	// references to it are rejected with this error; used internally.
+	PrivilegeError error
 }
 
 // ResultColumns is the type used throughout the sql module to
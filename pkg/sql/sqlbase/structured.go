@@ -344,6 +344,12 @@ func (desc *TableDescriptor) IsView() bool {
 	return desc.ViewQuery != ""
 }
 
+// IsMaterializedView returns true if the TableDescriptor describes a
+// materialized view, i.e. a view whose results are stored in the kv layer.
+func (desc *TableDescriptor) IsMaterializedView() bool {
+	return desc.IsView() && desc.Materialized
+}
+
 // IsVirtualTable returns true if the TableDescriptor describes a
 // virtual Table (like the information_schema tables) and thus doesn't
 // need to be physically stored.
@@ -353,10 +359,11 @@ func (desc *TableDescriptor) IsVirtualTable() bool {
 
 // IsPhysicalTable returns true if the TableDescriptor actually describes a
 // physical Table that needs to be stored in the kv layer, as opposed to a
-// different resource like a view or a virtual table. Physical tables have
-// primary keys, column families, and indexes (unlike virtual tables).
+// different resource like a logical view or a virtual table. Physical tables
+// (and materialized views) have primary keys, column families, and indexes
+// (unlike virtual tables).
 func (desc *TableDescriptor) IsPhysicalTable() bool {
-	return desc.IsTable() && !desc.IsVirtualTable()
+	return (desc.IsTable() || desc.IsMaterializedView()) && !desc.IsVirtualTable()
 }
 
 // KeysPerRow returns the maximum number of keys used to encode a row for the
@@ -1074,12 +1081,36 @@ func (desc *TableDescriptor) ValidateTable() error {
 		if err := desc.validateTableIndexes(columnNames, colIDToFamilyID); err != nil {
 			return err
 		}
+		if err := desc.validatePartitioning(); err != nil {
+			return err
+		}
+	}
+
+	if err := desc.validatePolicies(); err != nil {
+		return err
 	}
 
 	// Validate the privilege descriptor.
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+func (desc *TableDescriptor) validatePolicies() error {
+	policyNames := make(map[string]struct{}, len(desc.Policies))
+	for _, policy := range desc.Policies {
+		if err := validateName(policy.Name, "policy"); err != nil {
+			return err
+		}
+		if _, ok := policyNames[policy.Name]; ok {
+			return fmt.Errorf("duplicate policy name: %q", policy.Name)
+		}
+		policyNames[policy.Name] = struct{}{}
+		if policy.UsingExpr == nil && policy.CheckExpr == nil {
+			return fmt.Errorf("policy %q has neither a USING nor a WITH CHECK expression", policy.Name)
+		}
+	}
+	return nil
+}
+
 func (desc *TableDescriptor) validateColumnFamilies(
 	columnIDs map[ColumnID]string,
 ) (map[ColumnID]FamilyID, error) {
@@ -1235,13 +1266,17 @@ func upperBoundColumnValueEncodedSize(col ColumnDescriptor) (int, bool) {
 	switch col.Type.SemanticType {
 	case ColumnType_BOOL:
 		typ = encoding.True
-	case ColumnType_INT, ColumnType_DATE, ColumnType_TIMESTAMP,
+	case ColumnType_INT, ColumnType_DATE, ColumnType_TIME, ColumnType_TIMESTAMP,
 		ColumnType_TIMESTAMPTZ, ColumnType_OID:
 		typ, size = encoding.Int, int(col.Type.Width)
+	case ColumnType_TIMETZ:
+		typ = encoding.TimeTZ
 	case ColumnType_FLOAT:
 		typ = encoding.Float
 	case ColumnType_INTERVAL:
 		typ = encoding.Duration
+	case ColumnType_ENUM:
+		typ = encoding.Bytes
 	case ColumnType_STRING, ColumnType_BYTES, ColumnType_COLLATEDSTRING, ColumnType_NAME, ColumnType_UUID, ColumnType_INET:
 		// STRINGs are counted as runes, so this isn't totally correct, but this
 		// seems better than always assuming the maximum rune width.
@@ -1515,6 +1550,18 @@ func (desc *TableDescriptor) FindActiveColumnByName(name string) (ColumnDescript
 	return ColumnDescriptor{}, fmt.Errorf("column %q does not exist", name)
 }
 
+// FindPolicyByName finds the row-level security policy with the specified
+// name. It returns the index of the policy in desc.Policies, or -1 if it
+// does not exist.
+func (desc *TableDescriptor) FindPolicyByName(name string) int {
+	for i := range desc.Policies {
+		if desc.Policies[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
 // FindColumnByID finds the column with specified ID.
 func (desc *TableDescriptor) FindColumnByID(id ColumnID) (*ColumnDescriptor, error) {
 	for i, c := range desc.Columns {
@@ -1745,11 +1792,12 @@ func (desc *TableDescriptor) VisibleColumns() []ColumnDescriptor {
 }
 
 // ColumnsSelectors generates Select expressions for cols.
-func ColumnsSelectors(cols []ColumnDescriptor) parser.SelectExprs {
+func ColumnsSelectors(cols []ColumnDescriptor, forUpdateOrDelete bool) parser.SelectExprs {
 	exprs := make(parser.SelectExprs, len(cols))
 	colItems := make([]parser.ColumnItem, len(cols))
 	for i, col := range cols {
 		colItems[i].ColumnName = parser.Name(col.Name)
+		colItems[i].ForUpdateOrDelete = forUpdateOrDelete
 		exprs[i].Expr = &colItems[i]
 	}
 	return exprs
@@ -1782,6 +1830,8 @@ func (c *ColumnType) SQLString() string {
 			}
 			return fmt.Sprintf("%s(%d)", c.SemanticType.String(), c.Precision)
 		}
+	case ColumnType_TIMETZ:
+		return "TIME WITH TIME ZONE"
 	case ColumnType_TIMESTAMPTZ:
 		return "TIMESTAMP WITH TIME ZONE"
 	case ColumnType_COLLATEDSTRING:
@@ -1794,6 +1844,8 @@ func (c *ColumnType) SQLString() string {
 		return fmt.Sprintf("%s COLLATE %s", ColumnType_STRING.String(), *c.Locale)
 	case ColumnType_ARRAY:
 		return c.ArrayContents.String() + "[]"
+	case ColumnType_ENUM:
+		return parser.AsString(parser.Name(c.EnumName))
 	}
 	if c.VisibleType != ColumnType_NONE {
 		return c.VisibleType.String()
@@ -1881,6 +1933,10 @@ func DatumTypeToColumnSemanticType(ptyp parser.Type) (ColumnType_SemanticType, e
 		return ColumnType_NAME, nil
 	case parser.TypeDate:
 		return ColumnType_DATE, nil
+	case parser.TypeTime:
+		return ColumnType_TIME, nil
+	case parser.TypeTimeTZ:
+		return ColumnType_TIMETZ, nil
 	case parser.TypeTimestamp:
 		return ColumnType_TIMESTAMP, nil
 	case parser.TypeTimestampTZ:
@@ -1901,6 +1957,9 @@ func DatumTypeToColumnSemanticType(ptyp parser.Type) (ColumnType_SemanticType, e
 		if ptyp.FamilyEqual(parser.TypeCollatedString) {
 			return ColumnType_COLLATEDSTRING, nil
 		}
+		if t, ok := ptyp.(parser.TEnum); ok && !t.IsAmbiguous() {
+			return ColumnType_ENUM, nil
+		}
 		return -1, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError, "unsupported result type: %s", ptyp)
 	}
 }
@@ -1912,7 +1971,24 @@ func DatumTypeToColumnType(ptyp parser.Type) (ColumnType, error) {
 	case parser.TCollatedString:
 		ctyp.SemanticType = ColumnType_COLLATEDSTRING
 		ctyp.Locale = &t.Locale
+	case parser.TEnum:
+		if t.IsAmbiguous() {
+			return ColumnType{}, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError, "unsupported result type: %s", ptyp)
+		}
+		ctyp.SemanticType = ColumnType_ENUM
+		ctyp.EnumTypeID = ID(t.TypeID)
+		ctyp.EnumName = t.TypeName
+		ctyp.EnumMembers = make([]EnumMember, len(t.Members.LogicalReps))
+		for i := range ctyp.EnumMembers {
+			ctyp.EnumMembers[i] = EnumMember{
+				PhysicalRepresentation: t.Members.PhysicalReps[i],
+				LogicalRepresentation:  t.Members.LogicalReps[i],
+			}
+		}
 	case parser.TArray:
+		if t.Typ.FamilyEqual(parser.TypeAnyEnum) {
+			return ColumnType{}, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError, "arrays of ENUM types are not supported")
+		}
 		ctyp.SemanticType = ColumnType_ARRAY
 		contents, err := DatumTypeToColumnSemanticType(t.Typ)
 		if err != nil {
@@ -1949,6 +2025,10 @@ func columnSemanticTypeToDatumType(c *ColumnType, k ColumnType_SemanticType) par
 		return parser.TypeBytes
 	case ColumnType_DATE:
 		return parser.TypeDate
+	case ColumnType_TIME:
+		return parser.TypeTime
+	case ColumnType_TIMETZ:
+		return parser.TypeTimeTZ
 	case ColumnType_TIMESTAMP:
 		return parser.TypeTimestamp
 	case ColumnType_TIMESTAMPTZ:
@@ -1972,10 +2052,30 @@ func columnSemanticTypeToDatumType(c *ColumnType, k ColumnType_SemanticType) par
 		return parser.TypeNull
 	case ColumnType_INT2VECTOR:
 		return parser.TypeIntVector
+	case ColumnType_ENUM:
+		return MakeEnumType(c.EnumTypeID, c.EnumName, c.EnumMembers)
 	}
 	return nil
 }
 
+// MakeEnumType returns the parser.TEnum for the ENUM type with the given
+// descriptor ID, name and members.
+func MakeEnumType(id ID, name string, members []EnumMember) parser.TEnum {
+	m := &parser.EnumMembers{
+		LogicalReps:  make([]string, len(members)),
+		PhysicalReps: make([][]byte, len(members)),
+	}
+	for i := range members {
+		m.LogicalReps[i] = members[i].LogicalRepresentation
+		m.PhysicalReps[i] = members[i].PhysicalRepresentation
+	}
+	return parser.TEnum{
+		TypeID:   uint32(id),
+		TypeName: name,
+		Members:  m,
+	}
+}
+
 // ToDatumType converts the ColumnType to the correct type, or nil if there is
 // no correspondence.
 func (c *ColumnType) ToDatumType() parser.Type {
@@ -2016,6 +2116,53 @@ func (desc *DatabaseDescriptor) Validate() error {
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+// SetID implements the DescriptorProto interface.
+func (desc *TypeDescriptor) SetID(id ID) {
+	desc.ID = id
+}
+
+// TypeName returns the plain type of this descriptor.
+func (desc *TypeDescriptor) TypeName() string {
+	return "type"
+}
+
+// SetName implements the DescriptorProto interface.
+func (desc *TypeDescriptor) SetName(name string) {
+	desc.Name = name
+}
+
+// Validate validates that the type descriptor is well formed: its members
+// must be unique and sorted by their physical representation.
+func (desc *TypeDescriptor) Validate() error {
+	if err := validateName(desc.Name, "type"); err != nil {
+		return err
+	}
+	if desc.ID == 0 {
+		return fmt.Errorf("invalid type ID %d", desc.ID)
+	}
+	if desc.ParentID == 0 {
+		return fmt.Errorf("invalid parent ID %d", desc.ParentID)
+	}
+	logicalReps := make(map[string]struct{}, len(desc.EnumMembers))
+	for i, m := range desc.EnumMembers {
+		if _, ok := logicalReps[m.LogicalRepresentation]; ok {
+			return fmt.Errorf("duplicate enum member %q", m.LogicalRepresentation)
+		}
+		logicalReps[m.LogicalRepresentation] = struct{}{}
+		if i > 0 && bytes.Compare(desc.EnumMembers[i-1].PhysicalRepresentation, m.PhysicalRepresentation) >= 0 {
+			return fmt.Errorf("enum members %q and %q are not sorted",
+				desc.EnumMembers[i-1].LogicalRepresentation, m.LogicalRepresentation)
+		}
+	}
+	// Validate the privilege descriptor.
+	return desc.Privileges.Validate(desc.GetID())
+}
+
+// EnumType returns the parser.TEnum described by the type descriptor.
+func (desc *TypeDescriptor) EnumType() parser.TEnum {
+	return MakeEnumType(desc.ID, desc.Name, desc.EnumMembers)
+}
+
 // GetID returns the ID of the descriptor.
 func (desc *Descriptor) GetID() ID {
 	switch t := desc.Union.(type) {
@@ -2023,6 +2170,8 @@ func (desc *Descriptor) GetID() ID {
 		return t.Table.ID
 	case *Descriptor_Database:
 		return t.Database.ID
+	case *Descriptor_Type:
+		return t.Type.ID
 	default:
 		return 0
 	}
@@ -2035,6 +2184,8 @@ func (desc *Descriptor) GetName() string {
 		return t.Table.Name
 	case *Descriptor_Database:
 		return t.Database.Name
+	case *Descriptor_Type:
+		return t.Type.Name
 	default:
 		return ""
 	}
@@ -49,7 +49,9 @@ CREATE TABLE system.descriptor (
 	UsersTableSchema = `
 CREATE TABLE system.users (
   username         STRING PRIMARY KEY,
-  "hashedPassword" BYTES
+  "hashedPassword" BYTES,
+  "isRole"         BOOL DEFAULT false,
+  "validUntil"     TIMESTAMP
 );`
 
 	// Zone settings per DB/Table.
@@ -140,6 +142,39 @@ CREATE TABLE system.web_sessions (
 	INDEX("createdAt"),
 	FAMILY(id, "hashedSecret", username, "createdAt", "expiresAt", "revokedAt", "lastUsedAt", "auditInfo")
 );`
+
+	// role_members records which users and roles are members of each role.
+	// Roles themselves are stored in system.users with "isRole" set.
+	RoleMembersTableSchema = `
+CREATE TABLE system.role_members (
+	"role"    STRING NOT NULL,
+	member    STRING NOT NULL,
+	"isAdmin" BOOL   NOT NULL,
+	PRIMARY KEY ("role", member),
+	INDEX (member)
+);`
+
+	// login_attempts records the failed password authentication attempts of
+	// users, and whether their account is locked. It is kept out of
+	// system.users, which is gossiped, so that login attempts don't cause
+	// system config updates.
+	LoginAttemptsTableSchema = `
+CREATE TABLE system.login_attempts (
+  username       STRING PRIMARY KEY,
+  "failedLogins" INT,
+  "lockedUntil"  TIMESTAMP
+);`
+
+	// protected_ts_records holds the spans whose MVCC history jobs need the GC
+	// queue to preserve, and the timestamps they need it preserved at. Each
+	// row's ProtectedTimestampRecord is a proto so that the GC queue can decode
+	// it without going through SQL.
+	ProtectedTimestampRecordsTableSchema = `
+CREATE TABLE system.protected_ts_records (
+	id      INT   DEFAULT unique_rowid() PRIMARY KEY,
+	"jobID" INT,
+	record  BYTES NOT NULL
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -191,8 +226,11 @@ var SystemAllowedPrivileges = map[ID]privilege.Lists{
 	// users will be able to modify system tables' schemas at will. CREATE and
 	// DROP privileges are allowed on the above system tables for backwards
 	// compatibility reasons only!
-	keys.JobsTableID:        {privilege.ReadWriteData},
-	keys.WebSessionsTableID: {privilege.ReadWriteData},
+	keys.JobsTableID:                      {privilege.ReadWriteData},
+	keys.WebSessionsTableID:               {privilege.ReadWriteData},
+	keys.RoleMembersTableID:               {privilege.ReadWriteData},
+	keys.LoginAttemptsTableID:             {privilege.ReadWriteData},
+	keys.ProtectedTimestampRecordsTableID: {privilege.ReadWriteData},
 }
 
 // SystemDesiredPrivileges returns the desired privilege list (i.e., the
@@ -206,6 +244,7 @@ func SystemDesiredPrivileges(id ID) privilege.List {
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
 var (
+	colTypeBool      = ColumnType{SemanticType: ColumnType_BOOL}
 	colTypeInt       = ColumnType{SemanticType: ColumnType_INT}
 	colTypeString    = ColumnType{SemanticType: ColumnType_STRING}
 	colTypeBytes     = ColumnType{SemanticType: ColumnType_BYTES}
@@ -282,6 +321,8 @@ var (
 		NextMutationID: 1,
 	}
 
+	falseString = "false"
+
 	// UsersTable is the descriptor for the users table.
 	UsersTable = TableDescriptor{
 		Name:     "users",
@@ -291,14 +332,18 @@ var (
 		Columns: []ColumnDescriptor{
 			{Name: "username", ID: 1, Type: colTypeString},
 			{Name: "hashedPassword", ID: 2, Type: colTypeBytes, Nullable: true},
+			{Name: "isRole", ID: 3, Type: colTypeBool, Nullable: true, DefaultExpr: &falseString},
+			{Name: "validUntil", ID: 4, Type: colTypeTimestamp, Nullable: true},
 		},
-		NextColumnID: 3,
+		NextColumnID: 5,
 		Families: []ColumnFamilyDescriptor{
 			{Name: "primary", ID: 0, ColumnNames: []string{"username"}, ColumnIDs: singleID1},
 			{Name: "fam_2_hashedPassword", ID: 2, ColumnNames: []string{"hashedPassword"}, ColumnIDs: []ColumnID{2}, DefaultColumnID: 2},
+			{Name: "fam_3_isRole", ID: 3, ColumnNames: []string{"isRole"}, ColumnIDs: []ColumnID{3}, DefaultColumnID: 3},
+			{Name: "fam_4_validUntil", ID: 4, ColumnNames: []string{"validUntil"}, ColumnIDs: []ColumnID{4}, DefaultColumnID: 4},
 		},
 		PrimaryIndex:   pk("username"),
-		NextFamilyID:   3,
+		NextFamilyID:   5,
 		NextIndexID:    2,
 		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.UsersTableID)),
 		FormatVersion:  InterleavedFormatVersion,
@@ -606,6 +651,99 @@ var (
 		NextMutationID: 1,
 		FormatVersion:  3,
 	}
+
+	// RoleMembersTable is the descriptor for the role_members table.
+	RoleMembersTable = TableDescriptor{
+		Name:     "role_members",
+		ID:       keys.RoleMembersTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "role", ID: 1, Type: colTypeString},
+			{Name: "member", ID: 2, Type: colTypeString},
+			{Name: "isAdmin", ID: 3, Type: colTypeBool},
+		},
+		NextColumnID: 4,
+		Families: []ColumnFamilyDescriptor{
+			{Name: "primary", ID: 0, ColumnNames: []string{"role", "member"}, ColumnIDs: []ColumnID{1, 2}},
+			{Name: "fam_3_isAdmin", ID: 3, ColumnNames: []string{"isAdmin"}, ColumnIDs: []ColumnID{3}, DefaultColumnID: 3},
+		},
+		NextFamilyID: 4,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"role", "member"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2},
+		},
+		Indexes: []IndexDescriptor{
+			{
+				Name:             "role_members_member_idx",
+				ID:               2,
+				Unique:           false,
+				ColumnNames:      []string{"member"},
+				ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC},
+				ColumnIDs:        []ColumnID{2},
+				ExtraColumnIDs:   []ColumnID{1},
+			},
+		},
+		NextIndexID:    3,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.RoleMembersTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// LoginAttemptsTable is the descriptor for the login_attempts table.
+	LoginAttemptsTable = TableDescriptor{
+		Name:     "login_attempts",
+		ID:       keys.LoginAttemptsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "username", ID: 1, Type: colTypeString},
+			{Name: "failedLogins", ID: 2, Type: colTypeInt, Nullable: true},
+			{Name: "lockedUntil", ID: 3, Type: colTypeTimestamp, Nullable: true},
+		},
+		NextColumnID: 4,
+		Families: []ColumnFamilyDescriptor{
+			{Name: "primary", ID: 0, ColumnNames: []string{"username"}, ColumnIDs: singleID1},
+			{Name: "fam_2_failedLogins", ID: 2, ColumnNames: []string{"failedLogins"}, ColumnIDs: []ColumnID{2}, DefaultColumnID: 2},
+			{Name: "fam_3_lockedUntil", ID: 3, ColumnNames: []string{"lockedUntil"}, ColumnIDs: []ColumnID{3}, DefaultColumnID: 3},
+		},
+		PrimaryIndex:   pk("username"),
+		NextFamilyID:   4,
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.LoginAttemptsTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// ProtectedTimestampRecordsTable is the descriptor for the
+	// protected_ts_records table.
+	ProtectedTimestampRecordsTable = TableDescriptor{
+		Name:     "protected_ts_records",
+		ID:       keys.ProtectedTimestampRecordsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "jobID", ID: 2, Type: colTypeInt, Nullable: true},
+			{Name: "record", ID: 3, Type: colTypeBytes},
+		},
+		NextColumnID: 4,
+		Families: []ColumnFamilyDescriptor{
+			{Name: "primary", ID: 0, ColumnNames: []string{"id"}, ColumnIDs: singleID1},
+			{Name: "fam_2_jobID", ID: 2, ColumnNames: []string{"jobID"}, ColumnIDs: []ColumnID{2}, DefaultColumnID: 2},
+			{Name: "fam_3_record", ID: 3, ColumnNames: []string{"record"}, ColumnIDs: []ColumnID{3}, DefaultColumnID: 3},
+		},
+		PrimaryIndex:   pk("id"),
+		NextFamilyID:   4,
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.ProtectedTimestampRecordsTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create the key/value pair for the default zone config entry.
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -138,6 +139,8 @@ func MakeColumnDefDescs(
 				col.Type.Width, col.Type.Precision)
 		}
 	case *parser.DateColType:
+	case *parser.TimeColType:
+	case *parser.TimeTZColType:
 	case *parser.TimestampColType:
 	case *parser.TimestampTZColType:
 	case *parser.IntervalColType:
@@ -168,6 +171,7 @@ func MakeColumnDefDescs(
 			return nil, nil, errors.Errorf("vectors of type %s are unsupported", t.ParamType)
 		}
 	case *parser.OidColType:
+	case *parser.EnumColType:
 	default:
 		return nil, nil, errors.Errorf("unexpected type %T", t)
 	}
@@ -526,6 +530,16 @@ func EncodeTableKey(b []byte, val parser.Datum, dir encoding.Direction) ([]byte,
 			return encoding.EncodeVarintAscending(b, int64(*t)), nil
 		}
 		return encoding.EncodeVarintDescending(b, int64(*t)), nil
+	case *parser.DTime:
+		if dir == encoding.Ascending {
+			return encoding.EncodeVarintAscending(b, int64(*t)), nil
+		}
+		return encoding.EncodeVarintDescending(b, int64(*t)), nil
+	case *parser.DTimeTZ:
+		if dir == encoding.Ascending {
+			return encoding.EncodeTimeTZAscending(b, t.TimeOfDay, t.OffsetSecs), nil
+		}
+		return encoding.EncodeTimeTZDescending(b, t.TimeOfDay, t.OffsetSecs), nil
 	case *parser.DTimestamp:
 		if dir == encoding.Ascending {
 			return encoding.EncodeTimeAscending(b, t.Time), nil
@@ -566,6 +580,11 @@ func EncodeTableKey(b []byte, val parser.Datum, dir encoding.Direction) ([]byte,
 			return encoding.EncodeBytesAscending(b, t.Key), nil
 		}
 		return encoding.EncodeBytesDescending(b, t.Key), nil
+	case *parser.DEnum:
+		if dir == encoding.Ascending {
+			return encoding.EncodeBytesAscending(b, t.PhysicalRep), nil
+		}
+		return encoding.EncodeBytesDescending(b, t.PhysicalRep), nil
 	case *parser.DArray:
 		for _, datum := range t.Array {
 			var err error
@@ -610,6 +629,10 @@ func EncodeTableValue(
 		return encoding.EncodeBytesValue(appendTo, uint32(colID), []byte(*t)), nil
 	case *parser.DDate:
 		return encoding.EncodeIntValue(appendTo, uint32(colID), int64(*t)), nil
+	case *parser.DTime:
+		return encoding.EncodeIntValue(appendTo, uint32(colID), int64(*t)), nil
+	case *parser.DTimeTZ:
+		return encoding.EncodeTimeTZValue(appendTo, uint32(colID), t.TimeOfDay, t.OffsetSecs), nil
 	case *parser.DTimestamp:
 		return encoding.EncodeTimeValue(appendTo, uint32(colID), t.Time), nil
 	case *parser.DTimestampTZ:
@@ -630,6 +653,8 @@ func EncodeTableValue(
 		return encoding.EncodeBytesValue(appendTo, uint32(colID), []byte(t.Contents)), nil
 	case *parser.DOid:
 		return encoding.EncodeIntValue(appendTo, uint32(colID), int64(t.DInt)), nil
+	case *parser.DEnum:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), t.PhysicalRep), nil
 	}
 	return nil, errors.Errorf("unable to encode table value: %T", val)
 }
@@ -923,6 +948,8 @@ type DatumAlloc struct {
 	dbytesAlloc       []parser.DBytes
 	ddecimalAlloc     []parser.DDecimal
 	ddateAlloc        []parser.DDate
+	dtimeAlloc        []parser.DTime
+	dtimeTzAlloc      []parser.DTimeTZ
 	dtimestampAlloc   []parser.DTimestamp
 	dtimestampTzAlloc []parser.DTimestampTZ
 	dintervalAlloc    []parser.DInterval
@@ -1010,6 +1037,30 @@ func (a *DatumAlloc) NewDDate(v parser.DDate) *parser.DDate {
 	return r
 }
 
+// NewDTime allocates a DTime.
+func (a *DatumAlloc) NewDTime(v parser.DTime) *parser.DTime {
+	buf := &a.dtimeAlloc
+	if len(*buf) == 0 {
+		*buf = make([]parser.DTime, datumAllocSize)
+	}
+	r := &(*buf)[0]
+	*r = v
+	*buf = (*buf)[1:]
+	return r
+}
+
+// NewDTimeTZ allocates a DTimeTZ.
+func (a *DatumAlloc) NewDTimeTZ(v parser.DTimeTZ) *parser.DTimeTZ {
+	buf := &a.dtimeTzAlloc
+	if len(*buf) == 0 {
+		*buf = make([]parser.DTimeTZ, datumAllocSize)
+	}
+	r := &(*buf)[0]
+	*r = v
+	*buf = (*buf)[1:]
+	return r
+}
+
 // NewDTimestamp allocates a DTimestamp.
 func (a *DatumAlloc) NewDTimestamp(v parser.DTimestamp) *parser.DTimestamp {
 	buf := &a.dtimestampAlloc
@@ -1163,6 +1214,23 @@ func DecodeTableKey(
 			rkey, t, err = encoding.DecodeVarintDescending(key)
 		}
 		return a.NewDDate(parser.DDate(t)), rkey, err
+	case parser.TypeTime:
+		var t int64
+		if dir == encoding.Ascending {
+			rkey, t, err = encoding.DecodeVarintAscending(key)
+		} else {
+			rkey, t, err = encoding.DecodeVarintDescending(key)
+		}
+		return a.NewDTime(parser.DTime(t)), rkey, err
+	case parser.TypeTimeTZ:
+		var t timeofday.TimeOfDay
+		var offsetSecs int32
+		if dir == encoding.Ascending {
+			rkey, t, offsetSecs, err = encoding.DecodeTimeTZAscending(key)
+		} else {
+			rkey, t, offsetSecs, err = encoding.DecodeTimeTZDescending(key)
+		}
+		return a.NewDTimeTZ(parser.DTimeTZ{TimeOfDay: t, OffsetSecs: offsetSecs}), rkey, err
 	case parser.TypeTimestamp:
 		var t time.Time
 		if dir == encoding.Ascending {
@@ -1229,6 +1297,19 @@ func DecodeTableKey(
 			}
 			return nil, nil, errors.Errorf("TODO(eisen): cannot decode collation key: %q", r)
 		}
+		if typ, ok := valType.(parser.TEnum); ok {
+			var r []byte
+			if dir == encoding.Ascending {
+				rkey, r, err = encoding.DecodeBytesAscending(key, nil)
+			} else {
+				rkey, r, err = encoding.DecodeBytesDescending(key, nil)
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			d, err := parser.MakeDEnumFromPhysicalRep(typ, r)
+			return d, rkey, err
+		}
 		return nil, nil, errors.Errorf("TODO(pmattis): decoded index key: %s", valType)
 	}
 }
@@ -1378,6 +1459,18 @@ func decodeUntaggedDatum(a *DatumAlloc, t parser.Type, buf []byte) (parser.Datum
 			return nil, b, err
 		}
 		return a.NewDDate(parser.DDate(data)), b, nil
+	case parser.TypeTime:
+		b, data, err := encoding.DecodeUntaggedIntValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		return a.NewDTime(parser.DTime(data)), b, nil
+	case parser.TypeTimeTZ:
+		b, t, offsetSecs, err := encoding.DecodeUntaggedTimeTZValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		return a.NewDTimeTZ(parser.DTimeTZ{TimeOfDay: t, OffsetSecs: offsetSecs}), b, nil
 	case parser.TypeTimestamp:
 		b, data, err := encoding.DecodeUntaggedTimeValue(buf)
 		if err != nil {
@@ -1409,6 +1502,13 @@ func decodeUntaggedDatum(a *DatumAlloc, t parser.Type, buf []byte) (parser.Datum
 			return parser.NewDCollatedString(string(data), typ.Locale, &a.env), b, err
 		case parser.TArray:
 			return decodeArray(a, typ.Typ, buf)
+		case parser.TEnum:
+			b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+			if err != nil {
+				return nil, b, err
+			}
+			d, err := parser.MakeDEnumFromPhysicalRep(typ, data)
+			return d, b, err
 		}
 		return nil, buf, errors.Errorf("couldn't decode type %s", t)
 	}
@@ -1624,6 +1724,16 @@ func MarshalColumnValue(col ColumnDescriptor, val parser.Datum) (roachpb.Value,
 			r.SetInt(int64(*v))
 			return r, nil
 		}
+	case ColumnType_TIME:
+		if v, ok := val.(*parser.DTime); ok {
+			r.SetInt(int64(*v))
+			return r, nil
+		}
+	case ColumnType_TIMETZ:
+		if v, ok := val.(*parser.DTimeTZ); ok {
+			r.SetBytes(encoding.EncodeUntaggedTimeTZValue(nil, v.TimeOfDay, v.OffsetSecs))
+			return r, nil
+		}
 	case ColumnType_TIMESTAMP:
 		if v, ok := val.(*parser.DTimestamp); ok {
 			r.SetTime(v.Time)
@@ -1679,6 +1789,15 @@ func MarshalColumnValue(col ColumnDescriptor, val parser.Datum) (roachpb.Value,
 			r.SetInt(int64(v.DInt))
 			return r, nil
 		}
+	case ColumnType_ENUM:
+		if v, ok := val.(*parser.DEnum); ok {
+			if v.EnumTyp.TypeID != uint32(col.Type.EnumTypeID) {
+				return r, fmt.Errorf("value type %s doesn't match type %s of column %q",
+					val.ResolvedType(), col.Type.SQLString(), col.Name)
+			}
+			r.SetBytes(v.PhysicalRep)
+			return r, nil
+		}
 	default:
 		return r, errors.Errorf("unsupported column type: %s", col.Type.SemanticType)
 	}
@@ -1769,6 +1888,10 @@ func parserTypeToEncodingType(t parser.Type) (encoding.Type, error) {
 		return encoding.Bytes, nil
 	case parser.TypeTimestamp, parser.TypeTimestampTZ, parser.TypeDate:
 		return encoding.Time, nil
+	case parser.TypeTime:
+		return encoding.Int, nil
+	case parser.TypeTimeTZ:
+		return encoding.TimeTZ, nil
 	case parser.TypeInterval:
 		return encoding.Duration, nil
 	case parser.TypeBool:
@@ -1805,6 +1928,10 @@ func encodeArrayElement(b []byte, d parser.Datum) ([]byte, error) {
 		return encoding.EncodeUntaggedDecimalValue(b, &t.Decimal), nil
 	case *parser.DDate:
 		return encoding.EncodeUntaggedIntValue(b, int64(*t)), nil
+	case *parser.DTime:
+		return encoding.EncodeUntaggedIntValue(b, int64(*t)), nil
+	case *parser.DTimeTZ:
+		return encoding.EncodeUntaggedTimeTZValue(b, t.TimeOfDay, t.OffsetSecs), nil
 	case *parser.DTimestamp:
 		return encoding.EncodeUntaggedTimeValue(b, t.Time), nil
 	case *parser.DTimestampTZ:
@@ -1877,6 +2004,22 @@ func UnmarshalColumnValue(
 			return nil, err
 		}
 		return a.NewDDate(parser.DDate(v)), nil
+	case ColumnType_TIME:
+		v, err := value.GetInt()
+		if err != nil {
+			return nil, err
+		}
+		return a.NewDTime(parser.DTime(v)), nil
+	case ColumnType_TIMETZ:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		_, t, offsetSecs, err := encoding.DecodeUntaggedTimeTZValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return a.NewDTimeTZ(parser.DTimeTZ{TimeOfDay: t, OffsetSecs: offsetSecs}), nil
 	case ColumnType_TIMESTAMP:
 		v, err := value.GetTime()
 		if err != nil {
@@ -1934,6 +2077,12 @@ func UnmarshalColumnValue(
 			return nil, err
 		}
 		return a.NewDOid(parser.MakeDOid(parser.DInt(v))), nil
+	case ColumnType_ENUM:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		return parser.MakeDEnumFromPhysicalRep(MakeEnumType(typ.EnumTypeID, typ.EnumName, typ.EnumMembers), v)
 	default:
 		return nil, errors.Errorf("unsupported column type: %s", typ.SemanticType)
 	}
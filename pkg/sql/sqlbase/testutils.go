@@ -24,10 +24,12 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/enum"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -85,6 +87,11 @@ func RandDatum(rng *rand.Rand, typ ColumnType, null bool) parser.Datum {
 		return d
 	case ColumnType_DATE:
 		return parser.NewDDate(parser.DDate(rng.Intn(10000)))
+	case ColumnType_TIME:
+		return parser.MakeDTime(timeofday.Random(rng))
+	case ColumnType_TIMETZ:
+		offsetSecs := int32(rng.Intn(2*15*60*60+1) - 15*60*60)
+		return parser.MakeDTimeTZ(timeofday.Random(rng), offsetSecs)
 	case ColumnType_TIMESTAMP:
 		return &parser.DTimestamp{Time: timeutil.Unix(rng.Int63n(1000000), rng.Int63n(1000000))}
 	case ColumnType_INTERVAL:
@@ -146,6 +153,17 @@ func RandDatum(rng *rand.Rand, typ ColumnType, null bool) parser.Datum {
 		return parser.DNull
 	case ColumnType_INT2VECTOR:
 		return parser.DNull
+	case ColumnType_ENUM:
+		if len(typ.EnumMembers) == 0 {
+			return parser.DNull
+		}
+		m := typ.EnumMembers[rng.Intn(len(typ.EnumMembers))]
+		d, err := parser.MakeDEnumFromPhysicalRep(
+			MakeEnumType(typ.EnumTypeID, typ.EnumName, typ.EnumMembers), m.PhysicalRepresentation)
+		if err != nil {
+			panic(err)
+		}
+		return d
 	default:
 		panic(fmt.Sprintf("invalid type %s", typ.String()))
 	}
@@ -173,9 +191,12 @@ func RandColumnType(rng *rand.Rand) ColumnType {
 	if typ.SemanticType == ColumnType_COLLATEDSTRING {
 		typ.Locale = RandCollationLocale(rng)
 	}
+	if typ.SemanticType == ColumnType_ENUM {
+		randEnumColumnType(rng, &typ)
+	}
 	if typ.SemanticType == ColumnType_ARRAY {
 		typ.ArrayContents = &columnSemanticTypes[rng.Intn(len(columnSemanticTypes))]
-		if *typ.ArrayContents == ColumnType_COLLATEDSTRING {
+		if *typ.ArrayContents == ColumnType_COLLATEDSTRING || *typ.ArrayContents == ColumnType_ENUM {
 			// TODO(justin): change this when collated arrays are supported.
 			s := ColumnType_STRING
 			typ.ArrayContents = &s
@@ -184,6 +205,21 @@ func RandColumnType(rng *rand.Rand) ColumnType {
 	return typ
 }
 
+// randEnumColumnType fills in the type ID, name and members of an ENUM
+// column type with a made-up type.
+func randEnumColumnType(rng *rand.Rand, typ *ColumnType) {
+	typ.EnumTypeID = keys.MaxReservedDescID + 1 + ID(rng.Intn(100))
+	typ.EnumName = fmt.Sprintf("enum%d", typ.EnumTypeID)
+	reps := enum.GenerateEvenlySpacedBytes(1 + rng.Intn(10))
+	typ.EnumMembers = make([]EnumMember, len(reps))
+	for i := range reps {
+		typ.EnumMembers[i] = EnumMember{
+			PhysicalRepresentation: reps[i],
+			LogicalRepresentation:  fmt.Sprintf("v%d", i),
+		}
+	}
+}
+
 // RandColumnTypes returns a slice of numCols random ColumnType value.
 func RandColumnTypes(rng *rand.Rand, numCols int) []ColumnType {
 	types := make([]ColumnType, numCols)
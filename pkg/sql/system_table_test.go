@@ -118,6 +118,9 @@ func TestSystemTableLiterals(t *testing.T) {
 		{keys.JobsTableID, sqlbase.JobsTableSchema, sqlbase.JobsTable},
 		{keys.SettingsTableID, sqlbase.SettingsTableSchema, sqlbase.SettingsTable},
 		{keys.WebSessionsTableID, sqlbase.WebSessionsTableSchema, sqlbase.WebSessionsTable},
+		{keys.RoleMembersTableID, sqlbase.RoleMembersTableSchema, sqlbase.RoleMembersTable},
+		{keys.LoginAttemptsTableID, sqlbase.LoginAttemptsTableSchema, sqlbase.LoginAttemptsTable},
+		{keys.ProtectedTimestampRecordsTableID, sqlbase.ProtectedTimestampRecordsTableSchema, sqlbase.ProtectedTimestampRecordsTable},
 	} {
 		gen, err := sql.CreateTestTableDescriptor(
 			context.TODO(),
@@ -227,6 +227,13 @@ type uncommittedDatabase struct {
 	dropped bool
 }
 
+// An uncommitted type is a type that has been created, altered or dropped
+// within the current transaction using the TableCollection.
+type uncommittedType struct {
+	desc    *sqlbase.TypeDescriptor
+	dropped bool
+}
+
 // TableCollection is a collection of tables held by a single session that
 // serves SQL requests, or a background job using a table descriptor. The
 // collection is cleared using releaseTables() which is called at the
@@ -256,6 +263,14 @@ type TableCollection struct {
 	// an uncommitted transaction.
 	uncommittedDatabases []uncommittedDatabase
 
+	// A collection of type descriptors valid for the timestamp, released
+	// along with the tables.
+	types []*sqlbase.TypeDescriptor
+
+	// Same as uncommittedTables applying to types created, altered or
+	// dropped within an uncommitted transaction.
+	uncommittedTypes []uncommittedType
+
 	// leaseMgr manages acquiring and releasing per-table leases.
 	leaseMgr *LeaseManager
 	// databaseCache is used as a cache for database names.
@@ -440,8 +455,94 @@ func (tc *TableCollection) releaseTables(ctx context.Context) {
 		}
 		tc.tables = tc.tables[:0]
 	}
+	if len(tc.types) > 0 {
+		log.VEventf(ctx, 2, "releasing %d types", len(tc.types))
+		for _, typ := range tc.types {
+			if err := tc.leaseMgr.ReleaseType(typ); err != nil {
+				log.Warning(ctx, err)
+			}
+		}
+		tc.types = tc.types[:0]
+	}
 	tc.uncommittedTables = nil
 	tc.uncommittedDatabases = nil
+	tc.uncommittedTypes = nil
+}
+
+// getTypeVersion returns a type descriptor with a version suitable for the
+// transaction, like getTableVersion does for tables. The type must be
+// released by calling tc.releaseTables().
+func (tc *TableCollection) getTypeVersion(
+	ctx context.Context, txn *client.Txn, vt VirtualTabler, dbName string, typeName string,
+) (*sqlbase.TypeDescriptor, error) {
+	if log.V(2) {
+		log.Infof(ctx, "planner acquiring lease on type '%s'", typeName)
+	}
+
+	dbID, err := tc.getUncommittedDatabaseID(&parser.TableName{DatabaseName: parser.Name(dbName)})
+	if err != nil {
+		return nil, err
+	}
+	if dbID == 0 {
+		// Resolve the database from the database cache when the transaction
+		// hasn't modified the database.
+		dbID, err = tc.databaseCache.getDatabaseID(ctx, tc.leaseMgr.LeaseStore.db.Txn, vt, dbName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if testDisableTableLeases {
+		return mustGetTypeDesc(ctx, txn, dbID, typeName)
+	}
+
+	// If the txn has been pushed the table collection is released and
+	// txn deadline is reset.
+	tc.resetForTxnRetry(ctx, txn)
+
+	// Walk latest to earliest, since a type can be dropped and another one
+	// created with the same name.
+	for i := len(tc.uncommittedTypes) - 1; i >= 0; i-- {
+		if typ := tc.uncommittedTypes[i]; typ.desc.ParentID == dbID && typ.desc.Name == typeName {
+			log.VEventf(ctx, 2, "found uncommitted type %d", typ.desc.ID)
+			if typ.dropped {
+				return nil, sqlbase.NewUndefinedTypeError(typeName)
+			}
+			return typ.desc, nil
+		}
+	}
+
+	for _, typ := range tc.types {
+		if typ.ParentID == dbID && typ.Name == typeName {
+			log.VEventf(ctx, 2, "found type in table collection for type '%s'", typeName)
+			return typ, nil
+		}
+	}
+
+	typ, expiration, err := tc.leaseMgr.AcquireTypeByName(ctx, txn.OrigTimestamp(), dbID, typeName)
+	switch err {
+	case nil:
+	case errTypeVersionNotLeased:
+		// The transaction is older than all the versions of the type the node
+		// can lease.
+		return mustGetTypeDesc(ctx, txn, dbID, typeName)
+	case sqlbase.ErrDescriptorNotFound:
+		return nil, sqlbase.NewUndefinedTypeError(typeName)
+	default:
+		return nil, err
+	}
+	tc.timestamp = txn.OrigTimestamp()
+	tc.types = append(tc.types, typ)
+	log.VEventf(ctx, 2, "added type '%s' to table collection", typeName)
+
+	// If the type we just acquired expires before the txn's deadline, reduce
+	// the deadline.
+	txn.UpdateDeadlineMaybe(ctx, expiration)
+	return typ, nil
+}
+
+func (tc *TableCollection) addUncommittedType(desc sqlbase.TypeDescriptor, dropped bool) {
+	tc.uncommittedTypes = append(tc.uncommittedTypes, uncommittedType{desc: &desc, dropped: dropped})
 }
 
 func (tc *TableCollection) addUncommittedTable(desc sqlbase.TableDescriptor) {
@@ -526,6 +627,11 @@ func getTableNames(
 
 	var tableNames parser.TableNames
 	for _, row := range sr {
+		// The namespace of a database also holds its user-defined types, which
+		// are skipped.
+		if isTypeID(dbDesc, sqlbase.ID(row.ValueInt())) {
+			continue
+		}
 		_, tableName, err := encoding.DecodeUnsafeStringAscending(
 			bytes.TrimPrefix(row.Key, prefix), nil)
 		if err != nil {
@@ -541,7 +647,9 @@ func getTableNames(
 	return tableNames, nil
 }
 
-func (p *planner) getAliasedTableName(n parser.TableExpr) (*parser.TableName, error) {
+func (p *planner) getAliasedTableName(
+	ctx context.Context, n parser.TableExpr,
+) (*parser.TableName, error) {
 	if ate, ok := n.(*parser.AliasedTableExpr); ok {
 		n = ate.Expr
 	}
@@ -549,7 +657,7 @@ func (p *planner) getAliasedTableName(n parser.TableExpr) (*parser.TableName, er
 	if !ok {
 		return nil, errors.Errorf("TODO(pmattis): unsupported FROM: %s", n)
 	}
-	return table.NormalizeWithDatabaseName(p.session.Database)
+	return p.normalizeTableName(ctx, table)
 }
 
 // createSchemaChangeJob finalizes the current mutations in the table
@@ -659,7 +767,8 @@ func expandTableGlob(
 }
 
 // searchAndQualifyDatabase augments the table name with the database
-// where it was found. It searches first in the session current
+// where it was found. It searches first in the session's temporary
+// database, if it was created, then in the session current
 // database, if that's defined, otherwise the search path.  The
 // provided TableName is modified in-place in case of success, and
 // left unchanged otherwise.
@@ -676,6 +785,19 @@ func (p *planner) searchAndQualifyDatabase(ctx context.Context, tn *parser.Table
 		descFunc = getTableOrViewDesc
 	}
 
+	if p.session.tempDatabaseCreated {
+		t.DatabaseName = parser.Name(p.session.tempDatabaseName)
+		desc, err := descFunc(ctx, p.txn, p.getVirtualTabler(), &t)
+		if err != nil && !sqlbase.IsUndefinedRelationError(err) && !sqlbase.IsUndefinedDatabaseError(err) {
+			return err
+		}
+		if desc != nil {
+			// Temporary tables shadow the tables of the other databases.
+			*tn = t
+			return nil
+		}
+	}
+
 	if p.session.Database != "" {
 		t.DatabaseName = parser.Name(p.session.Database)
 		desc, err := descFunc(ctx, p.txn, p.getVirtualTabler(), &t)
@@ -772,7 +894,7 @@ func (p *planner) findTableContainingIndex(
 func (p *planner) expandIndexName(
 	ctx context.Context, index *parser.TableNameWithIndex,
 ) (*parser.TableName, error) {
-	tn, err := index.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &index.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -812,7 +934,7 @@ func (p *planner) getTableAndIndex(
 	var err error
 	if tableWithIndex == nil {
 		// Variant: ALTER TABLE
-		tn, err = table.NormalizeWithDatabaseName(p.session.Database)
+		tn, err = p.normalizeTableName(ctx, table)
 	} else {
 		// Variant: ALTER INDEX
 		tn, err = p.expandIndexName(ctx, tableWithIndex)
@@ -827,7 +949,7 @@ func (p *planner) getTableAndIndex(
 	if tableDesc == nil {
 		return nil, nil, sqlbase.NewUndefinedRelationError(tn)
 	}
-	if err := p.CheckPrivilege(tableDesc, privilege); err != nil {
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege); err != nil {
 		return nil, nil, err
 	}
 
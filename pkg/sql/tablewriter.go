@@ -210,6 +210,10 @@ type tableUpsertEvaler interface {
 	// shouldUpdate returns the result of evaluating the WHERE clause of the
 	// ON CONFLICT ... DO UPDATE clause.
 	shouldUpdate(insertRow parser.Datums, existingRow parser.Datums) (bool, error)
+
+	// canUpdate returns whether the row-level security policies of the table
+	// allow the existing (conflicting) row to be updated.
+	canUpdate(existingRow parser.Datums) (bool, error)
 }
 
 // tableUpserter handles writing kvs and forming table rows for upserts.
@@ -241,6 +245,11 @@ type tableUpserter struct {
 	updateCols []sqlbase.ColumnDescriptor
 	evaler     tableUpsertEvaler
 
+	// Set for ON CONFLICT DO UPDATE on tables with row-level security, to
+	// check the updated rows against the policies of the table.
+	checkHelper *checkHelper
+	evalCtx     *parser.EvalContext
+
 	// Set by init.
 	txn                   *client.Txn
 	fkTables              sqlbase.TableLookupsByID // for fk checks in update case
@@ -297,7 +306,10 @@ func (tu *tableUpserter) init(txn *client.Txn) error {
 		// path is disabled during all mutations.
 		len(tableDesc.Mutations) == 0 &&
 		// For the fast path, all columns must be specified in the insert.
-		len(tu.ri.InsertCols) == len(tableDesc.Columns)
+		len(tu.ri.InsertCols) == len(tableDesc.Columns) &&
+		// The existing rows are needed to check them against the row-level
+		// security policies of the table.
+		tu.checkHelper == nil
 	if enableFastPath {
 		tu.fastPathBatch = tu.txn.NewBatch()
 		tu.fastPathKeys = make(map[string]struct{})
@@ -441,6 +453,15 @@ func (tu *tableUpserter) flush(
 			// If len(tu.updateCols) == 0, then we're in the DO NOTHING case.
 			if len(tu.updateCols) > 0 {
 				existingValues := existingRow[:len(tu.ru.FetchCols)]
+				if tu.checkHelper != nil {
+					canUpdate, err := tu.evaler.canUpdate(existingValues)
+					if err != nil {
+						return nil, err
+					}
+					if !canUpdate {
+						return nil, newPolicyUsingViolationError(tableDesc)
+					}
+				}
 				shouldUpdate, err := tu.evaler.shouldUpdate(insertRow, existingValues)
 				if err != nil {
 					return nil, err
@@ -454,6 +475,17 @@ func (tu *tableUpserter) flush(
 				if err != nil {
 					return nil, err
 				}
+				if tu.checkHelper != nil {
+					if err := tu.checkHelper.loadRow(tu.fetchColIDtoRowIndex, existingValues, false); err != nil {
+						return nil, err
+					}
+					if err := tu.checkHelper.loadRow(tu.updateColIDtoRowIndex, updateValues, true); err != nil {
+						return nil, err
+					}
+					if err := tu.checkHelper.check(tu.evalCtx); err != nil {
+						return nil, err
+					}
+				}
 				updatedRow, err := tu.ru.UpdateRow(ctx, b, existingValues, updateValues, traceKV)
 				if err != nil {
 					return nil, err
@@ -0,0 +1,382 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// Temporary tables
+//
+// CREATE TEMPORARY TABLE creates a table that is only visible to the
+// session that created it and that is dropped when that session ends.
+//
+// Since schemas and databases are the same thing here, the "pg_temp"
+// schema of a session is a regular database, named
+// pg_temp_<nodeID>_<uniqueID>, which is created the first time the
+// session creates a temporary table. The session user is granted all
+// privileges on it, and the temporary tables inherit them.
+//
+// Name resolution consults the temporary database before the session
+// database for unqualified names, which lets temporary tables shadow
+// permanent tables like in PostgreSQL. The name "pg_temp" is an alias
+// for the temporary database of the current session; explicit
+// references to the temporary database of another session are
+// rejected, and other sessions' temporary databases are hidden from
+// introspection.
+//
+// The temporary database is dropped by Session.Finish and by DISCARD
+// TEMP. Sessions which die with their node cannot clean up after
+// themselves; TempDatabaseSweeper periodically drops the temporary
+// databases whose node is dead, or which belong to this node but to no
+// registered session.
+
+const (
+	// tempDatabasePrefix is the prefix of the names of the databases
+	// holding the temporary tables of each session.
+	tempDatabasePrefix = "pg_temp_"
+	// tempDatabaseAlias refers to the temporary database of the current
+	// session.
+	tempDatabaseAlias = "pg_temp"
+)
+
+// makeTempDatabaseName returns the name of the temporary database of a
+// session running on the given node.
+func makeTempDatabaseName(nodeID roachpb.NodeID) string {
+	return fmt.Sprintf("%s%d_%d", tempDatabasePrefix, nodeID, parser.GenerateUniqueInt(nodeID))
+}
+
+// isTempDatabaseName returns true if the given database name is that of
+// the temporary database of some session.
+func isTempDatabaseName(name string) bool {
+	return strings.HasPrefix(name, tempDatabasePrefix)
+}
+
+// tempDatabaseNodeID returns the ID of the node on which the session
+// owning the given temporary database was running.
+func tempDatabaseNodeID(name string) (roachpb.NodeID, bool) {
+	if !isTempDatabaseName(name) {
+		return 0, false
+	}
+	parts := strings.Split(strings.TrimPrefix(name, tempDatabasePrefix), "_")
+	if len(parts) != 2 {
+		return 0, false
+	}
+	nodeID, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return roachpb.NodeID(nodeID), true
+}
+
+// isTempDatabaseVisible returns false if the given database is the
+// temporary database of a session other than the given one.
+func isTempDatabaseVisible(dbName string, session *Session) bool {
+	return !isTempDatabaseName(dbName) || dbName == session.tempDatabaseName
+}
+
+// isTempTable returns true if the given table lives in the temporary
+// database of some session.
+func isTempTable(
+	ctx context.Context, txn *client.Txn, desc *sqlbase.TableDescriptor,
+) (bool, error) {
+	if desc.IsVirtualTable() {
+		return false, nil
+	}
+	dbDesc, err := MustGetDatabaseDescByID(ctx, txn, desc.ParentID)
+	if err != nil {
+		return false, err
+	}
+	return isTempDatabaseName(dbDesc.Name), nil
+}
+
+// checkTempTableDefs rejects the parts of a CREATE TABLE statement which
+// are not supported for temporary tables.
+func checkTempTableDefs(n *parser.CreateTable) error {
+	interleaved := n.Interleave != nil
+	for _, def := range n.Defs {
+		switch t := def.(type) {
+		case *parser.IndexTableDef:
+			interleaved = interleaved || t.Interleave != nil
+		case *parser.UniqueConstraintTableDef:
+			interleaved = interleaved || t.Interleave != nil
+		}
+	}
+	if interleaved {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"temporary tables cannot be interleaved")
+	}
+	return nil
+}
+
+// checkTempTableReference checks that a table referenced by a foreign
+// key of a new table is temporary if and only if the new table is.
+func checkTempTableReference(
+	ctx context.Context, txn *client.Txn, temporary bool, referenced *sqlbase.TableDescriptor,
+) error {
+	referencedTemp, err := isTempTable(ctx, txn, referenced)
+	if err != nil {
+		return err
+	}
+	if temporary && !referencedTemp {
+		return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+			"constraints on temporary tables may reference only temporary tables")
+	}
+	if !temporary && referencedTemp {
+		return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+			"constraints on permanent tables may reference only permanent tables")
+	}
+	return nil
+}
+
+// getOrCreateTempDatabase returns the descriptor of the temporary
+// database of the session, creating it if it does not exist yet.
+func (p *planner) getOrCreateTempDatabase(
+	ctx context.Context,
+) (*sqlbase.DatabaseDescriptor, error) {
+	name := p.session.tempDatabaseName
+	if name == "" {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"temporary tables are not supported by internal sessions")
+	}
+	desc, err := getDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), name)
+	if err != nil || desc != nil {
+		return desc, err
+	}
+
+	desc = &sqlbase.DatabaseDescriptor{
+		Name:       name,
+		Privileges: sqlbase.NewDefaultPrivilegeDescriptor(),
+	}
+	desc.Privileges.Grant(p.session.User, privilege.List{privilege.ALL})
+	// The flag is set before the descriptor is written: if the
+	// transaction is aborted, the worst that can happen is a useless
+	// lookup during name resolution.
+	p.session.tempDatabaseCreated = true
+	if _, err := p.createDatabase(ctx, desc, false /* ifNotExists */); err != nil {
+		return nil, err
+	}
+	p.session.tables.addUncommittedDatabase(desc.Name, desc.ID, false /* dropped */)
+	return desc, nil
+}
+
+// resolveTempDatabaseName maps the pg_temp alias in an explicitly
+// qualified table name to the temporary database of the session, and
+// rejects references to the temporary database of another session.
+// Internal planners, which have no temporary database of their own, may
+// refer to the temporary database of any session.
+func (p *planner) resolveTempDatabaseName(tn *parser.TableName) error {
+	if tn.DBNameOriginallyOmitted || p.session.tempDatabaseName == "" {
+		return nil
+	}
+	dbName := string(tn.DatabaseName)
+	if dbName == tempDatabaseAlias {
+		tn.DatabaseName = parser.Name(p.session.tempDatabaseName)
+		return nil
+	}
+	if !isTempDatabaseVisible(dbName, p.session) {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot access temporary tables of other sessions")
+	}
+	return nil
+}
+
+// qualifyTableName qualifies an unqualified table name with the
+// temporary database of the session if it contains a table by that
+// name, and with the session database otherwise. Explicitly qualified
+// names go through resolveTempDatabaseName.
+func (p *planner) qualifyTableName(ctx context.Context, tn *parser.TableName) error {
+	if !tn.DBNameOriginallyOmitted {
+		return p.resolveTempDatabaseName(tn)
+	}
+	if p.session.tempDatabaseCreated {
+		t := *tn
+		t.DatabaseName = parser.Name(p.session.tempDatabaseName)
+		desc, err := getTableOrViewDesc(ctx, p.txn, p.getVirtualTabler(), &t)
+		if err != nil && !sqlbase.IsUndefinedDatabaseError(err) {
+			return err
+		}
+		if desc != nil {
+			*tn = t
+			return nil
+		}
+	}
+	return tn.QualifyWithDatabase(p.session.Database)
+}
+
+// normalizeTableName normalizes the given table name and qualifies it
+// using qualifyTableName.
+func (p *planner) normalizeTableName(
+	ctx context.Context, n *parser.NormalizableTableName,
+) (*parser.TableName, error) {
+	tn, err := n.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.qualifyTableName(ctx, tn); err != nil {
+		return nil, err
+	}
+	return tn, nil
+}
+
+// discardTemp returns a plan dropping the temporary database of the
+// session along with all its temporary tables.
+func (p *planner) discardTemp(ctx context.Context) (planNode, error) {
+	if !p.session.tempDatabaseCreated {
+		return &zeroNode{}, nil
+	}
+	return p.DropDatabase(ctx, &parser.DropDatabase{
+		Name:         parser.Name(p.session.tempDatabaseName),
+		IfExists:     true,
+		DropBehavior: parser.DropCascade,
+	})
+}
+
+// dropTempDatabase drops the given temporary database in its own
+// transaction.
+func dropTempDatabase(
+	ctx context.Context, db *client.DB, leaseMgr *LeaseManager, name string,
+) error {
+	stmt := fmt.Sprintf("DROP DATABASE IF EXISTS %s CASCADE", parser.AsString(parser.Name(name)))
+	return db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		_, err := InternalExecutor{LeaseManager: leaseMgr}.ExecuteStatementInTransaction(
+			ctx, "drop-temp-database", txn, stmt)
+		return err
+	})
+}
+
+// DefaultTempDatabaseSweepInterval is a reasonable interval at which to
+// look for abandoned temporary databases.
+const DefaultTempDatabaseSweepInterval = 5 * time.Minute
+
+// tempDatabaseSweeperLiveness is the subset of storage.NodeLiveness used
+// by the TempDatabaseSweeper.
+type tempDatabaseSweeperLiveness interface {
+	GetLivenesses() []storage.Liveness
+}
+
+// TempDatabaseSweeper drops the temporary databases of sessions which
+// ended without dropping them, typically because their node died.
+type TempDatabaseSweeper struct {
+	st       *cluster.Settings
+	db       *client.DB
+	clock    *hlc.Clock
+	leaseMgr *LeaseManager
+	registry *SessionRegistry
+	nodeID   *base.NodeIDContainer
+}
+
+// NewTempDatabaseSweeper creates a new TempDatabaseSweeper.
+func NewTempDatabaseSweeper(
+	st *cluster.Settings,
+	db *client.DB,
+	clock *hlc.Clock,
+	leaseMgr *LeaseManager,
+	registry *SessionRegistry,
+	nodeID *base.NodeIDContainer,
+) *TempDatabaseSweeper {
+	return &TempDatabaseSweeper{
+		st:       st,
+		db:       db,
+		clock:    clock,
+		leaseMgr: leaseMgr,
+		registry: registry,
+		nodeID:   nodeID,
+	}
+}
+
+// Start starts a goroutine sweeping abandoned temporary databases every
+// interval.
+func (s *TempDatabaseSweeper) Start(
+	stopper *stop.Stopper, nl tempDatabaseSweeperLiveness, interval time.Duration,
+) {
+	stopper.RunWorker(context.Background(), func(ctx context.Context) {
+		for {
+			select {
+			case <-time.After(interval):
+				if err := s.sweep(ctx, nl); err != nil {
+					log.Warningf(ctx, "error while sweeping temporary databases: %s", err)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// sweep drops the temporary databases which belong either to this node
+// but to no registered session, or to a node which has been dead for
+// longer than server.time_until_store_dead.
+func (s *TempDatabaseSweeper) sweep(ctx context.Context, nl tempDatabaseSweeperLiveness) error {
+	var rows []parser.Datums
+	if err := s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		rows, err = InternalExecutor{LeaseManager: s.leaseMgr}.QueryRowsInTransaction(
+			ctx, "list-temp-databases", txn,
+			`SELECT name FROM system.namespace WHERE "parentID" = $1`, keys.RootNamespaceID)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	livenesses := make(map[roachpb.NodeID]storage.Liveness)
+	for _, l := range nl.GetLivenesses() {
+		livenesses[l.NodeID] = l
+	}
+	deadAfter := storage.TimeUntilStoreDead.Get(&s.st.SV).Nanoseconds()
+	now := s.clock.Now()
+
+	for _, row := range rows {
+		name := string(parser.MustBeDString(row[0]))
+		nodeID, ok := tempDatabaseNodeID(name)
+		if !ok {
+			continue
+		}
+		if nodeID == s.nodeID.Get() {
+			if s.registry.hasTempDatabase(name) {
+				continue
+			}
+		} else {
+			l, ok := livenesses[nodeID]
+			if !ok || !hlc.Timestamp(l.Expiration).Add(deadAfter, 0).Less(now) {
+				continue
+			}
+		}
+		log.Infof(ctx, "dropping abandoned temporary database %s", name)
+		if err := dropTempDatabase(ctx, s.db, s.leaseMgr, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
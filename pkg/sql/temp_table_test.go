@@ -0,0 +1,124 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+type fakeTempDatabaseSweeperLiveness []storage.Liveness
+
+func (l fakeTempDatabaseSweeperLiveness) GetLivenesses() []storage.Liveness {
+	return l
+}
+
+// TestTempDatabaseSweeper verifies that the temporary databases of sessions
+// which are gone, or whose node is dead, are dropped, and that the others
+// are kept.
+func TestTempDatabaseSweeper(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	e := s.Executor().(*Executor)
+	// Temporary tables live as long as their session, so make sure all
+	// statements run in the same one.
+	sqlDB.SetMaxOpenConns(1)
+
+	listTempDatabases := func() []string {
+		rows, err := sqlDB.Query(
+			`SELECT name FROM system.namespace WHERE "parentID" = $1 AND name LIKE 'pg_temp_%' ORDER BY name`,
+			keys.RootNamespaceID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, name)
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return names
+	}
+
+	// The temporary database of a live session on this node.
+	if _, err := sqlDB.Exec(`CREATE TEMPORARY TABLE t (x INT)`); err != nil {
+		t.Fatal(err)
+	}
+	live := listTempDatabases()
+	if len(live) != 1 {
+		t.Fatalf("expected the temporary database of the session, found %v", live)
+	}
+
+	nodeID := e.cfg.NodeID.Get()
+	deadNodeID, liveNodeID, unknownNodeID := nodeID+1, nodeID+2, nodeID+3
+	gone := fmt.Sprintf("pg_temp_%d_1", nodeID)
+	onDeadNode := fmt.Sprintf("pg_temp_%d_1", deadNodeID)
+	onLiveNode := fmt.Sprintf("pg_temp_%d_1", liveNodeID)
+	onUnknownNode := fmt.Sprintf("pg_temp_%d_1", unknownNodeID)
+	for _, name := range []string{gone, onDeadNode, onLiveNode, onUnknownNode} {
+		// Temporary databases cannot be created directly.
+		if _, err := sqlDB.Exec(fmt.Sprintf(
+			`CREATE DATABASE tmp; ALTER DATABASE tmp RENAME TO %s`, name,
+		)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := s.Clock().Now()
+	liveness := fakeTempDatabaseSweeperLiveness{
+		{NodeID: deadNodeID, Expiration: hlc.LegacyTimestamp(now.Add(-time.Hour.Nanoseconds(), 0))},
+		{NodeID: liveNodeID, Expiration: hlc.LegacyTimestamp(now.Add(time.Hour.Nanoseconds(), 0))},
+	}
+	sweeper := NewTempDatabaseSweeper(
+		s.ClusterSettings(), kvDB, s.Clock(), s.LeaseManager().(*LeaseManager),
+		e.cfg.SessionRegistry, e.cfg.NodeID,
+	)
+	if err := sweeper.sweep(ctx, liveness); err != nil {
+		t.Fatal(err)
+	}
+
+	// The database of the session that is gone and the one on the dead node
+	// are dropped. The one of the live session is kept, as are the ones on
+	// nodes which are live or whose liveness is unknown.
+	expected := []string{live[0], onLiveNode, onUnknownNode}
+	sort.Strings(expected)
+	if actual := listTempDatabases(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected temporary databases %v, got %v", expected, actual)
+	}
+	if _, err := sqlDB.Exec(`SELECT * FROM t`); err != nil {
+		t.Fatal(err)
+	}
+}
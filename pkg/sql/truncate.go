@@ -46,7 +46,7 @@ func (p *planner) Truncate(ctx context.Context, n *parser.Truncate) (planNode, e
 		if err != nil {
 			return nil, err
 		}
-		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		if err := p.qualifyTableName(ctx, tn); err != nil {
 			return nil, err
 		}
 
@@ -61,7 +61,8 @@ func (p *planner) Truncate(ctx context.Context, n *parser.Truncate) (planNode, e
 			return nil, errors.Errorf("cannot run TRUNCATE on view %q - views are not updateable", tn)
 		}
 
-		if err := p.CheckPrivilege(tableDesc, privilege.DROP); err != nil {
+		p.maybeAudit(tableDesc, privilege.DROP)
+		if err := p.CheckPrivilege(ctx, tableDesc, privilege.DROP); err != nil {
 			return nil, err
 		}
 
@@ -90,7 +91,8 @@ func (p *planner) Truncate(ctx context.Context, n *parser.Truncate) (planNode, e
 				if n.DropBehavior != parser.DropCascade {
 					return nil, errors.Errorf("%q is referenced by foreign key from table %q", tableDesc.Name, other.Name)
 				}
-				if err := p.CheckPrivilege(other, privilege.DROP); err != nil {
+				p.maybeAudit(other, privilege.DROP)
+				if err := p.CheckPrivilege(ctx, other, privilege.DROP); err != nil {
 					return nil, err
 				}
 				toTruncate[other.ID] = struct{}{}
@@ -20,6 +20,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -58,9 +59,22 @@ func (p *planner) makeEditNode(
 			errors.Errorf("cannot run %s on view %q - views are not updateable", priv, tn)
 	}
 
-	if err := p.CheckPrivilege(tableDesc, priv); err != nil {
+	p.maybeAudit(tableDesc, priv)
+	ok, err := p.hasPrivilege(ctx, tableDesc, priv)
+	if err != nil {
 		return editNodeBase{}, err
 	}
+	if !ok && priv == privilege.UPDATE {
+		// UPDATE may have been granted on some of the columns only. Update
+		// checks the privilege on the updated columns.
+		ok, err = p.anyColumnPrivilege(ctx, tableDesc, priv)
+		if err != nil {
+			return editNodeBase{}, err
+		}
+	}
+	if !ok {
+		return editNodeBase{}, newPrivilegeError(p.session.User, priv, tableDesc)
+	}
 
 	return editNodeBase{
 		p:         p,
@@ -88,7 +102,7 @@ func (r *editNodeRun) initEditNode(
 	r.rows = rows
 	r.tw = tw
 
-	rh, err := en.p.newReturningHelper(ctx, re, desiredTypes, tn, en.tableDesc.Columns)
+	rh, err := en.p.newReturningHelper(ctx, re, desiredTypes, tn, en.tableDesc)
 	if err != nil {
 		return err
 	}
@@ -104,6 +118,13 @@ func (r *editNodeRun) startEditNode(params runParams, en *editNodeBase) error {
 			return err
 		}
 	}
+	if en.tableDesc.ID == keys.RoleMembersTableID {
+		// The role memberships cached at the current version of the
+		// descriptor are about to become stale.
+		if err := en.p.bumpRoleMembersTableVersion(params.ctx); err != nil {
+			return err
+		}
+	}
 
 	return r.rows.Start(params)
 }
@@ -200,6 +221,8 @@ func addOrMergeExpr(
 
 // Update updates columns for a selection of rows from a table.
 // Privileges: UPDATE and SELECT on table. We currently always use a select statement.
+//   UPDATE can instead be granted on the updated columns, and SELECT on the
+//   columns read by the statement.
 //   Notes: postgres requires UPDATE. Requires SELECT with WHERE clause with table.
 //          mysql requires UPDATE. Also requires SELECT with WHERE clause with table.
 func (p *planner) Update(
@@ -211,7 +234,7 @@ func (p *planner) Update(
 
 	tracing.AnnotateTrace()
 
-	tn, err := p.getAliasedTableName(n.Table)
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -241,6 +264,9 @@ func (p *planner) Update(
 	if err != nil {
 		return nil, err
 	}
+	if err := p.checkColumnPrivileges(ctx, en.tableDesc, updateCols, privilege.UPDATE); err != nil {
+		return nil, err
+	}
 
 	defaultExprs, err := sqlbase.MakeDefaultExprs(updateCols, &p.parser, &p.evalCtx)
 	if err != nil {
@@ -248,9 +274,10 @@ func (p *planner) Update(
 	}
 
 	var requestedCols []sqlbase.ColumnDescriptor
-	if _, retExprs := n.Returning.(*parser.ReturningExprs); retExprs || len(en.tableDesc.Checks) > 0 {
+	if _, retExprs := n.Returning.(*parser.ReturningExprs); retExprs ||
+		len(en.tableDesc.Checks) > 0 || p.rowLevelSecurityApplies(en.tableDesc) {
 		// TODO(dan): This could be made tighter, just the rows needed for RETURNING
-		// exprs.
+		// exprs, CHECK constraints and policies.
 		requestedCols = en.tableDesc.Columns
 	}
 
@@ -270,7 +297,7 @@ func (p *planner) Update(
 	// We construct a query containing the columns being updated, and then later merge the values
 	// they are being updated with into that renderNode to ideally reuse some of the queries.
 	rows, err := p.SelectClause(ctx, &parser.SelectClause{
-		Exprs: sqlbase.ColumnsSelectors(ru.FetchCols),
+		Exprs: sqlbase.ColumnsSelectors(ru.FetchCols, true /* forUpdateOrDelete */),
 		From:  &parser.From{Tables: []parser.TableExpr{n.Table}},
 		Where: n.Where,
 	}, nil, nil, nil, publicAndNonPublicColumns)
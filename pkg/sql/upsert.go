@@ -35,6 +35,7 @@ type upsertHelper struct {
 	p                  *planner
 	evalExprs          []parser.TypedExpr
 	whereExpr          parser.TypedExpr
+	policyExpr         parser.TypedExpr
 	sourceInfo         *dataSourceInfo
 	excludedSourceInfo *dataSourceInfo
 	curSourceRow       parser.Datums
@@ -156,6 +157,16 @@ func (p *planner) makeUpsertHelper(
 		helper.whereExpr = whereExpr
 	}
 
+	if p.rowLevelSecurityApplies(tableDesc) {
+		policyExpr, err := p.analyzePolicyExpr(
+			ctx, tableDesc, tableDesc.Columns, ivarHelper, false, /* forCheck */
+		)
+		if err != nil {
+			return nil, err
+		}
+		helper.policyExpr = policyExpr
+	}
+
 	return helper, nil
 }
 
@@ -195,6 +206,14 @@ func (uh *upsertHelper) shouldUpdate(
 	return sqlbase.RunFilter(uh.whereExpr, &uh.p.evalCtx)
 }
 
+// canUpdate returns whether the existing (conflicting) row satisfies the
+// USING expressions of the row-level security policies of the table.
+func (uh *upsertHelper) canUpdate(existingRow parser.Datums) (bool, error) {
+	uh.curSourceRow = existingRow
+
+	return sqlbase.RunFilter(uh.policyExpr, &uh.p.evalCtx)
+}
+
 // upsertExprsAndIndex returns the upsert conflict index and the (possibly
 // synthetic) SET expressions used when a row conflicts.
 func upsertExprsAndIndex(
@@ -15,23 +15,177 @@
 package sql
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// PasswordEncryption is the hash method used for passwords.
+type PasswordEncryption int64
+
+const (
+	// PasswordEncryptionSCRAM stores SCRAM-SHA-256 secrets, which clients
+	// authenticate against without sending the password.
+	PasswordEncryptionSCRAM PasswordEncryption = iota
+	// PasswordEncryptionMD5 stores Postgres MD5 hashes, for legacy clients
+	// which do not support SCRAM-SHA-256.
+	PasswordEncryptionMD5
+)
+
+// passwordEncryption controls how newly set passwords are hashed. MD5
+// password authentication is only accepted while it is set to md5.
+var passwordEncryption = settings.RegisterEnumSetting(
+	"server.user_login.password_encryption",
+	"the hash method used for new passwords; md5 also enables MD5 password authentication for legacy clients",
+	"scram-sha-256",
+	map[int64]string{
+		int64(PasswordEncryptionSCRAM): "scram-sha-256",
+		int64(PasswordEncryptionMD5):   "md5",
+	},
+)
+
+// bcryptCleartextFallback allows users whose password is still stored as a
+// legacy bcrypt hash to log in by sending their password in cleartext. The
+// hash is replaced on their first successful login.
+var bcryptCleartextFallback = settings.RegisterBoolSetting(
+	"server.user_login.bcrypt_fallback.enabled",
+	"allow users with legacy bcrypt password hashes to authenticate with a cleartext password, "+
+		"upgrading the stored hash on success",
+	false,
+)
+
+// hbaConfiguration holds the host-based authentication rules that decide
+// how client connections authenticate. See package hba for the format.
+var hbaConfiguration = settings.RegisterValidatedStringSetting(
+	"server.host_based_authentication.configuration",
+	"host-based authentication rules, one per line, in the format 'TYPE DATABASE USER [ADDRESS] METHOD'; "+
+		"if empty, clients authenticate with a certificate or password",
+	"",
+	func(s string) error {
+		_, err := hba.Parse(s)
+		return err
+	},
+)
+
+// maxFailedLogins is the number of consecutive failed password
+// authentication attempts after which an account is locked.
+var maxFailedLogins = settings.RegisterValidatedIntSetting(
+	"server.user_login.max_failed_attempts",
+	"the number of consecutive failed password authentication attempts after which "+
+		"an account is locked (0 disables the lockout)",
+	0,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set server.user_login.max_failed_attempts to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+// lockoutDuration is how long an account stays locked after too many failed
+// password authentication attempts.
+var lockoutDuration = settings.RegisterNonNegativeDurationSetting(
+	"server.user_login.lockout_duration",
+	"the duration for which an account is locked after too many failed password authentication attempts",
+	15*time.Minute,
 )
 
-// GetUserHashedPassword returns the hashedPassword for the given username if
-// found in system.users.
+// HBAConfiguration returns the host-based authentication rules, or nil if
+// none are configured. The setting is validated when it is set, so it can
+// only fail to parse if a newer node accepted a configuration this node does
+// not understand. In that case the error is logged and the last valid
+// configuration keeps being used.
+func HBAConfiguration(ctx context.Context, e *Executor) *hba.Conf {
+	s := hbaConfiguration.Get(&e.cfg.Settings.SV)
+	e.hbaConf.Lock()
+	defer e.hbaConf.Unlock()
+	if s == e.hbaConf.source {
+		return e.hbaConf.conf
+	}
+	e.hbaConf.source = s
+	if s == "" {
+		e.hbaConf.conf = nil
+		return nil
+	}
+	conf, err := hba.Parse(s)
+	if err != nil {
+		log.Errorf(ctx, "invalid host-based authentication configuration, "+
+			"keeping the previous one: %v", err)
+		return e.hbaConf.conf
+	}
+	e.hbaConf.conf = conf
+	return conf
+}
+
+// MD5PasswordAuthEnabled returns whether MD5 password authentication is
+// accepted.
+func MD5PasswordAuthEnabled(e *Executor) bool {
+	return PasswordEncryption(passwordEncryption.Get(&e.cfg.Settings.SV)) == PasswordEncryptionMD5
+}
+
+// BCryptCleartextFallbackEnabled returns whether users with legacy bcrypt
+// password hashes may authenticate with a cleartext password.
+func BCryptCleartextFallbackEnabled(e *Executor) bool {
+	return bcryptCleartextFallback.Get(&e.cfg.Settings.SV)
+}
+
+// hashPassword hashes a password with the method selected by the
+// server.user_login.password_encryption setting.
+func hashPassword(st *cluster.Settings, normalizedUsername, password string) ([]byte, error) {
+	if PasswordEncryption(passwordEncryption.Get(&st.SV)) == PasswordEncryptionMD5 {
+		return security.HashPasswordMD5(normalizedUsername, password), nil
+	}
+	return security.HashPassword(password)
+}
+
+// UpgradeUserHashedPassword rehashes the password of a user whose stored hash
+// is a legacy bcrypt hash, using the method selected by the
+// server.user_login.password_encryption setting. The password must already
+// have been verified. The hash is only replaced if it has not changed since
+// it was read.
+func UpgradeUserHashedPassword(
+	ctx context.Context,
+	executor *Executor,
+	metrics *MemoryMetrics,
+	username string,
+	oldHashedPassword []byte,
+	password string,
+) error {
+	normalizedUsername := parser.Name(username).Normalize()
+	hashedPassword, err := hashPassword(executor.cfg.Settings, normalizedUsername, password)
+	if err != nil {
+		return err
+	}
+	return executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		p := makeInternalPlanner("upgrade-pwd", txn, security.RootUser, metrics)
+		defer finishInternalPlanner(p)
+		_, err := p.exec(ctx,
+			`UPDATE system.users SET "hashedPassword" = $1 WHERE username = $2 AND "hashedPassword" = $3`,
+			hashedPassword, normalizedUsername, oldHashedPassword)
+		return err
+	})
+}
+
+// GetUserHashedPassword returns the hashedPassword and the password policy
+// for the given username if found in system.users.
 func GetUserHashedPassword(
 	ctx context.Context, executor *Executor, metrics *MemoryMetrics, username string,
-) (bool, []byte, error) {
+) (bool, []byte, security.PasswordPolicy, error) {
+	var policy security.PasswordPolicy
 	normalizedUsername := parser.Name(username).Normalize()
 	// The root user is not in system.users.
 	if normalizedUsername == security.RootUser {
-		return true, nil, nil
+		return true, nil, policy, nil
 	}
 
 	var hashedPassword []byte
@@ -39,8 +193,10 @@ func GetUserHashedPassword(
 	err := executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 		p := makeInternalPlanner("get-pwd", txn, security.RootUser, metrics)
 		defer finishInternalPlanner(p)
-		const getHashedPassword = `SELECT "hashedPassword" FROM system.users ` +
-			`WHERE username=$1`
+		// Roles cannot log in, so they are treated as nonexistent users.
+		const getHashedPassword = `SELECT u."hashedPassword", u."validUntil", a."lockedUntil" ` +
+			`FROM system.users AS u LEFT JOIN system.login_attempts AS a ON a.username = u.username ` +
+			`WHERE u.username=$1 AND u."isRole" IS NOT TRUE`
 		values, err := p.QueryRow(ctx, getHashedPassword, normalizedUsername)
 		if err != nil {
 			return errors.Errorf("error looking up user %s", normalizedUsername)
@@ -50,8 +206,63 @@ func GetUserHashedPassword(
 		}
 		exists = true
 		hashedPassword = []byte(*(values[0].(*parser.DBytes)))
+		if validUntil, ok := values[1].(*parser.DTimestamp); ok {
+			policy.ValidUntil = validUntil.Time
+		}
+		if lockedUntil, ok := values[2].(*parser.DTimestamp); ok {
+			policy.LockedUntil = lockedUntil.Time
+		}
 		return nil
 	})
 
-	return exists, hashedPassword, err
+	return exists, hashedPassword, policy, err
+}
+
+// RecordUserLoginAttempt records the outcome of a password authentication
+// attempt of the given user. Consecutive failures are counted, and once
+// there are server.user_login.max_failed_attempts of them, the account is
+// locked for server.user_login.lockout_duration. A success resets the count.
+// The attempts are recorded in system.login_attempts rather than in
+// system.users, so that they don't cause system config updates.
+func RecordUserLoginAttempt(
+	ctx context.Context, executor *Executor, metrics *MemoryMetrics, username string, success bool,
+) error {
+	normalizedUsername := parser.Name(username).Normalize()
+	if normalizedUsername == security.RootUser {
+		return nil
+	}
+	return executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		p := makeInternalPlanner("record-login", txn, security.RootUser, metrics)
+		defer finishInternalPlanner(p)
+		if success {
+			// Users that never failed to log in have no row, so successful
+			// logins only write if there is something to reset.
+			_, err := p.exec(ctx,
+				`DELETE FROM system.login_attempts WHERE username = $1`, normalizedUsername)
+			return err
+		}
+
+		values, err := p.QueryRow(ctx,
+			`SELECT "failedLogins" FROM system.login_attempts WHERE username = $1`, normalizedUsername)
+		if err != nil {
+			return err
+		}
+		failedLogins := int64(1)
+		if len(values) > 0 {
+			if count, ok := values[0].(*parser.DInt); ok {
+				failedLogins += int64(*count)
+			}
+		}
+		if maxAttempts := maxFailedLogins.Get(&executor.cfg.Settings.SV); maxAttempts > 0 && failedLogins >= maxAttempts {
+			lockedUntil := timeutil.Now().Add(lockoutDuration.Get(&executor.cfg.Settings.SV))
+			_, err = p.exec(ctx,
+				`UPSERT INTO system.login_attempts (username, "failedLogins", "lockedUntil") VALUES ($1, NULL, $2)`,
+				normalizedUsername, lockedUntil)
+			return err
+		}
+		_, err = p.exec(ctx,
+			`UPSERT INTO system.login_attempts (username, "failedLogins") VALUES ($1, $2)`,
+			normalizedUsername, failedLogins)
+		return err
+	})
 }
@@ -0,0 +1,310 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// User-defined types share the namespace of the tables in their database:
+// a type is registered in system.namespace under its database's ID, and its
+// descriptor lives in system.descriptor alongside the table descriptors. The
+// database descriptor lists the IDs of its types, so that the namespace
+// entries of types and tables can be told apart.
+//
+// Type descriptors are leased like table descriptors when resolving type
+// names in queries (see lease_type.go); schema changes read them
+// transactionally. Columns of a user-defined type hold a copy of the type's
+// members, so that rows can be encoded and decoded using the table descriptor
+// alone; altering a type rewrites the descriptors of the tables that use it,
+// and the usual table lease mechanism ensures that all the nodes observe the
+// new members before they are used.
+
+// getTypeDesc looks up the type descriptor given its name and the ID of its
+// database, returning nil if the descriptor is not found.
+func getTypeDesc(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, name string,
+) (*sqlbase.TypeDescriptor, error) {
+	desc := &sqlbase.TypeDescriptor{}
+	found, err := getDescriptor(ctx, txn, tableKey{parentID: dbID, name: name}, desc)
+	if !found {
+		return nil, err
+	}
+	return desc, err
+}
+
+// mustGetTypeDesc looks up the type descriptor given its name and the ID of
+// its database, returning an error if the descriptor is not found.
+func mustGetTypeDesc(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, name string,
+) (*sqlbase.TypeDescriptor, error) {
+	desc, err := getTypeDesc(ctx, txn, dbID, name)
+	if err != nil {
+		return nil, err
+	}
+	if desc == nil {
+		return nil, sqlbase.NewUndefinedTypeError(name)
+	}
+	return desc, nil
+}
+
+// getTypeDescByID looks up the type descriptor given its ID, returning nil if
+// the descriptor is not found.
+func getTypeDescByID(
+	ctx context.Context, txn *client.Txn, id sqlbase.ID,
+) (*sqlbase.TypeDescriptor, error) {
+	desc := &sqlbase.TypeDescriptor{}
+	found, err := getDescriptorByID(ctx, txn, id, desc)
+	if !found {
+		return nil, err
+	}
+	return desc, err
+}
+
+// getTypeDescs returns the descriptors of all the types in the given
+// database.
+func getTypeDescs(
+	ctx context.Context, txn *client.Txn, dbDesc *sqlbase.DatabaseDescriptor,
+) ([]*sqlbase.TypeDescriptor, error) {
+	if len(dbDesc.TypeIDs) == 0 {
+		return nil, nil
+	}
+	b := &client.Batch{}
+	for _, id := range dbDesc.TypeIDs {
+		b.Get(sqlbase.MakeDescMetadataKey(id))
+	}
+	if err := txn.Run(ctx, b); err != nil {
+		return nil, err
+	}
+	types := make([]*sqlbase.TypeDescriptor, 0, len(dbDesc.TypeIDs))
+	for _, res := range b.Results {
+		desc := &sqlbase.Descriptor{}
+		if err := res.Rows[0].ValueProto(desc); err != nil {
+			return nil, err
+		}
+		if typ := desc.GetType(); typ != nil {
+			types = append(types, typ)
+		}
+	}
+	return types, nil
+}
+
+// isTypeID returns whether the ID is the one of a type of the database.
+func isTypeID(dbDesc *sqlbase.DatabaseDescriptor, id sqlbase.ID) bool {
+	for _, typeID := range dbDesc.TypeIDs {
+		if typeID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// writeDatabaseTypeIDs records the IDs of the types of the database in its
+// descriptor in the current transaction. added and removed are applied to
+// dbDesc.TypeIDs first.
+func (p *planner) writeDatabaseTypeIDs(
+	ctx context.Context,
+	dbDesc *sqlbase.DatabaseDescriptor,
+	added []sqlbase.ID,
+	removed []sqlbase.ID,
+) error {
+	typeIDs := make([]sqlbase.ID, 0, len(dbDesc.TypeIDs)+len(added))
+	for _, id := range dbDesc.TypeIDs {
+		keep := true
+		for _, r := range removed {
+			if id == r {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			typeIDs = append(typeIDs, id)
+		}
+	}
+	dbDesc.TypeIDs = append(typeIDs, added...)
+	descKey := sqlbase.MakeDescMetadataKey(dbDesc.ID)
+	descVal := sqlbase.WrapDescriptor(dbDesc)
+	if p.session.Tracing.KVTracingEnabled() {
+		log.VEventf(ctx, 2, "Put %s -> %s", descKey, descVal)
+	}
+	return p.txn.Put(ctx, descKey, descVal)
+}
+
+// writeTypeDesc writes the given type descriptor in the current transaction.
+func (p *planner) writeTypeDesc(ctx context.Context, desc *sqlbase.TypeDescriptor) error {
+	if err := desc.Validate(); err != nil {
+		return err
+	}
+	p.session.tables.addUncommittedType(*desc, false /* dropped */)
+	descKey := sqlbase.MakeDescMetadataKey(desc.GetID())
+	descVal := sqlbase.WrapDescriptor(desc)
+	if p.session.Tracing.KVTracingEnabled() {
+		log.VEventf(ctx, 2, "Put %s -> %s", descKey, descVal)
+	}
+	return p.txn.Put(ctx, descKey, descVal)
+}
+
+// ResolveEnumType implements the parser.TypeResolver interface. Types are
+// looked up in the current database.
+func (p *planner) ResolveEnumType(name parser.Name) (parser.TEnum, error) {
+	if p.txn == nil || p.session.Database == "" {
+		return parser.TEnum{}, sqlbase.NewUndefinedTypeError(string(name))
+	}
+	desc, err := p.session.tables.getTypeVersion(
+		p.session.Ctx(), p.txn, p.getVirtualTabler(), p.session.Database, string(name))
+	if err != nil {
+		if sqlbase.IsUndefinedDatabaseError(err) {
+			return parser.TEnum{}, sqlbase.NewUndefinedTypeError(string(name))
+		}
+		return parser.TEnum{}, err
+	}
+	return desc.EnumType(), nil
+}
+
+// resolveColumnType looks up the user-defined type of a column definition,
+// if any. Columns can only use the types of their table's database.
+func (p *planner) resolveColumnType(
+	ctx context.Context, d *parser.ColumnTableDef, dbID sqlbase.ID,
+) error {
+	t, ok := d.Type.(*parser.EnumColType)
+	if !ok {
+		return nil
+	}
+	desc, err := mustGetTypeDesc(ctx, p.txn, dbID, string(t.Name))
+	if err != nil {
+		return err
+	}
+	t.Typ = desc.EnumType()
+	return nil
+}
+
+// addTypeBackReferences records in the descriptor of every type used by the
+// columns of the given table that the table references it.
+func (p *planner) addTypeBackReferences(
+	ctx context.Context, tableDesc *sqlbase.TableDescriptor,
+) error {
+	for _, col := range tableDesc.Columns {
+		if err := p.addTypeBackReference(ctx, col, tableDesc.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTypeBackReference records in the descriptor of the type of the given
+// column, if it has a user-defined type, that the table references it.
+func (p *planner) addTypeBackReference(
+	ctx context.Context, col sqlbase.ColumnDescriptor, tableID sqlbase.ID,
+) error {
+	if col.Type.SemanticType != sqlbase.ColumnType_ENUM {
+		return nil
+	}
+	typeDesc, err := getTypeDescByID(ctx, p.txn, col.Type.EnumTypeID)
+	if err != nil {
+		return err
+	}
+	if typeDesc == nil {
+		return sqlbase.NewUndefinedTypeError(col.Type.EnumName)
+	}
+	for _, id := range typeDesc.ReferencingDescriptorIDs {
+		if id == tableID {
+			return nil
+		}
+	}
+	typeDesc.ReferencingDescriptorIDs = append(typeDesc.ReferencingDescriptorIDs, tableID)
+	return p.writeTypeDesc(ctx, typeDesc)
+}
+
+// getReferencingTables returns the descriptors of the live tables with
+// columns of the given type. Back-references are not removed when a column or
+// table goes away, so the columns are checked again here.
+func getReferencingTables(
+	ctx context.Context, txn *client.Txn, typeDesc *sqlbase.TypeDescriptor,
+) ([]*sqlbase.TableDescriptor, error) {
+	var tables []*sqlbase.TableDescriptor
+	for _, id := range typeDesc.ReferencingDescriptorIDs {
+		tableDesc, err := sqlbase.GetTableDescFromID(ctx, txn, id)
+		if err != nil {
+			if err == sqlbase.ErrDescriptorNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if tableDesc.Dropped() {
+			continue
+		}
+		if usesType(tableDesc, typeDesc.ID) {
+			tables = append(tables, tableDesc)
+		}
+	}
+	return tables, nil
+}
+
+// usesType returns whether any column of the table, including the columns
+// being added or dropped, has the given type.
+func usesType(tableDesc *sqlbase.TableDescriptor, typeID sqlbase.ID) bool {
+	found := false
+	forEachEnumColumn(tableDesc, func(col *sqlbase.ColumnDescriptor) {
+		if col.Type.EnumTypeID == typeID {
+			found = true
+		}
+	})
+	return found
+}
+
+// forEachEnumColumn calls fn on every column of a user-defined type in the
+// table, including the columns in mutations.
+func forEachEnumColumn(tableDesc *sqlbase.TableDescriptor, fn func(*sqlbase.ColumnDescriptor)) {
+	for i := range tableDesc.Columns {
+		if col := &tableDesc.Columns[i]; col.Type.SemanticType == sqlbase.ColumnType_ENUM {
+			fn(col)
+		}
+	}
+	for i := range tableDesc.Mutations {
+		if col := tableDesc.Mutations[i].GetColumn(); col != nil &&
+			col.Type.SemanticType == sqlbase.ColumnType_ENUM {
+			fn(col)
+		}
+	}
+}
+
+// updateReferencingTables copies the members of the given type into the
+// columns of the tables that use it, and publishes the new versions of the
+// table descriptors.
+func (p *planner) updateReferencingTables(
+	ctx context.Context, typeDesc *sqlbase.TypeDescriptor,
+) error {
+	tables, err := getReferencingTables(ctx, p.txn, typeDesc)
+	if err != nil {
+		return err
+	}
+	for _, tableDesc := range tables {
+		forEachEnumColumn(tableDesc, func(col *sqlbase.ColumnDescriptor) {
+			if col.Type.EnumTypeID == typeDesc.ID {
+				col.Type.EnumMembers = append([]sqlbase.EnumMember(nil), typeDesc.EnumMembers...)
+			}
+		})
+		if err := p.saveNonmutationAndNotify(ctx, tableDesc); err != nil {
+			return errors.Wrapf(err, "updating table %q", tableDesc.Name)
+		}
+	}
+	return nil
+}
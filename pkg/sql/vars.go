@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/pkg/errors"
 )
@@ -295,7 +296,7 @@ var varGen = map[string]sessionVar{
 			// and not a standard name, then we use a magic format in the Location's
 			// name. We attempt to parse that here and retrieve the original offset
 			// specified by the user.
-			_, origRepr, parsed := sqlbase.ParseFixedOffsetTimeZone(session.Location.String())
+			_, origRepr, parsed := timeutil.ParseFixedOffsetTimeZone(session.Location.String())
 			if parsed {
 				return origRepr
 			}
@@ -394,6 +395,53 @@ func stopTracing(s *Session) error {
 	return nil
 }
 
+// customVar returns the definition of a custom session variable. As in
+// PostgreSQL, any variable whose name contains a dot can be set to a string
+// value. These variables have no meaning to the database itself, but can be
+// read by queries through current_setting(), for example to parameterize
+// row-level security policies.
+func customVar(name string) sessionVar {
+	return sessionVar{
+		Set: func(_ context.Context, session *Session, values []parser.TypedExpr) error {
+			if len(values) != 1 {
+				return fmt.Errorf("set %s: requires a single value", name)
+			}
+			evalCtx := session.evalCtx()
+			val, err := values[0].Eval(&evalCtx)
+			if err != nil {
+				return err
+			}
+			s, ok := parser.AsDString(val)
+			if !ok {
+				s = parser.DString(parser.AsStringWithFlags(val, parser.FmtBareStrings))
+			}
+			if session.CustomVars == nil {
+				session.CustomVars = make(map[string]string)
+			}
+			session.CustomVars[name] = string(s)
+			return nil
+		},
+		Get: func(session *Session) string {
+			return session.CustomVars[name]
+		},
+		Reset: func(session *Session) error {
+			delete(session.CustomVars, name)
+			return nil
+		},
+	}
+}
+
+// lookupSessionVar returns the definition of the named session variable.
+func lookupSessionVar(name string) (sessionVar, bool) {
+	if v, ok := varGen[name]; ok {
+		return v, true
+	}
+	if strings.Contains(name, ".") {
+		return customVar(name), true
+	}
+	return sessionVar{}, false
+}
+
 var varNames = func() []string {
 	res := make([]string, 0, len(varGen))
 	for vName := range varGen {
@@ -223,10 +223,21 @@ func (v *planVisitor) visit(plan planNode) {
 		v.visit(n.plan)
 
 	case *distinctNode:
+		if !n.distinctOnColIdxs.Empty() && v.observer.attr != nil {
+			var buf bytes.Buffer
+			prefix := ""
+			columns := planColumns(n.plan)
+			n.distinctOnColIdxs.ForEach(func(i int) {
+				buf.WriteString(prefix)
+				buf.WriteString(columns[i].Name)
+				prefix = ", "
+			})
+			v.observer.attr(name, "distinct on", buf.String())
+		}
 		if n.columnsInOrder != nil && v.observer.attr != nil {
 			var buf bytes.Buffer
 			prefix := ""
-			columns := planColumns(n)
+			columns := planColumns(n.plan)
 			for i, key := range n.columnsInOrder {
 				if key {
 					buf.WriteString(prefix)
@@ -518,49 +529,56 @@ func nodeName(plan planNode) string {
 // strings are constant and not precomputed so that the type names can
 // be changed without changing the output of "EXPLAIN".
 var planNodeNames = map[reflect.Type]string{
-	reflect.TypeOf(&alterTableNode{}):        "alter table",
-	reflect.TypeOf(&cancelQueryNode{}):       "cancel query",
-	reflect.TypeOf(&controlJobNode{}):        "control job",
-	reflect.TypeOf(&copyNode{}):              "copy",
-	reflect.TypeOf(&createDatabaseNode{}):    "create database",
-	reflect.TypeOf(&createIndexNode{}):       "create index",
-	reflect.TypeOf(&createTableNode{}):       "create table",
-	reflect.TypeOf(&createUserNode{}):        "create user",
-	reflect.TypeOf(&createViewNode{}):        "create view",
-	reflect.TypeOf(&delayedNode{}):           "virtual table",
-	reflect.TypeOf(&deleteNode{}):            "delete",
-	reflect.TypeOf(&distinctNode{}):          "distinct",
-	reflect.TypeOf(&dropDatabaseNode{}):      "drop database",
-	reflect.TypeOf(&dropIndexNode{}):         "drop index",
-	reflect.TypeOf(&dropTableNode{}):         "drop table",
-	reflect.TypeOf(&dropViewNode{}):          "drop view",
-	reflect.TypeOf(&dropUserNode{}):          "drop user",
-	reflect.TypeOf(&explainDistSQLNode{}):    "explain dist_sql",
-	reflect.TypeOf(&explainPlanNode{}):       "explain plan",
-	reflect.TypeOf(&traceNode{}):             "show trace for",
-	reflect.TypeOf(&filterNode{}):            "filter",
-	reflect.TypeOf(&groupNode{}):             "group",
-	reflect.TypeOf(&unaryNode{}):             "emptyrow",
-	reflect.TypeOf(&hookFnNode{}):            "plugin",
-	reflect.TypeOf(&indexJoinNode{}):         "index-join",
-	reflect.TypeOf(&insertNode{}):            "insert",
-	reflect.TypeOf(&joinNode{}):              "join",
-	reflect.TypeOf(&limitNode{}):             "limit",
-	reflect.TypeOf(&ordinalityNode{}):        "ordinality",
-	reflect.TypeOf(&testingRelocateNode{}):   "testingRelocate",
-	reflect.TypeOf(&renderNode{}):            "render",
-	reflect.TypeOf(&scanNode{}):              "scan",
-	reflect.TypeOf(&scatterNode{}):           "scatter",
-	reflect.TypeOf(&setNode{}):               "set",
-	reflect.TypeOf(&setClusterSettingNode{}): "set cluster setting",
-	reflect.TypeOf(&showRangesNode{}):        "showRanges",
-	reflect.TypeOf(&showFingerprintsNode{}):  "showFingerprints",
-	reflect.TypeOf(&sortNode{}):              "sort",
-	reflect.TypeOf(&splitNode{}):             "split",
-	reflect.TypeOf(&unionNode{}):             "union",
-	reflect.TypeOf(&updateNode{}):            "update",
-	reflect.TypeOf(&valueGenerator{}):        "generator",
-	reflect.TypeOf(&valuesNode{}):            "values",
-	reflect.TypeOf(&windowNode{}):            "window",
-	reflect.TypeOf(&zeroNode{}):              "norows",
+	reflect.TypeOf(&alterTableNode{}):              "alter table",
+	reflect.TypeOf(&alterTypeNode{}):               "alter type",
+	reflect.TypeOf(&alterUserNode{}):               "alter user",
+	reflect.TypeOf(&cancelQueryNode{}):             "cancel query",
+	reflect.TypeOf(&controlJobNode{}):              "control job",
+	reflect.TypeOf(&copyNode{}):                    "copy",
+	reflect.TypeOf(&createDatabaseNode{}):          "create database",
+	reflect.TypeOf(&createIndexNode{}):             "create index",
+	reflect.TypeOf(&createPolicyNode{}):            "create policy",
+	reflect.TypeOf(&createTableNode{}):             "create table",
+	reflect.TypeOf(&createTypeNode{}):              "create type",
+	reflect.TypeOf(&createUserNode{}):              "create user",
+	reflect.TypeOf(&createViewNode{}):              "create view",
+	reflect.TypeOf(&delayedNode{}):                 "virtual table",
+	reflect.TypeOf(&deleteNode{}):                  "delete",
+	reflect.TypeOf(&distinctNode{}):                "distinct",
+	reflect.TypeOf(&dropDatabaseNode{}):            "drop database",
+	reflect.TypeOf(&dropIndexNode{}):               "drop index",
+	reflect.TypeOf(&dropPolicyNode{}):              "drop policy",
+	reflect.TypeOf(&dropTableNode{}):               "drop table",
+	reflect.TypeOf(&dropTypeNode{}):                "drop type",
+	reflect.TypeOf(&dropViewNode{}):                "drop view",
+	reflect.TypeOf(&dropUserNode{}):                "drop user",
+	reflect.TypeOf(&refreshMaterializedViewNode{}): "refresh materialized view",
+	reflect.TypeOf(&explainDistSQLNode{}):          "explain dist_sql",
+	reflect.TypeOf(&explainPlanNode{}):             "explain plan",
+	reflect.TypeOf(&traceNode{}):                   "show trace for",
+	reflect.TypeOf(&filterNode{}):                  "filter",
+	reflect.TypeOf(&groupNode{}):                   "group",
+	reflect.TypeOf(&unaryNode{}):                   "emptyrow",
+	reflect.TypeOf(&hookFnNode{}):                  "plugin",
+	reflect.TypeOf(&indexJoinNode{}):               "index-join",
+	reflect.TypeOf(&insertNode{}):                  "insert",
+	reflect.TypeOf(&joinNode{}):                    "join",
+	reflect.TypeOf(&limitNode{}):                   "limit",
+	reflect.TypeOf(&ordinalityNode{}):              "ordinality",
+	reflect.TypeOf(&testingRelocateNode{}):         "testingRelocate",
+	reflect.TypeOf(&renderNode{}):                  "render",
+	reflect.TypeOf(&scanNode{}):                    "scan",
+	reflect.TypeOf(&scatterNode{}):                 "scatter",
+	reflect.TypeOf(&setNode{}):                     "set",
+	reflect.TypeOf(&setClusterSettingNode{}):       "set cluster setting",
+	reflect.TypeOf(&showRangesNode{}):              "showRanges",
+	reflect.TypeOf(&showFingerprintsNode{}):        "showFingerprints",
+	reflect.TypeOf(&sortNode{}):                    "sort",
+	reflect.TypeOf(&splitNode{}):                   "split",
+	reflect.TypeOf(&unionNode{}):                   "union",
+	reflect.TypeOf(&updateNode{}):                  "update",
+	reflect.TypeOf(&valueGenerator{}):              "generator",
+	reflect.TypeOf(&valuesNode{}):                  "values",
+	reflect.TypeOf(&windowNode{}):                  "window",
+	reflect.TypeOf(&zeroNode{}):                    "norows",
 }
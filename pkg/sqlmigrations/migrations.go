@@ -16,6 +16,7 @@ package sqlmigrations
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
@@ -89,6 +90,36 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		name:   "persist trace.debug.enable = 'false'",
 		workFn: disableNetTrace,
 	},
+	{
+		name:   "add system.users isRole column",
+		workFn: addRoleColumnToUsersTable,
+	},
+	{
+		name:           "create system.role_members table",
+		workFn:         createRoleMembersTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
+	{
+		name:   "enable the bcrypt password fallback for users with bcrypt password hashes",
+		workFn: enableBCryptPasswordFallback,
+	},
+	{
+		name:   "add system.users password expiration column",
+		workFn: addPasswordExpirationColumnToUsersTable,
+	},
+	{
+		name:           "create system.login_attempts table",
+		workFn:         createLoginAttemptsTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
+	{
+		name:           "create system.protected_ts_records table",
+		workFn:         createProtectedTimestampRecordsTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
 }
 
 // migrationDescriptor describes a single migration hook that's used to modify
@@ -366,6 +397,93 @@ func createWebSessionsTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, sqlbase.WebSessionsTable)
 }
 
+func createRoleMembersTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.RoleMembersTable)
+}
+
+func createProtectedTimestampRecordsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.ProtectedTimestampRecordsTable)
+}
+
+// addRoleColumnToUsersTable installs the "isRole" column of system.users on
+// clusters bootstrapped before it existed. The column is nullable and lives
+// in its own column family, so existing rows need no backfill: their missing
+// value reads as NULL, which is treated the same as false.
+func addRoleColumnToUsersTable(ctx context.Context, r runner) error {
+	return upgradeUsersTableColumns(ctx, r, "isRole")
+}
+
+// addPasswordExpirationColumnToUsersTable installs the "validUntil" column
+// of system.users on clusters bootstrapped before it existed. Like "isRole",
+// it is nullable and lives in its own column family, and NULL means that the
+// password never expires.
+func addPasswordExpirationColumnToUsersTable(ctx context.Context, r runner) error {
+	return upgradeUsersTableColumns(ctx, r, "validUntil")
+}
+
+func createLoginAttemptsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.LoginAttemptsTable)
+}
+
+// upgradeUsersTableColumns replaces the columns and column families of
+// system.users with the current ones, unless the given column already
+// exists. Like createSystemTable, this writes the descriptor at the KV level
+// because the SQL layer does not allow schema changes to system config
+// tables.
+func upgradeUsersTableColumns(ctx context.Context, r runner, column string) error {
+	return r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		desc, err := sqlbase.GetTableDescFromID(ctx, txn, keys.UsersTableID)
+		if err != nil {
+			return err
+		}
+		if _, err := desc.FindActiveColumnByName(column); err == nil {
+			return nil
+		}
+		desc.Columns = append([]sqlbase.ColumnDescriptor(nil), sqlbase.UsersTable.Columns...)
+		desc.NextColumnID = sqlbase.UsersTable.NextColumnID
+		desc.Families = append([]sqlbase.ColumnFamilyDescriptor(nil), sqlbase.UsersTable.Families...)
+		desc.NextFamilyID = sqlbase.UsersTable.NextFamilyID
+		desc.Version++
+		if err := txn.SetSystemConfigTrigger(); err != nil {
+			return err
+		}
+		return txn.Put(ctx, sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(desc))
+	})
+}
+
+// enableBCryptPasswordFallback turns on the
+// server.user_login.bcrypt_fallback.enabled setting if any user's password is
+// still hashed with bcrypt, as it was before SCRAM-SHA-256 was supported.
+// Such users can only log in with a cleartext password, which is otherwise
+// rejected. Their hashes are upgraded on their next successful login, after
+// which the setting can be turned off.
+func enableBCryptPasswordFallback(ctx context.Context, r runner) error {
+	session := r.newRootSession(ctx)
+	defer session.Finish(r.sqlExecutor)
+	res, err := r.sqlExecutor.ExecuteStatementsBuffered(
+		session, `SELECT username, "hashedPassword" FROM system.users WHERE "hashedPassword" IS NOT NULL`, nil, 1)
+	if err != nil {
+		return err
+	}
+	defer res.Close(ctx)
+	var users []string
+	rows := res.ResultList[0].Rows
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.At(i)
+		if security.IsBCryptHash([]byte(*row[1].(*parser.DBytes))) {
+			users = append(users, string(parser.MustBeDString(row[0])))
+		}
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	log.Infof(ctx, "the passwords of users %s are hashed with bcrypt; enabling "+
+		"server.user_login.bcrypt_fallback.enabled so that they can log in and have "+
+		"their hashes upgraded", strings.Join(users, ", "))
+	return runStmtAsRootWithRetry(
+		ctx, r, `SET CLUSTER SETTING server.user_login.bcrypt_fallback.enabled = true`)
+}
+
 func createSystemTable(ctx context.Context, r runner, desc sqlbase.TableDescriptor) error {
 	// We install the table at the KV layer so that we can choose a known ID in
 	// the reserved ID space. (The SQL layer doesn't allow this.)
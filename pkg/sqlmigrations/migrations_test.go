@@ -16,7 +16,9 @@ package sqlmigrations
 
 import (
 	"bytes"
+	gosql "database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -28,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -38,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -727,3 +731,79 @@ CREATE INDEX y ON test.x(x);
 		t.Fatal(err)
 	}
 }
+
+// TestBCryptPasswordFallbackMigration verifies that users whose passwords
+// are still hashed with bcrypt can log in after the upgrade, and that their
+// hashes are upgraded when they do.
+func TestBCryptPasswordFallbackMigration(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	// Hijack the migration process to capture the SQL memory metric object,
+	// needed to run the migration below.
+	var memMetrics *sql.MemoryMetrics
+	newMigrations := append([]migrationDescriptor(nil), backwardCompatibleMigrations...)
+	newMigrations = append(newMigrations,
+		migrationDescriptor{
+			name: "capture mem metrics",
+			workFn: func(ctx context.Context, r runner) error {
+				memMetrics = r.memMetrics
+				return nil
+			},
+		})
+	defer func(prev []migrationDescriptor) { backwardCompatibleMigrations = prev }(backwardCompatibleMigrations)
+	backwardCompatibleMigrations = newMigrations
+
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	e := s.Executor().(*sql.Executor)
+
+	// Simulate a user created before SCRAM-SHA-256 was supported.
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`CREATE USER bcrypt_user`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(
+		`UPDATE system.users SET "hashedPassword" = $1 WHERE username = 'bcrypt_user'`, hashedPassword,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	pgURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword("bcrypt_user", "hunter2"),
+		Host:     s.ServingAddr(),
+		RawQuery: "sslmode=require",
+	}
+	login := func() error {
+		db, err := gosql.Open("postgres", pgURL.String())
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		_, err = db.Exec("SELECT 1")
+		return err
+	}
+	if err := login(); !testutils.IsError(err, "password of user bcrypt_user must be reset") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := enableBCryptPasswordFallback(
+		ctx, runner{db: kvDB, sqlExecutor: e, memMetrics: memMetrics},
+	); err != nil {
+		t.Fatal(err)
+	}
+	testutils.SucceedsSoon(t, login)
+
+	if err := sqlDB.QueryRow(
+		`SELECT "hashedPassword" FROM system.users WHERE username = 'bcrypt_user'`,
+	).Scan(&hashedPassword); err != nil {
+		t.Fatal(err)
+	}
+	if !security.IsScramHash(hashedPassword) {
+		t.Fatalf("expected the password hash to be upgraded, got %q", hashedPassword)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 const (
@@ -52,11 +53,19 @@ const (
 	minReplicaWeight = 0.001
 
 	// priorities for various repair operations.
+	removeLearnerPriority                 float64 = 12000
 	addMissingReplicaPriority             float64 = 10000
 	addDecommissioningReplacementPriority float64 = 5000
 	removeDeadReplicaPriority             float64 = 1000
 	removeDecommissioningReplicaPriority  float64 = 200
 	removeExtraReplicaPriority            float64 = 100
+
+	// Non-voting replicas do not affect the availability of a range, so they
+	// are repaired after the voting replicas.
+	removeDeadNonVoterPriority            float64 = 60
+	removeDecommissioningNonVoterPriority float64 = 50
+	addMissingNonVoterPriority            float64 = 40
+	removeExtraNonVoterPriority           float64 = 30
 )
 
 // MinLeaseTransferStatsDuration configures the minimum amount of time a
@@ -103,15 +112,25 @@ const (
 	AllocatorRemoveDead
 	AllocatorRemoveDecommissioning
 	AllocatorConsiderRebalance
+	AllocatorAddNonVoter
+	AllocatorRemoveNonVoter
+	AllocatorRemoveDeadNonVoter
+	AllocatorRemoveDecommissioningNonVoter
+	AllocatorRemoveLearner
 )
 
 var allocatorActionNames = map[AllocatorAction]string{
-	AllocatorNoop:                  "noop",
-	AllocatorRemove:                "remove",
-	AllocatorAdd:                   "add",
-	AllocatorRemoveDead:            "remove dead",
-	AllocatorRemoveDecommissioning: "remove decommissioning",
-	AllocatorConsiderRebalance:     "consider rebalance",
+	AllocatorNoop:                          "noop",
+	AllocatorRemove:                        "remove",
+	AllocatorAdd:                           "add",
+	AllocatorRemoveDead:                    "remove dead",
+	AllocatorRemoveDecommissioning:         "remove decommissioning",
+	AllocatorConsiderRebalance:             "consider rebalance",
+	AllocatorAddNonVoter:                   "add non-voter",
+	AllocatorRemoveNonVoter:                "remove non-voter",
+	AllocatorRemoveDeadNonVoter:            "remove dead non-voter",
+	AllocatorRemoveDecommissioningNonVoter: "remove decommissioning non-voter",
+	AllocatorRemoveLearner:                 "remove learner",
 }
 
 func (a AllocatorAction) String() string {
@@ -181,12 +200,40 @@ type RangeInfo struct {
 	Desc            *roachpb.RangeDescriptor
 	LogicalBytes    int64
 	WritesPerSecond float64
+	// AbandonedLearners are the learners of the range which are no longer
+	// being caught up by a replica addition. See Replica.abandonedLearners.
+	AbandonedLearners []roachpb.ReplicaDescriptor
+}
+
+// voterRangeInfo returns a copy of the RangeInfo whose descriptor only
+// contains the voting replicas of the range.
+func voterRangeInfo(info RangeInfo) RangeInfo {
+	if len(info.Desc.Voters()) == len(info.Desc.Replicas) {
+		return info
+	}
+	desc := *info.Desc
+	desc.Replicas = desc.Voters()
+	info.Desc = &desc
+	return info
+}
+
+// nonVotingReplicas returns the replicas of the range which do not vote,
+// including learners which have not been promoted yet.
+func nonVotingReplicas(desc *roachpb.RangeDescriptor) []roachpb.ReplicaDescriptor {
+	var replicas []roachpb.ReplicaDescriptor
+	for _, rep := range desc.Replicas {
+		if !rep.IsVoter() {
+			replicas = append(replicas, rep)
+		}
+	}
+	return replicas
 }
 
 func rangeInfoForRepl(repl *Replica, desc *roachpb.RangeDescriptor) RangeInfo {
 	info := RangeInfo{
-		Desc:         desc,
-		LogicalBytes: repl.GetMVCCStats().Total(),
+		Desc:              desc,
+		LogicalBytes:      repl.GetMVCCStats().Total(),
+		AbandonedLearners: repl.abandonedLearners(desc, timeutil.Now()),
 	}
 	if writesPerSecond, dur := repl.writeStats.avgQPS(); dur >= MinStatsDuration {
 		info.WritesPerSecond = writesPerSecond
@@ -225,6 +272,12 @@ func MakeAllocator(
 // ComputeAction determines the exact operation needed to repair the
 // supplied range, as governed by the supplied zone configuration. It
 // returns the required action that should be taken and a priority.
+//
+// Learners left behind by failed replica additions are removed first, and no
+// action is taken while other learners are being caught up. The voting
+// replicas of the range are then repaired before its non-voting replicas,
+// which are only considered once the voting replicas match the zone
+// configuration.
 func (a *Allocator) ComputeAction(
 	ctx context.Context, zone config.ZoneConfig, rangeInfo RangeInfo,
 ) (AllocatorAction, float64) {
@@ -233,9 +286,24 @@ func (a *Allocator) ComputeAction(
 		return AllocatorNoop, 0
 	}
 
+	if learners := rangeInfo.AbandonedLearners; len(learners) > 0 {
+		// A learner is only left behind by a replica addition which failed
+		// before promoting it. Remove it before taking any other action.
+		log.VEventf(ctx, 3, "AllocatorRemoveLearner - learners=%v, priority=%.2f",
+			learners, removeLearnerPriority)
+		return AllocatorRemoveLearner, removeLearnerPriority
+	}
+	if learners := rangeInfo.Desc.Learners(); len(learners) > 0 {
+		// The replica additions which created the learners are still in
+		// flight. Any other change would race with them.
+		log.VEventf(ctx, 3, "AllocatorNoop - waiting for learners=%v to be promoted", learners)
+		return AllocatorNoop, 0
+	}
+
 	// TODO(mrtracy): Handle non-homogeneous and mismatched attribute sets.
+	voters := rangeInfo.Desc.Voters()
 	need := int(zone.NumReplicas)
-	have := len(rangeInfo.Desc.Replicas)
+	have := len(voters)
 	quorum := computeQuorum(need)
 	if have < need {
 		// Range is under-replicated, and should add an additional replica.
@@ -246,7 +314,7 @@ func (a *Allocator) ComputeAction(
 		return AllocatorAdd, priority
 	}
 
-	decommissioningReplicas := a.storePool.decommissioningReplicas(rangeInfo.Desc.RangeID, rangeInfo.Desc.Replicas)
+	decommissioningReplicas := a.storePool.decommissioningReplicas(rangeInfo.Desc.RangeID, voters)
 	if have == need && len(decommissioningReplicas) > 0 {
 		// Range has decommissioning replica(s). We should up-replicate to add
 		// another replica. The decommissioning replica(s) will be down-replicated
@@ -257,7 +325,7 @@ func (a *Allocator) ComputeAction(
 		return AllocatorAdd, priority
 	}
 
-	liveReplicas, deadReplicas := a.storePool.liveAndDeadReplicas(rangeInfo.Desc.RangeID, rangeInfo.Desc.Replicas)
+	liveReplicas, deadReplicas := a.storePool.liveAndDeadReplicas(rangeInfo.Desc.RangeID, voters)
 	if len(liveReplicas) < quorum {
 		// Do not take any removal action if we do not have a quorum of live
 		// replicas.
@@ -316,10 +384,55 @@ func (a *Allocator) ComputeAction(
 		return AllocatorRemove, priority
 	}
 
+	if action, priority := a.computeNonVoterAction(ctx, zone, rangeInfo); action != AllocatorNoop {
+		return action, priority
+	}
+
 	// Nothing needs to be done, but we may want to rebalance.
 	return AllocatorConsiderRebalance, 0
 }
 
+// computeNonVoterAction determines the operation needed to repair the
+// non-voting replicas of the supplied range. Since non-voting replicas do not
+// participate in the quorum, dead and decommissioning ones are removed
+// without waiting for a replacement.
+func (a *Allocator) computeNonVoterAction(
+	ctx context.Context, zone config.ZoneConfig, rangeInfo RangeInfo,
+) (AllocatorAction, float64) {
+	if !a.storePool.st.Version.IsActive(cluster.VersionNonVoterReplicas) {
+		// Nodes running older binaries would treat non-voting replicas as
+		// voters.
+		return AllocatorNoop, 0
+	}
+	nonVoters := rangeInfo.Desc.NonVoters()
+	need := int(zone.NumNonVoters)
+	have := len(nonVoters)
+
+	if _, dead := a.storePool.liveAndDeadReplicas(rangeInfo.Desc.RangeID, nonVoters); len(dead) > 0 {
+		log.VEventf(ctx, 3, "AllocatorRemoveDeadNonVoter - dead=%d, priority=%.2f",
+			len(dead), removeDeadNonVoterPriority)
+		return AllocatorRemoveDeadNonVoter, removeDeadNonVoterPriority
+	}
+	if decommissioning := a.storePool.decommissioningReplicas(
+		rangeInfo.Desc.RangeID, nonVoters,
+	); len(decommissioning) > 0 {
+		log.VEventf(ctx, 3, "AllocatorRemoveDecommissioningNonVoter - num_decommissioning=%d, priority=%.2f",
+			len(decommissioning), removeDecommissioningNonVoterPriority)
+		return AllocatorRemoveDecommissioningNonVoter, removeDecommissioningNonVoterPriority
+	}
+	if have < need {
+		log.VEventf(ctx, 3, "AllocatorAddNonVoter - need=%d, have=%d, priority=%.2f",
+			need, have, addMissingNonVoterPriority)
+		return AllocatorAddNonVoter, addMissingNonVoterPriority
+	}
+	if have > need {
+		log.VEventf(ctx, 3, "AllocatorRemoveNonVoter - need=%d, have=%d, priority=%.2f",
+			need, have, removeExtraNonVoterPriority)
+		return AllocatorRemoveNonVoter, removeExtraNonVoterPriority
+	}
+	return AllocatorNoop, 0
+}
+
 type decisionDetails struct {
 	Target               string
 	Existing             string `json:",omitempty"`
@@ -342,6 +455,8 @@ func (a *Allocator) AllocateTarget(
 	relaxConstraints bool,
 ) (*roachpb.StoreDescriptor, string, error) {
 	sl, _, throttledStoreCount := a.storePool.getStoreList(rangeInfo.Desc.RangeID, storeFilterThrottled)
+	// A node holds at most one replica of a range, voting or not.
+	sl = sl.excludeReplicaNodes(nonVotingReplicas(rangeInfo.Desc))
 
 	candidates := allocateCandidates(
 		a.storePool.st,
@@ -471,7 +586,11 @@ func (a Allocator) RebalanceTarget(
 	rangeInfo RangeInfo,
 	filter storeFilter,
 ) (*roachpb.StoreDescriptor, string) {
+	// Only voting replicas are rebalanced, and never onto a node which
+	// already holds a non-voting replica of the range.
 	sl, _, _ := a.storePool.getStoreList(rangeInfo.Desc.RangeID, filter)
+	sl = sl.excludeReplicaNodes(nonVotingReplicas(rangeInfo.Desc))
+	rangeInfo = voterRangeInfo(rangeInfo)
 
 	existingCandidates, candidates := rebalanceCandidates(
 		ctx,
@@ -566,9 +685,12 @@ func (a Allocator) RebalanceTarget(
 // TransferLeaseTarget returns a suitable replica to transfer the range lease
 // to from the provided list. It excludes the current lease holder replica
 // unless asked to do otherwise by the checkTransferLeaseSource parameter.
+// If the zone has lease preferences, only the replicas satisfying the first
+// preference which can be satisfied are considered, and the lease is always
+// moved to one of them if the current lease holder does not satisfy it.
 func (a *Allocator) TransferLeaseTarget(
 	ctx context.Context,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	existing []roachpb.ReplicaDescriptor,
 	leaseStoreID roachpb.StoreID,
 	rangeID roachpb.RangeID,
@@ -578,7 +700,13 @@ func (a *Allocator) TransferLeaseTarget(
 	alwaysAllowDecisionWithoutStats bool,
 ) roachpb.ReplicaDescriptor {
 	sl, _, _ := a.storePool.getStoreList(rangeID, storeFilterNone)
-	sl = sl.filter(constraints)
+	if preferred := preferredLeaseholders(sl, zone.LeasePreferences, existing); len(preferred) > 0 {
+		if !containsStore(preferred, leaseStoreID) {
+			return leastLeasedReplica(sl, preferred)
+		}
+		existing = preferred
+	}
+	sl = sl.filter(zone.Constraints)
 
 	// Filter stores that are on nodes containing existing replicas, but leave
 	// the stores containing the existing replicas in place. This excludes stores
@@ -658,12 +786,13 @@ func (a *Allocator) TransferLeaseTarget(
 	return candidates[a.randGen.Intn(len(candidates))]
 }
 
-// ShouldTransferLease returns true if the specified store is overfull in terms
-// of leases with respect to the other stores matching the specified
-// attributes.
+// ShouldTransferLease returns true if the specified store does not satisfy
+// the lease preferences of the zone while another replica's store does, or if
+// it is overfull in terms of leases with respect to the other stores matching
+// the specified attributes.
 func (a *Allocator) ShouldTransferLease(
 	ctx context.Context,
-	constraints config.Constraints,
+	zone config.ZoneConfig,
 	existing []roachpb.ReplicaDescriptor,
 	leaseStoreID roachpb.StoreID,
 	rangeID roachpb.RangeID,
@@ -674,7 +803,17 @@ func (a *Allocator) ShouldTransferLease(
 		return false
 	}
 	sl, _, _ := a.storePool.getStoreList(rangeID, storeFilterNone)
-	sl = sl.filter(constraints)
+	if preferred := preferredLeaseholders(sl, zone.LeasePreferences, existing); len(preferred) > 0 {
+		if !containsStore(preferred, leaseStoreID) {
+			log.VEventf(ctx, 3, "ShouldTransferLease (lease-holder=%d): not a preferred lease holder", leaseStoreID)
+			return true
+		}
+		if len(preferred) == 1 {
+			return false
+		}
+		existing = preferred
+	}
+	sl = sl.filter(zone.Constraints)
 	log.VEventf(ctx, 3, "ShouldTransferLease (lease-holder=%d):\n%s", leaseStoreID, sl)
 
 	transferDec, _ := a.shouldTransferLeaseUsingStats(ctx, sl, source, existing, stats)
@@ -898,6 +1037,59 @@ func (a Allocator) shouldTransferLeaseWithoutStats(
 	return false
 }
 
+// preferredLeaseholders returns the replicas satisfying the first of the
+// given lease preferences which is satisfied by any replica on a store in
+// the store list. Since the store list only contains live stores, a
+// preference is skipped if all the replicas satisfying it are on dead
+// stores. It returns nil if no preference can be satisfied.
+func preferredLeaseholders(
+	sl StoreList, preferences []config.Constraints, existing []roachpb.ReplicaDescriptor,
+) []roachpb.ReplicaDescriptor {
+	for _, preference := range preferences {
+		var preferred []roachpb.ReplicaDescriptor
+		for _, repl := range existing {
+			for _, store := range sl.stores {
+				if store.StoreID != repl.StoreID {
+					continue
+				}
+				if ok, _ := constraintCheck(store, preference); ok {
+					preferred = append(preferred, repl)
+				}
+				break
+			}
+		}
+		if len(preferred) > 0 {
+			return preferred
+		}
+	}
+	return nil
+}
+
+// leastLeasedReplica returns the replica whose store holds the fewest leases
+// according to the store list.
+func leastLeasedReplica(sl StoreList, replicas []roachpb.ReplicaDescriptor) roachpb.ReplicaDescriptor {
+	var target roachpb.ReplicaDescriptor
+	targetLeases := int32(math.MaxInt32)
+	for _, repl := range replicas {
+		for _, store := range sl.stores {
+			if store.StoreID == repl.StoreID && store.Capacity.LeaseCount < targetLeases {
+				target, targetLeases = repl, store.Capacity.LeaseCount
+			}
+		}
+	}
+	return target
+}
+
+// containsStore returns whether one of the replicas is on the given store.
+func containsStore(replicas []roachpb.ReplicaDescriptor, storeID roachpb.StoreID) bool {
+	for _, repl := range replicas {
+		if repl.StoreID == storeID {
+			return true
+		}
+	}
+	return false
+}
+
 // computeQuorum computes the quorum value for the given number of nodes.
 func computeQuorum(nodes int) int {
 	return (nodes / 2) + 1
@@ -1151,7 +1151,7 @@ func TestAllocatorTransferLeaseTarget(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			target := a.TransferLeaseTarget(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				c.existing,
 				c.leaseholder,
 				0,
@@ -1205,7 +1205,7 @@ func TestAllocatorTransferLeaseTargetMultiStore(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			target := a.TransferLeaseTarget(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				existing,
 				c.leaseholder,
 				0,
@@ -1268,7 +1268,7 @@ func TestAllocatorShouldTransferLease(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			result := a.ShouldTransferLease(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				c.existing,
 				c.leaseholder,
 				0,
@@ -1281,6 +1281,117 @@ func TestAllocatorShouldTransferLease(t *testing.T) {
 	}
 }
 
+// TestAllocatorLeasePreferences verifies that the lease is moved to and kept
+// on the replicas satisfying the first satisfiable lease preference, falling
+// back to the next preference when the preferred nodes are dead.
+func TestAllocatorLeasePreferences(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, storePool, mnl := createTestStorePool(
+		TestTimeUntilStoreDeadOff, true /* deterministic */, nodeStatusLive)
+	defer stopper.Stop(context.Background())
+	a := MakeAllocator(storePool, func(string) (time.Duration, bool) {
+		return 0, true
+	})
+
+	// 4 stores where the lease count for each store is equal to 10x the store
+	// ID. Stores 1 and 2 are in us-east1, store 3 in us-west1 and store 4 in
+	// eu-west1.
+	regions := []string{"us-east1", "us-east1", "us-west1", "eu-west1"}
+	var stores []*roachpb.StoreDescriptor
+	for i, region := range regions {
+		stores = append(stores, &roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i + 1),
+			Node: roachpb.NodeDescriptor{
+				NodeID: roachpb.NodeID(i + 1),
+				Locality: roachpb.Locality{
+					Tiers: []roachpb.Tier{{Key: "region", Value: region}},
+				},
+			},
+			Capacity: roachpb.StoreCapacity{LeaseCount: int32(10 * (i + 1))},
+		})
+	}
+	sg := gossiputil.NewStoreGossiper(g)
+	sg.GossipStores(stores, t)
+
+	preference := func(region string) config.Constraints {
+		return config.Constraints{Constraints: []config.Constraint{
+			{Type: config.Constraint_REQUIRED, Key: "region", Value: region},
+		}}
+	}
+	zone := config.ZoneConfig{
+		LeasePreferences: []config.Constraints{preference("us-east1"), preference("us-west1")},
+	}
+
+	replicas := func(storeIDs ...roachpb.StoreID) []roachpb.ReplicaDescriptor {
+		var r []roachpb.ReplicaDescriptor
+		for _, storeID := range storeIDs {
+			r = append(r, roachpb.ReplicaDescriptor{
+				NodeID:  roachpb.NodeID(storeID),
+				StoreID: storeID,
+			})
+		}
+		return r
+	}
+
+	testCases := []struct {
+		existing       []roachpb.ReplicaDescriptor
+		leaseholder    roachpb.StoreID
+		dead           roachpb.NodeID
+		expectTransfer bool
+		expected       roachpb.StoreID
+	}{
+		// The lease holder satisfies the first preference.
+		{existing: replicas(1, 2, 3), leaseholder: 1, expectTransfer: false, expected: 0},
+		{existing: replicas(2, 3, 4), leaseholder: 2, expectTransfer: false, expected: 0},
+		// The lease holder doesn't satisfy the first preference, so the lease
+		// moves to the least leased preferred replica.
+		{existing: replicas(1, 2, 3), leaseholder: 3, expectTransfer: true, expected: 1},
+		{existing: replicas(2, 3, 4), leaseholder: 4, expectTransfer: true, expected: 2},
+		// No replica satisfies the first preference, so the second one applies.
+		{existing: replicas(3, 4), leaseholder: 4, expectTransfer: true, expected: 3},
+		{existing: replicas(3, 4), leaseholder: 3, expectTransfer: false, expected: 0},
+		// The only replica satisfying the first preference is dead, so the
+		// second one applies.
+		{existing: replicas(1, 3, 4), leaseholder: 4, dead: 1, expectTransfer: true, expected: 3},
+		{existing: replicas(1, 3, 4), leaseholder: 3, dead: 1, expectTransfer: false, expected: 0},
+	}
+	for _, c := range testCases {
+		t.Run("", func(t *testing.T) {
+			for _, store := range stores {
+				mnl.setNodeStatus(store.Node.NodeID, nodeStatusLive)
+			}
+			if c.dead != 0 {
+				mnl.setNodeStatus(c.dead, nodeStatusDead)
+			}
+			result := a.ShouldTransferLease(
+				context.Background(),
+				zone,
+				c.existing,
+				c.leaseholder,
+				0,
+				nil, /* replicaStats */
+			)
+			if c.expectTransfer != result {
+				t.Errorf("expected ShouldTransferLease to return %v, but found %v", c.expectTransfer, result)
+			}
+			target := a.TransferLeaseTarget(
+				context.Background(),
+				zone,
+				c.existing,
+				c.leaseholder,
+				0,
+				nil,   /* replicaStats */
+				true,  /* checkTransferLeaseSource */
+				true,  /* checkCandidateFullness */
+				false, /* !alwaysAllowDecisionWithoutStats */
+			)
+			if c.expected != target.StoreID {
+				t.Errorf("expected s%d, but found s%d", c.expected, target.StoreID)
+			}
+		})
+	}
+}
+
 // Test out the load-based lease transfer algorithm against a variety of
 // request distributions and inter-node latencies.
 func TestAllocatorTransferLeaseTargetLoadBased(t *testing.T) {
@@ -1434,7 +1545,7 @@ func TestAllocatorTransferLeaseTargetLoadBased(t *testing.T) {
 			})
 			target := a.TransferLeaseTarget(
 				context.Background(),
-				config.Constraints{},
+				config.ZoneConfig{},
 				existing,
 				c.leaseholder,
 				0,
@@ -2315,6 +2426,146 @@ func TestAllocatorComputeActionDecommission(t *testing.T) {
 	}
 }
 
+// TestAllocatorComputeActionNonVoters verifies the actions computed for the
+// non-voting replicas of a range, and that they never take precedence over
+// the repair of its voting replicas.
+func TestAllocatorComputeActionNonVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	voter := func(id int) roachpb.ReplicaDescriptor {
+		return roachpb.ReplicaDescriptor{
+			StoreID:   roachpb.StoreID(id),
+			NodeID:    roachpb.NodeID(id),
+			ReplicaID: roachpb.ReplicaID(id),
+		}
+	}
+	nonVoter := func(id int) roachpb.ReplicaDescriptor {
+		repDesc := voter(id)
+		repDesc.Type = roachpb.NON_VOTER.Enum()
+		return repDesc
+	}
+	learner := func(id int) roachpb.ReplicaDescriptor {
+		repDesc := voter(id)
+		repDesc.Type = roachpb.LEARNER.Enum()
+		return repDesc
+	}
+
+	testCases := []struct {
+		numNonVoters    int32
+		replicas        []roachpb.ReplicaDescriptor
+		abandoned       []roachpb.ReplicaDescriptor
+		expectedAction  AllocatorAction
+		live            []roachpb.StoreID
+		dead            []roachpb.StoreID
+		decommissioning []roachpb.StoreID
+	}{
+		// Missing non-voter.
+		{
+			numNonVoters:   1,
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), voter(3)},
+			expectedAction: AllocatorAddNonVoter,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+		// Missing voter takes precedence over a missing non-voter. The non-voter
+		// does not count toward the voters.
+		{
+			numNonVoters:   2,
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), nonVoter(3)},
+			expectedAction: AllocatorAdd,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+		// Extra non-voter.
+		{
+			numNonVoters:   1,
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), voter(3), nonVoter(4), nonVoter(5)},
+			expectedAction: AllocatorRemoveNonVoter,
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+		},
+		// Dead non-voter.
+		{
+			numNonVoters:   1,
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), voter(3), nonVoter(4)},
+			expectedAction: AllocatorRemoveDeadNonVoter,
+			live:           []roachpb.StoreID{1, 2, 3},
+			dead:           []roachpb.StoreID{4},
+		},
+		// Decommissioning non-voter.
+		{
+			numNonVoters:    1,
+			replicas:        []roachpb.ReplicaDescriptor{voter(1), voter(2), voter(3), nonVoter(4)},
+			expectedAction:  AllocatorRemoveDecommissioningNonVoter,
+			live:            []roachpb.StoreID{1, 2, 3},
+			decommissioning: []roachpb.StoreID{4},
+		},
+		// Non-voters match the zone config.
+		{
+			numNonVoters:   1,
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), voter(3), nonVoter(4)},
+			expectedAction: AllocatorConsiderRebalance,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+		// Abandoned learner. The learner counts neither as a voter nor as a
+		// non-voter, and is removed before the range is up-replicated.
+		{
+			numNonVoters:   0,
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), learner(3)},
+			abandoned:      []roachpb.ReplicaDescriptor{learner(3)},
+			expectedAction: AllocatorRemoveLearner,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+		// Abandoned learner on a dead store.
+		{
+			numNonVoters:   0,
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), voter(3), learner(4)},
+			abandoned:      []roachpb.ReplicaDescriptor{learner(4)},
+			expectedAction: AllocatorRemoveLearner,
+			live:           []roachpb.StoreID{1, 2, 3},
+			dead:           []roachpb.StoreID{4},
+		},
+		// Learner being caught up by an in-flight addition. It is neither
+		// removed nor raced by the up-replication of the range.
+		{
+			numNonVoters:   0,
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), learner(3)},
+			expectedAction: AllocatorNoop,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+		// In-flight learner for a rebalance, e.g. by the store rebalancer.
+		{
+			numNonVoters:   0,
+			replicas:       []roachpb.ReplicaDescriptor{voter(1), voter(2), voter(3), learner(4)},
+			expectedAction: AllocatorNoop,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+	}
+
+	stopper, _, sp, a, _ := createTestAllocator( /* deterministic */ false)
+	ctx := context.Background()
+	defer stopper.Stop(ctx)
+
+	for i, tcase := range testCases {
+		mockStorePool(sp, tcase.live, tcase.dead, tcase.decommissioning, nil)
+
+		zone := config.ZoneConfig{NumReplicas: 3, NumNonVoters: tcase.numNonVoters}
+		desc := roachpb.RangeDescriptor{Replicas: tcase.replicas}
+		action, _ := a.ComputeAction(ctx, zone, RangeInfo{Desc: &desc, AbandonedLearners: tcase.abandoned})
+		if tcase.expectedAction != action {
+			t.Errorf("%d: expected action %s, got action %s", i, tcase.expectedAction, action)
+		}
+	}
+
+	// Non-voters are not added until the cluster version allows it.
+	sp.st = cluster.MakeTestingClusterSettingsWithVersion(
+		cluster.BinaryMinimumSupportedVersion, cluster.VersionByKey(cluster.VersionMeta2Splits))
+	mockStorePool(sp, []roachpb.StoreID{1, 2, 3, 4}, nil, nil, nil)
+	zone := config.ZoneConfig{NumReplicas: 3, NumNonVoters: 1}
+	desc := roachpb.RangeDescriptor{Replicas: []roachpb.ReplicaDescriptor{voter(1), voter(2), voter(3)}}
+	if action, _ := a.ComputeAction(ctx, zone, RangeInfo{Desc: &desc}); action != AllocatorConsiderRebalance {
+		t.Errorf("expected action %s before the cluster version is active, got action %s",
+			AllocatorConsiderRebalance, action)
+	}
+}
+
 // TestAllocatorComputeActionNoStorePool verifies that
 // ComputeAction returns AllocatorNoop when storePool is nil.
 func TestAllocatorComputeActionNoStorePool(t *testing.T) {
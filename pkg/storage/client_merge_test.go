@@ -29,11 +29,13 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 func adminMergeArgs(key roachpb.Key) *roachpb.AdminMergeRequest {
@@ -91,6 +93,53 @@ func TestStoreRangeMergeTwoEmptyRanges(t *testing.T) {
 	}
 }
 
+// TestStoreRangeSplitMergeClosedTimestamp verifies that closed timestamps
+// survive splits and merges: the RHS of a split does not accept writes below
+// the timestamp closed before the split, and the merged range does not accept
+// writes to the subsumed keys below the timestamp closed by the subsumed
+// range.
+func TestStoreRangeSplitMergeClosedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	storeCfg := storage.TestStoreConfig(nil)
+	storeCfg.TestingKnobs.DisableSplitQueue = true
+	// Only close timestamps explicitly.
+	storagebase.ClosedTimestampTargetDuration.Override(&storeCfg.Settings.SV, 0)
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store := createTestStoreWithConfig(t, stopper, storeCfg)
+
+	lhsClosed := store.LookupReplica(roachpb.RKeyMin, nil).CloseTimestamp(store.Clock().Now())
+	if _, _, err := createSplitRanges(store); err != nil {
+		t.Fatal(err)
+	}
+	rhs := store.LookupReplica(roachpb.RKey("c"), nil)
+	if closed := rhs.CloseTimestamp(hlc.Timestamp{}); closed.Less(lhsClosed) {
+		t.Fatalf("expected RHS to inherit closed timestamp %s, got %s", lhsClosed, closed)
+	}
+
+	rhsClosed := rhs.CloseTimestamp(store.Clock().Now())
+	if !lhsClosed.Less(rhsClosed) {
+		t.Fatalf("expected RHS closed timestamp %s to be above %s", rhsClosed, lhsClosed)
+	}
+	if _, pErr := client.SendWrapped(context.Background(), rg1(store), adminMergeArgs(roachpb.KeyMin)); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// A write to the subsumed keys between the two closed timestamps must be
+	// pushed above the one closed by the subsumed range.
+	ba := roachpb.BatchRequest{}
+	ba.RangeID = store.LookupReplica(roachpb.RKey("c"), nil).RangeID
+	ba.Timestamp = lhsClosed.Next()
+	ba.Add(putArgs(roachpb.Key("c"), []byte("value")))
+	br, pErr := store.Send(context.Background(), ba)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if !rhsClosed.Less(br.Timestamp) {
+		t.Fatalf("expected write to be pushed above %s, got %s", rhsClosed, br.Timestamp)
+	}
+}
+
 // TestStoreRangeMergeMetadataCleanup tests that all metadata of a
 // subsumed range is cleaned up on merge.
 func TestStoreRangeMergeMetadataCleanup(t *testing.T) {
@@ -367,6 +416,107 @@ func TestStoreRangeMergeNonCollocated(t *testing.T) {
 	}
 }
 
+// TestStoreRangeMergeWithOutstandingIntent verifies that a merge completes
+// when it runs into an intent on the right-hand side of a transaction which is
+// itself anchored on the right-hand side. The merge transaction must be able
+// to push that transaction and to resolve its intent while the right-hand side
+// is frozen.
+func TestStoreRangeMergeWithOutstandingIntent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	mtc := &multiTestContext{}
+	defer mtc.Stop()
+	mtc.Start(t, 3)
+	store := mtc.stores[0]
+	ctx := context.Background()
+
+	mtc.replicateRange(1, 1, 2)
+	_, bDesc, pErr := createSplitRanges(store)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Start a low-priority transaction whose record lives on the right-hand
+	// side, and leave an intent on the right-hand side's range descriptor.
+	txn := client.NewTxn(mtc.dbs[0], 0 /* gatewayNodeID */)
+	if err := txn.SetUserPriority(roachpb.MinUserPriority); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "c", "value"); err != nil {
+		t.Fatal(err)
+	}
+	rightDescKey := keys.RangeDescriptorKey(bDesc.StartKey)
+	var rightDesc roachpb.RangeDescriptor
+	if err := txn.GetProto(ctx, rightDescKey, &rightDesc); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, rightDescKey, &rightDesc); err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan *roachpb.Error, 1)
+	go func() {
+		_, pErr := client.SendWrapped(ctx, rg1(store), adminMergeArgs(roachpb.KeyMin))
+		errCh <- pErr
+	}()
+	select {
+	case pErr := <-errCh:
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("merge did not complete with an outstanding intent on the right-hand side")
+	}
+
+	// The merge aborted the other transaction.
+	if err := txn.Commit(ctx); !testutils.IsError(err, "TransactionAbortedError|TransactionRetryError") {
+		t.Fatalf("expected the transaction to be aborted, got %v", err)
+	}
+
+	// Verify the merge on every store.
+	testutils.SucceedsSoon(t, func() error {
+		for _, s := range mtc.stores {
+			if repl := s.LookupReplica(roachpb.RKey("c"), nil); repl == nil || repl.RangeID != 1 {
+				return fmt.Errorf("store %d: key \"c\" is not served by r1: %v", s.StoreID(), repl)
+			}
+		}
+		return nil
+	})
+}
+
+// TestStoreRangeMergeFrozenLeaseTransfer verifies that the lease of a range
+// frozen for a merge cannot be transferred away, as the freeze is only held by
+// the leaseholder.
+func TestStoreRangeMergeFrozenLeaseTransfer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	mtc := &multiTestContext{}
+	defer mtc.Stop()
+	mtc.Start(t, 2)
+	store := mtc.stores[0]
+	ctx := context.Background()
+
+	mtc.replicateRange(1, 1)
+	_, bDesc, pErr := createSplitRanges(store)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	rightRepl := store.LookupReplica(bDesc.StartKey, nil)
+	if err := rightRepl.FreezeForMerge(ctx, uuid.MakeV4()); err != nil {
+		t.Fatal(err)
+	}
+
+	rightKey := bDesc.StartKey.AsRawKey()
+	if err := mtc.dbs[0].AdminTransferLease(
+		ctx, rightKey, mtc.stores[1].StoreID(),
+	); !testutils.IsError(err, "cannot transfer the lease of a range frozen for merge") {
+		t.Fatalf("expected the lease transfer to be refused, got %v", err)
+	}
+
+	rightRepl.UnfreezeAfterMerge()
+	if err := mtc.dbs[0].AdminTransferLease(ctx, rightKey, mtc.stores[1].StoreID()); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestStoreRangeMergeStats starts by splitting a range, then writing random data
 // to both sides of the split. It then merges the ranges and verifies the merged
 // range has stats consistent with recomputations.
@@ -1114,7 +1114,7 @@ func TestRefreshPendingCommands(t *testing.T) {
 }
 
 // TestStoreRangeUpReplicate verifies that the replication queue will notice
-// under-replicated ranges and replicate them. Also tests that preemptive
+// under-replicated ranges and replicate them. Also tests that learner
 // snapshots which contain sideloaded proposals don't panic the receiving end.
 func TestStoreRangeUpReplicate(t *testing.T) {
 	defer leaktest.AfterTest(t)()
@@ -1156,7 +1156,7 @@ func TestStoreRangeUpReplicate(t *testing.T) {
 				return errors.Errorf("expected 0 reservations, but found %d", n)
 			}
 			if len(r.Desc().Replicas) != 3 {
-				// This fails even after the preemptive snapshot has arrived and
+				// This fails even after the learner snapshot has arrived and
 				// only goes through once the replica has properly caught up to
 				// the fully replicated descriptor.
 				return errors.Errorf("not fully initialized")
@@ -1178,11 +1178,11 @@ func TestStoreRangeUpReplicate(t *testing.T) {
 		t.Fatalf("expected at least 1 snapshot, but found 0")
 	}
 
-	if normalApplied != 0 {
-		t.Fatalf("expected 0 normal snapshots, but found %d", normalApplied)
+	if preemptiveApplied != 0 {
+		t.Fatalf("expected 0 preemptive snapshots, but found %d", preemptiveApplied)
 	}
-	if generated != preemptiveApplied {
-		t.Fatalf("expected %d preemptive snapshots, but found %d", generated, preemptiveApplied)
+	if generated != normalApplied {
+		t.Fatalf("expected %d normal snapshots, but found %d", generated, normalApplied)
 	}
 
 	r.PutBogusSideloadedData()
@@ -1367,13 +1367,32 @@ func TestUnreplicateFirstRange(t *testing.T) {
 	mtc.replicateRange(rangeID, 2)
 }
 
+// TestStoreRangeUpReplicatePreemptive verifies that replicas are added with
+// preemptive snapshots rather than as learners until the cluster version
+// allows learners.
+func TestStoreRangeUpReplicatePreemptive(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := storage.TestStoreConfig(nil)
+	sc.Settings = cluster.MakeTestingClusterSettingsWithVersion(
+		cluster.BinaryMinimumSupportedVersion, cluster.VersionByKey(cluster.VersionNonVoterReplicas))
+	mtc := &multiTestContext{storeConfig: &sc}
+	defer mtc.Stop()
+	mtc.Start(t, 2)
+
+	before := mtc.stores[1].Metrics().RangeSnapshotsPreemptiveApplied.Count()
+	mtc.replicateRange(1, 1)
+	if after := mtc.stores[1].Metrics().RangeSnapshotsPreemptiveApplied.Count(); after != before+1 {
+		t.Fatalf("expected a preemptive snapshot to be applied, before %d after %d", before, after)
+	}
+	for _, repDesc := range mtc.stores[0].LookupReplica(roachpb.RKeyMin, nil).Desc().Replicas {
+		if repDesc.Type != nil {
+			t.Fatalf("expected only voters, found %s", repDesc)
+		}
+	}
+}
+
 // TestChangeReplicasDescriptorInvariant tests that a replica change aborts if
 // another change has been made to the RangeDescriptor since it was initiated.
-//
-// TODO(tschottdorf): If this test is flaky because the snapshot count does not
-// increase, it's likely because with proposer-evaluated KV, less gets proposed
-// and so sometimes Raft discards the preemptive snapshot (though we count that
-// case in stats already) or doesn't produce a Ready.
 func TestChangeReplicasDescriptorInvariant(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	mtc := &multiTestContext{}
@@ -1414,37 +1433,31 @@ func TestChangeReplicasDescriptorInvariant(t *testing.T) {
 		return nil
 	})
 
-	before := mtc.stores[2].Metrics().RangeSnapshotsPreemptiveApplied.Count()
+	before := mtc.stores[2].Metrics().RangeSnapshotsNormalApplied.Count()
 	// Attempt to add replica to the third store with the original descriptor.
 	// This should fail because the descriptor is stale.
 	expectedErr := `change replicas of r1 failed: descriptor changed: \[expected\]`
 	if err := addReplica(2, origDesc); !testutils.IsError(err, expectedErr) {
 		t.Fatalf("got unexpected error: %v", err)
 	}
+	// The learner is never added, so no snapshot is sent.
+	if after := mtc.stores[2].Metrics().RangeSnapshotsNormalApplied.Count(); after != before {
+		t.Fatalf("failed ChangeReplicas call should not have applied a snapshot, before %d after %d",
+			before, after)
+	}
 
-	testutils.SucceedsSoon(t, func() error {
-		after := mtc.stores[2].Metrics().RangeSnapshotsPreemptiveApplied.Count()
-		// The failed ChangeReplicas call should have applied a preemptive snapshot.
-		if after != before+1 {
-			return errors.Errorf(
-				"ChangeReplicas call should have applied a preemptive snapshot, before %d after %d",
-				before, after)
-		}
-		return nil
-	})
-
-	before = mtc.stores[2].Metrics().RangeSnapshotsPreemptiveApplied.Count()
 	// Add to third store with fresh descriptor.
 	if err := addReplica(2, repl.Desc()); err != nil {
 		t.Fatal(err)
 	}
 
 	testutils.SucceedsSoon(t, func() error {
-		after := mtc.stores[2].Metrics().RangeSnapshotsPreemptiveApplied.Count()
-		// The failed ChangeReplicas call should have applied a preemptive snapshot.
+		after := mtc.stores[2].Metrics().RangeSnapshotsNormalApplied.Count()
+		// The ChangeReplicas call should have caught up the learner with a
+		// snapshot.
 		if after != before+1 {
 			return errors.Errorf(
-				"ChangeReplicas call should have applied a preemptive snapshot, before %d after %d",
+				"ChangeReplicas call should have applied a snapshot, before %d after %d",
 				before, after)
 		}
 		r := mtc.stores[2].LookupReplica(roachpb.RKey("a"), roachpb.RKey("b"))
@@ -3459,9 +3472,10 @@ func TestTransferRaftLeadership(t *testing.T) {
 	})
 }
 
-// TestFailedPreemptiveSnapshot verifies that ChangeReplicas is
-// aborted if we are unable to send a preemptive snapshot.
-func TestFailedPreemptiveSnapshot(t *testing.T) {
+// TestFailedLearnerSnapshot verifies that ChangeReplicas is aborted, and the
+// learner it added removed again, if we are unable to send a snapshot to the
+// learner.
+func TestFailedLearnerSnapshot(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
 	mtc := &multiTestContext{}
@@ -3471,11 +3485,11 @@ func TestFailedPreemptiveSnapshot(t *testing.T) {
 	// Replicate a range onto the two stores. This replication is
 	// important because if there was only one node to begin with, the
 	// ChangeReplicas would fail because it was unable to achieve quorum
-	// even if the preemptive snapshot failure were ignored.
+	// even if the learner snapshot failure were ignored.
 	mtc.replicateRange(1, 1)
 
 	// Now try to add a third. It should fail because we cannot send a
-	// preemptive snapshot to it.
+	// snapshot to the learner.
 	rep, err := mtc.stores[0].GetReplica(1)
 	if err != nil {
 		t.Fatal(err)
@@ -3491,7 +3505,10 @@ func TestFailedPreemptiveSnapshot(t *testing.T) {
 	); !testutils.IsError(err, expErr) {
 		t.Fatalf("expected %s; got %v", expErr, err)
 	} else if !storage.IsSnapshotError(err) {
-		t.Fatalf("expected preemptive snapshot failed error; got %T: %v", err, err)
+		t.Fatalf("expected snapshot failed error; got %T: %v", err, err)
+	}
+	if desc := rep.Desc(); len(desc.Replicas) != 2 {
+		t.Fatalf("expected learner to be removed, found replicas %v", desc.Replicas)
 	}
 }
 
@@ -3756,3 +3773,58 @@ func TestFailedConfChange(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestStoreRangeAddNonVoter verifies that a non-voting replica added via
+// AdminChangeReplicas receives the range's writes, is subject to the same
+// checks as voters and can be removed again.
+func TestStoreRangeAddNonVoter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	mtc := &multiTestContext{}
+	defer mtc.Stop()
+	mtc.Start(t, 3)
+
+	ctx := context.Background()
+	key := roachpb.Key("a")
+	if _, err := client.SendWrapped(ctx, rg1(mtc.stores[0]), incrementArgs(key, 5)); err != nil {
+		t.Fatal(err)
+	}
+	mtc.replicateRange(1, 1)
+	nonVoter := roachpb.ReplicationTarget{
+		NodeID:  mtc.idents[2].NodeID,
+		StoreID: mtc.idents[2].StoreID,
+	}
+	if err := mtc.dbs[0].AdminChangeReplicas(
+		ctx, roachpb.KeyMin, roachpb.ADD_NON_VOTER, []roachpb.ReplicationTarget{nonVoter},
+	); err != nil {
+		t.Fatal(err)
+	}
+	mtc.waitForValues(key, []int64{5, 5, 5})
+
+	desc := mtc.stores[0].LookupReplica(roachpb.RKeyMin, nil).Desc()
+	if voters := desc.Voters(); len(voters) != 2 {
+		t.Fatalf("expected 2 voters, got %v", voters)
+	}
+	if nonVoters := desc.NonVoters(); len(nonVoters) != 1 || nonVoters[0].StoreID != nonVoter.StoreID {
+		t.Fatalf("expected a non-voter on s%d, got %v", nonVoter.StoreID, nonVoters)
+	}
+
+	// The non-voter keeps receiving writes.
+	if _, err := client.SendWrapped(ctx, rg1(mtc.stores[0]), incrementArgs(key, 2)); err != nil {
+		t.Fatal(err)
+	}
+	mtc.waitForValues(key, []int64{7, 7, 7})
+
+	// The store already has a replica, whatever its type.
+	for _, changeType := range []roachpb.ReplicaChangeType{roachpb.ADD_REPLICA, roachpb.ADD_NON_VOTER} {
+		if err := mtc.dbs[0].AdminChangeReplicas(
+			ctx, roachpb.KeyMin, changeType, []roachpb.ReplicationTarget{nonVoter},
+		); !testutils.IsError(err, "unable to add replica .* which is already present") {
+			t.Fatalf("%s: unexpected error %v", changeType, err)
+		}
+	}
+
+	mtc.unreplicateRange(1, 2)
+	if nonVoters := mtc.stores[0].LookupReplica(roachpb.RKeyMin, nil).Desc().NonVoters(); len(nonVoters) != 0 {
+		t.Fatalf("expected no non-voters, got %v", nonVoters)
+	}
+}
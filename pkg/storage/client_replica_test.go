@@ -1597,3 +1597,78 @@ func TestSystemZoneConfigs(t *testing.T) {
 	expectedReplicas += 8
 	testutils.SucceedsSoon(t, waitForReplicas)
 }
+
+// TestFollowerReadBelowClosedTimestamp verifies that a follower serves a
+// consistent read once its timestamp has been closed, and redirects it to
+// the leaseholder until then.
+func TestFollowerReadBelowClosedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := storage.TestStoreConfig(nil)
+	storagebase.ClosedTimestampTargetDuration.Override(&sc.Settings.SV, 10*time.Millisecond)
+	mtc := &multiTestContext{storeConfig: &sc}
+	defer mtc.Stop()
+	mtc.Start(t, 3)
+	mtc.replicateRange(1, 1, 2)
+
+	ctx := context.Background()
+	key := roachpb.Key("a")
+	if err := mtc.dbs[0].Put(ctx, key, "value"); err != nil {
+		t.Fatal(err)
+	}
+	readTS := mtc.clock.Now()
+
+	follower := mtc.stores[1].LookupReplica(roachpb.RKey(key), nil)
+	followerDesc, err := follower.GetReplicaDescriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	followerRead := func() (*roachpb.GetResponse, *roachpb.Error) {
+		reply, pErr := client.SendWrappedWith(ctx, mtc.senders[1], roachpb.Header{
+			Replica:   followerDesc,
+			Timestamp: readTS,
+		}, getArgs(key))
+		if pErr != nil {
+			return nil, pErr
+		}
+		return reply.(*roachpb.GetResponse), nil
+	}
+
+	// The read's timestamp has not been closed yet, so the follower redirects
+	// it to the leaseholder.
+	if _, pErr := followerRead(); pErr == nil {
+		t.Fatalf("expected %T, got success", &roachpb.NotLeaseHolderError{})
+	} else if _, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); !ok {
+		t.Fatalf("expected %T, got %s", &roachpb.NotLeaseHolderError{}, pErr)
+	}
+
+	// Move the clock past the closed timestamp target and write elsewhere in
+	// the range, so that a closed timestamp above the read's is carried to
+	// the follower whether or not the range has quiesced in the meantime.
+	mtc.manualClock.Increment(time.Second.Nanoseconds())
+	testutils.SucceedsSoon(t, func() error {
+		if err := mtc.dbs[0].Put(ctx, "b", "value"); err != nil {
+			return err
+		}
+		if closed := follower.ClosedTimestamp(); closed.Less(readTS) {
+			return errors.Errorf("closed timestamp %s below read timestamp %s", closed, readTS)
+		}
+		return nil
+	})
+
+	before := mtc.stores[1].Metrics().FollowerReadsCount.Count()
+	reply, pErr := followerRead()
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if reply.Value == nil {
+		t.Fatalf("expected value for %s, got none", key)
+	}
+	if v, err := reply.Value.GetBytes(); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, []byte("value")) {
+		t.Fatalf("expected %q, got %q", "value", v)
+	}
+	if after := mtc.stores[1].Metrics().FollowerReadsCount.Count(); after != before+1 {
+		t.Fatalf("expected follower reads count %d, got %d", before+1, after)
+	}
+}
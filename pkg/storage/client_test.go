@@ -1004,7 +1004,7 @@ func (m *multiTestContext) changeReplicasLocked(
 
 	var alreadyDoneErr string
 	switch changeType {
-	case roachpb.ADD_REPLICA:
+	case roachpb.ADD_REPLICA, roachpb.ADD_NON_VOTER:
 		alreadyDoneErr = "unable to add replica .* which is already present"
 	case roachpb.REMOVE_REPLICA:
 		alreadyDoneErr = "unable to remove replica .* which is not present"
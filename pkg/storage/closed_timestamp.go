@@ -0,0 +1,258 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// closedTimestampIdleCheckInterval is the interval at which the store checks
+// whether closed timestamps have been enabled while they are disabled.
+const closedTimestampIdleCheckInterval = 10 * time.Second
+
+// closedTimestampTracker is used by the leaseholder of a range to close
+// timestamps, that is, to promise that it will not propose any further writes
+// at or below them. The promise is attached to every proposal (see
+// RaftCommand.ClosedTimestamp) and lets the replicas which have applied the
+// proposal serve consistent reads below it without holding the lease.
+//
+// A write which has passed the timestamp cache is tracked until its proposal
+// has been inserted into the proposals map, and thus has been assigned a
+// MaxLeaseIndex. A timestamp is only closed if it is below all tracked writes,
+// and writes at or below the closed timestamp are forwarded above it before
+// being tracked. Consequently, a write which applies after a proposal
+// carrying a closed timestamp is either above that timestamp or was proposed
+// with a lower MaxLeaseIndex, in which case it fails to apply and is
+// re-evaluated.
+type closedTimestampTracker struct {
+	syncutil.Mutex
+	// closed is the highest timestamp promised to followers.
+	closed hlc.Timestamp
+	// inFlight counts the tracked writes by timestamp.
+	inFlight map[hlc.Timestamp]int
+}
+
+// track registers a write at the given timestamp, which is forwarded above
+// the closed timestamp if necessary. The returned function must be called
+// once the write's proposal has been inserted into the proposals map, or
+// when the write is abandoned.
+func (t *closedTimestampTracker) track(ts *hlc.Timestamp) (bumped bool, untrack func()) {
+	t.Lock()
+	defer t.Unlock()
+	if !t.closed.Less(*ts) {
+		*ts = t.closed.Next()
+		bumped = true
+	}
+	if t.inFlight == nil {
+		t.inFlight = map[hlc.Timestamp]int{}
+	}
+	tracked := *ts
+	t.inFlight[tracked]++
+	var done bool
+	return bumped, func() {
+		t.Lock()
+		defer t.Unlock()
+		if done {
+			return
+		}
+		done = true
+		if t.inFlight[tracked]--; t.inFlight[tracked] == 0 {
+			delete(t.inFlight, tracked)
+		}
+	}
+}
+
+// close attempts to advance the closed timestamp to the target, but not to
+// or above any tracked write. It returns the resulting closed timestamp.
+func (t *closedTimestampTracker) close(target hlc.Timestamp) hlc.Timestamp {
+	t.Lock()
+	defer t.Unlock()
+	for ts := range t.inFlight {
+		if !target.Less(ts) {
+			target = ts.Prev()
+		}
+	}
+	t.closed.Forward(target)
+	return t.closed
+}
+
+// lastClosed returns the closed timestamp.
+func (t *closedTimestampTracker) lastClosed() hlc.Timestamp {
+	t.Lock()
+	defer t.Unlock()
+	return t.closed
+}
+
+// closedTimestampTarget returns the timestamp which the leaseholder should
+// aim to close, or a zero timestamp if closed timestamps are disabled.
+func (r *Replica) closedTimestampTarget(now hlc.Timestamp) hlc.Timestamp {
+	target := storagebase.ClosedTimestampTargetDuration.Get(&r.store.cfg.Settings.SV)
+	if target == 0 {
+		return hlc.Timestamp{}
+	}
+	return hlc.Timestamp{WallTime: now.WallTime - target.Nanoseconds()}
+}
+
+// trackWriteTimestamp forwards the batch's (transaction) timestamp above the
+// closed timestamp if necessary and tracks it until the returned function is
+// called. See closedTimestampTracker.track.
+func (r *Replica) trackWriteTimestamp(ba *roachpb.BatchRequest) (bool, func()) {
+	if ba.Txn == nil {
+		return r.closedTSTracker.track(&ba.Timestamp)
+	}
+	txn := ba.Txn.Clone()
+	bumped, untrack := r.closedTSTracker.track(&txn.Timestamp)
+	if bumped {
+		ba.Txn = &txn
+	}
+	return bumped, untrack
+}
+
+// ClosedTimestamp returns the highest timestamp at which the replica has
+// learned, through applied Raft commands, that no further writes will be
+// accepted.
+func (r *Replica) ClosedTimestamp() hlc.Timestamp {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mu.closedTimestamp
+}
+
+// canServeFollowerRead returns whether a read-only batch, for which the
+// replica was unable to obtain the lease with the given error, may be
+// served by this replica nonetheless because its timestamp has been closed.
+func (r *Replica) canServeFollowerRead(
+	ctx context.Context, ba roachpb.BatchRequest, pErr *roachpb.Error,
+) bool {
+	if _, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); !ok {
+		return false
+	}
+	if !storagebase.FollowerReadsEnabled.Get(&r.store.cfg.Settings.SV) ||
+		!storagebase.BatchCanBeEvaluatedOnFollower(ba) {
+		return false
+	}
+	ts := storagebase.FollowerReadTimestamp(ba)
+	if closed := r.ClosedTimestamp(); closed.Less(ts) {
+		log.Eventf(ctx, "%s: cannot serve follower read at %s above closed timestamp %s", r, ts, closed)
+		return false
+	}
+	r.store.metrics.FollowerReadsCount.Inc(1)
+	log.Event(ctx, "serving via follower read")
+	return true
+}
+
+// maybeProposeClosedTimestamp proposes an empty command carrying a new
+// closed timestamp if the replica holds the lease and has not closed a
+// timestamp within the close interval, typically because the range has not
+// received any writes. Quiesced ranges are left alone: proposing would wake
+// them up, and keeping idle ranges quiet matters more than follower reads on
+// them. Their followers keep the timestamp closed before quiescence, and
+// reads above it are redirected to the leaseholder.
+func (r *Replica) maybeProposeClosedTimestamp(ctx context.Context, now hlc.Timestamp) error {
+	target := r.closedTimestampTarget(now)
+	if target == (hlc.Timestamp{}) || !r.OwnsValidLease(now) {
+		return nil
+	}
+	fraction := storagebase.ClosedTimestampCloseFraction.Get(&r.store.cfg.Settings.SV)
+	interval := time.Duration(fraction * float64(storagebase.ClosedTimestampTargetDuration.Get(&r.store.cfg.Settings.SV)))
+	if lastClosed := r.closedTSTracker.lastClosed(); target.WallTime-lastClosed.WallTime < interval.Nanoseconds() {
+		return nil
+	}
+
+	r.mu.RLock()
+	quiescent := r.mu.quiescent
+	lease := *r.mu.state.Lease
+	desc := r.mu.state.Desc
+	r.mu.RUnlock()
+	if quiescent {
+		return nil
+	}
+
+	ba := roachpb.BatchRequest{}
+	ba.Timestamp = now
+	ba.RangeID = r.RangeID
+	proposal := &ProposalData{
+		ctx:     ctx,
+		idKey:   makeIDKey(),
+		doneCh:  make(chan proposalResult, 1),
+		Local:   &LocalEvalResult{Reply: &roachpb.BatchResponse{}},
+		Request: &ba,
+		command: storagebase.RaftCommand{
+			ReplicatedEvalResult: storagebase.ReplicatedEvalResult{
+				Timestamp: now,
+				StartKey:  desc.StartKey,
+				EndKey:    desc.EndKey,
+			},
+		},
+	}
+
+	r.raftMu.Lock()
+	defer r.raftMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.mu.destroyed; err != nil {
+		return err
+	}
+	repDesc, err := r.getReplicaDescriptorRLocked()
+	if err != nil {
+		return err
+	}
+	r.insertProposalLocked(proposal, repDesc, lease)
+	if err := r.submitProposalLocked(proposal); err != nil {
+		delete(r.mu.proposals, proposal.idKey)
+		return err
+	}
+	return nil
+}
+
+// startClosedTimestampCloser starts a worker which periodically advances the
+// closed timestamps of the ranges for which the store holds the lease, so
+// that their followers can serve reads even when the ranges receive few
+// writes. Quiesced ranges are skipped.
+func (s *Store) startClosedTimestampCloser(ctx context.Context) {
+	s.stopper.RunWorker(ctx, func(ctx context.Context) {
+		timer := timeutil.NewTimer()
+		defer timer.Stop()
+		for {
+			interval := closedTimestampIdleCheckInterval
+			if target := storagebase.ClosedTimestampTargetDuration.Get(&s.cfg.Settings.SV); target != 0 {
+				fraction := storagebase.ClosedTimestampCloseFraction.Get(&s.cfg.Settings.SV)
+				interval = time.Duration(fraction * float64(target))
+			}
+			timer.Reset(interval)
+			select {
+			case <-timer.C:
+				timer.Read = true
+			case <-s.stopper.ShouldStop():
+				return
+			}
+			now := s.Clock().Now()
+			newStoreReplicaVisitor(s).Visit(func(repl *Replica) bool {
+				if err := repl.maybeProposeClosedTimestamp(repl.AnnotateCtx(ctx), now); err != nil {
+					log.VEventf(ctx, 2, "%s: unable to close timestamp: %s", repl, err)
+				}
+				return true
+			})
+		}
+	})
+}
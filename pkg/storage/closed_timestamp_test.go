@@ -0,0 +1,97 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestClosedTimestampTracker verifies that timestamps are only closed below
+// writes which are in flight, and that writes are forwarded above the closed
+// timestamp.
+func TestClosedTimestampTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var tr closedTimestampTracker
+	if closed := tr.close(makeTS(10, 0)); closed != makeTS(10, 0) {
+		t.Fatalf("expected closed timestamp %s, got %s", makeTS(10, 0), closed)
+	}
+
+	// A write below the closed timestamp is forwarded above it.
+	ts := makeTS(5, 0)
+	bumped, untrack1 := tr.track(&ts)
+	if !bumped || ts != makeTS(10, 0).Next() {
+		t.Fatalf("expected write to be forwarded to %s, got %s (bumped=%t)", makeTS(10, 0).Next(), ts, bumped)
+	}
+
+	// A write above the closed timestamp is left alone.
+	ts = makeTS(20, 0)
+	bumped, untrack2 := tr.track(&ts)
+	if bumped || ts != makeTS(20, 0) {
+		t.Fatalf("expected write to remain at %s, got %s (bumped=%t)", makeTS(20, 0), ts, bumped)
+	}
+
+	// The closed timestamp cannot advance past the in-flight writes.
+	if closed := tr.close(makeTS(30, 0)); closed != makeTS(10, 0) {
+		t.Fatalf("expected closed timestamp %s, got %s", makeTS(10, 0), closed)
+	}
+	untrack1()
+	untrack1() // untracking is idempotent
+	if closed := tr.close(makeTS(30, 0)); closed != makeTS(20, 0).Prev() {
+		t.Fatalf("expected closed timestamp %s, got %s", makeTS(20, 0).Prev(), closed)
+	}
+	untrack2()
+	if closed := tr.close(makeTS(30, 0)); closed != makeTS(30, 0) {
+		t.Fatalf("expected closed timestamp %s, got %s", makeTS(30, 0), closed)
+	}
+
+	// The closed timestamp never regresses.
+	if closed := tr.close(makeTS(25, 0)); closed != makeTS(30, 0) {
+		t.Fatalf("expected closed timestamp %s, got %s", makeTS(30, 0), closed)
+	}
+	if len(tr.inFlight) != 0 {
+		t.Fatalf("expected no in-flight writes, got %v", tr.inFlight)
+	}
+}
+
+// TestBatchCanBeEvaluatedOnFollower verifies which batches are eligible for
+// follower reads.
+func TestBatchCanBeEvaluatedOnFollower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		reqs        []roachpb.Request
+		consistency roachpb.ReadConsistencyType
+		expected    bool
+	}{
+		{[]roachpb.Request{&roachpb.GetRequest{}}, roachpb.CONSISTENT, true},
+		{[]roachpb.Request{&roachpb.ScanRequest{}, &roachpb.ReverseScanRequest{}}, roachpb.CONSISTENT, true},
+		{[]roachpb.Request{&roachpb.GetRequest{}}, roachpb.INCONSISTENT, false},
+		{[]roachpb.Request{&roachpb.GetRequest{}, &roachpb.PutRequest{}}, roachpb.CONSISTENT, false},
+		{[]roachpb.Request{&roachpb.QueryTxnRequest{}}, roachpb.CONSISTENT, false},
+	}
+	for i, c := range testCases {
+		var ba roachpb.BatchRequest
+		ba.ReadConsistency = c.consistency
+		ba.Add(c.reqs...)
+		if actual := storagebase.BatchCanBeEvaluatedOnFollower(ba); actual != c.expected {
+			t.Errorf("%d: expected %t, got %t", i, c.expected, actual)
+		}
+	}
+}
@@ -231,6 +231,12 @@ type Engine interface {
 	// IngestExternalFile links a file into the RocksDB log-structured
 	// merge-tree.
 	IngestExternalFile(ctx context.Context, path string, move bool) error
+	// WriteFile writes data to a file in the engine's env. Files meant to be
+	// ingested must be written this way, so that they are encrypted at rest
+	// if the engine is.
+	WriteFile(filename string, data []byte) error
+	// IsEncrypted returns whether the engine encrypts new files at rest.
+	IsEncrypted() bool
 }
 
 // Batch is the interface for batch specific operations.
@@ -306,6 +306,14 @@ type RocksDBConfig struct {
 	WarnLargeBatchThreshold time.Duration
 	// Settings instance for cluster-wide knobs.
 	Settings *cluster.Settings
+	// EncryptionKeyPath is the path of the file holding the store key used to
+	// encrypt the store's files at rest, or "plain" to write new files
+	// unencrypted. Encryption is disabled if it is empty, and requires a CCL
+	// binary otherwise.
+	EncryptionKeyPath string
+	// EncryptionOldKeyPath is the path of the file holding the previous store
+	// key, or "plain", when the store key is being rotated.
+	EncryptionOldKeyPath string
 }
 
 // RocksDB is a wrapper around a RocksDB database instance.
@@ -439,12 +447,14 @@ func (r *RocksDB) open() error {
 
 	status := C.DBOpen(&r.rdb, goToCSlice([]byte(r.cfg.Dir)),
 		C.DBOptions{
-			cache:           r.cache.cache,
-			block_size:      C.uint64_t(blockSize),
-			wal_ttl_seconds: C.uint64_t(walTTL),
-			logging_enabled: C.bool(log.V(3)),
-			num_cpu:         C.int(runtime.NumCPU()),
-			max_open_files:  C.int(maxOpenFiles),
+			cache:              r.cache.cache,
+			block_size:         C.uint64_t(blockSize),
+			wal_ttl_seconds:    C.uint64_t(walTTL),
+			logging_enabled:    C.bool(log.V(3)),
+			num_cpu:            C.int(runtime.NumCPU()),
+			max_open_files:     C.int(maxOpenFiles),
+			encryption_key:     goToCSlice([]byte(r.cfg.EncryptionKeyPath)),
+			encryption_old_key: goToCSlice([]byte(r.cfg.EncryptionOldKeyPath)),
 		})
 	if err := statusToError(status); err != nil {
 		return errors.Errorf("could not open rocksdb instance: %s", err)
@@ -2164,6 +2174,11 @@ func (r *RocksDB) WriteFile(filename string, data []byte) error {
 	return statusToError(C.DBEnvWriteFile(r.rdb, goToCSlice([]byte(filename)), goToCSlice(data)))
 }
 
+// IsEncrypted implements the Engine interface.
+func (r *RocksDB) IsEncrypted() bool {
+	return r.cfg.EncryptionKeyPath != "" && r.cfg.EncryptionKeyPath != "plain"
+}
+
 // IsValidSplitKey returns whether the key is a valid split key. Certain key
 // ranges cannot be split (the meta1 span and the system DB span); split keys
 // chosen within any of these ranges are considered invalid. And a split key
@@ -583,6 +583,24 @@ func chunkGCRequest(
 	return ret
 }
 
+// readProtectedTimestamp returns the earliest timestamp protected by an
+// unexpired record in system.protected_ts_records overlapping the range.
+func (gcq *gcQueue) readProtectedTimestamp(
+	ctx context.Context, desc *roachpb.RangeDescriptor, now hlc.Timestamp,
+) (hlc.Timestamp, error) {
+	start := roachpb.Key(keys.MakeTablePrefix(keys.ProtectedTimestampRecordsTableID))
+	kvs, err := gcq.store.DB().Scan(ctx, start, start.PrefixEnd(), 0 /* maxRows */)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	records := make([]roachpb.KeyValue, len(kvs))
+	for i, kv := range kvs {
+		records[i] = roachpb.KeyValue{Key: kv.Key, Value: *kv.Value}
+	}
+	protectedTS, _, err := config.GetProtectedTimestamp(records, desc.RSpan(), now)
+	return protectedTS, err
+}
+
 func (gcq *gcQueue) processImpl(
 	ctx context.Context, repl *Replica, sysCfg config.SystemConfig, now hlc.Timestamp,
 ) error {
@@ -595,8 +613,13 @@ func (gcq *gcQueue) processImpl(
 	if err != nil {
 		return errors.Errorf("could not find zone config for range %s: %s", repl, err)
 	}
+	// Lookup the earliest timestamp which running jobs need preserved.
+	protectedTS, err := gcq.readProtectedTimestamp(ctx, desc, now)
+	if err != nil {
+		return errors.Errorf("could not find protected timestamp for range %s: %s", repl, err)
+	}
 
-	gcKeys, info, err := RunGC(ctx, desc, snap, now, zone.GC,
+	gcKeys, info, err := RunGC(ctx, desc, snap, now, zone.GC, protectedTS,
 		func(now hlc.Timestamp, txn *roachpb.Transaction, typ roachpb.PushTxnType) {
 			pushTxn(ctx, gcq.store.DB(), now, txn, typ)
 		},
@@ -673,7 +696,11 @@ type GCInfo struct {
 	ResolveTotal int
 	// ResolveErrors is the number of successful intent resolutions.
 	ResolveSuccess int
-	// Threshold is the computed expiration timestamp. Equal to `Now - Policy`.
+	// ProtectedTimestamp is the earliest timestamp protected by a record in
+	// system.protected_ts_records overlapping the range, if any.
+	ProtectedTimestamp hlc.Timestamp
+	// Threshold is the computed expiration timestamp. Equal to `Now - Policy`,
+	// unless that would have collected data at ProtectedTimestamp.
 	Threshold hlc.Timestamp
 }
 
@@ -702,13 +729,15 @@ type lockableGCInfo struct {
 // Engine (which is not mutated). It uses the provided functions pushTxnFn and
 // resolveIntentsFn to clarify the true status of and clean up after encountered
 // transactions. It returns a slice of gc'able keys from the data, transaction,
-// and abort spans.
+// and abort spans. If protectedTS is set, no values visible at it are
+// collected, regardless of policy.
 func RunGC(
 	ctx context.Context,
 	desc *roachpb.RangeDescriptor,
 	snap engine.Reader,
 	now hlc.Timestamp,
 	policy config.GCPolicy,
+	protectedTS hlc.Timestamp,
 	pushTxnFn pushFunc,
 	resolveIntentsFn resolveFunc,
 ) ([]roachpb.GCRequest_GCKey, GCInfo, error) {
@@ -719,6 +748,7 @@ func RunGC(
 	var infoMu = lockableGCInfo{}
 	infoMu.Policy = policy
 	infoMu.Now = now
+	infoMu.ProtectedTimestamp = protectedTS
 
 	{
 		realResolveIntentsFn := resolveIntentsFn
@@ -750,6 +780,11 @@ func RunGC(
 	abortSpanGCThreshold := now.Add(-int64(abortCacheAgeThreshold), 0)
 
 	gc := engine.MakeGarbageCollector(now, policy)
+	if (protectedTS != hlc.Timestamp{}) && !gc.Threshold.Less(protectedTS) {
+		// Reads at the protected timestamp must remain possible, which
+		// requires the threshold to stay strictly below it.
+		gc.Threshold = protectedTS.Prev()
+	}
 	infoMu.Threshold = gc.Threshold
 	infoMu.TxnSpanGCThreshold = txnExp
 
@@ -25,12 +25,14 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -536,6 +538,83 @@ func TestGCQueueProcess(t *testing.T) {
 	}
 }
 
+// TestGCQueueProtectedTimestamp verifies that the GC queue does not collect
+// values needed to read at a protected timestamp, and that it ignores
+// protected timestamp records once they have expired.
+func TestGCQueueProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	tc.manualClock.Increment(48 * 60 * 60 * 1E9) // 2d past the epoch
+	now := tc.Clock().Now().WallTime
+
+	ts1 := makeTS(now-2*24*60*60*1E9+1, 0) // 2d old
+	ts2 := makeTS(now-30*60*60*1E9, 0)     // 30h old, so ts1 is GC'able
+	ts3 := makeTS(now-1E9, 0)              // 1s old
+	key := roachpb.Key("a")
+	for i, ts := range []hlc.Timestamp{ts1, ts2, ts3} {
+		pArgs := putArgs(key, []byte("value"))
+		if _, err := tc.SendWrappedWith(roachpb.Header{Timestamp: ts}, &pArgs); err != nil {
+			t.Fatalf("%d: could not put data: %s", i, err)
+		}
+	}
+
+	cfg, ok := tc.gossip.GetSystemConfig()
+	if !ok {
+		t.Fatal("config not set")
+	}
+	// Protect the value written at ts1, which is the one visible just below
+	// ts2, until an hour from now.
+	protectedTS := ts2.Prev()
+	record := config.ProtectedTimestampRecord{
+		Timestamp:  protectedTS,
+		Spans:      []roachpb.Span{{Key: key, EndKey: key.Next()}},
+		Expiration: makeTS(now+60*60*1E9, 0),
+	}
+	recordKey := keys.MakeTablePrefix(keys.ProtectedTimestampRecordsTableID)
+	recordKey = encoding.EncodeUvarintAscending(recordKey, 1 /* primary index */)
+	recordKey = encoding.EncodeVarintAscending(recordKey, 1 /* id */)
+	recordKey = keys.MakeFamilyKey(recordKey, 3 /* record */)
+	if err := tc.store.DB().Put(context.Background(), recordKey, &record); err != nil {
+		t.Fatal(err)
+	}
+
+	verifyKVs := func(expTSs ...hlc.Timestamp) {
+		kvs, err := engine.Scan(tc.store.Engine(), engine.MakeMVCCMetadataKey(key),
+			engine.MakeMVCCMetadataKey(keys.MaxKey), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(kvs) != len(expTSs) {
+			t.Fatalf("expected length %d; got %d", len(expTSs), len(kvs))
+		}
+		for i, kv := range kvs {
+			if kv.Key.Timestamp != expTSs[i] {
+				t.Errorf("%d: expected ts=%s; got %s", i, expTSs[i], kv.Key.Timestamp)
+			}
+		}
+	}
+
+	gcQ := newGCQueue(tc.store, tc.gossip)
+	if err := gcQ.processImpl(context.Background(), tc.repl, cfg, tc.Clock().Now()); err != nil {
+		t.Fatal(err)
+	}
+	verifyKVs(ts3, ts2, ts1)
+	if threshold := tc.repl.GetGCThreshold(); !threshold.Less(protectedTS) {
+		t.Errorf("expected GC threshold below %s; got %s", protectedTS, threshold)
+	}
+
+	// Once the record expires, the value written at ts1 is collected.
+	tc.manualClock.Increment(2 * 60 * 60 * 1E9)
+	if err := gcQ.processImpl(context.Background(), tc.repl, cfg, tc.Clock().Now()); err != nil {
+		t.Fatal(err)
+	}
+	verifyKVs(ts3, ts2)
+}
+
 func TestGCQueueTransactionTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
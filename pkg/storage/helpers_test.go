@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -97,6 +98,12 @@ func (s *Store) ForceSplitScanAndProcess() {
 	forceScanAndProcess(s, s.splitQueue.baseQueue)
 }
 
+// ForceMergeScanAndProcess iterates over all ranges and enqueues any that
+// may need to be merged.
+func (s *Store) ForceMergeScanAndProcess() {
+	forceScanAndProcess(s, s.mergeQueue.baseQueue)
+}
+
 // ForceRaftLogScanAndProcess iterates over all ranges and enqueues any that
 // need their raft logs truncated and then process each of them.
 func (s *Store) ForceRaftLogScanAndProcess() {
@@ -165,6 +172,28 @@ func (s *Store) SetSplitQueueActive(active bool) {
 	s.setSplitQueueActive(active)
 }
 
+// SetMergeQueueActive enables or disables the merge queue.
+func (s *Store) SetMergeQueueActive(active bool) {
+	s.setMergeQueueActive(active)
+}
+
+// FreezeForMerge freezes the range as the right-hand side of a merge by the
+// transaction with the given ID.
+func (r *Replica) FreezeForMerge(ctx context.Context, txnID uuid.UUID) error {
+	return r.freezeForMerge(ctx, txnID)
+}
+
+// UnfreezeAfterMerge lifts a freeze set up by FreezeForMerge.
+func (r *Replica) UnfreezeAfterMerge() {
+	r.unfreezeAfterMerge()
+}
+
+// CloseTimestamp attempts to advance the closed timestamp tracked by the
+// replica to the target and returns the resulting closed timestamp.
+func (r *Replica) CloseTimestamp(target hlc.Timestamp) hlc.Timestamp {
+	return r.closedTSTracker.close(target)
+}
+
 // SetRaftSnapshotQueueActive enables or disables the raft snapshot queue.
 func (s *Store) SetRaftSnapshotQueueActive(active bool) {
 	s.setRaftSnapshotQueueActive(active)
@@ -40,6 +40,7 @@ const (
 	ReasonStoreDecommissioning RangeLogEventReason = "store decommissioning"
 	ReasonRebalance            RangeLogEventReason = "rebalance"
 	ReasonAdminRequest         RangeLogEventReason = "admin request"
+	ReasonStoreOverloaded      RangeLogEventReason = "store overloaded"
 )
 
 func (s *Store) insertRangeLogEvent(
@@ -177,6 +178,33 @@ func (s *Store) logChange(
 	})
 }
 
+// logLeaseTransfer logs the transfer of a range's lease from this store to
+// the given replica.
+func (s *Store) logLeaseTransfer(
+	ctx context.Context,
+	txn *client.Txn,
+	desc roachpb.RangeDescriptor,
+	target roachpb.ReplicaDescriptor,
+	reason RangeLogEventReason,
+	details string,
+) error {
+	if !s.cfg.LogRangeEvents {
+		return nil
+	}
+	return s.insertRangeLogEvent(ctx, txn, RangeLogEvent{
+		Timestamp: selectEventTimestamp(s, txn.Proto().Timestamp),
+		RangeID:   desc.RangeID,
+		EventType: RangeLogEventType_transfer_lease,
+		StoreID:   s.StoreID(),
+		Info: &RangeLogEvent_Info{
+			UpdatedDesc: &desc,
+			LeaseTarget: &target,
+			Reason:      reason,
+			Details:     details,
+		},
+	})
+}
+
 // selectEventTimestamp selects a timestamp for this log message. If the
 // transaction this event is being written in has a non-zero timestamp, then that
 // timestamp should be used; otherwise, the store's physical clock is used.
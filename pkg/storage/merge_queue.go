@@ -0,0 +1,228 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+const (
+	// mergeQueueTimerDuration is the duration between merges of queued ranges.
+	mergeQueueTimerDuration = 0 // zero duration to process merges greedily.
+)
+
+// MergeQueueEnabled controls whether ranges are merged automatically.
+var MergeQueueEnabled = settings.RegisterBoolSetting(
+	"kv.range_merge.queue_enabled",
+	"whether the automatic merge queue is enabled",
+	false,
+)
+
+// mergeQueue manages a queue of ranges slated to be merged with their
+// right-hand neighbor because both have shrunk below the minimum size of
+// their zone, e.g. after a table was dropped or its rows were deleted.
+//
+// A range is merged by its leaseholder. Before merging, the replicas of the
+// right-hand range are relocated onto the stores of the left-hand range and
+// its lease is moved to the same store, as AdminMerge requires.
+type mergeQueue struct {
+	*baseQueue
+	db *client.DB
+}
+
+// newMergeQueue returns a new instance of mergeQueue.
+func newMergeQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *mergeQueue {
+	mq := &mergeQueue{
+		db: db,
+	}
+	mq.baseQueue = newBaseQueue(
+		"merge", mq, store, gossip,
+		queueConfig{
+			maxSize:              defaultQueueMaxSize,
+			needsLease:           true,
+			needsSystemConfig:    true,
+			acceptsUnsplitRanges: false,
+			successes:            store.metrics.MergeQueueSuccesses,
+			failures:             store.metrics.MergeQueueFailures,
+			pending:              store.metrics.MergeQueuePending,
+			processingNanos:      store.metrics.MergeQueueProcessingNanos,
+		},
+	)
+	return mq
+}
+
+func (mq *mergeQueue) enabled() bool {
+	return MergeQueueEnabled.Get(&mq.store.ClusterSettings().SV)
+}
+
+// shouldQueue determines whether a range should be queued for merging. This
+// is true if the range is smaller than the minimum size for its zone. Whether
+// its right-hand neighbor is small enough as well is only checked when the
+// range is processed.
+func (mq *mergeQueue) shouldQueue(
+	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg config.SystemConfig,
+) (shouldQ bool, priority float64) {
+	if !mq.enabled() {
+		return false, 0
+	}
+	desc := repl.Desc()
+	if desc.EndKey.Equal(roachpb.RKeyMax) {
+		// The last range has no right-hand neighbor.
+		return false, 0
+	}
+	zone, err := sysCfg.GetZoneConfigForKey(desc.StartKey)
+	if err != nil {
+		log.Error(ctx, err)
+		return false, 0
+	}
+	size := repl.GetMVCCStats().Total()
+	if size >= zone.RangeMinBytes {
+		return false, 0
+	}
+	if mq.splitByLoad(repl) {
+		// Merging a range which is busy enough to be split by load would only
+		// have the split queue undo the merge.
+		return false, 0
+	}
+	// Prioritize the emptiest ranges.
+	return true, 1 - float64(size)/float64(zone.RangeMinBytes)
+}
+
+// splitByLoad returns whether the range serves enough queries per second to
+// be split based on its load. See LoadBasedSplitQPSThreshold.
+func (mq *mergeQueue) splitByLoad(repl *Replica) bool {
+	sv := &mq.store.ClusterSettings().SV
+	if !LoadBasedSplitEnabled.Get(sv) || repl.leaseholderStats == nil {
+		return false
+	}
+	qps, dur := repl.leaseholderStats.avgQPS()
+	return dur >= MinStatsDuration && qps >= float64(LoadBasedSplitQPSThreshold.Get(sv))
+}
+
+// process merges the range with its right-hand neighbor if both are smaller
+// than the minimum size of their zone and no split is required between them.
+func (mq *mergeQueue) process(
+	ctx context.Context, lhsRepl *Replica, sysCfg config.SystemConfig,
+) error {
+	if !mq.enabled() {
+		return nil
+	}
+	lhsDesc := lhsRepl.Desc()
+	if lhsDesc.EndKey.Equal(roachpb.RKeyMax) {
+		return nil
+	}
+	zone, err := sysCfg.GetZoneConfigForKey(lhsDesc.StartKey)
+	if err != nil {
+		return err
+	}
+	lhsStats := lhsRepl.GetMVCCStats()
+	if lhsStats.Total() >= zone.RangeMinBytes || mq.splitByLoad(lhsRepl) {
+		return nil
+	}
+
+	rhsDesc, rhsStats, err := mq.rangeStats(ctx, lhsDesc.EndKey)
+	if err != nil {
+		return err
+	}
+	if sysCfg.NeedsSplit(lhsDesc.StartKey, rhsDesc.EndKey) {
+		// The ranges belong to different tables or zones.
+		if log.V(2) {
+			log.Infof(ctx, "skipping merge with %s, which would need to be split again", rhsDesc)
+		}
+		return nil
+	}
+	if rhsStats.Total() >= zone.RangeMinBytes {
+		if log.V(2) {
+			log.Infof(ctx, "skipping merge with %s, which is too large (%d bytes)", rhsDesc, rhsStats.Total())
+		}
+		return nil
+	}
+	if merged := lhsStats.Total() + rhsStats.Total(); merged >= zone.RangeMaxBytes {
+		// Don't merge ranges which the split queue would split again.
+		return nil
+	}
+
+	// Collocate the replicas of the right-hand range with those of the
+	// left-hand range, and move its lease onto this store.
+	targets := []roachpb.ReplicationTarget{{
+		NodeID:  lhsRepl.store.Ident.NodeID,
+		StoreID: lhsRepl.store.Ident.StoreID,
+	}}
+	for _, r := range lhsDesc.Replicas {
+		if r.StoreID != lhsRepl.store.Ident.StoreID {
+			targets = append(targets, roachpb.ReplicationTarget{NodeID: r.NodeID, StoreID: r.StoreID})
+		}
+	}
+	if !replicaSetsEqual(lhsDesc.Replicas, rhsDesc.Replicas) {
+		log.Eventf(ctx, "relocating %s to collocate it with %s", rhsDesc, lhsDesc)
+		if err := relocateRange(ctx, mq.db, rhsDesc, targets); err != nil {
+			return errors.Wrapf(err, "unable to collocate %s with %s", rhsDesc, lhsDesc)
+		}
+	} else if err := mq.db.AdminTransferLease(
+		ctx, rhsDesc.StartKey.AsRawKey(), lhsRepl.store.StoreID(),
+	); err != nil {
+		return errors.Wrapf(err, "unable to transfer the lease of %s", rhsDesc)
+	}
+
+	log.Eventf(ctx, "merging %s into %s", rhsDesc, lhsDesc)
+	if _, pErr := lhsRepl.AdminMerge(ctx, roachpb.AdminMergeRequest{
+		Span: roachpb.Span{Key: lhsDesc.StartKey.AsRawKey()},
+	}); pErr != nil {
+		return errors.Wrapf(pErr.GoError(), "unable to merge %s into %s", rhsDesc, lhsRepl)
+	}
+	return nil
+}
+
+// rangeStats returns the descriptor and the MVCC statistics of the range
+// containing the given key.
+func (mq *mergeQueue) rangeStats(
+	ctx context.Context, key roachpb.RKey,
+) (roachpb.RangeDescriptor, enginepb.MVCCStats, error) {
+	resp, pErr := client.SendWrappedWith(
+		ctx, mq.db.GetSender(), roachpb.Header{ReturnRangeInfo: true},
+		&roachpb.RangeStatsRequest{Span: roachpb.Span{Key: key.AsRawKey()}},
+	)
+	if pErr != nil {
+		return roachpb.RangeDescriptor{}, enginepb.MVCCStats{}, pErr.GoError()
+	}
+	rangeInfos := resp.Header().RangeInfos
+	if len(rangeInfos) != 1 {
+		return roachpb.RangeDescriptor{}, enginepb.MVCCStats{}, errors.Errorf(
+			"expected range info for a single range, got %d", len(rangeInfos))
+	}
+	return rangeInfos[0].Desc, resp.(*roachpb.RangeStatsResponse).MVCCStats, nil
+}
+
+// timer returns interval between processing successive queued merges.
+func (*mergeQueue) timer(_ time.Duration) time.Duration {
+	return mergeQueueTimerDuration
+}
+
+// purgatoryChan returns nil.
+func (*mergeQueue) purgatoryChan() <-chan struct{} {
+	return nil
+}
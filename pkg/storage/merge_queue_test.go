@@ -0,0 +1,97 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// TestMergeQueueShouldQueue verifies that shouldQueue compares the size of
+// the range with the minimum size of its zone.
+func TestMergeQueueShouldQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	config.TestingSetZoneConfig(2000, config.ZoneConfig{
+		RangeMinBytes: 1 << 10, RangeMaxBytes: 64 << 20,
+	})
+
+	tableStart := roachpb.RKey(keys.MakeTablePrefix(2000))
+	tableEnd := roachpb.RKey(keys.MakeTablePrefix(2001))
+	testCases := []struct {
+		start, end roachpb.RKey
+		bytes      int64
+		disabled   bool
+		shouldQ    bool
+		priority   float64
+	}{
+		// Empty range.
+		{tableStart, tableEnd, 0, false, true, 1},
+		// Half of the minimum size.
+		{tableStart, tableEnd, 1 << 9, false, true, 0.5},
+		// At the minimum size.
+		{tableStart, tableEnd, 1 << 10, false, false, 0},
+		// The last range has no right-hand neighbor.
+		{tableStart, roachpb.RKeyMax, 0, false, false, 0},
+		// The queue is disabled.
+		{tableStart, tableEnd, 0, true, false, 0},
+	}
+
+	mergeQ := newMergeQueue(tc.store, nil, tc.gossip)
+
+	cfg, ok := tc.gossip.GetSystemConfig()
+	if !ok {
+		t.Fatal("config not set")
+	}
+
+	for i, test := range testCases {
+		MergeQueueEnabled.Override(&tc.store.ClusterSettings().SV, !test.disabled)
+
+		// Create a replica for testing that is not hooked up to the store.
+		copy := *tc.repl.Desc()
+		copy.StartKey = test.start
+		copy.EndKey = test.end
+		repl, err := NewReplica(&copy, tc.store, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		repl.mu.Lock()
+		repl.mu.state.Stats = &enginepb.MVCCStats{KeyBytes: test.bytes}
+		repl.mu.Unlock()
+
+		shouldQ, priority := mergeQ.shouldQueue(context.TODO(), hlc.Timestamp{}, repl, cfg)
+		if shouldQ != test.shouldQ {
+			t.Errorf("%d: should queue expected %t; got %t", i, test.shouldQ, shouldQ)
+		}
+		if math.Abs(priority-test.priority) > 0.00001 {
+			t.Errorf("%d: priority expected %f; got %f", i, test.priority, priority)
+		}
+	}
+}
@@ -104,6 +104,11 @@ var (
 		Name: "leases.epoch",
 		Help: "Number of replica leaseholders using epoch-based leases"}
 
+	// Follower read metrics.
+	metaFollowerReadsCount = metric.Metadata{
+		Name: "follower_reads.success_count",
+		Help: "Number of reads successfully processed by any replica"}
+
 	// Storage metrics.
 	metaLiveBytes = metric.Metadata{
 		Name: "livebytes",
@@ -164,6 +169,15 @@ var (
 	metaAverageWritesPerSecond = metric.Metadata{
 		Name: "rebalancing.writespersecond",
 		Help: "Number of keys written (i.e. applied by raft) per second to the store, averaged over a large time period as used in rebalancing decisions"}
+	metaAverageQueriesPerSecond = metric.Metadata{
+		Name: "rebalancing.queriespersecond",
+		Help: "Number of requests received per second by the store's leaseholders, averaged over a large time period as used in rebalancing decisions"}
+	metaStoreRebalancerLeaseTransferCount = metric.Metadata{
+		Name: "rebalancing.lease.transfers",
+		Help: "Number of lease transfers motivated by store-level load imbalances"}
+	metaStoreRebalancerRangeRebalanceCount = metric.Metadata{
+		Name: "rebalancing.range.rebalances",
+		Help: "Number of range rebalance operations motivated by store-level load imbalances"}
 
 	// RocksDB metrics.
 	metaRdbBlockCacheHits = metric.Metadata{
@@ -222,6 +236,12 @@ var (
 	metaRangeSnapshotsPreemptiveApplied = metric.Metadata{
 		Name: "range.snapshots.preemptive-applied",
 		Help: "Number of applied pre-emptive snapshots"}
+	metaRangeSnapshotsSentBytes = metric.Metadata{
+		Name: "range.snapshots.sent-bytes",
+		Help: "Number of snapshot bytes sent"}
+	metaRangeSnapshotsThrottledNanos = metric.Metadata{
+		Name: "range.snapshots.throttled-nanos",
+		Help: "Nanoseconds spent waiting on snapshot rate and concurrency limits"}
 	metaRangeRaftLeaderTransfers = metric.Metadata{
 		Name: "range.raftleadertransfers",
 		Help: "Number of raft leader transfers"}
@@ -392,6 +412,18 @@ var (
 	metaSplitQueueProcessingNanos = metric.Metadata{
 		Name: "queue.split.processingnanos",
 		Help: "Nanoseconds spent processing replicas in the split queue"}
+	metaMergeQueueSuccesses = metric.Metadata{
+		Name: "queue.merge.process.success",
+		Help: "Number of replicas successfully processed by the merge queue"}
+	metaMergeQueueFailures = metric.Metadata{
+		Name: "queue.merge.process.failure",
+		Help: "Number of replicas which failed processing in the merge queue"}
+	metaMergeQueuePending = metric.Metadata{
+		Name: "queue.merge.pending",
+		Help: "Number of pending replicas in the merge queue"}
+	metaMergeQueueProcessingNanos = metric.Metadata{
+		Name: "queue.merge.processingnanos",
+		Help: "Nanoseconds spent processing replicas in the merge queue"}
 	metaTimeSeriesMaintenanceQueueSuccesses = metric.Metadata{
 		Name: "queue.tsmaintenance.process.success",
 		Help: "Number of replicas successfully processed by the time series maintenance queue"}
@@ -503,6 +535,9 @@ type StoreMetrics struct {
 	LeaseExpirationCount      *metric.Gauge
 	LeaseEpochCount           *metric.Gauge
 
+	// Follower read metrics.
+	FollowerReadsCount *metric.Counter
+
 	// Storage metrics.
 	LiveBytes       *metric.Gauge
 	KeyBytes        *metric.Gauge
@@ -524,7 +559,10 @@ type StoreMetrics struct {
 	SysCount        *metric.Gauge
 
 	// Rebalancing metrics.
-	AverageWritesPerSecond *metric.GaugeFloat64
+	AverageWritesPerSecond             *metric.GaugeFloat64
+	AverageQueriesPerSecond            *metric.GaugeFloat64
+	StoreRebalancerLeaseTransferCount  *metric.Counter
+	StoreRebalancerRangeRebalanceCount *metric.Counter
 
 	// RocksDB metrics.
 	RdbBlockCacheHits           *metric.Gauge
@@ -552,6 +590,8 @@ type StoreMetrics struct {
 	RangeSnapshotsGenerated         *metric.Counter
 	RangeSnapshotsNormalApplied     *metric.Counter
 	RangeSnapshotsPreemptiveApplied *metric.Counter
+	RangeSnapshotsSentBytes         *metric.Counter
+	RangeSnapshotsThrottledNanos    *metric.Counter
 	RangeRaftLeaderTransfers        *metric.Counter
 
 	// Raft processing metrics.
@@ -621,6 +661,10 @@ type StoreMetrics struct {
 	SplitQueueFailures                        *metric.Counter
 	SplitQueuePending                         *metric.Gauge
 	SplitQueueProcessingNanos                 *metric.Counter
+	MergeQueueSuccesses                       *metric.Counter
+	MergeQueueFailures                        *metric.Counter
+	MergeQueuePending                         *metric.Gauge
+	MergeQueueProcessingNanos                 *metric.Counter
 	TimeSeriesMaintenanceQueueSuccesses       *metric.Counter
 	TimeSeriesMaintenanceQueueFailures        *metric.Counter
 	TimeSeriesMaintenanceQueuePending         *metric.Gauge
@@ -694,6 +738,9 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		LeaseExpirationCount:      metric.NewGauge(metaLeaseExpirationCount),
 		LeaseEpochCount:           metric.NewGauge(metaLeaseEpochCount),
 
+		// Follower read metrics.
+		FollowerReadsCount: metric.NewCounter(metaFollowerReadsCount),
+
 		// Storage metrics.
 		LiveBytes:       metric.NewGauge(metaLiveBytes),
 		KeyBytes:        metric.NewGauge(metaKeyBytes),
@@ -715,7 +762,10 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		SysCount:        metric.NewGauge(metaSysCount),
 
 		// Rebalancing metrics.
-		AverageWritesPerSecond: metric.NewGaugeFloat64(metaAverageWritesPerSecond),
+		AverageWritesPerSecond:             metric.NewGaugeFloat64(metaAverageWritesPerSecond),
+		AverageQueriesPerSecond:            metric.NewGaugeFloat64(metaAverageQueriesPerSecond),
+		StoreRebalancerLeaseTransferCount:  metric.NewCounter(metaStoreRebalancerLeaseTransferCount),
+		StoreRebalancerRangeRebalanceCount: metric.NewCounter(metaStoreRebalancerRangeRebalanceCount),
 
 		// RocksDB metrics.
 		RdbBlockCacheHits:           metric.NewGauge(metaRdbBlockCacheHits),
@@ -738,6 +788,8 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		RangeSnapshotsGenerated:         metric.NewCounter(metaRangeSnapshotsGenerated),
 		RangeSnapshotsNormalApplied:     metric.NewCounter(metaRangeSnapshotsNormalApplied),
 		RangeSnapshotsPreemptiveApplied: metric.NewCounter(metaRangeSnapshotsPreemptiveApplied),
+		RangeSnapshotsSentBytes:         metric.NewCounter(metaRangeSnapshotsSentBytes),
+		RangeSnapshotsThrottledNanos:    metric.NewCounter(metaRangeSnapshotsThrottledNanos),
 		RangeRaftLeaderTransfers:        metric.NewCounter(metaRangeRaftLeaderTransfers),
 
 		// Raft processing metrics.
@@ -805,6 +857,10 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		SplitQueueFailures:                        metric.NewCounter(metaSplitQueueFailures),
 		SplitQueuePending:                         metric.NewGauge(metaSplitQueuePending),
 		SplitQueueProcessingNanos:                 metric.NewCounter(metaSplitQueueProcessingNanos),
+		MergeQueueSuccesses:                       metric.NewCounter(metaMergeQueueSuccesses),
+		MergeQueueFailures:                        metric.NewCounter(metaMergeQueueFailures),
+		MergeQueuePending:                         metric.NewGauge(metaMergeQueuePending),
+		MergeQueueProcessingNanos:                 metric.NewCounter(metaMergeQueueProcessingNanos),
 		TimeSeriesMaintenanceQueueSuccesses:       metric.NewCounter(metaTimeSeriesMaintenanceQueueFailures),
 		TimeSeriesMaintenanceQueueFailures:        metric.NewCounter(metaTimeSeriesMaintenanceQueueSuccesses),
 		TimeSeriesMaintenanceQueuePending:         metric.NewGauge(metaTimeSeriesMaintenanceQueuePending),
@@ -113,6 +113,16 @@ func (rq *raftSnapshotQueue) processRaftSnapshot(
 	if !ok {
 		return errors.Errorf("%s: replica %d not present in %v", repl, id, desc.Replicas)
 	}
+	// Learners are caught up by the replica addition which created them,
+	// which runs on the leaseholder. Avoid sending a duplicate snapshot
+	// concurrently with it. If the addition failed, the replicate queue
+	// removes the learner.
+	if repDesc.GetType() == roachpb.LEARNER && repl.OwnsValidLease(repl.store.Clock().Now()) {
+		if log.V(1) {
+			log.Infof(ctx, "skipping snapshot; %s is a learner being caught up by its addition", repDesc)
+		}
+		return nil
+	}
 	err := repl.sendSnapshot(ctx, repDesc, snapTypeRaft, SnapshotRequest_RECOVERY)
 	// Report the snapshot status to Raft, which expects us to do this once
 	// we finish sending the snapshot.
@@ -25,8 +25,10 @@ import (
 	"unsafe"
 
 	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/pkg/errors"
 	"github.com/rubyist/circuitbreaker"
 	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 
 	"github.com/cockroachdb/cockroach/pkg/gossip"
@@ -160,6 +162,14 @@ type RaftTransport struct {
 	stats    syncutil.IntMap // map[roachpb.NodeID]*raftTransportStats
 	breakers syncutil.IntMap // map[roachpb.NodeID]*circuit.Breaker
 	handlers syncutil.IntMap // map[roachpb.StoreID]*RaftMessageHandler
+
+	// Token buckets limiting the rate at which the snapshots of each priority
+	// are sent. They are shared by all snapshots sent by the node so that
+	// concurrent snapshots do not multiply the configured rates.
+	snapshotLimiters struct {
+		syncutil.Mutex
+		m map[SnapshotRequest_Priority]*rate.Limiter
+	}
 }
 
 // NewDummyRaftTransport returns a dummy raft transport for use in tests which
@@ -182,6 +192,7 @@ func NewRaftTransport(
 		rpcContext:     rpcContext,
 		st:             st,
 	}
+	t.snapshotLimiters.m = make(map[SnapshotRequest_Priority]*rate.Limiter)
 
 	if grpcServer != nil {
 		RegisterMultiRaftServer(grpcServer, t)
@@ -606,14 +617,15 @@ func (t *RaftTransport) SendAsync(req *RaftMessageRequest) bool {
 	}
 }
 
-// SendSnapshot streams the given outgoing snapshot. The caller is responsible
-// for closing the OutgoingSnapshot.
+// SendSnapshot streams the given outgoing snapshot, rate limited according to
+// its priority. The caller is responsible for closing the OutgoingSnapshot.
 func (t *RaftTransport) SendSnapshot(
 	ctx context.Context,
 	storePool *StorePool,
 	header SnapshotRequest_Header,
 	snap *OutgoingSnapshot,
 	newBatch func() engine.Batch,
+	metrics *StoreMetrics,
 	sent func(),
 ) error {
 	var stream MultiRaft_RaftSnapshotClient
@@ -645,5 +657,38 @@ func (t *RaftTransport) SendSnapshot(
 			log.Warningf(ctx, "failed to close snapshot stream: %s", err)
 		}
 	}()
-	return sendSnapshot(ctx, t.st, stream, storePool, header, snap, newBatch, sent)
+	limiter, err := t.snapshotLimiter(header.Priority)
+	if err != nil {
+		return errors.Wrapf(err, "%s", header.RaftMessageRequest.ToReplica)
+	}
+	return sendSnapshot(ctx, stream, storePool, header, snap, newBatch, limiter, metrics, sent)
+}
+
+// snapshotLimiter returns the token bucket shared by the snapshots of the
+// given priority. Its rate is refreshed from the corresponding cluster
+// setting.
+func (t *RaftTransport) snapshotLimiter(priority SnapshotRequest_Priority) (*rate.Limiter, error) {
+	targetRate, err := snapshotRateLimit(t.st, priority)
+	if err != nil {
+		return nil, err
+	}
+	// Convert the bytes/sec rate limit to batches/sec.
+	//
+	// TODO(peter): Using bytes/sec for rate limiting seems more natural but has
+	// practical difficulties. We either need to use a very large burst size
+	// which seems to disable the rate limiting, or call WaitN in smaller than
+	// burst size chunks which caused excessive slowness in testing. Would be
+	// nice to figure this out, but the batches/sec rate limit works for now.
+	limit := targetRate / snapshotBatchSize
+
+	t.snapshotLimiters.Lock()
+	defer t.snapshotLimiters.Unlock()
+	limiter, ok := t.snapshotLimiters.m[priority]
+	if !ok {
+		limiter = rate.NewLimiter(limit, 1 /* burst size */)
+		t.snapshotLimiters.m[priority] = limiter
+	} else if limiter.Limit() != limit {
+		limiter.SetLimit(limit)
+	}
+	return limiter, nil
 }
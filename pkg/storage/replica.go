@@ -234,6 +234,10 @@ type Replica struct {
 	// writeStats tracks the number of keys written by applied raft commands
 	// in order to aid in replica rebalancing decisions.
 	writeStats *replicaStats
+	// loadSplitDecider samples the keys of the requests to the replica once
+	// the leaseholderStats indicate that the range should be split to spread
+	// its load.
+	loadSplitDecider *loadSplitDecider
 
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
@@ -244,6 +248,26 @@ type Replica struct {
 	// RWMutex.
 	readOnlyCmdMu syncutil.RWMutex
 
+	// mergeMu tracks the freeze of this range while it is being merged into
+	// its left-hand neighbor. See freezeForMerge.
+	mergeMu struct {
+		syncutil.Mutex
+		// frozen is non-nil while the range is frozen, and is closed when the
+		// freeze is lifted.
+		frozen chan struct{}
+		// txnID is the ID of the merge transaction, whose requests are served
+		// while the range is frozen.
+		txnID uuid.UUID
+		// inFlight is the number of requests currently being served.
+		inFlight int
+		// sealed is set by sealForMerge, after which pushes and intent
+		// resolution are blocked as well.
+		sealed bool
+		// lease is the lease under which the range was frozen, which
+		// sealForMerge verifies is still held.
+		lease roachpb.Lease
+	}
+
 	// rangeStr is a string representation of a RangeDescriptor that can be
 	// atomically read and updated without needing to acquire the replica.mu lock.
 	// All updates to state.Desc should be duplicated here.
@@ -267,6 +291,11 @@ type Replica struct {
 	// Contains the lease history when enabled.
 	leaseHistory *leaseHistory
 
+	// closedTSTracker closes timestamps while the replica holds the lease.
+	//
+	// Locking notes: Replica.mu < Replica.closedTSTracker
+	closedTSTracker closedTimestampTracker
+
 	cmdQMu struct {
 		// Protects all fields in the cmdQMu struct.
 		//
@@ -308,10 +337,17 @@ type Replica struct {
 		// the follower to estimate the number of Raft log entries it is
 		// behind. This field is only valid when the Replica is a follower.
 		estimatedCommitIndex uint64
-		// The raft log index of a pending preemptive snapshot. Used to prohibit
-		// raft log truncation while a preemptive snapshot is in flight. A value of
-		// 0 indicates that there is no pending snapshot.
+		// The raft log index of a pending snapshot for a replica being added to
+		// the range. Used to prohibit raft log truncation while the new replica
+		// is being caught up. A value of 0 indicates that there is no pending
+		// snapshot.
 		pendingSnapshotIndex uint64
+		// inFlightLearners holds the learners which this replica is catching
+		// up in addReplica. They are never considered abandoned.
+		inFlightLearners map[roachpb.ReplicaID]struct{}
+		// learnersSeen records when the learners of the range which this
+		// replica is not adding were first observed by abandonedLearners.
+		learnersSeen map[roachpb.ReplicaID]time.Time
 		// raftLogSize is the approximate size in bytes of the persisted raft log.
 		// On server restart, this value is assumed to be zero to avoid costly scans
 		// of the raft log. This will be correct when all log entries predating this
@@ -342,6 +378,10 @@ type Replica struct {
 		minLeaseProposedTS hlc.Timestamp
 		// Max bytes before split.
 		maxBytes int64
+		// closedTimestamp is the highest closed timestamp carried by a Raft
+		// command applied by this replica. Consistent reads at or below it
+		// can be served without the range lease.
+		closedTimestamp hlc.Timestamp
 		// proposals stores the Raft in-flight commands which
 		// originated at this Replica, i.e. all commands for which
 		// propose has been called, but which have not yet
@@ -593,6 +633,7 @@ func newReplica(rangeID roachpb.RangeID, store *Store) *Replica {
 	// Pass nil for the localityOracle because we intentionally don't track the
 	// origin locality of write load.
 	r.writeStats = newReplicaStats(store.Clock(), nil)
+	r.loadSplitDecider = newLoadSplitDecider()
 
 	// Init rangeStr with the range ID.
 	r.rangeStr.store(0, &roachpb.RangeDescriptor{RangeID: rangeID})
@@ -1773,6 +1814,14 @@ func (r *Replica) Send(
 	ctx, cleanup := tracing.EnsureContext(ctx, r.AmbientContext.Tracer, "replica send")
 	defer cleanup()
 
+	// Hold off requests while the range is frozen for a merge.
+	if pErr := r.beginMergeGuard(ctx, ba); pErr != nil {
+		return nil, pErr
+	}
+	defer r.endMergeGuard()
+
+	r.recordLoadForSplit(ctx, ba)
+
 	// If the internal Raft group is not initialized, create it and wake the leader.
 	r.maybeInitializeRaftGroup(ctx)
 
@@ -2403,10 +2452,14 @@ func (r *Replica) executeAdminBatch(
 func (r *Replica) executeReadOnlyBatch(
 	ctx context.Context, ba roachpb.BatchRequest,
 ) (br *roachpb.BatchResponse, pErr *roachpb.Error) {
-	// If the read is consistent, the read requires the range lease.
+	// If the read is consistent, the read requires the range lease, unless
+	// its timestamp has been closed and it can be served by a follower.
 	if ba.ReadConsistency != roachpb.INCONSISTENT {
 		if _, pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
-			return nil, pErr
+			if !r.canServeFollowerRead(ctx, ba, pErr) {
+				return nil, pErr
+			}
+			pErr = nil
 		}
 	}
 
@@ -2613,9 +2666,23 @@ func (r *Replica) tryExecuteWriteBatch(
 	// commands which require this command to move its timestamp
 	// forward. Or, in the case of a transactional write, the txn
 	// timestamp and possible write-too-old bool.
-	if bumped, pErr := r.applyTimestampCache(&ba); pErr != nil {
+	bumped, pErr := r.applyTimestampCache(&ba)
+	if pErr != nil {
 		return nil, pErr, proposalNoRetry
-	} else if bumped {
+	}
+
+	// The write must not be proposed at or below a timestamp this replica has
+	// closed, and must hold off further closing below its timestamp until it
+	// has been assigned a lease index. Lease requests are exempt since they
+	// do not write user data and are not subject to lease index ordering.
+	untrackWrite := func() {}
+	if !ba.IsLeaseRequest() {
+		var bumpedClosed bool
+		bumpedClosed, untrackWrite = r.trackWriteTimestamp(&ba)
+		bumped = bumped || bumpedClosed
+	}
+
+	if bumped {
 		// If we bump the transaction's timestamp, we must absolutely
 		// tell the client in a response transaction (for otherwise it
 		// doesn't know about the incremented timestamp). Response
@@ -2637,6 +2704,9 @@ func (r *Replica) tryExecuteWriteBatch(
 	log.Event(ctx, "applied timestamp cache")
 
 	ch, tryAbandon, undoQuotaAcquisition, pErr := r.propose(ctx, lease, ba, endCmds, spans)
+	// The proposal, if any, now has a lease index and any timestamp closed
+	// from here on only applies after it.
+	untrackWrite()
 	defer func() {
 		// NB: We may be double free-ing here, consider the following cases:
 		//  - The request was evaluated and the command resulted in an error, but a
@@ -2830,6 +2900,11 @@ func (r *Replica) insertProposalLocked(
 	proposal.command.MaxLeaseIndex = r.mu.lastAssignedLeaseIndex
 	proposal.command.ProposerReplica = proposerReplica
 	proposal.command.ProposerLease = proposerLease
+	if !proposal.Request.IsLeaseRequest() {
+		if target := r.closedTimestampTarget(r.store.Clock().Now()); target != (hlc.Timestamp{}) {
+			proposal.command.ClosedTimestamp = r.closedTSTracker.close(target)
+		}
+	}
 	if log.V(4) {
 		log.Infof(proposal.ctx, "submitting proposal %x: maxLeaseIndex=%d",
 			proposal.idKey, proposal.command.MaxLeaseIndex)
@@ -3097,7 +3172,7 @@ func defaultSubmitProposalLocked(r *Replica, p *ProposalData) error {
 			r.unquiesceLocked()
 			return false, /* !unquiesceAndWakeLeader */
 				raftGroup.ProposeConfChange(raftpb.ConfChange{
-					Type:    changeTypeInternalToRaft[crt.ChangeType],
+					Type:    confChangeType(&crt.ChangeReplicasTrigger),
 					NodeID:  uint64(crt.Replica.ReplicaID),
 					Context: encodedCtx,
 				})
@@ -4491,6 +4566,14 @@ func (r *Replica) processRaftCommand(
 		// before notifying a potentially waiting client.
 		r.handleEvalResultRaftMuLocked(ctx, lResult,
 			raftCmd.ReplicatedEvalResult, raftIndex, leaseIndex)
+
+		// Now that the command has applied, reads at or below the timestamp
+		// it closed will observe all writes they need to.
+		if pErr == nil {
+			r.mu.Lock()
+			r.mu.closedTimestamp.Forward(raftCmd.ClosedTimestamp)
+			r.mu.Unlock()
+		}
 	}
 
 	if proposedLocally {
@@ -5473,8 +5556,8 @@ func (r *Replica) setPendingSnapshotIndex(index uint64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	// We allow the pendingSnapshotIndex to change from 0 to 1 and then from 1 to
-	// a value greater than 1. Any other change indicates 2 current preemptive
-	// snapshots on the same replica which is disallowed.
+	// a value greater than 1. Any other change indicates 2 concurrent replica
+	// additions on the same replica which is disallowed.
 	if (index == 1 && r.mu.pendingSnapshotIndex != 0) ||
 		(index > 1 && r.mu.pendingSnapshotIndex != 1) {
 		return errors.Errorf(
@@ -5618,7 +5701,7 @@ func calcReplicaMetrics(
 	if m.RangeCounter {
 		var goodReplicas int
 		goodReplicas, m.BehindCount = calcGoodReplicas(raftStatus, desc, livenessMap)
-		if goodReplicas < computeQuorum(len(desc.Replicas)) {
+		if goodReplicas < computeQuorum(len(desc.Voters())) {
 			m.Unavailable = true
 		}
 		if zoneConfig, err := cfg.GetZoneConfigForKey(desc.StartKey); err != nil {
@@ -5637,7 +5720,8 @@ func calcReplicaMetrics(
 // calcGoodReplicas returns a count of the "good" replicas and a count of the
 // number of log entries the replicas are behind. The log entry count is only
 // returned if the local replica is the leader. A "good" replica must be on a
-// live node and, if there is a leader, not too far behind.
+// live node and, if there is a leader, not too far behind. Non-voting replicas
+// are not counted, as they do not contribute to the availability of the range.
 func calcGoodReplicas(
 	raftStatus *raft.Status, desc *roachpb.RangeDescriptor, livenessMap map[roachpb.NodeID]bool,
 ) (int, int64) {
@@ -5645,6 +5729,9 @@ func calcGoodReplicas(
 	var goodReplicas int
 	var behindCount int64
 	for _, rd := range desc.Replicas {
+		if !rd.IsVoter() {
+			continue
+		}
 		live := livenessMap[rd.NodeID]
 		if !leader {
 			if live {
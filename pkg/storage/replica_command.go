@@ -139,6 +139,7 @@ var commands = map[roachpb.Method]Command{
 	roachpb.RequestLease:       {DeclareKeys: declareKeysRequestLease, Eval: evalRequestLease},
 	roachpb.TransferLease:      {DeclareKeys: declareKeysRequestLease, Eval: evalTransferLease},
 	roachpb.LeaseInfo:          {DeclareKeys: declareKeysLeaseInfo, Eval: evalLeaseInfo},
+	roachpb.RangeStats:         {DeclareKeys: declareKeysRangeStats, Eval: evalRangeStats},
 	roachpb.ComputeChecksum:    {DeclareKeys: DefaultDeclareKeys, Eval: evalComputeChecksum},
 	roachpb.WriteBatch:         writeBatchCmd,
 	roachpb.Export:             exportCmd,
@@ -2200,7 +2201,8 @@ func evalNewLease(
 	if err != nil {
 		return EvalResult{}, err
 	}
-	if _, ok := desc.GetReplicaDescriptor(lease.Replica.StoreID); !ok {
+	repDesc, ok := desc.GetReplicaDescriptor(lease.Replica.StoreID)
+	if !ok {
 		return newFailedLeaseTrigger(isTransfer),
 			&roachpb.LeaseRejectedError{
 				Existing:  prevLease,
@@ -2208,6 +2210,16 @@ func evalNewLease(
 				Message:   "replica not found",
 			}
 	}
+	// Non-voting replicas may not hold the lease, as they are not guaranteed
+	// to have applied all committed commands.
+	if !repDesc.IsVoter() {
+		return newFailedLeaseTrigger(isTransfer),
+			&roachpb.LeaseRejectedError{
+				Existing:  prevLease,
+				Requested: lease,
+				Message:   "replica is not a voter",
+			}
+	}
 
 	// Store the lease to disk & in-memory.
 	if err := rec.makeReplicaStateLoader().setLease(ctx, batch, ms, lease); err != nil {
@@ -3282,7 +3294,9 @@ func splitTrigger(
 // reassigned key range is carried out seamlessly through a merge
 // trigger carried out as part of the commit of that transaction.  A
 // merge requires that the two ranges are collocated on the same set
-// of replicas.
+// of replicas, and that this store holds the lease of the right hand
+// side as well, since that range is frozen for the duration of the
+// merge.
 //
 // The supplied RangeDescriptor is used as a form of optimistic lock. See the
 // comment of "AdminSplit" for more information on this pattern.
@@ -3306,15 +3320,18 @@ func (r *Replica) AdminMerge(
 	// descriptor end key. We look up the descriptor here only to get
 	// the new end key and then repeat the lookup inside the
 	// transaction.
-	{
-		rightRng := r.store.LookupReplica(origLeftDesc.EndKey, nil)
-		if rightRng == nil {
-			return reply, roachpb.NewErrorf("ranges not collocated")
-		}
-
-		updatedLeftDesc.EndKey = rightRng.Desc().EndKey
-		log.Infof(ctx, "initiating a merge of %s into this range", rightRng)
+	rightRng := r.store.LookupReplica(origLeftDesc.EndKey, nil)
+	if rightRng == nil {
+		return reply, roachpb.NewErrorf("ranges not collocated")
 	}
+	// The right hand side is frozen through its lease, which must therefore be
+	// held by this store as well.
+	if !rightRng.OwnsValidLease(r.store.Clock().Now()) {
+		return reply, roachpb.NewErrorf("range leases not collocated")
+	}
+	updatedLeftDesc.EndKey = rightRng.Desc().EndKey
+	log.Infof(ctx, "initiating a merge of %s into this range", rightRng)
+	defer rightRng.unfreezeAfterMerge()
 
 	if err := r.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 		log.Event(ctx, "merge closure begins")
@@ -3334,6 +3351,12 @@ func (r *Replica) AdminMerge(
 			}
 		}
 
+		// Block all requests to the right hand side other than those of this
+		// transaction until the merge has either committed or aborted.
+		if err := rightRng.freezeForMerge(ctx, txn.ID()); err != nil {
+			return err
+		}
+
 		// Do a consistent read of the right hand side's range descriptor.
 		rightDescKey := keys.RangeDescriptorKey(origLeftDesc.EndKey)
 		var rightDesc roachpb.RangeDescriptor
@@ -3363,8 +3386,20 @@ func (r *Replica) AdminMerge(
 		if err := mergeRangeAddressing(b, origLeftDesc, &updatedLeftDesc); err != nil {
 			return err
 		}
+		if err := txn.Run(ctx, b); err != nil {
+			return err
+		}
+
+		// Make sure that every replica of the right hand side has applied all
+		// of its writes, including the ones above, before the left hand side
+		// takes over its keyspace.
+		if err := rightRng.sealForMerge(ctx); err != nil {
+			return err
+		}
+
 		// End the transaction manually instead of letting RunTransaction
 		// loop do it, in order to provide a merge trigger.
+		b = txn.NewBatch()
 		b.AddRawRequest(&roachpb.EndTransactionRequest{
 			Commit: true,
 			InternalCommitTrigger: &roachpb.InternalCommitTrigger{
@@ -3537,8 +3572,7 @@ func (s *snapshotError) Error() string {
 	return fmt.Sprintf("snapshot failed: %s", s.cause.Error())
 }
 
-// IsSnapshotError returns true iff the error indicates a preemptive
-// snapshot failed.
+// IsSnapshotError returns true iff the error indicates a snapshot failed.
 func IsSnapshotError(err error) bool {
 	_, ok := err.(*snapshotError)
 	return ok
@@ -3592,32 +3626,24 @@ func IsSnapshotError(err error) bool {
 // state.
 //
 // When a new replica is added, it will have to catch up to the state of the
-// other replicas. The Raft leader automatically handles this by either sending
-// the new replica Raft log entries to apply, or by generating and sending a
-// snapshot. See Replica.Snapshot and Replica.Entries.
-//
-// Note that Replica.ChangeReplicas returns when the distributed transaction
-// has been committed to a quorum of replicas in the range. The actual
-// replication of data occurs asynchronously via a snapshot or application of
-// Raft log entries. This is important for the replicate queue to be aware
-// of. A node can process hundreds or thousands of ChangeReplicas operations
-// per second even though the actual replication of data proceeds at a much
-// slower base. In order to avoid having this background replication overwhelm
-// the system, replication is throttled via a reservation system. When
-// allocating a new replica for a range, the replicate queue reserves space for
-// that replica on the target store via a ReservationRequest. (See
-// StorePool.reserve). The reservation is fulfilled when the snapshot is
-// applied.
+// other replicas. To avoid adding a voter which cannot yet participate in the
+// quorum, a replica is first added as a Raft learner by a change of the kind
+// described above. The replica adding it then sends the learner a Raft
+// snapshot, after which a second change promotes the learner to a voter. See
+// Replica.addReplica. Learners do not count toward the quorum, so the range
+// stays available even if the addition stalls or the process dies midway; an
+// abandoned learner is removed by the replicate queue.
 //
-// TODO(peter): There is a rare scenario in which a replica can be brought up
-// to date via Raft log replay. In this scenario, the reservation will be left
-// dangling until it expires. See #7849.
+// Note that Replica.ChangeReplicas returns when the new replica has been
+// caught up via a snapshot and promoted. Snapshots are throttled via a
+// reservation system: a store can decline the snapshot for a learner if it is
+// busy applying other snapshots, in which case the learner is removed again
+// and the replicate queue retries the addition later.
 //
-// TODO(peter): Describe preemptive snapshots. Preemptive snapshots are needed
-// for the replicate queue to function properly. Currently the replicate queue
-// will fire off as many replica additions as possible until it starts getting
-// reservations denied at which point it will ignore the replica until the next
-// scanner cycle.
+// A change of type ADD_NON_VOTER adds a non-voting replica, which receives the
+// Raft log as a Raft learner but never participates in the quorum. It is
+// subject to the same checks as the addition of a voter, and is removed like
+// any other replica via REMOVE_REPLICA.
 func (r *Replica) ChangeReplicas(
 	ctx context.Context,
 	changeType roachpb.ReplicaChangeType,
@@ -3626,7 +3652,9 @@ func (r *Replica) ChangeReplicas(
 	reason RangeLogEventReason,
 	details string,
 ) error {
-	return r.changeReplicas(ctx, changeType, target, desc, SnapshotRequest_REBALANCE, reason, details)
+	return r.changeReplicas(
+		ctx, changeType, target, desc, SnapshotRequest_REBALANCE, reason, details,
+	)
 }
 
 func (r *Replica) changeReplicas(
@@ -3651,16 +3679,16 @@ func (r *Replica) changeReplicas(
 		if nodeUsedByExistingRep && existingRep.StoreID == repDesc.StoreID {
 			repDescIdx = i
 			repDesc.ReplicaID = existingRep.ReplicaID
+			repDesc.Type = existingRep.Type
 			break
 		}
 	}
 
-	rangeID := desc.RangeID
 	updatedDesc := *desc
 	updatedDesc.Replicas = append([]roachpb.ReplicaDescriptor(nil), desc.Replicas...)
 
 	switch changeType {
-	case roachpb.ADD_REPLICA:
+	case roachpb.ADD_REPLICA, roachpb.ADD_NON_VOTER:
 		// If the replica exists on the remote node, no matter in which store,
 		// abort the replica add.
 		if nodeUsed {
@@ -3670,44 +3698,15 @@ func (r *Replica) changeReplicas(
 			return errors.Errorf("%s: unable to add replica %v; node already has a replica", r, repDesc)
 		}
 
-		// Prohibit premature raft log truncation. We set the pending index to 1
-		// here until we determine what it is below. This removes a small window of
-		// opportunity for the raft log to get truncated after the snapshot is
-		// generated.
-		if err := r.setPendingSnapshotIndex(1); err != nil {
-			return err
-		}
-		defer r.clearPendingSnapshotIndex()
-
-		// Send a pre-emptive snapshot. Note that the replica to which this
-		// snapshot is addressed has not yet had its replica ID initialized; this
-		// is intentional, and serves to avoid the following race with the replica
-		// GC queue:
-		//
-		// - snapshot received, a replica is lazily created with the "real" replica ID
-		// - the replica is eligible for GC because it is not yet a member of the range
-		// - GC queue runs, creating a raft tombstone with the replica's ID
-		// - the replica is added to the range
-		// - lazy creation of the replica fails due to the raft tombstone
-		//
-		// Instead, the replica GC queue will create a tombstone with replica ID
-		// zero, which is never legitimately used, and thus never interferes with
-		// raft operations. Racing with the replica GC queue can still partially
-		// negate the benefits of pre-emptive snapshots, but that is a recoverable
-		// degradation, not a catastrophic failure.
-		//
-		// NB: A closure is used here so that we can release the snapshot as soon
-		// as it has been applied on the remote and before the ChangeReplica
-		// operation is processed. This is important to allow other ranges to make
-		// progress which might be required for this ChangeReplicas operation to
-		// complete. See #10409.
-		if err := r.sendSnapshot(ctx, repDesc, snapTypePreemptive, priority); err != nil {
-			return err
+		replicaType := roachpb.VOTER
+		if changeType == roachpb.ADD_NON_VOTER {
+			if !r.store.cfg.Settings.Version.IsActive(cluster.VersionNonVoterReplicas) {
+				return errors.Errorf("%s: cannot add non-voting replica %v until the cluster version is at least %s",
+					r, repDesc, cluster.VersionByKey(cluster.VersionNonVoterReplicas))
+			}
+			replicaType = roachpb.NON_VOTER
 		}
-
-		repDesc.ReplicaID = updatedDesc.NextReplicaID
-		updatedDesc.NextReplicaID++
-		updatedDesc.Replicas = append(updatedDesc.Replicas, repDesc)
+		return r.addReplica(ctx, repDesc, replicaType, desc, priority, reason, details)
 
 	case roachpb.REMOVE_REPLICA:
 		// If that exact node-store combination does not have the replica,
@@ -3719,6 +3718,227 @@ func (r *Replica) changeReplicas(
 		updatedDesc.Replicas = updatedDesc.Replicas[:len(updatedDesc.Replicas)-1]
 	}
 
+	return r.execChangeReplicasTxn(
+		ctx, desc, changeType, repDesc, updatedDesc, reason, details, true, /* logChange */
+	)
+}
+
+// addReplica adds a replica of the given type to the range. The replica is
+// first added as a Raft learner, which does not count toward the quorum of
+// the range, and is then caught up with a Raft snapshot. Voters are promoted
+// in a second replica change once the snapshot has been applied, so that a
+// voter which has yet to receive any data never becomes part of the quorum.
+//
+// If the snapshot fails, the learner is removed again. Should that fail as
+// well (or should the process die midway), the replicate queue removes the
+// abandoned learner.
+//
+// Until VersionLearnerReplicas is active, nodes running older binaries may
+// not know about learners, and the replica is added with a preemptive
+// snapshot instead. See addReplicaWithPreemptiveSnapshot.
+func (r *Replica) addReplica(
+	ctx context.Context,
+	repDesc roachpb.ReplicaDescriptor,
+	replicaType roachpb.ReplicaType,
+	desc *roachpb.RangeDescriptor,
+	priority SnapshotRequest_Priority,
+	reason RangeLogEventReason,
+	details string,
+) error {
+	// Prohibit premature raft log truncation until the new replica has been
+	// caught up. The pending index of 1 holds back truncation entirely, which
+	// is conservative but simple: the addition is short-lived.
+	if err := r.setPendingSnapshotIndex(1); err != nil {
+		return err
+	}
+	defer r.clearPendingSnapshotIndex()
+
+	if !r.store.cfg.Settings.Version.IsActive(cluster.VersionLearnerReplicas) {
+		return r.addReplicaWithPreemptiveSnapshot(
+			ctx, repDesc, replicaType, desc, priority, reason, details,
+		)
+	}
+
+	// Voters are added as learners and promoted below. Non-voting replicas
+	// remain Raft learners, so their addition is complete once they have
+	// been caught up.
+	isVoter := replicaType == roachpb.VOTER
+	learnerType := replicaType
+	if isVoter {
+		learnerType = roachpb.LEARNER
+	}
+	repDesc.ReplicaID = desc.NextReplicaID
+	repDesc.Type = learnerType.Enum()
+	if isVoter {
+		r.trackInFlightLearner(repDesc.ReplicaID)
+		defer r.untrackInFlightLearner(repDesc.ReplicaID)
+	}
+	learnerDesc := *desc
+	learnerDesc.Replicas = append(append([]roachpb.ReplicaDescriptor(nil), desc.Replicas...), repDesc)
+	learnerDesc.NextReplicaID++
+	// The range log only records the replica once it has reached its final
+	// type.
+	if err := r.execChangeReplicasTxn(
+		ctx, desc, roachpb.ADD_REPLICA, repDesc, learnerDesc, reason, details, !isVoter, /* logChange */
+	); err != nil {
+		return err
+	}
+
+	// The learner is a member of the Raft group, so the snapshot is addressed
+	// to its replica ID and applied like any other Raft snapshot. Raft expects
+	// us to report the snapshot status once we finish sending it.
+	err := r.sendSnapshot(ctx, repDesc, snapTypeRaft, priority)
+	r.reportSnapshotStatus(uint64(repDesc.ReplicaID), err)
+	if err != nil {
+		rollbackDesc := learnerDesc
+		rollbackDesc.Replicas = desc.Replicas
+		if rollbackErr := r.execChangeReplicasTxn(
+			ctx, &learnerDesc, roachpb.REMOVE_REPLICA, repDesc, rollbackDesc,
+			reason, details, !isVoter, /* logChange */
+		); rollbackErr != nil {
+			log.Warningf(ctx, "unable to remove learner %s after failed snapshot: %s",
+				repDesc, rollbackErr)
+		}
+		return err
+	}
+	if !isVoter {
+		return nil
+	}
+
+	// Promote the learner. The promotion is a single Raft configuration
+	// change which keeps the replica ID of the learner.
+	voterDesc := learnerDesc
+	voterDesc.Replicas = append([]roachpb.ReplicaDescriptor(nil), learnerDesc.Replicas...)
+	repDesc.Type = nil
+	voterDesc.Replicas[len(voterDesc.Replicas)-1] = repDesc
+	return r.execChangeReplicasTxn(
+		ctx, &learnerDesc, roachpb.ADD_REPLICA, repDesc, voterDesc, reason, details, true, /* logChange */
+	)
+}
+
+// addReplicaWithPreemptiveSnapshot adds a replica of the given type to the
+// range by sending it a preemptive snapshot, and then adding it to the range
+// descriptor in a single replica change. The caller must prohibit premature
+// raft log truncation.
+//
+// TODO: delete this, and the preemptive snapshots it relies on, once
+// VersionLearnerReplicas is the minimum supported version.
+func (r *Replica) addReplicaWithPreemptiveSnapshot(
+	ctx context.Context,
+	repDesc roachpb.ReplicaDescriptor,
+	replicaType roachpb.ReplicaType,
+	desc *roachpb.RangeDescriptor,
+	priority SnapshotRequest_Priority,
+	reason RangeLogEventReason,
+	details string,
+) error {
+	// Send a pre-emptive snapshot. Note that the replica to which this
+	// snapshot is addressed has not yet had its replica ID initialized; this
+	// is intentional, and serves to avoid the following race with the replica
+	// GC queue:
+	//
+	// - snapshot received, a replica is lazily created with the "real" replica ID
+	// - the replica is eligible for GC because it is not yet a member of the range
+	// - GC queue runs, creating a raft tombstone with the replica's ID
+	// - the replica is added to the range
+	// - lazy creation of the replica fails due to the raft tombstone
+	//
+	// Instead, the replica GC queue will create a tombstone with replica ID
+	// zero, which is never legitimately used, and thus never interferes with
+	// raft operations. Racing with the replica GC queue can still partially
+	// negate the benefits of pre-emptive snapshots, but that is a recoverable
+	// degradation, not a catastrophic failure.
+	//
+	// NB: A closure is used here so that we can release the snapshot as soon
+	// as it has been applied on the remote and before the ChangeReplica
+	// operation is processed. This is important to allow other ranges to make
+	// progress which might be required for this ChangeReplicas operation to
+	// complete. See #10409.
+	if err := r.sendSnapshot(ctx, repDesc, snapTypePreemptive, priority); err != nil {
+		return err
+	}
+
+	// Voters are left without an explicit type so that the encoding of
+	// their descriptors is unchanged.
+	if replicaType != roachpb.VOTER {
+		repDesc.Type = replicaType.Enum()
+	}
+	updatedDesc := *desc
+	updatedDesc.Replicas = append([]roachpb.ReplicaDescriptor(nil), desc.Replicas...)
+	repDesc.ReplicaID = updatedDesc.NextReplicaID
+	updatedDesc.NextReplicaID++
+	updatedDesc.Replicas = append(updatedDesc.Replicas, repDesc)
+	return r.execChangeReplicasTxn(
+		ctx, desc, roachpb.ADD_REPLICA, repDesc, updatedDesc, reason, details, true, /* logChange */
+	)
+}
+
+// abandonedLearnerAge is the time after which a learner which another
+// replica added is considered left behind by a failed replica addition. It
+// comfortably exceeds the time needed to send a snapshot of a range of the
+// maximum size.
+const abandonedLearnerAge = 10 * time.Minute
+
+func (r *Replica) trackInFlightLearner(replicaID roachpb.ReplicaID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mu.inFlightLearners == nil {
+		r.mu.inFlightLearners = map[roachpb.ReplicaID]struct{}{}
+	}
+	r.mu.inFlightLearners[replicaID] = struct{}{}
+}
+
+func (r *Replica) untrackInFlightLearner(replicaID roachpb.ReplicaID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.mu.inFlightLearners, replicaID)
+}
+
+// abandonedLearners returns the learners of the range which are no longer
+// being caught up by a replica addition. Learners which this replica is
+// adding are in flight. Learners added elsewhere, for instance by a replica
+// which has since lost the lease, are only considered abandoned once they
+// have been observed here for abandonedLearnerAge, so that concurrent
+// additions run by other replicas are not interrupted.
+func (r *Replica) abandonedLearners(
+	desc *roachpb.RangeDescriptor, now time.Time,
+) []roachpb.ReplicaDescriptor {
+	learners := desc.Learners()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := make(map[roachpb.ReplicaID]time.Time, len(learners))
+	var abandoned []roachpb.ReplicaDescriptor
+	for _, learner := range learners {
+		if _, ok := r.mu.inFlightLearners[learner.ReplicaID]; ok {
+			continue
+		}
+		firstSeen, ok := r.mu.learnersSeen[learner.ReplicaID]
+		if !ok {
+			firstSeen = now
+		}
+		seen[learner.ReplicaID] = firstSeen
+		if now.Sub(firstSeen) >= abandonedLearnerAge {
+			abandoned = append(abandoned, learner)
+		}
+	}
+	r.mu.learnersSeen = seen
+	return abandoned
+}
+
+// execChangeReplicasTxn runs the transaction which replaces the range
+// descriptor desc by updatedDesc, performing the supplied replica change. The
+// change is recorded in the range log if logChange is set.
+func (r *Replica) execChangeReplicasTxn(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	changeType roachpb.ReplicaChangeType,
+	repDesc roachpb.ReplicaDescriptor,
+	updatedDesc roachpb.RangeDescriptor,
+	reason RangeLogEventReason,
+	details string,
+	logChange bool,
+) error {
+	rangeID := desc.RangeID
 	descKey := keys.RangeDescriptorKey(desc.StartKey)
 
 	if err := r.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
@@ -3752,10 +3972,12 @@ func (r *Replica) changeReplicas(
 		}
 
 		// Log replica change into range event log.
-		if err := r.store.logChange(
-			ctx, txn, changeType, repDesc, updatedDesc, reason, details,
-		); err != nil {
-			return err
+		if logChange {
+			if err := r.store.logChange(
+				ctx, txn, changeType, repDesc, updatedDesc, reason, details,
+			); err != nil {
+				return err
+			}
 		}
 
 		// End the transaction manually instead of letting RunTransaction
@@ -3802,18 +4024,25 @@ func (r *Replica) changeReplicas(
 }
 
 // sendSnapshot sends a snapshot of the replica state to the specified
-// replica. This is used for both preemptive snapshots that are performed
-// before adding a replica to a range, and for Raft-initiated snapshots that
-// are used to bring a replica up to date that has fallen too far
-// behind. Currently only invoked from replicateQueue and raftSnapshotQueue. Be
-// careful about adding additional calls as generating a snapshot is moderately
-// expensive.
+// replica. This is used both to catch up learners that are being added to a
+// range, and for Raft-initiated snapshots that are used to bring a replica up
+// to date that has fallen too far behind. Currently only invoked from
+// addReplica and raftSnapshotQueue. Be careful about adding additional calls
+// as generating a snapshot is moderately expensive.
 func (r *Replica) sendSnapshot(
 	ctx context.Context,
 	repDesc roachpb.ReplicaDescriptor,
 	snapType string,
 	priority SnapshotRequest_Priority,
 ) error {
+	// Wait for a free slot before generating the snapshot so that queued
+	// snapshots do not hold on to engine snapshots.
+	release, err := r.store.acquireSnapshotSendSem(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	snap, err := r.GetSnapshot(ctx, snapType)
 	if err != nil {
 		return errors.Wrapf(err, "%s: failed to generate %s snapshot", r, snapType)
@@ -3826,12 +4055,6 @@ func (r *Replica) sendSnapshot(
 		return errors.Wrapf(err, "%s: change replicas failed", r)
 	}
 
-	if snapType == snapTypePreemptive {
-		if err := r.setPendingSnapshotIndex(snap.RaftSnap.Metadata.Index); err != nil {
-			return err
-		}
-	}
-
 	status := r.RaftStatus()
 	if status == nil {
 		return errors.New("raft status not initialized")
@@ -3852,15 +4075,17 @@ func (r *Replica) sendSnapshot(
 			},
 		},
 		RangeSize: r.GetMVCCStats().Total(),
-		// Recipients can choose to decline preemptive snapshots.
-		CanDecline: snapType == snapTypePreemptive,
+		// Recipients can choose to decline snapshots for replicas which do
+		// not vote, as the range does not depend on them to make progress.
+		CanDecline: !repDesc.IsVoter(),
 		Priority:   priority,
 	}
 	sent := func() {
 		r.store.metrics.RangeSnapshotsGenerated.Inc(1)
 	}
 	if err := r.store.cfg.Transport.SendSnapshot(
-		ctx, r.store.allocator.storePool, req, snap, r.store.Engine().NewBatch, sent); err != nil {
+		ctx, r.store.allocator.storePool, req, snap, r.store.Engine().NewBatch, r.store.metrics, sent,
+	); err != nil {
 		return &snapshotError{err}
 	}
 	return nil
@@ -3941,6 +4166,25 @@ func declareKeysLeaseInfo(
 	spans.Add(SpanReadOnly, roachpb.Span{Key: keys.RangeLeaseKey(header.RangeID)})
 }
 
+func declareKeysRangeStats(
+	_ roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *SpanSet,
+) {
+	spans.Add(SpanReadOnly, roachpb.Span{Key: keys.RangeStatsKey(header.RangeID)})
+}
+
+// RangeStats returns the MVCC statistics for the range.
+func evalRangeStats(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (EvalResult, error) {
+	reply := resp.(*roachpb.RangeStatsResponse)
+	ms, err := cArgs.EvalCtx.GetMVCCStats()
+	if err != nil {
+		return EvalResult{}, err
+	}
+	reply.MVCCStats = ms
+	return EvalResult{}, nil
+}
+
 // LeaseInfo returns information about the lease holder for the range.
 func evalLeaseInfo(
 	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
@@ -3970,6 +4214,17 @@ func TestingRelocateRange(
 	db *client.DB,
 	rangeDesc roachpb.RangeDescriptor,
 	targets []roachpb.ReplicationTarget,
+) error {
+	return relocateRange(ctx, db, rangeDesc, targets)
+}
+
+// relocateRange implements TestingRelocateRange. It is also used by the merge
+// queue to collocate the replicas of adjacent ranges, which retries on error.
+func relocateRange(
+	ctx context.Context,
+	db *client.DB,
+	rangeDesc roachpb.RangeDescriptor,
+	targets []roachpb.ReplicationTarget,
 ) error {
 	// Step 1: Add any stores that don't already have a replica in of the range.
 	//
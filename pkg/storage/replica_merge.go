@@ -0,0 +1,388 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// mergeFreezeTimeout bounds how long freezeForMerge and sealForMerge wait for
+// in-flight requests to finish and for the followers to catch up. Requests to
+// the range are blocked in the meantime, so the merge is abandoned rather than
+// stalling the range for long.
+const mergeFreezeTimeout = 5 * time.Second
+
+// beginMergeGuard blocks the request while the range is frozen for a merge,
+// unless it belongs to the merge transaction itself. Until the range is
+// sealed, PushTxn and intent resolution requests are let through as well: the
+// merge transaction may have to push a transaction anchored on this range, or
+// to have intents on it resolved, to make progress. Lease requests are let
+// through so that the leaseholder can keep its lease, but lease transfers are
+// refused: the freeze is held by the leaseholder, and the merge requires the
+// lease to stay where it is. Once the freeze is lifted, requests to a range
+// which was merged away are rejected with a RangeNotFoundError so that they
+// are retried against the subsuming range. Every successful call must be
+// paired with a call to endMergeGuard.
+func (r *Replica) beginMergeGuard(ctx context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+	r.mergeMu.Lock()
+	for {
+		frozen := r.mergeMu.frozen
+		if frozen != nil && isLeaseTransferBatch(ba) {
+			r.mergeMu.Unlock()
+			return roachpb.NewErrorf("%s: cannot transfer the lease of a range frozen for merge", r)
+		}
+		if frozen == nil || (ba.Txn != nil && ba.Txn.ID == r.mergeMu.txnID) ||
+			(!r.mergeMu.sealed && isPushOrResolveBatch(ba)) || isLeaseRequestBatch(ba) {
+			r.mergeMu.inFlight++
+			r.mergeMu.Unlock()
+			return nil
+		}
+		r.mergeMu.Unlock()
+
+		log.Event(ctx, "waiting for merge to complete")
+		select {
+		case <-frozen:
+		case <-ctx.Done():
+			return roachpb.NewError(errors.Wrap(ctx.Err(), "aborted while waiting for merge"))
+		}
+		if r.IsDestroyed() != nil {
+			return roachpb.NewError(roachpb.NewRangeNotFoundError(r.RangeID))
+		}
+		r.mergeMu.Lock()
+	}
+}
+
+// isPushOrResolveBatch returns whether the batch only pushes transactions or
+// resolves intents.
+func isPushOrResolveBatch(ba roachpb.BatchRequest) bool {
+	for _, union := range ba.Requests {
+		switch union.GetInner().(type) {
+		case *roachpb.PushTxnRequest, *roachpb.ResolveIntentRequest, *roachpb.ResolveIntentRangeRequest:
+		default:
+			return false
+		}
+	}
+	return len(ba.Requests) > 0
+}
+
+// isLeaseRequestBatch returns whether the batch only requests the range lease.
+func isLeaseRequestBatch(ba roachpb.BatchRequest) bool {
+	if ba.IsSingleRequest() {
+		_, ok := ba.Requests[0].GetInner().(*roachpb.RequestLeaseRequest)
+		return ok
+	}
+	return false
+}
+
+// isLeaseTransferBatch returns whether the batch transfers the range lease.
+func isLeaseTransferBatch(ba roachpb.BatchRequest) bool {
+	for _, union := range ba.Requests {
+		switch union.GetInner().(type) {
+		case *roachpb.TransferLeaseRequest, *roachpb.AdminTransferLeaseRequest:
+			return true
+		}
+	}
+	return false
+}
+
+// endMergeGuard marks the end of a request admitted by beginMergeGuard.
+func (r *Replica) endMergeGuard() {
+	r.mergeMu.Lock()
+	r.mergeMu.inFlight--
+	r.mergeMu.Unlock()
+}
+
+// freezeForMerge prepares this range, the right-hand side of a merge, to be
+// subsumed by the merge transaction with the given ID. It blocks all other
+// requests except pushes and intent resolution, and waits for the requests in
+// flight to finish. The replica must hold the range lease, which cannot be
+// transferred away until the freeze is lifted.
+//
+// The freeze only lives in the memory of the leaseholder. If the lease is
+// nevertheless acquired by another replica, for instance because this node
+// failed to heartbeat its liveness record, the new leaseholder freezes the
+// range again on finding the intent the merge transaction left on the range
+// descriptor (see maybeWatchForMerge), and sealForMerge fails here.
+//
+// freezeForMerge may be called again with a new transaction ID if the merge
+// transaction restarts. Before the merge commits, sealForMerge must be called
+// to make sure that the followers have applied every write to the range. The
+// freeze is lifted by unfreezeAfterMerge.
+func (r *Replica) freezeForMerge(ctx context.Context, txnID uuid.UUID) error {
+	lease, nextLease := r.getLease()
+	r.mergeMu.Lock()
+	alreadyFrozen := r.mergeMu.frozen != nil
+	if !alreadyFrozen {
+		r.mergeMu.frozen = make(chan struct{})
+		r.mergeMu.lease = lease
+	}
+	r.mergeMu.txnID = txnID
+	r.mergeMu.sealed = false
+	r.mergeMu.Unlock()
+	if alreadyFrozen {
+		return nil
+	}
+
+	if nextLease != nil || !r.OwnsValidLease(r.store.Clock().Now()) {
+		return errors.Errorf("%s: cannot freeze range for merge without holding its lease", r)
+	}
+	if err := r.waitForMergeInFlight(ctx); err != nil {
+		return errors.Wrapf(err, "%s: freezing range for merge", r)
+	}
+	log.Event(ctx, "range frozen for merge")
+	return nil
+}
+
+// sealForMerge is called by the merge transaction right before it commits. It
+// stops admitting pushes and intent resolution, waits for the requests in
+// flight to finish, and then waits for every follower to have applied the
+// commands proposed so far. Once the merge trigger applies, the left-hand side
+// takes over the keyspace of this range on every store, so a follower which
+// had not applied all of the writes to this range would diverge.
+//
+// Writes to this range are only blocked for as long as the lease under which
+// it was frozen is held, so sealForMerge fails if that lease was lost in the
+// meantime.
+func (r *Replica) sealForMerge(ctx context.Context) error {
+	r.mergeMu.Lock()
+	if r.mergeMu.frozen == nil {
+		r.mergeMu.Unlock()
+		return errors.Errorf("%s: cannot seal a range which is not frozen for merge", r)
+	}
+	r.mergeMu.sealed = true
+	frozenLease := r.mergeMu.lease
+	r.mergeMu.Unlock()
+
+	if err := r.waitForMergeInFlight(ctx); err != nil {
+		return errors.Wrapf(err, "%s: sealing range for merge", r)
+	}
+
+	r.mu.RLock()
+	leaseIndex := r.mu.state.LeaseAppliedIndex
+	r.mu.RUnlock()
+	localReplica, err := r.GetReplicaDescriptor()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, mergeFreezeTimeout)
+	defer cancel()
+	log.Eventf(ctx, "waiting for followers to apply lease index %d", leaseIndex)
+	replicas := r.Desc().Replicas
+	errCh := make(chan error, len(replicas))
+	var waiting int
+	for _, replica := range replicas {
+		if replica == localReplica {
+			continue
+		}
+		replica := replica // per-iteration copy
+		if err := r.store.Stopper().RunAsyncTask(ctx, "storage.Replica: waiting for application",
+			func(ctx context.Context) {
+				errCh <- r.waitForFollowerApplication(ctx, replica, leaseIndex)
+			}); err != nil {
+			return err
+		}
+		waiting++
+	}
+	for ; waiting > 0; waiting-- {
+		if e := <-errCh; e != nil && err == nil {
+			err = e
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Check the lease only now, as the commands the followers were waited on
+	// must all have been proposed under the lease the range was frozen with.
+	if lease, nextLease := r.getLease(); nextLease != nil || !lease.Equivalent(frozenLease) ||
+		!r.OwnsValidLease(r.store.Clock().Now()) {
+		return errors.Errorf("%s: lost the lease %s while frozen for merge", r, frozenLease)
+	}
+	return nil
+}
+
+// waitForFollowerApplication waits until the given follower has applied the
+// command with the given lease index.
+func (r *Replica) waitForFollowerApplication(
+	ctx context.Context, replica roachpb.ReplicaDescriptor, leaseIndex uint64,
+) error {
+	addr, err := r.store.cfg.Transport.resolver(replica.NodeID)
+	if err != nil {
+		return errors.Wrapf(err, "could not resolve node ID %d", replica.NodeID)
+	}
+	conn, err := r.store.cfg.Transport.rpcContext.GRPCDial(addr.String())
+	if err != nil {
+		return errors.Wrapf(err, "could not dial node ID %d address %s", replica.NodeID, addr)
+	}
+	client := NewConsistencyClient(conn)
+	req := &WaitForApplicationRequest{
+		StoreRequestHeader: StoreRequestHeader{NodeID: replica.NodeID, StoreID: replica.StoreID},
+		RangeID:            r.RangeID,
+		LeaseIndex:         leaseIndex,
+	}
+	if _, err := client.WaitForApplication(ctx, req); err != nil {
+		return errors.Wrapf(err, "replica %s did not apply lease index %d", replica, leaseIndex)
+	}
+	return nil
+}
+
+// waitForMergeInFlight waits until no request admitted by beginMergeGuard is
+// in flight.
+func (r *Replica) waitForMergeInFlight(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, mergeFreezeTimeout)
+	defer cancel()
+	opts := retry.Options{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		Multiplier:     2,
+		Closer:         r.store.stopper.ShouldQuiesce(),
+	}
+	for re := retry.StartWithCtx(ctx, opts); re.Next(); {
+		r.mergeMu.Lock()
+		inFlight := r.mergeMu.inFlight
+		r.mergeMu.Unlock()
+		if inFlight == 0 {
+			return nil
+		}
+		if log.V(2) {
+			log.Infof(ctx, "waiting for %d requests in flight", inFlight)
+		}
+	}
+	return errors.New("timed out waiting for requests in flight")
+}
+
+// unfreezeAfterMerge lifts a freeze set up by freezeForMerge, unblocking the
+// waiting requests. If the merge committed, the replica has been destroyed by
+// then and the waiting requests are redirected to the subsuming range.
+func (r *Replica) unfreezeAfterMerge() {
+	r.mergeMu.Lock()
+	defer r.mergeMu.Unlock()
+	if r.mergeMu.frozen != nil {
+		close(r.mergeMu.frozen)
+		r.mergeMu.frozen = nil
+		r.mergeMu.txnID = uuid.UUID{}
+		r.mergeMu.sealed = false
+		r.mergeMu.lease = roachpb.Lease{}
+	}
+}
+
+// maybeWatchForMerge is called when this replica acquires the range lease
+// from another store. A merge transaction deletes the descriptor of the range
+// it subsumes, so an intent deleting the range descriptor means that the
+// previous leaseholder may still be merging this range. In that case the range
+// is frozen again, so that no request is served by this replica before the
+// merge transaction is known to have aborted. If it committed instead, the
+// freeze is held until the merge trigger has destroyed this replica.
+func (r *Replica) maybeWatchForMerge(ctx context.Context) {
+	eng := r.store.Engine()
+	descKey := keys.RangeDescriptorKey(r.Desc().StartKey)
+	_, intents, err := engine.MVCCGet(
+		ctx, eng, descKey, hlc.MaxTimestamp, false /* consistent */, nil, /* txn */
+	)
+	if err != nil {
+		log.Fatalf(ctx, "unable to read the range descriptor: %s", err)
+	}
+	if len(intents) == 0 {
+		return
+	}
+	intent := intents[0]
+	// Read the intent's value as its own transaction would to tell a deletion
+	// apart from an update of the descriptor by a split or replica change.
+	val, _, err := engine.MVCCGet(
+		ctx, eng, descKey, intent.Txn.Timestamp, true, /* consistent */
+		&roachpb.Transaction{TxnMeta: intent.Txn},
+	)
+	if err != nil {
+		log.Fatalf(ctx, "unable to read the range descriptor: %s", err)
+	}
+	if val != nil {
+		return
+	}
+
+	r.mergeMu.Lock()
+	if r.mergeMu.frozen == nil {
+		r.mergeMu.frozen = make(chan struct{})
+	}
+	r.mergeMu.txnID = intent.Txn.ID
+	r.mergeMu.sealed = true
+	r.mergeMu.lease = roachpb.Lease{}
+	r.mergeMu.Unlock()
+	log.Infof(ctx, "range frozen for merge by transaction %s", intent.Txn.ID.Short())
+
+	watchCtx := r.AnnotateCtx(context.Background())
+	if err := r.store.Stopper().RunAsyncTask(watchCtx, "storage.Replica: watching for merge",
+		func(ctx context.Context) {
+			r.watchForMerge(ctx, intent)
+		}); err != nil {
+		log.Warningf(ctx, "unable to watch for merge: %s", err)
+	}
+}
+
+// watchForMerge aborts the merge transaction which left the given intent on
+// the range descriptor, unless it has committed already. The previous
+// leaseholder can no longer seal the range, so the merge would fail anyway.
+// The freeze is lifted once the merge transaction has aborted and its intent
+// was resolved, or once the merge trigger has destroyed this replica.
+func (r *Replica) watchForMerge(ctx context.Context, intent roachpb.Intent) {
+	opts := retry.Options{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		Closer:         r.store.stopper.ShouldQuiesce(),
+	}
+	for re := retry.StartWithCtx(ctx, opts); re.Next(); {
+		if r.IsDestroyed() != nil {
+			r.unfreezeAfterMerge()
+			return
+		}
+		h := roachpb.Header{
+			Timestamp:    r.store.Clock().Now(),
+			UserPriority: roachpb.MaxUserPriority,
+		}
+		resolveIntents, pErr := r.store.intentResolver.maybePushTransactions(
+			ctx, []roachpb.Intent{intent}, h, roachpb.PUSH_ABORT, false, /* skipIfInFlight */
+		)
+		if pErr != nil {
+			log.VEventf(ctx, 2, "unable to push merge transaction %s: %s", intent.Txn.ID.Short(), pErr)
+			continue
+		}
+		if len(resolveIntents) != 1 || resolveIntents[0].Status == roachpb.COMMITTED {
+			// Wait for the merge trigger to destroy this replica.
+			continue
+		}
+		if err := r.store.intentResolver.resolveIntents(
+			ctx, resolveIntents, ResolveOptions{Wait: true, Poison: true},
+		); err != nil {
+			log.VEventf(ctx, 2, "unable to resolve the intent of merge transaction %s: %s",
+				intent.Txn.ID.Short(), err)
+			continue
+		}
+		log.Infof(ctx, "merge transaction %s aborted; lifting the freeze", intent.Txn.ID.Short())
+		r.unfreezeAfterMerge()
+		return
+	}
+}
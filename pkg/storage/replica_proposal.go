@@ -16,7 +16,6 @@ package storage
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"time"
@@ -504,6 +503,10 @@ func (r *Replica) leasePostApply(ctx context.Context, newLease roachpb.Lease) {
 		if r.leaseholderStats != nil {
 			r.leaseholderStats.resetRequestCounts()
 		}
+
+		// The previous leaseholder may have frozen the range for a merge, in
+		// which case it must not serve requests under this lease either.
+		r.maybeWatchForMerge(ctx)
 	}
 
 	// We're setting the new lease after we've updated the timestamp cache in
@@ -624,7 +627,9 @@ func addSSTablePreApply(
 			}
 		}
 
-		if err := ioutil.WriteFile(path, sst.Data, 0600); err != nil {
+		// Write the file through the engine's env so that it is encrypted at
+		// rest if the store is.
+		if err := eng.WriteFile(path, sst.Data); err != nil {
 			log.Fatalf(ctx, "while ingesting %s: %s", path, err)
 		}
 	}
@@ -64,12 +64,24 @@ func (r *replicaRaftStorage) InitialState() (raftpb.HardState, raftpb.ConfState,
 	if raft.IsEmptyHardState(hs) || err != nil {
 		return raftpb.HardState{}, raftpb.ConfState{}, err
 	}
+	return hs, confStateFromDesc(r.mu.state.Desc), nil
+}
+
+// confStateFromDesc synthesizes the raftpb.ConfState of the range from its
+// descriptor. Non-voting replicas and learners are Raft learners. Ranges only
+// have them once VersionNonVoterReplicas or VersionLearnerReplicas is active,
+// respectively, so the ConfState of a range of a cluster running an older
+// version only contains voters, as nodes running older binaries expect.
+func confStateFromDesc(desc *roachpb.RangeDescriptor) raftpb.ConfState {
 	var cs raftpb.ConfState
-	for _, rep := range r.mu.state.Desc.Replicas {
-		cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
+	for _, rep := range desc.Replicas {
+		if rep.IsVoter() {
+			cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
+		} else {
+			cs.Learners = append(cs.Learners, uint64(rep.ReplicaID))
+		}
 	}
-
-	return hs, cs, nil
+	return cs
 }
 
 // Entries implements the raft.Storage interface. Note that maxBytes is advisory
@@ -503,10 +515,7 @@ func snapshot(
 	}
 
 	// Synthesize our raftpb.ConfState from desc.
-	var cs raftpb.ConfState
-	for _, rep := range desc.Replicas {
-		cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
-	}
+	cs := confStateFromDesc(&desc)
 
 	term, err := term(ctx, rsl, snap, rangeID, eCache, appliedIndex)
 	if err != nil {
@@ -406,6 +406,13 @@ func (r *Replica) requestLeaseLocked(
 		llChan <- roachpb.NewError(newNotLeaseHolderError(nil, r.store.StoreID(), r.mu.state.Desc))
 		return llChan
 	}
+	if !repDesc.IsVoter() {
+		// Non-voting replicas cannot hold the lease.
+		llChan := make(chan *roachpb.Error, 1)
+		llChan <- roachpb.NewError(
+			newNotLeaseHolderError(r.mu.state.Lease, r.store.StoreID(), r.mu.state.Desc))
+		return llChan
+	}
 	return r.mu.pendingLeaseRequest.InitOrJoinRequest(
 		ctx, r, repDesc, status, r.mu.state.Desc.StartKey.AsRawKey(), false /* transfer */)
 }
@@ -451,6 +458,9 @@ func (r *Replica) AdminTransferLease(ctx context.Context, target roachpb.StoreID
 		if nextLeaseHolder, ok = desc.GetReplicaDescriptor(target); !ok {
 			return nil, nil, errors.Errorf("unable to find store %d in range %+v", target, desc)
 		}
+		if !nextLeaseHolder.IsVoter() {
+			return nil, nil, errors.Errorf("unable to transfer lease to non-voting replica %s", nextLeaseHolder)
+		}
 
 		if nextLease, ok := r.mu.pendingLeaseRequest.RequestPending(); ok &&
 			nextLease.Replica != nextLeaseHolder {
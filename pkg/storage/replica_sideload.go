@@ -94,9 +94,16 @@ type sideloadStorage interface {
 // incorrect since an ill-timed crash gives you thin proposals and no files.
 //
 // The passed-in slice is not mutated.
+//
+// Entries are not sideloaded on stores encrypted at rest: sideloaded files
+// are written outside the engine's env and would not be encrypted. Their
+// SSTables stay inlined in the Raft log, which is.
 func (r *Replica) maybeSideloadEntriesRaftMuLocked(
 	ctx context.Context, entriesToAppend []raftpb.Entry,
 ) (_ []raftpb.Entry, sideloadedEntriesSize int64, _ error) {
+	if r.store.Engine().IsEncrypted() {
+		return entriesToAppend, 0, nil
+	}
 	// TODO(tschottdorf): allocating this closure could be expensive. If so make
 	// it a method on Replica.
 	maybeRaftCommand := func(cmdID storagebase.CmdIDKey) (storagebase.RaftCommand, bool) {
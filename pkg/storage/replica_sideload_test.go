@@ -29,6 +29,7 @@ import (
 	"testing"
 
 	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -746,12 +747,13 @@ func TestRaftSSTableSideloadingSnapshot(t *testing.T) {
 		mockSender := &mockSender{}
 		if err := sendSnapshot(
 			ctx,
-			tc.store.cfg.Settings,
 			mockSender,
 			&fakeStorePool{},
 			SnapshotRequest_Header{State: os.State, Priority: SnapshotRequest_RECOVERY},
 			os,
 			tc.repl.store.Engine().NewBatch,
+			rate.NewLimiter(rate.Inf, 1),
+			tc.store.metrics,
 			func() {},
 		); err != nil {
 			t.Fatal(err)
@@ -867,12 +869,13 @@ func TestRaftSSTableSideloadingSnapshot(t *testing.T) {
 		mockSender := &mockSender{}
 		err = sendSnapshot(
 			ctx,
-			tc.store.cfg.Settings,
 			mockSender,
 			&fakeStorePool{},
 			SnapshotRequest_Header{State: failingOS.State, Priority: SnapshotRequest_RECOVERY},
 			failingOS,
 			tc.repl.store.Engine().NewBatch,
+			rate.NewLimiter(rate.Inf, 1),
+			tc.store.metrics,
 			func() {},
 		)
 		if _, ok := errors.Cause(err).(*errMustRetrySnapshotDueToTruncation); !ok {
@@ -424,6 +424,71 @@ func TestReplicaContains(t *testing.T) {
 	}
 }
 
+// TestReplicaAbandonedLearners verifies that learners being caught up by an
+// in-flight replica addition are never considered abandoned, and that other
+// learners are only considered abandoned once they have been observed for
+// abandonedLearnerAge.
+func TestReplicaAbandonedLearners(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	learner := func(id int) roachpb.ReplicaDescriptor {
+		return roachpb.ReplicaDescriptor{
+			StoreID:   roachpb.StoreID(id),
+			NodeID:    roachpb.NodeID(id),
+			ReplicaID: roachpb.ReplicaID(id),
+			Type:      roachpb.LEARNER.Enum(),
+		}
+	}
+	desc := &roachpb.RangeDescriptor{
+		Replicas: []roachpb.ReplicaDescriptor{{StoreID: 1, NodeID: 1, ReplicaID: 1}, learner(2), learner(3)},
+	}
+	// This test really only needs a hollow shell of a Replica.
+	r := &Replica{}
+	abandoned := func(now time.Time) []roachpb.ReplicaID {
+		var ids []roachpb.ReplicaID
+		for _, repDesc := range r.abandonedLearners(desc, now) {
+			ids = append(ids, repDesc.ReplicaID)
+		}
+		return ids
+	}
+
+	// The learner on s2 is being added by this replica, for instance on behalf
+	// of the store rebalancer or the merge queue.
+	r.trackInFlightLearner(2)
+	start := time.Unix(0, 0)
+	if ids := abandoned(start); len(ids) != 0 {
+		t.Fatalf("expected no abandoned learners, got %v", ids)
+	}
+	if ids := abandoned(start.Add(abandonedLearnerAge - 1)); len(ids) != 0 {
+		t.Fatalf("expected no abandoned learners, got %v", ids)
+	}
+	if ids := abandoned(start.Add(abandonedLearnerAge)); !reflect.DeepEqual(ids, []roachpb.ReplicaID{3}) {
+		t.Fatalf("expected learner 3 to be abandoned, got %v", ids)
+	}
+	if ids := abandoned(start.Add(10 * abandonedLearnerAge)); !reflect.DeepEqual(ids, []roachpb.ReplicaID{3}) {
+		t.Fatalf("expected in-flight learner 2 not to be abandoned, got %v", ids)
+	}
+
+	// Once the addition of the learner on s2 has finished without promoting
+	// it, the learner is given abandonedLearnerAge from then on.
+	r.untrackInFlightLearner(2)
+	later := start.Add(10 * abandonedLearnerAge)
+	if ids := abandoned(later); !reflect.DeepEqual(ids, []roachpb.ReplicaID{3}) {
+		t.Fatalf("expected learner 3 to be abandoned, got %v", ids)
+	}
+	if ids := abandoned(later.Add(abandonedLearnerAge)); !reflect.DeepEqual(ids, []roachpb.ReplicaID{2, 3}) {
+		t.Fatalf("expected learners 2 and 3 to be abandoned, got %v", ids)
+	}
+
+	// A learner which left the descriptor is forgotten.
+	desc.Replicas = desc.Replicas[:2]
+	if ids := abandoned(later.Add(abandonedLearnerAge)); !reflect.DeepEqual(ids, []roachpb.ReplicaID{2}) {
+		t.Fatalf("expected learner 2 to be abandoned, got %v", ids)
+	}
+	if _, ok := r.mu.learnersSeen[3]; ok {
+		t.Fatalf("expected learner 3 to be forgotten")
+	}
+}
+
 func sendLeaseRequest(r *Replica, l *roachpb.Lease) error {
 	ba := roachpb.BatchRequest{}
 	ba.Timestamp = r.store.Clock().Now()
@@ -69,6 +69,15 @@ var (
 	metaReplicateQueueTransferLeaseCount = metric.Metadata{
 		Name: "queue.replicate.transferlease",
 		Help: "Number of range lease transfers attempted by the replicate queue"}
+	metaReplicateQueueAddNonVoterCount = metric.Metadata{
+		Name: "queue.replicate.addnonvoter",
+		Help: "Number of non-voting replica additions attempted by the replicate queue"}
+	metaReplicateQueueRemoveNonVoterCount = metric.Metadata{
+		Name: "queue.replicate.removenonvoter",
+		Help: "Number of non-voting replica removals attempted by the replicate queue"}
+	metaReplicateQueueRemoveLearnerCount = metric.Metadata{
+		Name: "queue.replicate.removelearner",
+		Help: "Number of removals of learners left behind by failed replica additions attempted by the replicate queue"}
 )
 
 // ReplicateQueueMetrics is the set of metrics for the replicate queue.
@@ -78,6 +87,9 @@ type ReplicateQueueMetrics struct {
 	RemoveDeadReplicaCount *metric.Counter
 	RebalanceReplicaCount  *metric.Counter
 	TransferLeaseCount     *metric.Counter
+	AddNonVoterCount       *metric.Counter
+	RemoveNonVoterCount    *metric.Counter
+	RemoveLearnerCount     *metric.Counter
 }
 
 func makeReplicateQueueMetrics() ReplicateQueueMetrics {
@@ -87,6 +99,9 @@ func makeReplicateQueueMetrics() ReplicateQueueMetrics {
 		RemoveDeadReplicaCount: metric.NewCounter(metaReplicateQueueRemoveDeadReplicaCount),
 		RebalanceReplicaCount:  metric.NewCounter(metaReplicateQueueRebalanceReplicaCount),
 		TransferLeaseCount:     metric.NewCounter(metaReplicateQueueTransferLeaseCount),
+		AddNonVoterCount:       metric.NewCounter(metaReplicateQueueAddNonVoterCount),
+		RemoveNonVoterCount:    metric.NewCounter(metaReplicateQueueRemoveNonVoterCount),
+		RemoveLearnerCount:     metric.NewCounter(metaReplicateQueueRemoveLearnerCount),
 	}
 }
 
@@ -196,7 +211,7 @@ func (rq *replicateQueue) shouldQueue(
 	if lease, _ := repl.getLease(); repl.IsLeaseValid(lease, now) {
 		if rq.canTransferLease() &&
 			rq.allocator.ShouldTransferLease(
-				ctx, zone.Constraints, desc.Replicas, lease.Replica.StoreID, desc.RangeID, repl.leaseholderStats) {
+				ctx, zone, desc.Voters(), lease.Replica.StoreID, desc.RangeID, repl.leaseholderStats) {
 			log.VEventf(ctx, 2, "lease transfer needed, enqueuing")
 			return true, 0
 		}
@@ -248,12 +263,15 @@ func (rq *replicateQueue) processOneChange(
 	dryRun bool,
 ) (requeue bool, _ error) {
 	desc := repl.Desc()
+	// Unless noted otherwise, the actions below only concern the voting
+	// replicas of the range.
+	voters := desc.Voters()
 
 	// Avoid taking action if the range has too many dead replicas to make
 	// quorum.
-	liveReplicas, deadReplicas := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, desc.Replicas)
+	liveReplicas, deadReplicas := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, voters)
 	{
-		quorum := computeQuorum(len(desc.Replicas))
+		quorum := computeQuorum(len(voters))
 		if lr := len(liveReplicas); lr < quorum {
 			return false, errors.Errorf(
 				"range requires a replication change, but lacks a quorum of live replicas (%d/%d)", lr, quorum)
@@ -266,7 +284,8 @@ func (rq *replicateQueue) processOneChange(
 	}
 
 	rangeInfo := rangeInfoForRepl(repl, desc)
-	switch action, _ := rq.allocator.ComputeAction(ctx, zone, rangeInfo); action {
+	action, _ := rq.allocator.ComputeAction(ctx, zone, rangeInfo)
+	switch action {
 	case AllocatorNoop:
 		break
 	case AllocatorAdd:
@@ -287,7 +306,7 @@ func (rq *replicateQueue) processOneChange(
 		}
 
 		need := int(zone.NumReplicas)
-		willHave := len(desc.Replicas) + 1
+		willHave := len(voters) + 1
 
 		// Only up-replicate if there are suitable allocation targets such
 		// that, either the replication goal is met, or it is possible to get to the
@@ -326,6 +345,7 @@ func (rq *replicateQueue) processOneChange(
 			ctx,
 			repl,
 			newReplica,
+			roachpb.ADD_REPLICA,
 			desc,
 			SnapshotRequest_RECOVERY,
 			ReasonRangeUnderReplicated,
@@ -340,9 +360,9 @@ func (rq *replicateQueue) processOneChange(
 		if timeutil.Since(lastAddedTime) > newReplicaGracePeriod {
 			lastReplAdded = 0
 		}
-		candidates := filterUnremovableReplicas(repl.RaftStatus(), desc.Replicas, lastReplAdded)
+		candidates := filterUnremovableReplicas(repl.RaftStatus(), voters, lastReplAdded)
 		log.VEventf(ctx, 3, "filtered unremovable replicas from %v to get %v as candidates for removal",
-			desc.Replicas, candidates)
+			voters, candidates)
 		if len(candidates) == 0 {
 			return false, errors.Errorf("no removable replicas from range that needs a removal: %s",
 				rangeRaftProgress(repl.RaftStatus(), desc.Replicas))
@@ -394,7 +414,7 @@ func (rq *replicateQueue) processOneChange(
 		}
 	case AllocatorRemoveDecommissioning:
 		log.VEventf(ctx, 1, "removing a decommissioning replica")
-		decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(desc.RangeID, desc.Replicas)
+		decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(desc.RangeID, voters)
 		if len(decommissioningReplicas) == 0 {
 			log.VEventf(ctx, 1, "range of replica %s was identified as having decommissioning replicas, "+
 				"but no decommissioning replicas were found", repl)
@@ -454,6 +474,102 @@ func (rq *replicateQueue) processOneChange(
 		); err != nil {
 			return false, err
 		}
+	case AllocatorAddNonVoter:
+		log.VEventf(ctx, 1, "adding a new non-voting replica")
+		// All replicas of the range, voting or not, are passed to the allocator
+		// so that non-voting replicas are spread across localities too.
+		newStore, details, err := rq.allocator.AllocateTarget(
+			ctx,
+			zone.Constraints,
+			desc.Replicas,
+			rangeInfo,
+			true, /* relaxConstraints */
+		)
+		if err != nil {
+			return false, err
+		}
+		newNonVoter := roachpb.ReplicationTarget{
+			NodeID:  newStore.Node.NodeID,
+			StoreID: newStore.StoreID,
+		}
+		rq.metrics.AddNonVoterCount.Inc(1)
+		log.VEventf(ctx, 1, "adding non-voting replica %+v: %s",
+			newNonVoter, rangeRaftProgress(repl.RaftStatus(), desc.Replicas))
+		if err := rq.addReplica(
+			ctx,
+			repl,
+			newNonVoter,
+			roachpb.ADD_NON_VOTER,
+			desc,
+			SnapshotRequest_REBALANCE,
+			ReasonRangeUnderReplicated,
+			details,
+			dryRun,
+		); err != nil {
+			return false, err
+		}
+	case AllocatorRemoveNonVoter:
+		log.VEventf(ctx, 1, "removing a non-voting replica")
+		removeNonVoter, details, err := rq.allocator.RemoveTarget(
+			ctx, zone.Constraints, desc.NonVoters(), rangeInfo,
+		)
+		if err != nil {
+			return false, err
+		}
+		rq.metrics.RemoveNonVoterCount.Inc(1)
+		log.VEventf(ctx, 1, "removing non-voting replica %+v due to over-replication: %s",
+			removeNonVoter, rangeRaftProgress(repl.RaftStatus(), desc.Replicas))
+		target := roachpb.ReplicationTarget{
+			NodeID:  removeNonVoter.NodeID,
+			StoreID: removeNonVoter.StoreID,
+		}
+		if err := rq.removeReplica(
+			ctx, repl, target, desc, ReasonRangeOverReplicated, details, dryRun,
+		); err != nil {
+			return false, err
+		}
+	case AllocatorRemoveDeadNonVoter, AllocatorRemoveDecommissioningNonVoter:
+		nonVoters := desc.NonVoters()
+		reason := ReasonStoreDead
+		_, removable := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, nonVoters)
+		if action == AllocatorRemoveDecommissioningNonVoter {
+			reason = ReasonStoreDecommissioning
+			removable = rq.allocator.storePool.decommissioningReplicas(desc.RangeID, nonVoters)
+		}
+		if len(removable) == 0 {
+			log.VEventf(ctx, 1, "range of replica %s was identified as requiring %s, "+
+				"but no such non-voting replicas were found", repl, action)
+			break
+		}
+		rq.metrics.RemoveNonVoterCount.Inc(1)
+		log.VEventf(ctx, 1, "removing non-voting replica %+v from store", removable[0])
+		target := roachpb.ReplicationTarget{
+			NodeID:  removable[0].NodeID,
+			StoreID: removable[0].StoreID,
+		}
+		if err := rq.removeReplica(
+			ctx, repl, target, desc, reason, "", dryRun,
+		); err != nil {
+			return false, err
+		}
+	case AllocatorRemoveLearner:
+		learners := rangeInfo.AbandonedLearners
+		if len(learners) == 0 {
+			log.VEventf(ctx, 1, "range of replica %s was identified as requiring %s, "+
+				"but no abandoned learners were found", repl, action)
+			break
+		}
+		rq.metrics.RemoveLearnerCount.Inc(1)
+		log.VEventf(ctx, 1, "removing learner %+v left behind by a failed replica addition", learners[0])
+		target := roachpb.ReplicationTarget{
+			NodeID:  learners[0].NodeID,
+			StoreID: learners[0].StoreID,
+		}
+		if err := rq.removeReplica(
+			ctx, repl, target, desc, ReasonRangeOverReplicated, "abandoned learner", dryRun,
+		); err != nil {
+			return false, err
+		}
 	case AllocatorConsiderRebalance:
 		// The Noop case will result if this replica was queued in order to
 		// rebalance. Attempt to find a rebalancing target.
@@ -476,6 +592,7 @@ func (rq *replicateQueue) processOneChange(
 					ctx,
 					repl,
 					rebalanceReplica,
+					roachpb.ADD_REPLICA,
 					desc,
 					SnapshotRequest_REBALANCE,
 					ReasonRebalance,
@@ -532,10 +649,10 @@ func (rq *replicateQueue) transferLease(
 	zone config.ZoneConfig,
 	opts transferLeaseOptions,
 ) (bool, error) {
-	candidates := filterBehindReplicas(repl.RaftStatus(), desc.Replicas, 0 /* brandNewReplicaID */)
+	candidates := filterBehindReplicas(repl.RaftStatus(), desc.Voters(), 0 /* brandNewReplicaID */)
 	if target := rq.allocator.TransferLeaseTarget(
 		ctx,
-		zone.Constraints,
+		zone,
 		candidates,
 		repl.store.StoreID(),
 		desc.RangeID,
@@ -562,6 +679,7 @@ func (rq *replicateQueue) addReplica(
 	ctx context.Context,
 	repl *Replica,
 	target roachpb.ReplicationTarget,
+	changeType roachpb.ReplicaChangeType,
 	desc *roachpb.RangeDescriptor,
 	priority SnapshotRequest_Priority,
 	reason RangeLogEventReason,
@@ -571,7 +689,9 @@ func (rq *replicateQueue) addReplica(
 	if dryRun {
 		return nil
 	}
-	if err := repl.changeReplicas(ctx, roachpb.ADD_REPLICA, target, desc, priority, reason, details); err != nil {
+	if err := repl.changeReplicas(
+		ctx, changeType, target, desc, priority, reason, details,
+	); err != nil {
 		return err
 	}
 	rangeInfo := rangeInfoForRepl(repl, desc)
@@ -0,0 +1,278 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+const (
+	// loadSplitSampleSize is the number of candidate split keys sampled from
+	// the requests to a hot range.
+	loadSplitSampleSize = 20
+	// loadSplitMinSampleCount is the number of requests which must have been
+	// counted against a candidate before it can be chosen as the split key.
+	loadSplitMinSampleCount = 100
+	// loadSplitMaxImbalance is the largest tolerated difference between the
+	// requests on either side of a candidate split key, as a fraction of
+	// the requests to both sides.
+	loadSplitMaxImbalance = 0.25
+	// loadSplitMaxContained is the largest tolerated fraction of requests
+	// which span a candidate split key and would thus hit both sides.
+	loadSplitMaxContained = 0.5
+	// loadSplitMinSampleDuration is how long a range's requests are sampled
+	// before a split key is suggested.
+	loadSplitMinSampleDuration = 10 * time.Second
+	// loadSplitCheckInterval is the interval at which the QPS of a range is
+	// compared to the threshold.
+	loadSplitCheckInterval = time.Second
+)
+
+// LoadBasedSplitEnabled controls whether ranges are split to spread their
+// load.
+var LoadBasedSplitEnabled = settings.RegisterBoolSetting(
+	"kv.range_split.by_load_enabled",
+	"set to enable splitting of ranges based on their request load",
+	true,
+)
+
+// LoadBasedSplitQPSThreshold is the QPS above which a range is split to
+// spread its load.
+var LoadBasedSplitQPSThreshold = settings.RegisterValidatedIntSetting(
+	"kv.range_split.load_qps_threshold",
+	"the QPS over which a range is split to spread its load",
+	2500,
+	func(v int64) error {
+		if v <= 0 {
+			return errors.Errorf("cannot set kv.range_split.load_qps_threshold to a non-positive value: %d", v)
+		}
+		return nil
+	},
+)
+
+// splitKeySample is a candidate split key along with the number of requests
+// seen since it was sampled which lie to its left or right, or contain it.
+type splitKeySample struct {
+	key                    roachpb.Key
+	left, right, contained int
+}
+
+// splitKeyFinder picks a split key which balances the requests to a range
+// between its two sides. The candidates are a uniform sample of the start
+// keys of the requests, maintained using reservoir sampling.
+type splitKeyFinder struct {
+	startTime time.Time
+	count     int
+	samples   [loadSplitSampleSize]splitKeySample
+	rand      *rand.Rand
+}
+
+func newSplitKeyFinder(startTime time.Time, rng *rand.Rand) *splitKeyFinder {
+	return &splitKeyFinder{startTime: startTime, rand: rng}
+}
+
+// record accounts for a request spanning the given keys, which may make
+// its start key a candidate.
+func (f *splitKeyFinder) record(span roachpb.Span) {
+	idx := f.count
+	if f.count >= loadSplitSampleSize {
+		idx = f.rand.Intn(f.count + 1)
+	}
+	f.count++
+	if idx < loadSplitSampleSize {
+		f.samples[idx] = splitKeySample{key: span.Key}
+	}
+
+	for i := range f.samples[:f.numSamples()] {
+		s := &f.samples[i]
+		switch {
+		case bytes.Compare(span.Key, s.key) >= 0:
+			s.right++
+		case len(span.EndKey) > 0 && bytes.Compare(span.EndKey, s.key) > 0:
+			s.contained++
+		default:
+			s.left++
+		}
+	}
+}
+
+func (f *splitKeyFinder) numSamples() int {
+	if f.count < loadSplitSampleSize {
+		return f.count
+	}
+	return loadSplitSampleSize
+}
+
+// key returns the candidate which splits the requests most evenly, or nil
+// if no candidate has been observed for long enough or splits them evenly
+// enough.
+func (f *splitKeyFinder) key(now time.Time) roachpb.Key {
+	if now.Sub(f.startTime) < loadSplitMinSampleDuration {
+		return nil
+	}
+	var best roachpb.Key
+	bestImbalance := math.Inf(1)
+	for _, s := range f.samples[:f.numSamples()] {
+		total := s.left + s.right + s.contained
+		if total < loadSplitMinSampleCount || s.left == 0 || s.right == 0 {
+			continue
+		}
+		if float64(s.contained)/float64(total) > loadSplitMaxContained {
+			continue
+		}
+		imbalance := math.Abs(float64(s.left-s.right)) / float64(s.left+s.right)
+		if imbalance <= loadSplitMaxImbalance && imbalance < bestImbalance {
+			best, bestImbalance = s.key, imbalance
+		}
+	}
+	return best
+}
+
+// loadSplitDecider decides when a range receives enough requests to be split
+// and where. Once the QPS measured by the range's replicaStats exceeds
+// LoadBasedSplitQPSThreshold, it samples the keys of the range's requests
+// with a splitKeyFinder until it has found a good split key. Sampling stops
+// as soon as the QPS drops below the threshold again.
+type loadSplitDecider struct {
+	mu struct {
+		syncutil.Mutex
+		lastCheck time.Time
+		finder    *splitKeyFinder
+		splitKey  roachpb.Key
+		rand      *rand.Rand
+	}
+}
+
+func newLoadSplitDecider() *loadSplitDecider {
+	d := &loadSplitDecider{}
+	d.mu.rand = rand.New(rand.NewSource(timeutil.Now().UnixNano()))
+	return d
+}
+
+// record accounts for a request to the range at the given time. The current
+// QPS of the range is only retrieved once per loadSplitCheckInterval, and
+// the keys of the request only while the range is sampled. record returns
+// true when a split key has just been found.
+func (d *loadSplitDecider) record(
+	now time.Time, threshold float64, qps func() float64, span func() (roachpb.Span, error),
+) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.mu.lastCheck) >= loadSplitCheckInterval {
+		d.mu.lastCheck = now
+		if qps() >= threshold {
+			if d.mu.finder == nil {
+				d.mu.finder = newSplitKeyFinder(now, d.mu.rand)
+			}
+		} else {
+			d.mu.finder = nil
+		}
+	}
+	if d.mu.finder == nil || d.mu.splitKey != nil {
+		return false
+	}
+
+	s, err := span()
+	if err != nil {
+		return false
+	}
+	d.mu.finder.record(s)
+	if key := d.mu.finder.key(now); key != nil {
+		d.mu.splitKey = key
+		return true
+	}
+	return false
+}
+
+// splitKey returns the split key found by the decider, if any.
+func (d *loadSplitDecider) splitKey() roachpb.Key {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.mu.splitKey
+}
+
+// reset discards the split key and the samples.
+func (d *loadSplitDecider) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mu.finder = nil
+	d.mu.splitKey = nil
+}
+
+// recordLoadForSplit accounts for the request in the decision whether to
+// split the range based on its load, and queues the range for splitting once
+// a split key has been found.
+func (r *Replica) recordLoadForSplit(ctx context.Context, ba roachpb.BatchRequest) {
+	if r.leaseholderStats == nil || ba.Header.GatewayNodeID == 0 {
+		return
+	}
+	sv := &r.store.ClusterSettings().SV
+	if !LoadBasedSplitEnabled.Get(sv) {
+		return
+	}
+	threshold := float64(LoadBasedSplitQPSThreshold.Get(sv))
+	qps := func() float64 {
+		qps, dur := r.leaseholderStats.avgQPS()
+		if dur < MinStatsDuration {
+			return 0
+		}
+		return qps
+	}
+	span := func() (roachpb.Span, error) {
+		rspan, err := keys.Range(ba)
+		if err != nil {
+			return roachpb.Span{}, err
+		}
+		return rspan.AsRawSpanWithNoLocals(), nil
+	}
+	now := timeutil.Unix(0, r.store.Clock().PhysicalNow())
+	if r.loadSplitDecider.record(now, threshold, qps, span) {
+		log.VEventf(ctx, 2, "load-based split key found: %s", r.loadSplitDecider.splitKey())
+		r.store.splitQueue.MaybeAdd(r, r.store.Clock().Now())
+	}
+}
+
+// loadBasedSplitKey returns the key at which the range should be split to
+// spread its load, if any. The key is adjusted so that it does not fall into
+// the middle of a SQL row.
+func (r *Replica) loadBasedSplitKey() roachpb.Key {
+	key := r.loadSplitDecider.splitKey()
+	if key == nil {
+		return nil
+	}
+	if safeKey, err := keys.EnsureSafeSplitKey(key); err == nil {
+		key = safeKey
+	}
+	desc := r.Desc()
+	if rkey, err := keys.Addr(key); err != nil || !rkey.Equal(key) ||
+		!desc.ContainsKey(rkey) || rkey.Equal(desc.StartKey) {
+		return nil
+	}
+	return key
+}
@@ -0,0 +1,151 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func splitTestKey(i int) roachpb.Key {
+	return roachpb.Key(fmt.Sprintf("k%04d", i))
+}
+
+// TestSplitKeyFinder verifies that the finder suggests a split key which
+// balances the requests between both sides, and none if no such key exists.
+func TestSplitKeyFinder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	start := time.Unix(1500000000, 0)
+	testCases := []struct {
+		name string
+		// span returns the span of the i-th request.
+		span func(rng *rand.Rand, i int) roachpb.Span
+		// lo and hi bound the expected split key; an empty lo means that no
+		// split key is expected.
+		lo, hi roachpb.Key
+	}{
+		{
+			name: "uniform",
+			span: func(rng *rand.Rand, _ int) roachpb.Span {
+				return roachpb.Span{Key: splitTestKey(rng.Intn(1000))}
+			},
+			lo: splitTestKey(300), hi: splitTestKey(700),
+		},
+		{
+			name: "skewed",
+			span: func(rng *rand.Rand, i int) roachpb.Span {
+				if i%2 == 0 {
+					return roachpb.Span{Key: splitTestKey(rng.Intn(100))}
+				}
+				return roachpb.Span{Key: splitTestKey(100 + rng.Intn(900))}
+			},
+			lo: splitTestKey(50), hi: splitTestKey(350),
+		},
+		{
+			name: "single key",
+			span: func(_ *rand.Rand, _ int) roachpb.Span {
+				return roachpb.Span{Key: splitTestKey(1)}
+			},
+		},
+		{
+			name: "scans",
+			span: func(rng *rand.Rand, _ int) roachpb.Span {
+				return roachpb.Span{Key: splitTestKey(rng.Intn(10)), EndKey: splitTestKey(990 + rng.Intn(10))}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			f := newSplitKeyFinder(start, rng)
+			for i := 0; i < 10000; i++ {
+				f.record(tc.span(rng, i))
+			}
+			if key := f.key(start.Add(loadSplitMinSampleDuration / 2)); key != nil {
+				t.Fatalf("expected no split key before %s, got %s", loadSplitMinSampleDuration, key)
+			}
+			key := f.key(start.Add(loadSplitMinSampleDuration))
+			if len(tc.lo) == 0 {
+				if key != nil {
+					t.Fatalf("expected no split key, got %s", key)
+				}
+				return
+			}
+			if key.Compare(tc.lo) < 0 || key.Compare(tc.hi) > 0 {
+				t.Fatalf("expected split key in [%s, %s], got %s", tc.lo, tc.hi, key)
+			}
+		})
+	}
+}
+
+// TestLoadSplitDecider verifies that the decider only samples requests while
+// the QPS is above the threshold.
+func TestLoadSplitDecider(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	d := newLoadSplitDecider()
+	now := time.Unix(1500000000, 0)
+	var qps float64
+	rng := rand.New(rand.NewSource(1))
+	record := func() bool {
+		return d.record(now, 100, func() float64 { return qps }, func() (roachpb.Span, error) {
+			return roachpb.Span{Key: splitTestKey(rng.Intn(1000))}, nil
+		})
+	}
+
+	// Below the threshold, no requests are sampled.
+	for i := 0; i < 100; i++ {
+		now = now.Add(time.Second)
+		if record() {
+			t.Fatal("unexpected split key below the threshold")
+		}
+	}
+	if d.mu.finder != nil {
+		t.Fatal("unexpected sampling below the threshold")
+	}
+
+	// Above the threshold, a split key is found after sampling for a while.
+	qps = 200
+	var found bool
+	for i := 0; i < 1000 && !found; i++ {
+		now = now.Add(100 * time.Millisecond)
+		found = record()
+	}
+	if !found {
+		t.Fatal("expected a split key to be found")
+	}
+	if d.splitKey() == nil {
+		t.Fatal("expected the split key to be retained")
+	}
+
+	// Once the split key is reset, the QPS dropping below the threshold stops
+	// the sampling.
+	d.reset()
+	qps = 0
+	now = now.Add(time.Second)
+	if record() {
+		t.Fatal("unexpected split key below the threshold")
+	}
+	if d.mu.finder != nil || d.splitKey() != nil {
+		t.Fatal("expected the decider to be reset")
+	}
+}
@@ -32,8 +32,8 @@ const (
 	splitQueueTimerDuration = 0 // zero duration to process splits greedily.
 )
 
-// splitQueue manages a queue of ranges slated to be split due to size
-// or along intersecting zone config boundaries.
+// splitQueue manages a queue of ranges slated to be split due to size,
+// along intersecting zone config boundaries or to spread their load.
 type splitQueue struct {
 	*baseQueue
 	db *client.DB
@@ -62,7 +62,8 @@ func newSplitQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *splitQue
 
 // shouldQueue determines whether a range should be queued for
 // splitting. This is true if the range is intersected by a zone config
-// prefix or if the range's size in bytes exceeds the limit for the zone.
+// prefix, if the range's size in bytes exceeds the limit for the zone or
+// if a split key which spreads the range's load has been found.
 func (sq *splitQueue) shouldQueue(
 	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg config.SystemConfig,
 ) (shouldQ bool, priority float64) {
@@ -79,6 +80,12 @@ func (sq *splitQueue) shouldQueue(
 		priority += ratio
 		shouldQ = true
 	}
+
+	// Add a priority of 1 if the range is split to spread its load.
+	if repl.loadSplitDecider.splitKey() != nil {
+		priority++
+		shouldQ = true
+	}
 	return
 }
 
@@ -127,7 +134,33 @@ func (sq *splitQueue) process(ctx context.Context, r *Replica, sysCfg config.Sys
 			}
 			r.SetMaxBytes(zone.RangeMaxBytes)
 		}
+		return nil
+	}
+
+	// Finally handle case of splitting due to load.
+	if r.loadSplitDecider.splitKey() == nil {
+		return nil
+	}
+	splitKey := r.loadBasedSplitKey()
+	if splitKey == nil {
+		// The suggested key is not a valid split key (any longer), so start
+		// over sampling the requests.
+		r.loadSplitDecider.reset()
+		return nil
+	}
+	if _, _, pErr := r.adminSplitWithDescriptor(
+		ctx,
+		roachpb.AdminSplitRequest{
+			Span: roachpb.Span{
+				Key: splitKey,
+			},
+			SplitKey: splitKey,
+		},
+		desc,
+	); pErr != nil {
+		return errors.Wrapf(pErr.GoError(), "unable to split %s at key %q", r, splitKey)
 	}
+	r.loadSplitDecider.reset()
 	return nil
 }
 
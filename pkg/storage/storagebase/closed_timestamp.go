@@ -0,0 +1,101 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storagebase
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// ClosedTimestampTargetDuration is the lag behind the present at which
+// leaseholders close timestamps, i.e. promise not to accept any further
+// writes at or below them. A value of zero disables closing timestamps.
+var ClosedTimestampTargetDuration = settings.RegisterNonNegativeDurationSetting(
+	"kv.closed_timestamp.target_duration",
+	"if nonzero, attempt to provide closed timestamp notifications for timestamps trailing cluster time by approximately this duration",
+	30*time.Second,
+)
+
+// ClosedTimestampCloseFraction is the fraction of the target duration at
+// which the closed timestamps of ranges without write traffic are advanced.
+var ClosedTimestampCloseFraction = settings.RegisterValidatedFloatSetting(
+	"kv.closed_timestamp.close_fraction",
+	"fraction of closed timestamp target duration specifying how frequently the closed timestamp is advanced",
+	0.2,
+	func(v float64) error {
+		if v <= 0 || v > 1 {
+			return errors.Errorf("value not between zero and one: %f", v)
+		}
+		return nil
+	},
+)
+
+// FollowerReadsEnabled controls whether replicas which do not hold the
+// range lease serve reads at timestamps which have been closed.
+var FollowerReadsEnabled = settings.RegisterBoolSetting(
+	"kv.closed_timestamp.follower_reads_enabled",
+	"allow (all) replicas to serve consistent historical reads based on closed timestamp information",
+	true,
+)
+
+// FollowerReadThreshold returns the timestamp at or below which a read can
+// be expected to be served by any replica, given the current time. It
+// trails the closed timestamp target by the interval at which timestamps
+// are closed, so that ranges without writes have had a chance to catch up.
+// Quiesced ranges do not advance their closed timestamps, so reads on them
+// may still be redirected to the leaseholder. The returned timestamp is zero
+// if closed timestamps are disabled.
+func FollowerReadThreshold(sv *settings.Values, now hlc.Timestamp) hlc.Timestamp {
+	target := ClosedTimestampTargetDuration.Get(sv)
+	if target == 0 || !FollowerReadsEnabled.Get(sv) {
+		return hlc.Timestamp{}
+	}
+	lag := target + time.Duration(float64(target)*ClosedTimestampCloseFraction.Get(sv))
+	return hlc.Timestamp{WallTime: now.WallTime - lag.Nanoseconds()}
+}
+
+// BatchCanBeEvaluatedOnFollower returns whether the batch may be served by
+// a replica which does not hold the range lease, provided its timestamp
+// has been closed. Only consistent, non-locking reads qualify.
+func BatchCanBeEvaluatedOnFollower(ba roachpb.BatchRequest) bool {
+	if !ba.IsReadOnly() || ba.ReadConsistency != roachpb.CONSISTENT {
+		return false
+	}
+	for _, union := range ba.Requests {
+		switch union.GetInner().(type) {
+		case *roachpb.GetRequest, *roachpb.ScanRequest, *roachpb.ReverseScanRequest:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// FollowerReadTimestamp returns the highest timestamp at which the batch
+// may observe writes. For transactional batches this includes the
+// transaction's uncertainty interval.
+func FollowerReadTimestamp(ba roachpb.BatchRequest) hlc.Timestamp {
+	if ba.Txn != nil {
+		ts := ba.Txn.OrigTimestamp
+		ts.Forward(ba.Txn.MaxTimestamp)
+		return ts
+	}
+	return ba.Timestamp
+}
@@ -112,6 +112,16 @@ var changeTypeInternalToRaft = map[roachpb.ReplicaChangeType]raftpb.ConfChangeTy
 	roachpb.REMOVE_REPLICA: raftpb.ConfChangeRemoveNode,
 }
 
+// confChangeType returns the Raft configuration change corresponding to the
+// replica change. Non-voting replicas and learners are added as Raft learners.
+// Adding a voter under the replica ID of an existing learner promotes it.
+func confChangeType(crt *roachpb.ChangeReplicasTrigger) raftpb.ConfChangeType {
+	if crt.ChangeType == roachpb.ADD_REPLICA && !crt.Replica.IsVoter() {
+		return raftpb.ConfChangeAddLearnerNode
+	}
+	return changeTypeInternalToRaft[crt.ChangeType]
+}
+
 var storeSchedulerConcurrency = envutil.EnvOrDefaultInt(
 	"COCKROACH_SCHEDULER_CONCURRENCY", 8*runtime.NumCPU())
 
@@ -351,6 +361,8 @@ type Store struct {
 	rangeIDAlloc       *idAllocator                // Range ID allocator
 	gcQueue            *gcQueue                    // Garbage collection queue
 	splitQueue         *splitQueue                 // Range splitting queue
+	mergeQueue         *mergeQueue                 // Range merging queue
+	storeRebalancer    *storeRebalancer            // Load-based rebalancing of leases and replicas
 	replicateQueue     *replicateQueue             // Replication queue
 	replicaGCQueue     *replicaGCQueue             // Replica GC queue
 	raftLogQueue       *raftLogQueue               // Raft log truncation queue
@@ -368,10 +380,12 @@ type Store struct {
 	// gossip interval. Updated atomically.
 	gossipRangeCountdown int32
 	gossipLeaseCountdown int32
-	// gossipWritesPerSecondVal serves a similar purpose, but simply records
-	// the most recently gossiped value so that we can tell if a newly measured
-	// value differs by enough to justify re-gossiping the store.
-	gossipWritesPerSecondVal syncutil.AtomicFloat64
+	// gossipQueriesPerSecondVal and gossipWritesPerSecondVal serve a similar
+	// purpose, but simply record the most recently gossiped value so that we
+	// can tell if a newly measured value differs by enough to justify
+	// re-gossiping the store.
+	gossipQueriesPerSecondVal syncutil.AtomicFloat64
+	gossipWritesPerSecondVal  syncutil.AtomicFloat64
 
 	coalescedMu struct {
 		syncutil.Mutex
@@ -395,6 +409,8 @@ type Store struct {
 	// Semaphore to limit concurrent non-empty snapshot application and replica
 	// data destruction.
 	snapshotApplySem chan struct{}
+	// Semaphore to limit the snapshots sent concurrently by the store.
+	snapshotSendSem chan struct{}
 
 	// Are rebalances to this store allowed or prohibited. Rebalances are
 	// prohibited while a store is catching up replicas (i.e. recovering) after
@@ -620,6 +636,10 @@ type StoreConfig struct {
 	// to be applied concurrently.
 	concurrentSnapshotApplyLimit int
 
+	// concurrentSnapshotSendLimit specifies the maximum number of snapshots
+	// the store is permitted to send concurrently.
+	concurrentSnapshotSendLimit int
+
 	// MetricsSampleInterval is (server.Context).MetricsSampleInterval
 	MetricsSampleInterval time.Duration
 
@@ -725,6 +745,10 @@ type StoreTestingKnobs struct {
 	DisableReplicaRebalancing bool
 	// DisableSplitQueue disables the split queue.
 	DisableSplitQueue bool
+	// DisableMergeQueue disables the merge queue.
+	DisableMergeQueue bool
+	// DisableStoreRebalancer turns off the store rebalancer.
+	DisableStoreRebalancer bool
 	// DisableTimeSeriesMaintenanceQueue disables the time series maintenance
 	// queue.
 	DisableTimeSeriesMaintenanceQueue bool
@@ -809,6 +833,10 @@ func (sc *StoreConfig) SetDefaults() {
 		sc.concurrentSnapshotApplyLimit =
 			envutil.EnvOrDefaultInt("COCKROACH_CONCURRENT_SNAPSHOT_APPLY_LIMIT", 1)
 	}
+	if sc.concurrentSnapshotSendLimit == 0 {
+		sc.concurrentSnapshotSendLimit =
+			envutil.EnvOrDefaultInt("COCKROACH_CONCURRENT_SNAPSHOT_SEND_LIMIT", 2)
+	}
 
 	if sc.GossipWhenCapacityDeltaExceedsFraction == 0 {
 		sc.GossipWhenCapacityDeltaExceedsFraction = defaultGossipWhenCapacityDeltaExceedsFraction
@@ -872,6 +900,7 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 	s.tsCacheMu.Unlock()
 
 	s.snapshotApplySem = make(chan struct{}, cfg.concurrentSnapshotApplyLimit)
+	s.snapshotSendSem = make(chan struct{}, cfg.concurrentSnapshotSendLimit)
 
 	if s.cfg.Gossip != nil {
 		// Add range scanner and configure with queues.
@@ -880,14 +909,16 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 		)
 		s.gcQueue = newGCQueue(s, s.cfg.Gossip)
 		s.splitQueue = newSplitQueue(s, s.db, s.cfg.Gossip)
+		s.mergeQueue = newMergeQueue(s, s.db, s.cfg.Gossip)
 		s.replicateQueue = newReplicateQueue(s, s.cfg.Gossip, s.allocator, s.cfg.Clock)
 		s.replicaGCQueue = newReplicaGCQueue(s, s.db, s.cfg.Gossip)
 		s.raftLogQueue = newRaftLogQueue(s, s.db, s.cfg.Gossip)
 		s.raftSnapshotQueue = newRaftSnapshotQueue(s, s.cfg.Gossip, s.cfg.Clock)
 		s.consistencyQueue = newConsistencyQueue(s, s.cfg.Gossip)
 		s.scanner.AddQueues(
-			s.gcQueue, s.splitQueue, s.replicateQueue, s.replicaGCQueue,
+			s.gcQueue, s.splitQueue, s.mergeQueue, s.replicateQueue, s.replicaGCQueue,
 			s.raftLogQueue, s.raftSnapshotQueue, s.consistencyQueue)
+		s.storeRebalancer = newStoreRebalancer(s, s.replicateQueue)
 
 		if s.cfg.TimeSeriesDataStore != nil {
 			s.tsMaintenanceQueue = newTimeSeriesMaintenanceQueue(
@@ -906,6 +937,9 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 	if cfg.TestingKnobs.DisableSplitQueue {
 		s.setSplitQueueActive(false)
 	}
+	if cfg.TestingKnobs.DisableMergeQueue {
+		s.setMergeQueueActive(false)
+	}
 	if cfg.TestingKnobs.DisableTimeSeriesMaintenanceQueue {
 		s.setTimeSeriesMaintenanceQueueActive(false)
 	}
@@ -1204,6 +1238,16 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 			}
 		})
 
+		// Start the store rebalancer, which moves leases and replicas off the
+		// store when it is serving more than its share of the cluster's load.
+		if !s.cfg.TestingKnobs.DisableStoreRebalancer {
+			s.storeRebalancer.start(ctx, s.stopper)
+		}
+
+		// Start the closer of timestamps for ranges without write traffic, so
+		// that their followers can serve historical reads.
+		s.startClosedTimestampCloser(ctx)
+
 		// Run metrics computation up front to populate initial statistics.
 		if err = s.ComputeMetrics(ctx, -1); err != nil {
 			log.Infof(ctx, "%s: failed initial metrics computation: %s", s, err)
@@ -1340,6 +1384,7 @@ func (s *Store) GossipStore(ctx context.Context) error {
 
 	// Temporarily indicate that we're gossiping the store capacity to avoid
 	// recursively triggering a gossip of the store capacity.
+	syncutil.StoreFloat64(&s.gossipQueriesPerSecondVal, -1)
 	syncutil.StoreFloat64(&s.gossipWritesPerSecondVal, -1)
 
 	storeDesc, err := s.Descriptor()
@@ -1353,6 +1398,7 @@ func (s *Store) GossipStore(ctx context.Context) error {
 	atomic.StoreInt32(&s.gossipRangeCountdown, int32(math.Ceil(math.Max(rangeCountdown, 1))))
 	leaseCountdown := float64(storeDesc.Capacity.LeaseCount) * s.cfg.GossipWhenCapacityDeltaExceedsFraction
 	atomic.StoreInt32(&s.gossipLeaseCountdown, int32(math.Ceil(math.Max(leaseCountdown, 1))))
+	syncutil.StoreFloat64(&s.gossipQueriesPerSecondVal, storeDesc.Capacity.QueriesPerSecond)
 	syncutil.StoreFloat64(&s.gossipWritesPerSecondVal, storeDesc.Capacity.WritesPerSecond)
 
 	// Unique gossip key per store.
@@ -1423,25 +1469,29 @@ func (s *Store) maybeGossipOnCapacityChange(ctx context.Context, cce capacityCha
 	}
 }
 
-// recordNewWritesPerSecond takes a recently calculated value for the number
-// of key writes the store is handling and decides whether it has changed enough
-// to justify re-gossiping the store's capacity.
-func (s *Store) recordNewWritesPerSecond(newVal float64) {
-	oldVal := syncutil.LoadFloat64(&s.gossipWritesPerSecondVal)
-	if oldVal == -1 {
+// recordNewPerSecondStats takes recently calculated values for the number of
+// queries and key writes the store is handling and decides whether either has
+// changed enough to justify re-gossiping the store's capacity.
+func (s *Store) recordNewPerSecondStats(newQPS, newWPS float64) {
+	oldQPS := syncutil.LoadFloat64(&s.gossipQueriesPerSecondVal)
+	oldWPS := syncutil.LoadFloat64(&s.gossipWritesPerSecondVal)
+	if oldQPS == -1 || oldWPS == -1 {
 		// Gossiping of store capacity is already ongoing.
 		return
 	}
-	if newVal < oldVal*.5 || newVal > oldVal*1.5 {
+	changed := func(oldVal, newVal float64) bool {
+		return newVal < oldVal*.5 || newVal > oldVal*1.5
+	}
+	if changed(oldQPS, newQPS) || changed(oldWPS, newWPS) {
 		ctx := s.AnnotateCtx(context.TODO())
 		if err := s.stopper.RunAsyncTask(
-			ctx, "storage.Store: gossip on writes-per-second change",
+			ctx, "storage.Store: gossip on per-second stats change",
 			func(ctx context.Context) {
 				if err := s.GossipStore(ctx); err != nil {
-					log.Warningf(ctx, "error gossiping on writes-per-second change: %s", err)
+					log.Warningf(ctx, "error gossiping on per-second stats change: %s", err)
 				}
 			}); err != nil {
-			log.Warningf(ctx, "unable to gossip on writes-per-second change: %s", err)
+			log.Warningf(ctx, "unable to gossip on per-second stats change: %s", err)
 		}
 	}
 }
@@ -1846,6 +1896,12 @@ func splitPostApply(
 	rightRng.mu.Lock()
 	// Copy the minLeaseProposedTS from the LHS.
 	rightRng.mu.minLeaseProposedTS = r.mu.minLeaseProposedTS
+	// The timestamps closed before the split were promised for the RHS' keys
+	// as well, and followers may have served reads below them. The RHS starts
+	// out with the LHS' lease, so carry the promise over to its tracker; the
+	// store-wide timestamp cache does not cover reads served by followers.
+	rightRng.mu.closedTimestamp.Forward(r.mu.closedTimestamp)
+	rightRng.closedTSTracker.close(r.closedTSTracker.lastClosed())
 	rightLease := *rightRng.mu.state.Lease
 	rightRng.mu.Unlock()
 	r.mu.Unlock()
@@ -1939,6 +1995,7 @@ func (s *Store) SplitRange(ctx context.Context, origRng, newRng *Replica) error
 	// spans that are now owned by the new range.
 	origRng.leaseholderStats.resetRequestCounts()
 	origRng.writeStats.splitRequestCounts(newRng.writeStats)
+	origRng.loadSplitDecider.reset()
 
 	if kr := s.mu.replicasByKey.ReplaceOrInsert(origRng); kr != nil {
 		return errors.Errorf("replicasByKey unexpectedly contains %s when inserting replica %s", kr, origRng)
@@ -2000,11 +2057,29 @@ func (s *Store) MergeRange(
 		// logic that depends on them.
 		subsumingRng.writeStats.resetRequestCounts()
 	}
+	subsumingRng.loadSplitDecider.reset()
 
 	if err := s.maybeMergeTimestampCaches(ctx, subsumingRng, subsumedRng); err != nil {
 		return err
 	}
 
+	// The subsumed range's followers may have served reads below its closed
+	// timestamp, which can be ahead of the subsuming range's. Follower reads
+	// do not populate the timestamp cache, so bump it over the subsumed keys
+	// to keep the subsuming range from accepting writes below those reads.
+	// Forwarding the subsuming range's tracker instead would also close the
+	// timestamp over its own keys, below which writes may be in flight.
+	closed := subsumedRng.closedTSTracker.lastClosed()
+	closed.Forward(subsumedRng.ClosedTimestamp())
+	if closed != (hlc.Timestamp{}) {
+		s.tsCacheMu.Lock()
+		for _, keyRange := range makeReplicatedKeyRanges(subsumedDesc) {
+			s.tsCacheMu.cache.add(
+				keyRange.start.Key, keyRange.end.Key, closed, lowWaterTxnIDMarker, true /* readOnly */)
+		}
+		s.tsCacheMu.Unlock()
+	}
+
 	// Remove and destroy the subsumed range. Note that we were called
 	// (indirectly) from raft processing so we must call removeReplicaImpl
 	// directly to avoid deadlocking on Replica.raftMu.
@@ -2298,12 +2373,18 @@ func (s *Store) Capacity() (roachpb.StoreCapacity, error) {
 	now := s.cfg.Clock.Now()
 	var leaseCount int32
 	var logicalBytes int64
+	var totalQueriesPerSecond float64
 	var totalWritesPerSecond float64
 	bytesPerReplica := make([]float64, 0, capacity.RangeCount)
 	writesPerReplica := make([]float64, 0, capacity.RangeCount)
 	newStoreReplicaVisitor(s).Visit(func(r *Replica) bool {
 		if r.OwnsValidLease(now) {
 			leaseCount++
+			if r.leaseholderStats != nil {
+				if qps, dur := r.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+					totalQueriesPerSecond += qps
+				}
+			}
 		}
 		mvccStats := r.GetMVCCStats()
 		logicalBytes += mvccStats.Total()
@@ -2319,10 +2400,11 @@ func (s *Store) Capacity() (roachpb.StoreCapacity, error) {
 	})
 	capacity.LeaseCount = leaseCount
 	capacity.LogicalBytes = logicalBytes
+	capacity.QueriesPerSecond = totalQueriesPerSecond
 	capacity.WritesPerSecond = totalWritesPerSecond
 	capacity.BytesPerReplica = roachpb.PercentilesFromData(bytesPerReplica)
 	capacity.WritesPerReplica = roachpb.PercentilesFromData(writesPerReplica)
-	s.recordNewWritesPerSecond(totalWritesPerSecond)
+	s.recordNewPerSecondStats(totalQueriesPerSecond, totalWritesPerSecond)
 
 	return capacity, nil
 }
@@ -2739,6 +2821,23 @@ func (s *Store) reserveSnapshot(
 	}, "", nil
 }
 
+// acquireSnapshotSendSem blocks until the store is permitted to send another
+// snapshot. The returned closure releases the slot again.
+func (s *Store) acquireSnapshotSendSem(ctx context.Context) (func(), error) {
+	start := timeutil.Now()
+	select {
+	case s.snapshotSendSem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.stopper.ShouldStop():
+		return nil, errors.Errorf("stopped")
+	}
+	s.metrics.RangeSnapshotsThrottledNanos.Inc(timeutil.Since(start).Nanoseconds())
+	return func() {
+		<-s.snapshotSendSem
+	}, nil
+}
+
 // HandleSnapshot reads an incoming streaming snapshot and applies it if
 // possible.
 func (s *Store) HandleSnapshot(
@@ -3317,6 +3416,10 @@ var recoverySnapshotRate = settings.RegisterByteSizeSetting(
 	envutil.EnvOrDefaultBytes("COCKROACH_RAFT_SNAPSHOT_RATE", 8<<20),
 )
 
+// snapshotBatchSize is the size of the batches in which snapshot data is
+// sent. This is the granularity of rate limiting.
+const snapshotBatchSize = 256 << 10 // 256 KB
+
 func snapshotRateLimit(
 	st *cluster.Settings, priority SnapshotRequest_Priority,
 ) (rate.Limit, error) {
@@ -3341,15 +3444,18 @@ func (e *errMustRetrySnapshotDueToTruncation) Error() string {
 	)
 }
 
-// sendSnapshot sends an outgoing snapshot via a pre-opened GRPC stream.
+// sendSnapshot sends an outgoing snapshot via a pre-opened GRPC stream. The
+// limiter is consumed one token per batch of snapshotBatchSize bytes and is
+// shared with the other snapshots of the same priority sent by the node.
 func sendSnapshot(
 	ctx context.Context,
-	st *cluster.Settings,
 	stream OutgoingSnapshotStream,
 	storePool SnapshotStorePool,
 	header SnapshotRequest_Header,
 	snap *OutgoingSnapshot,
 	newBatch func() engine.Batch,
+	limiter *rate.Limiter,
+	metrics *StoreMetrics,
 	sent func(),
 ) error {
 	start := timeutil.Now()
@@ -3388,22 +3494,18 @@ func sendSnapshot(
 			to, resp.Status)
 	}
 
-	// The size of batches to send. This is the granularity of rate limiting.
-	const batchSize = 256 << 10 // 256 KB
-	targetRate, err := snapshotRateLimit(st, header.Priority)
-	if err != nil {
-		return errors.Wrapf(err, "%s", to)
+	targetRate := limiter.Limit() * snapshotBatchSize
+	waitAndSendBatch := func(b engine.Batch) error {
+		waitStart := timeutil.Now()
+		if err := limiter.WaitN(ctx, 1); err != nil {
+			b.Close()
+			return err
+		}
+		metrics.RangeSnapshotsThrottledNanos.Inc(timeutil.Since(waitStart).Nanoseconds())
+		metrics.RangeSnapshotsSentBytes.Inc(int64(len(b.Repr())))
+		return sendBatch(stream, b)
 	}
 
-	// Convert the bytes/sec rate limit to batches/sec.
-	//
-	// TODO(peter): Using bytes/sec for rate limiting seems more natural but has
-	// practical difficulties. We either need to use a very large burst size
-	// which seems to disable the rate limiting, or call WaitN in smaller than
-	// burst size chunks which caused excessive slowness in testing. Would be
-	// nice to figure this out, but the batches/sec rate limit works for now.
-	limiter := rate.NewLimiter(targetRate/batchSize, 1 /* burst size */)
-
 	// Determine the unreplicated key prefix so we can drop any
 	// unreplicated keys from the snapshot.
 	unreplicatedPrefix := keys.MakeRangeIDUnreplicatedPrefix(header.State.Desc.RangeID)
@@ -3435,11 +3537,8 @@ func sendSnapshot(
 			return err
 		}
 
-		if len(b.Repr()) >= batchSize {
-			if err := limiter.WaitN(ctx, 1); err != nil {
-				return err
-			}
-			if err := sendBatch(stream, b); err != nil {
+		if len(b.Repr()) >= snapshotBatchSize {
+			if err := waitAndSendBatch(b); err != nil {
 				return err
 			}
 			b = nil
@@ -3449,10 +3548,7 @@ func sendSnapshot(
 		}
 	}
 	if b != nil {
-		if err := limiter.WaitN(ctx, 1); err != nil {
-			return err
-		}
-		if err := sendBatch(stream, b); err != nil {
+		if err := waitAndSendBatch(b); err != nil {
 			return err
 		}
 	}
@@ -3536,6 +3632,9 @@ func sendSnapshot(
 		LogEntries: logEntries,
 		Final:      true,
 	}
+	for _, ent := range logEntries {
+		metrics.RangeSnapshotsSentBytes.Inc(int64(len(ent)))
+	}
 	// Notify the sent callback before the final snapshot request is sent so that
 	// the snapshots generated metric gets incremented before the snapshot is
 	// applied.
@@ -4084,6 +4183,7 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		leaseEpochCount               int64
 		raftLeaderNotLeaseHolderCount int64
 		quiescentCount                int64
+		averageQueriesPerSecond       float64
 		averageWritesPerSecond        float64
 
 		rangeCount                int64
@@ -4109,6 +4209,11 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		}
 		if metrics.Leaseholder {
 			leaseHolderCount++
+			if rep.leaseholderStats != nil {
+				if qps, dur := rep.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+					averageQueriesPerSecond += qps
+				}
+			}
 			switch metrics.LeaseType {
 			case roachpb.LeaseNone:
 			case roachpb.LeaseExpiration:
@@ -4143,8 +4248,9 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 	s.metrics.LeaseExpirationCount.Update(leaseExpirationCount)
 	s.metrics.LeaseEpochCount.Update(leaseEpochCount)
 	s.metrics.QuiescentCount.Update(quiescentCount)
+	s.metrics.AverageQueriesPerSecond.Update(averageQueriesPerSecond)
 	s.metrics.AverageWritesPerSecond.Update(averageWritesPerSecond)
-	s.recordNewWritesPerSecond(averageWritesPerSecond)
+	s.recordNewPerSecondStats(averageQueriesPerSecond, averageWritesPerSecond)
 
 	s.metrics.RangeCount.Update(rangeCount)
 	s.metrics.UnavailableRangeCount.Update(unavailableRangeCount)
@@ -4336,6 +4442,9 @@ func (s *Store) setReplicateQueueActive(active bool) {
 func (s *Store) setSplitQueueActive(active bool) {
 	s.splitQueue.SetDisabled(!active)
 }
+func (s *Store) setMergeQueueActive(active bool) {
+	s.mergeQueue.SetDisabled(!active)
+}
 func (s *Store) setTimeSeriesMaintenanceQueueActive(active bool) {
 	s.tsMaintenanceQueue.SetDisabled(!active)
 }
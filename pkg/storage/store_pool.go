@@ -360,6 +360,38 @@ func (sp *StorePool) updateLocalStoreAfterRebalance(
 	sp.detailsMu.storeDetails[storeID] = &detail
 }
 
+// updateLocalStoresAfterLeaseTransfer is used to update the local copies of
+// the source and target stores immediately after a lease transfer, moving the
+// given QPS of the range from the former to the latter.
+func (sp *StorePool) updateLocalStoresAfterLeaseTransfer(
+	from roachpb.StoreID, to roachpb.StoreID, rangeQPS float64,
+) {
+	sp.detailsMu.Lock()
+	defer sp.detailsMu.Unlock()
+
+	fromDetail := *sp.getStoreDetailLocked(from)
+	if fromDetail.desc != nil {
+		fromDesc := *fromDetail.desc
+		fromDesc.Capacity.LeaseCount--
+		if fromDesc.Capacity.QueriesPerSecond < rangeQPS {
+			fromDesc.Capacity.QueriesPerSecond = 0
+		} else {
+			fromDesc.Capacity.QueriesPerSecond -= rangeQPS
+		}
+		fromDetail.desc = &fromDesc
+		sp.detailsMu.storeDetails[from] = &fromDetail
+	}
+
+	toDetail := *sp.getStoreDetailLocked(to)
+	if toDetail.desc != nil {
+		toDesc := *toDetail.desc
+		toDesc.Capacity.LeaseCount++
+		toDesc.Capacity.QueriesPerSecond += rangeQPS
+		toDetail.desc = &toDesc
+		sp.detailsMu.storeDetails[to] = &toDetail
+	}
+}
+
 // newStoreDetail makes a new storeDetail struct. It sets index to be -1 to
 // ensure that it will be processed by a queue immediately.
 func newStoreDetail() *storeDetail {
@@ -496,6 +528,10 @@ type StoreList struct {
 	// to be rebalance targets.
 	candidateLogicalBytes stat
 
+	// candidateQueriesPerSecond tracks queries-per-second stats for stores that
+	// are eligible to be rebalance targets.
+	candidateQueriesPerSecond stat
+
 	// candidateWritesPerSecond tracks writes-per-second stats for stores that are
 	// eligible to be rebalance targets.
 	candidateWritesPerSecond stat
@@ -503,6 +539,21 @@ type StoreList struct {
 
 // Generates a new store list based on the passed in descriptors. It will
 // maintain the order of those descriptors.
+// excludeReplicaNodes returns a store list without the stores on the nodes
+// holding any of the supplied replicas.
+func (sl StoreList) excludeReplicaNodes(replicas []roachpb.ReplicaDescriptor) StoreList {
+	if len(replicas) == 0 {
+		return sl
+	}
+	var filtered []roachpb.StoreDescriptor
+	for _, store := range sl.stores {
+		if preexistingReplicaCheck(store.Node.NodeID, replicas) {
+			filtered = append(filtered, store)
+		}
+	}
+	return makeStoreList(filtered)
+}
+
 func makeStoreList(descriptors []roachpb.StoreDescriptor) StoreList {
 	sl := StoreList{stores: descriptors}
 	for _, desc := range descriptors {
@@ -511,6 +562,7 @@ func makeStoreList(descriptors []roachpb.StoreDescriptor) StoreList {
 		}
 		sl.candidateLeases.update(float64(desc.Capacity.LeaseCount))
 		sl.candidateLogicalBytes.update(float64(desc.Capacity.LogicalBytes))
+		sl.candidateQueriesPerSecond.update(desc.Capacity.QueriesPerSecond)
 		sl.candidateWritesPerSecond.update(desc.Capacity.WritesPerSecond)
 	}
 	return sl
@@ -519,10 +571,12 @@ func makeStoreList(descriptors []roachpb.StoreDescriptor) StoreList {
 func (sl StoreList) String() string {
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf,
-		"  candidate: avg-ranges=%v avg-leases=%v avg-disk-usage=%v avg-writes-per-second=%v",
+		"  candidate: avg-ranges=%v avg-leases=%v avg-disk-usage=%v avg-queries-per-second=%v "+
+			"avg-writes-per-second=%v",
 		sl.candidateRanges.mean,
 		sl.candidateLeases.mean,
 		humanizeutil.IBytes(int64(sl.candidateLogicalBytes.mean)),
+		sl.candidateQueriesPerSecond.mean,
 		sl.candidateWritesPerSecond.mean)
 	if len(sl.stores) > 0 {
 		fmt.Fprintf(&buf, "\n")
@@ -530,9 +584,11 @@ func (sl StoreList) String() string {
 		fmt.Fprintf(&buf, " <no candidates>")
 	}
 	for _, desc := range sl.stores {
-		fmt.Fprintf(&buf, "  %d: ranges=%d leases=%d disk-usage=%s writes-per-second=%.2f\n",
+		fmt.Fprintf(&buf,
+			"  %d: ranges=%d leases=%d disk-usage=%s queries-per-second=%.2f writes-per-second=%.2f\n",
 			desc.StoreID, desc.Capacity.RangeCount,
-			desc.Capacity.LeaseCount, humanizeutil.IBytes(desc.Capacity.LogicalBytes), desc.Capacity.WritesPerSecond)
+			desc.Capacity.LeaseCount, humanizeutil.IBytes(desc.Capacity.LogicalBytes),
+			desc.Capacity.QueriesPerSecond, desc.Capacity.WritesPerSecond)
 	}
 	return buf.String()
 }
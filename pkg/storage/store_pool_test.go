@@ -397,6 +397,54 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 	}
 }
 
+// TestStorePoolUpdateLocalStoresAfterLeaseTransfer verifies that a lease
+// transfer moves the QPS of the range between the local copies of the stores.
+func TestStorePoolUpdateLocalStoresAfterLeaseTransfer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, sp, _ := createTestStorePool(
+		TestTimeUntilStoreDead, false /* deterministic */, nodeStatusDead)
+	defer stopper.Stop(context.TODO())
+	sg := gossiputil.NewStoreGossiper(g)
+	stores := []*roachpb.StoreDescriptor{
+		{
+			StoreID: 1,
+			Node:    roachpb.NodeDescriptor{NodeID: 1},
+			Capacity: roachpb.StoreCapacity{
+				LeaseCount:       5,
+				QueriesPerSecond: 300,
+			},
+		},
+		{
+			StoreID: 2,
+			Node:    roachpb.NodeDescriptor{NodeID: 2},
+			Capacity: roachpb.StoreCapacity{
+				LeaseCount:       4,
+				QueriesPerSecond: 100,
+			},
+		},
+	}
+	sg.GossipStores(stores, t)
+
+	sp.updateLocalStoresAfterLeaseTransfer(roachpb.StoreID(1), roachpb.StoreID(2), 150)
+	for _, tc := range []struct {
+		storeID    roachpb.StoreID
+		leaseCount int32
+		qps        float64
+	}{
+		{1, 4, 150},
+		{2, 5, 250},
+	} {
+		desc, ok := sp.getStoreDescriptor(tc.storeID)
+		if !ok {
+			t.Fatalf("couldn't find StoreDescriptor for Store ID %d", tc.storeID)
+		}
+		if desc.Capacity.LeaseCount != tc.leaseCount || desc.Capacity.QueriesPerSecond != tc.qps {
+			t.Fatalf("s%d: expected %d leases and %.2f QPS, but got %d leases and %.2f QPS",
+				tc.storeID, tc.leaseCount, tc.qps, desc.Capacity.LeaseCount, desc.Capacity.QueriesPerSecond)
+		}
+	}
+}
+
 // TestStorePoolUpdateLocalStoreBeforeGossip verifies that an attempt to update
 // the local copy of store before that store has been gossiped will be a no-op.
 func TestStorePoolUpdateLocalStoreBeforeGossip(t *testing.T) {
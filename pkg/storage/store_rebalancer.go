@@ -0,0 +1,446 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+const (
+	// storeRebalancerInterval is the interval at which the store rebalancer
+	// checks whether the store is overloaded.
+	storeRebalancerInterval = time.Minute
+
+	// minQPSThresholdDifference is the minimum QPS by which a store must
+	// exceed the mean before it is considered overloaded. It keeps stores
+	// in lightly loaded clusters from shuffling leases around over noise.
+	minQPSThresholdDifference = 100
+)
+
+// LBRebalancingMode controls whether the store rebalancer moves leases
+// and/or replicas to balance the QPS across stores.
+type LBRebalancingMode int64
+
+const (
+	// LBRebalancingOff disables load-based rebalancing.
+	LBRebalancingOff LBRebalancingMode = iota
+	// LBRebalancingLeasesOnly only moves leases to balance load.
+	LBRebalancingLeasesOnly
+	// LBRebalancingLeasesAndReplicas moves leases and, where that does not
+	// suffice, replicas to balance load.
+	LBRebalancingLeasesAndReplicas
+)
+
+// LoadBasedRebalancingMode controls whether the store rebalancer is enabled
+// and what it may move.
+var LoadBasedRebalancingMode = settings.RegisterEnumSetting(
+	"kv.allocator.load_based_rebalancing",
+	"whether to rebalance based on the distribution of QPS across stores",
+	"leases and replicas",
+	map[int64]string{
+		int64(LBRebalancingOff):               "off",
+		int64(LBRebalancingLeasesOnly):        "leases",
+		int64(LBRebalancingLeasesAndReplicas): "leases and replicas",
+	},
+)
+
+// qpsRebalanceThreshold is the fraction above the mean QPS of all stores at
+// which a store is considered overloaded.
+var qpsRebalanceThreshold = settings.RegisterNonNegativeFloatSetting(
+	"kv.allocator.qps_rebalance_threshold",
+	"minimum fraction away from the mean a store's QPS can be before it is considered overloaded",
+	0.25,
+)
+
+// overfullQPSThreshold returns the QPS above which a store is considered
+// overloaded, given the mean QPS of all stores.
+func overfullQPSThreshold(threshold, mean float64) float64 {
+	return math.Max(mean*(1+threshold), mean+minQPSThresholdDifference)
+}
+
+// storeRebalancer periodically checks whether the QPS served by the store's
+// leaseholders exceeds the mean QPS of all stores by more than
+// qpsRebalanceThreshold. If so, it sheds load by transferring the leases of
+// its hottest ranges to other replicas on stores with spare capacity, and if
+// that does not suffice, by moving the replicas of its hottest ranges to such
+// stores. The store's QPS and the QPS of the other stores are taken from the
+// gossiped store descriptors.
+//
+// Every lease transfer and replica change is recorded in the range log with
+// ReasonStoreOverloaded.
+type storeRebalancer struct {
+	log.AmbientContext
+	store *Store
+	rq    *replicateQueue
+
+	// The functions below are overridden in tests, which don't have real
+	// Raft groups to inspect or ranges to move.
+	getRaftStatusFn   func(*Replica) *raft.Status
+	transferLeaseFn   func(context.Context, replicaWithQPS, roachpb.ReplicaDescriptor, string) error
+	relocateReplicaFn func(
+		context.Context, replicaWithQPS, *roachpb.RangeDescriptor, roachpb.ReplicationTarget, string,
+	) error
+}
+
+func newStoreRebalancer(store *Store, rq *replicateQueue) *storeRebalancer {
+	sr := &storeRebalancer{
+		AmbientContext: store.cfg.AmbientCtx,
+		store:          store,
+		rq:             rq,
+		getRaftStatusFn: func(r *Replica) *raft.Status {
+			return r.RaftStatus()
+		},
+	}
+	sr.transferLeaseFn = sr.transferLease
+	sr.relocateReplicaFn = sr.relocateReplica
+	return sr
+}
+
+// start runs the store rebalancer loop until the stopper stops.
+func (sr *storeRebalancer) start(ctx context.Context, stopper *stop.Stopper) {
+	ctx = sr.AnnotateCtx(ctx)
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(storeRebalancerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-stopper.ShouldStop():
+				return
+			}
+			mode := LBRebalancingMode(LoadBasedRebalancingMode.Get(&sr.store.ClusterSettings().SV))
+			if mode == LBRebalancingOff {
+				continue
+			}
+			sr.rebalanceStore(ctx, mode)
+		}
+	})
+}
+
+// replicaWithQPS pairs a replica with the QPS it serves as the leaseholder.
+type replicaWithQPS struct {
+	repl *Replica
+	qps  float64
+}
+
+// storeRebalancerDetails is recorded in the range log to explain a decision
+// of the store rebalancer.
+type storeRebalancerDetails struct {
+	RangeQPS       float64
+	StoreQPS       float64
+	TargetStoreQPS float64
+	MeanQPS        float64
+}
+
+// rebalanceStore moves leases and, if the mode allows it, replicas off the
+// store until its QPS is no longer above the overfull threshold or no further
+// move would help.
+func (sr *storeRebalancer) rebalanceStore(ctx context.Context, mode LBRebalancingMode) {
+	sysCfg, ok := sr.store.Gossip().GetSystemConfig()
+	if !ok {
+		log.Event(ctx, "system config not yet available; skipping load-based rebalancing")
+		return
+	}
+	storePool := sr.rq.allocator.storePool
+	storeList, _, _ := storePool.getStoreList(roachpb.RangeID(0), storeFilterNone)
+	throttledList, _, _ := storePool.getStoreList(roachpb.RangeID(0), storeFilterThrottled)
+	hottest, localQPS := sr.hottestReplicas()
+	sr.rebalance(ctx, mode, sysCfg, storeList, throttledList, hottest, localQPS)
+}
+
+// rebalance implements rebalanceStore, given the store lists to choose targets
+// from and the local replicas ordered by decreasing QPS. It returns the QPS
+// left on the store.
+func (sr *storeRebalancer) rebalance(
+	ctx context.Context,
+	mode LBRebalancingMode,
+	sysCfg config.SystemConfig,
+	storeList, throttledList StoreList,
+	hottest []replicaWithQPS,
+	localQPS float64,
+) float64 {
+	meanQPS := storeList.candidateQueriesPerSecond.mean
+	maxQPS := overfullQPSThreshold(qpsRebalanceThreshold.Get(&sr.store.ClusterSettings().SV), meanQPS)
+	if localQPS <= maxQPS {
+		log.VEventf(ctx, 1, "local QPS %.2f is below the max threshold %.2f (mean=%.2f); no rebalancing needed",
+			localQPS, maxQPS, meanQPS)
+		return localQPS
+	}
+	log.Infof(ctx, "considering load-based rebalancing: local QPS %.2f is above the max threshold %.2f (mean=%.2f)",
+		localQPS, maxQPS, meanQPS)
+
+	// Keep local copies of the store descriptors, which are updated as load is
+	// moved between stores.
+	storeQPS := make(map[roachpb.StoreID]float64, len(storeList.stores))
+	for _, desc := range storeList.stores {
+		storeQPS[desc.StoreID] = desc.Capacity.QueriesPerSecond
+	}
+
+	// First try to shed load by transferring leases, which is cheap.
+	var remaining []replicaWithQPS
+	for _, r := range hottest {
+		if localQPS <= maxQPS {
+			break
+		}
+		zone, err := sysCfg.GetZoneConfigForKey(r.repl.Desc().StartKey)
+		if err != nil {
+			log.Warning(ctx, err)
+			continue
+		}
+		target, ok := sr.chooseLeaseTarget(r, zone, storeList, storeQPS, maxQPS)
+		if !ok {
+			remaining = append(remaining, r)
+			continue
+		}
+		details := sr.details(r, localQPS, storeQPS[target.StoreID], meanQPS)
+		if err := sr.transferLeaseFn(ctx, r, target, details); err != nil {
+			log.Warning(ctx, err)
+			continue
+		}
+		localQPS -= r.qps
+		storeQPS[target.StoreID] += r.qps
+	}
+
+	if localQPS <= maxQPS {
+		log.Infof(ctx, "load-based lease transfers brought local QPS down to %.2f", localQPS)
+		return localQPS
+	}
+	if mode != LBRebalancingLeasesAndReplicas {
+		log.Infof(ctx, "ran out of leases worth transferring; local QPS %.2f is still above the max threshold %.2f",
+			localQPS, maxQPS)
+		return localQPS
+	}
+
+	// Then move the replicas of the hottest ranges whose leases could not be
+	// transferred to less loaded stores.
+	for _, r := range remaining {
+		if localQPS <= maxQPS {
+			break
+		}
+		desc := r.repl.Desc()
+		zone, err := sysCfg.GetZoneConfigForKey(desc.StartKey)
+		if err != nil {
+			log.Warning(ctx, err)
+			continue
+		}
+		if len(desc.Voters()) != int(zone.NumReplicas) ||
+			len(desc.NonVoters()) != int(zone.NumNonVoters) ||
+			len(desc.Learners()) > 0 {
+			// Leave ranges which need up- or down-replication, or which are in
+			// the middle of a replica addition, to the replicate queue.
+			continue
+		}
+		target, ok := sr.chooseReplicaTarget(r, desc, zone, throttledList, storeQPS, maxQPS)
+		if !ok {
+			continue
+		}
+		details := sr.details(r, localQPS, storeQPS[target.StoreID], meanQPS)
+		if err := sr.relocateReplicaFn(ctx, r, desc, target, details); err != nil {
+			log.Warning(ctx, err)
+			continue
+		}
+		localQPS -= r.qps
+		storeQPS[target.StoreID] += r.qps
+	}
+	log.Infof(ctx, "load-based rebalancing brought local QPS down to %.2f", localQPS)
+	return localQPS
+}
+
+// hottestReplicas returns the replicas for which the store holds the lease,
+// ordered by decreasing QPS, along with their total QPS.
+func (sr *storeRebalancer) hottestReplicas() ([]replicaWithQPS, float64) {
+	now := sr.store.Clock().Now()
+	var replicas []replicaWithQPS
+	var total float64
+	newStoreReplicaVisitor(sr.store).Visit(func(r *Replica) bool {
+		if r.leaseholderStats == nil || !r.OwnsValidLease(now) {
+			return true
+		}
+		if qps, dur := r.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+			replicas = append(replicas, replicaWithQPS{repl: r, qps: qps})
+			total += qps
+		}
+		return true
+	})
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].qps > replicas[j].qps
+	})
+	return replicas, total
+}
+
+// chooseLeaseTarget returns the least loaded store among the up-to-date
+// replicas of the range which can take the range's QPS without becoming
+// overloaded itself. If the zone has lease preferences, only the preferred
+// replicas are considered so as not to fight the replicate queue.
+func (sr *storeRebalancer) chooseLeaseTarget(
+	r replicaWithQPS,
+	zone config.ZoneConfig,
+	storeList StoreList,
+	storeQPS map[roachpb.StoreID]float64,
+	maxQPS float64,
+) (roachpb.ReplicaDescriptor, bool) {
+	candidates := filterBehindReplicas(sr.getRaftStatusFn(r.repl), r.repl.Desc().Voters(), 0)
+	if preferred := preferredLeaseholders(storeList, zone.LeasePreferences, candidates); len(preferred) > 0 {
+		candidates = preferred
+	}
+	var target roachpb.ReplicaDescriptor
+	targetQPS := math.Inf(1)
+	for _, candidate := range candidates {
+		if candidate.StoreID == sr.store.StoreID() {
+			continue
+		}
+		qps, ok := storeQPS[candidate.StoreID]
+		if !ok || qps+r.qps > maxQPS {
+			// The store is not live, or the transfer would overload it.
+			continue
+		}
+		if qps < targetQPS {
+			target, targetQPS = candidate, qps
+		}
+	}
+	return target, targetQPS != math.Inf(1)
+}
+
+// chooseReplicaTarget returns the least loaded store which satisfies the
+// constraints of the range, does not lower its diversity when replacing the
+// local replica, and can take the range's QPS without becoming overloaded
+// itself.
+func (sr *storeRebalancer) chooseReplicaTarget(
+	r replicaWithQPS,
+	desc *roachpb.RangeDescriptor,
+	zone config.ZoneConfig,
+	storeList StoreList,
+	storeQPS map[roachpb.StoreID]float64,
+	maxQPS float64,
+) (roachpb.ReplicationTarget, bool) {
+	var others []roachpb.ReplicaDescriptor
+	for _, repDesc := range desc.Replicas {
+		if repDesc.StoreID != sr.store.StoreID() {
+			others = append(others, repDesc)
+		}
+	}
+	storePool := sr.rq.allocator.storePool
+	minDiversity := diversityRemovalScore(sr.store.Ident.NodeID, storePool.getLocalities(desc.Replicas))
+	otherLocalities := storePool.getLocalities(others)
+
+	var target roachpb.ReplicationTarget
+	targetQPS := math.Inf(1)
+	for _, store := range storeList.stores {
+		if store.StoreID == sr.store.StoreID() || !preexistingReplicaCheck(store.Node.NodeID, others) {
+			continue
+		}
+		if ok, _ := constraintCheck(store, zone.Constraints); !ok || !maxCapacityCheck(store) {
+			continue
+		}
+		if diversityScore(store, otherLocalities) < minDiversity {
+			continue
+		}
+		qps := storeQPS[store.StoreID]
+		if qps+r.qps > maxQPS {
+			continue
+		}
+		if qps < targetQPS {
+			target = roachpb.ReplicationTarget{NodeID: store.Node.NodeID, StoreID: store.StoreID}
+			targetQPS = qps
+		}
+	}
+	return target, targetQPS != math.Inf(1)
+}
+
+// transferLease transfers the lease of the range to the given replica and
+// records the transfer in the range log.
+func (sr *storeRebalancer) transferLease(
+	ctx context.Context, r replicaWithQPS, target roachpb.ReplicaDescriptor, details string,
+) error {
+	log.VEventf(ctx, 1, "transferring lease of r%d (qps=%.2f) to s%d", r.repl.RangeID, r.qps, target.StoreID)
+	if err := r.repl.AdminTransferLease(ctx, target.StoreID); err != nil {
+		return errors.Wrapf(err, "%s: unable to transfer lease to s%d", r.repl, target.StoreID)
+	}
+	sr.store.metrics.StoreRebalancerLeaseTransferCount.Inc(1)
+	sr.rq.allocator.storePool.updateLocalStoresAfterLeaseTransfer(sr.store.StoreID(), target.StoreID, r.qps)
+
+	desc := *r.repl.Desc()
+	return sr.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return sr.store.logLeaseTransfer(ctx, txn, desc, target, ReasonStoreOverloaded, details)
+	})
+}
+
+// relocateReplica moves the local replica of the range to the target store.
+// It adds a replica on the target, transfers the lease to it and then
+// removes the local replica.
+func (sr *storeRebalancer) relocateReplica(
+	ctx context.Context,
+	r replicaWithQPS,
+	desc *roachpb.RangeDescriptor,
+	target roachpb.ReplicationTarget,
+	details string,
+) error {
+	log.VEventf(ctx, 1, "moving replica of r%d (qps=%.2f) to s%d", r.repl.RangeID, r.qps, target.StoreID)
+	if err := sr.rq.addReplica(
+		ctx, r.repl, target, roachpb.VOTER, desc, SnapshotRequest_REBALANCE, ReasonStoreOverloaded, details,
+		false, /* dryRun */
+	); err != nil {
+		return errors.Wrapf(err, "%s: unable to add replica on s%d", r.repl, target.StoreID)
+	}
+	sr.store.metrics.StoreRebalancerRangeRebalanceCount.Inc(1)
+
+	newReplica, ok := r.repl.Desc().GetReplicaDescriptor(target.StoreID)
+	if !ok {
+		// The replicate queue removes the excess replica, if any.
+		return errors.Errorf("%s: added replica on s%d is not in the range descriptor", r.repl, target.StoreID)
+	}
+	if err := sr.transferLease(ctx, r, newReplica, details); err != nil {
+		// The replicate queue removes the excess replica.
+		return err
+	}
+	local := roachpb.ReplicationTarget{NodeID: sr.store.Ident.NodeID, StoreID: sr.store.StoreID()}
+	if err := sr.rq.removeReplica(
+		ctx, r.repl, local, r.repl.Desc(), ReasonStoreOverloaded, details, false, /* dryRun */
+	); err != nil {
+		// The replicate queue of the new leaseholder removes the excess replica.
+		return errors.Wrapf(err, "%s: unable to remove local replica", r.repl)
+	}
+	return nil
+}
+
+// details returns the JSON-encoded storeRebalancerDetails for the range log.
+func (sr *storeRebalancer) details(
+	r replicaWithQPS, storeQPS, targetStoreQPS, meanQPS float64,
+) string {
+	details, err := json.Marshal(storeRebalancerDetails{
+		RangeQPS:       r.qps,
+		StoreQPS:       storeQPS,
+		TargetStoreQPS: targetStoreQPS,
+		MeanQPS:        meanQPS,
+	})
+	if err != nil {
+		log.Warningf(sr.AnnotateCtx(context.TODO()), "failed to marshal store rebalancer details: %s", err)
+	}
+	return string(details)
+}
@@ -0,0 +1,340 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/etcd/raft"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// makeQPSStores returns store descriptors for stores 1 through len(qps), each
+// on its own node, serving the given QPS.
+func makeQPSStores(qps ...float64) []roachpb.StoreDescriptor {
+	stores := make([]roachpb.StoreDescriptor, len(qps))
+	for i := range qps {
+		stores[i] = roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i + 1),
+			Attrs:   roachpb.Attributes{Attrs: []string{"hdd"}},
+			Node:    roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i + 1)},
+			Capacity: roachpb.StoreCapacity{
+				Capacity:         200,
+				Available:        100,
+				QueriesPerSecond: qps[i],
+			},
+		}
+	}
+	return stores
+}
+
+// makeQPSReplica returns a replica of a range with replicas on the given
+// stores, serving the given QPS.
+func makeQPSReplica(
+	rangeID roachpb.RangeID, qps float64, storeIDs ...roachpb.StoreID,
+) replicaWithQPS {
+	desc := &roachpb.RangeDescriptor{
+		RangeID:  rangeID,
+		StartKey: roachpb.RKey(fmt.Sprintf("r%d", rangeID)),
+		EndKey:   roachpb.RKey(fmt.Sprintf("r%d", rangeID+1)),
+	}
+	for i, storeID := range storeIDs {
+		desc.Replicas = append(desc.Replicas, roachpb.ReplicaDescriptor{
+			NodeID:    roachpb.NodeID(storeID),
+			StoreID:   storeID,
+			ReplicaID: roachpb.ReplicaID(i + 1),
+		})
+	}
+	desc.NextReplicaID = roachpb.ReplicaID(len(storeIDs) + 1)
+	repl := &Replica{RangeID: rangeID}
+	repl.mu.state.Desc = desc
+	return replicaWithQPS{repl: repl, qps: qps}
+}
+
+// createTestStoreRebalancer returns a store rebalancer for store 1, which
+// considers every replica to be up to date. Stopper must be stopped by the
+// caller.
+func createTestStoreRebalancer() (*stop.Stopper, *storeRebalancer) {
+	stopper, _, _, a, _ := createTestAllocator(true /* deterministic */)
+	cfg := TestStoreConfig(nil)
+	store := &Store{
+		cfg:   cfg,
+		Ident: roachpb.StoreIdent{NodeID: 1, StoreID: 1},
+	}
+	sr := newStoreRebalancer(store, &replicateQueue{allocator: a})
+	sr.getRaftStatusFn = func(r *Replica) *raft.Status {
+		status := &raft.Status{
+			Progress: make(map[uint64]raft.Progress),
+		}
+		status.Lead = 1
+		for _, replica := range r.Desc().Replicas {
+			status.Progress[uint64(replica.ReplicaID)] = raft.Progress{
+				State: raft.ProgressStateReplicate,
+			}
+		}
+		return status
+	}
+	return stopper, sr
+}
+
+func storeQPSMap(storeList StoreList) map[roachpb.StoreID]float64 {
+	storeQPS := make(map[roachpb.StoreID]float64)
+	for _, desc := range storeList.stores {
+		storeQPS[desc.StoreID] = desc.Capacity.QueriesPerSecond
+	}
+	return storeQPS
+}
+
+func TestOverfullQPSThreshold(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		threshold, mean, expected float64
+	}{
+		// The minimum difference applies to lightly loaded clusters.
+		{0.25, 0, 100},
+		{0.25, 200, 300},
+		// The fraction applies to heavily loaded clusters.
+		{0.25, 1000, 1250},
+		{0.1, 2000, 2200},
+	}
+	for _, c := range testCases {
+		if actual := overfullQPSThreshold(c.threshold, c.mean); actual != c.expected {
+			t.Errorf("overfullQPSThreshold(%.2f, %.2f) = %.2f, expected %.2f",
+				c.threshold, c.mean, actual, c.expected)
+		}
+	}
+}
+
+func TestStoreRebalancerChooseLeaseTarget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, sr := createTestStoreRebalancer()
+	defer stopper.Stop(context.TODO())
+
+	storeList := makeStoreList(makeQPSStores(1500, 1000, 500, 300, 200))
+	// The mean is 700, so stores are overfull above 875 QPS.
+	const maxQPS = 875
+
+	testCases := []struct {
+		storeIDs []roachpb.StoreID
+		qps      float64
+		expected roachpb.StoreID
+	}{
+		// The least loaded replica is chosen.
+		{[]roachpb.StoreID{1, 2, 3}, 100, 3},
+		{[]roachpb.StoreID{1, 2, 4}, 100, 4},
+		{[]roachpb.StoreID{1, 4, 5}, 100, 5},
+		// Replicas which the transfer would overload are skipped.
+		{[]roachpb.StoreID{1, 3, 4}, 500, 4},
+		{[]roachpb.StoreID{1, 2, 4}, 600, 0},
+		{[]roachpb.StoreID{1, 2}, 100, 0},
+		// There is no other replica.
+		{[]roachpb.StoreID{1}, 100, 0},
+	}
+	for i, c := range testCases {
+		r := makeQPSReplica(roachpb.RangeID(i+1), c.qps, c.storeIDs...)
+		target, ok := sr.chooseLeaseTarget(
+			r, config.ZoneConfig{}, storeList, storeQPSMap(storeList), maxQPS)
+		if ok != (c.expected != 0) || target.StoreID != c.expected {
+			t.Errorf("%d: replicas on %v with %.2f QPS: expected lease target s%d, got s%d (ok=%t)",
+				i, c.storeIDs, c.qps, c.expected, target.StoreID, ok)
+		}
+	}
+
+	// Replicas which are behind are not considered.
+	sr.getRaftStatusFn = func(r *Replica) *raft.Status {
+		return &raft.Status{
+			Progress: map[uint64]raft.Progress{
+				1: {State: raft.ProgressStateReplicate},
+				2: {State: raft.ProgressStateProbe},
+			},
+		}
+	}
+	r := makeQPSReplica(roachpb.RangeID(100), 100, 1, 5)
+	if target, ok := sr.chooseLeaseTarget(
+		r, config.ZoneConfig{}, storeList, storeQPSMap(storeList), maxQPS,
+	); ok {
+		t.Errorf("expected no lease target for a replica which is behind, got s%d", target.StoreID)
+	}
+}
+
+func TestStoreRebalancerChooseReplicaTarget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, sr := createTestStoreRebalancer()
+	defer stopper.Stop(context.TODO())
+
+	stores := makeQPSStores(1500, 1000, 500, 300, 200)
+	stores[3].Attrs.Attrs = []string{"ssd"}
+	storeList := makeStoreList(stores)
+	// The mean is 700, so stores are overfull above 875 QPS.
+	const maxQPS = 875
+
+	ssdZone := config.ZoneConfig{
+		Constraints: config.Constraints{
+			Constraints: []config.Constraint{{Value: "ssd", Type: config.Constraint_REQUIRED}},
+		},
+	}
+	testCases := []struct {
+		storeIDs []roachpb.StoreID
+		qps      float64
+		zone     config.ZoneConfig
+		expected roachpb.StoreID
+	}{
+		// The least loaded store without a replica is chosen.
+		{[]roachpb.StoreID{1, 2, 3}, 100, config.ZoneConfig{}, 5},
+		{[]roachpb.StoreID{1, 4, 5}, 100, config.ZoneConfig{}, 3},
+		{[]roachpb.StoreID{1, 2, 3}, 600, config.ZoneConfig{}, 5},
+		// Stores which the move would overload are skipped.
+		{[]roachpb.StoreID{1, 2, 5}, 500, config.ZoneConfig{}, 4},
+		{[]roachpb.StoreID{1, 2, 3}, 700, config.ZoneConfig{}, 0},
+		// Stores which don't satisfy the constraints are skipped.
+		{[]roachpb.StoreID{1, 2, 3}, 100, ssdZone, 4},
+		{[]roachpb.StoreID{1, 2, 4}, 100, ssdZone, 0},
+	}
+	for i, c := range testCases {
+		r := makeQPSReplica(roachpb.RangeID(i+1), c.qps, c.storeIDs...)
+		target, ok := sr.chooseReplicaTarget(
+			r, r.repl.Desc(), c.zone, storeList, storeQPSMap(storeList), maxQPS)
+		if ok != (c.expected != 0) || target.StoreID != c.expected {
+			t.Errorf("%d: replicas on %v with %.2f QPS: expected replica target s%d, got s%d (ok=%t)",
+				i, c.storeIDs, c.qps, c.expected, target.StoreID, ok)
+		}
+	}
+}
+
+func TestStoreRebalancerRebalance(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, sr := createTestStoreRebalancer()
+	defer stopper.Stop(context.TODO())
+	config.TestingSetupZoneConfigHook(stopper)
+	config.TestingSetZoneConfig(keys.RootNamespaceID, config.ZoneConfig{NumReplicas: 2})
+
+	type move struct {
+		rangeID roachpb.RangeID
+		lease   bool
+		storeID roachpb.StoreID
+	}
+	var moves []move
+	sr.transferLeaseFn = func(
+		_ context.Context, r replicaWithQPS, target roachpb.ReplicaDescriptor, _ string,
+	) error {
+		moves = append(moves, move{r.repl.RangeID, true, target.StoreID})
+		return nil
+	}
+	sr.relocateReplicaFn = func(
+		_ context.Context,
+		r replicaWithQPS,
+		_ *roachpb.RangeDescriptor,
+		target roachpb.ReplicationTarget,
+		_ string,
+	) error {
+		moves = append(moves, move{r.repl.RangeID, false, target.StoreID})
+		return nil
+	}
+
+	testCases := []struct {
+		name     string
+		mode     LBRebalancingMode
+		storeQPS []float64
+		hottest  []replicaWithQPS
+		expected []move
+		localQPS float64
+	}{
+		{
+			// The mean is 400 and the threshold 500, which the store is below.
+			name:     "below threshold",
+			mode:     LBRebalancingLeasesAndReplicas,
+			storeQPS: []float64{450, 400, 400, 400, 350},
+			hottest: []replicaWithQPS{
+				makeQPSReplica(1, 300, 1, 5),
+			},
+			localQPS: 450,
+		},
+		{
+			// The mean is 540 and the threshold 675. Leases are transferred
+			// until the store drops below it.
+			name:     "leases until below threshold",
+			mode:     LBRebalancingLeasesAndReplicas,
+			storeQPS: []float64{1500, 300, 300, 300, 300},
+			hottest: []replicaWithQPS{
+				makeQPSReplica(1, 300, 1, 2),
+				makeQPSReplica(2, 300, 1, 3),
+				makeQPSReplica(3, 300, 1, 4),
+				makeQPSReplica(4, 300, 1, 5),
+			},
+			expected: []move{{1, true, 2}, {2, true, 3}, {3, true, 4}},
+			localQPS: 600,
+		},
+		{
+			// The mean is 500 and the threshold 625. No lease can be
+			// transferred without overloading store 2, so replicas are moved.
+			name:     "replicas when leases don't suffice",
+			mode:     LBRebalancingLeasesAndReplicas,
+			storeQPS: []float64{1500, 700, 100, 100, 100},
+			hottest: []replicaWithQPS{
+				makeQPSReplica(1, 500, 1, 2),
+				makeQPSReplica(2, 400, 1, 2),
+				makeQPSReplica(3, 300, 1, 2),
+			},
+			expected: []move{{1, false, 3}, {2, false, 4}},
+			localQPS: 600,
+		},
+		{
+			name:     "leases only",
+			mode:     LBRebalancingLeasesOnly,
+			storeQPS: []float64{1500, 700, 100, 100, 100},
+			hottest: []replicaWithQPS{
+				makeQPSReplica(1, 500, 1, 2),
+				makeQPSReplica(2, 400, 1, 2),
+			},
+			localQPS: 1500,
+		},
+		{
+			// Ranges which are not fully replicated are left to the replicate
+			// queue.
+			name:     "under-replicated",
+			mode:     LBRebalancingLeasesAndReplicas,
+			storeQPS: []float64{1500, 700, 100, 100, 100},
+			hottest: []replicaWithQPS{
+				makeQPSReplica(1, 500, 1),
+			},
+			localQPS: 1500,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			moves = nil
+			storeList := makeStoreList(makeQPSStores(c.storeQPS...))
+			localQPS := sr.rebalance(
+				context.Background(), c.mode, config.SystemConfig{}, storeList, storeList,
+				c.hottest, c.storeQPS[0],
+			)
+			if !reflect.DeepEqual(moves, c.expected) {
+				t.Errorf("expected moves %+v, got %+v", c.expected, moves)
+			}
+			if localQPS != c.localQPS {
+				t.Errorf("expected local QPS %.2f, got %.2f", c.localQPS, localQPS)
+			}
+		})
+	}
+}
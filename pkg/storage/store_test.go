@@ -2514,7 +2514,7 @@ func TestSendSnapshotThrottling(t *testing.T) {
 	defer e.Close()
 
 	ctx := context.Background()
-	st := cluster.MakeTestingClusterSettings()
+	limiter := rate.NewLimiter(rate.Inf, 1)
 
 	header := SnapshotRequest_Header{
 		CanDecline: true,
@@ -2529,7 +2529,7 @@ func TestSendSnapshotThrottling(t *testing.T) {
 		sp := &fakeStorePool{}
 		expectedErr := errors.New("")
 		c := fakeSnapshotStream{nil, expectedErr}
-		err := sendSnapshot(ctx, st, c, sp, header, nil, newBatch, nil)
+		err := sendSnapshot(ctx, c, sp, header, nil, newBatch, limiter, nil, nil)
 		if sp.failedThrottles != 1 {
 			t.Fatalf("expected 1 failed throttle, but found %d", sp.failedThrottles)
 		}
@@ -2545,7 +2545,7 @@ func TestSendSnapshotThrottling(t *testing.T) {
 			Status: SnapshotResponse_DECLINED,
 		}
 		c := fakeSnapshotStream{resp, nil}
-		err := sendSnapshot(ctx, st, c, sp, header, nil, newBatch, nil)
+		err := sendSnapshot(ctx, c, sp, header, nil, newBatch, limiter, nil, nil)
 		if sp.declinedThrottles != 1 {
 			t.Fatalf("expected 1 declined throttle, but found %d", sp.declinedThrottles)
 		}
@@ -2562,7 +2562,7 @@ func TestSendSnapshotThrottling(t *testing.T) {
 			Status: SnapshotResponse_DECLINED,
 		}
 		c := fakeSnapshotStream{resp, nil}
-		err := sendSnapshot(ctx, st, c, sp, header, nil, newBatch, nil)
+		err := sendSnapshot(ctx, c, sp, header, nil, newBatch, limiter, nil, nil)
 		if sp.failedThrottles != 1 {
 			t.Fatalf("expected 1 failed throttle, but found %d", sp.failedThrottles)
 		}
@@ -2578,7 +2578,7 @@ func TestSendSnapshotThrottling(t *testing.T) {
 			Status: SnapshotResponse_ERROR,
 		}
 		c := fakeSnapshotStream{resp, nil}
-		err := sendSnapshot(ctx, st, c, sp, header, nil, newBatch, nil)
+		err := sendSnapshot(ctx, c, sp, header, nil, newBatch, limiter, nil, nil)
 		if sp.failedThrottles != 1 {
 			t.Fatalf("expected 1 failed throttle, but found %d", sp.failedThrottles)
 		}
@@ -2699,6 +2699,48 @@ func TestSnapshotRateLimit(t *testing.T) {
 	}
 }
 
+// TestRaftTransportSnapshotLimiter verifies that the snapshots of a priority
+// share a token bucket which follows the corresponding cluster setting.
+func TestRaftTransportSnapshotLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	transport := NewDummyRaftTransport(st)
+
+	recovery, err := transport.snapshotLimiter(SnapshotRequest_RECOVERY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebalance, err := transport.snapshotLimiter(SnapshotRequest_REBALANCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovery == rebalance {
+		t.Fatal("expected separate limiters for recovery and rebalance snapshots")
+	}
+	if e, a := rate.Limit(8<<20)/snapshotBatchSize, recovery.Limit(); e != a {
+		t.Fatalf("expected recovery limit %v, but found %v", e, a)
+	}
+
+	rebalanceSnapshotRate.Override(&st.SV, 4<<20)
+	again, err := transport.snapshotLimiter(SnapshotRequest_REBALANCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != rebalance {
+		t.Fatal("expected rebalance snapshots to share a limiter")
+	}
+	if e, a := rate.Limit(4<<20)/snapshotBatchSize, again.Limit(); e != a {
+		t.Fatalf("expected rebalance limit %v, but found %v", e, a)
+	}
+
+	if _, err := transport.snapshotLimiter(SnapshotRequest_UNKNOWN); !testutils.IsError(
+		err, "unknown snapshot priority",
+	) {
+		t.Fatalf("expected unknown priority error, but found %v", err)
+	}
+}
+
 func BenchmarkStoreGetReplica(b *testing.B) {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.TODO())
@@ -16,12 +16,14 @@ package storage
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
 )
 
 // Server implements ConsistencyServer.
@@ -74,3 +76,35 @@ func (is Server) CollectChecksum(
 		})
 	return resp, err
 }
+
+// WaitForApplication implements ConsistencyServer.
+func (is Server) WaitForApplication(
+	ctx context.Context, req *WaitForApplicationRequest,
+) (*WaitForApplicationResponse, error) {
+	resp := &WaitForApplicationResponse{}
+	err := is.execStoreCommand(req.StoreRequestHeader,
+		func(s *Store) error {
+			opts := retry.Options{
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     50 * time.Millisecond,
+				Multiplier:     2,
+				Closer:         s.stopper.ShouldQuiesce(),
+			}
+			for re := retry.StartWithCtx(ctx, opts); re.Next(); {
+				// The replica may not exist yet if it is being created by a
+				// snapshot, so it is looked up on every attempt.
+				r, err := s.GetReplica(req.RangeID)
+				if err != nil {
+					continue
+				}
+				r.mu.RLock()
+				leaseAppliedIndex := r.mu.state.LeaseAppliedIndex
+				r.mu.RUnlock()
+				if leaseAppliedIndex >= req.LeaseIndex {
+					return nil
+				}
+			}
+			return errors.Errorf("r%d did not apply lease index %d", req.RangeID, req.LeaseIndex)
+		})
+	return resp, err
+}
@@ -24,6 +24,12 @@ export const DROP_INDEX = "drop_index";
 export const CREATE_VIEW = "create_view";
 // Recorded when a view is dropped.
 export const DROP_VIEW = "drop_view";
+// Recorded when a type is created.
+export const CREATE_TYPE = "create_type";
+// Recorded when a type is dropped.
+export const DROP_TYPE = "drop_type";
+// Recorded when a type is altered.
+export const ALTER_TYPE = "alter_type";
 // Recorded when an in-progress schema change encounters a problem and is
 // reversed.
 export const REVERSE_SCHEMA_CHANGE = "reverse_schema_change";
@@ -44,7 +50,8 @@ export const SET_CLUSTER_SETTING = "set_cluster_setting";
 export const nodeEvents = [NODE_JOIN, NODE_RESTART, NODE_DECOMMISSIONED, NODE_RECOMMISSIONED];
 export const databaseEvents = [CREATE_DATABASE, DROP_DATABASE];
 export const tableEvents = [CREATE_TABLE, DROP_TABLE, ALTER_TABLE, CREATE_INDEX,
-  DROP_INDEX, CREATE_VIEW, DROP_VIEW, REVERSE_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE];
+  DROP_INDEX, CREATE_VIEW, DROP_VIEW, CREATE_TYPE, DROP_TYPE, ALTER_TYPE,
+  REVERSE_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE];
 export const settingsEvents = [SET_CLUSTER_SETTING];
 export const allEvents = [...nodeEvents, ...databaseEvents, ...tableEvents, ...settingsEvents];
 
@@ -54,6 +54,7 @@ export function getEventInfo(e: Event$Properties): SimplifiedEvent {
     IndexName: string,
     MutationID: string,
     TableName: string,
+    TypeName: string,
     User: string,
     ViewName: string,
     SettingName: string,
@@ -97,6 +98,15 @@ export function getEventInfo(e: Event$Properties): SimplifiedEvent {
     case eventTypes.DROP_VIEW:
       content = <span>View Dropped: User {info.User} dropped view {info.ViewName}</span>;
       break;
+    case eventTypes.CREATE_TYPE:
+      content = <span>Type Created: User {info.User} created type {info.TypeName}</span>;
+      break;
+    case eventTypes.DROP_TYPE:
+      content = <span>Type Dropped: User {info.User} dropped type {info.TypeName}</span>;
+      break;
+    case eventTypes.ALTER_TYPE:
+      content = <span>Type Altered: User {info.User} altered type {info.TypeName}</span>;
+      break;
     case eventTypes.REVERSE_SCHEMA_CHANGE:
       content = <span>Schema Change Reversed: Schema change with ID {info.MutationID} was reversed.</span>;
       break;
@@ -15,6 +15,7 @@ export default function (props: GraphDashboardProps) {
         <Metric name="cr.store.queue.replicagc.process.failure" title="Replica GC" nonNegativeRate />
         <Metric name="cr.store.queue.replicate.process.failure" title="Replication" nonNegativeRate />
         <Metric name="cr.store.queue.split.process.failure" title="Split" nonNegativeRate />
+        <Metric name="cr.store.queue.merge.process.failure" title="Merge" nonNegativeRate />
         <Metric name="cr.store.queue.consistency.process.failure" title="Consistency" nonNegativeRate />
         <Metric name="cr.store.queue.raftlog.process.failure" title="Raft Log" nonNegativeRate />
         <Metric name="cr.store.queue.tsmaintenance.process.failure" title="Time Series Maintenance" nonNegativeRate />
@@ -27,6 +28,7 @@ export default function (props: GraphDashboardProps) {
         <Metric name="cr.store.queue.replicagc.processingnanos" title="Replica GC" nonNegativeRate />
         <Metric name="cr.store.queue.replicate.processingnanos" title="Replication" nonNegativeRate />
         <Metric name="cr.store.queue.split.processingnanos" title="Split" nonNegativeRate />
+        <Metric name="cr.store.queue.merge.processingnanos" title="Merge" nonNegativeRate />
         <Metric name="cr.store.queue.consistency.processingnanos" title="Consistency" nonNegativeRate />
         <Metric name="cr.store.queue.raftlog.processingnanos" title="Raft Log" nonNegativeRate />
         <Metric name="cr.store.queue.tsmaintenance.processingnanos" title="Time Series Maintenance" nonNegativeRate />
@@ -64,6 +66,13 @@ export default function (props: GraphDashboardProps) {
       </Axis>
     </LineGraph>,
 
+    <LineGraph title="Merge Queue" sources={storeSources}>
+      <Axis>
+        <Metric name="cr.store.queue.merge.process.success" title="Successful Actions / sec" nonNegativeRate />
+        <Metric name="cr.store.queue.merge.pending" title="Pending Actions" downsampleMax />
+      </Axis>
+    </LineGraph>,
+
     <LineGraph title="GC Queue" sources={storeSources}>
       <Axis>
         <Metric name="cr.store.queue.gc.process.success" title="Successful Actions / sec" nonNegativeRate />
@@ -87,6 +87,21 @@ export default function (props: GraphDashboardProps) {
       </Axis>
     </LineGraph>,
 
+    <LineGraph title="Queries per Second per Store" tooltip={`The average number of requests served per second by the leaseholders on each store.`}>
+      <Axis>
+        {
+          _.map(nodeIDs, (nid) => (
+            <Metric
+              key={nid}
+              name="cr.store.rebalancing.queriespersecond"
+              title={nodeAddress(nodesSummary, nid)}
+              sources={storeIDsForNode(nodesSummary, nid)}
+            />
+          ))
+        }
+      </Axis>
+    </LineGraph>,
+
     <LineGraph title="Replica Quiescence" sources={storeSources}>
       <Axis>
         <Metric name="cr.store.replicas" title="Replicas" />
@@ -21,6 +21,7 @@ function printLogEventType(eventType: protos.cockroach.storage.RangeLogEventType
     case protos.cockroach.storage.RangeLogEventType.add: return "Add";
     case protos.cockroach.storage.RangeLogEventType.remove: return "Remove";
     case protos.cockroach.storage.RangeLogEventType.split: return "Split";
+    case protos.cockroach.storage.RangeLogEventType.transfer_lease: return "Transfer Lease";
     default: return "Unknown";
   }
 }
@@ -65,6 +66,7 @@ export default class LogTable extends React.Component<LogTableProps, {}> {
         {this.renderLogInfoDescriptor("New Range Descriptor", info.new_desc)}
         {this.renderLogInfoDescriptor("Added Replica", info.added_replica)}
         {this.renderLogInfoDescriptor("Removed Replica", info.removed_replica)}
+        {this.renderLogInfoDescriptor("Lease Target", info.lease_target)}
         {this.renderLogInfoDescriptor("Reason", info.reason)}
         {this.renderLogInfoDescriptor("Details", info.details)}
       </ul>
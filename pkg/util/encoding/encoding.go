@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -70,6 +71,8 @@ const (
 	decimalNaNDesc          = decimalInfinity + 1 // NaN encoded descendingly
 	decimalTerminator       = 0x00
 
+	timeTZMarker = decimalNaNDesc + 1
+
 	// IntMin is chosen such that the range of int tags does not overlap the
 	// ascii character set that is frequently used in testing.
 	IntMin      = 0x80
@@ -754,6 +757,55 @@ func decodeTime(b []byte) (r []byte, sec int64, nsec int64, err error) {
 	return b, sec, nsec, nil
 }
 
+// EncodeTimeTZAscending encodes a time of day observed at a fixed UTC offset
+// (in seconds east of UTC), appends it to the supplied buffer, and returns the
+// final buffer. The encoding orders values first by the corresponding time of
+// day in UTC, and then by offset, such that bytes.Compare orders the encoded
+// values the same way that TIMETZ values are compared.
+func EncodeTimeTZAscending(b []byte, t timeofday.TimeOfDay, offsetSecs int32) []byte {
+	b = append(b, timeTZMarker)
+	b = EncodeVarintAscending(b, int64(timeofday.ToUTC(t, offsetSecs)))
+	return EncodeVarintAscending(b, int64(offsetSecs))
+}
+
+// EncodeTimeTZDescending is the descending version of EncodeTimeTZAscending.
+func EncodeTimeTZDescending(b []byte, t timeofday.TimeOfDay, offsetSecs int32) []byte {
+	b = append(b, timeTZMarker)
+	b = EncodeVarintDescending(b, int64(timeofday.ToUTC(t, offsetSecs)))
+	return EncodeVarintDescending(b, int64(offsetSecs))
+}
+
+// DecodeTimeTZAscending decodes a time of day and UTC offset which were encoded
+// using EncodeTimeTZAscending. The remainder of the input buffer and the
+// decoded values are returned.
+func DecodeTimeTZAscending(b []byte) ([]byte, timeofday.TimeOfDay, int32, error) {
+	return decodeTimeTZ(b, DecodeVarintAscending)
+}
+
+// DecodeTimeTZDescending is the descending version of DecodeTimeTZAscending.
+func DecodeTimeTZDescending(b []byte) ([]byte, timeofday.TimeOfDay, int32, error) {
+	return decodeTimeTZ(b, DecodeVarintDescending)
+}
+
+func decodeTimeTZ(
+	b []byte, decodeVarint func([]byte) ([]byte, int64, error),
+) ([]byte, timeofday.TimeOfDay, int32, error) {
+	if PeekType(b) != TimeTZ {
+		return nil, 0, 0, errors.Errorf("did not find marker")
+	}
+	b = b[1:]
+	b, utc, err := decodeVarint(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	b, offsetSecs, err := decodeVarint(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	t := timeofday.FromUTC(timeofday.TimeOfDay(utc), int32(offsetSecs))
+	return b, t, int32(offsetSecs), nil
+}
+
 // EncodeDurationAscending encodes a duration.Duration value, appends it to the
 // supplied buffer, and returns the final buffer. The encoding is guaranteed to
 // be ordered such that if t1.Compare(t2) < 0 (or = 0 or > 0) then bytes.Compare
@@ -865,6 +917,7 @@ const (
 	// Do not change SentinelType from 15. This value is specifically used for bit
 	// manipulation in EncodeValueTag.
 	SentinelType Type = 15 // Used in the Value encoding.
+	TimeTZ       Type = 16
 )
 
 // PeekType peeks at the type of the value encoded at the start of b.
@@ -884,6 +937,8 @@ func PeekType(b []byte) Type {
 			return Time
 		case m == durationBigNegMarker, m == durationMarker, m == durationBigPosMarker:
 			return Duration
+		case m == timeTZMarker:
+			return TimeTZ
 		case m >= IntMin && m <= IntMax:
 			return Int
 		case m >= floatNaN && m <= floatNaNDesc:
@@ -938,7 +993,7 @@ func PeekLength(b []byte) (int, error) {
 		return getBytesLength(b, ascendingEscapes)
 	case bytesDescMarker:
 		return getBytesLength(b, descendingEscapes)
-	case timeMarker:
+	case timeMarker, timeTZMarker:
 		return GetMultiVarintLen(b, 2)
 	case durationBigNegMarker, durationMarker, durationBigPosMarker:
 		return GetMultiVarintLen(b, 3)
@@ -1028,6 +1083,14 @@ func prettyPrintFirstValue(b []byte) ([]byte, string, error) {
 			return b, "", err
 		}
 		return b, t.UTC().Format(time.RFC3339Nano), nil
+	case TimeTZ:
+		var t timeofday.TimeOfDay
+		var offsetSecs int32
+		b, t, offsetSecs, err = DecodeTimeTZAscending(b)
+		if err != nil {
+			return b, "", err
+		}
+		return b, formatTimeTZ(t, offsetSecs), nil
 	case Duration:
 		var d duration.Duration
 		b, d, err = DecodeDurationAscending(b)
@@ -1249,6 +1312,23 @@ func EncodeUntaggedTimeValue(appendTo []byte, t time.Time) []byte {
 	return EncodeNonsortingStdlibVarint(appendTo, int64(t.Nanosecond()))
 }
 
+// EncodeTimeTZValue encodes a time of day and UTC offset (in seconds east of
+// UTC) with its value tag, appends it to the supplied buffer, and returns the
+// final buffer.
+func EncodeTimeTZValue(
+	appendTo []byte, colID uint32, t timeofday.TimeOfDay, offsetSecs int32,
+) []byte {
+	appendTo = EncodeValueTag(appendTo, colID, TimeTZ)
+	return EncodeUntaggedTimeTZValue(appendTo, t, offsetSecs)
+}
+
+// EncodeUntaggedTimeTZValue encodes a time of day and UTC offset, appends them
+// to the supplied buffer, and returns the final buffer.
+func EncodeUntaggedTimeTZValue(appendTo []byte, t timeofday.TimeOfDay, offsetSecs int32) []byte {
+	appendTo = EncodeNonsortingStdlibVarint(appendTo, int64(t))
+	return EncodeNonsortingStdlibVarint(appendTo, int64(offsetSecs))
+}
+
 // EncodeDecimalValue encodes an apd.Decimal value with its value tag, appends
 // it to the supplied buffer, and returns the final buffer.
 func EncodeDecimalValue(appendTo []byte, colID uint32, d *apd.Decimal) []byte {
@@ -1454,6 +1534,34 @@ func DecodeUntaggedTimeValue(b []byte) (remaining []byte, t time.Time, err error
 	return b, timeutil.Unix(sec, nsec), nil
 }
 
+// DecodeTimeTZValue decodes a value encoded by EncodeTimeTZValue.
+func DecodeTimeTZValue(
+	b []byte,
+) (remaining []byte, t timeofday.TimeOfDay, offsetSecs int32, err error) {
+	b, err = decodeValueTypeAssert(b, TimeTZ)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	return DecodeUntaggedTimeTZValue(b)
+}
+
+// DecodeUntaggedTimeTZValue decodes a value encoded by
+// EncodeUntaggedTimeTZValue.
+func DecodeUntaggedTimeTZValue(
+	b []byte,
+) (remaining []byte, t timeofday.TimeOfDay, offsetSecs int32, err error) {
+	var micros, offset int64
+	b, _, micros, err = DecodeNonsortingStdlibVarint(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	b, _, offset, err = DecodeNonsortingStdlibVarint(b)
+	if err != nil {
+		return b, 0, 0, err
+	}
+	return b, timeofday.TimeOfDay(micros), int32(offset), nil
+}
+
 // DecodeDecimalValue decodes a value encoded by EncodeDecimalValue.
 func DecodeDecimalValue(b []byte) (remaining []byte, d apd.Decimal, err error) {
 	b, err = decodeValueTypeAssert(b, Decimal)
@@ -1587,7 +1695,7 @@ func PeekValueLength(b []byte) (typeOffset int, length int, err error) {
 	case Decimal:
 		_, n, i, err := DecodeNonsortingStdlibUvarint(b)
 		return typeOffset, dataOffset + n + int(i), err
-	case Time:
+	case Time, TimeTZ:
 		n, err := getMultiNonsortingVarintLen(b, 2)
 		return typeOffset, dataOffset + n, err
 	case Duration:
@@ -1631,7 +1739,7 @@ func UpperBoundValueEncodingSize(colID uint32, typ Type, size int) (int, bool) {
 			return len(encodedTag) + maxBinaryUvarintSize + upperBoundNonsortingDecimalUnscaledSize(size), true
 		}
 		return 0, false
-	case Time:
+	case Time, TimeTZ:
 		return len(encodedTag) + 2*maxVarintSize, true
 	case Duration:
 		return len(encodedTag) + 3*maxVarintSize, true
@@ -1697,6 +1805,14 @@ func PrettyPrintValueEncoded(b []byte) ([]byte, string, error) {
 			return b, "", err
 		}
 		return b, t.UTC().Format(time.RFC3339Nano), nil
+	case TimeTZ:
+		var t timeofday.TimeOfDay
+		var offsetSecs int32
+		b, t, offsetSecs, err = DecodeTimeTZValue(b)
+		if err != nil {
+			return b, "", err
+		}
+		return b, formatTimeTZ(t, offsetSecs), nil
 	case Duration:
 		var d duration.Duration
 		b, d, err = DecodeDurationValue(b)
@@ -1722,3 +1838,9 @@ func PrettyPrintValueEncoded(b []byte) ([]byte, string, error) {
 		return b, "", errors.Errorf("unknown type %s", typ)
 	}
 }
+
+// formatTimeTZ returns a string representation of a time of day at a fixed UTC
+// offset, for use when pretty-printing.
+func formatTimeTZ(t timeofday.TimeOfDay, offsetSecs int32) string {
+	return t.String() + time.Unix(0, 0).In(timeofday.OffsetSecondsToZone(offsetSecs)).Format("-07:00")
+}
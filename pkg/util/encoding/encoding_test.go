@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -766,6 +767,56 @@ func TestEncodeDecodeTime(t *testing.T) {
 	}
 }
 
+func TestEncodeDecodeTimeTZ(t *testing.T) {
+	const hour = 60 * 60
+	// Test cases are in increasing order: first by time of day in UTC, then by
+	// offset.
+	testCases := []struct {
+		t          timeofday.TimeOfDay
+		offsetSecs int32
+	}{
+		{timeofday.New(0, 0, 0, 0), 0},
+		{timeofday.New(1, 0, 0, 0), hour},
+		{timeofday.New(0, 0, 0, 1), 0},
+		{timeofday.New(11, 59, 59, 999999), 0},
+		{timeofday.New(7, 0, 0, 0), -5 * hour},
+		{timeofday.New(12, 0, 0, 0), 0},
+		{timeofday.New(17, 30, 0, 0), 5*hour + 30*60},
+		{timeofday.New(23, 59, 59, 999999), 0},
+	}
+	for _, dir := range []Direction{Ascending, Descending} {
+		var lastEncoded []byte
+		for i, tc := range testCases {
+			var b []byte
+			var decoded timeofday.TimeOfDay
+			var decodedOffset int32
+			var err error
+			if dir == Ascending {
+				b = EncodeTimeTZAscending(b, tc.t, tc.offsetSecs)
+				_, decoded, decodedOffset, err = DecodeTimeTZAscending(b)
+			} else {
+				b = EncodeTimeTZDescending(b, tc.t, tc.offsetSecs)
+				_, decoded, decodedOffset, err = DecodeTimeTZDescending(b)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded != tc.t || decodedOffset != tc.offsetSecs {
+				t.Fatalf("lossy transport: before (%s, %d) vs after (%s, %d)",
+					tc.t, tc.offsetSecs, decoded, decodedOffset)
+			}
+			testPeekLength(t, b)
+			if i > 0 {
+				if (bytes.Compare(lastEncoded, b) >= 0 && dir == Ascending) ||
+					(bytes.Compare(lastEncoded, b) <= 0 && dir == Descending) {
+					t.Fatalf("encodings of %d and %d not increasing", i-1, i)
+				}
+			}
+			lastEncoded = b
+		}
+	}
+}
+
 type testCaseDuration struct {
 	value  duration.Duration
 	expEnc []byte
@@ -1367,6 +1418,27 @@ func TestValueEncodeDecodeTime(t *testing.T) {
 	}
 }
 
+func TestValueEncodeDecodeTimeTZ(t *testing.T) {
+	rng, seed := randutil.NewPseudoRand()
+	for i := 0; i < 1000; i++ {
+		tod := timeofday.Random(rng)
+		offsetSecs := int32(rng.Intn(2*24*60*60-1) - (24*60*60 - 1))
+		buf := EncodeTimeTZValue(nil, NoColumnID, tod, offsetSecs)
+		_, x, xOffset, err := DecodeTimeTZValue(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x != tod || xOffset != offsetSecs {
+			t.Errorf("seed %d: expected (%s, %d) got (%s, %d)", seed, tod, offsetSecs, x, xOffset)
+		}
+		if _, l, err := PeekValueLength(buf); err != nil {
+			t.Fatal(err)
+		} else if l != len(buf) {
+			t.Errorf("seed %d: got length %d expected %d", seed, l, len(buf))
+		}
+	}
+}
+
 func TestValueEncodeDecodeDuration(t *testing.T) {
 	rng, seed := randutil.NewPseudoRand()
 	rd := randData{rng}
@@ -1815,6 +1887,8 @@ func TestPrettyPrintValueEncoded(t *testing.T) {
 		{EncodeDecimalValue(nil, NoColumnID, apd.New(628, -2)), "6.28"},
 		{EncodeTimeValue(nil, NoColumnID,
 			time.Date(2016, 6, 29, 16, 2, 50, 5, time.UTC)), "2016-06-29T16:02:50.000000005Z"},
+		{EncodeTimeTZValue(nil, NoColumnID,
+			timeofday.New(16, 2, 50, 5), -4*60*60), "16:02:50.000005-04:00"},
 		{EncodeDurationValue(nil, NoColumnID,
 			duration.Duration{Months: 1, Days: 2, Nanos: 3}), "1mon2d3ns"},
 		{EncodeBytesValue(nil, NoColumnID, []byte{0x1, 0x2, 0xF, 0xFF}), "01020fff"},
@@ -577,6 +577,7 @@ func init() {
 	logging.setVState(0, nil, false)
 	logging.exitFunc = os.Exit
 	logging.gcNotify = make(chan struct{}, 1)
+	logging.prefix = program
 
 	go logging.flushDaemon()
 }
@@ -612,6 +613,10 @@ func SetSync(sync bool) {
 type loggingT struct {
 	noStderrRedirect bool
 
+	// prefix is the program name component of the names of the log files
+	// written by this logger.
+	prefix string
+
 	// Level flag for output to stderr. Handled atomically.
 	stderrThreshold Severity
 	// Level flag for output to files.
@@ -909,7 +914,7 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 		}
 	}
 	var err error
-	sb.file, sb.lastRotation, _, err = create(now, sb.lastRotation)
+	sb.file, sb.lastRotation, _, err = create(sb.logger.prefix, now, sb.lastRotation)
 	sb.nbytes = 0
 	if err != nil {
 		return err
@@ -918,8 +923,9 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 	// Redirect stderr to the current INFO log file in order to capture panic
 	// stack traces that are written by the Go runtime to stderr. Note that if
 	// --logtostderr is true we'll never enter this code path and panic stack
-	// traces will go to the original stderr as you would expect.
-	if logging.stderrThreshold > Severity_INFO && !logging.noStderrRedirect {
+	// traces will go to the original stderr as you would expect. Secondary
+	// loggers never capture stderr.
+	if sb.logger == &logging && logging.stderrThreshold > Severity_INFO && !logging.noStderrRedirect {
 		// NB: any concurrent output to stderr may straddle the old and new
 		// files. This doesn't apply to log messages as we won't reach this code
 		// unless we're not logging to stderr.
@@ -958,7 +964,7 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 	}
 
 	select {
-	case logging.gcNotify <- struct{}{}:
+	case sb.logger.gcNotify <- struct{}{}:
 	default:
 	}
 	return nil
@@ -1059,7 +1065,7 @@ func (l *loggingT) gcOldFiles() {
 	}
 
 	logFilesCombinedMaxSize := atomic.LoadInt64(&LogFilesCombinedMaxSize)
-	files := selectFiles(allFiles, math.MaxInt64)
+	files := selectFiles(filterFiles(allFiles, l.prefix), math.MaxInt64)
 	if len(files) == 0 {
 		return
 	}
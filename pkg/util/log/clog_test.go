@@ -23,6 +23,7 @@ import (
 	"io"
 	"io/ioutil"
 	stdLog "log"
+	"math"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -775,6 +776,94 @@ func TestFileSeverityFilter(t *testing.T) {
 	}
 }
 
+func TestSecondaryLogger(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+
+	l := NewSecondaryLogger("test", true /* forceSyncWrites */)
+	Infof(context.Background(), "main text")
+	l.Logf(context.Background(), "secondary text")
+
+	contents, err := ioutil.ReadFile(l.logger.file.(*syncBuffer).file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), "secondary text") {
+		t.Errorf("secondary log does not contain its text\n%s", contents)
+	}
+	if strings.Contains(string(contents), "main text") {
+		t.Errorf("secondary log contains text of the main log\n%s", contents)
+	}
+
+	files, err := ListLogFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := 1, len(filterFiles(files, l.logger.prefix)); e != a {
+		t.Errorf("expected %d secondary log files, found %d", e, a)
+	}
+
+	// The secondary log is not searched.
+	Flush()
+	entries, err := FetchEntriesFromFiles(0, math.MaxInt64, 100, regexp.MustCompile("text"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !strings.Contains(entries[0].Message, "main text") {
+		t.Errorf("expected only the main log entry, found %v", entries)
+	}
+}
+
+func TestSecondaryLoggerGC(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+
+	l := NewSecondaryLogger("gc", true /* forceSyncWrites */)
+	l.logger.mu.Lock()
+	l.logger.disableDaemons = true
+	l.logger.mu.Unlock()
+
+	Infof(context.Background(), "main text")
+
+	defer func(previous int64) { LogFileMaxSize = previous }(LogFileMaxSize)
+	LogFileMaxSize = 1 // ensure rotation on every log write
+	defer func(previous int64) {
+		atomic.StoreInt64(&LogFilesCombinedMaxSize, previous)
+	}(LogFilesCombinedMaxSize)
+	atomic.StoreInt64(&LogFilesCombinedMaxSize, 1)
+
+	countFiles := func(prefix string) int {
+		files, err := ListLogFiles()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(filterFiles(files, prefix))
+	}
+
+	const newLogFiles = 5
+	for i := 0; i < newLogFiles; i++ {
+		l.Logf(context.Background(), "%d", i)
+	}
+	if e, a := newLogFiles, countFiles(l.logger.prefix); e != a {
+		t.Fatalf("expected %d secondary log files, but found %d", e, a)
+	}
+
+	l.logger.gcOldFiles()
+
+	// Only the most recent secondary log file is kept, and the main log
+	// files are left alone.
+	if e, a := 1, countFiles(l.logger.prefix); e != a {
+		t.Errorf("expected %d secondary log files, but found %d", e, a)
+	}
+	if e, a := 1, countFiles(logging.prefix); e != a {
+		t.Errorf("expected %d main log files, but found %d", e, a)
+	}
+}
+
 func BenchmarkHeader(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		buf := formatHeader(Severity_INFO, timeutil.Now(), 200, "file.go", 100, nil)
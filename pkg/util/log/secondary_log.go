@@ -0,0 +1,60 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"os"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/caller"
+)
+
+// SecondaryLogger writes entries to a set of log files separate from the
+// main log, in the same directory. Its files are named like the main log
+// files, with the program name followed by the logger's file name prefix,
+// and are rotated and garbage collected at the same sizes, separately from
+// the main log files. Its entries are neither copied to stderr nor returned
+// by FetchEntriesFromFiles.
+type SecondaryLogger struct {
+	logger loggingT
+}
+
+// NewSecondaryLogger creates a SecondaryLogger whose files are named
+// "<program>-<fileNamePrefix>.*.log". If forceSyncWrites is set, every
+// entry is flushed and synced to disk before the call that logs it
+// returns; otherwise, entries are flushed periodically like the main log.
+func NewSecondaryLogger(fileNamePrefix string, forceSyncWrites bool) *SecondaryLogger {
+	l := &SecondaryLogger{}
+	l.logger.prefix = program + "-" + fileNamePrefix
+	l.logger.stderrThreshold = Severity_NONE
+	l.logger.fileThreshold = Severity_INFO
+	l.logger.syncWrites = forceSyncWrites
+	l.logger.exitFunc = os.Exit
+	l.logger.gcNotify = make(chan struct{}, 1)
+	go l.logger.gcDaemon()
+	if !forceSyncWrites {
+		go l.logger.flushDaemon()
+	}
+	return l
+}
+
+// Logf logs an entry to the secondary log. The entry is tagged with the
+// log tags of ctx. Nothing is logged if no log directory is configured.
+func (l *SecondaryLogger) Logf(ctx context.Context, format string, args ...interface{}) {
+	file, line, _ := caller.Lookup(1)
+	msg := MakeMessage(ctx, format, args)
+	l.logger.outputLogEntry(Severity_INFO, file, line, msg)
+}
@@ -0,0 +1,161 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeofday
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// TimeOfDay represents a time of day (with no date or time zone), stored as
+// the number of microseconds since midnight.
+type TimeOfDay int64
+
+const (
+	// Midnight is the time of day corresponding to 00:00:00.
+	Midnight = TimeOfDay(0)
+	// Min is the minimum representable time of day, 00:00:00.
+	Min = Midnight
+	// Max is the maximum representable time of day, 23:59:59.999999.
+	Max = TimeOfDay(microsecondsPerDay - 1)
+
+	microsecondsPerSecond = 1e6
+	microsecondsPerMinute = 60 * microsecondsPerSecond
+	microsecondsPerHour   = 60 * microsecondsPerMinute
+	microsecondsPerDay    = 24 * microsecondsPerHour
+	nanosPerMicro         = 1000
+)
+
+// New creates a TimeOfDay representing the specified time.
+func New(hour, min, sec, micro int) TimeOfDay {
+	hours := time.Duration(hour) * time.Hour
+	minutes := time.Duration(min) * time.Minute
+	seconds := time.Duration(sec) * time.Second
+	micros := time.Duration(micro) * time.Microsecond
+	return FromInt(int64((hours + minutes + seconds + micros) / time.Microsecond))
+}
+
+// FromInt constructs a TimeOfDay from an int64 representing microseconds since
+// midnight. Values outside of a single day wrap around.
+func FromInt(i int64) TimeOfDay {
+	return TimeOfDay(positiveMod(i, microsecondsPerDay))
+}
+
+// FromTime constructs a TimeOfDay from the wall-clock reading of a time.Time,
+// in the time's own location.
+func FromTime(t time.Time) TimeOfDay {
+	// Adjust for rounding errors: round to the nearest microsecond first.
+	t = t.Round(time.Microsecond)
+	return New(t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/nanosPerMicro)
+}
+
+// ToTime converts a TimeOfDay to a time.Time on the Unix epoch date, in UTC.
+func (t TimeOfDay) ToTime() time.Time {
+	return timeutil.Unix(0, int64(t)*nanosPerMicro)
+}
+
+// Format emits a string representation of the TimeOfDay to a Buffer. The
+// output omits trailing zeros from the fractional seconds.
+func (t TimeOfDay) Format(buf *bytes.Buffer) {
+	micros := t.Microsecond()
+	if micros > 0 {
+		s := fmt.Sprintf("%02d:%02d:%02d.%06d", t.Hour(), t.Minute(), t.Second(), micros)
+		buf.WriteString(strings.TrimRight(s, "0"))
+		return
+	}
+	fmt.Fprintf(buf, "%02d:%02d:%02d", t.Hour(), t.Minute(), t.Second())
+}
+
+// String implements the fmt.Stringer interface.
+func (t TimeOfDay) String() string {
+	var buf bytes.Buffer
+	t.Format(&buf)
+	return buf.String()
+}
+
+// Hour returns the hour specified by t, in the range [0, 23].
+func (t TimeOfDay) Hour() int {
+	return int(int64(t)%microsecondsPerDay) / microsecondsPerHour
+}
+
+// Minute returns the minute offset within the hour specified by t, in the
+// range [0, 59].
+func (t TimeOfDay) Minute() int {
+	return int(int64(t)%microsecondsPerHour) / microsecondsPerMinute
+}
+
+// Second returns the second offset within the minute specified by t, in the
+// range [0, 59].
+func (t TimeOfDay) Second() int {
+	return int(int64(t)%microsecondsPerMinute) / microsecondsPerSecond
+}
+
+// Microsecond returns the microsecond offset within the second specified by t,
+// in the range [0, 999999].
+func (t TimeOfDay) Microsecond() int {
+	return int(int64(t) % microsecondsPerSecond)
+}
+
+// Add adds a Duration to a TimeOfDay, wrapping into the next (or previous) day
+// if necessary. The Months and Days components of the Duration are ignored, as
+// they have no effect on a time of day.
+func (t TimeOfDay) Add(d duration.Duration) TimeOfDay {
+	return FromInt(int64(t) + d.Nanos/nanosPerMicro)
+}
+
+// Difference returns the interval between t1 and t2, which may be negative.
+func Difference(t1 TimeOfDay, t2 TimeOfDay) duration.Duration {
+	return duration.Duration{Nanos: int64(t1-t2) * nanosPerMicro}
+}
+
+// Random generates a random TimeOfDay.
+func Random(rng *rand.Rand) TimeOfDay {
+	return TimeOfDay(rng.Int63n(microsecondsPerDay))
+}
+
+// OffsetSecondsToZone returns the time.Location corresponding to a fixed
+// offset of the given number of seconds east of UTC.
+func OffsetSecondsToZone(offsetSecs int32) *time.Location {
+	if offsetSecs == 0 {
+		return time.UTC
+	}
+	return time.FixedZone("", int(offsetSecs))
+}
+
+// ToUTC converts a time of day observed at a fixed offset (in seconds east of
+// UTC) to the corresponding time of day in UTC.
+func ToUTC(t TimeOfDay, offsetSecs int32) TimeOfDay {
+	return FromInt(int64(t) - int64(offsetSecs)*microsecondsPerSecond)
+}
+
+// FromUTC converts a time of day in UTC to the corresponding time of day
+// observed at a fixed offset (in seconds east of UTC).
+func FromUTC(t TimeOfDay, offsetSecs int32) TimeOfDay {
+	return FromInt(int64(t) + int64(offsetSecs)*microsecondsPerSecond)
+}
+
+func positiveMod(x, y int64) int64 {
+	r := x % y
+	if r < 0 {
+		r += y
+	}
+	return r
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeofday
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+)
+
+func TestString(t *testing.T) {
+	testCases := []struct {
+		t   TimeOfDay
+		exp string
+	}{
+		{Midnight, "00:00:00"},
+		{New(0, 0, 0, 1), "00:00:00.000001"},
+		{New(0, 0, 1, 0), "00:00:01"},
+		{New(0, 1, 0, 0), "00:01:00"},
+		{New(1, 0, 0, 0), "01:00:00"},
+		{New(12, 34, 56, 789000), "12:34:56.789"},
+		{Max, "23:59:59.999999"},
+	}
+	for _, tc := range testCases {
+		if actual := tc.t.String(); actual != tc.exp {
+			t.Errorf("%d: expected %s, but got %s", int64(tc.t), tc.exp, actual)
+		}
+	}
+}
+
+func TestFromAndToTime(t *testing.T) {
+	testCases := []struct {
+		s   string
+		exp string
+	}{
+		{"0000-01-01T00:00:00Z", "1970-01-01T00:00:00Z"},
+		{"2017-05-31T12:34:56.789Z", "1970-01-01T12:34:56.789Z"},
+		{"2017-05-31T23:59:59.999999Z", "1970-01-01T23:59:59.999999Z"},
+		{"2017-05-31T12:00:00+05:00", "1970-01-01T12:00:00Z"},
+	}
+	for _, tc := range testCases {
+		fromTime, err := time.Parse(time.RFC3339Nano, tc.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := FromTime(fromTime).ToTime().Format(time.RFC3339Nano)
+		if actual != tc.exp {
+			t.Errorf("%s: got %s, expected %s", tc.s, actual, tc.exp)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	testCases := []struct {
+		t   TimeOfDay
+		d   duration.Duration
+		exp TimeOfDay
+	}{
+		{New(12, 0, 0, 0), duration.Duration{Nanos: int64(time.Hour)}, New(13, 0, 0, 0)},
+		{New(12, 0, 0, 0), duration.Duration{Nanos: -int64(time.Hour)}, New(11, 0, 0, 0)},
+		{New(23, 0, 0, 0), duration.Duration{Nanos: 2 * int64(time.Hour)}, New(1, 0, 0, 0)},
+		{New(1, 0, 0, 0), duration.Duration{Nanos: -2 * int64(time.Hour)}, New(23, 0, 0, 0)},
+		{New(12, 0, 0, 0), duration.Duration{Days: 1}, New(12, 0, 0, 0)},
+		{New(12, 0, 0, 0), duration.Duration{Months: 1}, New(12, 0, 0, 0)},
+		{Max, duration.Duration{Nanos: int64(time.Microsecond)}, Min},
+		{Min, duration.Duration{Nanos: -int64(time.Microsecond)}, Max},
+		{Min, duration.Duration{Nanos: -24 * int64(time.Hour)}, Min},
+	}
+	for _, tc := range testCases {
+		if actual := tc.t.Add(tc.d); actual != tc.exp {
+			t.Errorf("%s + %s: got %s, expected %s", tc.t, tc.d, actual, tc.exp)
+		}
+	}
+}
+
+func TestDifference(t *testing.T) {
+	testCases := []struct {
+		t1, t2 TimeOfDay
+		exp    duration.Duration
+	}{
+		{Min, Max, duration.Duration{Nanos: -int64(24*time.Hour - time.Microsecond)}},
+		{New(13, 0, 0, 0), New(12, 0, 0, 0), duration.Duration{Nanos: int64(time.Hour)}},
+		{New(12, 0, 0, 0), New(12, 0, 0, 0), duration.Duration{}},
+	}
+	for _, tc := range testCases {
+		if actual := Difference(tc.t1, tc.t2); actual.Compare(tc.exp) != 0 {
+			t.Errorf("%s - %s: got %s, expected %s", tc.t1, tc.t2, actual, tc.exp)
+		}
+	}
+}
+
+func TestUTCConversion(t *testing.T) {
+	testCases := []struct {
+		t          TimeOfDay
+		offsetSecs int32
+		utc        TimeOfDay
+	}{
+		{New(12, 0, 0, 0), 0, New(12, 0, 0, 0)},
+		{New(12, 0, 0, 0), 5 * 60 * 60, New(7, 0, 0, 0)},
+		{New(12, 0, 0, 0), -5 * 60 * 60, New(17, 0, 0, 0)},
+		{New(2, 0, 0, 0), 3 * 60 * 60, New(23, 0, 0, 0)},
+		{New(22, 30, 0, 0), -3 * 60 * 60, New(1, 30, 0, 0)},
+	}
+	for _, tc := range testCases {
+		if actual := ToUTC(tc.t, tc.offsetSecs); actual != tc.utc {
+			t.Errorf("ToUTC(%s, %d): got %s, expected %s", tc.t, tc.offsetSecs, actual, tc.utc)
+		}
+		if actual := FromUTC(tc.utc, tc.offsetSecs); actual != tc.t {
+			t.Errorf("FromUTC(%s, %d): got %s, expected %s", tc.utc, tc.offsetSecs, actual, tc.t)
+		}
+	}
+}
@@ -12,15 +12,13 @@
 // implied. See the License for the specific language governing
 // permissions and limitations under the License.
 
-package sqlbase
+package timeutil
 
 import (
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 const fixedOffsetPrefix string = "fixed offset:"
@@ -35,13 +33,52 @@ func FixedOffsetTimeZoneToLocation(offset int, origRepr string) *time.Location {
 
 // TimeZoneStringToLocation transforms a string into a time.Location. It
 // supports the usual locations and also time zones with fixed offsets created
-// by FixedOffsetTimeZoneToLocation().
+// by FixedOffsetTimeZoneToLocation(), and is meant for locations marshalled
+// between nodes. Time zone names given by users go through
+// TimeZoneNameToLocation instead.
 func TimeZoneStringToLocation(location string) (*time.Location, error) {
 	offset, origRepr, parsed := ParseFixedOffsetTimeZone(location)
 	if parsed {
 		return FixedOffsetTimeZoneToLocation(offset, origRepr), nil
 	}
-	return timeutil.LoadLocation(location)
+	return LoadLocation(location)
+}
+
+// TimeZoneNameToLocation transforms a time zone name given by a user into a
+// time.Location. Names are matched as given, then upper-cased, then with each
+// part separated by "/" or "_" title-cased, so that e.g. "utc" and
+// "america/new_york" are accepted. The error of the first lookup is returned
+// if none of them succeed. The names of the fixed offset time zones created
+// by FixedOffsetTimeZoneToLocation() are rejected.
+func TimeZoneNameToLocation(name string) (*time.Location, error) {
+	if strings.HasPrefix(strings.ToLower(name), fixedOffsetPrefix) {
+		return nil, fmt.Errorf("invalid time zone name %q", name)
+	}
+	loc, err := LoadLocation(name)
+	if err == nil {
+		return loc, nil
+	}
+	for _, s := range []string{strings.ToUpper(name), titleCaseTimeZoneName(name)} {
+		if loc, err1 := LoadLocation(s); err1 == nil {
+			return loc, nil
+		}
+	}
+	return nil, err
+}
+
+// titleCaseTimeZoneName upper-cases the first letter of each part of the
+// given time zone name separated by "/" or "_", and lower-cases the others,
+// as in "America/New_York".
+func titleCaseTimeZoneName(name string) string {
+	b := []byte(strings.ToLower(name))
+	for i := range b {
+		if i == 0 || b[i-1] == '/' || b[i-1] == '_' {
+			if 'a' <= b[i] && b[i] <= 'z' {
+				b[i] -= 'a' - 'A'
+			}
+		}
+	}
+	return string(b)
 }
 
 // ParseFixedOffsetTimeZone takes the string representation of a time.Location
@@ -0,0 +1,75 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeZoneNameToLocation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"UTC", "UTC"},
+		{"utc", "UTC"},
+		{"local", "UTC"},
+		{"America/New_York", "America/New_York"},
+		{"america/new_york", "America/New_York"},
+		{"AMERICA/NEW_YORK", "America/New_York"},
+		{"america/argentina/buenos_aires", "America/Argentina/Buenos_Aires"},
+		{"europe/berlin", "Europe/Berlin"},
+		{"est", "EST"},
+		// Fixed offsets are only accepted from other nodes.
+		{"fixed offset:3600 (1h)", ""},
+		{"Fixed Offset:3600 (1h)", ""},
+		{"no/such_zone", ""},
+	}
+	for _, tc := range testCases {
+		loc, err := TimeZoneNameToLocation(tc.name)
+		if tc.expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", tc.name, loc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		} else if loc.String() != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, loc)
+		}
+	}
+}
+
+func TestTimeZoneStringToLocation(t *testing.T) {
+	fixed := FixedOffsetTimeZoneToLocation(3600, "1h")
+	loc, err := TimeZoneStringToLocation(fixed.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, offset := time.Date(2017, 1, 1, 0, 0, 0, 0, loc).Zone(); offset != 3600 {
+		t.Errorf("expected offset 3600, got %d", offset)
+	}
+	if loc.String() != fixed.String() {
+		t.Errorf("expected %s, got %s", fixed, loc)
+	}
+
+	if loc, err := TimeZoneStringToLocation("America/New_York"); err != nil {
+		t.Fatal(err)
+	} else if loc.String() != "America/New_York" {
+		t.Errorf("expected America/New_York, got %s", loc)
+	}
+}